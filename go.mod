@@ -20,6 +20,7 @@ require (
 	github.com/labstack/echo/v4 v4.15.0
 	github.com/nicholas-fedor/shoutrrr v0.13.2
 	github.com/patrickmn/go-cache v2.1.0+incompatible
+	github.com/pion/opus v0.1.0
 	github.com/pkg/sftp v1.13.10
 	github.com/prometheus/client_golang v1.23.2
 	github.com/prometheus/client_model v0.6.2
@@ -37,6 +38,7 @@ require (
 	github.com/tphakala/simd v1.0.22
 	go.uber.org/goleak v1.3.0
 	golang.org/x/crypto v0.48.0
+	golang.org/x/image v0.25.0
 	golang.org/x/net v0.50.0
 	golang.org/x/text v0.34.0
 	google.golang.org/api v0.266.0
@@ -0,0 +1,62 @@
+package alerting
+
+import (
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// AudioQualityAlertBridge bridges the events.EventBus audio quality events to
+// the alerting event bus. Raised issues become stream or device audio issue
+// events; cleared issues are not alerted.
+type AudioQualityAlertBridge struct {
+	log logger.Logger
+}
+
+// NewAudioQualityAlertBridge creates a new bridge consumer.
+func NewAudioQualityAlertBridge(log logger.Logger) *AudioQualityAlertBridge {
+	return &AudioQualityAlertBridge{log: log}
+}
+
+func (b *AudioQualityAlertBridge) Name() string {
+	return "audio-quality-alert-bridge"
+}
+
+func (b *AudioQualityAlertBridge) ProcessEvent(_ events.ErrorEvent) error {
+	return nil
+}
+
+func (b *AudioQualityAlertBridge) ProcessBatch(_ []events.ErrorEvent) error {
+	return nil
+}
+
+func (b *AudioQualityAlertBridge) SupportsBatching() bool {
+	return false
+}
+
+// ProcessAudioQualityEvent publishes raised audio quality issues to the alert event bus.
+func (b *AudioQualityAlertBridge) ProcessAudioQualityEvent(event events.AudioQualityEvent) error {
+	if event.GetSeverity() == events.SeverityRecovery {
+		return nil
+	}
+
+	props := map[string]any{
+		PropertyIssue:   event.GetIssue(),
+		PropertyMessage: event.GetMessage(),
+		PropertyValue:   event.GetValue(),
+	}
+
+	alert := &AlertEvent{Properties: props}
+	if event.GetSourceKind() == events.AudioSourceDevice {
+		alert.ObjectType = ObjectTypeDevice
+		alert.EventName = EventDeviceAudioIssue
+		props[PropertyDeviceName] = event.GetSourceName()
+	} else {
+		alert.ObjectType = ObjectTypeStream
+		alert.EventName = EventStreamAudioIssue
+		props[PropertyStreamName] = event.GetSourceName()
+		props[PropertyStreamURL] = event.GetSourceURL()
+	}
+
+	TryPublish(alert)
+	return nil
+}
@@ -0,0 +1,66 @@
+package alerting
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/events"
+)
+
+func TestAudioQualityAlertBridge(t *testing.T) {
+	bus := NewAlertEventBus()
+	defer bus.Stop()
+	SetGlobalBus(bus)
+	t.Cleanup(func() { SetGlobalBus(nil) })
+
+	var received atomic.Pointer[AlertEvent]
+	var count atomic.Int32
+	bus.Subscribe(func(event *AlertEvent) {
+		received.Store(event)
+		count.Add(1)
+	})
+
+	bridge := NewAudioQualityAlertBridge(initTestLogger())
+
+	// Cleared issues are not alerted
+	require.NoError(t, bridge.ProcessAudioQualityEvent(events.NewAudioQualityEvent(events.AudioQualityEventData{
+		SourceName: "Garden mic",
+		SourceKind: events.AudioSourceDevice,
+		Issue:      "mains_hum",
+		Severity:   events.SeverityRecovery,
+	})))
+
+	require.NoError(t, bridge.ProcessAudioQualityEvent(events.NewAudioQualityEvent(events.AudioQualityEventData{
+		SourceName: "Garden mic",
+		SourceKind: events.AudioSourceDevice,
+		Issue:      "mains_hum",
+		Value:      50,
+		Severity:   events.SeverityWarning,
+		Message:    "50 Hz mains hum",
+	})))
+
+	require.Eventually(t, func() bool { return received.Load() != nil }, time.Second, 5*time.Millisecond)
+	got := received.Load()
+	assert.Equal(t, ObjectTypeDevice, got.ObjectType)
+	assert.Equal(t, EventDeviceAudioIssue, got.EventName)
+	assert.Equal(t, "Garden mic", got.Properties[PropertyDeviceName])
+	assert.Equal(t, "mains_hum", got.Properties[PropertyIssue])
+	assert.InDelta(t, 50.0, got.Properties[PropertyValue], 0)
+	assert.Equal(t, int32(1), count.Load())
+
+	require.NoError(t, bridge.ProcessAudioQualityEvent(events.NewAudioQualityEvent(events.AudioQualityEventData{
+		SourceName: "Pond cam",
+		SourceKind: events.AudioSourceStream,
+		SourceURL:  "rtsp://cam/stream",
+		Issue:      "clipping",
+		Severity:   events.SeverityWarning,
+	})))
+	require.Eventually(t, func() bool { return count.Load() == 2 }, time.Second, 5*time.Millisecond)
+	got = received.Load()
+	assert.Equal(t, ObjectTypeStream, got.ObjectType)
+	assert.Equal(t, EventStreamAudioIssue, got.EventName)
+	assert.Equal(t, "rtsp://cam/stream", got.Properties[PropertyStreamURL])
+}
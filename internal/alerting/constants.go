@@ -9,6 +9,7 @@ const (
 	ObjectTypeIntegration = "integration"
 	ObjectTypeDevice      = "device"
 	ObjectTypeSystem      = "system"
+	ObjectTypeFailover    = "failover"
 )
 
 // Trigger types define how a rule is activated.
@@ -22,6 +23,7 @@ const (
 	EventStreamConnected    = "stream.connected"
 	EventStreamDisconnected = "stream.disconnected"
 	EventStreamError        = "stream.error"
+	EventStreamAudioIssue   = "stream.audio_issue"
 
 	EventDetectionNewSpecies = "detection.new_species"
 	EventDetectionOccurred   = "detection.occurred"
@@ -33,9 +35,13 @@ const (
 	EventMQTTConnected      = "integration.mqtt_connected"
 	EventMQTTDisconnected   = "integration.mqtt_disconnected"
 
-	EventDeviceStarted = "device.started"
-	EventDeviceStopped = "device.stopped"
-	EventDeviceError   = "device.error"
+	EventDeviceStarted    = "device.started"
+	EventDeviceStopped    = "device.stopped"
+	EventDeviceError      = "device.error"
+	EventDeviceAudioIssue = "device.audio_issue"
+
+	EventFailoverSwitched  = "failover.switched"
+	EventFailoverRecovered = "failover.recovered"
 )
 
 // Metric names identify threshold-based metrics.
@@ -70,6 +76,11 @@ const (
 	PropertyError          = "error"
 	PropertyPath           = "path"
 	PropertyBroker         = "broker"
+	PropertyIssue          = "issue"
+	PropertyMessage        = "message"
+	PropertyGroupName      = "group_name"
+	PropertyFromSource     = "from_source"
+	PropertyToSource       = "to_source"
 )
 
 // Action targets identify where notifications are sent.
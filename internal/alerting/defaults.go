@@ -60,6 +60,32 @@ func DefaultRules() []entities.AlertRule {
 				{Target: TargetBell, SortOrder: 0},
 			},
 		},
+		{
+			Name:        "Audio source failover",
+			Description: "Notifies when a failover group switches analysis to a backup source",
+			Enabled:     true,
+			BuiltIn:     true,
+			ObjectType:  ObjectTypeFailover,
+			TriggerType: TriggerTypeEvent,
+			EventName:   EventFailoverSwitched,
+			CooldownSec: 300,
+			Actions: []entities.AlertAction{
+				{Target: TargetBell, SortOrder: 0},
+			},
+		},
+		{
+			Name:        "Audio source recovered",
+			Description: "Notifies when a failover group switches analysis back to a recovered source",
+			Enabled:     true,
+			BuiltIn:     true,
+			ObjectType:  ObjectTypeFailover,
+			TriggerType: TriggerTypeEvent,
+			EventName:   EventFailoverRecovered,
+			CooldownSec: 300,
+			Actions: []entities.AlertAction{
+				{Target: TargetBell, SortOrder: 0},
+			},
+		},
 		{
 			Name:        "High CPU usage",
 			Description: "Notifies when CPU usage exceeds 90% for 5 minutes",
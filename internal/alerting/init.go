@@ -52,13 +52,21 @@ func Initialize(
 	eventBus.Subscribe(engine.HandleEvent)
 	SetGlobalBus(eventBus)
 
-	// Register detection bridge with the global event bus so detection events
-	// flow into the alerting engine.
+	// Register detection, audio quality and source failover bridges with the global event bus
+	// so their events flow into the alerting engine.
 	if eventBusInstance := events.GetEventBus(); eventBusInstance != nil {
 		bridge := NewDetectionAlertBridge(log)
 		if err := eventBusInstance.RegisterConsumer(bridge); err != nil {
 			log.Warn("failed to register detection alert bridge", logger.Error(err))
 		}
+		audioBridge := NewAudioQualityAlertBridge(log)
+		if err := eventBusInstance.RegisterConsumer(audioBridge); err != nil {
+			log.Warn("failed to register audio quality alert bridge", logger.Error(err))
+		}
+		failoverBridge := NewSourceFailoverAlertBridge(log)
+		if err := eventBusInstance.RegisterConsumer(failoverBridge); err != nil {
+			log.Warn("failed to register source failover alert bridge", logger.Error(err))
+		}
 	}
 
 	// Signal to the notification subsystem that the alert engine now handles
@@ -61,6 +61,7 @@ func GetSchema() Schema {
 					{Name: EventStreamConnected, Label: "Stream Connected", Properties: streamProperties()},
 					{Name: EventStreamDisconnected, Label: "Stream Disconnected", Properties: streamProperties()},
 					{Name: EventStreamError, Label: "Stream Error", Properties: streamErrorProperties()},
+					{Name: EventStreamAudioIssue, Label: "Stream Audio Quality Issue", Properties: audioIssueProperties(streamProperties())},
 				},
 			},
 			{
@@ -95,6 +96,15 @@ func GetSchema() Schema {
 					{Name: EventDeviceStarted, Label: "Device Started", Properties: deviceProperties()},
 					{Name: EventDeviceStopped, Label: "Device Stopped", Properties: deviceProperties()},
 					{Name: EventDeviceError, Label: "Device Error", Properties: deviceErrorProperties()},
+					{Name: EventDeviceAudioIssue, Label: "Device Audio Quality Issue", Properties: audioIssueProperties(deviceProperties())},
+				},
+			},
+			{
+				Name:  ObjectTypeFailover,
+				Label: "Source Failover",
+				Events: []EventSchema{
+					{Name: EventFailoverSwitched, Label: "Switched to Backup Source", Properties: failoverProperties()},
+					{Name: EventFailoverRecovered, Label: "Switched Back to Recovered Source", Properties: failoverProperties()},
 				},
 			},
 			{
@@ -166,6 +176,25 @@ func deviceErrorProperties() []PropertySchema {
 	)
 }
 
+// audioIssueProperties adds the audio quality issue properties to the
+// properties of a stream or device.
+func audioIssueProperties(source []PropertySchema) []PropertySchema {
+	return append(source,
+		PropertySchema{Name: PropertyIssue, Label: "Issue", Type: "string", Operators: stringOperators},
+		PropertySchema{Name: PropertyMessage, Label: "Message", Type: "string", Operators: stringOperators},
+		PropertySchema{Name: PropertyValue, Label: "Value", Type: "number", Operators: numericOperators},
+	)
+}
+
+func failoverProperties() []PropertySchema {
+	return []PropertySchema{
+		{Name: PropertyGroupName, Label: "Failover Group", Type: "string", Operators: stringOperators},
+		{Name: PropertyFromSource, Label: "From Source", Type: "string", Operators: stringOperators},
+		{Name: PropertyToSource, Label: "To Source", Type: "string", Operators: stringOperators},
+		{Name: PropertyMessage, Label: "Message", Type: "string", Operators: stringOperators},
+	}
+}
+
 func numericValueProperties() []PropertySchema {
 	return []PropertySchema{
 		{Name: PropertyValue, Label: "Value", Type: "number", Operators: numericOperators},
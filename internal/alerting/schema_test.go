@@ -15,7 +15,7 @@ func TestGetSchema_AllObjectTypesPresent(t *testing.T) {
 	}
 	assert.ElementsMatch(t, []string{
 		ObjectTypeStream, ObjectTypeDetection, ObjectTypeApplication,
-		ObjectTypeIntegration, ObjectTypeDevice, ObjectTypeSystem,
+		ObjectTypeIntegration, ObjectTypeDevice, ObjectTypeSystem, ObjectTypeFailover,
 	}, names)
 }
 
@@ -28,11 +28,12 @@ func TestGetSchema_AllEventsPresent(t *testing.T) {
 		}
 	}
 	expectedEvents := []string{
-		EventStreamConnected, EventStreamDisconnected, EventStreamError,
+		EventStreamConnected, EventStreamDisconnected, EventStreamError, EventStreamAudioIssue,
 		EventDetectionNewSpecies, EventDetectionOccurred,
 		EventApplicationStarted, EventApplicationStopped,
 		EventBirdWeatherFailed, EventMQTTConnected, EventMQTTDisconnected,
-		EventDeviceStarted, EventDeviceStopped, EventDeviceError,
+		EventDeviceStarted, EventDeviceStopped, EventDeviceError, EventDeviceAudioIssue,
+		EventFailoverSwitched, EventFailoverRecovered,
 	}
 	assert.ElementsMatch(t, expectedEvents, allEvents)
 }
@@ -0,0 +1,54 @@
+package alerting
+
+import (
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// SourceFailoverAlertBridge bridges the events.EventBus source failover events
+// to the alerting event bus, so that switches to a backup source and back are
+// alerted and recorded in the alert history.
+type SourceFailoverAlertBridge struct {
+	log logger.Logger
+}
+
+// NewSourceFailoverAlertBridge creates a new bridge consumer.
+func NewSourceFailoverAlertBridge(log logger.Logger) *SourceFailoverAlertBridge {
+	return &SourceFailoverAlertBridge{log: log}
+}
+
+func (b *SourceFailoverAlertBridge) Name() string {
+	return "source-failover-alert-bridge"
+}
+
+func (b *SourceFailoverAlertBridge) ProcessEvent(_ events.ErrorEvent) error {
+	return nil
+}
+
+func (b *SourceFailoverAlertBridge) ProcessBatch(_ []events.ErrorEvent) error {
+	return nil
+}
+
+func (b *SourceFailoverAlertBridge) SupportsBatching() bool {
+	return false
+}
+
+// ProcessSourceFailoverEvent publishes failover group switches to the alert event bus.
+func (b *SourceFailoverAlertBridge) ProcessSourceFailoverEvent(event events.SourceFailoverEvent) error {
+	eventName := EventFailoverSwitched
+	if event.IsRecovery() {
+		eventName = EventFailoverRecovered
+	}
+
+	TryPublish(&AlertEvent{
+		ObjectType: ObjectTypeFailover,
+		EventName:  eventName,
+		Properties: map[string]any{
+			PropertyGroupName:  event.GetGroupName(),
+			PropertyFromSource: event.GetFromSourceName(),
+			PropertyToSource:   event.GetToSourceName(),
+			PropertyMessage:    event.GetMessage(),
+		},
+	})
+	return nil
+}
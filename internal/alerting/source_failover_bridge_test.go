@@ -0,0 +1,53 @@
+package alerting
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/events"
+)
+
+func TestSourceFailoverAlertBridge(t *testing.T) {
+	bus := NewAlertEventBus()
+	defer bus.Stop()
+	SetGlobalBus(bus)
+	t.Cleanup(func() { SetGlobalBus(nil) })
+
+	var received atomic.Pointer[AlertEvent]
+	var count atomic.Int32
+	bus.Subscribe(func(event *AlertEvent) {
+		received.Store(event)
+		count.Add(1)
+	})
+
+	bridge := NewSourceFailoverAlertBridge(initTestLogger())
+
+	require.NoError(t, bridge.ProcessSourceFailoverEvent(events.NewSourceFailoverEvent(events.SourceFailoverEventData{
+		GroupName:      "Main",
+		FromSourceName: "USB mic",
+		ToSourceName:   "Pond cam",
+		Message:        "no audio from USB mic for 15s",
+	})))
+
+	require.Eventually(t, func() bool { return received.Load() != nil }, time.Second, 5*time.Millisecond)
+	got := received.Load()
+	assert.Equal(t, ObjectTypeFailover, got.ObjectType)
+	assert.Equal(t, EventFailoverSwitched, got.EventName)
+	assert.Equal(t, "Main", got.Properties[PropertyGroupName])
+	assert.Equal(t, "USB mic", got.Properties[PropertyFromSource])
+	assert.Equal(t, "Pond cam", got.Properties[PropertyToSource])
+
+	require.NoError(t, bridge.ProcessSourceFailoverEvent(events.NewSourceFailoverEvent(events.SourceFailoverEventData{
+		GroupName:      "Main",
+		FromSourceName: "Pond cam",
+		ToSourceName:   "USB mic",
+		Recovery:       true,
+	})))
+	require.Eventually(t, func() bool { return count.Load() == 2 }, time.Second, 5*time.Millisecond)
+	got = received.Load()
+	assert.Equal(t, EventFailoverRecovered, got.EventName)
+	assert.Equal(t, "USB mic", got.Properties[PropertyToSource])
+}
@@ -0,0 +1,66 @@
+package analysis
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/diskmanager"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// archiveSyncInterval is how often recording is stopped for sources no longer
+// selected for the archive.
+const archiveSyncInterval = 30 * time.Second
+
+// startArchiveMonitor starts the rolling retention of the continuous recording
+// archive. It stops recording of sources deselected at runtime and finishes the
+// open archive files on shutdown. Recording itself is started by the capture
+// routines when the archive is enabled for a source.
+func startArchiveMonitor(wg *sync.WaitGroup, quitChan chan struct{}) {
+	wg.Go(func() {
+		archiveMonitor(quitChan)
+	})
+}
+
+// archiveMonitor runs archive cleanup at the clip cleanup interval and syncs the
+// recorders with the settings until quitChan is closed.
+func archiveMonitor(quitChan chan struct{}) {
+	defer myaudio.StopArchiveRecording()
+
+	checkInterval := conf.Setting().Realtime.Audio.Export.Retention.CheckInterval
+	if checkInterval <= 0 {
+		checkInterval = conf.DefaultCleanupCheckInterval
+	}
+	ticker := time.NewTicker(time.Duration(checkInterval) * time.Minute)
+	defer ticker.Stop()
+	syncTicker := time.NewTicker(archiveSyncInterval)
+	defer syncTicker.Stop()
+
+	for {
+		select {
+		case <-quitChan:
+			return
+		case <-syncTicker.C:
+			myaudio.SyncArchiveRecording()
+		case <-ticker.C:
+			// Settings are read on every run so enabling the archive takes effect without a restart
+			settings := conf.Setting().Realtime.Audio.Archive
+			if !settings.Enabled {
+				continue
+			}
+			result := diskmanager.ArchiveCleanup(quitChan, &settings)
+			if result.Err != nil {
+				GetLogger().Error("archive cleanup failed",
+					logger.Error(result.Err),
+					logger.String("operation", "archive_cleanup"))
+			} else if result.ClipsRemoved > 0 {
+				GetLogger().Info("archive cleanup completed",
+					logger.Int("files_removed", result.ClipsRemoved),
+					logger.Int("disk_utilization_percent", result.DiskUtilization),
+					logger.String("operation", "archive_cleanup"))
+			}
+		}
+	}
+}
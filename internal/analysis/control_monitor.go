@@ -296,6 +296,10 @@ func (cm *ControlMonitor) handleReconfigureStreams() {
 		if registry != nil {
 			for _, stream := range settings.Realtime.RTSP.Streams {
 				if streamSource := registry.GetOrCreateSource(stream.URL, myaudio.StreamTypeToSourceType(stream.Type)); streamSource != nil {
+					// Apply renames to already running sources
+					if stream.Name != "" {
+						registry.UpdateDisplayName(streamSource.ID, stream.Name)
+					}
 					sources = append(sources, streamSource.ID)
 				} else {
 					GetLogger().Warn("Failed to get stream source ID from registry during reconfiguration",
@@ -318,6 +322,16 @@ func (cm *ControlMonitor) handleReconfigureStreams() {
 			GetLogger().Warn("Registry not available during stream reconfiguration, skipping audio source")
 		}
 	}
+	if len(settings.Realtime.Audio.Devices) > 0 {
+		deviceSources, err := myaudio.RegisterDeviceSources(settings)
+		if err != nil {
+			GetLogger().Warn("Failed to register some audio device sources during stream reconfiguration",
+				logger.Error(err))
+		}
+		for _, source := range deviceSources {
+			sources = append(sources, source.ID)
+		}
+	}
 
 	// Update the analysis buffer monitors
 	if err := cm.bufferManager.UpdateMonitors(sources); err != nil {
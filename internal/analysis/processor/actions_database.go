@@ -135,6 +135,9 @@ func (a *DatabaseAction) ExecuteContext(ctx context.Context, _ any) error {
 		a.DetectionCtx.NoteID.Store(uint64(a.Result.ID))
 	}
 
+	// Link the detections at other grouped sources that were merged into this one
+	a.saveDetectionLinks(ctx)
+
 	// After successful save, publish detection event for new species
 	a.publishNewSpeciesDetectionEvent(isNewSpecies, daysSinceFirstSeen)
 
@@ -192,6 +195,7 @@ func (a *DatabaseAction) ExecuteContext(ctx context.Context, _ any) error {
 				NoteID:        a.Result.ID,
 				PreRenderer:   a.PreRenderer,
 				CorrelationID: a.CorrelationID,
+				Metadata:      a.clipMetadata(),
 			}
 
 			if err := saveAudioAction.Execute(ctx, nil); err != nil {
@@ -351,6 +355,62 @@ func (a *DatabaseAction) publishNewSpeciesDetectionEvent(isNewSpecies bool, days
 	}
 }
 
+// saveDetectionLinks stores the detections merged into the saved detection.
+// Link errors are logged but not returned, the detection itself is saved.
+func (a *DatabaseAction) saveDetectionLinks(ctx context.Context) {
+	if len(a.Result.MergedSources) == 0 {
+		return
+	}
+	store, ok := a.Ds.(datastore.DetectionLinkStore)
+	if !ok {
+		return
+	}
+
+	links := make([]datastore.DetectionLinkRecord, 0, len(a.Result.MergedSources))
+	for i := range a.Result.MergedSources {
+		merged := &a.Result.MergedSources[i]
+		links = append(links, datastore.DetectionLinkRecord{
+			SourceURI:  merged.AudioSource.SafeString,
+			SourceName: merged.AudioSource.DisplayName,
+			NodeName:   a.Result.SourceNode,
+			Confidence: merged.Confidence,
+			BeginTime:  merged.BeginTime,
+		})
+	}
+	if err := store.SaveDetectionLinks(ctx, a.Result.ID, links); err != nil {
+		GetLogger().Warn("Failed to save merged detection links",
+			logger.String("component", "analysis.processor.actions"),
+			logger.String("detection_id", a.CorrelationID),
+			logger.Error(err),
+			logger.String("species", a.Result.Species.CommonName),
+			logger.Int("merged_count", len(links)),
+			logger.String("operation", "database_save_links"))
+	}
+}
+
+// clipMetadata returns the detection metadata written into the audio clip.
+func (a *DatabaseAction) clipMetadata() *myaudio.ClipMetadata {
+	source := a.Result.AudioSource.DisplayName
+	if source == "" {
+		source = a.Result.AudioSource.SafeString
+	}
+	model := strings.TrimSpace(a.Result.Model.Name + " " + a.Result.Model.Version)
+	if a.Result.Model.Variant != "" && a.Result.Model.Variant != detection.DefaultModelVariant {
+		model += " (" + a.Result.Model.Variant + ")"
+	}
+	return &myaudio.ClipMetadata{
+		CommonName:     a.Result.Species.CommonName,
+		ScientificName: a.Result.Species.ScientificName,
+		Confidence:     a.Result.Confidence,
+		Model:          model,
+		Source:         source,
+		Station:        a.Settings.Main.Name,
+		RecordedAt:     a.Result.BeginTime,
+		Latitude:       a.Result.Latitude,
+		Longitude:      a.Result.Longitude,
+	}
+}
+
 // Execute saves the audio clip to a file
 func (a *SaveAudioAction) Execute(_ context.Context, _ any) error {
 	// Get the full path by joining the export path with the relative clip name
@@ -363,11 +423,11 @@ func (a *SaveAudioAction) Execute(_ context.Context, _ any) error {
 	}
 
 	if a.Settings.Realtime.Audio.Export.Type == "wav" {
-		if err := myaudio.SavePCMDataToWAV(outputPath, a.pcmData); err != nil {
+		if err := myaudio.SavePCMDataToWAVWithMetadata(outputPath, a.pcmData, a.Metadata); err != nil {
 			return err
 		}
 	} else {
-		if err := myaudio.ExportAudioWithFFmpeg(a.pcmData, outputPath, &a.Settings.Realtime.Audio); err != nil {
+		if err := myaudio.ExportAudioWithFFmpegMetadata(a.pcmData, outputPath, &a.Settings.Realtime.Audio, a.Metadata); err != nil {
 			return err
 		}
 	}
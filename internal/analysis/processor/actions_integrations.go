@@ -12,6 +12,7 @@ import (
 
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/hub"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/logger"
 	"github.com/tphakala/birdnet-go/internal/notification"
@@ -292,6 +293,104 @@ func (a *MqttAction) Execute(_ context.Context, data any) error {
 	return nil
 }
 
+// Execute pushes the detection to the hub, retrying network failures and
+// server errors within the deadline of ctx.
+// Clip upload failures are logged but do not fail the action, so the
+// detection itself is never held back by a missing or oversized clip.
+func (a *HubPushAction) Execute(ctx context.Context, _ any) error {
+	var detectionID uint
+	if a.DetectionCtx != nil {
+		detectionID = uint(a.DetectionCtx.NoteID.Load())
+	}
+	if detectionID == 0 {
+		// Without a local ID the hub cannot deduplicate retries
+		return errors.Newf("detection has no database ID, cannot push to hub").
+			Component("analysis.processor").
+			Category(errors.CategoryProcessing).
+			Context("operation", "hub_push").
+			Context("retryable", false).
+			Build()
+	}
+
+	note := datastore.NoteFromResult(&a.Result)
+	note.ID = detectionID
+	payload := hub.FromNote(&note, datastore.AdditionalResultsToDatastoreResults(a.Results))
+
+	var lastErr error
+	for attempt := 1; attempt <= HubPushAttempts; attempt++ {
+		if attempt > 1 {
+			backoff := time.Duration(attempt-1) * time.Second
+			if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < backoff {
+				// No time left for another attempt before the action times out
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+		}
+
+		attemptCtx, cancel := hubAttemptContext(ctx, HubPushAttempts-attempt+1)
+		resp, err := a.HubClient.PushDetection(attemptCtx, payload)
+		cancel()
+		if err == nil {
+			GetLogger().Debug("Pushed detection to hub",
+				logger.String("detection_id", a.CorrelationID),
+				logger.Uint64("note_id", uint64(detectionID)),
+				logger.Uint64("hub_id", uint64(resp.ID)),
+				logger.Bool("duplicate", resp.Duplicate),
+				logger.String("operation", "hub_push_success"))
+			a.uploadClip(ctx, detectionID)
+			return nil
+		}
+		lastErr = err
+		if !hub.IsRetryable(err) || ctx.Err() != nil {
+			break
+		}
+	}
+
+	GetLogger().Error("Failed to push detection to hub",
+		logger.String("component", "analysis.processor.actions"),
+		logger.String("detection_id", a.CorrelationID),
+		logger.Uint64("note_id", uint64(detectionID)),
+		logger.Error(privacy.WrapError(lastErr)),
+		logger.String("species", a.Result.Species.CommonName),
+		logger.String("operation", "hub_push"))
+	return lastErr
+}
+
+// hubAttemptContext bounds a hub push attempt to its share of the time left
+// before the deadline of ctx, so that a hanging request leaves time for the
+// remaining attempts.
+func hubAttemptContext(ctx context.Context, attemptsLeft int) (context.Context, context.CancelFunc) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, time.Until(deadline)/time.Duration(attemptsLeft))
+}
+
+// uploadClip uploads the detection's clip when clip forwarding is enabled.
+func (a *HubPushAction) uploadClip(ctx context.Context, detectionID uint) {
+	if !a.Settings.Hub.Upstream.SendClips || a.Result.ClipName == "" {
+		return
+	}
+	if a.DetectionCtx != nil && a.DetectionCtx.AudioExportFailed.Load() {
+		return
+	}
+
+	clipPath := filepath.Join(a.Settings.Realtime.Audio.Export.Path, a.Result.ClipName)
+	if err := a.HubClient.UploadClip(ctx, detectionID, clipPath); err != nil {
+		GetLogger().Warn("Failed to upload clip to hub",
+			logger.String("component", "analysis.processor.actions"),
+			logger.String("detection_id", a.CorrelationID),
+			logger.String("clip_name", a.Result.ClipName),
+			logger.Error(privacy.WrapError(err)),
+			logger.String("operation", "hub_clip_upload"))
+	}
+}
+
 // Execute updates the range filter species list, this is run every day
 // Note: The ShouldUpdateRangeFilterToday() check in processor.go ensures this action
 // is only created once per day, preventing duplicate concurrent updates (GitHub issue #1357)
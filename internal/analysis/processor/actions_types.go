@@ -16,8 +16,10 @@ import (
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/detection"
+	"github.com/tphakala/birdnet-go/internal/hub"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/mqtt"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
 )
 
 // Timeout and interval constants
@@ -35,6 +37,10 @@ const (
 	// MQTTPublishTimeout is the timeout for MQTT publish operations
 	MQTTPublishTimeout = 10 * time.Second
 
+	// HubPushAttempts is how many times a detection push to the hub is attempted.
+	// Retries are safe because the hub deduplicates by node and detection ID.
+	HubPushAttempts = 3
+
 	// CompositeActionTimeout is the default timeout for each action in a composite action
 	// This is generous to accommodate slow hardware (e.g., Raspberry Pi with SD cards)
 	CompositeActionTimeout = 30 * time.Second
@@ -107,7 +113,8 @@ type SaveAudioAction struct {
 	PreRenderer   PreRendererSubmit // Injected from processor
 	EventTracker  *EventTracker
 	Description   string
-	CorrelationID string // Detection correlation ID for log tracking
+	CorrelationID string                // Detection correlation ID for log tracking
+	Metadata      *myaudio.ClipMetadata // Detection metadata written into the clip, nil for none
 }
 
 // PreRenderJob represents a spectrogram pre-rendering task.
@@ -163,6 +170,19 @@ type MqttAction struct {
 	mu             sync.Mutex // Protect concurrent access to Result
 }
 
+// HubPushAction pushes a saved detection, and optionally its clip, to a hub.
+// It runs after DatabaseAction because the local detection ID identifies the
+// detection on the hub.
+type HubPushAction struct {
+	Settings      *conf.Settings
+	Result        detection.Result // Domain model (single source of truth)
+	Results       []detection.AdditionalResult
+	HubClient     *hub.Client
+	DetectionCtx  *DetectionContext // Shared context from DatabaseAction
+	Description   string
+	CorrelationID string // Detection correlation ID for log tracking
+}
+
 type UpdateRangeFilterAction struct {
 	Bn          *birdnet.BirdNET
 	Settings    *conf.Settings
@@ -260,6 +280,14 @@ func (a *MqttAction) GetDescription() string {
 	return "Publish detection to MQTT"
 }
 
+// GetDescription returns a human-readable description of the HubPushAction
+func (a *HubPushAction) GetDescription() string {
+	if a.Description != "" {
+		return a.Description
+	}
+	return "Push detection to hub"
+}
+
 // GetDescription returns a human-readable description of the UpdateRangeFilterAction
 func (a *UpdateRangeFilterAction) GetDescription() string {
 	if a.Description != "" {
@@ -0,0 +1,78 @@
+// hub_push_action_test.go - Tests for HubPushAction.Execute()
+//
+// These tests verify that HubPushAction retries only failures that may pass
+// on another attempt, and only within the deadline of the action.
+package processor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/detection"
+	"github.com/tphakala/birdnet-go/internal/hub"
+)
+
+// newTestHubPushAction returns a push action for a saved detection and the
+// number of requests received by a hub answering with status.
+func newTestHubPushAction(t *testing.T, status int) (*HubPushAction, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.WriteHeader(status)
+		if status == http.StatusCreated {
+			_, _ = w.Write([]byte(`{"id":7}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := hub.NewClient(&conf.HubUpstreamSettings{URL: server.URL, Token: "node-token"})
+	require.NoError(t, err)
+	detectionCtx := &DetectionContext{}
+	detectionCtx.NoteID.Store(42)
+	return &HubPushAction{
+		Settings:     &conf.Settings{},
+		Result:       detection.Result{Species: detection.Species{CommonName: "Eurasian Blackbird", ScientificName: "Turdus merula"}},
+		HubClient:    client,
+		DetectionCtx: detectionCtx,
+	}, &requests
+}
+
+func TestHubPushAction_Success(t *testing.T) {
+	t.Parallel()
+
+	action, requests := newTestHubPushAction(t, http.StatusCreated)
+	require.NoError(t, action.Execute(t.Context(), nil))
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestHubPushAction_RejectedNotRetried(t *testing.T) {
+	t.Parallel()
+
+	for _, status := range []int{http.StatusBadRequest, http.StatusUnauthorized, http.StatusConflict} {
+		action, requests := newTestHubPushAction(t, status)
+		require.Error(t, action.Execute(t.Context(), nil))
+		assert.Equal(t, int32(1), requests.Load(), "status %d is not retried", status)
+	}
+}
+
+func TestHubPushAction_RetriesWithinDeadline(t *testing.T) {
+	t.Parallel()
+
+	action, requests := newTestHubPushAction(t, http.StatusServiceUnavailable)
+	ctx, cancel := context.WithTimeout(t.Context(), 2500*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	require.Error(t, action.Execute(ctx, nil))
+	// The second backoff would outlast the deadline, so the third attempt is skipped
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Less(t, time.Since(start), 2500*time.Millisecond)
+}
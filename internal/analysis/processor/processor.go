@@ -22,6 +22,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/detection"
+	"github.com/tphakala/birdnet-go/internal/hub"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
 	"github.com/tphakala/birdnet-go/internal/logger"
 	"github.com/tphakala/birdnet-go/internal/mqtt"
@@ -56,6 +57,7 @@ type Processor struct {
 	bwClientMutex       sync.RWMutex // Mutex to protect BwClient access
 	MqttClient          mqtt.Client
 	mqttMutex           sync.RWMutex // Mutex to protect MQTT client access
+	HubClient           *hub.Client  // Client for pushing detections to a hub, nil when upstream is disabled
 	BirdImageCache      *imageprovider.BirdImageCache
 	EventTracker        *EventTracker
 	eventTrackerMu      sync.RWMutex            // Mutex to protect EventTracker access
@@ -69,7 +71,7 @@ type Processor struct {
 	Metrics             *observability.Metrics
 	DynamicThresholds   map[string]*DynamicThreshold
 	thresholdsMutex     sync.RWMutex // Mutex to protect access to DynamicThresholds
-	pendingDetections   map[string]PendingDetection
+	pendingDetections   map[pendingKey]PendingDetection
 	pendingMutex        sync.Mutex // Mutex to protect access to pendingDetections
 	lastDogDetectionLog map[string]time.Time
 	dogDetectionMutex   sync.Mutex
@@ -109,6 +111,7 @@ type PendingDetection struct {
 	Detection     Detections // The detection data
 	Confidence    float64    // Confidence level of the detection
 	Source        string     // Audio source of the detection, RTSP URL or audio card name
+	Group         string     // Source group of the audio source, empty if it is in none
 	FirstDetected time.Time  // Time the detection was first detected
 	LastUpdated   time.Time  // Last time this detection was updated
 	FlushDeadline time.Time  // Deadline by which the detection must be processed
@@ -311,6 +314,23 @@ func (p *Processor) initBirdWeatherClient(settings *conf.Settings) {
 	p.SetBwClient(bwClient)
 }
 
+// initHubClient initializes the hub client if pushing to a hub is enabled.
+func (p *Processor) initHubClient(settings *conf.Settings) {
+	if !settings.Hub.Upstream.Enabled {
+		return
+	}
+
+	client, err := hub.NewClient(&settings.Hub.Upstream)
+	if err != nil {
+		GetLogger().Error("Failed to create hub client",
+			logger.Error(err),
+			logger.String("operation", "hub_client_init"),
+			logger.String("integration", "hub"))
+		return
+	}
+	p.HubClient = client
+}
+
 // initDynamicThresholds loads and starts persistence for dynamic thresholds if enabled.
 func (p *Processor) initDynamicThresholds(settings *conf.Settings) {
 	if !settings.Realtime.DynamicThreshold.Enabled {
@@ -355,7 +375,7 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 		LastDogDetection:    make(map[string]time.Time),
 		LastHumanDetection:  make(map[string]time.Time),
 		DynamicThresholds:   make(map[string]*DynamicThreshold),
-		pendingDetections:   make(map[string]PendingDetection),
+		pendingDetections:   make(map[pendingKey]PendingDetection),
 		lastDogDetectionLog: make(map[string]time.Time),
 		controlChan:         make(chan string, 10),  // Buffered channel to prevent blocking
 		JobQueue:            jobqueue.NewJobQueue(), // Initialize the job queue
@@ -404,6 +424,9 @@ func New(settings *conf.Settings, ds datastore.Interface, bn *birdnet.BirdNET, m
 	// Initialize MQTT client if enabled in settings
 	p.initializeMQTT(settings)
 
+	// Initialize hub client if this node reports to a hub
+	p.initHubClient(settings)
+
 	// Start the job queue
 	p.JobQueue.Start()
 
@@ -464,59 +487,79 @@ func (p *Processor) processDetections(item birdnet.Results) {
 	// Log processing results with deduplication to prevent spam
 	p.logDetectionResults(item.Source.ID, len(item.Results), len(detectionResults))
 
+	// Detections at grouped sources wait at least the merge window of the
+	// group, so detections of the same bird at the other sources can join them
+	group := p.sourceGroupOf(item.Source)
+	flushWindow := detectionWindow
+	if group != nil {
+		flushWindow = max(detectionWindow, group.WindowDuration())
+	}
+
 	for i := range detectionResults {
-		det := detectionResults[i]
-		commonName := strings.ToLower(det.Result.Species.CommonName)
-		confidence := det.Result.Confidence
-
-		// Lock the mutex to ensure thread-safe access to shared resources
-		p.pendingMutex.Lock()
-
-		if existing, exists := p.pendingDetections[commonName]; exists {
-			// Update the existing detection if it's already in pendingDetections map
-			oldConfidence := existing.Confidence
-			if confidence > existing.Confidence {
-				existing.Detection = det
-				existing.Confidence = confidence
-				existing.Source = item.Source.ID
-				existing.LastUpdated = time.Now()
-				// Add structured logging for confidence update
-				GetLogger().Debug("Updated pending detection with higher confidence",
-					logger.String("species", commonName),
-					logger.Float64("old_confidence", oldConfidence),
-					logger.Float64("new_confidence", confidence),
-					logger.Int("count", existing.Count+1),
-					logger.String("operation", "update_pending_detection"))
-			}
-			existing.Count++
-			p.pendingDetections[commonName] = existing
-		} else {
-			// Create a new pending detection if it doesn't exist
-			// Add structured logging for new pending detection
-			GetLogger().Info("Created new pending detection",
+		p.addPendingDetection(&item, detectionResults[i], group, flushWindow)
+	}
+}
+
+// addPendingDetection holds a detection until its flush deadline, updating the
+// pending detection of the species with new or higher-confidence instances.
+//
+//nolint:gocritic // hugeParam: Pass by value is intentional - avoids pointer dereferencing in hot path
+func (p *Processor) addPendingDetection(item *birdnet.Results, det Detections, group *conf.SourceGroupSettings, flushWindow time.Duration) {
+	commonName := strings.ToLower(det.Result.Species.CommonName)
+	confidence := det.Result.Confidence
+	key := pendingKeyOf(item.Source.ID, commonName, group)
+
+	// Lock the mutex to ensure thread-safe access to shared resources
+	p.pendingMutex.Lock()
+
+	if existing, exists := p.pendingDetections[key]; exists {
+		// Update the existing detection if it's already in pendingDetections map
+		oldConfidence := existing.Confidence
+		if confidence > existing.Confidence {
+			existing.Detection = det
+			existing.Confidence = confidence
+			existing.Source = item.Source.ID
+			existing.LastUpdated = time.Now()
+			// Add structured logging for confidence update
+			GetLogger().Debug("Updated pending detection with higher confidence",
 				logger.String("species", commonName),
-				logger.Float64("confidence", confidence),
-				logger.String("source", item.Source.DisplayName),
-				logger.Time("flush_deadline", time.Now().Add(detectionWindow)),
-				logger.String("operation", "create_pending_detection"))
-			p.pendingDetections[commonName] = PendingDetection{
-				Detection:     det,
-				Confidence:    confidence,
-				Source:        item.Source.ID,
-				FirstDetected: item.StartTime,
-				// FlushDeadline is relative to NOW (not startTime) to ensure it's always in the future.
-				// startTime is backdated for audio extraction, but FlushDeadline needs to be a future deadline.
-				FlushDeadline: time.Now().Add(detectionWindow),
-				Count:         1,
-			}
+				logger.Float64("old_confidence", oldConfidence),
+				logger.Float64("new_confidence", confidence),
+				logger.Int("count", existing.Count+1),
+				logger.String("operation", "update_pending_detection"))
+		}
+		existing.Count++
+		p.pendingDetections[key] = existing
+	} else {
+		// Create a new pending detection if it doesn't exist
+		// Add structured logging for new pending detection
+		GetLogger().Info("Created new pending detection",
+			logger.String("species", commonName),
+			logger.Float64("confidence", confidence),
+			logger.String("source", item.Source.DisplayName),
+			logger.Time("flush_deadline", time.Now().Add(flushWindow)),
+			logger.String("operation", "create_pending_detection"))
+		pending := PendingDetection{
+			Detection:     det,
+			Confidence:    confidence,
+			Source:        item.Source.ID,
+			FirstDetected: item.StartTime,
+			// FlushDeadline is relative to NOW (not startTime) to ensure it's always in the future.
+			// startTime is backdated for audio extraction, but FlushDeadline needs to be a future deadline.
+			FlushDeadline: time.Now().Add(flushWindow),
+			Count:         1,
 		}
+		if group != nil {
+			pending.Group = group.Name
+		}
+		p.pendingDetections[key] = pending
+	}
 
-		// Update the dynamic threshold for this species if enabled
-		p.updateDynamicThreshold(commonName, confidence)
+	// Update the dynamic threshold for this species if enabled
+	p.updateDynamicThreshold(commonName, confidence)
 
-		// Unlock the mutex to allow other goroutines to access shared resources
-		p.pendingMutex.Unlock()
-	}
+	// Unlock the mutex to allow other goroutines to access shared resources
+	p.pendingMutex.Unlock()
 }
 
 // processResults processes the results from the BirdNET prediction and returns a list of detections.
@@ -678,8 +721,13 @@ func (p *Processor) createDetection(item birdnet.Results, result datastore.Resul
 	occurrence := p.Bn.GetSpeciesOccurrenceAtTime(result.Species, item.StartTime)
 
 	// Compute detection time once to ensure Result has consistent timestamp
-	// This prevents date mismatch around midnight when time.Now() would be called separately
-	detectionTime := time.Now().Add(-detection.DetectionTimeOffset)
+	// This prevents date mismatch around midnight when time.Now() would be called separately.
+	// The capture time of the audio is preferred, it stays correct when a stream delivers late.
+	audioTime := item.AudioTime
+	if audioTime.IsZero() {
+		audioTime = time.Now()
+	}
+	detectionTime := audioTime.Add(-detection.DetectionTimeOffset)
 
 	// Create the detection.Result
 	detectionResult := p.createDetectionResult(
@@ -690,6 +738,12 @@ func (p *Processor) createDetection(item birdnet.Results, result datastore.Resul
 		item.Source, clipName,
 		item.ElapsedTime, occurrence)
 
+	// Microphone arrays estimate the bearing of the analyzed audio
+	chunkDuration := time.Duration(len(item.PCMdata)/(conf.BitDepth/8)) * time.Second / conf.SampleRate
+	if bearing, ok := myaudio.EstimateBearing(item.Source.ID, audioTime.Add(-chunkDuration), audioTime); ok {
+		detectionResult.Bearing = &bearing
+	}
+
 	// Convert additional results from datastore.Results to detection.AdditionalResult
 	additionalResults := p.convertToAdditionalResults(item.Results)
 
@@ -1126,11 +1180,20 @@ func (p *Processor) flushPendingDetections(minDetections int) (pendingCount, flu
 
 	pendingCount = len(p.pendingDetections)
 
-	for species := range p.pendingDetections {
-		item := p.pendingDetections[species]
-		if !now.After(item.FlushDeadline) {
+	for key := range p.pendingDetections {
+		// Detections merged into another one earlier in this cycle are gone
+		item, exists := p.pendingDetections[key]
+		if !exists || !now.After(item.FlushDeadline) {
 			continue
 		}
+		species := key.species
+
+		if merged := p.groupDetectionsOf(key, &item); merged != nil {
+			if p.flushMergedDetections(merged, species, minDetections) {
+				flushedCount++
+				continue
+			}
+		}
 
 		if shouldDiscard, reason := p.shouldDiscardDetection(&item, minDetections); shouldDiscard {
 			GetLogger().Info("discarding detection",
@@ -1139,7 +1202,7 @@ func (p *Processor) flushPendingDetections(minDetections int) (pendingCount, flu
 				logger.String("reason", reason),
 				logger.Int("count", item.Count),
 				logger.String("operation", "discard_detection"))
-			delete(p.pendingDetections, species)
+			delete(p.pendingDetections, key)
 			continue
 		}
 
@@ -1152,7 +1215,7 @@ func (p *Processor) flushPendingDetections(minDetections int) (pendingCount, flu
 			logger.String("operation", "flush_detection"))
 
 		p.processApprovedDetection(&item, species)
-		delete(p.pendingDetections, species)
+		delete(p.pendingDetections, key)
 		flushedCount++
 	}
 
@@ -1398,12 +1461,23 @@ func (p *Processor) getDefaultActions(det *Detections) []Action {
 	if mqttAction != nil {
 		sequentialActions = append(sequentialActions, mqttAction)
 	}
+	// Hub push needs the database ID, so it only runs after a database save
+	if databaseAction != nil && p.HubClient != nil {
+		sequentialActions = append(sequentialActions, &HubPushAction{
+			Settings:      p.Settings,
+			Result:        det.Result,
+			Results:       det.Results,
+			HubClient:     p.HubClient,
+			DetectionCtx:  detectionCtx,
+			CorrelationID: det.CorrelationID,
+		})
+	}
 
 	if len(sequentialActions) > 1 {
 		// Create composite action for sequential execution with shared context
 		compositeAction := &CompositeAction{
 			Actions:       sequentialActions,
-			Description:   "Database save, SSE broadcast, and MQTT publish (sequential)",
+			Description:   "Database save, SSE broadcast, MQTT publish, and hub push (sequential)",
 			CorrelationID: det.CorrelationID,
 		}
 		actions = append(actions, compositeAction)
@@ -1805,18 +1879,20 @@ func (p *Processor) initPreRenderer() {
 			return
 		}
 
-		// Validate Sox binary is configured and exists
-		if p.Settings.Realtime.Audio.SoxPath == "" {
-			GetLogger().Error("Sox binary not configured, disabling pre-rendering",
-				logger.String("operation", "prerenderer_init"))
-			return
-		}
-		if _, err := exec.LookPath(p.Settings.Realtime.Audio.SoxPath); err != nil {
-			GetLogger().Error("Sox binary not found, disabling pre-rendering",
-				logger.String("path", p.Settings.Realtime.Audio.SoxPath),
-				logger.Error(err),
-				logger.String("operation", "prerenderer_init"))
-			return
+		// Validate Sox binary is configured and exists, the native renderer needs none
+		if !p.Settings.Realtime.Dashboard.Spectrogram.UseNativeRenderer() {
+			if p.Settings.Realtime.Audio.SoxPath == "" {
+				GetLogger().Error("Sox binary not configured, disabling pre-rendering",
+					logger.String("operation", "prerenderer_init"))
+				return
+			}
+			if _, err := exec.LookPath(p.Settings.Realtime.Audio.SoxPath); err != nil {
+				GetLogger().Error("Sox binary not found, disabling pre-rendering",
+					logger.String("path", p.Settings.Realtime.Audio.SoxPath),
+					logger.Error(err),
+					logger.String("operation", "prerenderer_init"))
+				return
+			}
 		}
 
 		// Create SecureFS for path validation
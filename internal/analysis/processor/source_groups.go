@@ -0,0 +1,147 @@
+// source_groups.go: merging detections of the same bird at grouped audio sources
+package processor
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/detection"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// pendingKey identifies a pending detection: a species heard at a grouped
+// audio source, or a species heard at any ungrouped source.
+type pendingKey struct {
+	source  string // source ID, empty for ungrouped sources
+	species string // lowercase common name
+}
+
+// pendingKeyOf returns the key of a detection of species at a source. Grouped
+// sources hold their own detections until they are merged, detections at
+// ungrouped sources share one pending detection per species.
+func pendingKeyOf(sourceID, species string, group *conf.SourceGroupSettings) pendingKey {
+	if group == nil {
+		return pendingKey{species: species}
+	}
+	return pendingKey{source: sourceID, species: species}
+}
+
+// sourceGroupOf returns the configured source group of an audio source, or
+// nil if the source is in none. Group entries match the display name of the
+// source, its ID, or its connection string with or without credentials.
+func (p *Processor) sourceGroupOf(source datastore.AudioSource) *conf.SourceGroupSettings {
+	groups := p.Settings.Realtime.SourceGroups
+	for i := range groups {
+		for _, entry := range groups[i].Sources {
+			if sourceMatchesEntry(source, strings.TrimSpace(entry)) {
+				return &groups[i]
+			}
+		}
+	}
+	return nil
+}
+
+// sourceMatchesEntry reports whether a source group entry names source.
+func sourceMatchesEntry(source datastore.AudioSource, entry string) bool {
+	if entry == "" {
+		return false
+	}
+	if strings.EqualFold(entry, source.DisplayName) || entry == source.ID || entry == source.SafeString {
+		return true
+	}
+	// Stream URLs with credentials are registered by their full connection string
+	if registry := myaudio.GetRegistry(); registry != nil {
+		if registered, exists := registry.GetSourceByConnection(entry); exists {
+			return registered.ID == source.ID
+		}
+	}
+	return false
+}
+
+// groupDetectionsOf returns the key of item followed by the keys of pending
+// detections of the same species at other sources of its source group that
+// started within the merge window of item, or nil if there are none.
+// The caller must hold pendingMutex.
+func (p *Processor) groupDetectionsOf(key pendingKey, item *PendingDetection) []pendingKey {
+	if item.Group == "" {
+		return nil
+	}
+	var group *conf.SourceGroupSettings
+	for i := range p.Settings.Realtime.SourceGroups {
+		if p.Settings.Realtime.SourceGroups[i].Name == item.Group {
+			group = &p.Settings.Realtime.SourceGroups[i]
+			break
+		}
+	}
+	if group == nil {
+		// The group was removed from the configuration while the detection was pending
+		return nil
+	}
+
+	window := group.WindowDuration()
+	keys := []pendingKey{key}
+	for otherKey := range p.pendingDetections {
+		other := p.pendingDetections[otherKey]
+		if otherKey == key || otherKey.species != key.species || other.Group != item.Group {
+			continue
+		}
+		if gap := other.FirstDetected.Sub(item.FirstDetected); gap <= window && gap >= -window {
+			keys = append(keys, otherKey)
+		}
+	}
+	if len(keys) == 1 {
+		return nil
+	}
+	return keys
+}
+
+// flushMergedDetections approves the detection with the highest confidence of
+// the pending detections of a species at grouped sources, linking the other
+// approved detections to it, and removes them all. It returns false without
+// changes if none of the detections would be approved on its own.
+// The caller must hold pendingMutex.
+func (p *Processor) flushMergedDetections(keys []pendingKey, species string, minDetections int) bool {
+	approved := make([]PendingDetection, 0, len(keys))
+	for _, key := range keys {
+		item := p.pendingDetections[key]
+		if shouldDiscard, _ := p.shouldDiscardDetection(&item, minDetections); !shouldDiscard {
+			approved = append(approved, item)
+		}
+	}
+	if len(approved) == 0 {
+		return false
+	}
+
+	slices.SortStableFunc(approved, func(a, b PendingDetection) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+	best := approved[0]
+	best.Detection.Result.MergedSources = make([]detection.MergedSource, 0, len(approved)-1)
+	for i := range approved[1:] {
+		other := &approved[i+1]
+		best.Detection.Result.MergedSources = append(best.Detection.Result.MergedSources, detection.MergedSource{
+			AudioSource: other.Detection.Result.AudioSource,
+			Confidence:  other.Confidence,
+			BeginTime:   other.FirstDetected,
+		})
+	}
+
+	GetLogger().Info("merging detections at grouped sources",
+		logger.String("species", species),
+		logger.String("group", best.Group),
+		logger.String("source", p.getDisplayNameForSource(best.Source)),
+		logger.Float64("confidence", best.Confidence),
+		logger.Int("merged_count", len(approved)-1),
+		logger.Int("pending_count", len(keys)),
+		logger.String("operation", "merge_group_detections"))
+
+	p.processApprovedDetection(&best, species)
+	for _, key := range keys {
+		delete(p.pendingDetections, key)
+	}
+	return true
+}
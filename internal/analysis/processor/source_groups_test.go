@@ -0,0 +1,124 @@
+package processor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/birdnet"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestSourceGroupOf(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.SourceGroups = []conf.SourceGroupSettings{
+		{Name: "Garden", Sources: []string{"front yard camera", "rtsp://back.local/stream"}},
+		{Name: "Pond", Sources: []string{"hw:1,0", "pond_mic"}},
+	}
+	p := &Processor{Settings: settings}
+
+	tests := []struct {
+		name   string
+		source datastore.AudioSource
+		want   string
+	}{
+		{name: "display name ignores case", source: datastore.AudioSource{ID: "rtsp_1", DisplayName: "Front Yard Camera"}, want: "Garden"},
+		{name: "sanitized URL", source: datastore.AudioSource{ID: "rtsp_2", SafeString: "rtsp://back.local/stream", DisplayName: "Back"}, want: "Garden"},
+		{name: "source ID", source: datastore.AudioSource{ID: "pond_mic", DisplayName: "Mic"}, want: "Pond"},
+		{name: "device", source: datastore.AudioSource{ID: "alsa_1", SafeString: "hw:1,0", DisplayName: "USB"}, want: "Pond"},
+		{name: "ungrouped", source: datastore.AudioSource{ID: "rtsp_3", DisplayName: "Shed"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			group := p.sourceGroupOf(tt.source)
+			if tt.want == "" {
+				assert.Nil(t, group)
+				return
+			}
+			require.NotNil(t, group)
+			assert.Equal(t, tt.want, group.Name)
+		})
+	}
+}
+
+func TestGroupDetectionsOf(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.SourceGroups = []conf.SourceGroupSettings{
+		{Name: "Garden", Sources: []string{"Front", "Back", "Shed"}, Window: 5},
+	}
+	now := time.Now()
+	pending := func(source, group string, offset time.Duration) PendingDetection {
+		return PendingDetection{Source: source, Group: group, FirstDetected: now.Add(offset), Count: 3}
+	}
+
+	p := &Processor{
+		Settings: settings,
+		pendingDetections: map[pendingKey]PendingDetection{
+			{source: "front", species: "eurasian blackbird"}: pending("front", "Garden", 0),
+			{source: "back", species: "eurasian blackbird"}:  pending("back", "Garden", 2*time.Second),
+			{source: "shed", species: "eurasian blackbird"}:  pending("shed", "Garden", 9*time.Second),
+			{source: "back", species: "european robin"}:      pending("back", "Garden", 0),
+			{species: "eurasian blackbird"}:                  pending("pond", "", 0),
+		},
+	}
+
+	key := pendingKey{source: "front", species: "eurasian blackbird"}
+	item := p.pendingDetections[key]
+	keys := p.groupDetectionsOf(key, &item)
+	assert.Equal(t, []pendingKey{key, {source: "back", species: "eurasian blackbird"}}, keys,
+		"only the same species at grouped sources within the window is merged")
+
+	ungrouped := pendingKey{species: "eurasian blackbird"}
+	item = p.pendingDetections[ungrouped]
+	assert.Nil(t, p.groupDetectionsOf(ungrouped, &item))
+
+	alone := pendingKey{source: "back", species: "european robin"}
+	item = p.pendingDetections[alone]
+	assert.Nil(t, p.groupDetectionsOf(alone, &item), "nothing to merge with")
+
+	// A group removed from the configuration no longer merges
+	item = p.pendingDetections[key]
+	item.Group = "Removed"
+	assert.Nil(t, p.groupDetectionsOf(key, &item))
+}
+
+func TestAddPendingDetection(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.SourceGroups = []conf.SourceGroupSettings{
+		{Name: "Garden", Sources: []string{"Front", "Back"}},
+	}
+	p := &Processor{Settings: settings, pendingDetections: make(map[pendingKey]PendingDetection)}
+
+	add := func(source datastore.AudioSource, confidence float64) {
+		var det Detections
+		det.Result.Species.CommonName = "Eurasian Blackbird"
+		det.Result.Confidence = confidence
+		item := birdnet.Results{Source: source, StartTime: time.Now()}
+		p.addPendingDetection(&item, det, p.sourceGroupOf(source), time.Second)
+	}
+
+	// Ungrouped sources share the pending detection of a species
+	add(datastore.AudioSource{ID: "rtsp_1", DisplayName: "Shed"}, 0.7)
+	add(datastore.AudioSource{ID: "rtsp_2", DisplayName: "Pond"}, 0.9)
+	require.Len(t, p.pendingDetections, 1)
+	pending := p.pendingDetections[pendingKey{species: "eurasian blackbird"}]
+	assert.Equal(t, 2, pending.Count)
+	assert.Equal(t, "rtsp_2", pending.Source, "the source of the highest confidence is kept")
+	assert.Empty(t, pending.Group)
+
+	// Grouped sources hold their own until they are merged
+	add(datastore.AudioSource{ID: "rtsp_3", DisplayName: "Front"}, 0.8)
+	add(datastore.AudioSource{ID: "rtsp_4", DisplayName: "Back"}, 0.8)
+	assert.Len(t, p.pendingDetections, 3)
+	assert.Equal(t, "Garden", p.pendingDetections[pendingKey{source: "rtsp_3", species: "eurasian blackbird"}].Group)
+}
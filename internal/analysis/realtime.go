@@ -25,6 +25,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
 	"github.com/tphakala/birdnet-go/internal/datastore/v2/migration"
 	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	v2sources "github.com/tphakala/birdnet-go/internal/datastore/v2/sources"
 	"github.com/tphakala/birdnet-go/internal/datastore/v2only"
 	"github.com/tphakala/birdnet-go/internal/detection"
 	"github.com/tphakala/birdnet-go/internal/diskmanager"
@@ -249,6 +250,11 @@ func RealtimeAnalysis(settings *conf.Settings) error {
 
 	// audioLevelChan and soundLevelChan are already initialized as global variables at package level
 
+	// In enhanced database mode the database defines the audio streams
+	if v2OnlyMode && v2OnlyDatastore != nil {
+		loadStreamsFromDatabase(settings, v2OnlyDatastore)
+	}
+
 	// Initialize audio sources
 	sources, err := initializeAudioSources(settings)
 	if err != nil {
@@ -382,7 +388,7 @@ func RealtimeAnalysis(settings *conf.Settings) error {
 	bufferManager := MustNewBufferManager(bn, quitChan, &wg)
 
 	// Start buffer monitors for each audio source only if we have active sources
-	if len(settings.Realtime.RTSP.Streams) > 0 || settings.Realtime.Audio.Source != "" {
+	if len(sources) > 0 {
 		if err := bufferManager.UpdateMonitors(sources); err != nil {
 			// Use structured logging to improve error visibility and triage
 			// Extract error details from the enhanced error if available
@@ -427,6 +433,15 @@ func RealtimeAnalysis(settings *conf.Settings) error {
 		startClipCleanupMonitor(&wg, quitChan, dataStore)
 	}
 
+	// start rolling retention of the continuous recording archive
+	startArchiveMonitor(&wg, quitChan)
+
+	// store gaps detected in stream audio
+	startStreamGapRecorder(&wg, quitChan, dataStore, settings.Main.Name)
+
+	// switch analysis between the sources of failover groups
+	myaudio.StartFailoverMonitor(&wg, quitChan)
+
 	// start weather polling
 	if settings.Realtime.Weather.Provider != "none" {
 		startWeatherPolling(&wg, settings, dataStore, metrics, quitChan)
@@ -1578,11 +1593,53 @@ func initializeBirdImageCacheIfNeeded(settings *conf.Settings, dataStore datasto
 	return initBirdImageCache(dataStore, metrics)
 }
 
+// loadStreamsFromDatabase replaces the configured streams with the enabled
+// sources stored in the v2 database. On first start the config file streams are
+// imported so existing installations keep their streams.
+func loadStreamsFromDatabase(settings *conf.Settings, ds *v2only.Datastore) {
+	log := GetLogger()
+	if ds.AudioSources() == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	service := v2sources.NewService(ds.AudioSources(), settings.Main.Name)
+	imported, result, err := service.SeedFromConfig(ctx, settings.Realtime.RTSP.Streams)
+	if err != nil {
+		log.Warn("failed to import configured streams into database, using config file streams",
+			logger.Error(err),
+			logger.String("operation", "import_stream_sources"))
+		return
+	}
+	if imported {
+		log.Info("imported configured streams into database",
+			logger.Int("created", result.Created),
+			logger.Int("updated", result.Updated),
+			logger.Int("skipped", result.Skipped),
+			logger.String("operation", "import_stream_sources"))
+	}
+
+	streams, err := service.EnabledStreams(ctx)
+	if err != nil {
+		log.Warn("failed to load stream sources from database, using config file streams",
+			logger.Error(err),
+			logger.String("operation", "load_stream_sources"))
+		return
+	}
+	v2sources.PreserveStreamSettings(streams, settings.Realtime.RTSP.Streams)
+	settings.Realtime.RTSP.Streams = streams
+	log.Debug("loaded stream sources from database",
+		logger.Int("stream_count", len(streams)),
+		logger.String("operation", "load_stream_sources"))
+}
+
 // initializeAudioSources prepares and validates audio sources
 func initializeAudioSources(settings *conf.Settings) ([]string, error) {
 	log := GetLogger()
 	var sources []string
-	if len(settings.Realtime.RTSP.Streams) > 0 || settings.Realtime.Audio.Source != "" {
+	if len(settings.Realtime.RTSP.Streams) > 0 || settings.Realtime.Audio.Source != "" || len(settings.Realtime.Audio.Devices) > 0 {
 		if len(settings.Realtime.RTSP.Streams) > 0 {
 			// Register RTSP sources in the registry and get their source IDs
 			registry := myaudio.GetRegistry()
@@ -1642,6 +1699,16 @@ func initializeAudioSources(settings *conf.Settings) ([]string, error) {
 			}
 		}
 
+		// Register additional capture devices, one source per split channel
+		deviceSources, err := myaudio.RegisterDeviceSources(settings)
+		if err != nil {
+			log.Warn("failed to register some audio device sources",
+				logger.Error(err))
+		}
+		for _, source := range deviceSources {
+			sources = append(sources, source.ID)
+		}
+
 		// Initialize buffers for all audio sources
 		if err := initializeBuffers(sources); err != nil {
 			// If buffer initialization fails, log the error but continue
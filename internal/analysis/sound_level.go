@@ -25,6 +25,8 @@ const (
 	// dB value bounds for validation and sanitization
 	minValidDB = -200.0
 	maxValidDB = 20.0
+	// maxValidSPL bounds calibrated levels, well above the loudest sound in air
+	maxValidSPL = 200.0
 
 	// Error message constants
 	errMsgEmptyField       = "empty %s field"
@@ -51,6 +53,24 @@ func sanitizeSoundLevelData(data myaudio.SoundLevelData) myaudio.SoundLevelData
 		Name:        stringOrDefault(data.Name, "unknown"),
 		Duration:    data.Duration,
 		OctaveBands: make(map[string]myaudio.OctaveBandData),
+		Weighting:   data.Weighting,
+		Leq:         roundToDecimalPlaces(sanitizeFloat64(data.Leq, -100.0), 2),
+		LMin:        roundToDecimalPlaces(sanitizeFloat64(data.LMin, -100.0), 2),
+		LMax:        roundToDecimalPlaces(sanitizeFloat64(data.LMax, -100.0), 2),
+		Calibrated:  data.Calibrated,
+	}
+
+	// Acoustic indices are finite by construction, but guard the JSON encoding anyway
+	for _, indices := range data.AcousticIndices {
+		sanitized.AcousticIndices = append(sanitized.AcousticIndices, myaudio.AcousticIndices{
+			Start:    indices.Start,
+			Duration: indices.Duration,
+			ACI:      sanitizeAcousticIndex(indices.ACI),
+			ADI:      sanitizeAcousticIndex(indices.ADI),
+			NDSI:     sanitizeAcousticIndex(indices.NDSI),
+			BI:       sanitizeAcousticIndex(indices.BI),
+			H:        sanitizeAcousticIndex(indices.H),
+		})
 	}
 
 	// Ensure duration is valid
@@ -118,6 +138,15 @@ func sanitizeFloat64(value, defaultValue float64) float64 {
 	return value
 }
 
+// sanitizeAcousticIndex replaces a non-finite acoustic index with 0 and rounds
+// it to 3 decimal places. Unlike levels, indices are not clamped to a dB range.
+func sanitizeAcousticIndex(value float64) float64 {
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0
+	}
+	return roundToDecimalPlaces(value, 3)
+}
+
 // stringOrDefault returns the value if non-empty, otherwise returns the default.
 // This is a simple helper for providing fallback values, not for security sanitization.
 func stringOrDefault(value, defaultValue string) string {
@@ -221,7 +250,12 @@ func validateSoundLevelData(data *myaudio.SoundLevelData) error {
 			Build()
 	}
 
-	// Verify all dB values are within reasonable range
+	// Verify all dB values are within reasonable range, calibrated levels
+	// are in dB SPL
+	maxDB := maxValidDB
+	if data.Calibrated {
+		maxDB = maxValidSPL
+	}
 	for band, bandData := range data.OctaveBands {
 		if math.IsNaN(bandData.Min) || math.IsInf(bandData.Min, 0) ||
 			math.IsNaN(bandData.Max) || math.IsInf(bandData.Max, 0) ||
@@ -239,10 +273,10 @@ func validateSoundLevelData(data *myaudio.SoundLevelData) error {
 				Build()
 		}
 
-		// Check reasonable bounds (minValidDB to maxValidDB)
-		if bandData.Min < minValidDB || bandData.Min > maxValidDB ||
-			bandData.Max < minValidDB || bandData.Max > maxValidDB ||
-			bandData.Mean < minValidDB || bandData.Mean > maxValidDB {
+		// Check reasonable bounds (minValidDB to maxDB)
+		if bandData.Min < minValidDB || bandData.Min > maxDB ||
+			bandData.Max < minValidDB || bandData.Max > maxDB ||
+			bandData.Mean < minValidDB || bandData.Mean > maxDB {
 			return errors.Newf(errMsgOutOfRange, band).
 				Component("analysis.soundlevel").
 				Category(errors.CategorySoundLevel).
@@ -253,7 +287,7 @@ func validateSoundLevelData(data *myaudio.SoundLevelData) error {
 				Context("max_value", bandData.Max).
 				Context("mean_value", bandData.Mean).
 				Context("valid_range_min", minValidDB).
-				Context("valid_range_max", maxValidDB).
+				Context("valid_range_max", maxDB).
 				Context("sound_data", soundDataCtx).
 				Build()
 		}
@@ -270,6 +304,11 @@ type CompactSoundLevelData struct {
 	Name  string                     `json:"nm"`   // Name
 	Dur   int                        `json:"dur"`  // Duration in seconds
 	Bands map[string]CompactBandData `json:"b"`    // Octave bands
+	W     string                     `json:"w"`    // Frequency weighting of the overall levels
+	Leq   float64                    `json:"leq"`  // Equivalent continuous level (1 decimal)
+	LMin  float64                    `json:"lmin"` // Quietest one-second level (1 decimal)
+	LMax  float64                    `json:"lmax"` // Loudest one-second level (1 decimal)
+	Cal   bool                       `json:"cal"`  // True when levels are in dB SPL rather than dBFS
 }
 
 // CompactBandData is a compact representation of octave band data
@@ -280,6 +319,20 @@ type CompactBandData struct {
 	Mean float64 `json:"m"` // Mean dB (1 decimal)
 }
 
+// CompactAcousticIndicesData is the MQTT payload of one minute of acoustic indices
+type CompactAcousticIndicesData struct {
+	TS   string  `json:"ts"`   // ISO8601 start of the interval
+	Node string  `json:"node"` // Node name (BirdNET-Go instance)
+	Src  string  `json:"src"`  // Source
+	Name string  `json:"nm"`   // Name
+	Dur  int     `json:"dur"`  // Duration in seconds
+	ACI  float64 `json:"aci"`  // Acoustic Complexity Index
+	ADI  float64 `json:"adi"`  // Acoustic Diversity Index
+	NDSI float64 `json:"ndsi"` // Normalized Difference Soundscape Index
+	BI   float64 `json:"bi"`   // Bioacoustic Index
+	H    float64 `json:"h"`    // Acoustic entropy
+}
+
 // toCompactFormat converts sound level data to compact format for MQTT
 func toCompactFormat(data myaudio.SoundLevelData, nodeName string) CompactSoundLevelData {
 	compact := CompactSoundLevelData{
@@ -289,6 +342,11 @@ func toCompactFormat(data myaudio.SoundLevelData, nodeName string) CompactSoundL
 		Name:  data.Name,
 		Dur:   data.Duration,
 		Bands: make(map[string]CompactBandData),
+		W:     data.Weighting,
+		Leq:   roundToDecimalPlaces(data.Leq, 1),
+		LMin:  roundToDecimalPlaces(data.LMin, 1),
+		LMax:  roundToDecimalPlaces(data.LMax, 1),
+		Cal:   data.Calibrated,
 	}
 
 	// Convert bands to compact format with 1 decimal place
@@ -418,6 +476,10 @@ func publishSoundLevelToMQTT(soundData myaudio.SoundLevelData, proc *processor.P
 
 	LogSoundLevelMQTTPublished(topic, soundData.Source, len(soundData.OctaveBands))
 
+	if err := publishAcousticIndicesToMQTT(ctx, sanitizedData, settings, proc); err != nil {
+		return err
+	}
+
 	// Log detailed sound level data if debug is enabled
 	// These logs are for publishing events, not realtime processing
 	if settings.Realtime.Audio.SoundLevel.Debug {
@@ -450,6 +512,53 @@ func publishSoundLevelToMQTT(soundData myaudio.SoundLevelData, proc *processor.P
 	return nil
 }
 
+// publishAcousticIndicesToMQTT publishes each minute of acoustic indices in
+// sanitized sound level data to the acousticindices topic.
+func publishAcousticIndicesToMQTT(ctx context.Context, soundData myaudio.SoundLevelData, settings *conf.Settings, proc *processor.Processor) error {
+	if len(soundData.AcousticIndices) == 0 {
+		return nil
+	}
+	topic := fmt.Sprintf("%s/acousticindices", strings.TrimSuffix(settings.Realtime.MQTT.Topic, "/"))
+
+	for _, indices := range soundData.AcousticIndices {
+		jsonData, err := json.Marshal(CompactAcousticIndicesData{
+			TS:   indices.Start.Format(time.RFC3339),
+			Node: settings.Main.Name,
+			Src:  soundData.Source,
+			Name: soundData.Name,
+			Dur:  indices.Duration,
+			ACI:  indices.ACI,
+			ADI:  indices.ADI,
+			NDSI: indices.NDSI,
+			BI:   indices.BI,
+			H:    indices.H,
+		})
+		if err != nil {
+			return errors.New(err).
+				Component("analysis.soundlevel").
+				Category(errors.CategorySoundLevel).
+				Context("operation", "marshal_acoustic_indices").
+				Context("source", soundData.Source).
+				Build()
+		}
+
+		if err := proc.PublishMQTT(ctx, topic, string(jsonData)); err != nil {
+			if proc.Metrics != nil && proc.Metrics.SoundLevel != nil {
+				proc.Metrics.SoundLevel.RecordSoundLevelPublishingError(soundData.Source, soundData.Name, "mqtt", "publish_error")
+			}
+			return errors.New(err).
+				Component("analysis.soundlevel").
+				Category(errors.CategorySoundLevel).
+				Context("operation", "publish_acoustic_indices_mqtt").
+				Context("topic", topic).
+				Context("source", soundData.Source).
+				Context("retryable", true).
+				Build()
+		}
+	}
+	return nil
+}
+
 // startSoundLevelPublishers starts all sound level publishers with the given done channel
 func startSoundLevelPublishers(wg *sync.WaitGroup, doneChan chan struct{}, proc *processor.Processor, soundLevelChan chan myaudio.SoundLevelData, apiController *apiv2.Controller) {
 	settings := conf.Setting()
@@ -461,6 +570,15 @@ func startSoundLevelPublishers(wg *sync.WaitGroup, doneChan chan struct{}, proc
 		close(mergedQuitChan)
 	}()
 
+	// Record history first so every measurement is stored, then pass the
+	// measurements on to the other publishers
+	if proc != nil {
+		if store, ok := soundLevelHistoryStore(proc.Ds, settings); ok {
+			recorder := newSoundLevelHistoryRecorder(store, settings.Realtime.Audio.SoundLevel.History, settings.Main.Name)
+			soundLevelChan = startSoundLevelHistoryRecorder(wg, mergedQuitChan, recorder, soundLevelChan)
+		}
+	}
+
 	// Start MQTT publisher if enabled
 	if settings.Realtime.MQTT.Enabled {
 		startSoundLevelMQTTPublisherWithDone(wg, mergedQuitChan, proc, soundLevelChan)
@@ -649,6 +767,24 @@ func registerSoundLevelProcessorsForActiveSources(settings *conf.Settings) error
 		}
 	}
 
+	// Register for additional capture devices, one processor per split channel
+	if len(settings.Realtime.Audio.Devices) > 0 {
+		deviceSources, err := myaudio.RegisterDeviceSources(settings)
+		if err != nil {
+			errs = append(errs, err)
+		}
+		for _, audioSource := range deviceSources {
+			totalSources++
+			if err := myaudio.RegisterSoundLevelProcessor(audioSource.ID, audioSource.DisplayName); err != nil {
+				errs = append(errs, err)
+				LogSoundLevelProcessorRegistrationFailed(audioSource.DisplayName, "audio_device", "analysis.soundlevel", err)
+				continue
+			}
+			successCount++
+			LogSoundLevelProcessorRegistered(audioSource.DisplayName, "audio_device", "analysis.soundlevel")
+		}
+	}
+
 	// Get actually running RTSP streams to ensure we only register for active streams
 	activeStreams := myaudio.GetStreamHealth()
 
@@ -739,6 +875,18 @@ func unregisterAllSoundLevelProcessors(settings *conf.Settings) {
 		}
 	}
 
+	// Unregister additional capture device sources
+	if registry := myaudio.GetRegistry(); registry != nil {
+		for i := range settings.Realtime.Audio.Devices {
+			for _, deviceSource := range myaudio.DeviceSources(&settings.Realtime.Audio.Devices[i]) {
+				if audioSource, exists := registry.GetSourceByConnection(deviceSource.Connection); exists {
+					myaudio.UnregisterSoundLevelProcessor(audioSource.ID)
+					LogSoundLevelProcessorUnregistered(audioSource.DisplayName, "audio_device", "analysis.soundlevel")
+				}
+			}
+		}
+	}
+
 	// Unregister all stream sources
 	for _, stream := range settings.Realtime.RTSP.Streams {
 		// Get the source from registry to retrieve its ID
@@ -0,0 +1,269 @@
+package analysis
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Sound level history timing
+const (
+	soundLevelHistoryFlushInterval       = time.Minute
+	soundLevelHistoryMaintenanceInterval = time.Hour
+	soundLevelHistoryWriteTimeout        = 30 * time.Second
+	soundLevelHistoryMaintenanceTimeout  = 5 * time.Minute
+	// soundLevelHistoryMaxPending bounds buffered measurements while the
+	// database is unavailable; the oldest are dropped first.
+	soundLevelHistoryMaxPending = 2000
+)
+
+// soundLevelHistoryRecorder buffers sound level measurements, writes them to
+// the datastore in batches and periodically downsamples and prunes the history.
+type soundLevelHistoryRecorder struct {
+	store    datastore.SoundLevelHistoryStore
+	settings conf.SoundLevelHistorySettings
+	nodeName string
+	pending  []datastore.SoundLevelRecord
+	// indicesStore is nil if the datastore does not store acoustic indices
+	indicesStore   datastore.AcousticIndicesStore
+	pendingIndices []datastore.AcousticIndicesRecord
+	now            func() time.Time
+	// lookupSource resolves a source ID to its sanitized URI and display name.
+	lookupSource func(id string) (uri, name string, ok bool)
+}
+
+// newSoundLevelHistoryRecorder returns a recorder writing to store.
+func newSoundLevelHistoryRecorder(store datastore.SoundLevelHistoryStore, settings conf.SoundLevelHistorySettings, nodeName string) *soundLevelHistoryRecorder {
+	indicesStore, _ := store.(datastore.AcousticIndicesStore)
+	return &soundLevelHistoryRecorder{
+		store:        store,
+		settings:     settings,
+		nodeName:     nodeName,
+		indicesStore: indicesStore,
+		now:          time.Now,
+		lookupSource: lookupRegistrySource,
+	}
+}
+
+// lookupRegistrySource resolves a source ID through the audio source registry.
+func lookupRegistrySource(id string) (uri, name string, ok bool) {
+	registry := myaudio.GetRegistry()
+	if registry == nil {
+		return "", "", false
+	}
+	source, found := registry.GetSourceByID(id)
+	if !found {
+		return "", "", false
+	}
+	return source.SafeString, source.DisplayName, true
+}
+
+// add converts a measurement to a history record and buffers it.
+func (r *soundLevelHistoryRecorder) add(data myaudio.SoundLevelData) {
+	if err := validateSoundLevelData(&data); err != nil {
+		return
+	}
+	data = sanitizeSoundLevelData(data)
+
+	record := datastore.SoundLevelRecord{
+		SourceURI:  data.Source,
+		SourceName: data.Name,
+		NodeName:   r.nodeName,
+		Start:      data.Timestamp.Add(-time.Duration(data.Duration) * time.Second),
+		Duration:   data.Duration,
+		Resolution: data.Duration,
+		Bands:      make([]datastore.SoundLevelBand, 0, len(data.OctaveBands)),
+	}
+	// Store under the same sanitized URI as detections so the two can be joined
+	if uri, name, ok := r.lookupSource(data.Source); ok {
+		record.SourceURI = uri
+		if name != "" {
+			record.SourceName = name
+		}
+	}
+	for _, band := range data.OctaveBands {
+		record.Bands = append(record.Bands, datastore.SoundLevelBand{
+			CenterFreq: band.CenterFreq,
+			Leq:        band.Mean,
+			Min:        band.Min,
+			Max:        band.Max,
+		})
+	}
+	slices.SortFunc(record.Bands, func(a, b datastore.SoundLevelBand) int {
+		switch {
+		case a.CenterFreq < b.CenterFreq:
+			return -1
+		case a.CenterFreq > b.CenterFreq:
+			return 1
+		default:
+			return 0
+		}
+	})
+	record.Leq = datastore.BroadbandLeq(record.Bands)
+
+	if len(r.pending) >= soundLevelHistoryMaxPending {
+		r.pending = slices.Delete(r.pending, 0, len(r.pending)-soundLevelHistoryMaxPending+1)
+	}
+	r.pending = append(r.pending, record)
+
+	if r.indicesStore == nil {
+		return
+	}
+	for _, indices := range data.AcousticIndices {
+		if len(r.pendingIndices) >= soundLevelHistoryMaxPending {
+			r.pendingIndices = slices.Delete(r.pendingIndices, 0, len(r.pendingIndices)-soundLevelHistoryMaxPending+1)
+		}
+		r.pendingIndices = append(r.pendingIndices, datastore.AcousticIndicesRecord{
+			SourceURI:  record.SourceURI,
+			SourceName: record.SourceName,
+			NodeName:   r.nodeName,
+			Start:      indices.Start,
+			Duration:   indices.Duration,
+			ACI:        indices.ACI,
+			ADI:        indices.ADI,
+			NDSI:       indices.NDSI,
+			BI:         indices.BI,
+			H:          indices.H,
+		})
+	}
+}
+
+// flush writes buffered records. Records are kept for the next flush if the write fails.
+func (r *soundLevelHistoryRecorder) flush() {
+	if len(r.pending) == 0 && len(r.pendingIndices) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), soundLevelHistoryWriteTimeout)
+	defer cancel()
+
+	if len(r.pending) > 0 {
+		if err := r.store.SaveSoundLevels(ctx, r.pending); err != nil {
+			getSoundLevelLogger().Warn("failed to store sound level history",
+				logger.Int("pending", len(r.pending)),
+				logger.Error(err),
+				logger.String("operation", "sound_level_history_flush"))
+		} else {
+			r.pending = r.pending[:0]
+		}
+	}
+
+	if len(r.pendingIndices) > 0 {
+		if err := r.indicesStore.SaveAcousticIndices(ctx, r.pendingIndices); err != nil {
+			getSoundLevelLogger().Warn("failed to store acoustic indices",
+				logger.Int("pending", len(r.pendingIndices)),
+				logger.Error(err),
+				logger.String("operation", "acoustic_indices_flush"))
+		} else {
+			r.pendingIndices = r.pendingIndices[:0]
+		}
+	}
+}
+
+// maintain downsamples measurements older than the raw retention and deletes
+// measurements older than the retention period.
+func (r *soundLevelHistoryRecorder) maintain() {
+	ctx, cancel := context.WithTimeout(context.Background(), soundLevelHistoryMaintenanceTimeout)
+	defer cancel()
+
+	lg := getSoundLevelLogger()
+	now := r.now()
+	rawCutoff := now.Add(-time.Duration(r.settings.RawRetentionHours) * time.Hour)
+	resolution := time.Duration(r.settings.DownsampleMinutes) * time.Minute
+	if merged, err := r.store.DownsampleSoundLevels(ctx, rawCutoff, resolution); err != nil {
+		lg.Warn("failed to downsample sound level history",
+			logger.Error(err),
+			logger.String("operation", "sound_level_history_downsample"))
+	} else if merged > 0 {
+		lg.Debug("downsampled sound level history",
+			logger.Int64("merged_records", merged),
+			logger.String("resolution", resolution.String()))
+	}
+
+	if r.settings.RetentionDays <= 0 {
+		return
+	}
+	cutoff := now.AddDate(0, 0, -r.settings.RetentionDays)
+	if deleted, err := r.store.PruneSoundLevels(ctx, cutoff); err != nil {
+		lg.Warn("failed to prune sound level history",
+			logger.Error(err),
+			logger.String("operation", "sound_level_history_prune"))
+	} else if deleted > 0 {
+		lg.Debug("pruned sound level history",
+			logger.Int64("deleted_records", deleted),
+			logger.Time("cutoff", cutoff))
+	}
+
+	if r.indicesStore == nil {
+		return
+	}
+	if deleted, err := r.indicesStore.PruneAcousticIndices(ctx, cutoff); err != nil {
+		lg.Warn("failed to prune acoustic indices",
+			logger.Error(err),
+			logger.String("operation", "acoustic_indices_prune"))
+	} else if deleted > 0 {
+		lg.Debug("pruned acoustic indices",
+			logger.Int64("deleted_records", deleted),
+			logger.Time("cutoff", cutoff))
+	}
+}
+
+// startSoundLevelHistoryRecorder stores every measurement received on in and
+// forwards it to the returned channel, which the other publishers consume.
+// Forwarding never blocks, matching how measurements are produced.
+func startSoundLevelHistoryRecorder(wg *sync.WaitGroup, doneChan <-chan struct{}, recorder *soundLevelHistoryRecorder, in chan myaudio.SoundLevelData) chan myaudio.SoundLevelData {
+	out := make(chan myaudio.SoundLevelData, cap(in))
+
+	wg.Go(func() {
+		lg := getSoundLevelLogger()
+		lg.Info("started sound level history recorder")
+
+		flushTicker := time.NewTicker(soundLevelHistoryFlushInterval)
+		defer flushTicker.Stop()
+		maintenanceTicker := time.NewTicker(soundLevelHistoryMaintenanceInterval)
+		defer maintenanceTicker.Stop()
+		recorder.maintain()
+
+		for {
+			select {
+			case <-doneChan:
+				recorder.flush()
+				lg.Info("stopping sound level history recorder")
+				return
+			case <-flushTicker.C:
+				recorder.flush()
+			case <-maintenanceTicker.C:
+				recorder.maintain()
+			case data, ok := <-in:
+				if !ok {
+					recorder.flush()
+					lg.Info("sound level channel closed, stopping history recorder")
+					return
+				}
+				recorder.add(data)
+				select {
+				case out <- data:
+				default:
+					// Downstream publishers are behind, drop as producers do
+				}
+			}
+		}
+	})
+
+	return out
+}
+
+// soundLevelHistoryStore returns the datastore's sound level history support
+// if history is enabled and the datastore provides it.
+func soundLevelHistoryStore(ds datastore.Interface, settings *conf.Settings) (datastore.SoundLevelHistoryStore, bool) {
+	if ds == nil || !settings.Realtime.Audio.SoundLevel.History.Enabled {
+		return nil, false
+	}
+	store, ok := ds.(datastore.SoundLevelHistoryStore)
+	return store, ok
+}
@@ -0,0 +1,256 @@
+package analysis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// fakeSoundLevelStore records calls to the sound level history store.
+type fakeSoundLevelStore struct {
+	mu             sync.Mutex
+	saved          []datastore.SoundLevelRecord
+	saveErr        error
+	downsampleAt   time.Time
+	downsampleRes  time.Duration
+	pruneAt        time.Time
+	pruneCallCount int
+}
+
+func (f *fakeSoundLevelStore) SaveSoundLevels(_ context.Context, records []datastore.SoundLevelRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.saveErr != nil {
+		return f.saveErr
+	}
+	f.saved = append(f.saved, records...)
+	return nil
+}
+
+func (f *fakeSoundLevelStore) GetSoundLevels(context.Context, datastore.SoundLevelQuery) ([]datastore.SoundLevelRecord, error) {
+	return nil, nil
+}
+
+func (f *fakeSoundLevelStore) GetDetectionSoundLevel(context.Context, uint) (*datastore.SoundLevelRecord, error) {
+	return nil, nil
+}
+
+func (f *fakeSoundLevelStore) DownsampleSoundLevels(_ context.Context, before time.Time, resolution time.Duration) (int64, error) {
+	f.downsampleAt, f.downsampleRes = before, resolution
+	return 0, nil
+}
+
+func (f *fakeSoundLevelStore) PruneSoundLevels(_ context.Context, before time.Time) (int64, error) {
+	f.pruneAt = before
+	f.pruneCallCount++
+	return 0, nil
+}
+
+func (f *fakeSoundLevelStore) savedCount() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.saved)
+}
+
+func testSoundLevelData(source string, ts time.Time) myaudio.SoundLevelData {
+	return myaudio.SoundLevelData{
+		Timestamp: ts,
+		Source:    source,
+		Name:      "Sound card",
+		Duration:  10,
+		OctaveBands: map[string]myaudio.OctaveBandData{
+			"1.0_kHz":  {CenterFreq: 1000, Min: -45, Max: -35, Mean: -40},
+			"31.5_Hz":  {CenterFreq: 31.5, Min: -60, Max: -50, Mean: -55},
+			"250.0_Hz": {CenterFreq: 250, Min: -50, Max: -40, Mean: -45},
+		},
+	}
+}
+
+func newTestHistoryRecorder(store *fakeSoundLevelStore) *soundLevelHistoryRecorder {
+	recorder := newSoundLevelHistoryRecorder(store, conf.SoundLevelHistorySettings{
+		Enabled: true, RawRetentionHours: 48, DownsampleMinutes: 5, RetentionDays: 90,
+	}, "garden-node")
+	recorder.lookupSource = func(id string) (uri, name string, ok bool) {
+		if id == "card_1" {
+			return "hw:1,0", "Garden mic", true
+		}
+		return "", "", false
+	}
+	return recorder
+}
+
+func TestSoundLevelHistoryRecorderAdd(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeSoundLevelStore{}
+	recorder := newTestHistoryRecorder(store)
+	ts := time.Date(2024, 5, 1, 4, 10, 10, 0, time.UTC)
+
+	recorder.add(testSoundLevelData("card_1", ts))
+	recorder.add(testSoundLevelData("unregistered", ts))
+	recorder.add(myaudio.SoundLevelData{Source: "card_1", Timestamp: ts}) // no bands, invalid
+	require.Len(t, recorder.pending, 2)
+
+	record := recorder.pending[0]
+	assert.Equal(t, "hw:1,0", record.SourceURI, "registered sources are stored under their sanitized URI")
+	assert.Equal(t, "Garden mic", record.SourceName)
+	assert.Equal(t, "garden-node", record.NodeName)
+	assert.Equal(t, ts.Add(-10*time.Second), record.Start, "the timestamp marks the end of the interval")
+	assert.Equal(t, 10, record.Resolution)
+	require.Len(t, record.Bands, 3)
+	assert.InDelta(t, 31.5, record.Bands[0].CenterFreq, 0.001)
+	assert.InDelta(t, 1000.0, record.Bands[2].CenterFreq, 0.001)
+	assert.InDelta(t, -40.0, record.Bands[2].Leq, 0.001)
+	assert.InDelta(t, datastore.BroadbandLeq(record.Bands), record.Leq, 0.001)
+
+	assert.Equal(t, "unregistered", recorder.pending[1].SourceURI)
+}
+
+func TestSoundLevelHistoryRecorderFlush(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeSoundLevelStore{saveErr: errors.New("database locked")}
+	recorder := newTestHistoryRecorder(store)
+	ts := time.Date(2024, 5, 1, 4, 10, 10, 0, time.UTC)
+
+	recorder.add(testSoundLevelData("card_1", ts))
+	recorder.flush()
+	assert.Len(t, recorder.pending, 1, "records are kept when the write fails")
+
+	store.saveErr = nil
+	recorder.flush()
+	assert.Empty(t, recorder.pending)
+	assert.Equal(t, 1, store.savedCount())
+
+	// The buffer is bounded while the database is unavailable
+	store.saveErr = errors.New("database locked")
+	for i := range soundLevelHistoryMaxPending + 10 {
+		recorder.add(testSoundLevelData("card_1", ts.Add(time.Duration(i)*10*time.Second)))
+	}
+	require.Len(t, recorder.pending, soundLevelHistoryMaxPending)
+	assert.Equal(t, ts.Add(90*time.Second), recorder.pending[0].Start, "the oldest records are dropped")
+}
+
+func TestSoundLevelHistoryRecorderMaintain(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeSoundLevelStore{}
+	recorder := newTestHistoryRecorder(store)
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+
+	recorder.maintain()
+	assert.Equal(t, now.Add(-48*time.Hour), store.downsampleAt)
+	assert.Equal(t, 5*time.Minute, store.downsampleRes)
+	assert.Equal(t, now.AddDate(0, 0, -90), store.pruneAt)
+
+	recorder.settings.RetentionDays = 0
+	recorder.maintain()
+	assert.Equal(t, 1, store.pruneCallCount, "retention 0 keeps history forever")
+}
+
+func TestStartSoundLevelHistoryRecorder(t *testing.T) {
+	t.Parallel()
+
+	store := &fakeSoundLevelStore{}
+	recorder := newTestHistoryRecorder(store)
+	in := make(chan myaudio.SoundLevelData, 4)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	out := startSoundLevelHistoryRecorder(&wg, done, recorder, in)
+	data := testSoundLevelData("card_1", time.Now())
+	in <- data
+
+	select {
+	case forwarded := <-out:
+		assert.Equal(t, data.Source, forwarded.Source)
+	case <-time.After(2 * time.Second):
+		require.FailNow(t, "measurement was not forwarded")
+	}
+
+	close(done)
+	wg.Wait()
+	assert.Equal(t, 1, store.savedCount(), "pending records are flushed on stop")
+}
+
+func TestSoundLevelHistoryStore(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Audio.SoundLevel.History.Enabled = true
+
+	_, ok := soundLevelHistoryStore(nil, settings)
+	assert.False(t, ok)
+
+	settings.Realtime.Audio.SoundLevel.History.Enabled = false
+	_, ok = soundLevelHistoryStore(nil, settings)
+	assert.False(t, ok)
+}
+
+// fakeAcousticIndicesStore is a sound level store that also stores acoustic indices.
+type fakeAcousticIndicesStore struct {
+	fakeSoundLevelStore
+	indices      []datastore.AcousticIndicesRecord
+	indicesPrune time.Time
+}
+
+func (f *fakeAcousticIndicesStore) SaveAcousticIndices(_ context.Context, records []datastore.AcousticIndicesRecord) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.indices = append(f.indices, records...)
+	return nil
+}
+
+func (f *fakeAcousticIndicesStore) GetAcousticIndices(context.Context, datastore.SoundLevelQuery) ([]datastore.AcousticIndicesRecord, error) {
+	return nil, nil
+}
+
+func (f *fakeAcousticIndicesStore) PruneAcousticIndices(_ context.Context, before time.Time) (int64, error) {
+	f.indicesPrune = before
+	return 0, nil
+}
+
+func TestSoundLevelHistoryRecorderAcousticIndices(t *testing.T) {
+	t.Parallel()
+
+	ts := time.Date(2024, 5, 1, 4, 11, 0, 0, time.UTC)
+	data := testSoundLevelData("card_1", ts)
+	data.AcousticIndices = []myaudio.AcousticIndices{
+		{Start: ts.Add(-time.Minute), Duration: 60, ACI: 152.34567, ADI: 1.9, NDSI: 0.5, BI: 10.2, H: 0.8},
+	}
+
+	// Datastores without acoustic index support only store the levels
+	plain := newTestHistoryRecorder(&fakeSoundLevelStore{})
+	plain.add(data)
+	assert.Len(t, plain.pending, 1)
+	assert.Empty(t, plain.pendingIndices)
+
+	store := &fakeAcousticIndicesStore{}
+	recorder := newSoundLevelHistoryRecorder(store, conf.SoundLevelHistorySettings{RetentionDays: 30}, "garden-node")
+	recorder.lookupSource = func(string) (uri, name string, ok bool) { return "hw:1,0", "Garden mic", true }
+	now := time.Date(2024, 5, 10, 12, 0, 0, 0, time.UTC)
+	recorder.now = func() time.Time { return now }
+
+	recorder.add(data)
+	recorder.flush()
+	require.Len(t, store.indices, 1)
+	record := store.indices[0]
+	assert.Equal(t, "hw:1,0", record.SourceURI)
+	assert.Equal(t, "Garden mic", record.SourceName)
+	assert.Equal(t, "garden-node", record.NodeName)
+	assert.Equal(t, ts.Add(-time.Minute), record.Start)
+	assert.InDelta(t, 152.346, record.ACI, 1e-9, "indices are rounded")
+	assert.Empty(t, recorder.pendingIndices)
+
+	recorder.maintain()
+	assert.Equal(t, now.AddDate(0, 0, -30), store.indicesPrune, "indices are pruned with the sound level history")
+}
@@ -117,6 +117,30 @@ func updateSoundLevelMetrics(soundData myaudio.SoundLevelData, metrics *observab
 		}
 	}
 
+	// The weighted level is measured on the audio itself, in dB SPL for
+	// calibrated sources and dBFS otherwise
+	if soundData.Weighting != "" {
+		metrics.SoundLevel.UpdateSoundLevel(soundData.Source, soundData.Name, "weighted", math.Round(soundData.Leq*100)/100)
+		if soundData.Calibrated {
+			metrics.SoundLevel.UpdateSoundPressureLevel(soundData.Source, soundData.Name, soundData.Weighting,
+				math.Round(soundData.Leq*100)/100,
+				math.Round(soundData.LMin*100)/100,
+				math.Round(soundData.LMax*100)/100)
+		}
+	}
+
+	// Acoustic indices arrive once a minute; report the latest
+	if n := len(soundData.AcousticIndices); n > 0 {
+		indices := soundData.AcousticIndices[n-1]
+		for index, value := range map[string]float64{
+			"aci": indices.ACI, "adi": indices.ADI, "ndsi": indices.NDSI, "bi": indices.BI, "h": indices.H,
+		} {
+			if !math.IsInf(value, 0) && !math.IsNaN(value) {
+				metrics.SoundLevel.UpdateAcousticIndex(soundData.Source, soundData.Name, index, value)
+			}
+		}
+	}
+
 	// Record processing duration
 	processingDuration := time.Since(startTime).Seconds()
 	metrics.SoundLevel.RecordSoundLevelProcessingDuration(soundData.Source, soundData.Name, "update_metrics", processingDuration)
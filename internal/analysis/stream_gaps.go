@@ -0,0 +1,57 @@
+package analysis
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// streamGapWriteTimeout bounds storing one stream gap
+const streamGapWriteTimeout = 30 * time.Second
+
+// startStreamGapRecorder stores the gaps detected in stream audio if the
+// datastore supports it. Gaps are rare, so each is stored as it arrives.
+func startStreamGapRecorder(wg *sync.WaitGroup, quitChan chan struct{}, dataStore datastore.Interface, nodeName string) {
+	store, ok := dataStore.(datastore.StreamGapStore)
+	if !ok {
+		return
+	}
+	wg.Go(func() {
+		for {
+			select {
+			case <-quitChan:
+				return
+			case gap := <-myaudio.StreamGaps():
+				recordStreamGap(store, gap, nodeName)
+			}
+		}
+	})
+}
+
+// recordStreamGap stores one stream gap under the source's sanitized URI.
+func recordStreamGap(store datastore.StreamGapStore, gap myaudio.StreamGap, nodeName string) {
+	record := datastore.StreamGapRecord{
+		SourceURI: gap.SourceID,
+		NodeName:  nodeName,
+		Start:     gap.Start,
+		Duration:  gap.Duration,
+		Reason:    gap.Reason,
+	}
+	if uri, name, ok := lookupRegistrySource(gap.SourceID); ok {
+		record.SourceURI = uri
+		record.SourceName = name
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), streamGapWriteTimeout)
+	defer cancel()
+	if err := store.SaveStreamGaps(ctx, []datastore.StreamGapRecord{record}); err != nil {
+		GetLogger().Warn("failed to store stream gap",
+			logger.String("source_id", gap.SourceID),
+			logger.Error(err),
+			logger.String("operation", "stream_gap_record"))
+	}
+}
@@ -66,7 +66,7 @@ type CSRFConfig struct {
 }
 
 // DefaultCSRFSkipper returns the default skipper function that exempts
-// static assets, media streams, SSE, and auth endpoints from CSRF protection.
+// static assets, media streams, SSE, auth and hub ingestion endpoints from CSRF protection.
 func DefaultCSRFSkipper(c echo.Context) bool {
 	path := c.Request().URL.Path
 
@@ -96,6 +96,11 @@ func DefaultCSRFSkipper(c echo.Context) bool {
 		return true
 	}
 
+	// Skip for hub ingestion (remote nodes authenticate with bearer tokens, not cookies)
+	if strings.HasPrefix(path, "/api/v2/hub/") {
+		return true
+	}
+
 	// Skip for social OAuth endpoints (GET requests for OAuth flow)
 	if strings.HasPrefix(path, "/auth/") {
 		return true
@@ -60,8 +60,13 @@ func (c *Controller) initAlertRoutes() {
 
 // requireV2 checks that the enhanced database is available and returns an error response if not.
 func (c *Controller) requireV2(ctx echo.Context) error {
+	return c.requireV2For(ctx, "Alert rules")
+}
+
+// requireV2For returns a conflict response naming the feature that needs the enhanced database.
+func (c *Controller) requireV2For(ctx echo.Context, feature string) error {
 	return c.HandleError(ctx, fmt.Errorf("enhanced database not enabled"),
-		"Alert rules require the enhanced (v2) database", http.StatusConflict)
+		feature+" require the enhanced (v2) database", http.StatusConflict)
 }
 
 // GetAlertSchema returns the alerting schema for the UI.
@@ -111,7 +111,7 @@ type aggregatedBirdInfo struct {
 // initAnalyticsRoutes registers all analytics-related API endpoints
 func (c *Controller) initAnalyticsRoutes() {
 	// Create analytics API group - publicly accessible
-	analyticsGroup := c.Group.Group("/analytics")
+	analyticsGroup := c.Group.Group("/analytics", c.nodeFilterMiddleware)
 
 	// Species analytics routes
 	speciesGroup := analyticsGroup.Group("/species")
@@ -129,6 +129,16 @@ func (c *Controller) initAnalyticsRoutes() {
 	timeGroup.GET("/daily", c.GetDailyAnalytics)
 	timeGroup.GET("/daily/batch", c.GetBatchDailySpeciesData)         // Batch daily trends for multiple species
 	timeGroup.GET("/distribution/hourly", c.GetTimeOfDayDistribution) // Renamed endpoint for time-of-day distribution
+	timeGroup.GET("/sun-relative", c.GetSunRelativeActivity)          // Activity relative to sunrise/sunset and dawn chorus onset
+
+	// Phenology routes (arrival/departure, weekly presence)
+	c.initPhenologyRoutes(analyticsGroup)
+
+	// Weather-correlated activity routes
+	c.initWeatherActivityRoutes(analyticsGroup)
+
+	// Detections merged across grouped sources
+	c.initMergedDetectionRoutes(analyticsGroup)
 }
 
 // GetDailySpeciesSummary handles GET /api/v2/analytics/species/daily
@@ -152,7 +162,7 @@ func (c *Controller) GetDailySpeciesSummary(ctx echo.Context) error {
 	)
 
 	// 2. Get Initial Data (limit applied at database level)
-	notes, err := c.DS.GetTopBirdsData(selectedDate, minConfidence, limit)
+	notes, err := c.getTopBirdsData(ctx.Request().Context(), selectedDate, minConfidence, limit)
 	if err != nil {
 		c.logErrorIfEnabled("Failed to get initial daily species data",
 			logger.String("date", selectedDate),
@@ -165,7 +175,7 @@ func (c *Controller) GetDailySpeciesSummary(ctx echo.Context) error {
 	}
 
 	// 3. Aggregate Data (including fetching hourly counts)
-	aggregatedData, err := c.aggregateDailySpeciesData(notes, selectedDate, minConfidence)
+	aggregatedData, err := c.aggregateDailySpeciesData(ctx.Request().Context(), notes, selectedDate, minConfidence)
 	if err != nil {
 		// Errors during hourly fetch are logged within the helper, but we need to handle the overall failure
 		c.logErrorIfEnabled("Failed to aggregate daily species data",
@@ -227,7 +237,7 @@ func (c *Controller) GetBatchDailySpeciesSummary(ctx echo.Context) error {
 	)
 
 	// Process each date and collect results
-	batchResults, processingErrors := c.processBatchDates(dates, minConfidence, limit, ip, path)
+	batchResults, processingErrors := c.processBatchDates(ctx.Request().Context(), dates, minConfidence, limit, ip, path)
 
 	// Handle results and errors
 	return c.handleBatchResults(ctx, batchResults, processingErrors, len(dates), ip, path)
@@ -253,12 +263,12 @@ func (c *Controller) parseBatchDailySummaryParams(ctx echo.Context) (dates []str
 }
 
 // processBatchDates processes multiple dates and returns results and errors
-func (c *Controller) processBatchDates(dates []string, minConfidence float64, limit int, ip, path string) (batchResults map[string][]SpeciesDailySummary, processingErrors []string) {
+func (c *Controller) processBatchDates(reqCtx context.Context, dates []string, minConfidence float64, limit int, ip, path string) (batchResults map[string][]SpeciesDailySummary, processingErrors []string) {
 	batchResults = make(map[string][]SpeciesDailySummary)
 	processingErrors = make([]string, 0)
 
 	for _, selectedDate := range dates {
-		result, err := c.processSingleDateForBatch(selectedDate, minConfidence, limit, ip, path)
+		result, err := c.processSingleDateForBatch(reqCtx, selectedDate, minConfidence, limit, ip, path)
 		if err != nil {
 			errorMsg := fmt.Sprintf("Failed to process date %s: %v", selectedDate, err)
 			processingErrors = append(processingErrors, errorMsg)
@@ -271,9 +281,9 @@ func (c *Controller) processBatchDates(dates []string, minConfidence float64, li
 }
 
 // processSingleDateForBatch processes a single date using the same logic as the regular endpoint
-func (c *Controller) processSingleDateForBatch(selectedDate string, minConfidence float64, limit int, ip, path string) ([]SpeciesDailySummary, error) {
+func (c *Controller) processSingleDateForBatch(reqCtx context.Context, selectedDate string, minConfidence float64, limit int, ip, path string) ([]SpeciesDailySummary, error) {
 	// Get data for the date (limit applied at database level)
-	notes, err := c.DS.GetTopBirdsData(selectedDate, minConfidence, limit)
+	notes, err := c.getTopBirdsData(reqCtx, selectedDate, minConfidence, limit)
 	if err != nil {
 		c.logErrorIfEnabled("Failed to get data for date in batch request",
 			logger.String("date", selectedDate),
@@ -285,7 +295,7 @@ func (c *Controller) processSingleDateForBatch(selectedDate string, minConfidenc
 	}
 
 	// Aggregate data
-	aggregatedData, err := c.aggregateDailySpeciesData(notes, selectedDate, minConfidence)
+	aggregatedData, err := c.aggregateDailySpeciesData(reqCtx, notes, selectedDate, minConfidence)
 	if err != nil {
 		c.logErrorIfEnabled("Failed to aggregate data for date in batch request",
 			logger.String("date", selectedDate),
@@ -338,7 +348,7 @@ func (c *Controller) parseDailySpeciesSummaryParams(ctx echo.Context) (selectedD
 }
 
 // aggregateDailySpeciesData processes raw notes, fetches hourly counts, and aggregates results.
-func (c *Controller) aggregateDailySpeciesData(notes []datastore.Note, selectedDate string, minConfidence float64) (map[string]aggregatedBirdInfo, error) {
+func (c *Controller) aggregateDailySpeciesData(reqCtx context.Context, notes []datastore.Note, selectedDate string, minConfidence float64) (map[string]aggregatedBirdInfo, error) {
 	aggregatedData := make(map[string]aggregatedBirdInfo)
 
 	if len(notes) == 0 {
@@ -356,7 +366,7 @@ func (c *Controller) aggregateDailySpeciesData(notes []datastore.Note, selectedD
 	// Batch fetch hourly counts for all species in single query
 	speciesList := slices.Collect(maps.Keys(uniqueSpecies))
 
-	hourlyCounts, err := c.DS.GetBatchHourlyOccurrences(selectedDate, speciesList, minConfidence)
+	hourlyCounts, err := c.getBatchHourlyOccurrences(reqCtx, selectedDate, speciesList, minConfidence)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get batch hourly occurrences: %w", err)
 	}
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -24,6 +25,7 @@ import (
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	datastoreV2 "github.com/tphakala/birdnet-go/internal/datastore/v2"
 	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	v2sources "github.com/tphakala/birdnet-go/internal/datastore/v2/sources"
 	"github.com/tphakala/birdnet-go/internal/ebird"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/imageprovider"
@@ -86,6 +88,19 @@ type Controller struct {
 	// V2Manager provides access to the v2 normalized database for stats and backup
 	V2Manager datastoreV2.Manager
 
+	// Detection tag repository (initialized lazily in initDetectionTagRoutes)
+	detectionTagRepo repository.DetectionRepository
+
+	// Live audio WebSocket streaming (initialized in initLiveAudioRoutes)
+	liveAudio        *myaudio.LiveOpusManager
+	liveAudioClients atomic.Int32
+
+	// Audio source service (initialized lazily in initAudioSourceRoutes)
+	audioSources *v2sources.Service
+
+	// Per-year phenology summaries (initialized lazily in initPhenologyRoutes)
+	phenologyCache *datastore.PhenologyCache
+
 	// Alerting fields (initialized lazily in initAlertRoutes)
 	alertRuleRepo repository.AlertRuleRepository
 	alertEngine   *alerting.Engine
@@ -125,6 +140,18 @@ func WithV2Manager(mgr datastoreV2.Manager) Option {
 	}
 }
 
+// v2RepoOptions returns the table prefix mode and SQL dialect of the v2 manager,
+// for constructing repositories that take (db, useV2Prefix, isMySQL).
+func (c *Controller) v2RepoOptions() (useV2Prefix, isMySQL bool) {
+	if c.V2Manager == nil {
+		return false, false
+	}
+	if prefixed, ok := c.V2Manager.(interface{ TablePrefix() string }); ok {
+		useV2Prefix = prefixed.TablePrefix() != ""
+	}
+	return useV2Prefix, c.V2Manager.IsMySQL()
+}
+
 // parseIPFromHeader attempts to parse a valid IP from a header value.
 // Returns the IP string if valid, empty string otherwise.
 func parseIPFromHeader(headerValue string) string {
@@ -353,8 +380,13 @@ func NewWithOptions(e *echo.Echo, ds datastore.Interface, settings *conf.Setting
 	// c.Group.Use(middleware.Logger())        // Removed: Use custom LoggingMiddleware below for structured logging
 	// NOTE: CORS middleware is configured at the global Echo level in server.go
 	// Removing duplicate CORS here to avoid conflicts with global CORS configuration
-	c.Group.Use(middleware.BodyLimit("1M")) // Limit request body to 1MB to prevent DoS attacks
-	c.Group.Use(c.LoggingMiddleware())      // Use custom structured logging middleware
+	// Limit request body to 1MB to prevent DoS attacks. Hub clip uploads are
+	// streamed and capped by hub.max_clip_size_mb in their handler instead.
+	c.Group.Use(middleware.BodyLimitWithConfig(middleware.BodyLimitConfig{
+		Limit:   "1M",
+		Skipper: isHubClipUpload,
+	}))
+	c.Group.Use(c.LoggingMiddleware()) // Use custom structured logging middleware
 
 	// NOTE: CSRF token is provided by the /app/config endpoint using middleware.EnsureCSRFToken()
 	// which handles Echo v4.15.0's Sec-Fetch-Site optimization that may skip token generation
@@ -476,6 +508,7 @@ func (c *Controller) initRoutes() {
 		{"stream health routes", c.initStreamHealthRoutes},
 		{"audio level routes", c.initAudioLevelRoutes},
 		{"hls streaming routes", c.initHLSRoutes},
+		{"live audio routes", c.initLiveAudioRoutes},
 		{"integration routes", c.initIntegrationsRoutes},
 		{"control routes", c.initControlRoutes},
 		{"auth routes", c.initAuthRoutes},
@@ -488,6 +521,12 @@ func (c *Controller) initRoutes() {
 		{"species routes", c.initSpeciesRoutes},
 		{"dynamic threshold routes", c.initDynamicThresholdRoutes},
 		{"alert routes", c.initAlertRoutes},
+		{"detection tag routes", c.initDetectionTagRoutes},
+		{"audio source routes", c.initAudioSourceRoutes},
+		{"onvif discovery routes", c.initONVIFRoutes},
+		{"hub routes", c.initHubRoutes},
+		{"sound level history routes", c.initSoundLevelHistoryRoutes},
+		{"archive routes", c.initArchiveRoutes},
 	}
 
 	for _, initializer := range routeInitializers {
@@ -0,0 +1,252 @@
+// internal/api/v2/archive.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Archive query limits
+const (
+	archiveSegmentsMaxRange = 7 * 24 * time.Hour
+	archiveSegmentsDefault  = 24 * time.Hour
+	archiveAudioTimeout     = 2 * time.Minute
+	// archiveDetectionPadding is the audio included before and after a detection in its archive URL
+	archiveDetectionPadding = 5 * time.Second
+)
+
+// ArchiveSource is a source with continuously recorded audio.
+type ArchiveSource struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"display_name,omitempty"`
+}
+
+// ArchiveSegmentsResponse is the response of the archive segments endpoint.
+type ArchiveSegmentsResponse struct {
+	Source   string                   `json:"source"`
+	Start    time.Time                `json:"start"`
+	End      time.Time                `json:"end"`
+	Segments []myaudio.ArchiveSegment `json:"segments"`
+}
+
+// DetectionArchiveLocation is the position of a detection in an archive file.
+type DetectionArchiveLocation struct {
+	myaudio.ArchiveLocation
+	AudioURL string `json:"audio_url"` // archive audio around the detection
+}
+
+// DetectionArchiveResponse lists the archive files covering a detection.
+type DetectionArchiveResponse struct {
+	DetectionID uint                       `json:"detection_id"`
+	BeginTime   time.Time                  `json:"begin_time"`
+	Locations   []DetectionArchiveLocation `json:"locations"`
+}
+
+// initArchiveRoutes registers the continuous recording archive endpoints.
+// Archived audio is a continuous recording of the site, so all endpoints require authentication.
+func (c *Controller) initArchiveRoutes() {
+	archiveGroup := c.Group.Group("/archive")
+	if c.authMiddleware != nil {
+		archiveGroup.Use(c.authMiddleware)
+	}
+	archiveGroup.GET("/sources", c.GetArchiveSources)
+	archiveGroup.GET("/segments", c.GetArchiveSegments)
+	archiveGroup.GET("/audio", c.GetArchiveAudio)
+	archiveGroup.GET("/detection/:id", c.GetDetectionArchive)
+}
+
+// archive returns a reader for the configured archive.
+func (c *Controller) archive() *myaudio.Archive {
+	return myaudio.NewArchive(&c.Settings.Realtime.Audio)
+}
+
+// GetArchiveSources handles GET /api/v2/archive/sources
+// Returns the sources with archived audio.
+func (c *Controller) GetArchiveSources(ctx echo.Context) error {
+	ids, err := c.archive().Sources()
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to list archive sources", http.StatusInternalServerError)
+	}
+
+	registry := myaudio.GetRegistry()
+	sources := make([]ArchiveSource, 0, len(ids))
+	for _, id := range ids {
+		source := ArchiveSource{ID: id}
+		if registry != nil {
+			if s, ok := registry.GetSourceByID(id); ok {
+				source.DisplayName = s.DisplayName
+			}
+		}
+		sources = append(sources, source)
+	}
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"enabled": c.Settings.Realtime.Audio.Archive.Enabled,
+		"sources": sources,
+	})
+}
+
+// GetArchiveSegments handles GET /api/v2/archive/segments
+// Returns the archive files of a source.
+//
+// Query parameters: source (required), start and end as RFC3339 timestamps or
+// YYYY-MM-DD dates (default: the last 24 hours, at most 7 days).
+func (c *Controller) GetArchiveSegments(ctx echo.Context) error {
+	source := ctx.QueryParam("source")
+	if source == "" {
+		return c.HandleError(ctx, errors.NewStd("missing source"), "source is required", http.StatusBadRequest)
+	}
+
+	end := time.Now()
+	var err error
+	if value := ctx.QueryParam("end"); value != "" {
+		if end, err = parseSoundLevelTime(value, true); err != nil {
+			return c.HandleError(ctx, err, "Invalid end: use RFC3339 or YYYY-MM-DD", http.StatusBadRequest)
+		}
+	}
+	start := end.Add(-archiveSegmentsDefault)
+	if value := ctx.QueryParam("start"); value != "" {
+		if start, err = parseSoundLevelTime(value, false); err != nil {
+			return c.HandleError(ctx, err, "Invalid start: use RFC3339 or YYYY-MM-DD", http.StatusBadRequest)
+		}
+	}
+	if !start.Before(end) {
+		return c.HandleError(ctx, errors.NewStd("start must be before end"), "start must be before end", http.StatusBadRequest)
+	}
+	if end.Sub(start) > archiveSegmentsMaxRange {
+		return c.HandleError(ctx, errors.NewStd("time range too large"), "Time range cannot exceed 7 days", http.StatusBadRequest)
+	}
+
+	segments, err := c.archive().Segments(source, start, end)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to list archive files", http.StatusInternalServerError)
+	}
+	if segments == nil {
+		segments = []myaudio.ArchiveSegment{}
+	}
+	return ctx.JSON(http.StatusOK, ArchiveSegmentsResponse{Source: source, Start: start, End: end, Segments: segments})
+}
+
+// GetArchiveAudio handles GET /api/v2/archive/audio
+// Returns the archived audio of a source between start and end, clipped from
+// the hourly archive files. Unrecorded parts of the range are silent.
+//
+// Query parameters: source (required), start and end as RFC3339 timestamps
+// (required, at most 10 minutes apart), format (wav or flac, default wav).
+func (c *Controller) GetArchiveAudio(ctx echo.Context) error {
+	source := ctx.QueryParam("source")
+	if source == "" {
+		return c.HandleError(ctx, errors.NewStd("missing source"), "source is required", http.StatusBadRequest)
+	}
+	start, err := time.Parse(time.RFC3339, ctx.QueryParam("start"))
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid start: use RFC3339", http.StatusBadRequest)
+	}
+	end, err := time.Parse(time.RFC3339, ctx.QueryParam("end"))
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid end: use RFC3339", http.StatusBadRequest)
+	}
+	if !start.Before(end) || end.Sub(start) > myaudio.MaxArchiveRange {
+		return c.HandleError(ctx, errors.NewStd("invalid time range"),
+			fmt.Sprintf("start must be before end and the range cannot exceed %s", myaudio.MaxArchiveRange), http.StatusBadRequest)
+	}
+	format := ctx.QueryParam("format")
+	if format == "" {
+		format = "wav"
+	}
+	if format != "wav" && format != myaudio.FormatFLAC {
+		return c.HandleError(ctx, errors.NewStd("unsupported format"), "format must be wav or flac", http.StatusBadRequest)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), archiveAudioTimeout)
+	defer cancel()
+
+	pcm, err := c.archive().ReadRange(reqCtx, source, start, end)
+	if errors.Is(err, myaudio.ErrArchiveNotFound) {
+		return c.HandleError(ctx, err, "No archived audio for the requested time range", http.StatusNotFound)
+	}
+	if err != nil {
+		c.logErrorIfEnabled("Failed to read archived audio",
+			logger.String("source", source),
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+			logger.String("path", ctx.Request().URL.Path),
+		)
+		return c.HandleError(ctx, err, "Failed to read archived audio", http.StatusInternalServerError)
+	}
+
+	var data []byte
+	if format == myaudio.FormatFLAC {
+		buf, err := myaudio.ExportAudioWithCustomFFmpegArgsContext(reqCtx, pcm, c.Settings.Realtime.Audio.FfmpegPath,
+			[]string{"-c:a", "flac", "-f", "flac"})
+		if err != nil {
+			return c.HandleError(ctx, err, "Failed to encode archived audio", http.StatusInternalServerError)
+		}
+		data = buf.Bytes()
+	} else {
+		buf, err := myaudio.EncodePCMtoWAVWithContext(reqCtx, pcm)
+		if err != nil {
+			return c.HandleError(ctx, err, "Failed to encode archived audio", http.StatusInternalServerError)
+		}
+		data = buf.Bytes()
+	}
+
+	filename := fmt.Sprintf("archive_%s_%s.%s", source, start.UTC().Format("20060102T150405Z"), format)
+	if isValidFilename(filename) {
+		ctx.Response().Header().Set("Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q; filename*=UTF-8''%s", filename, url.QueryEscape(filename)))
+	}
+	return ctx.Blob(http.StatusOK, audioMimeType("."+format), data)
+}
+
+// GetDetectionArchive handles GET /api/v2/archive/detection/:id
+// Returns the archive files covering a detection with the offset of the
+// detection in each file. Detections do not record their capture source, so
+// all sources are searched unless the source query parameter is given.
+func (c *Controller) GetDetectionArchive(ctx echo.Context) error {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return c.HandleError(ctx, err, "Invalid detection ID", http.StatusBadRequest)
+	}
+	note, err := c.DS.Get(strconv.FormatUint(id, 10))
+	if err != nil {
+		return c.HandleError(ctx, err, "Detection not found", http.StatusNotFound)
+	}
+	if note.BeginTime.IsZero() {
+		return c.HandleError(ctx, errors.NewStd("detection has no begin time"), "Detection has no recorded time", http.StatusNotFound)
+	}
+
+	locations, err := c.archive().Locate(ctx.QueryParam("source"), note.BeginTime)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to search the archive", http.StatusInternalServerError)
+	}
+
+	end := note.EndTime
+	if !end.After(note.BeginTime) {
+		end = note.BeginTime.Add(time.Duration(c.Settings.Realtime.Audio.Export.Length) * time.Second)
+	}
+	response := DetectionArchiveResponse{
+		DetectionID: uint(id),
+		BeginTime:   note.BeginTime,
+		Locations:   make([]DetectionArchiveLocation, 0, len(locations)),
+	}
+	for _, loc := range locations {
+		query := url.Values{}
+		query.Set("source", loc.Segment.SourceID)
+		query.Set("start", note.BeginTime.Add(-archiveDetectionPadding).Format(time.RFC3339))
+		query.Set("end", end.Add(archiveDetectionPadding).Format(time.RFC3339))
+		response.Locations = append(response.Locations, DetectionArchiveLocation{
+			ArchiveLocation: loc,
+			AudioURL:        "/api/v2/archive/audio?" + query.Encode(),
+		})
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
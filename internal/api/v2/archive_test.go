@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// newArchiveTestController returns a controller with an archive holding one
+// file per hour from 12:00 to 14:00 UTC on 2026-10-18 for source rtsp_1.
+func newArchiveTestController(t *testing.T, ds datastore.Interface) (*Controller, time.Time) {
+	t.Helper()
+	settings := &conf.Settings{}
+	settings.Realtime.Audio.Archive = conf.ArchiveSettings{Enabled: true, Path: t.TempDir(), Type: myaudio.FormatOpus}
+	settings.Realtime.Audio.Export.Length = 15
+
+	first := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	for i := range 3 {
+		path := myaudio.ArchiveFilePath(settings.Realtime.Audio.Archive.Path, "rtsp_1", first.Add(time.Duration(i)*time.Hour), ".opus")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("OggS"), 0o600))
+	}
+	return &Controller{Settings: settings, DS: ds}, first
+}
+
+// serveArchiveRequest calls handler with the given query and path parameters.
+func serveArchiveRequest(t *testing.T, handler echo.HandlerFunc, query url.Values, params ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/archive?"+query.Encode(), http.NoBody)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	for i := 0; i+1 < len(params); i += 2 {
+		ctx.SetParamNames(params[i])
+		ctx.SetParamValues(params[i+1])
+	}
+	_ = handler(ctx)
+	return rec
+}
+
+func TestGetArchiveSources(t *testing.T) {
+	t.Parallel()
+	c, _ := newArchiveTestController(t, nil)
+
+	rec := serveArchiveRequest(t, c.GetArchiveSources, url.Values{})
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp struct {
+		Enabled bool            `json:"enabled"`
+		Sources []ArchiveSource `json:"sources"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.True(t, resp.Enabled)
+	require.Len(t, resp.Sources, 1)
+	assert.Equal(t, "rtsp_1", resp.Sources[0].ID)
+}
+
+func TestGetArchiveSegments(t *testing.T) {
+	t.Parallel()
+	c, first := newArchiveTestController(t, nil)
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+		wantCount  int
+	}{
+		{"range covering two files", url.Values{"source": {"rtsp_1"}, "start": {"2026-10-18T12:30:00Z"}, "end": {"2026-10-18T13:30:00Z"}}, http.StatusOK, 2},
+		{"whole day", url.Values{"source": {"rtsp_1"}, "start": {"2026-10-18T00:00:00Z"}, "end": {"2026-10-19T00:00:00Z"}}, http.StatusOK, 3},
+		{"unknown source", url.Values{"source": {"rtsp_2"}, "start": {"2026-10-18T00:00:00Z"}, "end": {"2026-10-19T00:00:00Z"}}, http.StatusOK, 0},
+		{"missing source", url.Values{}, http.StatusBadRequest, 0},
+		{"range too large", url.Values{"source": {"rtsp_1"}, "start": {"2026-10-01"}, "end": {"2026-10-18"}}, http.StatusBadRequest, 0},
+		{"reversed range", url.Values{"source": {"rtsp_1"}, "start": {"2026-10-18T13:00:00Z"}, "end": {"2026-10-18T12:00:00Z"}}, http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := serveArchiveRequest(t, c.GetArchiveSegments, tt.query)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp ArchiveSegmentsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Len(t, resp.Segments, tt.wantCount)
+			if tt.wantCount > 0 {
+				assert.True(t, resp.Segments[0].Start.Equal(first) || resp.Segments[0].Start.After(first))
+			}
+		})
+	}
+}
+
+func TestGetArchiveAudio_Validation(t *testing.T) {
+	t.Parallel()
+	c, _ := newArchiveTestController(t, nil)
+
+	tests := []struct {
+		name       string
+		query      url.Values
+		wantStatus int
+	}{
+		{"missing source", url.Values{"start": {"2026-10-18T12:00:00Z"}, "end": {"2026-10-18T12:00:10Z"}}, http.StatusBadRequest},
+		{"invalid start", url.Values{"source": {"rtsp_1"}, "start": {"noon"}, "end": {"2026-10-18T12:00:10Z"}}, http.StatusBadRequest},
+		{"range too long", url.Values{"source": {"rtsp_1"}, "start": {"2026-10-18T12:00:00Z"}, "end": {"2026-10-18T12:30:00Z"}}, http.StatusBadRequest},
+		{"unsupported format", url.Values{"source": {"rtsp_1"}, "start": {"2026-10-18T12:00:00Z"}, "end": {"2026-10-18T12:00:10Z"}, "format": {"mp3"}}, http.StatusBadRequest},
+		{"not archived", url.Values{"source": {"rtsp_1"}, "start": {"2026-10-17T12:00:00Z"}, "end": {"2026-10-17T12:00:10Z"}}, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := serveArchiveRequest(t, c.GetArchiveAudio, tt.query)
+			assert.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestGetDetectionArchive(t *testing.T) {
+	t.Parallel()
+	mockDS := mocks.NewMockInterface(t)
+	begin := time.Date(2026, 10, 18, 13, 15, 30, 0, time.UTC)
+	mockDS.On("Get", "1").Return(datastore.Note{ID: 1, BeginTime: begin, EndTime: begin.Add(3 * time.Second)}, nil)
+	mockDS.On("Get", "2").Return(datastore.Note{}, assert.AnError)
+	c, _ := newArchiveTestController(t, mockDS)
+
+	rec := serveArchiveRequest(t, c.GetDetectionArchive, url.Values{}, "id", "1")
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp DetectionArchiveResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Locations, 1)
+	loc := resp.Locations[0]
+	assert.Equal(t, "rtsp_1", loc.Segment.SourceID)
+	assert.InDelta(t, 15*60+30, loc.Offset, 0.001)
+
+	audioURL, err := url.Parse(loc.AudioURL)
+	require.NoError(t, err)
+	assert.Equal(t, "/api/v2/archive/audio", audioURL.Path)
+	assert.Equal(t, "2026-10-18T13:15:25Z", audioURL.Query().Get("start"))
+	assert.Equal(t, "2026-10-18T13:15:38Z", audioURL.Query().Get("end"))
+
+	rec = serveArchiveRequest(t, c.GetDetectionArchive, url.Values{}, "id", "2")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+
+	rec = serveArchiveRequest(t, c.GetDetectionArchive, url.Values{}, "id", "x")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
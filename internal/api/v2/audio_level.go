@@ -462,7 +462,7 @@ func (c *Controller) getAnonymizedSourceName(source *myaudio.AudioSource) string
 	case myaudio.SourceTypeAudioCard:
 		return audioSourceDefaultName
 	case myaudio.SourceTypeRTSP, myaudio.SourceTypeHTTP, myaudio.SourceTypeHLS,
-		myaudio.SourceTypeRTMP, myaudio.SourceTypeUDP:
+		myaudio.SourceTypeRTMP, myaudio.SourceTypeUDP, myaudio.SourceTypeSRT:
 		// All stream types use the same anonymization pattern
 		audioLevelMgr.streamAnonymMu.RLock()
 		if name, exists := audioLevelMgr.streamAnonymMap[source.ID]; exists {
@@ -0,0 +1,265 @@
+// internal/api/v2/audio_live.go
+package api
+
+import (
+	"encoding/binary"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// Live audio WebSocket configuration
+const (
+	liveAudioMaxClients  = 20
+	liveAudioWriteWait   = 10 * time.Second
+	liveAudioPongWait    = 60 * time.Second
+	liveAudioPingPeriod  = (liveAudioPongWait * 9) / 10
+	liveAudioMaxMsgSize  = 1024 // clients only send control frames
+	liveAudioEventBuffer = 100
+)
+
+// Live audio event types
+const (
+	liveAudioEventInit       = "init"
+	liveAudioEventDetection  = "detection"
+	liveAudioEventAudioLevel = "audio_level"
+	liveAudioEventSoundLevel = "sound_level"
+)
+
+// LiveAudioEvent is a JSON text message of the live audio WebSocket.
+type LiveAudioEvent struct {
+	Type string    `json:"type"`
+	Time time.Time `json:"time"`
+	Data any       `json:"data"`
+}
+
+// LiveAudioInit describes the audio of a live stream. It is the first
+// message of every connection and holds what a decoder needs to be configured,
+// e.g. a WebCodecs AudioDecoder with codec "opus".
+type LiveAudioInit struct {
+	SourceID        string `json:"source_id"`
+	Name            string `json:"name,omitempty"`
+	Codec           string `json:"codec"`
+	SampleRate      int    `json:"sample_rate"`
+	Channels        int    `json:"channels"`
+	FrameDurationMs int    `json:"frame_duration_ms"`
+	OpusHead        []byte `json:"opus_head"` // base64 in JSON
+}
+
+var liveAudioUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 8192,
+	CheckOrigin:     terminalUpgrader.CheckOrigin,
+}
+
+// initLiveAudioRoutes registers the live audio WebSocket endpoint. Live audio
+// can reveal conversations near the microphone, so it requires authentication.
+func (c *Controller) initLiveAudioRoutes() {
+	c.liveAudio = myaudio.NewLiveOpusManager(c.Settings.Realtime.Audio.FfmpegPath, c.Settings.WebServer.LiveStream.BitRate)
+
+	liveGroup := c.Group.Group("/streams/live")
+	if c.authMiddleware != nil {
+		liveGroup.Use(c.authMiddleware)
+	}
+	liveGroup.GET("/:sourceID/ws", c.StreamLiveAudio)
+}
+
+// StreamLiveAudio handles GET /api/v2/streams/live/:sourceID/ws
+// Streams the audio of a source over a WebSocket together with the events of
+// the source, so playback and overlays share a single ordered channel.
+//
+// The first message is an "init" text message with a LiveAudioInit. Binary
+// messages are Opus packets of LiveOpusFrameDuration, each prefixed with the
+// 8 byte big-endian Unix time in milliseconds at which it was encoded. Text
+// messages are LiveAudioEvent JSON with type detection, audio_level or
+// sound_level. Events carry server time to align them with the audio.
+//
+// One encoder per source is shared by all listeners and no files are written.
+func (c *Controller) StreamLiveAudio(ctx echo.Context) error {
+	sourceID, err := c.validateAndDecodeSourceID(ctx)
+	if err != nil || sourceID == "" {
+		return err
+	}
+	if !myaudio.HasCaptureBuffer(sourceID) {
+		return c.HandleError(ctx, errors.NewStd("unknown source"), "Audio source not found", http.StatusNotFound)
+	}
+	if c.liveAudio == nil || c.Settings.Realtime.Audio.FfmpegPath == "" {
+		return c.HandleError(ctx, errors.NewStd("FFmpeg is not available"),
+			"FFmpeg is required for live audio", http.StatusServiceUnavailable)
+	}
+	if c.liveAudioClients.Add(1) > liveAudioMaxClients {
+		c.liveAudioClients.Add(-1)
+		return c.HandleError(ctx, errors.NewStd("too many listeners"),
+			"Too many live audio listeners, try again later", http.StatusServiceUnavailable)
+	}
+	defer c.liveAudioClients.Add(-1)
+
+	packets, head, unsubscribe, err := c.liveAudio.Subscribe(ctx.Request().Context(), sourceID)
+	if err != nil {
+		c.logErrorIfEnabled("Failed to start live audio encoder",
+			logger.String("source_id", privacy.SanitizeRTSPUrl(sourceID)),
+			logger.Error(err))
+		return c.HandleError(ctx, err, "Failed to start live audio", http.StatusServiceUnavailable)
+	}
+	defer unsubscribe()
+
+	conn, err := liveAudioUpgrader.Upgrade(ctx.Response(), ctx.Request(), nil)
+	if err != nil {
+		// The upgrader has already written an HTTP error response
+		c.logWarnIfEnabled("Failed to upgrade live audio WebSocket", logger.Error(err))
+		return nil
+	}
+	defer func() { _ = conn.Close() }()
+
+	c.logInfoIfEnabled("Live audio listener connected",
+		logger.String("source_id", privacy.SanitizeRTSPUrl(sourceID)),
+		logger.String("ip", ctx.RealIP()))
+
+	init := LiveAudioInit{
+		SourceID:        sourceID,
+		Codec:           "opus",
+		SampleRate:      myaudio.LiveOpusSampleRate,
+		Channels:        conf.NumChannels,
+		FrameDurationMs: int(myaudio.LiveOpusFrameDuration.Milliseconds()),
+		OpusHead:        head,
+	}
+	if registry := myaudio.GetRegistry(); registry != nil {
+		if source, ok := registry.GetSourceByID(sourceID); ok {
+			init.Name = source.DisplayName
+		}
+	}
+
+	c.runLiveAudioSession(conn, sourceID, &init, packets)
+
+	c.logInfoIfEnabled("Live audio listener disconnected",
+		logger.String("source_id", privacy.SanitizeRTSPUrl(sourceID)),
+		logger.String("ip", ctx.RealIP()))
+	return nil
+}
+
+// runLiveAudioSession writes audio and events to conn until the client
+// disconnects, the encoder stops or the server shuts down.
+func (c *Controller) runLiveAudioSession(conn *websocket.Conn, sourceID string, init *LiveAudioInit, packets <-chan myaudio.LiveOpusPacket) {
+	// Reader: handles pongs and detects the client closing the connection
+	closed := make(chan struct{})
+	conn.SetReadLimit(liveAudioMaxMsgSize)
+	_ = conn.SetReadDeadline(time.Now().Add(liveAudioPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(liveAudioPongWait))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Detection and sound level events come from the SSE broadcaster
+	var detections <-chan SSEDetectionData
+	var soundLevels <-chan SSESoundLevelData
+	if c.sseManager != nil {
+		client := &SSEClient{
+			ID:             "live-audio-" + generateCorrelationID(),
+			Channel:        make(chan SSEDetectionData, liveAudioEventBuffer),
+			SoundLevelChan: make(chan SSESoundLevelData, liveAudioEventBuffer),
+			Done:           make(chan struct{}, sseDoneChannelBuffer),
+			StreamType:     streamTypeAll,
+		}
+		c.sseManager.AddClient(client)
+		defer c.sseManager.RemoveClient(client.ID)
+		detections, soundLevels = client.Channel, client.SoundLevelChan
+	}
+	audioLevels := subscribeToAudioLevels()
+	defer unsubscribeFromAudioLevels(audioLevels)
+
+	if err := writeLiveAudioJSON(conn, &LiveAudioEvent{Type: liveAudioEventInit, Time: time.Now(), Data: init}); err != nil {
+		return
+	}
+
+	var shutdown <-chan struct{}
+	if c.ctx != nil {
+		shutdown = c.ctx.Done()
+	}
+	pingTicker := time.NewTicker(liveAudioPingPeriod)
+	defer pingTicker.Stop()
+	frame := make([]byte, 0, 512)
+	var lastAudioLevel time.Time
+
+	for {
+		var err error
+		select {
+		case <-closed:
+			return
+		case <-shutdown:
+			_ = conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(liveAudioWriteWait))
+			return
+		case packet, ok := <-packets:
+			if !ok {
+				_ = conn.WriteControl(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "live audio encoder stopped"),
+					time.Now().Add(liveAudioWriteWait))
+				return
+			}
+			frame = appendLiveAudioFrame(frame[:0], &packet)
+			_ = conn.SetWriteDeadline(time.Now().Add(liveAudioWriteWait))
+			err = conn.WriteMessage(websocket.BinaryMessage, frame)
+		case detection, ok := <-detections:
+			if !ok {
+				// Removed by the SSE manager as a slow client, continue with audio only
+				detections = nil
+				continue
+			}
+			if detection.Source.ID != "" && detection.Source.ID != sourceID {
+				continue
+			}
+			err = writeLiveAudioJSON(conn, &LiveAudioEvent{Type: liveAudioEventDetection, Time: detection.Timestamp, Data: &detection})
+		case level, ok := <-soundLevels:
+			if !ok {
+				soundLevels = nil
+				continue
+			}
+			if level.Source != sourceID {
+				continue
+			}
+			err = writeLiveAudioJSON(conn, &LiveAudioEvent{Type: liveAudioEventSoundLevel, Time: level.Timestamp, Data: &level.SoundLevelData})
+		case level, ok := <-audioLevels:
+			if !ok {
+				audioLevels = nil
+				continue
+			}
+			if level.Source != sourceID || time.Since(lastAudioLevel) < audioLevelRateLimitUpdate {
+				continue
+			}
+			lastAudioLevel = time.Now()
+			err = writeLiveAudioJSON(conn, &LiveAudioEvent{Type: liveAudioEventAudioLevel, Time: lastAudioLevel, Data: level})
+		case <-pingTicker.C:
+			err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(liveAudioWriteWait))
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// appendLiveAudioFrame appends the binary frame of an Opus packet to frame.
+func appendLiveAudioFrame(frame []byte, packet *myaudio.LiveOpusPacket) []byte {
+	frame = binary.BigEndian.AppendUint64(frame, uint64(packet.Time.UnixMilli())) //nolint:gosec // G115: Unix time in ms is positive
+	return append(frame, packet.Data...)
+}
+
+// writeLiveAudioJSON writes an event as a JSON text message.
+func writeLiveAudioJSON(conn *websocket.Conn, event *LiveAudioEvent) error {
+	_ = conn.SetWriteDeadline(time.Now().Add(liveAudioWriteWait))
+	return conn.WriteJSON(event)
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"encoding/binary"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+func TestStreamLiveAudioUnknownSource(t *testing.T) {
+	t.Parallel()
+
+	c := &Controller{Settings: &conf.Settings{}}
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/streams/live/missing/ws", http.NoBody)
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(req, rec)
+	ctx.SetParamNames("sourceID")
+	ctx.SetParamValues("missing_source")
+
+	_ = c.StreamLiveAudio(ctx)
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Zero(t, c.liveAudioClients.Load())
+}
+
+func TestAppendLiveAudioFrame(t *testing.T) {
+	t.Parallel()
+
+	at := time.UnixMilli(1714550400123)
+	frame := appendLiveAudioFrame(nil, &myaudio.LiveOpusPacket{Data: []byte{0xFC, 0x01}, Time: at})
+	assert.Len(t, frame, 10)
+	assert.Equal(t, uint64(at.UnixMilli()), binary.BigEndian.Uint64(frame[:8]))
+	assert.Equal(t, []byte{0xFC, 0x01}, frame[8:])
+}
@@ -0,0 +1,404 @@
+// internal/api/v2/clip_export.go
+package api
+
+import (
+	"archive/zip"
+	"cmp"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Clip processing and export limits
+const (
+	clipTrimDefaultPadding = time.Second
+	clipTrimMaxPadding     = 10 * time.Second
+	clipProcessTimeout     = time.Minute
+	// maxConcurrentClipProcesses caps the FFmpeg processes started for clip
+	// requests, which the public audio endpoint can trigger
+	maxConcurrentClipProcesses = 2
+	clipProcessRetryAfter      = "5" // seconds, suggested when all slots are in use
+	// searchExportMaxClips caps the number of detections in one export
+	searchExportMaxClips = 1000
+	searchExportPageSize = 200
+	searchExportTimeout  = 30 * time.Minute
+)
+
+// Clip export manifest statuses
+const (
+	clipExportStatusOK      = "ok"
+	clipExportStatusNoAudio = "no_audio"
+	clipExportStatusMissing = "missing"
+	clipExportStatusFailed  = "failed"
+)
+
+// clipProcessingParams are the query parameters that request a processed
+// copy of a clip instead of the stored file.
+var clipProcessingParams = []string{"trim", "padding", "format", "bitrate", "normalize", "eq"}
+
+// clipProcessSemaphore holds a slot for every clip being processed by FFmpeg.
+var clipProcessSemaphore = make(chan struct{}, maxConcurrentClipProcesses)
+
+// tryAcquireClipProcessSlot takes a clip processing slot without waiting. It
+// returns the function releasing the slot, or false when all are in use.
+func tryAcquireClipProcessSlot() (release func(), ok bool) {
+	select {
+	case clipProcessSemaphore <- struct{}{}:
+		return func() { <-clipProcessSemaphore }, true
+	default:
+		return nil, false
+	}
+}
+
+// handleClipProcessBusy responds that all clip processing slots are in use.
+func (c *Controller) handleClipProcessBusy(ctx echo.Context) error {
+	ctx.Response().Header().Set("Retry-After", clipProcessRetryAfter)
+	return c.HandleError(ctx, errors.NewStd("all clip processing slots in use"),
+		"Too many audio clips are being processed, please retry", http.StatusServiceUnavailable)
+}
+
+// clipProcessingRequested reports whether the request asks for clip processing.
+func clipProcessingRequested(ctx echo.Context) bool {
+	for _, param := range clipProcessingParams {
+		if ctx.QueryParam(param) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseClipProcessOptions reads the clip processing query parameters:
+//   - trim=true: keep only the detection window
+//   - padding: seconds of audio kept around the detection window when trimming (default 1, max 10)
+//   - format: wav, flac, mp3, opus, aac or alac (default: format of the stored clip)
+//   - bitrate: bitrate of lossy formats, e.g. 96k
+//   - normalize=true: adjust gain to the loudness target
+//   - eq=true: apply the configured equalizer filters
+//
+// An empty format is resolved per clip by clipFormatForPath.
+func (c *Controller) parseClipProcessOptions(ctx echo.Context) (*myaudio.ClipProcessOptions, error) {
+	opts := &myaudio.ClipProcessOptions{
+		Format:    strings.ToLower(ctx.QueryParam("format")),
+		Bitrate:   ctx.QueryParam("bitrate"),
+		Normalize: ctx.QueryParam("normalize") == "true",
+	}
+	if ctx.QueryParam("eq") == "true" {
+		eq := c.Settings.Realtime.Audio.Equalizer
+		opts.Equalizer = &eq
+	}
+
+	if ctx.QueryParam("trim") == "true" {
+		padding := clipTrimDefaultPadding
+		if value := ctx.QueryParam("padding"); value != "" {
+			seconds, err := strconv.ParseFloat(value, 64)
+			if err != nil || seconds < 0 || seconds > clipTrimMaxPadding.Seconds() {
+				return nil, errors.Newf("padding must be between 0 and %.0f seconds", clipTrimMaxPadding.Seconds()).
+					Component("api").
+					Category(errors.CategoryValidation).
+					Context("padding", value).
+					Build()
+			}
+			padding = time.Duration(seconds * float64(time.Second))
+		}
+		preCapture := time.Duration(c.Settings.Realtime.Audio.Export.PreCapture) * time.Second
+		opts.Offset, opts.Length = clipTrimWindow(preCapture, padding)
+	}
+
+	// Validate with a placeholder format when the clip format is used
+	check := *opts
+	if check.Format == "" {
+		check.Format = myaudio.ClipFormatWAV
+	}
+	if err := check.Validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// clipTrimWindow returns the offset and length of the detection window in a
+// clip with padding on both sides. Clips start preCapture before the analyzed
+// audio, which is conf.CaptureLength long.
+func clipTrimWindow(preCapture, padding time.Duration) (offset, length time.Duration) {
+	offset = max(preCapture-padding, 0)
+	end := preCapture + conf.CaptureLength*time.Second + padding
+	return offset, end - offset
+}
+
+// clipFormatForPath returns the processing format matching a clip's file
+// extension, so processed clips keep the stored format by default.
+func clipFormatForPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".flac":
+		return myaudio.FormatFLAC
+	case ".mp3":
+		return myaudio.FormatMP3
+	case ".opus", ".ogg":
+		return myaudio.FormatOpus
+	case ".m4a", ".aac":
+		return myaudio.FormatAAC
+	default:
+		return myaudio.ClipFormatWAV
+	}
+}
+
+// processClipFile processes the clip at absPath and returns the encoded
+// audio with its file extension.
+func (c *Controller) processClipFile(ctx context.Context, absPath string, opts *myaudio.ClipProcessOptions) (data []byte, ext string, err error) {
+	clipOpts := *opts
+	if clipOpts.Format == "" {
+		clipOpts.Format = clipFormatForPath(absPath)
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, clipProcessTimeout)
+	defer cancel()
+
+	data, err = myaudio.ProcessClipWithContext(reqCtx, absPath, c.Settings.Realtime.Audio.FfmpegPath, &clipOpts)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, myaudio.ClipFileExtension(clipOpts.Format), nil
+}
+
+// absoluteClipPath validates a clip path relative to the clips directory and
+// returns its absolute path.
+func (c *Controller) absoluteClipPath(clipPath string) (string, error) {
+	relPath, err := c.normalizeAndValidatePath(clipPath)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(c.SFS.BaseDir(), relPath), nil
+}
+
+// serveProcessedAudioClip serves a trimmed, filtered, normalized or
+// re-encoded copy of a clip. See parseClipProcessOptions for the options.
+func (c *Controller) serveProcessedAudioClip(ctx echo.Context, noteID, clipPath, filename string) error {
+	opts, err := c.parseClipProcessOptions(ctx)
+	if err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+	if c.Settings.Realtime.Audio.FfmpegPath == "" {
+		return c.HandleError(ctx, errors.NewStd("FFmpeg is not available"),
+			"FFmpeg is required to process audio clips", http.StatusServiceUnavailable)
+	}
+
+	absPath, err := c.absoluteClipPath(clipPath)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid clip path", http.StatusBadRequest)
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return c.HandleError(ctx, err, "Audio clip not found", http.StatusNotFound)
+	}
+
+	release, ok := tryAcquireClipProcessSlot()
+	if !ok {
+		return c.handleClipProcessBusy(ctx)
+	}
+	defer release()
+
+	data, ext, err := c.processClipFile(ctx.Request().Context(), absPath, opts)
+	if err != nil {
+		c.logErrorIfEnabled("Failed to process audio clip",
+			logger.String("note_id", noteID),
+			logger.Error(err),
+			logger.String("path", ctx.Request().URL.Path),
+			logger.String("ip", ctx.RealIP()),
+		)
+		return c.HandleError(ctx, err, "Failed to process audio clip", http.StatusInternalServerError)
+	}
+
+	filename = strings.TrimSuffix(filename, filepath.Ext(filename)) + ext
+	if isValidFilename(filename) {
+		disposition := "inline"
+		if ctx.QueryParam("download") == "true" {
+			disposition = "attachment"
+		}
+		ctx.Response().Header().Set("Content-Disposition",
+			fmt.Sprintf("%s; filename=%q; filename*=UTF-8''%s", disposition, filename, url.QueryEscape(filename)))
+	}
+	return ctx.Blob(http.StatusOK, audioMimeType(ext), data)
+}
+
+// ExportSearchClips handles POST /api/v2/search/export
+// Streams a zip archive with the clips of all detections matching a search,
+// up to searchExportMaxClips, and a manifest.csv describing them. The body
+// is a search request; the page is ignored. The clip processing query
+// parameters of the audio endpoint apply to every clip.
+func (c *Controller) ExportSearchClips(ctx echo.Context) error {
+	var req SearchRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request format", http.StatusBadRequest)
+	}
+	if err := c.validateAndNormalizeSearchRequest(ctx, &req); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	var opts *myaudio.ClipProcessOptions
+	if clipProcessingRequested(ctx) {
+		var err error
+		if opts, err = c.parseClipProcessOptions(ctx); err != nil {
+			return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+		}
+		if c.Settings.Realtime.Audio.FfmpegPath == "" {
+			return c.HandleError(ctx, errors.NewStd("FFmpeg is not available"),
+				"FFmpeg is required to process audio clips", http.StatusServiceUnavailable)
+		}
+	}
+
+	exportCtx, cancel := context.WithTimeout(ctx.Request().Context(), searchExportTimeout)
+	defer cancel()
+
+	records, err := c.searchExportRecords(exportCtx, &req)
+	if err != nil {
+		c.logErrorIfEnabled("Search for clip export failed",
+			logger.Error(err),
+			logger.String("path", ctx.Request().URL.Path),
+			logger.String("ip", ctx.RealIP()),
+		)
+		return c.HandleError(ctx, err, "Search failed", http.StatusInternalServerError)
+	}
+
+	filename := fmt.Sprintf("birdnet-clips-%s.zip", time.Now().Format("20060102-150405"))
+	ctx.Response().Header().Set(echo.HeaderContentType, "application/zip")
+	ctx.Response().Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Response().WriteHeader(http.StatusOK)
+
+	// Errors past this point cannot change the response, the archive ends early
+	if err := c.writeClipExportZip(exportCtx, ctx.Response(), records, opts); err != nil {
+		c.logErrorIfEnabled("Clip export ended early",
+			logger.Int("detections", len(records)),
+			logger.Error(err),
+			logger.String("path", ctx.Request().URL.Path),
+			logger.String("ip", ctx.RealIP()),
+		)
+	}
+	return nil
+}
+
+// searchExportRecords returns the detections matching req, up to searchExportMaxClips.
+func (c *Controller) searchExportRecords(ctx context.Context, req *SearchRequest) ([]datastore.DetectionRecord, error) {
+	var records []datastore.DetectionRecord
+	for page := 1; len(records) < searchExportMaxClips; page++ {
+		req.Page = page
+		filters := c.buildSearchFilters(req, ctx)
+		filters.PerPage = searchExportPageSize
+
+		results, total, err := c.DS.SearchDetections(&filters)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, results...)
+		if len(results) < searchExportPageSize || len(records) >= total {
+			break
+		}
+	}
+	if len(records) > searchExportMaxClips {
+		records = records[:searchExportMaxClips]
+	}
+	return records, nil
+}
+
+// writeClipExportZip writes the clips of records and their manifest as a zip
+// archive. Clips are copied as stored unless opts requests processing.
+func (c *Controller) writeClipExportZip(ctx context.Context, w io.Writer, records []datastore.DetectionRecord, opts *myaudio.ClipProcessOptions) error {
+	zw := zip.NewWriter(w)
+
+	manifest := [][]string{{"id", "timestamp", "common_name", "scientific_name", "confidence", "source", "verified", "locked", "file", "status"}}
+	for i := range records {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		record := &records[i]
+		file, status, err := c.addClipToZip(ctx, zw, record, opts)
+		if err != nil {
+			return err
+		}
+		manifest = append(manifest, []string{
+			record.ID,
+			record.Timestamp.Format(time.RFC3339),
+			record.CommonName,
+			record.ScientificName,
+			strconv.FormatFloat(record.Confidence, 'f', 4, 64),
+			cmp.Or(record.Source, record.Device),
+			record.Verified,
+			strconv.FormatBool(record.Locked),
+			file,
+			status,
+		})
+	}
+
+	mw, err := zw.CreateHeader(&zip.FileHeader{Name: "manifest.csv", Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return err
+	}
+	cw := csv.NewWriter(mw)
+	if err := cw.WriteAll(manifest); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// addClipToZip adds the clip of a detection to the archive and returns its
+// name in the archive and its manifest status. Clips that cannot be read or
+// processed are reported in the manifest; only write errors are returned.
+func (c *Controller) addClipToZip(ctx context.Context, zw *zip.Writer, record *datastore.DetectionRecord, opts *myaudio.ClipProcessOptions) (file, status string, err error) {
+	if record.AudioFilePath == "" {
+		return "", clipExportStatusNoAudio, nil
+	}
+	absPath, err := c.absoluteClipPath(record.AudioFilePath)
+	if err != nil {
+		return "", clipExportStatusMissing, nil
+	}
+	if _, err := os.Stat(absPath); err != nil {
+		return "", clipExportStatusMissing, nil
+	}
+
+	base := filepath.Base(absPath)
+	header := &zip.FileHeader{Method: zip.Store, Modified: record.Timestamp}
+	if opts == nil {
+		header.Name = "clips/" + record.ID + "_" + base
+		src, err := os.Open(absPath) //nolint:gosec // G304: path is validated by SecureFS
+		if err != nil {
+			return "", clipExportStatusMissing, nil
+		}
+		defer func() { _ = src.Close() }()
+		dst, err := zw.CreateHeader(header)
+		if err != nil {
+			return "", "", err
+		}
+		if _, err := io.Copy(dst, src); err != nil {
+			return "", "", err
+		}
+		return header.Name, clipExportStatusOK, nil
+	}
+
+	data, ext, err := c.processClipFile(ctx, absPath, opts)
+	if err != nil {
+		c.logWarnIfEnabled("Failed to process clip for export",
+			logger.String("detection_id", record.ID),
+			logger.Error(err))
+		return "", clipExportStatusFailed, nil
+	}
+	header.Name = "clips/" + record.ID + "_" + strings.TrimSuffix(base, filepath.Ext(base)) + ext
+	dst, err := zw.CreateHeader(header)
+	if err != nil {
+		return "", "", err
+	}
+	if _, err := dst.Write(data); err != nil {
+		return "", "", err
+	}
+	return header.Name, clipExportStatusOK, nil
+}
@@ -0,0 +1,156 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+	"github.com/tphakala/birdnet-go/internal/securefs"
+)
+
+func TestClipTrimWindow(t *testing.T) {
+	t.Parallel()
+
+	offset, length := clipTrimWindow(3*time.Second, time.Second)
+	assert.Equal(t, 2*time.Second, offset)
+	assert.Equal(t, 5*time.Second, length)
+
+	// Padding beyond the pre-capture starts at the beginning of the clip
+	offset, length = clipTrimWindow(time.Second, 2*time.Second)
+	assert.Equal(t, time.Duration(0), offset)
+	assert.Equal(t, 6*time.Second, length)
+}
+
+func TestParseClipProcessOptions(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.Settings{}
+	settings.Realtime.Audio.Export.PreCapture = 3
+	c := &Controller{Settings: settings}
+
+	parse := func(query string) error {
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/1?"+query, http.NoBody)
+		ctx := echo.New().NewContext(req, httptest.NewRecorder())
+		_, err := c.parseClipProcessOptions(ctx)
+		return err
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/1?trim=true&padding=0.5&format=mp3&bitrate=96k&normalize=true", http.NoBody)
+	ctx := echo.New().NewContext(req, httptest.NewRecorder())
+	require.True(t, clipProcessingRequested(ctx))
+	opts, err := c.parseClipProcessOptions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2500*time.Millisecond, opts.Offset)
+	assert.Equal(t, 4*time.Second, opts.Length)
+	assert.Equal(t, "mp3", opts.Format)
+	assert.True(t, opts.Normalize)
+	assert.Nil(t, opts.Equalizer)
+
+	require.NoError(t, parse("eq=true"))
+	require.Error(t, parse("format=exe"))
+	require.Error(t, parse("bitrate=fast"))
+	require.Error(t, parse("trim=true&padding=60"))
+
+	ctx = echo.New().NewContext(httptest.NewRequest(http.MethodGet, "/api/v2/audio/1?download=true", http.NoBody), httptest.NewRecorder())
+	assert.False(t, clipProcessingRequested(ctx))
+}
+
+func TestClipFormatForPath(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "flac", clipFormatForPath("clips/a.FLAC"))
+	assert.Equal(t, "aac", clipFormatForPath("clips/a.m4a"))
+	assert.Equal(t, "wav", clipFormatForPath("clips/a.wav"))
+}
+
+func TestExportSearchClips(t *testing.T) {
+	t.Parallel()
+
+	tempDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(tempDir, "2024", "05"), 0o750))
+	clip := []byte("RIFF-test-clip")
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "2024", "05", "turdus_merula.wav"), clip, 0o600))
+	sfs, err := securefs.New(tempDir)
+	require.NoError(t, err)
+
+	timestamp := time.Date(2024, 5, 1, 6, 30, 0, 0, time.UTC)
+	mockDS := mocks.NewMockInterface(t)
+	mockDS.On("SearchDetections", mock.Anything).Return([]datastore.DetectionRecord{
+		{ID: "1", Timestamp: timestamp, CommonName: "Eurasian Blackbird", ScientificName: "Turdus merula", Confidence: 0.91, AudioFilePath: "2024/05/turdus_merula.wav", Source: "Garden"},
+		{ID: "2", Timestamp: timestamp, CommonName: "European Robin", ScientificName: "Erithacus rubecula", Confidence: 0.8},
+		{ID: "3", Timestamp: timestamp, CommonName: "Great Tit", ScientificName: "Parus major", Confidence: 0.75, AudioFilePath: "2024/05/removed.wav"},
+	}, 3, nil).Once()
+
+	c := &Controller{DS: mockDS, Settings: &conf.Settings{}, SFS: sfs}
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/search/export", strings.NewReader(`{"species":"","page":1}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	require.NoError(t, c.ExportSearchClips(echo.New().NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/zip", rec.Header().Get(echo.HeaderContentType))
+
+	zr, err := zip.NewReader(bytes.NewReader(rec.Body.Bytes()), int64(rec.Body.Len()))
+	require.NoError(t, err)
+	files := make(map[string][]byte)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		data, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		files[f.Name] = data
+	}
+	assert.Equal(t, clip, files["clips/1_turdus_merula.wav"])
+
+	rows, err := csv.NewReader(bytes.NewReader(files["manifest.csv"])).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+	assert.Equal(t, []string{"1", "2024-05-01T06:30:00Z", "Eurasian Blackbird", "Turdus merula", "0.9100", "Garden", "", "false", "clips/1_turdus_merula.wav", "ok"}, rows[1])
+	assert.Equal(t, "no_audio", rows[2][9])
+	assert.Equal(t, "missing", rows[3][9])
+}
+
+//nolint:paralleltest // fills the global clip processing slots
+func TestServeProcessedAudioClipBusy(t *testing.T) {
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "clip.wav"), []byte("RIFF-test-clip"), 0o600))
+	sfs, err := securefs.New(tempDir)
+	require.NoError(t, err)
+	settings := &conf.Settings{}
+	settings.Realtime.Audio.FfmpegPath = "/usr/bin/ffmpeg"
+	c := &Controller{Settings: settings, SFS: sfs}
+
+	// All slots are held by clips being processed
+	for range maxConcurrentClipProcesses {
+		_, ok := tryAcquireClipProcessSlot()
+		require.True(t, ok)
+	}
+	t.Cleanup(func() {
+		for range maxConcurrentClipProcesses {
+			<-clipProcessSemaphore
+		}
+	})
+	_, ok := tryAcquireClipProcessSlot()
+	require.False(t, ok)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/1?normalize=true", http.NoBody)
+	rec := httptest.NewRecorder()
+	_ = c.serveProcessedAudioClip(echo.New().NewContext(req, rec), "1", "clip.wav", "clip.wav")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, clipProcessRetryAfter, rec.Header().Get("Retry-After"))
+}
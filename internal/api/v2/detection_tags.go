@@ -0,0 +1,183 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	datastoreV2 "github.com/tphakala/birdnet-go/internal/datastore/v2"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// maxTagsPerRequest limits how many tags can be added in a single request.
+const maxTagsPerRequest = 20
+
+// TagsRequest is the request body for adding tags to a detection.
+type TagsRequest struct {
+	Tags []string `json:"tags"`
+}
+
+// TagsResponse lists the tags of a detection.
+type TagsResponse struct {
+	DetectionID uint     `json:"detectionId"`
+	Tags        []string `json:"tags"`
+}
+
+// TagCountResponse is a tag with the number of detections carrying it.
+type TagCountResponse struct {
+	Tag   string `json:"tag"`
+	Count int64  `json:"count"`
+}
+
+// initDetectionTagRoutes registers detection tag endpoints.
+// Tags are stored in the v2 schema only, so the routes require the v2 manager.
+func (c *Controller) initDetectionTagRoutes() {
+	if c.V2Manager == nil {
+		return
+	}
+
+	useV2Prefix, isMySQL := c.v2RepoOptions()
+	c.detectionTagRepo = repository.NewDetectionRepository(c.V2Manager.DB(), useV2Prefix, isMySQL)
+
+	// Public read endpoints
+	c.Group.GET("/detections/tags", c.ListDetectionTags)
+	c.Group.GET("/detections/:id/tags", c.GetDetectionTags)
+
+	// Protected endpoints
+	protected := c.Group.Group("/detections", c.authMiddleware)
+	protected.POST("/:id/tags", c.AddDetectionTags)
+	protected.DELETE("/:id/tags/:tag", c.RemoveDetectionTag)
+}
+
+// ListDetectionTags returns all tags in use with their detection counts.
+func (c *Controller) ListDetectionTags(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Detection tags")
+	}
+
+	counts, err := c.detectionTagRepo.GetAllTags(ctx.Request().Context())
+	if err != nil {
+		c.logErrorIfEnabled("failed to list detection tags", logger.Error(err))
+		return c.HandleError(ctx, err, "Failed to list tags", http.StatusInternalServerError)
+	}
+
+	tags := make([]TagCountResponse, 0, len(counts))
+	for _, tc := range counts {
+		tags = append(tags, TagCountResponse{Tag: tc.Tag, Count: tc.Count})
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"tags":  tags,
+		"count": len(tags),
+	})
+}
+
+// GetDetectionTags returns the tags of a single detection.
+func (c *Controller) GetDetectionTags(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Detection tags")
+	}
+
+	id, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid detection ID", http.StatusBadRequest)
+	}
+
+	exists, err := c.detectionTagRepo.Exists(ctx.Request().Context(), id)
+	if err != nil {
+		return c.handleTagError(ctx, err, "Failed to get tags")
+	}
+	if !exists {
+		return c.HandleError(ctx, repository.ErrDetectionNotFound, "Detection not found", http.StatusNotFound)
+	}
+
+	return c.respondWithTags(ctx, id)
+}
+
+// AddDetectionTags attaches one or more tags to a detection.
+func (c *Controller) AddDetectionTags(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Detection tags")
+	}
+
+	id, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid detection ID", http.StatusBadRequest)
+	}
+
+	var req TagsRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if len(req.Tags) == 0 {
+		return c.HandleError(ctx, errors.NewStd("no tags provided"), "At least one tag is required", http.StatusBadRequest)
+	}
+	if len(req.Tags) > maxTagsPerRequest {
+		return c.HandleError(ctx, errors.NewStd("too many tags"), "Too many tags in request", http.StatusBadRequest)
+	}
+
+	if err := c.detectionTagRepo.AddTags(ctx.Request().Context(), id, req.Tags); err != nil {
+		return c.handleTagError(ctx, err, "Failed to add tags")
+	}
+
+	c.invalidateDetectionCache()
+	c.logInfoIfEnabled("detection tags added",
+		logger.Uint64("detection_id", uint64(id)),
+		logger.Int("count", len(req.Tags)))
+
+	return c.respondWithTags(ctx, id)
+}
+
+// RemoveDetectionTag removes a single tag from a detection.
+func (c *Controller) RemoveDetectionTag(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Detection tags")
+	}
+
+	id, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid detection ID", http.StatusBadRequest)
+	}
+
+	if err := c.detectionTagRepo.RemoveTag(ctx.Request().Context(), id, ctx.Param("tag")); err != nil {
+		return c.handleTagError(ctx, err, "Failed to remove tag")
+	}
+
+	c.invalidateDetectionCache()
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// respondWithTags writes the current tags of a detection.
+func (c *Controller) respondWithTags(ctx echo.Context, detectionID uint) error {
+	tags, err := c.detectionTagRepo.GetTags(ctx.Request().Context(), detectionID)
+	if err != nil {
+		c.logErrorIfEnabled("failed to get detection tags",
+			logger.Uint64("detection_id", uint64(detectionID)),
+			logger.Error(err))
+		return c.HandleError(ctx, err, "Failed to get tags", http.StatusInternalServerError)
+	}
+	if tags == nil {
+		tags = []string{}
+	}
+	return ctx.JSON(http.StatusOK, TagsResponse{DetectionID: detectionID, Tags: tags})
+}
+
+// handleTagError maps tag repository errors to HTTP responses.
+func (c *Controller) handleTagError(ctx echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, repository.ErrInvalidTag):
+		return c.HandleError(ctx, err,
+			fmt.Sprintf("Tags must be between 1 and %d characters", entities.MaxTagLength), http.StatusBadRequest)
+	case errors.Is(err, repository.ErrDetectionNotFound):
+		return c.HandleError(ctx, err, "Detection not found", http.StatusNotFound)
+	case errors.Is(err, repository.ErrTagNotFound):
+		return c.HandleError(ctx, err, "Tag not found on detection", http.StatusNotFound)
+	default:
+		c.logErrorIfEnabled(message, logger.Error(err))
+		return c.HandleError(ctx, err, message, http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,74 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	datastoreV2 "github.com/tphakala/birdnet-go/internal/datastore/v2"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+)
+
+// fakeTagRepository serves the tags of fixed detections.
+type fakeTagRepository struct {
+	repository.DetectionRepository
+	tags map[uint][]string
+}
+
+func (f *fakeTagRepository) Exists(_ context.Context, id uint) (bool, error) {
+	_, exists := f.tags[id]
+	return exists, nil
+}
+
+func (f *fakeTagRepository) GetTags(_ context.Context, id uint) ([]string, error) {
+	return f.tags[id], nil
+}
+
+// TestGetDetectionTags verifies that tags are served for existing detections only.
+// Not parallel: toggles the global enhanced database flag.
+func TestGetDetectionTags(t *testing.T) {
+	datastoreV2.SetEnhancedDatabaseMode()
+	t.Cleanup(datastoreV2.ResetDatabaseMode)
+
+	c := &Controller{
+		Settings:         &conf.Settings{},
+		detectionTagRepo: &fakeTagRepository{tags: map[uint][]string{1: {"nest"}, 2: nil}},
+	}
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantTags   []string
+	}{
+		{name: "tagged", id: "1", wantStatus: http.StatusOK, wantTags: []string{"nest"}},
+		{name: "untagged", id: "2", wantStatus: http.StatusOK, wantTags: []string{}},
+		{name: "unknown detection", id: "3", wantStatus: http.StatusNotFound},
+		{name: "invalid ID", id: "abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/detections/"+tt.id+"/tags", http.NoBody)
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, rec)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(tt.id)
+
+			require.NoError(t, c.GetDetectionTags(ctx))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var resp TagsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantTags, resp.Tags)
+		})
+	}
+}
@@ -8,12 +8,15 @@ import (
 	"regexp"
 	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/patrickmn/go-cache"
 	"github.com/tphakala/birdnet-go/internal/conf"
 	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/logger"
 	"github.com/tphakala/birdnet-go/internal/suncalc"
@@ -153,6 +156,7 @@ type DetectionResponse struct {
 	Locked             bool              `json:"locked"`
 	Comments           []CommentResponse `json:"comments,omitempty"`
 	Weather            *WeatherInfo      `json:"weather,omitempty"`
+	Bearing            *float64          `json:"bearing,omitempty"` // Compass bearing in degrees of the sound, from microphone arrays
 	TimeOfDay          string            `json:"timeOfDay,omitempty"`
 	IsNewSpecies       bool              `json:"isNewSpecies,omitempty"`       // First seen within tracking window
 	DaysSinceFirstSeen int               `json:"daysSinceFirstSeen,omitempty"` // Days since species was first detected
@@ -220,6 +224,9 @@ type detectionQueryParams struct {
 	Verified   string
 	Location   string
 	Locked     string
+	Tags       []string
+	TagMatch   string
+	Comment    string
 	// Sorting
 	SortBy string
 	// Include additional data
@@ -229,12 +236,12 @@ type detectionQueryParams struct {
 // advancedSearchCacheKey generates a deterministic cache key for advanced search queries.
 // Includes all filter parameters to avoid cache collisions.
 func (p *detectionQueryParams) advancedSearchCacheKey() string {
-	return fmt.Sprintf("adv_search:%s:%d:%d:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s",
+	return fmt.Sprintf("adv_search:%s:%d:%d:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s:%s",
 		p.Search, p.NumResults, p.Offset,
 		p.Confidence, p.TimeOfDay, p.HourRange,
 		p.Verified, p.Location, p.Locked,
 		p.Species, p.Date, p.StartDate+":"+p.EndDate,
-		p.SortBy)
+		p.SortBy, strings.Join(p.Tags, ","), p.TagMatch, p.Comment)
 }
 
 // parseDetectionQueryParams extracts and validates query parameters from the request
@@ -254,6 +261,8 @@ func (c *Controller) parseDetectionQueryParams(ctx echo.Context) (*detectionQuer
 		Verified:   ctx.QueryParam("verified"),
 		Location:   ctx.QueryParam("location"),
 		Locked:     ctx.QueryParam("locked"),
+		TagMatch:   ctx.QueryParam("tagMatch"),
+		Comment:    strings.TrimSpace(ctx.QueryParam("comment")),
 		// Sorting
 		SortBy: ctx.QueryParam("sortBy"),
 		// Include weather data
@@ -302,6 +311,11 @@ func (c *Controller) parseDetectionQueryParams(ctx echo.Context) (*detectionQuer
 		}
 	}
 
+	// Parse and validate tag and comment filters
+	if err := parseTagFilterParams(params, ctx.QueryParam("tags")); err != nil {
+		return nil, err
+	}
+
 	// Validate hour parameter based on query type
 	if params.QueryType == "hourly" {
 		// Hourly queries require a single valid integer hour (0-23), not a range
@@ -321,6 +335,40 @@ func (c *Controller) parseDetectionQueryParams(ctx echo.Context) (*detectionQuer
 	return params, nil
 }
 
+// parseTagFilterParams parses the comma-separated tags parameter and validates
+// the tagMatch and comment parameters.
+func parseTagFilterParams(params *detectionQueryParams, tagsParam string) error {
+	for raw := range strings.SplitSeq(tagsParam, ",") {
+		if strings.TrimSpace(raw) == "" {
+			continue
+		}
+		tag, err := repository.NormalizeTag(raw)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusBadRequest,
+				fmt.Sprintf("invalid tag '%s': tags must be 1-%d characters", raw, entities.MaxTagLength))
+		}
+		if !slices.Contains(params.Tags, tag) {
+			params.Tags = append(params.Tags, tag)
+		}
+	}
+	if len(params.Tags) > maxSearchTags {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("too many tags: maximum is %d", maxSearchTags))
+	}
+
+	switch params.TagMatch {
+	case "", QueryValueAny, tagMatchAll:
+	default:
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("invalid tagMatch parameter '%s'. Use 'any' or 'all'", params.TagMatch))
+	}
+
+	if len(params.Comment) > maxCommentQueryLength {
+		return echo.NewHTTPError(http.StatusBadRequest,
+			fmt.Sprintf("comment parameter exceeds maximum length of %d characters", maxCommentQueryLength))
+	}
+	return nil
+}
+
 // validateDateParameters validates start_date and end_date parameters
 func (c *Controller) validateDateParameters(startDateStr, endDateStr string, ctx echo.Context) error {
 	// Validate individual date formats
@@ -538,6 +586,7 @@ func (c *Controller) getDetectionsByQueryType(params *detectionQueryParams) ([]d
 	hasAdvancedFilters := params.Confidence != "" || params.TimeOfDay != "" ||
 		params.HourRange != "" || params.Verified != "" ||
 		params.Location != "" || params.Locked != "" ||
+		len(params.Tags) > 0 || params.Comment != "" ||
 		(params.SortBy != "" && params.SortBy != "date_desc")
 
 	switch params.QueryType {
@@ -589,6 +638,7 @@ func (c *Controller) noteToDetectionResponse(note *datastore.Note, includeWeathe
 		CommonName:     note.CommonName,
 		Confidence:     note.Confidence,
 		Locked:         note.Locked,
+		Bearing:        note.Bearing,
 	}
 
 	c.applySpeciesTrackingMetadata(&detection, note.ScientificName)
@@ -927,6 +977,11 @@ func (c *Controller) buildAdvancedSearchFilters(params *detectionQueryParams) da
 		filters.Locked = &locked
 	}
 
+	// Apply tag and comment filters
+	filters.Tags = params.Tags
+	filters.TagsMatchAll = params.TagMatch == tagMatchAll
+	filters.CommentQuery = params.Comment
+
 	// Apply sorting
 	filters.SortBy = params.SortBy
 
@@ -6,6 +6,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"runtime"
@@ -2053,3 +2054,45 @@ func TestTrueConcurrentPlatformSpecific(t *testing.T) {
 	assert.Equal(t, int32(0), failures, "There should be no unexpected failures")
 	assert.Equal(t, int32(numConcurrent), successes+conflicts, "All requests should either succeed or get conflict") // #nosec G115 -- numConcurrent is a small test constant (3-10), no overflow risk
 }
+
+// TestParseTagFilterParams tests parsing of the tags, tagMatch and comment query parameters.
+func TestParseTagFilterParams(t *testing.T) {
+	t.Parallel()
+
+	// Build more distinct tags than allowed
+	manyTags := make([]string, 0, maxSearchTags+1)
+	for i := range maxSearchTags + 1 {
+		manyTags = append(manyTags, fmt.Sprintf("tag%d", i))
+	}
+
+	tests := []struct {
+		name      string
+		tags      string
+		tagMatch  string
+		comment   string
+		wantTags  []string
+		wantError bool
+	}{
+		{name: "empty", tags: "", wantTags: nil},
+		{name: "normalizes and dedupes", tags: "Feeder, feeder ,,Dawn", wantTags: []string{"feeder", "dawn"}},
+		{name: "match all", tags: "owl", tagMatch: "all", wantTags: []string{"owl"}},
+		{name: "invalid match", tags: "owl", tagMatch: "some", wantError: true},
+		{name: "tag too long", tags: strings.Repeat("x", 65), wantError: true},
+		{name: "too many tags", tags: strings.Join(manyTags, ","), wantError: true},
+		{name: "comment too long", comment: strings.Repeat("c", maxCommentQueryLength+1), wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			params := &detectionQueryParams{TagMatch: tt.tagMatch, Comment: tt.comment}
+			err := parseTagFilterParams(params, tt.tags)
+			if tt.wantError {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantTags, params.Tags)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	datastoreV2 "github.com/tphakala/birdnet-go/internal/datastore/v2"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/hub"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+const (
+	// hubNodeContextKey holds the authenticated remote node name.
+	hubNodeContextKey = "hub_node"
+
+	// hubClipDir is the directory under the export path holding clips received from nodes.
+	hubClipDir = "hub"
+)
+
+// hubClipExtensions lists the clip formats accepted from remote nodes.
+var hubClipExtensions = []string{".wav", ".flac", ".aac", ".opus", ".mp3", ".m4a"}
+
+// unsafePathChars matches characters not allowed in node directory and clip names.
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// hubIngester is implemented by datastores that can record detections pushed
+// by remote nodes. Only the v2 datastore supports it.
+type hubIngester interface {
+	SaveIngested(note *datastore.Note, results []datastore.Results, remoteID uint) (id uint, duplicate bool, err error)
+	IngestedDetectionID(nodeName string, remoteID uint) (uint, error)
+	SetClipName(id uint, clipName string) error
+}
+
+// initHubRoutes registers multi-station hub endpoints. Ingestion endpoints
+// authenticate remote nodes with per-node bearer tokens from the hub settings.
+func (c *Controller) initHubRoutes() {
+	if c.V2Manager == nil {
+		return
+	}
+
+	hubGroup := c.Group.Group("/hub")
+	hubGroup.GET("/nodes", c.ListHubNodes, c.authMiddleware)
+
+	ingest := hubGroup.Group("", c.hubNodeAuthMiddleware)
+	ingest.POST("/detections", c.IngestHubDetection)
+	ingest.PUT("/detections/:remoteId/clip", c.UploadHubClip)
+}
+
+// isHubClipUpload reports whether the request is a hub clip upload, which is
+// exempt from the API-wide body limit.
+func isHubClipUpload(ctx echo.Context) bool {
+	req := ctx.Request()
+	return req.Method == http.MethodPut &&
+		strings.HasPrefix(req.URL.Path, "/api/v2/hub/detections/") &&
+		strings.HasSuffix(req.URL.Path, "/clip")
+}
+
+// hubNodeAuthMiddleware authenticates a remote node by its bearer token and
+// stores the node name in the request context.
+func (c *Controller) hubNodeAuthMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		settings := c.getSettingsOrFallback()
+		if settings == nil || !settings.Hub.Enabled {
+			return c.HandleError(ctx, errors.NewStd("hub not enabled"),
+				"Hub ingestion is not enabled", http.StatusNotFound)
+		}
+
+		token, ok := strings.CutPrefix(ctx.Request().Header.Get("Authorization"), "Bearer ")
+		if !ok || token == "" {
+			return c.HandleError(ctx, errors.NewStd("missing bearer token"),
+				"Node token required", http.StatusUnauthorized)
+		}
+
+		node := matchHubNode(settings.Hub.Nodes, token)
+		if node == "" {
+			c.logWarnIfEnabled("rejected hub request with unknown node token",
+				logger.String("ip", ctx.RealIP()),
+				logger.String("path", ctx.Request().URL.Path))
+			return c.HandleError(ctx, errors.NewStd("invalid node token"),
+				"Invalid node token", http.StatusUnauthorized)
+		}
+
+		ctx.Set(hubNodeContextKey, node)
+		return next(ctx)
+	}
+}
+
+// matchHubNode returns the name of the node owning token, or "" if none does.
+// Every configured token is compared in constant time.
+func matchHubNode(nodes []conf.HubNode, token string) string {
+	match := ""
+	for i := range nodes {
+		if subtle.ConstantTimeCompare([]byte(nodes[i].Token), []byte(token)) == 1 {
+			match = nodes[i].Name
+		}
+	}
+	return match
+}
+
+// hubIngesterOrConflict returns the datastore as a hubIngester, writing a
+// conflict response when the enhanced database is not in use.
+func (c *Controller) hubIngesterOrConflict(ctx echo.Context) (hubIngester, error) {
+	ingester, ok := c.DS.(hubIngester)
+	if !datastoreV2.IsEnhancedDatabase() || !ok {
+		return nil, c.requireV2For(ctx, "Hub ingestion")
+	}
+	return ingester, nil
+}
+
+// IngestHubDetection stores a detection pushed by a remote node.
+// Returns 201 for a new detection and 200 when the detection was already received.
+func (c *Controller) IngestHubDetection(ctx echo.Context) error {
+	ingester, err := c.hubIngesterOrConflict(ctx)
+	if ingester == nil {
+		return err
+	}
+
+	var payload hub.Detection
+	if err := ctx.Bind(&payload); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if err := payload.Validate(); err != nil {
+		return c.HandleError(ctx, err, err.Error(), http.StatusBadRequest)
+	}
+
+	node, _ := ctx.Get(hubNodeContextKey).(string)
+	note, results := payload.ToNote(node, time.Local)
+
+	id, duplicate, err := ingester.SaveIngested(note, results, payload.RemoteID)
+	if err != nil {
+		c.logErrorIfEnabled("failed to store hub detection",
+			logger.String("node", node),
+			logger.Uint64("remote_id", uint64(payload.RemoteID)),
+			logger.Error(err))
+		return c.HandleError(ctx, err, "Failed to store detection", http.StatusInternalServerError)
+	}
+
+	status := http.StatusCreated
+	if duplicate {
+		status = http.StatusOK
+	} else {
+		c.logDebugIfEnabled("stored hub detection",
+			logger.String("node", node),
+			logger.Uint64("remote_id", uint64(payload.RemoteID)),
+			logger.Uint64("id", uint64(id)),
+			logger.String("species", payload.ScientificName))
+	}
+	return ctx.JSON(status, hub.IngestResponse{ID: id, Duplicate: duplicate})
+}
+
+// UploadHubClip stores the audio clip of a detection previously pushed by the node.
+// The body is the raw audio file; the filename query parameter names the file.
+func (c *Controller) UploadHubClip(ctx echo.Context) error {
+	ingester, err := c.hubIngesterOrConflict(ctx)
+	if ingester == nil {
+		return err
+	}
+
+	settings := c.getSettingsOrFallback()
+	if !settings.Hub.AcceptClips {
+		return c.HandleError(ctx, errors.NewStd("clip uploads disabled"),
+			"Hub does not accept clips", http.StatusForbidden)
+	}
+
+	node, _ := ctx.Get(hubNodeContextKey).(string)
+	remoteID, err := parseUintParam(ctx, "remoteId")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid detection ID", http.StatusBadRequest)
+	}
+
+	fileName := sanitizeHubPathElement(filepath.Base(ctx.QueryParam("filename")))
+	if !slices.Contains(hubClipExtensions, strings.ToLower(filepath.Ext(fileName))) {
+		return c.HandleError(ctx, errors.NewStd("unsupported clip format"),
+			"Unsupported clip format", http.StatusBadRequest)
+	}
+
+	id, err := ingester.IngestedDetectionID(node, remoteID)
+	if err != nil {
+		if errors.Is(err, repository.ErrDetectionNotFound) {
+			return c.HandleError(ctx, err, "Detection not received from this node", http.StatusNotFound)
+		}
+		return c.HandleError(ctx, err, "Failed to look up detection", http.StatusInternalServerError)
+	}
+
+	maxBytes := int64(settings.Hub.MaxClipSizeMB) << 20
+	if ctx.Request().ContentLength > maxBytes {
+		return c.HandleError(ctx, errors.NewStd("clip too large"), "Clip exceeds size limit", http.StatusRequestEntityTooLarge)
+	}
+
+	note, err := c.DS.Get(fmt.Sprint(id))
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to look up detection", http.StatusInternalServerError)
+	}
+	date, err := time.Parse(time.DateOnly, note.Date)
+	if err != nil {
+		date = time.Now()
+	}
+
+	// Clip names are relative to the export path so the media endpoints can serve them
+	clipName := filepath.ToSlash(filepath.Join(hubClipDir, sanitizeHubPathElement(node),
+		date.Format("2006"), date.Format("01"), fileName))
+	if err := writeHubClip(filepath.Join(settings.Realtime.Audio.Export.Path, clipName), ctx.Request().Body, maxBytes); err != nil {
+		if errors.Is(err, errHubClipTooLarge) {
+			return c.HandleError(ctx, err, "Clip exceeds size limit", http.StatusRequestEntityTooLarge)
+		}
+		c.logErrorIfEnabled("failed to store hub clip",
+			logger.String("node", node),
+			logger.String("clip_name", clipName),
+			logger.Error(err))
+		return c.HandleError(ctx, err, "Failed to store clip", http.StatusInternalServerError)
+	}
+
+	if err := ingester.SetClipName(id, clipName); err != nil {
+		return c.HandleError(ctx, err, "Failed to update detection", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusCreated, map[string]any{"id": id, "clipName": clipName})
+}
+
+// ListHubNodes returns the names of the nodes allowed to push to this hub.
+func (c *Controller) ListHubNodes(ctx echo.Context) error {
+	settings := c.getSettingsOrFallback()
+	nodes := make([]string, 0, len(settings.Hub.Nodes))
+	for i := range settings.Hub.Nodes {
+		nodes = append(nodes, settings.Hub.Nodes[i].Name)
+	}
+	return ctx.JSON(http.StatusOK, hub.NodesResponse{Nodes: nodes})
+}
+
+// nodeFilterMiddleware applies the optional "node" query parameter to the
+// request context, restricting analytics queries to one node's sources.
+// Node filtering requires the enhanced database, where sources carry node names.
+func (c *Controller) nodeFilterMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(ctx echo.Context) error {
+		node := strings.TrimSpace(ctx.QueryParam("node"))
+		if node == "" {
+			return next(ctx)
+		}
+		if !datastoreV2.IsEnhancedDatabase() {
+			return c.requireV2For(ctx, "Node filters")
+		}
+
+		req := ctx.Request()
+		ctx.SetRequest(req.WithContext(repository.WithNodeFilter(req.Context(), node)))
+		return next(ctx)
+	}
+}
+
+// topBirdsDataContext is implemented by datastores whose top birds query honours
+// the node filter carried by a context.
+type topBirdsDataContext interface {
+	GetTopBirdsDataContext(ctx context.Context, selectedDate string, minConfidenceNormalized float64, limit int) ([]datastore.Note, error)
+}
+
+// batchHourlyOccurrencesContext is implemented by datastores whose batch hourly
+// query honours the node filter carried by a context.
+type batchHourlyOccurrencesContext interface {
+	GetBatchHourlyOccurrencesContext(ctx context.Context, date string, species []string, minConfidence float64) (map[string][24]int, error)
+}
+
+// getTopBirdsData returns the top birds for a date, filtered by node when requested.
+func (c *Controller) getTopBirdsData(reqCtx context.Context, selectedDate string, minConfidence float64, limit int) ([]datastore.Note, error) {
+	if ds, ok := c.DS.(topBirdsDataContext); ok {
+		return ds.GetTopBirdsDataContext(reqCtx, selectedDate, minConfidence, limit)
+	}
+	return c.DS.GetTopBirdsData(selectedDate, minConfidence, limit)
+}
+
+// getBatchHourlyOccurrences returns hourly counts for species on a date, filtered by node when requested.
+func (c *Controller) getBatchHourlyOccurrences(reqCtx context.Context, date string, species []string, minConfidence float64) (map[string][24]int, error) {
+	if ds, ok := c.DS.(batchHourlyOccurrencesContext); ok {
+		return ds.GetBatchHourlyOccurrencesContext(reqCtx, date, species, minConfidence)
+	}
+	return c.DS.GetBatchHourlyOccurrences(date, species, minConfidence)
+}
+
+// errHubClipTooLarge indicates an uploaded clip exceeded the configured size limit.
+var errHubClipTooLarge = errors.NewStd("clip too large")
+
+// writeHubClip writes at most maxBytes from r to path. The file is written to a
+// temporary name and renamed, so a failed upload never leaves a partial clip.
+func writeHubClip(path string, r io.Reader, maxBytes int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".hub-upload-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	n, err := io.Copy(tmp, io.LimitReader(r, maxBytes+1))
+	closeErr := tmp.Close()
+	switch {
+	case err != nil:
+		return err
+	case closeErr != nil:
+		return closeErr
+	case n > maxBytes:
+		return errHubClipTooLarge
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
+
+// sanitizeHubPathElement makes a node or file name safe to use as a single path element.
+func sanitizeHubPathElement(name string) string {
+	name = unsafePathChars.ReplaceAllString(name, "_")
+	name = strings.TrimLeft(name, ".")
+	if name == "" {
+		return "_"
+	}
+	return name
+}
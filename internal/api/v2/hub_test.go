@@ -0,0 +1,201 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+	datastoreV2 "github.com/tphakala/birdnet-go/internal/datastore/v2"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	"github.com/tphakala/birdnet-go/internal/hub"
+)
+
+// fakeHubIngester records ingested detections keyed by node and remote ID.
+type fakeHubIngester struct {
+	*mocks.MockInterface
+	ingested map[string]uint
+	notes    []*datastore.Note
+}
+
+func (f *fakeHubIngester) SaveIngested(note *datastore.Note, _ []datastore.Results, remoteID uint) (id uint, duplicate bool, err error) {
+	key := note.SourceNode + "/" + strconv.FormatUint(uint64(remoteID), 10)
+	if existing, ok := f.ingested[key]; ok {
+		return existing, true, nil
+	}
+	f.notes = append(f.notes, note)
+	id = uint(len(f.notes))
+	f.ingested[key] = id
+	return id, false, nil
+}
+
+func (f *fakeHubIngester) IngestedDetectionID(string, uint) (uint, error) {
+	return 0, repository.ErrDetectionNotFound
+}
+
+func (f *fakeHubIngester) SetClipName(uint, string) error { return nil }
+
+func hubTestSettings() *conf.Settings {
+	settings := &conf.Settings{}
+	settings.Hub.Enabled = true
+	settings.Hub.Nodes = []conf.HubNode{{Name: "north", Token: "north-token"}, {Name: "south", Token: "south-token"}}
+	return settings
+}
+
+func TestMatchHubNode(t *testing.T) {
+	t.Parallel()
+
+	nodes := hubTestSettings().Hub.Nodes
+	assert.Equal(t, "north", matchHubNode(nodes, "north-token"))
+	assert.Equal(t, "south", matchHubNode(nodes, "south-token"))
+	assert.Empty(t, matchHubNode(nodes, "north-token-x"))
+	assert.Empty(t, matchHubNode(nodes, ""))
+}
+
+func TestHubNodeAuthMiddleware(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		enabled    bool
+		authHeader string
+		wantStatus int
+		wantNode   string
+	}{
+		{name: "hub disabled", enabled: false, authHeader: "Bearer north-token", wantStatus: http.StatusNotFound},
+		{name: "missing token", enabled: true, wantStatus: http.StatusUnauthorized},
+		{name: "wrong scheme", enabled: true, authHeader: "Basic north-token", wantStatus: http.StatusUnauthorized},
+		{name: "unknown token", enabled: true, authHeader: "Bearer other", wantStatus: http.StatusUnauthorized},
+		{name: "valid token", enabled: true, authHeader: "Bearer south-token", wantStatus: http.StatusOK, wantNode: "south"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			settings := hubTestSettings()
+			settings.Hub.Enabled = tt.enabled
+			c := &Controller{Settings: settings}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/hub/detections", http.NoBody)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+
+			var gotNode string
+			handler := c.hubNodeAuthMiddleware(func(ctx echo.Context) error {
+				gotNode, _ = ctx.Get(hubNodeContextKey).(string)
+				return ctx.NoContent(http.StatusOK)
+			})
+			require.NoError(t, handler(ctx))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			assert.Equal(t, tt.wantNode, gotNode)
+		})
+	}
+}
+
+// TestIngestHubDetection verifies that retries of a pushed detection are deduplicated.
+// Not parallel: toggles the global enhanced database flag.
+func TestIngestHubDetection(t *testing.T) {
+	datastoreV2.SetEnhancedDatabaseMode()
+	t.Cleanup(datastoreV2.ResetDatabaseMode)
+
+	ingester := &fakeHubIngester{MockInterface: mocks.NewMockInterface(t), ingested: map[string]uint{}}
+	c := &Controller{Settings: hubTestSettings(), DS: ingester}
+
+	e := echo.New()
+	body, err := json.Marshal(hub.Detection{
+		RemoteID:       42,
+		DetectedAt:     time.Date(2024, 5, 1, 6, 15, 0, 0, time.UTC),
+		ScientificName: "Turdus merula",
+		Confidence:     0.9,
+		Source:         hub.Source{ID: "rtsp_1", URI: "rtsp://10.0.0.5/stream"},
+	})
+	require.NoError(t, err)
+
+	push := func() (int, hub.IngestResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/v2/hub/detections", bytes.NewReader(body))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		rec := httptest.NewRecorder()
+		ctx := e.NewContext(req, rec)
+		ctx.Set(hubNodeContextKey, "north")
+		require.NoError(t, c.IngestHubDetection(ctx))
+		var resp hub.IngestResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+
+	code, first := push()
+	assert.Equal(t, http.StatusCreated, code)
+	assert.False(t, first.Duplicate)
+
+	code, retry := push()
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, retry.Duplicate)
+	assert.Equal(t, first.ID, retry.ID)
+
+	require.Len(t, ingester.notes, 1)
+	assert.Equal(t, "north", ingester.notes[0].SourceNode)
+	assert.Equal(t, "rtsp://10.0.0.5/stream", ingester.notes[0].Source.SafeString)
+}
+
+// TestNodeFilterRequiresV2 verifies that node filters are rejected on the legacy database.
+// Not parallel: depends on the global enhanced database flag being unset.
+func TestNodeFilterRequiresV2(t *testing.T) {
+	datastoreV2.ResetDatabaseMode()
+	c := &Controller{}
+	e := echo.New()
+
+	handler := c.nodeFilterMiddleware(func(ctx echo.Context) error {
+		return ctx.NoContent(http.StatusOK)
+	})
+
+	rec := httptest.NewRecorder()
+	ctx := e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v2/analytics/species/summary?node=north", http.NoBody), rec)
+	require.NoError(t, handler(ctx))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	rec = httptest.NewRecorder()
+	ctx = e.NewContext(httptest.NewRequest(http.MethodGet, "/api/v2/analytics/species/summary", http.NoBody), rec)
+	require.NoError(t, handler(ctx))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestWriteHubClip(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hub", "north", "2024", "05", "clip.wav")
+
+	require.NoError(t, writeHubClip(path, strings.NewReader("12345678"), 8))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "12345678", string(data))
+
+	tooLarge := filepath.Join(dir, "large.wav")
+	require.ErrorIs(t, writeHubClip(tooLarge, strings.NewReader("123456789"), 8), errHubClipTooLarge)
+	assert.NoFileExists(t, tooLarge)
+}
+
+func TestSanitizeHubPathElement(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "north_station", sanitizeHubPathElement("north station"))
+	assert.Equal(t, "_", sanitizeHubPathElement(".."))
+	assert.Equal(t, "clip.wav", sanitizeHubPathElement("clip.wav"))
+	assert.Equal(t, "_etc_passwd", sanitizeHubPathElement("/etc/passwd"))
+}
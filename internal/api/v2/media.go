@@ -2,6 +2,8 @@
 package api
 
 import (
+	"bytes"
+	"cmp"
 	"context"
 	"fmt"
 	"io/fs"
@@ -10,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,6 +20,8 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/detection"
 	"github.com/tphakala/birdnet-go/internal/errors"
 	"github.com/tphakala/birdnet-go/internal/logger"
 	"github.com/tphakala/birdnet-go/internal/myaudio"
@@ -321,7 +326,11 @@ func (c *Controller) ServeAudioClip(ctx echo.Context) error {
 	return nil
 }
 
-// ServeAudioByID serves an audio clip file based on note ID using SecureFS
+// ServeAudioByID serves an audio clip file based on note ID using SecureFS.
+// With download=true the clip is served as an attachment with the detection
+// metadata written as tags. The clip processing parameters (trim, padding,
+// format, bitrate, normalize, eq) serve a processed copy instead, see
+// parseClipProcessOptions.
 func (c *Controller) ServeAudioByID(ctx echo.Context) error {
 	noteID := ctx.Param("id")
 	if noteID == "" {
@@ -351,22 +360,21 @@ func (c *Controller) ServeAudioByID(ctx echo.Context) error {
 	originalFilename := filepath.Base(clipPath)
 	ext := strings.ToLower(filepath.Ext(originalFilename))
 
+	if clipProcessingRequested(ctx) {
+		return c.serveProcessedAudioClip(ctx, noteID, normalizedClipPath, originalFilename)
+	}
+
+	// Downloads carry the detection metadata, also for clips saved without it
+	if ctx.QueryParam("download") == "true" {
+		return c.serveTaggedAudioClip(ctx, noteID, normalizedClipPath, originalFilename)
+	}
+
 	// Set proper Content-Type for audio files BEFORE ServeRelativeFile
 	// This ensures Safari recognizes the file as audio
-	switch ext {
-	case ".flac":
-		ctx.Response().Header().Set("Content-Type", MimeTypeFLAC)
-	case ".wav":
-		ctx.Response().Header().Set("Content-Type", MimeTypeWAV)
-	case ".mp3":
-		ctx.Response().Header().Set("Content-Type", MimeTypeMP3)
-	case ".m4a":
-		ctx.Response().Header().Set("Content-Type", MimeTypeM4A)
-	case ".ogg":
-		ctx.Response().Header().Set("Content-Type", MimeTypeOGG)
-	default:
-		// Let ServeRelativeFile handle the content type
+	if mimeType := audioMimeType(ext); mimeType != "" {
+		ctx.Response().Header().Set("Content-Type", mimeType)
 	}
+	// Otherwise let ServeRelativeFile handle the content type
 
 	// Set Content-Disposition as inline to enable playback in browser
 	// Use filename* for proper UTF-8 filename encoding
@@ -390,6 +398,115 @@ func (c *Controller) ServeAudioByID(ctx echo.Context) error {
 	return nil
 }
 
+// serveTaggedAudioClip serves an audio clip as an attachment with the
+// detection metadata written as tags (ID3, Vorbis comments, MP4 atoms or
+// WAV LIST/INFO and GUANO chunks). The stored file is served without tags
+// when they cannot be written, e.g. without FFmpeg or while all clip
+// processing slots are in use.
+func (c *Controller) serveTaggedAudioClip(ctx echo.Context, noteID, clipPath, filename string) error {
+	relPath, err := c.SFS.ValidateRelativePath(clipPath)
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid clip path", http.StatusBadRequest)
+	}
+	absPath := filepath.Join(c.SFS.BaseDir(), relPath)
+	if _, err := os.Stat(absPath); err != nil {
+		return c.HandleError(ctx, err, "Audio clip not found", http.StatusNotFound)
+	}
+
+	data, err := c.tagAudioClip(ctx, noteID, absPath)
+	if err != nil {
+		c.logWarnIfEnabled("Serving audio clip without metadata tags",
+			logger.String("note_id", noteID),
+			logger.Error(err),
+			logger.String("path", ctx.Request().URL.Path),
+			logger.String("ip", ctx.RealIP()),
+		)
+	}
+
+	if isValidFilename(filename) {
+		ctx.Response().Header().Set("Content-Disposition",
+			fmt.Sprintf("attachment; filename=%q; filename*=UTF-8''%s", filename, url.QueryEscape(filename)))
+	}
+	mimeType := audioMimeType(filepath.Ext(filename))
+	if mimeType == "" {
+		mimeType = echo.MIMEOctetStream
+	}
+	if err != nil {
+		ctx.Response().Header().Set("Content-Type", mimeType)
+		if err := c.SFS.ServeRelativeFile(ctx, relPath); err != nil {
+			return c.translateSecureFSError(ctx, err, "Failed to serve audio clip due to an unexpected error")
+		}
+		return nil
+	}
+	return ctx.Blob(http.StatusOK, mimeType, data)
+}
+
+// tagAudioClip returns the clip at absPath with the metadata of its detection
+// written as tags. Clips other than WAV are tagged by FFmpeg, which takes a
+// clip processing slot.
+func (c *Controller) tagAudioClip(ctx echo.Context, noteID, absPath string) ([]byte, error) {
+	note, err := c.DS.Get(noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.EqualFold(filepath.Ext(absPath), ".wav") {
+		release, ok := tryAcquireClipProcessSlot()
+		if !ok {
+			return nil, errors.NewStd("all clip processing slots in use")
+		}
+		defer release()
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), 30*time.Second)
+	defer cancel()
+	return myaudio.TagAudioFileWithContext(reqCtx, absPath, c.Settings.Realtime.Audio.FfmpegPath, noteClipMetadata(&note, c.Settings))
+}
+
+// audioMimeType returns the content type of an audio clip extension, or an
+// empty string if it is not a known audio format.
+func audioMimeType(ext string) string {
+	switch strings.ToLower(ext) {
+	case ".flac":
+		return MimeTypeFLAC
+	case ".wav":
+		return MimeTypeWAV
+	case ".mp3":
+		return MimeTypeMP3
+	case ".m4a":
+		return MimeTypeM4A
+	case ".ogg", ".opus":
+		return MimeTypeOGG
+	default:
+		return ""
+	}
+}
+
+// noteClipMetadata returns the clip metadata of a stored detection. Notes do
+// not record the model, so the default model is assumed as elsewhere for notes.
+func noteClipMetadata(note *datastore.Note, settings *conf.Settings) *myaudio.ClipMetadata {
+	model := detection.DefaultModelInfo()
+	station := note.SourceNode
+	if station == "" {
+		station = settings.Main.Name
+	}
+	recordedAt := note.BeginTime
+	if recordedAt.IsZero() {
+		recordedAt, _ = time.ParseInLocation(time.DateTime, note.Date+" "+note.Time, time.Local)
+	}
+	return &myaudio.ClipMetadata{
+		CommonName:     note.CommonName,
+		ScientificName: note.ScientificName,
+		Confidence:     note.Confidence,
+		Model:          model.Name + " " + model.Version,
+		Source:         note.Source.DisplayName,
+		Station:        station,
+		RecordedAt:     recordedAt,
+		Latitude:       note.Latitude,
+		Longitude:      note.Longitude,
+	}
+}
+
 // spectrogramHTTPError handles common spectrogram generation errors and converts them to appropriate HTTP responses
 func (c *Controller) spectrogramHTTPError(ctx echo.Context, err error) error {
 	switch {
@@ -664,6 +781,10 @@ func (c *Controller) handleAutoPreRenderMode(ctx echo.Context, noteID, clipPath
 //   - raw: Whether to generate raw spectrogram without axes/legends
 //     Default: true (for backward compatibility with cached spectrograms)
 //     Accepts: "true", "false", "1", "0", "t", "f", "yes", "no", "on", "off"
+//   - annotated: Draw the detection box, axes and top predictions over the spectrogram.
+//     Default: false. Annotated spectrograms are generated on demand in every mode.
+//   - fmin, fmax: Optional frequency range in Hz highlighted in annotated spectrograms.
+//     Spectrograms with a frequency range are not cached.
 //
 // Response Format:
 // The response format varies based on the spectrogram generation mode setting and availability.
@@ -722,6 +843,12 @@ func (c *Controller) ServeSpectrogramByID(ctx echo.Context) error {
 		logger.String("path", ctx.Request().URL.Path),
 		logger.String("ip", ctx.RealIP()))
 
+	// Annotated spectrograms are only made when explicitly requested, so they
+	// are generated on demand in every mode
+	if parseAnnotatedParameter(ctx.QueryParam("annotated")) {
+		return c.serveAnnotatedSpectrogram(ctx, noteID, clipPath, params)
+	}
+
 	// Check spectrogram generation mode
 	spectrogramMode := c.Settings.Realtime.Dashboard.Spectrogram.GetMode()
 
@@ -737,6 +864,144 @@ func (c *Controller) ServeSpectrogramByID(ctx echo.Context) error {
 	return c.handleAutoPreRenderMode(ctx, noteID, clipPath, params)
 }
 
+// parseAnnotatedParameter parses the annotated query parameter, which defaults to false.
+func parseAnnotatedParameter(param string) bool {
+	if param == "" {
+		return false
+	}
+	return parseRawParameter(param)
+}
+
+// serveAnnotatedSpectrogram serves a spectrogram annotated with the detection's
+// time range, axes and top predictions. The annotation is drawn over the raw
+// spectrogram, which is generated first if needed, and cached next to it.
+func (c *Controller) serveAnnotatedSpectrogram(ctx echo.Context, noteID, clipPath string, params spectrogramParameters) error {
+	note, err := c.DS.Get(noteID)
+	if err != nil {
+		return c.HandleError(ctx, err, "Detection not found", http.StatusNotFound)
+	}
+	results, err := c.DS.GetNoteResults(noteID)
+	if err != nil {
+		// Predictions are optional, annotate with the detection alone
+		c.logWarnIfEnabled("Failed to get predictions for annotated spectrogram",
+			logger.String("note_id", noteID),
+			logger.Error(err))
+		results = nil
+	}
+
+	ann := noteAnnotation(&note, results)
+	ann.MinFreq, _ = strconv.ParseFloat(ctx.QueryParam("fmin"), 64)
+	ann.MaxFreq, _ = strconv.ParseFloat(ctx.QueryParam("fmax"), 64)
+
+	relRawPath, err := c.generateSpectrogram(ctx.Request().Context(), clipPath, params.width, true)
+	if err != nil {
+		if spectrogram.IsOperationalError(err) {
+			c.logDebugIfEnabled("Spectrogram generation canceled or interrupted",
+				logger.String("note_id", noteID), logger.Error(err))
+		} else {
+			c.logErrorIfEnabled("Spectrogram generation failed",
+				logger.String("note_id", noteID), logger.Error(err))
+		}
+		return c.spectrogramHTTPError(ctx, err)
+	}
+
+	if ann.MaxFreq > ann.MinFreq {
+		// Frequency ranges come from the query, so these overlays are rendered
+		// for every request instead of filling the cache with variants
+		return c.serveUncachedAnnotatedSpectrogram(ctx, noteID, clipPath, relRawPath, ann)
+	}
+
+	relAnnotatedPath := strings.TrimSuffix(relRawPath, ".png") + "-annotated.png"
+	if _, statErr := c.SFS.StatRel(relAnnotatedPath); statErr != nil {
+		outputPath := filepath.Join(c.SFS.BaseDir(), relAnnotatedPath)
+		if err := c.annotateSpectrogram(ctx, noteID, clipPath, relRawPath, ann,
+			func(audioPath, rawPath string) error {
+				return c.spectrogramGenerator.Annotate(ctx.Request().Context(), audioPath, rawPath, outputPath, ann)
+			}); err != nil {
+			return err
+		}
+	}
+
+	ctx.Response().Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d, immutable", SpectrogramCacheSeconds))
+	if err := c.SFS.ServeRelativeFile(ctx, relAnnotatedPath); err != nil {
+		if !ctx.Response().Committed {
+			ctx.Response().Header().Del("Cache-Control")
+		}
+		return c.translateSecureFSError(ctx, err, "Failed to serve spectrogram image")
+	}
+	return nil
+}
+
+// serveUncachedAnnotatedSpectrogram renders an annotated spectrogram in memory
+// and serves it without caching it.
+func (c *Controller) serveUncachedAnnotatedSpectrogram(ctx echo.Context, noteID, clipPath, relRawPath string, ann *spectrogram.Annotation) error {
+	var buf bytes.Buffer
+	if err := c.annotateSpectrogram(ctx, noteID, clipPath, relRawPath, ann,
+		func(audioPath, rawPath string) error {
+			return c.spectrogramGenerator.AnnotateTo(ctx.Request().Context(), audioPath, rawPath, &buf, ann)
+		}); err != nil {
+		return err
+	}
+	ctx.Response().Header().Set("Cache-Control", "no-store")
+	return ctx.Blob(http.StatusOK, "image/png", buf.Bytes())
+}
+
+// annotateSpectrogram validates the clip path and draws the annotation of a
+// detection over its raw spectrogram with render, which receives the absolute
+// audio and raw spectrogram paths. Returns ErrResponseHandled if an error
+// response was sent to the client.
+func (c *Controller) annotateSpectrogram(ctx echo.Context, noteID, clipPath, relRawPath string, ann *spectrogram.Annotation, render func(audioPath, rawPath string) error) error {
+	relAudioPath, err := c.normalizeAndValidatePath(clipPath)
+	if err != nil {
+		_ = c.spectrogramHTTPError(ctx, err)
+		return ErrResponseHandled
+	}
+	baseDir := c.SFS.BaseDir()
+	if err := render(filepath.Join(baseDir, relAudioPath), filepath.Join(baseDir, relRawPath)); err != nil {
+		c.logErrorIfEnabled("Spectrogram annotation failed",
+			logger.String("note_id", noteID),
+			logger.Error(err))
+		_ = c.HandleError(ctx, err, "Failed to annotate spectrogram", http.StatusInternalServerError)
+		return ErrResponseHandled
+	}
+	return nil
+}
+
+// noteAnnotation builds the spectrogram annotation of a detection. The clip
+// starts at the detection's begin time, as clips are read from the capture
+// buffer from that point. Predictions list the detected species first,
+// followed by the most confident other predictions.
+func noteAnnotation(note *datastore.Note, results []datastore.Results) *spectrogram.Annotation {
+	ann := &spectrogram.Annotation{
+		ClipStart: note.BeginTime,
+		BeginTime: note.BeginTime,
+		EndTime:   note.EndTime,
+	}
+	ann.Predictions = append(ann.Predictions, spectrogram.Prediction{
+		Label:      cmp.Or(note.CommonName, note.ScientificName),
+		Confidence: note.Confidence,
+	})
+
+	others := slices.Clone(results)
+	slices.SortStableFunc(others, func(a, b datastore.Results) int {
+		return cmp.Compare(b.Confidence, a.Confidence)
+	})
+	for _, r := range others {
+		if len(ann.Predictions) >= spectrogram.MaxAnnotationPredictions {
+			break
+		}
+		species := detection.ParseSpeciesString(r.Species)
+		if species.ScientificName == note.ScientificName {
+			continue
+		}
+		ann.Predictions = append(ann.Predictions, spectrogram.Prediction{
+			Label:      cmp.Or(species.CommonName, species.ScientificName),
+			Confidence: float64(r.Confidence),
+		})
+	}
+	return ann
+}
+
 // ServeAudioByQueryID serves an audio clip using query parameter for ID
 func (c *Controller) ServeAudioByQueryID(ctx echo.Context) error {
 	noteID := ctx.QueryParam("id")
@@ -5,6 +5,8 @@ package api
 import (
 	"errors"
 	"fmt"
+	"image"
+	"image/png"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -12,12 +14,16 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
 	"github.com/tphakala/birdnet-go/internal/securefs"
+	"github.com/tphakala/birdnet-go/internal/spectrogram"
 )
 
 // assertPartialContentHeaders checks headers for partial content responses.
@@ -1034,6 +1040,166 @@ func TestServeAudioByID_AudioFormats(t *testing.T) {
 	}
 }
 
+// TestServeAudioByID_Download tests that downloads carry the detection metadata
+func TestServeAudioByID_Download(t *testing.T) {
+	e, controller, tempDir := setupMediaTestEnvironment(t)
+
+	testFilename := "2024-01-15_14-30-45_Turdus_migratorius.wav"
+	require.NoError(t, myaudio.SavePCMDataToWAV(filepath.Join(tempDir, testFilename), make([]byte, 4800)))
+
+	mockDS := mocks.NewMockInterface(t)
+	mockDS.On("GetNoteClipPath", "123").Return(testFilename, nil)
+	mockDS.On("Get", "123").Return(datastore.Note{
+		ID:             123,
+		SourceNode:     "backyard",
+		Date:           "2024-01-15",
+		Time:           "14:30:45",
+		CommonName:     "American Robin",
+		ScientificName: "Turdus migratorius",
+		Confidence:     0.87,
+		Latitude:       42.36,
+		Longitude:      -71.06,
+	}, nil)
+	controller.DS = mockDS
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/123?download=true", http.NoBody)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+
+	require.NoError(t, controller.ServeAudioByID(c))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, MimeTypeWAV, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+
+	body := rec.Body.String()
+	assert.Contains(t, body, "INAM", "LIST/INFO title")
+	assert.Contains(t, body, "American Robin")
+	assert.Contains(t, body, "GUANO|Version: 1.0")
+	assert.Contains(t, body, "Species Auto ID: Turdus migratorius")
+	assert.Contains(t, body, "Loc Position: 42.360000 -71.060000")
+	assert.Contains(t, body, "BirdNET-Go|Station: backyard")
+}
+
+// TestServeAudioByID_DownloadUntagged tests that clips that cannot be tagged
+// are downloaded as stored
+func TestServeAudioByID_DownloadUntagged(t *testing.T) {
+	e, controller, tempDir := setupMediaTestEnvironment(t)
+	controller.Settings.Realtime.Audio.FfmpegPath = ""
+
+	testFilename := "2024-01-15_14-30-45_Turdus_migratorius.mp3"
+	testContent := "ID3-test-clip"
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, testFilename), []byte(testContent), 0o600))
+
+	mockDS := mocks.NewMockInterface(t)
+	mockDS.On("GetNoteClipPath", "123").Return(testFilename, nil)
+	mockDS.On("Get", "123").Return(datastore.Note{ID: 123, CommonName: "American Robin"}, nil)
+	controller.DS = mockDS
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/audio/123?download=true", http.NoBody)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+
+	require.NoError(t, controller.ServeAudioByID(c))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, MimeTypeMP3, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), "attachment")
+	assert.Equal(t, testContent, rec.Body.String())
+}
+
+func TestServeSpectrogramByID_Annotated(t *testing.T) {
+	e, controller, tempDir := setupMediaTestEnvironment(t)
+	controller.spectrogramGenerator = spectrogram.NewGenerator(controller.Settings, controller.SFS, nil)
+
+	testFilename := "2024-01-15_14-30-45_Turdus_migratorius.wav"
+	require.NoError(t, myaudio.SavePCMDataToWAV(filepath.Join(tempDir, testFilename), make([]byte, 96000)))
+
+	// The annotation is drawn over the cached raw spectrogram
+	rawSpectrogram := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	f, err := os.Create(filepath.Join(tempDir, "2024-01-15_14-30-45_Turdus_migratorius_400px.png")) //nolint:gosec // G304: test file in temp dir
+	require.NoError(t, err)
+	require.NoError(t, png.Encode(f, rawSpectrogram))
+	require.NoError(t, f.Close())
+
+	begin := time.Date(2024, 1, 15, 14, 30, 45, 0, time.UTC)
+	mockDS := mocks.NewMockInterface(t)
+	mockDS.On("GetNoteClipPath", "123").Return(testFilename, nil)
+	mockDS.On("Get", "123").Return(datastore.Note{
+		ID:             123,
+		BeginTime:      begin,
+		EndTime:        begin.Add(500 * time.Millisecond),
+		CommonName:     "American Robin",
+		ScientificName: "Turdus migratorius",
+		Confidence:     0.87,
+	}, nil)
+	mockDS.On("GetNoteResults", "123").Return([]datastore.Results{
+		{Species: "Turdus migratorius_American Robin", Confidence: 0.87},
+		{Species: "Turdus merula_Eurasian Blackbird", Confidence: 0.05},
+	}, nil)
+	controller.DS = mockDS
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/spectrogram/123?size=sm&annotated=true", http.NoBody)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+
+	require.NoError(t, controller.ServeSpectrogramByID(c))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "image/png", rec.Header().Get("Content-Type"))
+
+	annotated, err := png.Decode(rec.Body)
+	require.NoError(t, err)
+	assert.Greater(t, annotated.Bounds().Dx(), 400, "axes are added around the spectrogram")
+	assert.Greater(t, annotated.Bounds().Dy(), 200)
+	assert.FileExists(t, filepath.Join(tempDir, "2024-01-15_14-30-45_Turdus_migratorius_400px-annotated.png"), "annotated spectrogram is cached")
+
+	// Frequency ranges from the query are rendered without caching
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/spectrogram/123?size=sm&annotated=true&fmin=2000&fmax=4000", http.NoBody)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("123")
+
+	require.NoError(t, controller.ServeSpectrogramByID(c))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "no-store", rec.Header().Get("Cache-Control"))
+	_, err = png.Decode(rec.Body)
+	require.NoError(t, err)
+	cached, err := filepath.Glob(filepath.Join(tempDir, "*-annotated*.png"))
+	require.NoError(t, err)
+	assert.Len(t, cached, 1, "only the default annotation is cached")
+}
+
+func TestNoteAnnotation(t *testing.T) {
+	t.Parallel()
+
+	begin := time.Date(2024, 1, 15, 14, 30, 45, 0, time.UTC)
+	note := &datastore.Note{
+		BeginTime:      begin,
+		EndTime:        begin.Add(3 * time.Second),
+		CommonName:     "American Robin",
+		ScientificName: "Turdus migratorius",
+		Confidence:     0.87,
+	}
+	ann := noteAnnotation(note, []datastore.Results{
+		{Species: "Turdus migratorius_American Robin", Confidence: 0.87},
+		{Species: "Turdus merula_Eurasian Blackbird", Confidence: 0.05},
+		{Species: "Turdus philomelos_Song Thrush", Confidence: 0.12},
+		{Species: "Erithacus rubecula_European Robin", Confidence: 0.01},
+	})
+
+	assert.Equal(t, begin, ann.ClipStart)
+	assert.Equal(t, note.EndTime, ann.EndTime)
+	require.Len(t, ann.Predictions, 3)
+	assert.Equal(t, "American Robin", ann.Predictions[0].Label, "the detected species is listed first")
+	assert.Equal(t, "Song Thrush", ann.Predictions[1].Label, "other predictions by confidence")
+	assert.Equal(t, "Eurasian Blackbird", ann.Predictions[2].Label)
+}
+
 // TestServeSpectrogramByIDRawParameter tests the raw parameter parsing for ID-based spectrogram endpoint
 func TestServeSpectrogramByIDRawParameter(t *testing.T) {
 	// Setup test environment
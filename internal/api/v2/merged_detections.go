@@ -0,0 +1,115 @@
+// internal/api/v2/merged_detections.go
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// MergedDetectionCountsResponse counts detections merged across grouped sources.
+type MergedDetectionCountsResponse struct {
+	StartDate  string                           `json:"start_date"`
+	EndDate    string                           `json:"end_date"`
+	Events     int64                            `json:"events"`     // detections heard at more than one source
+	Duplicates int64                            `json:"duplicates"` // detections at other sources merged into them
+	Species    []datastore.MergedDetectionCount `json:"species"`
+}
+
+// DetectionLinksResponse lists the detections merged into a detection.
+type DetectionLinksResponse struct {
+	DetectionID uint                            `json:"detection_id"`
+	Links       []datastore.DetectionLinkRecord `json:"links"`
+}
+
+// initMergedDetectionRoutes registers the merged detection routes under the analytics group.
+func (c *Controller) initMergedDetectionRoutes(analyticsGroup *echo.Group) {
+	mergedGroup := analyticsGroup.Group("/merged")
+	mergedGroup.GET("", c.GetMergedDetectionCounts)
+	mergedGroup.GET("/:id", c.GetDetectionLinks)
+}
+
+// detectionLinkStore returns the datastore's merged detection support,
+// writing a 501 response if it has none.
+func (c *Controller) detectionLinkStore(ctx echo.Context) (datastore.DetectionLinkStore, error) {
+	store, ok := c.DS.(datastore.DetectionLinkStore)
+	if !ok {
+		_ = c.HandleError(ctx, errors.NewStd("merged detections not supported"),
+			"Merged detections require the v2 database", http.StatusNotImplemented)
+		return nil, ErrResponseHandled
+	}
+	return store, nil
+}
+
+// GetMergedDetectionCounts handles GET /api/v2/analytics/merged
+// Returns per species counts of detections merged from several sources of a
+// source group. Query parameters: start_date, end_date (default: last 30 days).
+func (c *Controller) GetMergedDetectionCounts(ctx echo.Context) error {
+	store, err := c.detectionLinkStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	startDate := ctx.QueryParam("start_date")
+	endDate := ctx.QueryParam("end_date")
+	if endDate == "" {
+		endDate = time.Now().Format(time.DateOnly)
+	}
+	if err := c.validateDateRangeWithResponse(ctx, startDate, endDate, "merged detections"); err != nil {
+		return err
+	}
+	end, _ := time.ParseInLocation(time.DateOnly, endDate, time.Local) // validated above
+	start := end.AddDate(0, 0, -defaultAnalyticsDays)
+	if startDate != "" {
+		start, _ = time.ParseInLocation(time.DateOnly, startDate, time.Local)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), analyticsQueryTimeout)
+	defer cancel()
+
+	counts, err := store.GetMergedDetectionCounts(reqCtx, start, end.AddDate(0, 0, 1))
+	if err != nil {
+		c.logErrorIfEnabled("Failed to get merged detection counts",
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+			logger.String("path", ctx.Request().URL.Path),
+		)
+		return c.HandleError(ctx, err, "Failed to get merged detection counts", http.StatusInternalServerError)
+	}
+
+	response := MergedDetectionCountsResponse{
+		StartDate: start.Format(time.DateOnly),
+		EndDate:   endDate,
+		Species:   counts,
+	}
+	for i := range counts {
+		response.Events += counts[i].Events
+		response.Duplicates += counts[i].Duplicates
+	}
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// GetDetectionLinks handles GET /api/v2/analytics/merged/:id
+// Returns the detections at other sources merged into a detection.
+func (c *Controller) GetDetectionLinks(ctx echo.Context) error {
+	store, err := c.detectionLinkStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid detection ID", http.StatusBadRequest)
+	}
+
+	links, err := store.GetDetectionLinks(ctx.Request().Context(), id)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get merged detections", http.StatusInternalServerError)
+	}
+	return ctx.JSON(http.StatusOK, DetectionLinksResponse{DetectionID: id, Links: links})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+)
+
+// fakeDetectionLinkStore serves fixed merged detections.
+type fakeDetectionLinkStore struct {
+	*mocks.MockInterface
+	start, end time.Time
+}
+
+func (f *fakeDetectionLinkStore) SaveDetectionLinks(context.Context, uint, []datastore.DetectionLinkRecord) error {
+	return nil
+}
+
+func (f *fakeDetectionLinkStore) GetDetectionLinks(_ context.Context, id uint) ([]datastore.DetectionLinkRecord, error) {
+	if id != 1 {
+		return []datastore.DetectionLinkRecord{}, nil
+	}
+	return []datastore.DetectionLinkRecord{{DetectionID: 1, SourceURI: "rtsp://back", SourceName: "Back", Confidence: 0.7}}, nil
+}
+
+func (f *fakeDetectionLinkStore) GetMergedDetectionCounts(_ context.Context, start, end time.Time) ([]datastore.MergedDetectionCount, error) {
+	f.start, f.end = start, end
+	return []datastore.MergedDetectionCount{
+		{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Events: 3, Duplicates: 4},
+		{ScientificName: "Erithacus rubecula", CommonName: "European Robin", Events: 1, Duplicates: 1},
+	}, nil
+}
+
+func TestGetMergedDetectionCounts(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+		wantDays   int
+	}{
+		{name: "default range", query: "end_date=2024-05-31", wantStatus: http.StatusOK, wantDays: defaultAnalyticsDays + 1},
+		{name: "single day", query: "start_date=2024-05-01&end_date=2024-05-01", wantStatus: http.StatusOK, wantDays: 1},
+		{name: "invalid date", query: "start_date=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "reversed range", query: "start_date=2024-05-02&end_date=2024-05-01", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := &fakeDetectionLinkStore{MockInterface: mocks.NewMockInterface(t)}
+			c := &Controller{DS: store}
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/merged?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			_ = c.GetMergedDetectionCounts(echo.New().NewContext(req, rec))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp MergedDetectionCountsResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, int64(4), resp.Events)
+			assert.Equal(t, int64(5), resp.Duplicates)
+			assert.Len(t, resp.Species, 2)
+			assert.Equal(t, tt.wantDays, int(store.end.Sub(store.start).Round(time.Hour).Hours()/24))
+		})
+	}
+}
+
+func TestGetDetectionLinks(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantLinks  int
+	}{
+		{name: "merged detection", id: "1", wantStatus: http.StatusOK, wantLinks: 1},
+		{name: "single source detection", id: "2", wantStatus: http.StatusOK},
+		{name: "invalid id", id: "abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &Controller{DS: &fakeDetectionLinkStore{MockInterface: mocks.NewMockInterface(t)}}
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/merged/"+tt.id, http.NoBody)
+			rec := httptest.NewRecorder()
+			ctx := echo.New().NewContext(req, rec)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(tt.id)
+
+			_ = c.GetDetectionLinks(ctx)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp DetectionLinksResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Len(t, resp.Links, tt.wantLinks)
+		})
+	}
+}
+
+func TestMergedDetectionsNotSupported(t *testing.T) {
+	t.Parallel()
+
+	c := &Controller{DS: mocks.NewMockInterface(t)}
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/merged", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	_ = c.GetMergedDetectionCounts(echo.New().NewContext(req, rec))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
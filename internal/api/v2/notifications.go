@@ -875,6 +875,7 @@ func (c *Controller) CreateTestNewSpeciesNotification(ctx echo.Context) error {
 		DetectionPath:      "/ui/detections/test",
 		DetectionURL:       baseURL + "/ui/detections/test",
 		ImageURL:           "https://static.avicommons.org/houfin-DzFZcHoKwyx9JOmg-320.jpg",
+		SpectrogramURL:     baseURL + "/api/v2/spectrogram/test?annotated=true",
 		DaysSinceFirstSeen: 0,
 	}
 
@@ -0,0 +1,167 @@
+// internal/api/v2/onvif.go
+package api
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+	"github.com/tphakala/birdnet-go/internal/onvif"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// ONVIF discovery limits
+const (
+	onvifQueryTimeout     = 15 * time.Second // per camera, covering all its requests
+	onvifQueryConcurrency = 4                // cameras queried at once
+)
+
+// ONVIFDiscoveryRequest is the request body for discovering ONVIF cameras.
+// Credentials are used to query the media profiles of the cameras.
+type ONVIFDiscoveryRequest struct {
+	Address  string `json:"address"`  // camera host, host:port or device service URL; empty scans the network
+	Username string `json:"username"` // camera user
+	Password string `json:"password"` // camera password
+	Timeout  int    `json:"timeout"`  // network scan duration in seconds, 0 for the default
+}
+
+// ONVIFCameraResponse is a discovered camera and its streams with audio.
+type ONVIFCameraResponse struct {
+	Name      string                `json:"name"`
+	Hardware  string                `json:"hardware"`
+	Address   string                `json:"address"`
+	DeviceURL string                `json:"deviceUrl"`
+	Streams   []ONVIFStreamResponse `json:"streams"`
+	Error     string                `json:"error,omitempty"` // why the camera could not be queried
+}
+
+// ONVIFStreamResponse is a media profile of a camera with audio. The URL is
+// sanitized; the stream is added with the credentials used for discovery.
+type ONVIFStreamResponse struct {
+	ProfileToken    string `json:"profileToken"`
+	ProfileName     string `json:"profileName"`
+	AudioEncoding   string `json:"audioEncoding"`
+	AudioSampleRate int    `json:"audioSampleRate"` // kHz
+	AudioBitrate    int    `json:"audioBitrate"`    // kbps
+	VideoEncoding   string `json:"videoEncoding,omitempty"`
+	URL             string `json:"url"`
+	Valid           bool   `json:"valid"`
+	ValidationError string `json:"validationError,omitempty"`
+}
+
+// initONVIFRoutes registers the ONVIF camera discovery endpoint.
+func (c *Controller) initONVIFRoutes() {
+	// Discovery takes camera credentials and exposes stream URLs, so it is protected
+	c.Group.POST("/sources/onvif/discover", c.DiscoverONVIFCameras, c.authMiddleware)
+}
+
+// DiscoverONVIFCameras handles POST /api/v2/sources/onvif/discover
+// Scans the local network for ONVIF cameras with WS-Discovery, or queries the
+// camera at the given address, and lists the media profiles with audio of
+// each camera with their RTSP stream URLs.
+func (c *Controller) DiscoverONVIFCameras(ctx echo.Context) error {
+	var req ONVIFDiscoveryRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if req.Timeout < 0 || time.Duration(req.Timeout)*time.Second > onvif.MaxDiscoveryTimeout {
+		return c.HandleError(ctx, errors.NewStd("invalid timeout"),
+			"Timeout must be between 0 and 15 seconds", http.StatusBadRequest)
+	}
+
+	reqCtx := ctx.Request().Context()
+	var devices []onvif.Device
+	if req.Address != "" {
+		deviceURL, err := onvif.DeviceURL(req.Address)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid camera address", http.StatusBadRequest)
+		}
+		devices = []onvif.Device{{Address: req.Address, XAddrs: []string{deviceURL}}}
+	} else {
+		var err error
+		devices, err = onvif.Discover(reqCtx, time.Duration(req.Timeout)*time.Second)
+		if err != nil {
+			c.logErrorIfEnabled("ONVIF discovery failed", logger.Error(err))
+			return c.HandleError(ctx, err, "Failed to scan the network for cameras", http.StatusInternalServerError)
+		}
+	}
+
+	cameras := make([]ONVIFCameraResponse, len(devices))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, onvifQueryConcurrency)
+	for i := range devices {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			cameras[i] = queryONVIFCamera(reqCtx, &devices[i], req.Username, req.Password)
+		})
+	}
+	wg.Wait()
+
+	c.logInfoIfEnabled("ONVIF camera discovery completed",
+		logger.Int("cameras", len(cameras)),
+		logger.Bool("network_scan", req.Address == ""))
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"cameras": cameras,
+		"count":   len(cameras),
+	})
+}
+
+// queryONVIFCamera lists the streams with audio of a camera, validating each
+// stream URL with its credentials as it would be added as an audio source.
+func queryONVIFCamera(ctx context.Context, device *onvif.Device, username, password string) ONVIFCameraResponse {
+	camera := ONVIFCameraResponse{
+		Name:     device.Name,
+		Hardware: device.Hardware,
+		Address:  device.Address,
+		Streams:  []ONVIFStreamResponse{},
+	}
+	if len(device.XAddrs) > 0 {
+		camera.DeviceURL = device.XAddrs[0]
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, onvifQueryTimeout)
+	defer cancel()
+
+	client := onvif.NewClient(camera.DeviceURL, username, password)
+	if err := client.SyncClock(ctx); err != nil {
+		// Cameras with a matching clock accept tokens without it
+		onvif.GetLogger().Debug("failed to read camera clock",
+			logger.String("camera", camera.Address),
+			logger.Error(err))
+	}
+	profiles, err := client.AudioProfiles(ctx)
+	if err != nil {
+		camera.Error = err.Error()
+		if errors.Is(err, onvif.ErrUnauthorized) {
+			camera.Error = "The camera rejected the username or password"
+		}
+		return camera
+	}
+
+	for i := range profiles {
+		profile := &profiles[i]
+		stream := ONVIFStreamResponse{
+			ProfileToken:    profile.Token,
+			ProfileName:     profile.Name,
+			AudioEncoding:   profile.AudioEncoding,
+			AudioSampleRate: profile.AudioSampleRate,
+			AudioBitrate:    profile.AudioBitrate,
+			VideoEncoding:   profile.VideoEncoding,
+			URL:             privacy.SanitizeStreamUrl(profile.URI),
+			Valid:           true,
+		}
+		if err := myaudio.GetRegistry().ValidateRTSPURL(onvif.WithCredentials(profile.URI, username, password)); err != nil {
+			stream.Valid = false
+			stream.ValidationError = err.Error()
+		}
+		camera.Streams = append(camera.Streams, stream)
+	}
+	return camera
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const onvifTestEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:trt="http://www.onvif.org/ver10/media/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+// newONVIFTestCamera serves a camera with one audio profile, whose stream URI
+// is streamURI.
+func newONVIFTestCamera(t *testing.T, streamURI string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body := string(data)
+		switch {
+		case strings.Contains(body, "GetSystemDateAndTime"):
+			_, _ = fmt.Fprintf(w, onvifTestEnvelope, `<tds:GetSystemDateAndTimeResponse/>`)
+		case strings.Contains(body, "GetCapabilities"):
+			_, _ = fmt.Fprintf(w, onvifTestEnvelope, `<tds:GetCapabilitiesResponse><tds:Capabilities/></tds:GetCapabilitiesResponse>`)
+		case strings.Contains(body, "GetProfiles"):
+			_, _ = fmt.Fprintf(w, onvifTestEnvelope, `<trt:GetProfilesResponse><trt:Profiles token="main"><tt:Name>Main</tt:Name>
+<tt:AudioEncoderConfiguration><tt:Encoding>G711</tt:Encoding><tt:SampleRate>8</tt:SampleRate></tt:AudioEncoderConfiguration>
+</trt:Profiles></trt:GetProfilesResponse>`)
+		case strings.Contains(body, "GetStreamUri"):
+			_, _ = fmt.Fprintf(w, onvifTestEnvelope, `<trt:GetStreamUriResponse><trt:MediaUri><tt:Uri>`+streamURI+`</tt:Uri></trt:MediaUri></trt:GetStreamUriResponse>`)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func discoverONVIF(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	c := &Controller{}
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/api/v2/sources/onvif/discover", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	_ = c.DiscoverONVIFCameras(e.NewContext(req, rec))
+	return rec
+}
+
+func TestDiscoverONVIFCameras_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "invalid json", body: `{`},
+		{name: "timeout too long", body: `{"timeout": 60}`},
+		{name: "invalid address", body: `{"address": "rtsp://cam/stream"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			rec := discoverONVIF(t, tt.body)
+			require.Equal(t, http.StatusBadRequest, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestDiscoverONVIFCameras_Address(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		streamURI string
+		wantValid bool
+	}{
+		{name: "rtsp stream", streamURI: "rtsp://192.168.1.64:554/live", wantValid: true},
+		{name: "http stream", streamURI: "http://192.168.1.64/live", wantValid: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			camera := newONVIFTestCamera(t, tt.streamURI)
+
+			rec := discoverONVIF(t, `{"address": "`+camera.URL+`", "username": "admin", "password": "secret"}`)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var resp struct {
+				Cameras []ONVIFCameraResponse `json:"cameras"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Len(t, resp.Cameras, 1)
+			require.Empty(t, resp.Cameras[0].Error)
+			require.Len(t, resp.Cameras[0].Streams, 1)
+
+			stream := resp.Cameras[0].Streams[0]
+			assert.Equal(t, "main", stream.ProfileToken)
+			assert.Equal(t, "G711", stream.AudioEncoding)
+			assert.Equal(t, tt.wantValid, stream.Valid, stream.ValidationError)
+			assert.NotContains(t, rec.Body.String(), "secret", "credentials are never returned")
+		})
+	}
+}
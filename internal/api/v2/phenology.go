@@ -0,0 +1,197 @@
+// internal/api/v2/phenology.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// Phenology query limits
+const (
+	phenologyMinYear         = 2000 // Earliest year accepted in phenology queries
+	phenologyDefaultYearSpan = 5    // Years compared when start_year is omitted
+	phenologyMaxYearSpan     = 20   // Maximum number of years in one comparison
+)
+
+// PhenologyMatrixResponse is the weekly presence matrix of a year: one row per
+// species, one column per week, each cell the number of detection days that week.
+type PhenologyMatrixResponse struct {
+	Year        int                  `json:"year"`
+	WeekStarts  []string             `json:"week_starts"` // first day of each week, YYYY-MM-DD
+	ActiveWeeks []int                `json:"active_weeks"`
+	Species     []PhenologyMatrixRow `json:"species"`
+}
+
+// PhenologyMatrixRow is one species row of the weekly presence matrix.
+type PhenologyMatrixRow struct {
+	ScientificName string `json:"scientific_name"`
+	CommonName     string `json:"common_name"`
+	Weeks          []int  `json:"weeks"`
+}
+
+// initPhenologyRoutes registers phenology routes under the analytics group.
+func (c *Controller) initPhenologyRoutes(analyticsGroup *echo.Group) {
+	if c.phenologyCache == nil {
+		c.phenologyCache = datastore.NewPhenologyCache()
+	}
+
+	phenologyGroup := analyticsGroup.Group("/phenology")
+	phenologyGroup.GET("", c.GetYearPhenology)
+	phenologyGroup.GET("/weekly", c.GetWeeklyPresenceMatrix)
+	phenologyGroup.GET("/compare", c.ComparePhenology)
+}
+
+// GetYearPhenology handles GET /api/v2/analytics/phenology
+// Returns first/last detection, detection days and weekly presence per species
+// for a year. Optional "species" limits the result to one species.
+func (c *Controller) GetYearPhenology(ctx echo.Context) error {
+	year, err := c.parsePhenologyYear(ctx, "year", time.Now().Year())
+	if err != nil {
+		return err
+	}
+
+	data, err := c.loadYearPhenology(ctx, year)
+	if err != nil {
+		return err
+	}
+
+	if species := ctx.QueryParam("species"); species != "" {
+		filtered := *data
+		filtered.Species = []datastore.SpeciesPhenology{}
+		if p, ok := data.Find(species); ok {
+			filtered.Species = append(filtered.Species, *p)
+		}
+		return ctx.JSON(http.StatusOK, &filtered)
+	}
+
+	return ctx.JSON(http.StatusOK, data)
+}
+
+// GetWeeklyPresenceMatrix handles GET /api/v2/analytics/phenology/weekly
+func (c *Controller) GetWeeklyPresenceMatrix(ctx echo.Context) error {
+	year, err := c.parsePhenologyYear(ctx, "year", time.Now().Year())
+	if err != nil {
+		return err
+	}
+
+	data, err := c.loadYearPhenology(ctx, year)
+	if err != nil {
+		return err
+	}
+
+	response := PhenologyMatrixResponse{
+		Year:        year,
+		WeekStarts:  make([]string, datastore.PhenologyWeeks),
+		ActiveWeeks: data.ActiveWeeks,
+		Species:     make([]PhenologyMatrixRow, 0, len(data.Species)),
+	}
+	jan1 := time.Date(year, time.January, 1, 0, 0, 0, 0, time.Local)
+	for i := range response.WeekStarts {
+		response.WeekStarts[i] = jan1.AddDate(0, 0, i*7).Format(time.DateOnly)
+	}
+	for i := range data.Species {
+		p := &data.Species[i]
+		response.Species = append(response.Species, PhenologyMatrixRow{
+			ScientificName: p.ScientificName,
+			CommonName:     p.CommonName,
+			Weeks:          p.WeeklyPresence,
+		})
+	}
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+// ComparePhenology handles GET /api/v2/analytics/phenology/compare
+// Compares arrival and departure dates of one species across a range of years.
+func (c *Controller) ComparePhenology(ctx echo.Context) error {
+	const operation = "phenology comparison"
+
+	if err := c.requireQueryParam(ctx, "species", operation); err != nil {
+		return err
+	}
+	species := ctx.QueryParam("species")
+
+	endYear, err := c.parsePhenologyYear(ctx, "end_year", time.Now().Year())
+	if err != nil {
+		return err
+	}
+	startYear, err := c.parsePhenologyYear(ctx, "start_year", max(endYear-phenologyDefaultYearSpan+1, phenologyMinYear))
+	if err != nil {
+		return err
+	}
+	if startYear > endYear {
+		return c.HandleError(ctx, errors.NewStd("start_year after end_year"),
+			"start_year cannot be after end_year", http.StatusBadRequest)
+	}
+	if endYear-startYear+1 > phenologyMaxYearSpan {
+		return c.HandleError(ctx, errors.NewStd("year range too large"),
+			fmt.Sprintf("Year range cannot exceed %d years", phenologyMaxYearSpan), http.StatusBadRequest)
+	}
+
+	years := make([]*datastore.YearPhenology, 0, endYear-startYear+1)
+	for year := startYear; year <= endYear; year++ {
+		data, err := c.loadYearPhenology(ctx, year)
+		if err != nil {
+			return err
+		}
+		years = append(years, data)
+	}
+
+	return ctx.JSON(http.StatusOK, datastore.ComparePhenology(species, years))
+}
+
+// loadYearPhenology returns the cached phenology of a year, writing the HTTP
+// error response itself on failure.
+func (c *Controller) loadYearPhenology(ctx echo.Context, year int) (*datastore.YearPhenology, error) {
+	provider, ok := c.DS.(datastore.SpeciesPresenceProvider)
+	if !ok || c.phenologyCache == nil {
+		_ = c.HandleError(ctx, errors.NewStd("phenology not supported"),
+			"Phenology analytics are not supported by this datastore", http.StatusNotImplemented)
+		return nil, ErrResponseHandled
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), analyticsQueryTimeout)
+	defer cancel()
+
+	// The node filter changes the underlying data, so it scopes the cache entry
+	data, err := c.phenologyCache.Year(reqCtx, provider, year, ctx.QueryParam("node"))
+	if err != nil {
+		c.logErrorIfEnabled("Failed to compute phenology",
+			logger.Int("year", year),
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+			logger.String("path", ctx.Request().URL.Path),
+		)
+		if errors.Is(err, context.DeadlineExceeded) {
+			_ = c.HandleError(ctx, err, "Query timeout - please try again later", http.StatusRequestTimeout)
+		} else {
+			_ = c.HandleError(ctx, err, "Failed to get phenology data", http.StatusInternalServerError)
+		}
+		return nil, ErrResponseHandled
+	}
+	return data, nil
+}
+
+// parsePhenologyYear parses an optional year query parameter.
+func (c *Controller) parsePhenologyYear(ctx echo.Context, param string, defaultYear int) (int, error) {
+	value := ctx.QueryParam(param)
+	if value == "" {
+		return defaultYear, nil
+	}
+	year, err := strconv.Atoi(value)
+	if err != nil || year < phenologyMinYear || year > time.Now().Year() {
+		_ = c.HandleError(ctx, err,
+			fmt.Sprintf("Invalid %s: must be a year between %d and %d", param, phenologyMinYear, time.Now().Year()),
+			http.StatusBadRequest)
+		return 0, ErrResponseHandled
+	}
+	return year, nil
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+)
+
+// fakePresenceStore serves fixed daily presence rows.
+type fakePresenceStore struct {
+	*mocks.MockInterface
+	rows []datastore.SpeciesDailyPresence
+}
+
+func (f *fakePresenceStore) GetSpeciesDailyPresence(context.Context, string, string) ([]datastore.SpeciesDailyPresence, error) {
+	return f.rows, nil
+}
+
+func newPhenologyTestController(t *testing.T) *Controller {
+	t.Helper()
+	return &Controller{
+		DS: &fakePresenceStore{
+			MockInterface: mocks.NewMockInterface(t),
+			rows: []datastore.SpeciesDailyPresence{
+				{ScientificName: "Phylloscopus collybita", CommonName: "Common Chiffchaff", Date: "2024-03-20", Count: 2},
+				{ScientificName: "Phylloscopus collybita", CommonName: "Common Chiffchaff", Date: "2024-09-30", Count: 1},
+				{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Date: "2024-01-05", Count: 4},
+			},
+		},
+		phenologyCache: datastore.NewPhenologyCache(),
+	}
+}
+
+func TestGetYearPhenology(t *testing.T) {
+	t.Parallel()
+
+	c := newPhenologyTestController(t)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/phenology?year=2024&species=Common%20Chiffchaff", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, c.GetYearPhenology(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp datastore.YearPhenology
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, 3, resp.ActiveDays)
+	require.Len(t, resp.Species, 1)
+	assert.Equal(t, "2024-03-20", resp.Species[0].FirstDetection)
+	assert.Equal(t, "2024-09-30", resp.Species[0].LastDetection)
+}
+
+func TestGetWeeklyPresenceMatrix(t *testing.T) {
+	t.Parallel()
+
+	c := newPhenologyTestController(t)
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/phenology/weekly?year=2024", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, c.GetWeeklyPresenceMatrix(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var resp PhenologyMatrixResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.WeekStarts, datastore.PhenologyWeeks)
+	assert.Equal(t, "2024-01-01", resp.WeekStarts[0])
+	assert.Equal(t, "2024-01-08", resp.WeekStarts[1])
+	require.Len(t, resp.Species, 2)
+	assert.Equal(t, "Turdus merula", resp.Species[0].ScientificName)
+	assert.Equal(t, 1, resp.Species[0].Weeks[0])
+}
+
+func TestComparePhenologyValidation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "missing species", query: "start_year=2022&end_year=2024", wantStatus: http.StatusBadRequest},
+		{name: "reversed years", query: "species=Turdus%20merula&start_year=2024&end_year=2022", wantStatus: http.StatusBadRequest},
+		{name: "invalid year", query: "species=Turdus%20merula&start_year=abc", wantStatus: http.StatusBadRequest},
+		{name: "range too large", query: "species=Turdus%20merula&start_year=2000&end_year=2024", wantStatus: http.StatusBadRequest},
+		{name: "valid", query: "species=Turdus%20merula&start_year=2023&end_year=2024", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := newPhenologyTestController(t)
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/phenology/compare?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			_ = c.ComparePhenology(e.NewContext(req, rec))
+			assert.Equal(t, tt.wantStatus, rec.Code)
+		})
+	}
+}
@@ -6,16 +6,26 @@ import (
 	"maps"
 	"net/http"
 	"slices"
+	"strings"
 	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
 	"github.com/tphakala/birdnet-go/internal/logger"
 )
 
 const defaultSearchTimeout = 60 * time.Second
 const defaultPerPage = 20
 
+// Tag and comment search limits
+const (
+	maxSearchTags         = 20
+	maxCommentQueryLength = 200
+	tagMatchAll           = "all"
+)
+
 // initSearchRoutes registers the search-related routes
 func (c *Controller) initSearchRoutes() {
 	c.logInfoIfEnabled("Initializing search routes")
@@ -23,22 +33,32 @@ func (c *Controller) initSearchRoutes() {
 	// Search endpoints - publicly accessible
 	c.Group.POST("/search", c.HandleSearch)
 
+	// Clip export reads every matching clip, so it requires authentication
+	var exportMiddleware []echo.MiddlewareFunc
+	if c.authMiddleware != nil {
+		exportMiddleware = append(exportMiddleware, c.authMiddleware)
+	}
+	c.Group.POST("/search/export", c.ExportSearchClips, exportMiddleware...)
+
 	c.logInfoIfEnabled("Search routes initialized successfully")
 }
 
 // SearchRequest defines the structure of the search API request
 type SearchRequest struct {
-	Species        string  `json:"species"`
-	DateStart      string  `json:"dateStart"`
-	DateEnd        string  `json:"dateEnd"`
-	ConfidenceMin  float64 `json:"confidenceMin"`
-	ConfidenceMax  float64 `json:"confidenceMax"`
-	VerifiedStatus string  `json:"verifiedStatus"`
-	LockedStatus   string  `json:"lockedStatus"`
-	DeviceFilter   string  `json:"deviceFilter"`
-	TimeOfDay      string  `json:"timeOfDay"`
-	Page           int     `json:"page"`
-	SortBy         string  `json:"sortBy"`
+	Species        string   `json:"species"`
+	DateStart      string   `json:"dateStart"`
+	DateEnd        string   `json:"dateEnd"`
+	ConfidenceMin  float64  `json:"confidenceMin"`
+	ConfidenceMax  float64  `json:"confidenceMax"`
+	VerifiedStatus string   `json:"verifiedStatus"`
+	LockedStatus   string   `json:"lockedStatus"`
+	DeviceFilter   string   `json:"deviceFilter"`
+	TimeOfDay      string   `json:"timeOfDay"`
+	Tags           []string `json:"tags,omitempty"`
+	TagMatch       string   `json:"tagMatch,omitempty"` // "any" (default) or "all"
+	CommentQuery   string   `json:"commentQuery,omitempty"`
+	Page           int      `json:"page"`
+	SortBy         string   `json:"sortBy"`
 }
 
 // SearchResponse defines the structure of the search API response
@@ -135,6 +155,9 @@ func (c *Controller) buildSearchFilters(req *SearchRequest, ctxTimeout context.C
 		UnlockedOnly:   req.LockedStatus == "unlocked",
 		Device:         req.DeviceFilter,
 		TimeOfDay:      req.TimeOfDay,
+		Tags:           req.Tags,
+		TagsMatchAll:   req.TagMatch == tagMatchAll,
+		CommentQuery:   req.CommentQuery,
 		Page:           req.Page,
 		PerPage:        defaultPerPage,
 		SortBy:         req.SortBy,
@@ -202,6 +225,11 @@ func (c *Controller) validateAndNormalizeSearchRequest(ctx echo.Context, req *Se
 		return err
 	}
 
+	err = c.validateSearchTagsAndComments(path, ip, req)
+	if err != nil {
+		return err
+	}
+
 	return nil // All validations passed
 }
 
@@ -319,3 +347,35 @@ func (c *Controller) validateSearchSortBy(path, ip string, req *SearchRequest) e
 	}
 	return nil
 }
+
+// validateSearchTagsAndComments validates and normalizes Tags, TagMatch and CommentQuery.
+func (c *Controller) validateSearchTagsAndComments(path, ip string, req *SearchRequest) error {
+	if len(req.Tags) > maxSearchTags {
+		c.logErrorIfEnabled("Too many search tags", logger.Int("count", len(req.Tags)), logger.String("path", path), logger.String("ip", ip))
+		return fmt.Errorf("too many tags: maximum is %d", maxSearchTags)
+	}
+	for i, tag := range req.Tags {
+		normalized, err := repository.NormalizeTag(tag)
+		if err != nil {
+			c.logErrorIfEnabled("Invalid search tag", logger.String("tag", tag), logger.String("path", path), logger.String("ip", ip))
+			return fmt.Errorf("invalid tag '%s': tags must be 1-%d characters", tag, entities.MaxTagLength)
+		}
+		req.Tags[i] = normalized
+	}
+
+	switch req.TagMatch {
+	case "":
+		req.TagMatch = QueryValueAny
+	case QueryValueAny, tagMatchAll:
+	default:
+		c.logErrorIfEnabled("Invalid tagMatch parameter", logger.String("tagMatch", req.TagMatch), logger.String("path", path), logger.String("ip", ip))
+		return fmt.Errorf("invalid tagMatch '%s'. Use 'any' or 'all'", req.TagMatch)
+	}
+
+	req.CommentQuery = strings.TrimSpace(req.CommentQuery)
+	if len(req.CommentQuery) > maxCommentQueryLength {
+		c.logErrorIfEnabled("Comment query too long", logger.Int("length", len(req.CommentQuery)), logger.String("path", path), logger.String("ip", ip))
+		return fmt.Errorf("commentQuery exceeds maximum length of %d characters", maxCommentQueryLength)
+	}
+	return nil
+}
@@ -1466,6 +1466,11 @@ var settingsChangeChecks = []settingsChangeCheck{
 func (c *Controller) handleSettingsChanges(oldSettings, currentSettings *conf.Settings) error {
 	var reconfigActions []string
 
+	// Keep database-managed sources in step with stream edits from the settings UI
+	if streamsSettingsChanged(oldSettings, currentSettings) {
+		c.syncStreamsToDatabase(currentSettings.Realtime.RTSP.Streams)
+	}
+
 	// Process all settings change checks using table-driven approach
 	for _, check := range settingsChangeChecks {
 		if check.changed(oldSettings, currentSettings) {
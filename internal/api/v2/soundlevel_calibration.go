@@ -0,0 +1,141 @@
+// internal/api/v2/soundlevel_calibration.go
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio"
+)
+
+// Sound level calibration limits
+const (
+	soundLevelCalibrationDefaultDuration = 5  // seconds of reference tone measured by default
+	soundLevelCalibrationMaxDuration     = 30 // longest measurement in seconds
+	soundLevelCalibrationMaxReference    = 160.0
+	soundLevelCalibrationAudioTimeout    = 10 * time.Second // allowance for audio arriving late
+)
+
+// SoundLevelCalibrationRequest is the body of the calibration endpoint.
+type SoundLevelCalibrationRequest struct {
+	SourceID    string  `json:"source_id"`        // registry ID of the source, as in sound level data
+	ReferenceDB float64 `json:"reference_db"`     // dB SPL of the 1 kHz reference tone, e.g. 94 or 114
+	Duration    int     `json:"duration_seconds"` // seconds to measure, default 5
+	Apply       bool    `json:"apply"`            // true to save the offset to the source calibration
+}
+
+// SoundLevelCalibrationResponse is the result of a calibration measurement.
+type SoundLevelCalibrationResponse struct {
+	SourceID       string  `json:"source_id"`
+	ToneLevel      float64 `json:"tone_dbfs"`      // level of the reference tone in the 1 kHz band
+	BroadbandLevel float64 `json:"broadband_dbfs"` // unweighted level of all audio
+	Spread         float64 `json:"spread_db"`      // variation of the tone level during the measurement
+	Offset         float64 `json:"offset_db"`      // dB SPL of a 0 dBFS signal
+	Applied        bool    `json:"applied"`        // whether the offset was saved
+}
+
+// CalibrateSoundLevel handles POST /api/v2/soundlevels/calibration
+// It measures a 1 kHz reference tone of known level, as from an acoustic
+// calibrator placed on the microphone, and returns the offset relating the
+// source's levels to dB SPL. With apply the offset is saved to the source's
+// calibration settings. Sound level monitoring must be enabled.
+func (c *Controller) CalibrateSoundLevel(ctx echo.Context) error {
+	var req SoundLevelCalibrationRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if req.SourceID == "" {
+		return c.HandleError(ctx, errors.NewStd("source_id is required"), "source_id is required", http.StatusBadRequest)
+	}
+	if req.ReferenceDB <= 0 || req.ReferenceDB > soundLevelCalibrationMaxReference {
+		return c.HandleError(ctx, errors.NewStd("invalid reference level"),
+			"reference_db must be the dB SPL of the reference tone, e.g. 94", http.StatusBadRequest)
+	}
+	if req.Duration == 0 {
+		req.Duration = soundLevelCalibrationDefaultDuration
+	}
+	if req.Duration < 1 || req.Duration > soundLevelCalibrationMaxDuration {
+		return c.HandleError(ctx, errors.NewStd("invalid duration"),
+			"duration_seconds must be between 1 and 30", http.StatusBadRequest)
+	}
+
+	duration := time.Duration(req.Duration) * time.Second
+	measureCtx, cancel := context.WithTimeout(ctx.Request().Context(), duration+soundLevelCalibrationAudioTimeout)
+	defer cancel()
+
+	measurement, err := myaudio.MeasureCalibrationTone(measureCtx, req.SourceID, duration)
+	switch {
+	case errors.Is(err, myaudio.ErrSoundLevelProcessorNotRegistered):
+		return c.HandleError(ctx, err, "No sound level monitoring for this source, enable sound level monitoring first", http.StatusNotFound)
+	case errors.Is(err, myaudio.ErrCalibrationInProgress):
+		return c.HandleError(ctx, err, "A calibration of this source is already in progress", http.StatusConflict)
+	case errors.Is(err, myaudio.ErrCalibrationToneNotFound):
+		return c.HandleError(ctx, err, "No steady 1 kHz reference tone found, check that the calibrator is on and seated on the microphone", http.StatusUnprocessableEntity)
+	case err != nil:
+		return c.HandleError(ctx, err, "The source delivered no audio during the measurement", http.StatusGatewayTimeout)
+	}
+
+	resp := SoundLevelCalibrationResponse{
+		SourceID:       req.SourceID,
+		ToneLevel:      roundDB(measurement.ToneLevel),
+		BroadbandLevel: roundDB(measurement.BroadbandLevel),
+		Spread:         roundDB(measurement.Spread),
+		Offset:         roundDB(req.ReferenceDB - measurement.ToneLevel),
+	}
+
+	if req.Apply {
+		found, err := c.applySoundLevelCalibration(req.SourceID, resp.Offset)
+		if err != nil {
+			return c.HandleError(ctx, err, "Failed to save the calibration", http.StatusInternalServerError)
+		}
+		if !found {
+			return c.HandleError(ctx, errors.NewStd("source not configured"),
+				"The source is not configured, add the calibration to its settings manually", http.StatusNotFound)
+		}
+		resp.Applied = true
+	}
+
+	GetLogger().Info("sound level calibration measured",
+		logger.String("source_id", req.SourceID),
+		logger.Float64("reference_db", req.ReferenceDB),
+		logger.Float64("tone_dbfs", resp.ToneLevel),
+		logger.Float64("offset_db", resp.Offset),
+		logger.Bool("applied", resp.Applied))
+
+	return ctx.JSON(http.StatusOK, resp)
+}
+
+// applySoundLevelCalibration saves offset as the calibration of a source. It
+// returns false if the source is not configured.
+func (c *Controller) applySoundLevelCalibration(sourceID string, offset float64) (bool, error) {
+	c.settingsMutex.Lock()
+	defer c.settingsMutex.Unlock()
+
+	calibration := myaudio.SourceCalibration(c.getSettingsOrFallback(), sourceID)
+	if calibration == nil {
+		return false, nil
+	}
+
+	previous := *calibration
+	calibration.Enabled = true
+	calibration.Offset = offset
+
+	if !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			*calibration = previous
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// roundDB rounds a level to hundredths of a dB.
+func roundDB(level float64) float64 {
+	return math.Round(level*100) / 100
+}
@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalibrateSoundLevel_Validation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+	}{
+		{name: "invalid json", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "missing source", body: `{"reference_db": 94}`, wantStatus: http.StatusBadRequest},
+		{name: "missing reference", body: `{"source_id": "audio_card_1"}`, wantStatus: http.StatusBadRequest},
+		{name: "reference too high", body: `{"source_id": "audio_card_1", "reference_db": 194}`, wantStatus: http.StatusBadRequest},
+		{name: "duration too long", body: `{"source_id": "audio_card_1", "reference_db": 94, "duration_seconds": 120}`, wantStatus: http.StatusBadRequest},
+		{name: "source without sound levels", body: `{"source_id": "no_such_source", "reference_db": 94}`, wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &Controller{DisableSaveSettings: true}
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/api/v2/soundlevels/calibration", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+
+			_ = c.CalibrateSoundLevel(e.NewContext(req, rec))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,275 @@
+// internal/api/v2/soundlevel_history.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// Sound level history query limits
+const (
+	soundLevelHistoryMaxRange   = 7 * 24 * time.Hour
+	soundLevelHistoryDefault    = 24 * time.Hour
+	soundLevelProfileMaxDays    = 31
+	soundLevelHistoryQueryLimit = 30 * time.Second
+)
+
+// noiseProfileBucketSizes lists the accepted noise profile bucket widths in minutes.
+var noiseProfileBucketSizes = []int{5, 10, 15, 30, 60}
+
+// SoundLevelHistoryResponse is the response of the sound level history endpoint.
+type SoundLevelHistoryResponse struct {
+	Start   time.Time                    `json:"start"`
+	End     time.Time                    `json:"end"`
+	Records []datastore.SoundLevelRecord `json:"records"`
+}
+
+// AcousticIndicesResponse is the response of the acoustic indices endpoint.
+type AcousticIndicesResponse struct {
+	Start   time.Time                         `json:"start"`
+	End     time.Time                         `json:"end"`
+	Records []datastore.AcousticIndicesRecord `json:"records"`
+}
+
+// DetectionSoundLevelResponse is the ambient sound level during a detection.
+type DetectionSoundLevelResponse struct {
+	DetectionID uint                        `json:"detection_id"`
+	SoundLevel  *datastore.SoundLevelRecord `json:"sound_level"` // null if no measurement covers the detection
+}
+
+// initSoundLevelHistoryRoutes registers the stored sound level endpoints and
+// the calibration endpoint. The live stream is registered with the other SSE routes.
+func (c *Controller) initSoundLevelHistoryRoutes() {
+	soundLevelGroup := c.Group.Group("/soundlevels")
+	soundLevelGroup.GET("/history", c.GetSoundLevelHistory)
+	soundLevelGroup.GET("/profile", c.GetNoiseProfiles)
+	soundLevelGroup.GET("/indices", c.GetAcousticIndices)
+	soundLevelGroup.GET("/detection/:id", c.GetDetectionSoundLevel)
+	soundLevelGroup.POST("/calibration", c.CalibrateSoundLevel, c.authMiddleware)
+}
+
+// soundLevelHistoryStore returns the datastore's sound level history support,
+// writing a 501 response if it has none.
+func (c *Controller) soundLevelHistoryStore(ctx echo.Context) (datastore.SoundLevelHistoryStore, error) {
+	store, ok := c.DS.(datastore.SoundLevelHistoryStore)
+	if !ok {
+		_ = c.HandleError(ctx, errors.NewStd("sound level history not supported"),
+			"Sound level history requires the v2 database", http.StatusNotImplemented)
+		return nil, ErrResponseHandled
+	}
+	return store, nil
+}
+
+// soundLevelHistoryRange parses the start and end query parameters of the
+// history endpoints, writing a 400 response if they are invalid.
+func (c *Controller) soundLevelHistoryRange(ctx echo.Context) (start, end time.Time, err error) {
+	end = time.Now()
+	if value := ctx.QueryParam("end"); value != "" {
+		if end, err = parseSoundLevelTime(value, true); err != nil {
+			_ = c.HandleError(ctx, err, "Invalid end: use RFC3339 or YYYY-MM-DD", http.StatusBadRequest)
+			return start, end, ErrResponseHandled
+		}
+	}
+	start = end.Add(-soundLevelHistoryDefault)
+	if value := ctx.QueryParam("start"); value != "" {
+		if start, err = parseSoundLevelTime(value, false); err != nil {
+			_ = c.HandleError(ctx, err, "Invalid start: use RFC3339 or YYYY-MM-DD", http.StatusBadRequest)
+			return start, end, ErrResponseHandled
+		}
+	}
+	if !start.Before(end) {
+		_ = c.HandleError(ctx, errors.NewStd("start must be before end"), "start must be before end", http.StatusBadRequest)
+		return start, end, ErrResponseHandled
+	}
+	if end.Sub(start) > soundLevelHistoryMaxRange {
+		_ = c.HandleError(ctx, errors.NewStd("time range too large"), "Time range cannot exceed 7 days", http.StatusBadRequest)
+		return start, end, ErrResponseHandled
+	}
+	return start, end, nil
+}
+
+// GetSoundLevelHistory handles GET /api/v2/soundlevels/history
+// Returns stored band levels of one or all sources.
+//
+// Query parameters: source (URI or display name), start and end as RFC3339
+// timestamps or YYYY-MM-DD dates (default: the last 24 hours, at most 7 days).
+func (c *Controller) GetSoundLevelHistory(ctx echo.Context) error {
+	store, err := c.soundLevelHistoryStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	start, end, err := c.soundLevelHistoryRange(ctx)
+	if err != nil {
+		return err
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), soundLevelHistoryQueryLimit)
+	defer cancel()
+
+	source := ctx.QueryParam("source")
+	records, err := store.GetSoundLevels(reqCtx, datastore.SoundLevelQuery{Source: source, Start: start, End: end})
+	if err != nil {
+		c.logErrorIfEnabled("Failed to get sound level history",
+			logger.String("source", source),
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+			logger.String("path", ctx.Request().URL.Path),
+		)
+		return c.HandleError(ctx, err, "Failed to get sound level history", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, SoundLevelHistoryResponse{Start: start, End: end, Records: records})
+}
+
+// GetAcousticIndices handles GET /api/v2/soundlevels/indices
+// Returns the stored per-minute acoustic indices (ACI, ADI, NDSI, BI, H) of
+// one or all sources.
+//
+// Query parameters: as for GetSoundLevelHistory.
+func (c *Controller) GetAcousticIndices(ctx echo.Context) error {
+	store, ok := c.DS.(datastore.AcousticIndicesStore)
+	if !ok {
+		return c.HandleError(ctx, errors.NewStd("acoustic indices not supported"),
+			"Acoustic indices require the v2 database", http.StatusNotImplemented)
+	}
+
+	start, end, err := c.soundLevelHistoryRange(ctx)
+	if err != nil {
+		return err
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), soundLevelHistoryQueryLimit)
+	defer cancel()
+
+	source := ctx.QueryParam("source")
+	records, err := store.GetAcousticIndices(reqCtx, datastore.SoundLevelQuery{Source: source, Start: start, End: end})
+	if err != nil {
+		c.logErrorIfEnabled("Failed to get acoustic indices",
+			logger.String("source", source),
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+			logger.String("path", ctx.Request().URL.Path),
+		)
+		return c.HandleError(ctx, err, "Failed to get acoustic indices", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, AcousticIndicesResponse{Start: start, End: end, Records: records})
+}
+
+// GetNoiseProfiles handles GET /api/v2/soundlevels/profile
+// Returns daily noise profiles per source: the equivalent level, L10, L50,
+// L90 and band levels per time-of-day bucket.
+//
+// Query parameters: start_date, end_date (default: today, at most 31 days),
+// source (URI or display name), bucket (minutes, default 15).
+func (c *Controller) GetNoiseProfiles(ctx echo.Context) error {
+	store, err := c.soundLevelHistoryStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	today := time.Now().Format(time.DateOnly)
+	startDate, endDate := ctx.QueryParam("start_date"), ctx.QueryParam("end_date")
+	if startDate == "" && endDate == "" {
+		startDate, endDate = today, today
+	} else if startDate == "" {
+		startDate = endDate
+	} else if endDate == "" {
+		endDate = startDate
+	}
+	if err := c.validateDateRangeWithResponse(ctx, startDate, endDate, "noise profile"); err != nil {
+		return err
+	}
+	start, _ := time.ParseInLocation(time.DateOnly, startDate, time.Local) // validated above
+	end, _ := time.ParseInLocation(time.DateOnly, endDate, time.Local)
+	end = end.AddDate(0, 0, 1)
+	if end.Sub(start) > soundLevelProfileMaxDays*24*time.Hour+time.Hour { // allow for DST
+		return c.HandleError(ctx, errors.NewStd("date range too large"),
+			fmt.Sprintf("Date range cannot exceed %d days", soundLevelProfileMaxDays), http.StatusBadRequest)
+	}
+
+	bucket := datastore.DefaultNoiseProfileBucketMinutes
+	if value := ctx.QueryParam("bucket"); value != "" {
+		bucket, err = strconv.Atoi(value)
+		if err != nil || !slices.Contains(noiseProfileBucketSizes, bucket) {
+			return c.HandleError(ctx, err, fmt.Sprintf("Invalid bucket: must be one of %v minutes", noiseProfileBucketSizes), http.StatusBadRequest)
+		}
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), soundLevelHistoryQueryLimit)
+	defer cancel()
+
+	source := ctx.QueryParam("source")
+	records, err := store.GetSoundLevels(reqCtx, datastore.SoundLevelQuery{Source: source, Start: start, End: end})
+	if err != nil {
+		c.logErrorIfEnabled("Failed to get sound levels for noise profile",
+			logger.String("source", source),
+			logger.String("start_date", startDate),
+			logger.String("end_date", endDate),
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+			logger.String("path", ctx.Request().URL.Path),
+		)
+		return c.HandleError(ctx, err, "Failed to get noise profile", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"start_date": startDate,
+		"end_date":   endDate,
+		"profiles":   datastore.ComputeNoiseProfiles(records, time.Local, bucket),
+	})
+}
+
+// GetDetectionSoundLevel handles GET /api/v2/soundlevels/detection/:id
+// Returns the ambient sound level measured at the detection's source when it was made.
+func (c *Controller) GetDetectionSoundLevel(ctx echo.Context) error {
+	store, err := c.soundLevelHistoryStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 64)
+	if err != nil || id == 0 {
+		return c.HandleError(ctx, err, "Invalid detection ID", http.StatusBadRequest)
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), soundLevelHistoryQueryLimit)
+	defer cancel()
+
+	level, err := store.GetDetectionSoundLevel(reqCtx, uint(id))
+	if errors.Is(err, repository.ErrDetectionNotFound) {
+		return c.HandleError(ctx, err, "Detection not found", http.StatusNotFound)
+	}
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to get detection sound level", http.StatusInternalServerError)
+	}
+
+	return ctx.JSON(http.StatusOK, DetectionSoundLevelResponse{DetectionID: uint(id), SoundLevel: level})
+}
+
+// parseSoundLevelTime parses an RFC3339 timestamp or a local YYYY-MM-DD date.
+// A date used as an end is exclusive, so it covers the whole day.
+func parseSoundLevelTime(value string, isEnd bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation(time.DateOnly, value, time.Local)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if isEnd {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,264 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+)
+
+// fakeSoundLevelHistory serves fixed sound level records.
+type fakeSoundLevelHistory struct {
+	*mocks.MockInterface
+	records   []datastore.SoundLevelRecord
+	lastQuery datastore.SoundLevelQuery
+}
+
+func (f *fakeSoundLevelHistory) SaveSoundLevels(context.Context, []datastore.SoundLevelRecord) error {
+	return nil
+}
+
+func (f *fakeSoundLevelHistory) GetSoundLevels(_ context.Context, query datastore.SoundLevelQuery) ([]datastore.SoundLevelRecord, error) {
+	f.lastQuery = query
+	return f.records, nil
+}
+
+func (f *fakeSoundLevelHistory) GetDetectionSoundLevel(_ context.Context, id uint) (*datastore.SoundLevelRecord, error) {
+	switch id {
+	case 1:
+		return &f.records[0], nil
+	case 2:
+		return nil, nil
+	default:
+		return nil, repository.ErrDetectionNotFound
+	}
+}
+
+func (f *fakeSoundLevelHistory) DownsampleSoundLevels(context.Context, time.Time, time.Duration) (int64, error) {
+	return 0, nil
+}
+
+func (f *fakeSoundLevelHistory) PruneSoundLevels(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newFakeSoundLevelHistory(t *testing.T) *fakeSoundLevelHistory {
+	t.Helper()
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.Local)
+	return &fakeSoundLevelHistory{
+		MockInterface: mocks.NewMockInterface(t),
+		records: []datastore.SoundLevelRecord{
+			{SourceURI: "hw:1,0", Start: start, Duration: 10, Resolution: 10, Leq: -40,
+				Bands: []datastore.SoundLevelBand{{CenterFreq: 1000, Leq: -40, Min: -45, Max: -35}}},
+			{SourceURI: "hw:1,0", Start: start.Add(20 * time.Minute), Duration: 10, Resolution: 10, Leq: -50,
+				Bands: []datastore.SoundLevelBand{{CenterFreq: 1000, Leq: -50, Min: -55, Max: -45}}},
+		},
+	}
+}
+
+func TestGetSoundLevelHistory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "default range", query: "", wantStatus: http.StatusOK},
+		{name: "date range", query: "start=2024-05-01&end=2024-05-01&source=hw:1,0", wantStatus: http.StatusOK},
+		{name: "rfc3339 range", query: "start=2024-05-01T06:00:00Z&end=2024-05-01T08:00:00Z", wantStatus: http.StatusOK},
+		{name: "invalid start", query: "start=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "reversed range", query: "start=2024-05-02&end=2024-05-01", wantStatus: http.StatusBadRequest},
+		{name: "range too large", query: "start=2024-05-01&end=2024-05-20", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			store := newFakeSoundLevelHistory(t)
+			c := &Controller{DS: store}
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/history?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			_ = c.GetSoundLevelHistory(e.NewContext(req, rec))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp SoundLevelHistoryResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Len(t, resp.Records, 2)
+			assert.LessOrEqual(t, resp.End.Sub(resp.Start), soundLevelHistoryMaxRange)
+		})
+	}
+
+	t.Run("whole days", func(t *testing.T) {
+		t.Parallel()
+		store := newFakeSoundLevelHistory(t)
+		c := &Controller{DS: store}
+		req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/history?start=2024-05-01&end=2024-05-01&source=Garden", http.NoBody)
+		rec := httptest.NewRecorder()
+
+		_ = c.GetSoundLevelHistory(echo.New().NewContext(req, rec))
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "Garden", store.lastQuery.Source)
+		assert.Equal(t, 24*time.Hour, store.lastQuery.End.Sub(store.lastQuery.Start), "a date as end includes the whole day")
+	})
+}
+
+func TestGetNoiseProfiles(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "single day", query: "start_date=2024-05-01", wantStatus: http.StatusOK},
+		{name: "hourly buckets", query: "start_date=2024-05-01&end_date=2024-05-02&bucket=60", wantStatus: http.StatusOK},
+		{name: "invalid bucket", query: "start_date=2024-05-01&bucket=7", wantStatus: http.StatusBadRequest},
+		{name: "range too large", query: "start_date=2024-01-01&end_date=2024-05-01", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &Controller{DS: newFakeSoundLevelHistory(t)}
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/profile?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			_ = c.GetNoiseProfiles(e.NewContext(req, rec))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp struct {
+				Profiles []datastore.NoiseProfile `json:"profiles"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			require.Len(t, resp.Profiles, 1)
+			assert.Equal(t, "2024-05-01", resp.Profiles[0].Date)
+			assert.NotEmpty(t, resp.Profiles[0].Buckets)
+		})
+	}
+}
+
+func TestGetDetectionSoundLevel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		id         string
+		wantStatus int
+		wantLevel  bool
+	}{
+		{name: "covered detection", id: "1", wantStatus: http.StatusOK, wantLevel: true},
+		{name: "no measurement", id: "2", wantStatus: http.StatusOK},
+		{name: "unknown detection", id: "3", wantStatus: http.StatusNotFound},
+		{name: "invalid id", id: "abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &Controller{DS: newFakeSoundLevelHistory(t)}
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/detection/"+tt.id, http.NoBody)
+			rec := httptest.NewRecorder()
+			ctx := e.NewContext(req, rec)
+			ctx.SetParamNames("id")
+			ctx.SetParamValues(tt.id)
+
+			_ = c.GetDetectionSoundLevel(ctx)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp DetectionSoundLevelResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, tt.wantLevel, resp.SoundLevel != nil)
+		})
+	}
+}
+
+func TestSoundLevelHistoryNotSupported(t *testing.T) {
+	t.Parallel()
+
+	c := &Controller{DS: mocks.NewMockInterface(t)}
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/history", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	_ = c.GetSoundLevelHistory(echo.New().NewContext(req, rec))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
+
+// fakeAcousticIndices serves fixed acoustic index records.
+type fakeAcousticIndices struct {
+	*mocks.MockInterface
+	records   []datastore.AcousticIndicesRecord
+	lastQuery datastore.SoundLevelQuery
+}
+
+func (f *fakeAcousticIndices) SaveAcousticIndices(context.Context, []datastore.AcousticIndicesRecord) error {
+	return nil
+}
+
+func (f *fakeAcousticIndices) GetAcousticIndices(_ context.Context, query datastore.SoundLevelQuery) ([]datastore.AcousticIndicesRecord, error) {
+	f.lastQuery = query
+	return f.records, nil
+}
+
+func (f *fakeAcousticIndices) PruneAcousticIndices(context.Context, time.Time) (int64, error) {
+	return 0, nil
+}
+
+func TestGetAcousticIndices(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 1, 7, 0, 0, 0, time.Local)
+	store := &fakeAcousticIndices{
+		MockInterface: mocks.NewMockInterface(t),
+		records: []datastore.AcousticIndicesRecord{
+			{SourceURI: "hw:1,0", Start: start, Duration: 60, ACI: 160.2, ADI: 2.1, NDSI: 0.6, BI: 14.8, H: 0.87},
+		},
+	}
+	c := &Controller{DS: store}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/indices?start=2024-05-01&end=2024-05-01&source=Garden", http.NoBody)
+	rec := httptest.NewRecorder()
+	_ = c.GetAcousticIndices(echo.New().NewContext(req, rec))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp AcousticIndicesResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	require.Len(t, resp.Records, 1)
+	assert.InDelta(t, 0.6, resp.Records[0].NDSI, 1e-9)
+	assert.Equal(t, "Garden", store.lastQuery.Source)
+	assert.Equal(t, 24*time.Hour, store.lastQuery.End.Sub(store.lastQuery.Start))
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/indices?start=2024-05-01&end=2024-05-20", http.NoBody)
+	rec = httptest.NewRecorder()
+	_ = c.GetAcousticIndices(echo.New().NewContext(req, rec))
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Datastores without acoustic index support
+	c = &Controller{DS: mocks.NewMockInterface(t)}
+	req = httptest.NewRequest(http.MethodGet, "/api/v2/soundlevels/indices", http.NoBody)
+	rec = httptest.NewRecorder()
+	_ = c.GetAcousticIndices(echo.New().NewContext(req, rec))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
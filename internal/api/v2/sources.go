@@ -0,0 +1,359 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	datastoreV2 "github.com/tphakala/birdnet-go/internal/datastore/v2"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	v2sources "github.com/tphakala/birdnet-go/internal/datastore/v2/sources"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// sourceSyncTimeout bounds database work when syncing streams with settings.
+const sourceSyncTimeout = 10 * time.Second
+
+// AudioSourceResponse is the API representation of a managed audio source.
+// The URL is sanitized; credentials are never returned.
+type AudioSourceResponse struct {
+	ID        uint             `json:"id"`
+	Name      string           `json:"name"`
+	URL       string           `json:"url"`
+	Type      string           `json:"type"`
+	Transport string           `json:"transport,omitempty"`
+	SRT       *SRTOptionsState `json:"srt,omitempty"`
+	Enabled   bool             `json:"enabled"`
+	Latitude  *float64         `json:"latitude,omitempty"`
+	Longitude *float64         `json:"longitude,omitempty"`
+	Notes     string           `json:"notes,omitempty"`
+	NodeName  string           `json:"nodeName"`
+	CreatedAt time.Time        `json:"createdAt"`
+	UpdatedAt time.Time        `json:"updatedAt"`
+}
+
+// SRTOptionsState describes the SRT options of a source without its passphrase.
+type SRTOptionsState struct {
+	Latency   int  `json:"latency"`
+	Encrypted bool `json:"encrypted"`
+}
+
+// AudioSourceRequest is the request body for creating or updating an audio source.
+// On update, omitted fields are left unchanged.
+type AudioSourceRequest struct {
+	Name          *string           `json:"name"`
+	URL           *string           `json:"url"`
+	Type          *string           `json:"type"`
+	Transport     *string           `json:"transport"`
+	SRT           *conf.SRTSettings `json:"srt"`
+	Enabled       *bool             `json:"enabled"`
+	Latitude      *float64          `json:"latitude"`
+	Longitude     *float64          `json:"longitude"`
+	ClearLocation bool              `json:"clearLocation"`
+	Notes         *string           `json:"notes"`
+}
+
+// AudioSourceImportRequest is the request body for importing stream definitions,
+// using the same format as the streams section of the config file.
+type AudioSourceImportRequest struct {
+	Streams []conf.StreamConfig `json:"streams"`
+}
+
+// initAudioSourceRoutes registers audio source management endpoints.
+// Sources are stored in the v2 schema only, so the routes require the v2 manager.
+func (c *Controller) initAudioSourceRoutes() {
+	if c.V2Manager == nil {
+		return
+	}
+
+	useV2Prefix, isMySQL := c.v2RepoOptions()
+	repo := repository.NewAudioSourceRepository(c.V2Manager.DB(), useV2Prefix, isMySQL)
+	nodeName := ""
+	if settings := c.getSettingsOrFallback(); settings != nil {
+		nodeName = settings.Main.Name
+	}
+	c.audioSources = v2sources.NewService(repo, nodeName)
+
+	// Sources expose stream endpoints, so all routes are protected
+	sourcesGroup := c.Group.Group("/sources", c.authMiddleware)
+	sourcesGroup.GET("", c.ListAudioSources)
+	sourcesGroup.GET("/:id", c.GetAudioSource)
+	sourcesGroup.POST("", c.CreateAudioSource)
+	sourcesGroup.PATCH("/:id", c.UpdateAudioSource)
+	sourcesGroup.DELETE("/:id", c.DeleteAudioSource)
+	sourcesGroup.POST("/import", c.ImportAudioSources)
+}
+
+// ListAudioSources returns all managed audio sources.
+func (c *Controller) ListAudioSources(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Audio source management")
+	}
+
+	list, err := c.audioSources.List(ctx.Request().Context())
+	if err != nil {
+		c.logErrorIfEnabled("failed to list audio sources", logger.Error(err))
+		return c.HandleError(ctx, err, "Failed to list audio sources", http.StatusInternalServerError)
+	}
+
+	result := make([]AudioSourceResponse, 0, len(list))
+	for _, source := range list {
+		result = append(result, toAudioSourceResponse(source))
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"sources": result,
+		"count":   len(result),
+	})
+}
+
+// GetAudioSource returns a single managed audio source by ID.
+func (c *Controller) GetAudioSource(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Audio source management")
+	}
+
+	id, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid source ID", http.StatusBadRequest)
+	}
+
+	source, err := c.audioSources.Get(ctx.Request().Context(), id)
+	if err != nil {
+		return c.handleAudioSourceError(ctx, err, "Failed to get audio source")
+	}
+
+	return ctx.JSON(http.StatusOK, toAudioSourceResponse(source))
+}
+
+// CreateAudioSource adds a managed audio source and starts capturing it when enabled.
+func (c *Controller) CreateAudioSource(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Audio source management")
+	}
+
+	var req AudioSourceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if req.Name == nil || req.URL == nil || req.Type == nil {
+		return c.HandleError(ctx, errors.NewStd("missing required fields"),
+			"Name, URL and type are required", http.StatusBadRequest)
+	}
+
+	spec := v2sources.Spec{
+		Name:      *req.Name,
+		URL:       *req.URL,
+		Type:      *req.Type,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		Notes:     req.Notes,
+	}
+	if req.Transport != nil {
+		spec.Transport = *req.Transport
+	}
+	if req.SRT != nil {
+		spec.SRT = *req.SRT
+	}
+
+	source, err := c.audioSources.Create(ctx.Request().Context(), &spec)
+	if err != nil {
+		return c.handleAudioSourceError(ctx, err, "Failed to create audio source")
+	}
+
+	c.logInfoIfEnabled("audio source created",
+		logger.Uint64("source_id", uint64(source.ID)),
+		logger.String("url", source.SourceURI))
+	c.applyDatabaseStreams(ctx.Request().Context())
+
+	return ctx.JSON(http.StatusCreated, toAudioSourceResponse(source))
+}
+
+// UpdateAudioSource renames, re-points, enables/disables or relocates a source.
+// The source keeps its ID, so historical detections stay attached.
+func (c *Controller) UpdateAudioSource(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Audio source management")
+	}
+
+	id, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid source ID", http.StatusBadRequest)
+	}
+
+	var req AudioSourceRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+
+	source, err := c.audioSources.Update(ctx.Request().Context(), id, &v2sources.Patch{
+		Name:          req.Name,
+		URL:           req.URL,
+		Type:          req.Type,
+		Transport:     req.Transport,
+		SRT:           req.SRT,
+		Enabled:       req.Enabled,
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
+		ClearLocation: req.ClearLocation,
+		Notes:         req.Notes,
+	})
+	if err != nil {
+		return c.handleAudioSourceError(ctx, err, "Failed to update audio source")
+	}
+
+	c.logInfoIfEnabled("audio source updated", logger.Uint64("source_id", uint64(id)))
+	c.applyDatabaseStreams(ctx.Request().Context())
+
+	return ctx.JSON(http.StatusOK, toAudioSourceResponse(source))
+}
+
+// DeleteAudioSource stops managing a source. Its historical detections are kept.
+func (c *Controller) DeleteAudioSource(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Audio source management")
+	}
+
+	id, err := parseUintParam(ctx, "id")
+	if err != nil {
+		return c.HandleError(ctx, err, "Invalid source ID", http.StatusBadRequest)
+	}
+
+	if err := c.audioSources.Delete(ctx.Request().Context(), id); err != nil {
+		return c.handleAudioSourceError(ctx, err, "Failed to delete audio source")
+	}
+
+	c.logInfoIfEnabled("audio source deleted", logger.Uint64("source_id", uint64(id)))
+	c.applyDatabaseStreams(ctx.Request().Context())
+
+	return ctx.NoContent(http.StatusNoContent)
+}
+
+// ImportAudioSources imports stream definitions in config file format.
+// Streams are matched by URL, so re-importing updates names instead of duplicating sources.
+func (c *Controller) ImportAudioSources(ctx echo.Context) error {
+	if !datastoreV2.IsEnhancedDatabase() {
+		return c.requireV2For(ctx, "Audio source management")
+	}
+
+	var req AudioSourceImportRequest
+	if err := ctx.Bind(&req); err != nil {
+		return c.HandleError(ctx, err, "Invalid request body", http.StatusBadRequest)
+	}
+	if len(req.Streams) == 0 {
+		return c.HandleError(ctx, errors.NewStd("no streams provided"), "At least one stream is required", http.StatusBadRequest)
+	}
+
+	result, err := c.audioSources.ImportStreams(ctx.Request().Context(), req.Streams)
+	if err != nil {
+		c.logErrorIfEnabled("failed to import audio sources", logger.Error(err))
+		return c.HandleError(ctx, err, "Failed to import audio sources", http.StatusInternalServerError)
+	}
+
+	c.logInfoIfEnabled("audio sources imported",
+		logger.Int("created", result.Created),
+		logger.Int("updated", result.Updated),
+		logger.Int("skipped", result.Skipped))
+	c.applyDatabaseStreams(ctx.Request().Context())
+
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// applyDatabaseStreams replaces the configured streams with the enabled database
+// sources, persists them as a fallback for legacy mode and reconfigures capture.
+func (c *Controller) applyDatabaseStreams(ctx context.Context) {
+	streams, err := c.audioSources.EnabledStreams(ctx)
+	if err != nil {
+		c.logErrorIfEnabled("failed to load audio sources for capture", logger.Error(err))
+		return
+	}
+
+	c.settingsMutex.Lock()
+	settings := c.getSettingsOrFallback()
+	if settings == nil {
+		c.settingsMutex.Unlock()
+		return
+	}
+	v2sources.PreserveStreamSettings(streams, settings.Realtime.RTSP.Streams)
+	settings.Realtime.RTSP.Streams = streams
+	if !c.DisableSaveSettings {
+		if err := conf.SaveSettings(); err != nil {
+			c.logWarnIfEnabled("failed to save streams to config file", logger.Error(err))
+		}
+	}
+	c.settingsMutex.Unlock()
+
+	if c.controlChan != nil {
+		go func() {
+			c.controlChan <- "reconfigure_rtsp_sources"
+		}()
+	}
+}
+
+// syncStreamsToDatabase mirrors stream edits made through the settings API into
+// the database so it stays the source of truth. Must be called with settingsMutex held.
+func (c *Controller) syncStreamsToDatabase(streams []conf.StreamConfig) {
+	if c.audioSources == nil || !datastoreV2.IsEnhancedDatabase() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sourceSyncTimeout)
+	defer cancel()
+
+	result, err := c.audioSources.ReconcileStreams(ctx, streams)
+	if err != nil {
+		c.logWarnIfEnabled("failed to sync streams to database", logger.Error(err))
+		return
+	}
+	c.logDebugIfEnabled("synced streams to database",
+		logger.Int("created", result.Created),
+		logger.Int("updated", result.Updated),
+		logger.Int("disabled", result.Disabled))
+}
+
+// handleAudioSourceError maps audio source errors to HTTP responses.
+func (c *Controller) handleAudioSourceError(ctx echo.Context, err error, message string) error {
+	switch {
+	case errors.Is(err, v2sources.ErrInvalidSource):
+		return c.HandleError(ctx, err, "Invalid audio source", http.StatusBadRequest)
+	case errors.Is(err, repository.ErrAudioSourceNotFound):
+		return c.HandleError(ctx, err, "Audio source not found", http.StatusNotFound)
+	case errors.Is(err, v2sources.ErrDuplicateName):
+		return c.HandleError(ctx, err, "An audio source with this name already exists", http.StatusConflict)
+	case errors.Is(err, repository.ErrDuplicateKey):
+		return c.HandleError(ctx, err, "An audio source with this URL already exists", http.StatusConflict)
+	default:
+		c.logErrorIfEnabled(message, logger.Error(err))
+		return c.HandleError(ctx, err, message, http.StatusInternalServerError)
+	}
+}
+
+// toAudioSourceResponse converts an audio source entity to its API representation.
+func toAudioSourceResponse(source *entities.AudioSource) AudioSourceResponse {
+	stream := v2sources.ToStreamConfig(source)
+	resp := AudioSourceResponse{
+		ID:        source.ID,
+		Name:      stream.Name,
+		URL:       source.SourceURI,
+		Type:      stream.Type,
+		Transport: stream.Transport,
+		Enabled:   source.Enabled,
+		Latitude:  source.Latitude,
+		Longitude: source.Longitude,
+		NodeName:  source.NodeName,
+		CreatedAt: source.CreatedAt,
+		UpdatedAt: source.UpdatedAt,
+	}
+	if stream.Type == conf.StreamTypeSRT {
+		resp.SRT = &SRTOptionsState{Latency: stream.SRT.Latency, Encrypted: stream.SRT.Passphrase != ""}
+	}
+	if source.Notes != nil {
+		resp.Notes = *source.Notes
+	}
+	return resp
+}
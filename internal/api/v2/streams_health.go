@@ -39,19 +39,29 @@ type SSEStreamHealthData struct {
 
 // StreamHealthResponse represents the API response for a single stream's health
 type StreamHealthResponse struct {
-	Name             string     `json:"name,omitempty"`                    // Stream name from config (empty if not found)
-	Type             string     `json:"type,omitempty"`                    // Stream type from config (empty if not found)
-	URL              string     `json:"url"`                               // Sanitized RTSP URL
-	IsHealthy        bool       `json:"is_healthy"`                        // Overall health status
-	ProcessState     string     `json:"process_state"`                     // Current process state (idle, starting, running, etc.)
-	LastDataReceived *time.Time `json:"last_data_received"`                // When data was last received (null if never)
-	TimeSinceData    *float64   `json:"time_since_data_seconds,omitempty"` // Seconds since last data (omitempty for never)
-	RestartCount     int        `json:"restart_count"`                     // Number of times stream has been restarted
-	Error            string     `json:"error,omitempty"`                   // Current error message if any
+	Name             string                  `json:"name,omitempty"`                    // Stream name from config (empty if not found)
+	Type             string                  `json:"type,omitempty"`                    // Stream type from config (empty if not found)
+	URL              string                  `json:"url"`                               // Sanitized RTSP URL
+	IsHealthy        bool                    `json:"is_healthy"`                        // Overall health status
+	ProcessState     string                  `json:"process_state"`                     // Current process state (idle, starting, running, etc.)
+	NativeClient     bool                    `json:"native_client"`                     // Whether the in-process RTSP client reads the stream instead of FFmpeg
+	Metadata         *StreamMetadataResponse `json:"metadata,omitempty"`                // Icecast/Shoutcast station metadata (omitted if the server sends none)
+	LastDataReceived *time.Time              `json:"last_data_received"`                // When data was last received (null if never)
+	TimeSinceData    *float64                `json:"time_since_data_seconds,omitempty"` // Seconds since last data (omitempty for never)
+	RestartCount     int                     `json:"restart_count"`                     // Number of times stream has been restarted
+	Error            string                  `json:"error,omitempty"`                   // Current error message if any
 	// Data statistics
 	TotalBytesReceived int64   `json:"total_bytes_received"` // Total bytes received
 	BytesPerSecond     float64 `json:"bytes_per_second"`     // Current data rate
 	IsReceivingData    bool    `json:"is_receiving_data"`    // Whether stream is actively receiving data
+	// Audio timeline statistics
+	LatencySeconds  float64 `json:"latency_seconds"`   // Steady delay of the audio behind the wall clock
+	DriftPPM        float64 `json:"drift_ppm"`         // Clock drift in parts per million, positive when the stream runs slow
+	TotalGapSeconds float64 `json:"total_gap_seconds"` // Audio lost in gaps
+	GapCount        int     `json:"gap_count"`         // Number of gaps
+	BurstCount      int     `json:"burst_count"`       // Stalls recovered by a burst of buffered audio
+	// Audio quality diagnostics of the last analysis interval (omitted until the first one completes)
+	AudioQuality *myaudio.AudioDiagnostics `json:"audio_quality,omitempty"`
 	// Error diagnostics (from PR #1380)
 	LastErrorContext *ErrorContextResponse   `json:"last_error_context,omitempty"` // Most recent error with troubleshooting
 	ErrorHistory     []*ErrorContextResponse `json:"error_history,omitempty"`      // Recent errors (last 10)
@@ -77,6 +87,17 @@ type ErrorContextResponse struct {
 	ShouldRestart     bool `json:"should_restart"`      // Whether stream should restart
 }
 
+// StreamMetadataResponse represents the station metadata of an Icecast/Shoutcast stream
+type StreamMetadataResponse struct {
+	StationName string    `json:"station_name,omitempty"` // Station or mount name (icy-name)
+	Description string    `json:"description,omitempty"`  // Station description
+	Genre       string    `json:"genre,omitempty"`        // Station genre
+	StationURL  string    `json:"station_url,omitempty"`  // Station website
+	Bitrate     int       `json:"bitrate_kbps,omitempty"` // Advertised bitrate in kbit/s
+	Title       string    `json:"title,omitempty"`        // Current stream title
+	UpdatedAt   time.Time `json:"updated_at"`             // When the metadata was read
+}
+
 // StateTransitionResponse represents a state transition event
 type StateTransitionResponse struct {
 	FromState string    `json:"from_state"`       // Previous state
@@ -90,8 +111,11 @@ type StreamsStatusSummaryResponse struct {
 	TotalStreams     int                     `json:"total_streams"`     // Total number of configured streams
 	HealthyStreams   int                     `json:"healthy_streams"`   // Number of healthy streams
 	UnhealthyStreams int                     `json:"unhealthy_streams"` // Number of unhealthy streams
+	IdleStreams      int                     `json:"idle_streams"`      // Number of streams idle outside their recording schedule
 	StreamsSummary   []StreamSummaryResponse `json:"streams_summary"`   // Brief summary of each stream
 	Timestamp        time.Time               `json:"timestamp"`         // When this status was generated
+	// Recording schedule of the audio device, if it has one
+	AudioDeviceSchedule *ScheduleStatusResponse `json:"audio_device_schedule,omitempty"`
 }
 
 // StreamSummaryResponse provides a brief summary of a single stream
@@ -103,8 +127,24 @@ type StreamSummaryResponse struct {
 	ProcessState  string   `json:"process_state"`                     // Current state
 	LastErrorType string   `json:"last_error_type,omitempty"`         // Type of last error if any
 	TimeSinceData *float64 `json:"time_since_data_seconds,omitempty"` // Seconds since last data
+	// Recording schedule state (omitted for streams without a schedule)
+	Schedule *ScheduleStatusResponse `json:"schedule,omitempty"`
 }
 
+// ScheduleStatusResponse describes the recording schedule state of a source
+type ScheduleStatusResponse struct {
+	State      string                `json:"state"`                 // "active" inside a window, "idle" outside
+	NextChange *time.Time            `json:"next_change,omitempty"` // When the state changes next
+	Windows    []conf.ScheduleWindow `json:"windows"`               // Configured windows
+	Error      string                `json:"error,omitempty"`       // Evaluation error, the source stays active
+}
+
+// Recording schedule states
+const (
+	scheduleStateActive = "active"
+	scheduleStateIdle   = "idle"
+)
+
 // initStreamHealthRoutes registers all stream health monitoring endpoints
 func (c *Controller) initStreamHealthRoutes() {
 	// All health endpoints require authentication as they may contain sensitive data
@@ -298,22 +338,117 @@ func (c *Controller) GetStreamsStatusSummary(ctx echo.Context) error {
 			streamSummary.LastErrorType = health.LastErrorContext.ErrorType
 		}
 
+		streamSummary.Schedule = streamScheduleStatus(rawURL)
+
 		summary.StreamsSummary = append(summary.StreamsSummary, streamSummary)
 	}
 
+	// Streams idle outside their recording schedule have no FFmpeg process
+	// and so no health data, report them from the configuration
+	if settings := conf.GetSettings(); settings != nil {
+		for i := range settings.Realtime.RTSP.Streams {
+			stream := &settings.Realtime.RTSP.Streams[i]
+			if _, running := healthData[stream.URL]; running {
+				continue
+			}
+			schedule := streamScheduleStatus(stream.URL)
+			if schedule == nil || schedule.State != scheduleStateIdle {
+				continue
+			}
+			summary.TotalStreams++
+			summary.IdleStreams++
+			summary.StreamsSummary = append(summary.StreamsSummary, StreamSummaryResponse{
+				Name:         stream.Name,
+				Type:         stream.Type,
+				URL:          privacy.SanitizeStreamUrl(stream.URL),
+				ProcessState: scheduleStateIdle,
+				Schedule:     schedule,
+			})
+		}
+
+		if settings.Realtime.Audio.Source != "" {
+			summary.AudioDeviceSchedule = newScheduleStatusResponse(settings.Realtime.Audio.Source, &settings.Realtime.Audio.Schedule)
+		}
+	}
+
 	return ctx.JSON(http.StatusOK, summary)
 }
 
+// streamScheduleStatus returns the schedule state of the configured stream
+// with rawURL, or nil if the stream has no recording schedule.
+func streamScheduleStatus(rawURL string) *ScheduleStatusResponse {
+	settings := conf.GetSettings()
+	if settings == nil {
+		return nil
+	}
+	for i := range settings.Realtime.RTSP.Streams {
+		if settings.Realtime.RTSP.Streams[i].URL == rawURL {
+			return newScheduleStatusResponse(rawURL, &settings.Realtime.RTSP.Streams[i].Schedule)
+		}
+	}
+	return nil
+}
+
+// newScheduleStatusResponse builds the schedule state of a source from the
+// state last evaluated by capture. Returns nil if the schedule is disabled or
+// has not been evaluated yet.
+func newScheduleStatusResponse(key string, schedule *conf.ScheduleSettings) *ScheduleStatusResponse {
+	if !schedule.Enabled {
+		return nil
+	}
+	state, ok := myaudio.GetScheduleState(key)
+	if !ok {
+		return nil
+	}
+	resp := &ScheduleStatusResponse{
+		State:   scheduleStateIdle,
+		Windows: schedule.Windows,
+		Error:   state.Error,
+	}
+	if state.Active {
+		resp.State = scheduleStateActive
+	}
+	if !state.NextChange.IsZero() {
+		nextChange := state.NextChange
+		resp.NextChange = &nextChange
+	}
+	return resp
+}
+
+// convertStreamMetadataToResponse converts stream metadata to API response format
+func convertStreamMetadataToResponse(metadata *myaudio.StreamMetadata) *StreamMetadataResponse {
+	if metadata == nil {
+		return nil
+	}
+	return &StreamMetadataResponse{
+		StationName: metadata.Name,
+		Description: metadata.Description,
+		Genre:       metadata.Genre,
+		StationURL:  metadata.URL,
+		Bitrate:     metadata.Bitrate,
+		Title:       metadata.Title,
+		UpdatedAt:   metadata.UpdatedAt,
+	}
+}
+
 // convertStreamHealthToResponse converts internal StreamHealth to API response format
 func convertStreamHealthToResponse(rawURL string, health *myaudio.StreamHealth) StreamHealthResponse {
 	response := StreamHealthResponse{
 		URL:                privacy.SanitizeStreamUrl(rawURL),
 		IsHealthy:          health.IsHealthy,
 		ProcessState:       health.ProcessState.String(),
+		NativeClient:       health.NativeClient,
+		Metadata:           convertStreamMetadataToResponse(health.Metadata),
 		RestartCount:       health.RestartCount,
 		TotalBytesReceived: health.TotalBytesReceived,
 		BytesPerSecond:     health.BytesPerSecond,
 		IsReceivingData:    health.IsReceivingData,
+		LatencySeconds:     health.Latency.Seconds(),
+		DriftPPM:           health.DriftPPM,
+		TotalGapSeconds:    health.TotalGapTime.Seconds(),
+		GapCount:           health.GapCount,
+		BurstCount:         health.BurstCount,
+		AudioQuality:       health.AudioQuality,
 	}
 
 	// Handle LastDataReceived (may be zero time if never received data)
@@ -679,10 +814,18 @@ func (c *Controller) sendStreamStatsUpdate(ctx echo.Context, rawURL string, heal
 		URL:                privacy.SanitizeStreamUrl(rawURL),
 		IsHealthy:          health.IsHealthy,
 		ProcessState:       health.ProcessState.String(),
+		NativeClient:       health.NativeClient,
+		Metadata:           convertStreamMetadataToResponse(health.Metadata),
 		RestartCount:       health.RestartCount,
 		TotalBytesReceived: health.TotalBytesReceived,
 		BytesPerSecond:     health.BytesPerSecond,
 		IsReceivingData:    health.IsReceivingData,
+		LatencySeconds:     health.Latency.Seconds(),
+		DriftPPM:           health.DriftPPM,
+		TotalGapSeconds:    health.TotalGapTime.Seconds(),
+		GapCount:           health.GapCount,
+		BurstCount:         health.BurstCount,
+		AudioQuality:       health.AudioQuality,
 		TimeSinceData:      timeSinceData,
 		// Explicitly omit: ErrorHistory, StateHistory, LastErrorContext
 	}
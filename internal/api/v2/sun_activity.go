@@ -0,0 +1,177 @@
+// internal/api/v2/sun_activity.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// Sun-relative activity query limits
+const (
+	sunActivityMaxDays          = 366 // Maximum date range of one query
+	sunActivityMinWindowMinutes = 30
+	sunActivityMaxWindowMinutes = 720
+	sunActivityMaxOnsetSpecies  = 20
+)
+
+// sunActivityBucketSizes lists the accepted histogram bucket widths in minutes.
+var sunActivityBucketSizes = []int{5, 10, 15, 30, 60}
+
+// GetSunRelativeActivity handles GET /api/v2/analytics/time/sun-relative
+// Buckets detections by minutes relative to sunrise, sunset or civil twilight,
+// overall and per species, and reports the daily dawn chorus onset. The
+// species filter limits the histograms; the dawn chorus always covers all
+// species.
+//
+// Query parameters: start_date, end_date (default: last 30 days), species,
+// event (civil_dawn, sunrise, sunset, civil_dusk; default sunrise),
+// bucket (minutes, default 15), window (minutes either side, default 240),
+// onset_species (distinct species marking chorus onset, default 3).
+func (c *Controller) GetSunRelativeActivity(ctx echo.Context) error {
+	const operation = "sun-relative activity"
+
+	provider, ok := c.DS.(datastore.DetectionMinuteProvider)
+	if !ok || c.SunCalc == nil {
+		return c.HandleError(ctx, errors.NewStd("sun-relative activity not supported"),
+			"Sun-relative activity requires sun calculations and a supported datastore", http.StatusNotImplemented)
+	}
+
+	startDate := ctx.QueryParam("start_date")
+	endDate := ctx.QueryParam("end_date")
+	if endDate == "" {
+		endDate = time.Now().Format(time.DateOnly)
+	}
+	if startDate == "" {
+		end, err := time.Parse(time.DateOnly, endDate)
+		if err != nil {
+			return c.HandleError(ctx, err, "Invalid end_date format. Use YYYY-MM-DD", http.StatusBadRequest)
+		}
+		startDate = end.AddDate(0, 0, -defaultAnalyticsDays).Format(time.DateOnly)
+	}
+	if err := c.validateDateRangeWithResponse(ctx, startDate, endDate, operation); err != nil {
+		return err
+	}
+	start, _ := time.Parse(time.DateOnly, startDate) // validated above
+	end, _ := time.Parse(time.DateOnly, endDate)
+	if end.Sub(start) > sunActivityMaxDays*24*time.Hour {
+		return c.HandleError(ctx, errors.NewStd("date range too large"),
+			fmt.Sprintf("Date range cannot exceed %d days", sunActivityMaxDays), http.StatusBadRequest)
+	}
+
+	opts, err := c.parseSunActivityOptions(ctx)
+	if err != nil {
+		return err
+	}
+	species := ctx.QueryParam("species")
+
+	c.logInfoIfEnabled("Retrieving sun-relative activity",
+		logger.String("start_date", startDate),
+		logger.String("end_date", endDate),
+		logger.String("species", species),
+		logger.String("event", opts.Event),
+		logger.String("ip", ctx.RealIP()),
+		logger.String("path", ctx.Request().URL.Path),
+	)
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), analyticsQueryTimeout)
+	defer cancel()
+
+	rows, err := c.querySunActivityRows(reqCtx, ctx, provider, startDate, endDate, species)
+	if err != nil {
+		return err
+	}
+	activity := datastore.ComputeSunRelativeActivity(rows, c.SunCalc.GetSunEventTimes, opts)
+
+	// The chorus onset counts distinct species, so a species filter would
+	// hide it; compute it from the detections of all species instead.
+	if species != "" {
+		all, err := c.querySunActivityRows(reqCtx, ctx, provider, startDate, endDate, "")
+		if err != nil {
+			return err
+		}
+		activity.DawnChorus = datastore.ComputeDawnChorus(all, c.SunCalc.GetSunEventTimes, opts.OnsetSpecies)
+	}
+
+	return ctx.JSON(http.StatusOK, activity)
+}
+
+// querySunActivityRows fetches per-minute detection counts, writing the error
+// response and returning ErrResponseHandled when the query fails.
+func (c *Controller) querySunActivityRows(reqCtx context.Context, ctx echo.Context, provider datastore.DetectionMinuteProvider,
+	startDate, endDate, species string) ([]datastore.DetectionMinuteCount, error) {
+	rows, err := provider.GetDetectionMinuteCounts(reqCtx, startDate, endDate, species)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			_ = c.HandleError(ctx, err, "Query timeout - please try a smaller date range", http.StatusRequestTimeout)
+			return nil, ErrResponseHandled
+		}
+		c.logErrorIfEnabled("Failed to get detection minute counts",
+			logger.String("start_date", startDate),
+			logger.String("end_date", endDate),
+			logger.String("species", species),
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+			logger.String("path", ctx.Request().URL.Path),
+		)
+		_ = c.HandleError(ctx, err, "Failed to get sun-relative activity", http.StatusInternalServerError)
+		return nil, ErrResponseHandled
+	}
+	return rows, nil
+}
+
+// parseSunActivityOptions parses and validates the histogram options.
+func (c *Controller) parseSunActivityOptions(ctx echo.Context) (datastore.SunActivityOptions, error) {
+	opts := datastore.SunActivityOptions{
+		Event:         datastore.SunEventSunrise,
+		BucketMinutes: datastore.DefaultSunActivityBucketMinutes,
+		WindowMinutes: datastore.DefaultSunActivityWindowMinutes,
+		OnsetSpecies:  datastore.DefaultDawnChorusOnsetSpecies,
+	}
+
+	if event := ctx.QueryParam("event"); event != "" {
+		if !datastore.ValidSunEvent(event) {
+			_ = c.HandleError(ctx, nil, "Invalid event: must be one of civil_dawn, sunrise, sunset, civil_dusk", http.StatusBadRequest)
+			return opts, ErrResponseHandled
+		}
+		opts.Event = event
+	}
+
+	if value := ctx.QueryParam("bucket"); value != "" {
+		bucket, err := strconv.Atoi(value)
+		if err != nil || !slices.Contains(sunActivityBucketSizes, bucket) {
+			_ = c.HandleError(ctx, err, fmt.Sprintf("Invalid bucket: must be one of %v minutes", sunActivityBucketSizes), http.StatusBadRequest)
+			return opts, ErrResponseHandled
+		}
+		opts.BucketMinutes = bucket
+	}
+
+	if value := ctx.QueryParam("window"); value != "" {
+		window, err := strconv.Atoi(value)
+		if err != nil || window < sunActivityMinWindowMinutes || window > sunActivityMaxWindowMinutes {
+			_ = c.HandleError(ctx, err, fmt.Sprintf("Invalid window: must be between %d and %d minutes",
+				sunActivityMinWindowMinutes, sunActivityMaxWindowMinutes), http.StatusBadRequest)
+			return opts, ErrResponseHandled
+		}
+		opts.WindowMinutes = window
+	}
+
+	if value := ctx.QueryParam("onset_species"); value != "" {
+		onset, err := strconv.Atoi(value)
+		if err != nil || onset < 1 || onset > sunActivityMaxOnsetSpecies {
+			_ = c.HandleError(ctx, err, fmt.Sprintf("Invalid onset_species: must be between 1 and %d", sunActivityMaxOnsetSpecies), http.StatusBadRequest)
+			return opts, ErrResponseHandled
+		}
+		opts.OnsetSpecies = onset
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+// fakeMinuteStore serves fixed per-minute detection counts.
+type fakeMinuteStore struct {
+	*mocks.MockInterface
+	rows []datastore.DetectionMinuteCount
+}
+
+func (f *fakeMinuteStore) GetDetectionMinuteCounts(_ context.Context, _, _, species string) ([]datastore.DetectionMinuteCount, error) {
+	if species == "" {
+		return f.rows, nil
+	}
+	var rows []datastore.DetectionMinuteCount
+	for _, row := range f.rows {
+		if row.ScientificName == species || row.CommonName == species {
+			rows = append(rows, row)
+		}
+	}
+	return rows, nil
+}
+
+func TestGetSunRelativeActivity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "defaults", query: "start_date=2024-05-01&end_date=2024-05-02", wantStatus: http.StatusOK},
+		{name: "sunset event", query: "start_date=2024-05-01&end_date=2024-05-02&event=sunset&bucket=30&window=120", wantStatus: http.StatusOK},
+		{name: "invalid event", query: "start_date=2024-05-01&end_date=2024-05-02&event=noon", wantStatus: http.StatusBadRequest},
+		{name: "invalid bucket", query: "start_date=2024-05-01&end_date=2024-05-02&bucket=7", wantStatus: http.StatusBadRequest},
+		{name: "window too large", query: "start_date=2024-05-01&end_date=2024-05-02&window=1000", wantStatus: http.StatusBadRequest},
+		{name: "range too large", query: "start_date=2022-01-01&end_date=2024-05-02", wantStatus: http.StatusBadRequest},
+		{name: "reversed dates", query: "start_date=2024-05-02&end_date=2024-05-01", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := &Controller{
+				DS: &fakeMinuteStore{
+					MockInterface: mocks.NewMockInterface(t),
+					rows: []datastore.DetectionMinuteCount{
+						{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Date: "2024-05-01", Minute: "04:30", Count: 2},
+					},
+				},
+				SunCalc: suncalc.NewSunCalc(60.17, 24.94),
+			}
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/time/sun-relative?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			_ = c.GetSunRelativeActivity(e.NewContext(req, rec))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp datastore.SunRelativeActivity
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, 2, resp.Total)
+			require.Len(t, resp.Species, 1)
+			assert.Equal(t, "Eurasian Blackbird", resp.Species[0].CommonName)
+		})
+	}
+}
+
+func TestGetSunRelativeActivity_SpeciesFilterKeepsDawnChorus(t *testing.T) {
+	t.Parallel()
+
+	sc := suncalc.NewSunCalc(60.17, 24.94)
+	day, err := time.ParseInLocation(time.DateOnly, "2024-05-01", time.Local)
+	require.NoError(t, err)
+	times, err := sc.GetSunEventTimes(day)
+	require.NoError(t, err)
+	sunrise := times.Sunrise.In(time.Local).Format("15:04")
+
+	c := &Controller{
+		DS: &fakeMinuteStore{
+			MockInterface: mocks.NewMockInterface(t),
+			rows: []datastore.DetectionMinuteCount{
+				{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Date: "2024-05-01", Minute: sunrise, Count: 2},
+				{ScientificName: "Erithacus rubecula", CommonName: "European Robin", Date: "2024-05-01", Minute: sunrise, Count: 1},
+				{ScientificName: "Fringilla coelebs", CommonName: "Common Chaffinch", Date: "2024-05-01", Minute: sunrise, Count: 1},
+			},
+		},
+		SunCalc: sc,
+	}
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet,
+		"/api/v2/analytics/time/sun-relative?start_date=2024-05-01&end_date=2024-05-01&species=Turdus+merula", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	require.NoError(t, c.GetSunRelativeActivity(e.NewContext(req, rec)))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var resp datastore.SunRelativeActivity
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	// The histograms only count the filtered species
+	assert.Equal(t, 2, resp.Total)
+	require.Len(t, resp.Species, 1)
+	assert.Equal(t, "Turdus merula", resp.Species[0].ScientificName)
+	// The chorus onset still counts every species
+	require.Len(t, resp.DawnChorus, 1)
+	assert.Equal(t, sunrise, resp.DawnChorus[0].Onset)
+	assert.Equal(t, 3, resp.DawnChorus[0].MorningSpecies)
+}
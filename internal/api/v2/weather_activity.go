@@ -0,0 +1,161 @@
+// internal/api/v2/weather_activity.go
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/weather"
+)
+
+// weatherActivityMaxDays limits the date range of weather-correlated analytics.
+// Hourly weather is loaded one day at a time, so this bounds the query count.
+const weatherActivityMaxDays = 92
+
+// weatherActivityInput holds the validated inputs of a weather analytics request.
+type weatherActivityInput struct {
+	startDate  string
+	endDate    string
+	detections []datastore.DetectionMinuteCount
+	hourly     []datastore.HourlyWeather
+}
+
+// initWeatherActivityRoutes registers weather-correlated analytics under the analytics group.
+func (c *Controller) initWeatherActivityRoutes(analyticsGroup *echo.Group) {
+	weatherGroup := analyticsGroup.Group("/weather")
+	weatherGroup.GET("/activity", c.GetWeatherActivity)
+	weatherGroup.GET("/daily", c.GetWeatherDailyActivity)
+}
+
+// GetWeatherActivity handles GET /api/v2/analytics/weather/activity
+// Returns detections per hour binned by temperature, wind speed, cloud cover,
+// pressure and weather condition, overall and per species.
+func (c *Controller) GetWeatherActivity(ctx echo.Context) error {
+	input, err := c.loadWeatherActivityInput(ctx, "weather activity")
+	if err != nil {
+		return err
+	}
+
+	result, err := weather.ComputeActivityCorrelation(input.startDate, input.endDate, time.Local, input.detections, input.hourly)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to compute weather activity", http.StatusInternalServerError)
+	}
+	return ctx.JSON(http.StatusOK, result)
+}
+
+// GetWeatherDailyActivity handles GET /api/v2/analytics/weather/daily
+// Compares each day's detections with nearby dry days and flags low-activity
+// days that the weather does not explain. Optional "rainy_only=true" limits
+// the result to days with precipitation.
+func (c *Controller) GetWeatherDailyActivity(ctx echo.Context) error {
+	input, err := c.loadWeatherActivityInput(ctx, "weather daily activity")
+	if err != nil {
+		return err
+	}
+
+	days, err := weather.ComputeDailyActivity(input.startDate, input.endDate, time.Local, input.detections, input.hourly)
+	if err != nil {
+		return c.HandleError(ctx, err, "Failed to compute daily weather activity", http.StatusInternalServerError)
+	}
+
+	if ctx.QueryParam("rainy_only") == "true" {
+		rainy := make([]weather.DayActivity, 0, len(days))
+		for i := range days {
+			if days[i].Rainy {
+				rainy = append(rainy, days[i])
+			}
+		}
+		days = rainy
+	}
+
+	return ctx.JSON(http.StatusOK, map[string]any{
+		"start_date": input.startDate,
+		"end_date":   input.endDate,
+		"days":       days,
+	})
+}
+
+// loadWeatherActivityInput validates the date range and loads detections and
+// hourly weather. Query parameters: start_date, end_date (default: last 30
+// days), species. Writes the error response itself on failure.
+func (c *Controller) loadWeatherActivityInput(ctx echo.Context, operation string) (*weatherActivityInput, error) {
+	provider, ok := c.DS.(datastore.DetectionMinuteProvider)
+	if !ok {
+		_ = c.HandleError(ctx, errors.NewStd("weather activity not supported"),
+			"Weather analytics are not supported by this datastore", http.StatusNotImplemented)
+		return nil, ErrResponseHandled
+	}
+
+	startDate := ctx.QueryParam("start_date")
+	endDate := ctx.QueryParam("end_date")
+	if endDate == "" {
+		endDate = time.Now().Format(time.DateOnly)
+	}
+	if startDate == "" {
+		end, err := time.Parse(time.DateOnly, endDate)
+		if err != nil {
+			_ = c.HandleError(ctx, err, "Invalid end_date format. Use YYYY-MM-DD", http.StatusBadRequest)
+			return nil, ErrResponseHandled
+		}
+		startDate = end.AddDate(0, 0, -defaultAnalyticsDays).Format(time.DateOnly)
+	}
+	if err := c.validateDateRangeWithResponse(ctx, startDate, endDate, operation); err != nil {
+		return nil, err
+	}
+	start, _ := time.Parse(time.DateOnly, startDate) // validated above
+	end, _ := time.Parse(time.DateOnly, endDate)
+	if end.Sub(start) > weatherActivityMaxDays*24*time.Hour {
+		_ = c.HandleError(ctx, errors.NewStd("date range too large"),
+			fmt.Sprintf("Date range cannot exceed %d days", weatherActivityMaxDays), http.StatusBadRequest)
+		return nil, ErrResponseHandled
+	}
+
+	reqCtx, cancel := context.WithTimeout(ctx.Request().Context(), analyticsQueryTimeout)
+	defer cancel()
+
+	species := ctx.QueryParam("species")
+	detections, err := provider.GetDetectionMinuteCounts(reqCtx, startDate, endDate, species)
+	if err != nil {
+		c.logErrorIfEnabled("Failed to get detections for weather analytics",
+			logger.String("operation", operation),
+			logger.String("start_date", startDate),
+			logger.String("end_date", endDate),
+			logger.Error(err),
+			logger.String("ip", ctx.RealIP()),
+		)
+		_ = c.HandleError(ctx, err, "Failed to get detection data", http.StatusInternalServerError)
+		return nil, ErrResponseHandled
+	}
+
+	// Load one extra day on either side so hours near midnight can be matched
+	// to the nearest weather record
+	var hourly []datastore.HourlyWeather
+	for day := start.AddDate(0, 0, -1); !day.After(end.AddDate(0, 0, 1)); day = day.AddDate(0, 0, 1) {
+		if err := reqCtx.Err(); err != nil {
+			_ = c.HandleError(ctx, err, "Query timeout - please try a smaller date range", http.StatusRequestTimeout)
+			return nil, ErrResponseHandled
+		}
+		records, err := c.DS.GetHourlyWeather(day.Format(time.DateOnly))
+		if err != nil {
+			c.logWarnIfEnabled("Failed to get hourly weather for weather analytics",
+				logger.String("date", day.Format(time.DateOnly)),
+				logger.Error(err),
+			)
+			continue
+		}
+		hourly = append(hourly, records...)
+	}
+
+	return &weatherActivityInput{
+		startDate:  startDate,
+		endDate:    endDate,
+		detections: detections,
+		hourly:     hourly,
+	}, nil
+}
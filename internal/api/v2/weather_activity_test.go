@@ -0,0 +1,140 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/mocks"
+	"github.com/tphakala/birdnet-go/internal/weather"
+)
+
+// newWeatherActivityController returns a controller whose datastore has
+// detections on 2024-05-01 and rainy hourly weather for that day only. The last
+// record is at 22:00 so that no hour of the next day matches it.
+func newWeatherActivityController(t *testing.T) *Controller {
+	t.Helper()
+
+	ds := &fakeMinuteStore{
+		MockInterface: mocks.NewMockInterface(t),
+		rows: []datastore.DetectionMinuteCount{
+			{ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Date: "2024-05-01", Minute: "04:30", Count: 2},
+		},
+	}
+	ds.EXPECT().GetHourlyWeather(mock.Anything).RunAndReturn(func(date string) ([]datastore.HourlyWeather, error) {
+		if date != "2024-05-01" {
+			return nil, nil
+		}
+		records := make([]datastore.HourlyWeather, 23)
+		for hour := range records {
+			records[hour] = datastore.HourlyWeather{
+				Time:        time.Date(2024, 5, 1, hour, 0, 0, 0, time.Local),
+				Temperature: 8,
+				Pressure:    1005,
+				WindSpeed:   3,
+				WeatherIcon: string(weather.IconRain),
+			}
+		}
+		return records, nil
+	}).Maybe()
+
+	return &Controller{DS: ds}
+}
+
+func TestGetWeatherActivity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		query      string
+		wantStatus int
+	}{
+		{name: "valid range", query: "start_date=2024-05-01&end_date=2024-05-02", wantStatus: http.StatusOK},
+		{name: "range too large", query: "start_date=2024-01-01&end_date=2024-05-02", wantStatus: http.StatusBadRequest},
+		{name: "reversed dates", query: "start_date=2024-05-02&end_date=2024-05-01", wantStatus: http.StatusBadRequest},
+		{name: "invalid end date", query: "end_date=2024-5-2", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := newWeatherActivityController(t)
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/weather/activity?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			_ = c.GetWeatherActivity(e.NewContext(req, rec))
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			var resp weather.ActivityCorrelation
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			assert.Equal(t, 24, resp.WeatherHours)
+			assert.Equal(t, 0, resp.UnmatchedDetections)
+			require.NotEmpty(t, resp.Variables)
+			temperature := resp.Variables[0]
+			require.Len(t, temperature.Bins, 1)
+			assert.Equal(t, "5 to 10 °C", temperature.Bins[0].Label)
+			assert.Equal(t, 2, temperature.Bins[0].Detections)
+			require.Len(t, temperature.Species, 1)
+			assert.Equal(t, "Eurasian Blackbird", temperature.Species[0].CommonName)
+		})
+	}
+}
+
+func TestGetWeatherDailyActivity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		query    string
+		wantDays []string
+	}{
+		{name: "all days", query: "start_date=2024-05-01&end_date=2024-05-02", wantDays: []string{"2024-05-01", "2024-05-02"}},
+		{name: "rainy only", query: "start_date=2024-05-01&end_date=2024-05-02&rainy_only=true", wantDays: []string{"2024-05-01"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			c := newWeatherActivityController(t)
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/weather/daily?"+tt.query, http.NoBody)
+			rec := httptest.NewRecorder()
+
+			_ = c.GetWeatherDailyActivity(e.NewContext(req, rec))
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var resp struct {
+				Days []weather.DayActivity `json:"days"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+			dates := make([]string, 0, len(resp.Days))
+			for _, day := range resp.Days {
+				dates = append(dates, day.Date)
+			}
+			assert.Equal(t, tt.wantDays, dates)
+		})
+	}
+}
+
+func TestWeatherActivityNotSupported(t *testing.T) {
+	t.Parallel()
+
+	c := &Controller{DS: mocks.NewMockInterface(t)}
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/api/v2/analytics/weather/activity", http.NoBody)
+	rec := httptest.NewRecorder()
+
+	_ = c.GetWeatherActivity(e.NewContext(req, rec))
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+}
@@ -9,6 +9,7 @@ import (
 // Results represents the data structure for storing BirdNET inference results
 type Results struct {
 	StartTime   time.Time             // Time when the analysis started
+	AudioTime   time.Time             // Time the end of the analyzed audio was captured
 	PCMdata     []byte                // Raw PCM audio data
 	Results     []datastore.Results   // Slice of analysis results
 	ElapsedTime time.Duration         // Time taken for analysis
@@ -0,0 +1,87 @@
+// bearing.go: microphone array geometry for direction of arrival estimation
+package conf
+
+import (
+	"fmt"
+	"math"
+)
+
+// minArrayArea is the smallest area in square metres a triangle of array
+// microphones may span; smaller arrays are treated as collinear.
+const minArrayArea = 1e-4
+
+// BearingSettings describes the microphones of a multichannel device for
+// estimating the bearing of detected sounds from the time differences
+// between channels. A stereo pair has Spacing; an array of three or more
+// microphones has Positions. A pair cannot tell front from back and reports
+// bearings in front of the array.
+type BearingSettings struct {
+	Enabled     bool        `json:"enabled"`     // true to estimate the bearing of detections
+	Spacing     float64     `json:"spacing"`     // metres between the microphones of a stereo pair, channel 1 on the left
+	Positions   [][]float64 `json:"positions"`   // x (right) and y (front) in metres of the microphone of each channel
+	Orientation float64     `json:"orientation"` // compass bearing in degrees the front of the array faces
+}
+
+// MicrophonePositions returns the x and y position in metres of the
+// microphone of each channel used for bearings. A stereo pair is placed
+// on the x axis around the origin.
+func (b *BearingSettings) MicrophonePositions() [][2]float64 {
+	if len(b.Positions) == 0 {
+		if b.Spacing <= 0 {
+			return nil
+		}
+		return [][2]float64{{-b.Spacing / 2, 0}, {b.Spacing / 2, 0}}
+	}
+	positions := make([][2]float64, len(b.Positions))
+	for i, p := range b.Positions {
+		if len(p) == 2 {
+			positions[i] = [2]float64{p[0], p[1]}
+		}
+	}
+	return positions
+}
+
+// Validate checks that an enabled bearing has a usable microphone geometry
+// for a device capturing channels channels.
+func (b *BearingSettings) Validate(channels int) error {
+	if !b.Enabled {
+		return nil
+	}
+	if channels < 2 {
+		return fmt.Errorf("bearing estimation requires at least 2 channels")
+	}
+	if b.Orientation < 0 || b.Orientation >= 360 {
+		return fmt.Errorf("bearing orientation must be between 0 and 360 degrees")
+	}
+	if len(b.Positions) == 0 {
+		if b.Spacing <= 0 {
+			return fmt.Errorf("bearing estimation requires the microphone spacing or positions")
+		}
+		return nil
+	}
+
+	if len(b.Positions) < 2 || len(b.Positions) > channels {
+		return fmt.Errorf("bearing estimation requires between 2 and %d microphone positions, one per channel", channels)
+	}
+	for i, p := range b.Positions {
+		if len(p) != 2 {
+			return fmt.Errorf("microphone position %d must be [x, y] in metres", i+1)
+		}
+	}
+	if len(b.Positions) == 2 {
+		if math.Hypot(b.Positions[1][0]-b.Positions[0][0], b.Positions[1][1]-b.Positions[0][1]) == 0 {
+			return fmt.Errorf("microphone positions must differ")
+		}
+		return nil
+	}
+
+	// Three or more microphones resolve a full circle only if they are not on a line
+	for i := 2; i < len(b.Positions); i++ {
+		p0, p1, p := b.Positions[0], b.Positions[1], b.Positions[i]
+		area := math.Abs((p1[0]-p0[0])*(p[1]-p0[1])-(p[0]-p0[0])*(p1[1]-p0[1])) / 2
+		if area > minArrayArea {
+			return nil
+		}
+	}
+	return fmt.Errorf("microphone positions of an array must not be on a line, use spacing for a stereo pair")
+}
@@ -0,0 +1,72 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBearingSettings_Validate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		bearing  BearingSettings
+		channels int
+		wantErr  bool
+	}{
+		{name: "disabled", bearing: BearingSettings{}, channels: 1},
+		{name: "stereo pair", bearing: BearingSettings{Enabled: true, Spacing: 0.2}, channels: 2},
+		{name: "mono device", bearing: BearingSettings{Enabled: true, Spacing: 0.2}, channels: 1, wantErr: true},
+		{name: "no geometry", bearing: BearingSettings{Enabled: true}, channels: 2, wantErr: true},
+		{name: "orientation out of range", bearing: BearingSettings{Enabled: true, Spacing: 0.2, Orientation: 360}, channels: 2, wantErr: true},
+		{
+			name:     "square array",
+			bearing:  BearingSettings{Enabled: true, Positions: [][]float64{{0, 0.05}, {0.05, 0}, {0, -0.05}, {-0.05, 0}}, Orientation: 90},
+			channels: 4,
+		},
+		{
+			name:     "more positions than channels",
+			bearing:  BearingSettings{Enabled: true, Positions: [][]float64{{0, 0.05}, {0.05, 0}, {0, -0.05}}},
+			channels: 2,
+			wantErr:  true,
+		},
+		{
+			name:     "position without y",
+			bearing:  BearingSettings{Enabled: true, Positions: [][]float64{{0, 0}, {0.1}}},
+			channels: 2,
+			wantErr:  true,
+		},
+		{
+			name:     "collinear array",
+			bearing:  BearingSettings{Enabled: true, Positions: [][]float64{{0, 0}, {0.1, 0}, {0.2, 0}}},
+			channels: 3,
+			wantErr:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.bearing.Validate(tt.channels)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestBearingSettings_MicrophonePositions(t *testing.T) {
+	t.Parallel()
+
+	stereo := BearingSettings{Spacing: 0.2}
+	assert.Equal(t, [][2]float64{{-0.1, 0}, {0.1, 0}}, stereo.MicrophonePositions())
+
+	array := BearingSettings{Spacing: 0.2, Positions: [][]float64{{0, 0.05}, {0.05, 0}, {0, -0.05}}}
+	assert.Equal(t, [][2]float64{{0, 0.05}, {0.05, 0}, {0, -0.05}}, array.MicrophonePositions())
+
+	assert.Nil(t, (&BearingSettings{}).MicrophonePositions())
+}
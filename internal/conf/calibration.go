@@ -0,0 +1,76 @@
+// calibration.go: microphone calibration relating digital sound levels to sound pressure
+package conf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Frequency weightings of overall sound levels, as defined in IEC 61672-1
+const (
+	WeightingA = "A" // A-weighting, approximates the hearing at low levels
+	WeightingC = "C" // C-weighting, flat over most of the audible range
+	WeightingZ = "Z" // zero weighting, unweighted
+)
+
+// referencePressure is the reference sound pressure of dB SPL in pascals
+const referencePressure = 20e-6
+
+// CalibrationSettings relates the digital level of an audio source to sound
+// pressure. Levels are reported in dB SPL when Enabled and the relation is
+// known: either Offset, as measured by the calibration endpoint with a
+// reference tone, or the microphone sensitivity together with the gain and
+// full scale voltage of the input.
+type CalibrationSettings struct {
+	Enabled          bool    `yaml:"enabled" json:"enabled" mapstructure:"enabled"`                                      // true to report sound levels of the source in dB SPL
+	Offset           float64 `yaml:"offset,omitempty" json:"offset" mapstructure:"offset"`                               // dB SPL of a 0 dBFS signal, takes precedence over sensitivity
+	Sensitivity      float64 `yaml:"sensitivity,omitempty" json:"sensitivity" mapstructure:"sensitivity"`                // microphone sensitivity in mV/Pa
+	Gain             float64 `yaml:"gain,omitempty" json:"gain" mapstructure:"gain"`                                     // preamplifier gain in dB
+	FullScaleVoltage float64 `yaml:"fullscalevoltage,omitempty" json:"fullScaleVoltage" mapstructure:"fullscalevoltage"` // peak input voltage at digital full scale
+}
+
+// SPLOffset returns the value to add to a level in dBFS to get dB SPL, and
+// false if the source is not calibrated.
+func (c *CalibrationSettings) SPLOffset() (float64, bool) {
+	if !c.Enabled {
+		return 0, false
+	}
+	if c.Offset != 0 {
+		return c.Offset, true
+	}
+	if c.Sensitivity <= 0 || c.FullScaleVoltage <= 0 {
+		return 0, false
+	}
+	// Full scale voltage as pressure at the microphone, relative to 20 µPa
+	volts := 20 * math.Log10(c.FullScaleVoltage)
+	voltsPerPascal := 20*math.Log10(c.Sensitivity/1000) + c.Gain
+	return volts - voltsPerPascal - 20*math.Log10(referencePressure), true
+}
+
+// Validate checks that an enabled calibration can relate levels to sound pressure.
+func (c *CalibrationSettings) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Sensitivity < 0 || c.FullScaleVoltage < 0 {
+		return fmt.Errorf("calibration sensitivity and full scale voltage must not be negative")
+	}
+	if c.Offset == 0 && (c.Sensitivity == 0 || c.FullScaleVoltage == 0) {
+		return fmt.Errorf("calibration is enabled but has neither an offset nor a sensitivity and full scale voltage")
+	}
+	return nil
+}
+
+// NormalizeWeighting returns weighting in upper case, or an error if it is
+// not A, C or Z. An empty weighting is Z.
+func NormalizeWeighting(weighting string) (string, error) {
+	switch w := strings.ToUpper(strings.TrimSpace(weighting)); w {
+	case "":
+		return WeightingZ, nil
+	case WeightingA, WeightingC, WeightingZ:
+		return w, nil
+	default:
+		return "", fmt.Errorf("unknown frequency weighting %q, use A, C or Z", weighting)
+	}
+}
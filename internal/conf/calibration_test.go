@@ -0,0 +1,79 @@
+package conf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalibrationSettings_SPLOffset(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		calibration CalibrationSettings
+		want        float64
+		calibrated  bool
+	}{
+		{
+			name:        "disabled",
+			calibration: CalibrationSettings{Offset: 120},
+		},
+		{
+			name:        "measured offset",
+			calibration: CalibrationSettings{Enabled: true, Offset: 121.5, Sensitivity: 10, FullScaleVoltage: 1},
+			want:        121.5,
+			calibrated:  true,
+		},
+		{
+			// 1 Pa gives 10 mV, which is -40 dBFS at 1 V full scale
+			name:        "sensitivity",
+			calibration: CalibrationSettings{Enabled: true, Sensitivity: 10, FullScaleVoltage: 1},
+			want:        133.98,
+			calibrated:  true,
+		},
+		{
+			name:        "sensitivity with gain",
+			calibration: CalibrationSettings{Enabled: true, Sensitivity: 10, Gain: 20, FullScaleVoltage: 1},
+			want:        113.98,
+			calibrated:  true,
+		},
+		{
+			name:        "sensitivity without full scale voltage",
+			calibration: CalibrationSettings{Enabled: true, Sensitivity: 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			offset, ok := tt.calibration.SPLOffset()
+			assert.Equal(t, tt.calibrated, ok)
+			assert.InDelta(t, tt.want, offset, 0.01)
+		})
+	}
+}
+
+func TestCalibrationSettings_Validate(t *testing.T) {
+	t.Parallel()
+
+	require.NoError(t, (&CalibrationSettings{}).Validate())
+	require.NoError(t, (&CalibrationSettings{Enabled: true, Offset: 120}).Validate())
+	require.NoError(t, (&CalibrationSettings{Enabled: true, Sensitivity: 10, FullScaleVoltage: 1.2}).Validate())
+	require.Error(t, (&CalibrationSettings{Enabled: true}).Validate())
+	require.Error(t, (&CalibrationSettings{Enabled: true, Sensitivity: 10}).Validate())
+	require.Error(t, (&CalibrationSettings{Enabled: true, Offset: 120, Sensitivity: -10}).Validate())
+}
+
+func TestNormalizeWeighting(t *testing.T) {
+	t.Parallel()
+
+	for input, want := range map[string]string{"": WeightingZ, "a": WeightingA, " C ": WeightingC, "Z": WeightingZ} {
+		got, err := NormalizeWeighting(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, want, got, input)
+	}
+	_, err := NormalizeWeighting("B")
+	require.Error(t, err)
+}
@@ -78,30 +78,83 @@ type RetentionSettings struct {
 	CheckInterval    int    `json:"checkInterval"`    // cleanup check interval in minutes (default: 15)
 }
 
+// ArchiveSettings contains settings for continuous recording of audio sources
+// into hourly files. The archive is independent of detection clips and has its
+// own rolling retention.
+type ArchiveSettings struct {
+	Enabled  bool     `json:"enabled" mapstructure:"enabled"`   // true to record audio sources continuously
+	Sources  []string `json:"sources" mapstructure:"sources"`   // display names, stream URLs or device names of the sources to record, empty to record all sources
+	Path     string   `json:"path" mapstructure:"path"`         // path to archive directory
+	Type     string   `json:"type" mapstructure:"type"`         // archive file type, flac or opus
+	Bitrate  string   `json:"bitrate" mapstructure:"bitrate"`   // bitrate for opus archives
+	MaxAge   string   `json:"maxAge" mapstructure:"maxage"`     // maximum age of archive files to keep, e.g. "7d"
+	MaxUsage string   `json:"maxUsage" mapstructure:"maxusage"` // disk usage percentage at which the oldest files are removed, empty to disable
+}
+
 // AudioSettings contains settings for audio processing and export.
 // SoundLevelSettings contains settings for sound level monitoring
 type SoundLevelSettings struct {
-	Enabled              bool `yaml:"enabled" mapstructure:"enabled" json:"enabled"`                                            // true to enable sound level monitoring
-	Interval             int  `yaml:"interval" mapstructure:"interval" json:"interval"`                                         // measurement interval in seconds (default: 10)
-	Debug                bool `yaml:"debug" mapstructure:"debug" json:"debug"`                                                  // true to enable debug logging for sound level monitoring
-	DebugRealtimeLogging bool `yaml:"debug_realtime_logging" mapstructure:"debug_realtime_logging" json:"debugRealtimeLogging"` // true to log debug messages for every realtime update, false to log only at configured interval
+	Enabled              bool                      `yaml:"enabled" mapstructure:"enabled" json:"enabled"`                                            // true to enable sound level monitoring
+	Interval             int                       `yaml:"interval" mapstructure:"interval" json:"interval"`                                         // measurement interval in seconds (default: 10)
+	Debug                bool                      `yaml:"debug" mapstructure:"debug" json:"debug"`                                                  // true to enable debug logging for sound level monitoring
+	DebugRealtimeLogging bool                      `yaml:"debug_realtime_logging" mapstructure:"debug_realtime_logging" json:"debugRealtimeLogging"` // true to log debug messages for every realtime update, false to log only at configured interval
+	AcousticIndices      bool                      `yaml:"acousticindices" mapstructure:"acousticindices" json:"acousticIndices"`                    // true to compute acoustic indices (ACI, ADI, NDSI, BI, H) every minute
+	Weighting            string                    `yaml:"weighting" mapstructure:"weighting" json:"weighting"`                                      // frequency weighting of the overall level: A, C or Z
+	History              SoundLevelHistorySettings `yaml:"history" mapstructure:"history" json:"history"`                                            // sound level history storage settings
+}
+
+// SoundLevelHistorySettings contains settings for storing sound level measurements.
+// History requires the v2 database; measurements are kept at full resolution
+// for RawRetentionHours, then downsampled to DownsampleMinutes until RetentionDays.
+type SoundLevelHistorySettings struct {
+	Enabled           bool `yaml:"enabled" mapstructure:"enabled" json:"enabled"`                               // true to store sound level measurements
+	RawRetentionHours int  `yaml:"rawretentionhours" mapstructure:"rawretentionhours" json:"rawRetentionHours"` // hours to keep measurements at full resolution
+	DownsampleMinutes int  `yaml:"downsampleminutes" mapstructure:"downsampleminutes" json:"downsampleMinutes"` // resolution of downsampled measurements in minutes
+	RetentionDays     int  `yaml:"retentiondays" mapstructure:"retentiondays" json:"retentionDays"`             // days to keep measurements, 0 keeps them forever
+}
+
+// DiagnosticsSettings contains settings for audio quality diagnostics. Every
+// source is checked for clipping, DC offset, dead channels, mains hum, sample
+// rate mismatch and abnormal spectral tilt once per interval.
+type DiagnosticsSettings struct {
+	Enabled  bool `yaml:"enabled" mapstructure:"enabled" json:"enabled"`    // true to check the quality of captured audio
+	Interval int  `yaml:"interval" mapstructure:"interval" json:"interval"` // analysis interval in seconds (default: 60)
 }
 
 type AudioSettings struct {
-	Source          string             `yaml:"source" mapstructure:"source" json:"source"`             // audio source to use for analysis
-	FfmpegPath      string             `yaml:"ffmpegpath" mapstructure:"ffmpegpath" json:"ffmpegPath"` // path to ffmpeg, runtime value
-	FfmpegVersion   string             `yaml:"-" json:"ffmpegVersion,omitempty"`                       // ffmpeg version string, runtime value
-	FfmpegMajor     int                `yaml:"-" json:"ffmpegMajor,omitempty"`                         // ffmpeg major version number, runtime value
-	FfmpegMinor     int                `yaml:"-" json:"ffmpegMinor,omitempty"`                         // ffmpeg minor version number, runtime value
-	SoxPath         string             `yaml:"soxpath" mapstructure:"soxpath" json:"soxPath"`          // path to sox, runtime value
-	SoxAudioTypes   []string           `yaml:"-" json:"-"`                                             // supported audio types of sox, runtime value
-	StreamTransport string             `json:"streamTransport"`                                        // preferred transport for audio streaming: "auto", "sse", or "ws"
-	Export          ExportSettings     `json:"export"`                                                 // export settings
-	SoundLevel      SoundLevelSettings `json:"soundLevel"`                                             // sound level monitoring settings
+	Source          string                `yaml:"source" mapstructure:"source" json:"source"`             // audio source to use for analysis
+	FfmpegPath      string                `yaml:"ffmpegpath" mapstructure:"ffmpegpath" json:"ffmpegPath"` // path to ffmpeg, runtime value
+	FfmpegVersion   string                `yaml:"-" json:"ffmpegVersion,omitempty"`                       // ffmpeg version string, runtime value
+	FfmpegMajor     int                   `yaml:"-" json:"ffmpegMajor,omitempty"`                         // ffmpeg major version number, runtime value
+	FfmpegMinor     int                   `yaml:"-" json:"ffmpegMinor,omitempty"`                         // ffmpeg minor version number, runtime value
+	SoxPath         string                `yaml:"soxpath" mapstructure:"soxpath" json:"soxPath"`          // path to sox, runtime value
+	SoxAudioTypes   []string              `yaml:"-" json:"-"`                                             // supported audio types of sox, runtime value
+	StreamTransport string                `json:"streamTransport"`                                        // preferred transport for audio streaming: "auto", "sse", or "ws"
+	Export          ExportSettings        `json:"export"`                                                 // export settings
+	Archive         ArchiveSettings       `json:"archive"`                                                // continuous recording settings
+	SoundLevel      SoundLevelSettings    `json:"soundLevel"`                                             // sound level monitoring settings
+	Diagnostics     DiagnosticsSettings   `json:"diagnostics"`                                            // audio quality diagnostics settings
+	Schedule        ScheduleSettings      `json:"schedule"`                                               // recording schedule of the audio device
+	Calibration     CalibrationSettings   `json:"calibration"`                                            // sound level calibration of the audio device
+	Devices         []AudioDeviceSettings `json:"devices"`                                                // additional local capture devices
 
 	Equalizer EqualizerSettings `json:"equalizer"` // equalizer settings
 }
 
+// AudioDeviceSettings configures an additional local capture device. A
+// multichannel device is either downmixed to a single source or split so
+// that each channel is analyzed and stored as a separate source.
+type AudioDeviceSettings struct {
+	Device       string              `json:"device"`       // device ID or name, matched like Source
+	Name         string              `json:"name"`         // display name, defaults to Device
+	Channels     int                 `json:"channels"`     // channels to capture, 0 or 1 for mono
+	Split        bool                `json:"split"`        // true to capture each channel as a separate source
+	ChannelNames []string            `json:"channelNames"` // display names of split channels
+	Schedule     ScheduleSettings    `json:"schedule"`     // recording schedule of the device
+	Calibration  CalibrationSettings `json:"calibration"`  // sound level calibration, shared by split channels
+	Bearing      BearingSettings     `json:"bearing"`      // direction of arrival estimation from the channels
+}
+
 // NeedsFfprobeWorkaround returns true if the current FFmpeg version requires
 // using ffprobe to get audio file length for spectrograms (FFmpeg 5.x bug).
 // FFmpeg 7.x and later have this issue fixed.
@@ -169,12 +222,52 @@ const (
 //   - "prerender": Background worker generates during audio clip save (continuous CPU usage)
 //   - "user-requested": Only generate when user clicks button in UI (zero automatic overhead)
 type SpectrogramPreRender struct {
-	Mode         string `json:"mode"         mapstructure:"mode"`         // Generation mode: "auto" (default), "prerender", "user-requested"
-	Enabled      bool   `json:"enabled"      mapstructure:"enabled"`      // DEPRECATED: Use Mode instead. Kept for backward compatibility (true = "prerender", false = "auto")
-	Size         string `json:"size"         mapstructure:"size"`         // Default size for all modes (see recommendations below)
-	Raw          bool   `json:"raw"          mapstructure:"raw"`          // Generate raw spectrogram without axes/legend (default: true)
-	Style        string `json:"style"        mapstructure:"style"`        // Visual style preset: "default", "scientific_dark", "high_contrast_dark", "scientific"
-	DynamicRange string `json:"dynamicRange" mapstructure:"dynamicRange"` // Dynamic range in dB: "80" (high contrast), "100" (standard), "120" (extended)
+	Mode         string                    `json:"mode"         mapstructure:"mode"`         // Generation mode: "auto" (default), "prerender", "user-requested"
+	Enabled      bool                      `json:"enabled"      mapstructure:"enabled"`      // DEPRECATED: Use Mode instead. Kept for backward compatibility (true = "prerender", false = "auto")
+	Size         string                    `json:"size"         mapstructure:"size"`         // Default size for all modes (see recommendations below)
+	Raw          bool                      `json:"raw"          mapstructure:"raw"`          // Generate raw spectrogram without axes/legend (default: true)
+	Style        string                    `json:"style"        mapstructure:"style"`        // Visual style preset: "default", "scientific_dark", "high_contrast_dark", "scientific"
+	DynamicRange string                    `json:"dynamicRange" mapstructure:"dynamicRange"` // Dynamic range in dB: "80" (high contrast), "100" (standard), "120" (extended)
+	Renderer     string                    `json:"renderer"     mapstructure:"renderer"`     // Renderer: "sox" (default, sox with FFmpeg fallback) or "native" (pure Go)
+	Native       NativeSpectrogramSettings `json:"native"       mapstructure:"native"`       // Native renderer settings
+}
+
+// Spectrogram renderer constants
+const (
+	SpectrogramRendererSox    = "sox"
+	SpectrogramRendererNative = "native"
+)
+
+// Native spectrogram window function constants
+const (
+	SpectrogramWindowHann     = "hann"
+	SpectrogramWindowHamming  = "hamming"
+	SpectrogramWindowBlackman = "blackman"
+	SpectrogramWindowDolph    = "dolph"
+)
+
+// Native spectrogram color map constants. An empty color map follows the style preset.
+const (
+	SpectrogramColorMapSox          = "sox"
+	SpectrogramColorMapHighContrast = "high_contrast"
+	SpectrogramColorMapGrayscale    = "grayscale"
+	SpectrogramColorMapInverted     = "grayscale_inverted"
+	SpectrogramColorMapViridis      = "viridis"
+)
+
+// NativeSpectrogramSettings contains settings for the pure Go spectrogram renderer.
+// The output format follows the output file extension: PNG, or WebP for .webp files.
+type NativeSpectrogramSettings struct {
+	FFTSize  int    `json:"fftSize"  mapstructure:"fftSize"`  // FFT size in samples, a power of two from 256 to 8192 (default 1024)
+	Window   string `json:"window"   mapstructure:"window"`   // Window function: "hann" (default), "hamming", "blackman", "dolph"; empty follows the style
+	MinFreq  int    `json:"minFreq"  mapstructure:"minFreq"`  // Lowest frequency shown in Hz (default 0)
+	MaxFreq  int    `json:"maxFreq"  mapstructure:"maxFreq"`  // Highest frequency shown in Hz (default 12000, at most 24000)
+	ColorMap string `json:"colorMap" mapstructure:"colorMap"` // Color map: "sox", "high_contrast", "grayscale", "grayscale_inverted", "viridis"; empty follows the style
+}
+
+// UseNativeRenderer returns true if spectrograms are rendered in Go instead of with sox.
+func (s *SpectrogramPreRender) UseNativeRenderer() bool {
+	return s.Renderer == SpectrogramRendererNative
 }
 
 // GetMode returns the effective spectrogram generation mode, handling backward compatibility.
@@ -429,14 +522,24 @@ const (
 	StreamTypeHLS  = "hls"  // HLS - .m3u8 playlists
 	StreamTypeRTMP = "rtmp" // RTMP/RTMPS - OBS, push streams
 	StreamTypeUDP  = "udp"  // UDP/RTP - Low-latency LAN
+	StreamTypeSRT  = "srt"  // SRT - Remote sites over lossy links
 )
 
 // StreamConfig represents a single audio stream source
 type StreamConfig struct {
-	Name      string `yaml:"name" json:"name" mapstructure:"name"`                // Required: descriptive name like "Front Yard"
-	URL       string `yaml:"url" json:"url" mapstructure:"url"`                   // Required: stream URL
-	Type      string `yaml:"type" json:"type" mapstructure:"type"`                // Stream type: rtsp, http, hls, rtmp, udp
-	Transport string `yaml:"transport" json:"transport" mapstructure:"transport"` // Transport: tcp or udp (for RTSP/RTMP)
+	Name        string              `yaml:"name" json:"name" mapstructure:"name"`                                // Required: descriptive name like "Front Yard"
+	URL         string              `yaml:"url" json:"url" mapstructure:"url"`                                   // Required: stream URL
+	Type        string              `yaml:"type" json:"type" mapstructure:"type"`                                // Stream type: rtsp, http, hls, rtmp, udp, srt
+	Transport   string              `yaml:"transport" json:"transport" mapstructure:"transport"`                 // Transport: tcp or udp (for RTSP/RTMP)
+	SRT         SRTSettings         `yaml:"srt,omitempty" json:"srt" mapstructure:"srt"`                         // SRT options, used by srt streams
+	Schedule    ScheduleSettings    `yaml:"schedule,omitempty" json:"schedule" mapstructure:"schedule"`          // Optional recording schedule
+	Calibration CalibrationSettings `yaml:"calibration,omitempty" json:"calibration" mapstructure:"calibration"` // Optional sound level calibration
+}
+
+// SRTSettings contains the connection options of an SRT stream.
+type SRTSettings struct {
+	Latency    int    `yaml:"latency,omitempty" json:"latency" mapstructure:"latency"`          // receiver latency in milliseconds, 0 uses the SRT default of 120 ms
+	Passphrase string `yaml:"passphrase,omitempty" json:"passphrase" mapstructure:"passphrase"` // encryption passphrase, empty for unencrypted streams
 }
 
 // RTSPSettings contains settings for audio streaming (supports multiple protocols).
@@ -447,6 +550,7 @@ type RTSPSettings struct {
 	Transport        string             `yaml:"transport,omitempty" json:"transport,omitempty" mapstructure:"transport"`  // Legacy: global default, migrated on load
 	Health           RTSPHealthSettings `yaml:"health" json:"health" mapstructure:"health"`                               // Health monitoring settings
 	FFmpegParameters []string           `yaml:"ffmpegParameters" json:"ffmpegParameters" mapstructure:"ffmpegParameters"` // Custom FFmpeg parameters
+	NativeClient     bool               `yaml:"nativeClient" json:"nativeClient" mapstructure:"nativeClient"`             // true to ingest G.711, L16 and Opus RTSP audio in-process, AAC and other codecs use FFmpeg
 }
 
 // CRITICAL: Legacy fields (URLs, Transport) MUST include json tags to accept
@@ -562,6 +666,8 @@ type RealtimeSettings struct {
 	Species          SpeciesSettings          `json:"species"`          // Custom thresholds and actions for species
 	Weather          WeatherSettings          `json:"weather"`          // Weather provider related settings
 	SpeciesTracking  SpeciesTrackingSettings  `json:"speciesTracking"`  // New species tracking settings
+	SourceGroups     []SourceGroupSettings    `json:"sourceGroups"`     // Sources whose detections of the same bird are merged
+	FailoverGroups   []FailoverGroupSettings  `json:"failoverGroups"`   // Sources of which only the first delivering audio is analyzed
 }
 
 // SpeciesAction represents a single action configuration
@@ -1251,6 +1357,8 @@ type Settings struct {
 	Notification NotificationConfig `json:"notification"` // Configuration for push notifications
 
 	Alerting AlertSettings `json:"alerting"` // Alerting rules engine settings
+
+	Hub HubSettings `json:"hub"` // Multi-station aggregation settings
 }
 
 // AlertSettings configures the alerting rules engine.
@@ -1258,6 +1366,34 @@ type AlertSettings struct {
 	HistoryRetentionDays int `json:"historyRetentionDays" yaml:"history_retention_days" mapstructure:"history_retention_days"` // Days to retain alert history (0 = unlimited)
 }
 
+// HubSettings configures multi-station aggregation. An instance acting as a
+// hub accepts detections pushed by remote nodes listed in Nodes; an instance
+// with Upstream enabled pushes its own detections to a hub. Both roles can be
+// active at once to build a chain of hubs.
+type HubSettings struct {
+	Enabled       bool                `json:"enabled"`                                                               // true to accept detections from remote nodes
+	AcceptClips   bool                `json:"acceptClips" yaml:"accept_clips" mapstructure:"accept_clips"`           // true to accept audio clip uploads from remote nodes
+	MaxClipSizeMB int                 `json:"maxClipSizeMB" yaml:"max_clip_size_mb" mapstructure:"max_clip_size_mb"` // maximum accepted clip size in megabytes
+	Nodes         []HubNode           `json:"nodes"`                                                                 // remote nodes allowed to push detections
+	Upstream      HubUpstreamSettings `json:"upstream"`                                                              // push settings when this instance reports to a hub
+}
+
+// HubNode identifies a remote node allowed to push detections to the hub.
+// The name is stored as the node name of the node's audio sources.
+type HubNode struct {
+	Name  string `json:"name"`  // node name, matches the remote node's main.name
+	Token string `json:"token"` // bearer token the node authenticates with
+}
+
+// HubUpstreamSettings configures pushing local detections to a hub.
+type HubUpstreamSettings struct {
+	Enabled   bool          `json:"enabled"`                                               // true to push detections to the hub
+	URL       string        `json:"url"`                                                   // base URL of the hub, e.g. https://hub.example.com
+	Token     string        `json:"token"`                                                 // bearer token issued by the hub for this node
+	SendClips bool          `json:"sendClips" yaml:"send_clips" mapstructure:"send_clips"` // true to upload audio clips along with detections
+	Timeout   time.Duration `json:"timeout"`                                               // timeout for a single push request
+}
+
 // settingsInstance is the current settings instance
 var (
 	settingsInstance *Settings
@@ -1579,6 +1715,8 @@ func inferStreamType(url string) string {
 		return StreamTypeRTMP
 	case strings.HasPrefix(urlLower, "udp://"), strings.HasPrefix(urlLower, "rtp://"):
 		return StreamTypeUDP
+	case strings.HasPrefix(urlLower, "srt://"):
+		return StreamTypeSRT
 	case strings.HasPrefix(urlLower, "http://"), strings.HasPrefix(urlLower, "https://"):
 		// Check for HLS (.m3u8) vs generic HTTP
 		if strings.Contains(urlLower, ".m3u8") {
@@ -89,6 +89,16 @@ func setDefaultConfig() {
 	// Sound level monitoring configuration
 	viper.SetDefault("realtime.audio.soundlevel.enabled", false)
 	viper.SetDefault("realtime.audio.soundlevel.interval", 10)
+	viper.SetDefault("realtime.audio.soundlevel.acousticindices", true)
+	viper.SetDefault("realtime.audio.soundlevel.weighting", "A")
+	viper.SetDefault("realtime.audio.soundlevel.history.enabled", true)
+	viper.SetDefault("realtime.audio.soundlevel.history.rawretentionhours", 48)
+	viper.SetDefault("realtime.audio.soundlevel.history.downsampleminutes", 5)
+	viper.SetDefault("realtime.audio.soundlevel.history.retentiondays", 90)
+
+	// Audio quality diagnostics configuration
+	viper.SetDefault("realtime.audio.diagnostics.enabled", true)
+	viper.SetDefault("realtime.audio.diagnostics.interval", 60)
 
 	// Audio capture configuration
 	viper.SetDefault("realtime.audio.export.debug", false)
@@ -140,6 +150,12 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.dashboard.spectrogram.raw", true)                                     // Raw spectrogram (no axes/legend)
 	viper.SetDefault("realtime.dashboard.spectrogram.style", "default")                              // Visual style preset
 	viper.SetDefault("realtime.dashboard.spectrogram.dynamicrange", SpectrogramDynamicRangeStandard) // Dynamic range in dB (100 = standard)
+	viper.SetDefault("realtime.dashboard.spectrogram.renderer", SpectrogramRendererSox)              // sox with FFmpeg fallback
+	viper.SetDefault("realtime.dashboard.spectrogram.native.fftsize", 1024)                          // Native renderer FFT size
+	viper.SetDefault("realtime.dashboard.spectrogram.native.window", "")                             // Empty follows the style preset
+	viper.SetDefault("realtime.dashboard.spectrogram.native.minfreq", 0)                             // Lowest frequency in Hz
+	viper.SetDefault("realtime.dashboard.spectrogram.native.maxfreq", 12000)                         // Matches sox resampling to 24 kHz
+	viper.SetDefault("realtime.dashboard.spectrogram.native.colormap", "")                           // Empty follows the style preset
 
 	// Retention policy configuration
 	viper.SetDefault("realtime.audio.export.retention.enabled", true)
@@ -151,6 +167,15 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.audio.export.retention.keepspectrograms", true)
 	viper.SetDefault("realtime.audio.export.retention.checkinterval", DefaultCleanupCheckInterval)
 
+	// Continuous recording configuration
+	viper.SetDefault("realtime.audio.archive.enabled", false)
+	viper.SetDefault("realtime.audio.archive.sources", []string{})
+	viper.SetDefault("realtime.audio.archive.path", "archive/")
+	viper.SetDefault("realtime.audio.archive.type", "flac")
+	viper.SetDefault("realtime.audio.archive.bitrate", "64k")
+	viper.SetDefault("realtime.audio.archive.maxage", "7d")
+	viper.SetDefault("realtime.audio.archive.maxusage", "90%")
+
 	// Dynamic threshold configuration
 	viper.SetDefault("realtime.dynamicthreshold.enabled", true)
 	viper.SetDefault("realtime.dynamicthreshold.debug", false)
@@ -224,6 +249,7 @@ func setDefaultConfig() {
 	viper.SetDefault("realtime.rtsp.health.healthydatathreshold", 60)
 	viper.SetDefault("realtime.rtsp.health.monitoringinterval", 30)
 	viper.SetDefault("realtime.rtsp.ffmpegparameters", []string{})
+	viper.SetDefault("realtime.rtsp.nativeclient", false)
 
 	// MQTT configuration
 	viper.SetDefault("realtime.mqtt.enabled", false)
@@ -402,6 +428,17 @@ func setDefaultConfig() {
 
 	// Alerting rules engine
 	viper.SetDefault("alerting.history_retention_days", 30)
+
+	// Multi-station hub
+	viper.SetDefault("hub.enabled", false)
+	viper.SetDefault("hub.accept_clips", false)
+	viper.SetDefault("hub.max_clip_size_mb", 8)
+	viper.SetDefault("hub.nodes", []map[string]any{})
+	viper.SetDefault("hub.upstream.enabled", false)
+	viper.SetDefault("hub.upstream.url", "")
+	viper.SetDefault("hub.upstream.token", "")
+	viper.SetDefault("hub.upstream.send_clips", false)
+	viper.SetDefault("hub.upstream.timeout", "30s")
 }
 
 // setModuleLogDefaults sets default values for a module log configuration
@@ -0,0 +1,88 @@
+// failover.go: audio source failover groups
+package conf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Failover group timing limits in seconds
+const (
+	DefaultFailoverTimeout  = 15
+	DefaultFailoverRecovery = 60
+	MaxFailoverTimeout      = 300
+	MaxFailoverRecovery     = 3600
+)
+
+// FailoverGroupSettings lists audio sources in priority order, of which only
+// one is analyzed: the first source delivering audio. When it stops for
+// Timeout seconds, analysis switches to the next source that delivers audio,
+// and switches back once a higher priority source has delivered audio for
+// Recovery seconds.
+type FailoverGroupSettings struct {
+	Name     string   `json:"name"`     // name of the group
+	Sources  []string `json:"sources"`  // display names, stream URLs or device names, primary first
+	Timeout  int      `json:"timeout"`  // seconds without audio before a source has failed, 0 for the default
+	Recovery int      `json:"recovery"` // seconds a recovered source must deliver audio before switching back, 0 for the default
+}
+
+// TimeoutDuration returns how long a source may deliver no audio before the
+// group fails over.
+func (g *FailoverGroupSettings) TimeoutDuration() time.Duration {
+	if g.Timeout <= 0 {
+		return DefaultFailoverTimeout * time.Second
+	}
+	return time.Duration(g.Timeout) * time.Second
+}
+
+// RecoveryDuration returns how long a higher priority source must deliver
+// audio before the group switches back to it.
+func (g *FailoverGroupSettings) RecoveryDuration() time.Duration {
+	if g.Recovery <= 0 {
+		return DefaultFailoverRecovery * time.Second
+	}
+	return time.Duration(g.Recovery) * time.Second
+}
+
+// validateFailoverGroups checks that groups have unique names and at least
+// two sources, and that no source belongs to more than one group.
+func validateFailoverGroups(groups []FailoverGroupSettings) error {
+	names := make(map[string]bool, len(groups))
+	grouped := make(map[string]string)
+	for i := range groups {
+		group := &groups[i]
+		name := strings.TrimSpace(group.Name)
+		if name == "" {
+			return fmt.Errorf("failover group %d: name is required", i+1)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("failover group '%s' is configured more than once", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if group.Timeout < 0 || group.Timeout > MaxFailoverTimeout {
+			return fmt.Errorf("failover group '%s': timeout must be between 0 and %d seconds", name, MaxFailoverTimeout)
+		}
+		if group.Recovery < 0 || group.Recovery > MaxFailoverRecovery {
+			return fmt.Errorf("failover group '%s': recovery must be between 0 and %d seconds", name, MaxFailoverRecovery)
+		}
+
+		sources := 0
+		for _, source := range group.Sources {
+			key := strings.ToLower(strings.TrimSpace(source))
+			if key == "" {
+				continue
+			}
+			if other, exists := grouped[key]; exists {
+				return fmt.Errorf("source '%s' is in failover groups '%s' and '%s'", source, other, name)
+			}
+			grouped[key] = name
+			sources++
+		}
+		if sources < 2 {
+			return fmt.Errorf("failover group '%s' must contain at least 2 sources", name)
+		}
+	}
+	return nil
+}
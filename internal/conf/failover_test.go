@@ -0,0 +1,64 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateFailoverGroups(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		groups  []FailoverGroupSettings
+		wantErr bool
+	}{
+		{name: "no groups"},
+		{name: "valid group", groups: []FailoverGroupSettings{{Name: "Main", Sources: []string{"USB Mic", "Camera"}, Timeout: 15, Recovery: 60}}},
+		{name: "missing name", groups: []FailoverGroupSettings{{Sources: []string{"USB Mic", "Camera"}}}, wantErr: true},
+		{name: "single source", groups: []FailoverGroupSettings{{Name: "Main", Sources: []string{"USB Mic", ""}}}, wantErr: true},
+		{name: "negative timeout", groups: []FailoverGroupSettings{{Name: "Main", Sources: []string{"USB Mic", "Camera"}, Timeout: -1}}, wantErr: true},
+		{name: "timeout too long", groups: []FailoverGroupSettings{{Name: "Main", Sources: []string{"USB Mic", "Camera"}, Timeout: 301}}, wantErr: true},
+		{name: "recovery too long", groups: []FailoverGroupSettings{{Name: "Main", Sources: []string{"USB Mic", "Camera"}, Recovery: 3601}}, wantErr: true},
+		{
+			name: "duplicate name",
+			groups: []FailoverGroupSettings{
+				{Name: "Main", Sources: []string{"USB Mic", "Camera"}},
+				{Name: "MAIN", Sources: []string{"Pond", "Shed"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "source in two groups",
+			groups: []FailoverGroupSettings{
+				{Name: "Main", Sources: []string{"USB Mic", "Camera"}},
+				{Name: "Backup", Sources: []string{"camera", "Shed"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateFailoverGroups(tt.groups)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestFailoverGroupSettings_Durations(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultFailoverTimeout*time.Second, (&FailoverGroupSettings{}).TimeoutDuration())
+	assert.Equal(t, DefaultFailoverRecovery*time.Second, (&FailoverGroupSettings{}).RecoveryDuration())
+	assert.Equal(t, 30*time.Second, (&FailoverGroupSettings{Timeout: 30}).TimeoutDuration())
+	assert.Equal(t, 120*time.Second, (&FailoverGroupSettings{Recovery: 120}).RecoveryDuration())
+}
@@ -0,0 +1,100 @@
+// schedule.go: recording schedules limiting when an audio source is captured and analyzed
+package conf
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Sun events a schedule time can be relative to
+const (
+	ScheduleEventDawn    = "dawn"    // civil dawn
+	ScheduleEventSunrise = "sunrise" // sunrise
+	ScheduleEventSunset  = "sunset"  // sunset
+	ScheduleEventDusk    = "dusk"    // civil dusk
+)
+
+// maxScheduleOffset limits sun relative offsets to half a day
+const maxScheduleOffset = 12 * time.Hour
+
+// ScheduleSettings limits capture and analysis of a source to time windows.
+// Outside the windows the source is idle, which for streams means their
+// FFmpeg process is stopped.
+type ScheduleSettings struct {
+	Enabled bool             `yaml:"enabled" json:"enabled" mapstructure:"enabled"` // true to capture only inside windows
+	Windows []ScheduleWindow `yaml:"windows" json:"windows" mapstructure:"windows"` // windows during which the source is active
+}
+
+// ScheduleWindow is a daily time window. Start and End are either a clock
+// time such as "05:30", or a sun event with an optional offset in minutes
+// such as "dawn-60", "sunrise", or "sunset+30". A window ending before it
+// starts continues over midnight.
+type ScheduleWindow struct {
+	Start string `yaml:"start" json:"start" mapstructure:"start"` // window start, e.g. "dawn-60"
+	End   string `yaml:"end" json:"end" mapstructure:"end"`       // window end, e.g. "dawn+180"
+}
+
+// ScheduleTime is a parsed start or end of a schedule window.
+type ScheduleTime struct {
+	Event  string        // sun event, empty for clock times
+	Clock  time.Duration // time of day for clock times
+	Offset time.Duration // offset from the sun event
+}
+
+// ParseScheduleTime parses a schedule window start or end.
+func ParseScheduleTime(spec string) (ScheduleTime, error) {
+	spec = strings.ToLower(strings.TrimSpace(spec))
+	if spec == "" {
+		return ScheduleTime{}, fmt.Errorf("schedule time is empty")
+	}
+
+	for _, event := range []string{ScheduleEventDawn, ScheduleEventSunrise, ScheduleEventSunset, ScheduleEventDusk} {
+		rest, found := strings.CutPrefix(spec, event)
+		if !found {
+			continue
+		}
+		rest = strings.ReplaceAll(rest, " ", "")
+		if rest == "" {
+			return ScheduleTime{Event: event}, nil
+		}
+		if rest[0] != '+' && rest[0] != '-' {
+			return ScheduleTime{}, fmt.Errorf("invalid schedule time '%s': expected +N or -N minutes after %s", spec, event)
+		}
+		minutes, err := strconv.Atoi(strings.TrimSuffix(rest, "m"))
+		if err != nil {
+			return ScheduleTime{}, fmt.Errorf("invalid schedule time '%s': offset must be whole minutes", spec)
+		}
+		offset := time.Duration(minutes) * time.Minute
+		if offset > maxScheduleOffset || offset < -maxScheduleOffset {
+			return ScheduleTime{}, fmt.Errorf("invalid schedule time '%s': offset exceeds %d minutes", spec, int(maxScheduleOffset.Minutes()))
+		}
+		return ScheduleTime{Event: event, Offset: offset}, nil
+	}
+
+	clock, err := time.Parse("15:04", spec)
+	if err != nil {
+		return ScheduleTime{}, fmt.Errorf("invalid schedule time '%s': use HH:MM or dawn, sunrise, sunset or dusk with an optional offset", spec)
+	}
+	return ScheduleTime{Clock: time.Duration(clock.Hour())*time.Hour + time.Duration(clock.Minute())*time.Minute}, nil
+}
+
+// Validate checks that an enabled schedule has valid windows.
+func (s *ScheduleSettings) Validate() error {
+	if !s.Enabled {
+		return nil
+	}
+	if len(s.Windows) == 0 {
+		return fmt.Errorf("schedule is enabled but has no windows")
+	}
+	for i, window := range s.Windows {
+		if _, err := ParseScheduleTime(window.Start); err != nil {
+			return fmt.Errorf("schedule window %d start: %w", i+1, err)
+		}
+		if _, err := ParseScheduleTime(window.End); err != nil {
+			return fmt.Errorf("schedule window %d end: %w", i+1, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseScheduleTime(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		spec    string
+		want    ScheduleTime
+		wantErr bool
+	}{
+		{spec: "05:30", want: ScheduleTime{Clock: 5*time.Hour + 30*time.Minute}},
+		{spec: "00:00", want: ScheduleTime{}},
+		{spec: "sunrise", want: ScheduleTime{Event: ScheduleEventSunrise}},
+		{spec: "dawn-60", want: ScheduleTime{Event: ScheduleEventDawn, Offset: -60 * time.Minute}},
+		{spec: " Dawn + 180 ", want: ScheduleTime{Event: ScheduleEventDawn, Offset: 180 * time.Minute}},
+		{spec: "sunset+30m", want: ScheduleTime{Event: ScheduleEventSunset, Offset: 30 * time.Minute}},
+		{spec: "dusk", want: ScheduleTime{Event: ScheduleEventDusk}},
+		{spec: "", wantErr: true},
+		{spec: "25:00", wantErr: true},
+		{spec: "noon", wantErr: true},
+		{spec: "dawn60", wantErr: true},
+		{spec: "dawn+1.5", wantErr: true},
+		{spec: "sunset+721", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseScheduleTime(tt.spec)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestScheduleSettings_Validate(t *testing.T) {
+	t.Parallel()
+
+	disabled := ScheduleSettings{Windows: []ScheduleWindow{{Start: "bogus"}}}
+	require.NoError(t, disabled.Validate(), "disabled schedules are not validated")
+
+	empty := ScheduleSettings{Enabled: true}
+	require.Error(t, empty.Validate())
+
+	valid := ScheduleSettings{Enabled: true, Windows: []ScheduleWindow{
+		{Start: "dawn-60", End: "dawn+180"},
+		{Start: "22:00", End: "02:00"},
+	}}
+	require.NoError(t, valid.Validate())
+
+	invalid := ScheduleSettings{Enabled: true, Windows: []ScheduleWindow{{Start: "dawn", End: "later"}}}
+	err := invalid.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "window 1 end")
+}
+
+func TestStreamConfig_ValidateSchedule(t *testing.T) {
+	t.Parallel()
+	stream := StreamConfig{
+		Name:     "Front Yard",
+		URL:      "rtsp://192.168.1.10/stream",
+		Type:     StreamTypeRTSP,
+		Schedule: ScheduleSettings{Enabled: true},
+	}
+	err := stream.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Front Yard")
+}
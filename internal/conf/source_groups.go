@@ -0,0 +1,71 @@
+// source_groups.go: groups of audio sources close enough to hear the same birds
+package conf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Source group merge window limits in seconds
+const (
+	DefaultSourceGroupWindow = 10
+	MaxSourceGroupWindow     = 60
+)
+
+// SourceGroupSettings groups audio sources that hear the same birds, such as
+// neighbouring cameras. Detections of a species at sources of a group within
+// Window seconds of each other are merged into one detection, kept from the
+// source with the highest confidence and linked to the others.
+type SourceGroupSettings struct {
+	Name    string   `json:"name"`    // name of the group
+	Sources []string `json:"sources"` // display names, stream URLs or device names of the sources in the group
+	Window  int      `json:"window"`  // seconds within which detections at the sources are merged, 0 for the default
+}
+
+// WindowDuration returns the merge window of the group.
+func (g *SourceGroupSettings) WindowDuration() time.Duration {
+	if g.Window <= 0 {
+		return DefaultSourceGroupWindow * time.Second
+	}
+	return time.Duration(g.Window) * time.Second
+}
+
+// validateSourceGroups checks that groups have unique names and at least two
+// sources, and that no source belongs to more than one group.
+func validateSourceGroups(groups []SourceGroupSettings) error {
+	names := make(map[string]bool, len(groups))
+	grouped := make(map[string]string)
+	for i := range groups {
+		group := &groups[i]
+		name := strings.TrimSpace(group.Name)
+		if name == "" {
+			return fmt.Errorf("source group %d: name is required", i+1)
+		}
+		if names[strings.ToLower(name)] {
+			return fmt.Errorf("source group '%s' is configured more than once", name)
+		}
+		names[strings.ToLower(name)] = true
+
+		if group.Window < 0 || group.Window > MaxSourceGroupWindow {
+			return fmt.Errorf("source group '%s': window must be between 0 and %d seconds", name, MaxSourceGroupWindow)
+		}
+
+		sources := 0
+		for _, source := range group.Sources {
+			key := strings.ToLower(strings.TrimSpace(source))
+			if key == "" {
+				continue
+			}
+			if other, exists := grouped[key]; exists {
+				return fmt.Errorf("source '%s' is in source groups '%s' and '%s'", source, other, name)
+			}
+			grouped[key] = name
+			sources++
+		}
+		if sources < 2 {
+			return fmt.Errorf("source group '%s' must contain at least 2 sources", name)
+		}
+	}
+	return nil
+}
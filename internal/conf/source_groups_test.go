@@ -0,0 +1,60 @@
+package conf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateSourceGroups(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		groups  []SourceGroupSettings
+		wantErr bool
+	}{
+		{name: "no groups"},
+		{name: "valid group", groups: []SourceGroupSettings{{Name: "Garden", Sources: []string{"Front", "Back"}, Window: 10}}},
+		{name: "missing name", groups: []SourceGroupSettings{{Sources: []string{"Front", "Back"}}}, wantErr: true},
+		{name: "single source", groups: []SourceGroupSettings{{Name: "Garden", Sources: []string{"Front", " "}}}, wantErr: true},
+		{name: "window too long", groups: []SourceGroupSettings{{Name: "Garden", Sources: []string{"Front", "Back"}, Window: 61}}, wantErr: true},
+		{
+			name: "duplicate name",
+			groups: []SourceGroupSettings{
+				{Name: "Garden", Sources: []string{"Front", "Back"}},
+				{Name: "garden", Sources: []string{"Pond", "Shed"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "source in two groups",
+			groups: []SourceGroupSettings{
+				{Name: "Garden", Sources: []string{"Front", "Back"}},
+				{Name: "Yard", Sources: []string{"back", "Shed"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := validateSourceGroups(tt.groups)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSourceGroupSettings_WindowDuration(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, DefaultSourceGroupWindow*time.Second, (&SourceGroupSettings{}).WindowDuration())
+	assert.Equal(t, 30*time.Second, (&SourceGroupSettings{Window: 30}).WindowDuration())
+}
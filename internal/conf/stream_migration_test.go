@@ -194,6 +194,7 @@ func TestInferStreamType(t *testing.T) {
 		{"rtmps://secure.rtmp/live", StreamTypeRTMP},
 		{"udp://192.168.1.5:1234", StreamTypeUDP},
 		{"rtp://192.168.1.5:5004", StreamTypeUDP},
+		{"srt://remote.example.com:9000", StreamTypeSRT},
 		{"unknown://something", StreamTypeRTSP}, // Default
 		{"no-scheme-url", StreamTypeRTSP},       // Default
 	}
@@ -81,6 +81,48 @@ func TestStreamConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid SRT stream with options",
+			stream: StreamConfig{
+				Name: "Remote Site",
+				URL:  "srt://remote.example.com:9000?mode=caller",
+				Type: StreamTypeSRT,
+				SRT:  SRTSettings{Latency: 800, Passphrase: "wetland-recorder"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "SRT passphrase too short",
+			stream: StreamConfig{
+				Name: "Remote Site",
+				URL:  "srt://remote.example.com:9000",
+				Type: StreamTypeSRT,
+				SRT:  SRTSettings{Passphrase: "short"},
+			},
+			wantErr: true,
+			errMsg:  "SRT passphrase must be 10 to 79 characters",
+		},
+		{
+			name: "SRT negative latency",
+			stream: StreamConfig{
+				Name: "Remote Site",
+				URL:  "srt://remote.example.com:9000",
+				Type: StreamTypeSRT,
+				SRT:  SRTSettings{Latency: -1},
+			},
+			wantErr: true,
+			errMsg:  "SRT latency",
+		},
+		{
+			name: "URL scheme mismatch - SRT type with UDP URL",
+			stream: StreamConfig{
+				Name: "Remote Site",
+				URL:  "udp://192.168.1.5:9000",
+				Type: StreamTypeSRT,
+			},
+			wantErr: true,
+			errMsg:  "SRT type requires srt://",
+		},
 		{
 			name: "missing name",
 			stream: StreamConfig{
@@ -5,6 +5,7 @@ package conf
 import (
 	"fmt"
 	"net"
+	"net/url"
 	"os/exec"
 	"regexp"
 	"slices"
@@ -39,6 +40,9 @@ const (
 	MaxStreamNameLength = 64
 )
 
+// MaxCaptureChannels is the maximum number of channels captured from a local audio device
+const MaxCaptureChannels = 32
+
 // ValidStreamTypes contains all supported stream types
 var ValidStreamTypes = map[string]bool{
 	StreamTypeRTSP: true,
@@ -46,8 +50,16 @@ var ValidStreamTypes = map[string]bool{
 	StreamTypeHLS:  true,
 	StreamTypeRTMP: true,
 	StreamTypeUDP:  true,
+	StreamTypeSRT:  true,
 }
 
+// SRT passphrase and latency limits
+const (
+	MinSRTPassphraseLength = 10    // SRT rejects shorter passphrases
+	MaxSRTPassphraseLength = 79    // SRT rejects longer passphrases
+	MaxSRTLatency          = 60000 // Maximum SRT latency in milliseconds
+)
+
 // EBU R128 normalization limits
 const (
 	MinTargetLUFS    = -40.0 // Minimum target loudness in LUFS
@@ -108,7 +120,7 @@ func (s *StreamConfig) Validate() error {
 
 	// Validate stream type
 	if !ValidStreamTypes[s.Type] {
-		return fmt.Errorf("invalid stream type '%s' for '%s': must be one of rtsp, http, hls, rtmp, udp", s.Type, s.Name)
+		return fmt.Errorf("invalid stream type '%s' for '%s': must be one of rtsp, http, hls, rtmp, udp, srt", s.Type, s.Name)
 	}
 
 	// Validate transport (only tcp/udp allowed, empty defaults to tcp)
@@ -117,7 +129,27 @@ func (s *StreamConfig) Validate() error {
 	}
 
 	// Validate URL scheme matches type
-	return s.validateURLScheme()
+	if err := s.validateURLScheme(); err != nil {
+		return err
+	}
+
+	// Validate SRT options
+	if s.Type == StreamTypeSRT {
+		if err := s.SRT.Validate(); err != nil {
+			return fmt.Errorf("stream '%s': %w", s.Name, err)
+		}
+	}
+
+	// Validate recording schedule
+	if err := s.Schedule.Validate(); err != nil {
+		return fmt.Errorf("stream '%s': %w", s.Name, err)
+	}
+
+	// Validate sound level calibration
+	if err := s.Calibration.Validate(); err != nil {
+		return fmt.Errorf("stream '%s': %w", s.Name, err)
+	}
+	return nil
 }
 
 // validateURLScheme checks URL scheme matches declared stream type
@@ -145,11 +177,26 @@ func (s *StreamConfig) validateURLScheme() error {
 		if !strings.HasPrefix(urlLower, "udp://") && !strings.HasPrefix(urlLower, "rtp://") {
 			return fmt.Errorf("stream '%s': UDP type requires udp:// or rtp:// URL", s.Name)
 		}
+	case StreamTypeSRT:
+		if !strings.HasPrefix(urlLower, "srt://") {
+			return fmt.Errorf("stream '%s': SRT type requires srt:// URL", s.Name)
+		}
 	}
 
 	return nil
 }
 
+// Validate validates the SRT latency and passphrase
+func (s *SRTSettings) Validate() error {
+	if s.Latency < 0 || s.Latency > MaxSRTLatency {
+		return fmt.Errorf("SRT latency %d ms must be between 0 and %d ms", s.Latency, MaxSRTLatency)
+	}
+	if s.Passphrase != "" && (len(s.Passphrase) < MinSRTPassphraseLength || len(s.Passphrase) > MaxSRTPassphraseLength) {
+		return fmt.Errorf("SRT passphrase must be %d to %d characters", MinSRTPassphraseLength, MaxSRTPassphraseLength)
+	}
+	return nil
+}
+
 // ValidateStreams validates the streams collection for uniqueness and individual validity
 func (r *RTSPSettings) ValidateStreams() error {
 	names := make(map[string]bool)
@@ -497,6 +544,11 @@ func ValidateSettings(settings *Settings) error {
 		ve.Errors = append(ve.Errors, err.Error())
 	}
 
+	// Validate Hub settings
+	if err := validateHubSettings(&settings.Hub); err != nil {
+		ve.Errors = append(ve.Errors, err.Error())
+	}
+
 	// If there are any errors, return the ValidationError
 	if len(ve.Errors) > 0 {
 		return ve
@@ -649,6 +701,30 @@ func validateRealtimeSettings(settings *RealtimeSettings) error {
 		return err
 	}
 
+	// Validate audio device recording schedule
+	if err := settings.Audio.Schedule.Validate(); err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-schedule").
+			Build()
+	}
+
+	// Validate audio device sound level calibration
+	if err := settings.Audio.Calibration.Validate(); err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-calibration").
+			Build()
+	}
+
+	// Validate additional capture devices
+	if err := validateAudioDevices(&settings.Audio); err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-devices").
+			Build()
+	}
+
 	// Validate stream configurations
 	if err := settings.RTSP.ValidateStreams(); err != nil {
 		return errors.New(err).
@@ -657,6 +733,61 @@ func validateRealtimeSettings(settings *RealtimeSettings) error {
 			Build()
 	}
 
+	// Validate source groups
+	if err := validateSourceGroups(settings.SourceGroups); err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "source-groups").
+			Build()
+	}
+
+	// Validate failover groups
+	if err := validateFailoverGroups(settings.FailoverGroups); err != nil {
+		return errors.New(err).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "failover-groups").
+			Build()
+	}
+
+	return nil
+}
+
+// validateAudioDevices validates the additional capture devices. A device can
+// be captured only once, so it must not repeat or duplicate Source.
+func validateAudioDevices(settings *AudioSettings) error {
+	seen := make(map[string]bool, len(settings.Devices))
+	if settings.Source != "" {
+		seen[settings.Source] = true
+	}
+	for i := range settings.Devices {
+		device := &settings.Devices[i]
+		if strings.TrimSpace(device.Device) == "" {
+			return fmt.Errorf("audio device %d: device is required", i+1)
+		}
+		if seen[device.Device] {
+			return fmt.Errorf("audio device '%s' is configured more than once", device.Device)
+		}
+		seen[device.Device] = true
+
+		if device.Channels < 0 || device.Channels > MaxCaptureChannels {
+			return fmt.Errorf("audio device '%s': channels must be between 0 and %d", device.Device, MaxCaptureChannels)
+		}
+		if device.Split && device.Channels < 2 {
+			return fmt.Errorf("audio device '%s': splitting requires at least 2 channels", device.Device)
+		}
+		if len(device.ChannelNames) > device.Channels {
+			return fmt.Errorf("audio device '%s': %d channel names given for %d channels", device.Device, len(device.ChannelNames), device.Channels)
+		}
+		if err := device.Schedule.Validate(); err != nil {
+			return fmt.Errorf("audio device '%s': %w", device.Device, err)
+		}
+		if err := device.Calibration.Validate(); err != nil {
+			return fmt.Errorf("audio device '%s': %w", device.Device, err)
+		}
+		if err := device.Bearing.Validate(device.Channels); err != nil {
+			return fmt.Errorf("audio device '%s': %w", device.Device, err)
+		}
+	}
 	return nil
 }
 
@@ -693,6 +824,48 @@ func validateSoundLevelSettings(settings *SoundLevelSettings) error {
 				Context("minimum_interval", MinSoundLevelInterval).
 				Build()
 		}
+		weighting, err := NormalizeWeighting(settings.Weighting)
+		if err != nil {
+			return errors.New(err).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "sound-level-weighting").
+				Context("weighting", settings.Weighting).
+				Build()
+		}
+		settings.Weighting = weighting
+		if err := validateSoundLevelHistorySettings(&settings.History); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSoundLevelHistorySettings validates the sound level history retention settings
+func validateSoundLevelHistorySettings(settings *SoundLevelHistorySettings) error {
+	if !settings.Enabled {
+		return nil
+	}
+	if settings.RawRetentionHours < 1 {
+		return errors.Newf("sound level history raw retention must be at least 1 hour, got %d", settings.RawRetentionHours).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "sound-level-history-raw-retention").
+			Context("raw_retention_hours", settings.RawRetentionHours).
+			Build()
+	}
+	if settings.DownsampleMinutes < 1 || settings.DownsampleMinutes > 60 || 60%settings.DownsampleMinutes != 0 {
+		return errors.Newf("sound level history downsample interval must divide 60 minutes, got %d", settings.DownsampleMinutes).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "sound-level-history-downsample").
+			Context("downsample_minutes", settings.DownsampleMinutes).
+			Build()
+	}
+	if settings.RetentionDays < 0 || (settings.RetentionDays > 0 && settings.RetentionDays*24 < settings.RawRetentionHours) {
+		return errors.Newf("sound level history retention must be 0 or cover the raw retention, got %d days", settings.RetentionDays).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "sound-level-history-retention").
+			Context("retention_days", settings.RetentionDays).
+			Context("raw_retention_hours", settings.RawRetentionHours).
+			Build()
 	}
 	return nil
 }
@@ -889,6 +1062,73 @@ func validateAudioSettings(settings *AudioSettings) error {
 		}
 	}
 
+	return validateArchiveSettings(&settings.Archive, settings.FfmpegPath)
+}
+
+// validateArchiveSettings validates the continuous recording settings.
+// Recording is disabled with a warning if FFmpeg is not available.
+func validateArchiveSettings(settings *ArchiveSettings, ffmpegPath string) error {
+	if !settings.Enabled {
+		return nil
+	}
+	if ffmpegPath == "" {
+		settings.Enabled = false
+		GetLogger().Warn("FFmpeg not available, continuous recording disabled")
+		return nil
+	}
+
+	for i, source := range settings.Sources {
+		if strings.TrimSpace(source) == "" {
+			return errors.Newf("archive source %d cannot be empty", i+1).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "audio-archive-sources").
+				Build()
+		}
+	}
+
+	if settings.Path == "" {
+		return errors.Newf("archive path cannot be empty").
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-archive-path").
+			Build()
+	}
+
+	switch settings.Type {
+	case "flac":
+	case "opus":
+		bitrate, err := strconv.Atoi(strings.TrimSuffix(settings.Bitrate, "k"))
+		if err != nil || !strings.HasSuffix(settings.Bitrate, "k") || bitrate < 6 || bitrate > 256 {
+			return errors.Newf("archive bitrate must be between 6k and 256k, got %q", settings.Bitrate).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "audio-archive-bitrate").
+				Context("bitrate", settings.Bitrate).
+				Build()
+		}
+	default:
+		return errors.Newf("unsupported archive type: %s, must be flac or opus", settings.Type).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-archive-type").
+			Context("archive_type", settings.Type).
+			Build()
+	}
+
+	if hours, err := ParseRetentionPeriod(settings.MaxAge); err != nil || hours < 1 {
+		return errors.Newf("invalid archive max age: %q", settings.MaxAge).
+			Category(errors.CategoryValidation).
+			Context("validation_type", "audio-archive-max-age").
+			Context("max_age", settings.MaxAge).
+			Build()
+	}
+
+	if settings.MaxUsage != "" {
+		if usage, err := ParsePercentage(settings.MaxUsage); err != nil || usage <= 0 || usage > 100 {
+			return errors.Newf("invalid archive max usage: %q", settings.MaxUsage).
+				Category(errors.CategoryValidation).
+				Context("validation_type", "audio-archive-max-usage").
+				Context("max_usage", settings.MaxUsage).
+				Build()
+		}
+	}
 	return nil
 }
 
@@ -943,6 +1183,9 @@ func validateDashboardSettings(settings *Dashboard) error {
 		}
 	}
 
+	// Validate spectrogram renderer
+	validateSpectrogramRenderer(&settings.Spectrogram)
+
 	// Log the effective spectrogram mode at startup for troubleshooting
 	effectiveMode := settings.Spectrogram.GetMode()
 	GetLogger().Debug("Spectrogram configuration",
@@ -956,6 +1199,62 @@ func validateDashboardSettings(settings *Dashboard) error {
 	return nil
 }
 
+// validateSpectrogramRenderer validates the spectrogram renderer and native
+// renderer settings. Like the other spectrogram settings, invalid values are
+// logged and replaced with defaults instead of failing validation.
+func validateSpectrogramRenderer(settings *SpectrogramPreRender) {
+	if settings.Renderer != "" && settings.Renderer != SpectrogramRendererSox && settings.Renderer != SpectrogramRendererNative {
+		GetLogger().Warn("Invalid spectrogram renderer, using sox",
+			logger.String("invalid_renderer", settings.Renderer),
+			logger.String("valid_renderers", "sox, native"))
+		settings.Renderer = SpectrogramRendererSox
+	}
+
+	native := &settings.Native
+	if native.FFTSize != 0 && (native.FFTSize < 256 || native.FFTSize > 8192 || native.FFTSize&(native.FFTSize-1) != 0) {
+		GetLogger().Warn("Invalid spectrogram FFT size, using 1024",
+			logger.Int("invalid_fft_size", native.FFTSize),
+			logger.String("valid_fft_sizes", "power of two from 256 to 8192"))
+		native.FFTSize = 1024
+	}
+
+	validWindows := []string{SpectrogramWindowHann, SpectrogramWindowHamming, SpectrogramWindowBlackman, SpectrogramWindowDolph}
+	if native.Window != "" && !slices.Contains(validWindows, native.Window) {
+		GetLogger().Warn("Invalid spectrogram window, using style default",
+			logger.String("invalid_window", native.Window),
+			logger.String("valid_windows", strings.Join(validWindows, ", ")))
+		native.Window = ""
+	}
+
+	validColorMaps := []string{
+		SpectrogramColorMapSox,
+		SpectrogramColorMapHighContrast,
+		SpectrogramColorMapGrayscale,
+		SpectrogramColorMapInverted,
+		SpectrogramColorMapViridis,
+	}
+	if native.ColorMap != "" && !slices.Contains(validColorMaps, native.ColorMap) {
+		GetLogger().Warn("Invalid spectrogram color map, using style default",
+			logger.String("invalid_color_map", native.ColorMap),
+			logger.String("valid_color_maps", strings.Join(validColorMaps, ", ")))
+		native.ColorMap = ""
+	}
+
+	maxFreq := SampleRate / 2
+	if native.MaxFreq < 0 || native.MaxFreq > maxFreq {
+		GetLogger().Warn("Invalid spectrogram maximum frequency, using 12000 Hz",
+			logger.Int("invalid_max_freq", native.MaxFreq),
+			logger.Int("nyquist_frequency", maxFreq))
+		native.MaxFreq = 12000
+	}
+	if native.MinFreq < 0 || (native.MaxFreq > 0 && native.MinFreq >= native.MaxFreq) {
+		GetLogger().Warn("Invalid spectrogram minimum frequency, using 0 Hz",
+			logger.Int("invalid_min_freq", native.MinFreq),
+			logger.Int("max_freq", native.MaxFreq))
+		native.MinFreq = 0
+	}
+}
+
 // validateWeatherSettings validates weather-specific settings
 func validateWeatherSettings(settings *WeatherSettings) error {
 	// Validate poll interval (minimum 15 minutes)
@@ -1137,6 +1436,78 @@ func validateSpeciesConfigSettings(settings *SpeciesSettings) error {
 	return nil
 }
 
+// validateHubSettings validates multi-station hub configuration
+func validateHubSettings(h *HubSettings) error {
+	if h.Enabled {
+		if h.MaxClipSizeMB < 0 {
+			return errors.Newf("hub.max_clip_size_mb must be >= 0").
+				Category(errors.CategoryValidation).
+				Context("validation_type", "hub-max-clip-size").
+				Build()
+		}
+		seen := make(map[string]bool, len(h.Nodes))
+		seenTokens := make(map[string]bool, len(h.Nodes))
+		for i := range h.Nodes {
+			node := &h.Nodes[i]
+			name := strings.TrimSpace(node.Name)
+			if name == "" {
+				return errors.Newf("hub node %d: name is required", i).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "hub-node-name").
+					Context("node_index", i).
+					Build()
+			}
+			if strings.TrimSpace(node.Token) == "" {
+				return errors.Newf("hub node '%s': token is required", name).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "hub-node-token").
+					Context("node_name", name).
+					Build()
+			}
+			if seen[strings.ToLower(name)] {
+				return errors.Newf("hub node '%s' is listed more than once", name).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "hub-node-duplicate").
+					Context("node_name", name).
+					Build()
+			}
+			if seenTokens[node.Token] {
+				return errors.Newf("hub node '%s': token is shared with another node", name).
+					Category(errors.CategoryValidation).
+					Context("validation_type", "hub-node-token-duplicate").
+					Context("node_name", name).
+					Build()
+			}
+			seen[strings.ToLower(name)] = true
+			seenTokens[node.Token] = true
+		}
+	}
+
+	if h.Upstream.Enabled {
+		u, err := url.Parse(h.Upstream.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.Newf("hub.upstream.url must be an http or https URL").
+				Category(errors.CategoryValidation).
+				Context("validation_type", "hub-upstream-url").
+				Build()
+		}
+		if strings.TrimSpace(h.Upstream.Token) == "" {
+			return errors.Newf("hub.upstream.token is required when upstream is enabled").
+				Category(errors.CategoryValidation).
+				Context("validation_type", "hub-upstream-token").
+				Build()
+		}
+		if h.Upstream.Timeout < 0 {
+			return errors.Newf("hub.upstream.timeout must be non-negative").
+				Category(errors.CategoryValidation).
+				Context("validation_type", "hub-upstream-timeout").
+				Build()
+		}
+	}
+
+	return nil
+}
+
 // validateNotificationSettings validates notification push configuration
 func validateNotificationSettings(n *NotificationConfig) error {
 	if !n.Push.Enabled {
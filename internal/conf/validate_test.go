@@ -164,6 +164,120 @@ func TestValidateSoundLevelSettingsErrorMessage(t *testing.T) {
 	assert.Equal(t, expectedMsg, err.Error())
 }
 
+func TestValidateSoundLevelHistorySettings(t *testing.T) {
+	valid := SoundLevelHistorySettings{Enabled: true, RawRetentionHours: 48, DownsampleMinutes: 5, RetentionDays: 90}
+
+	tests := []struct {
+		name    string
+		modify  func(s *SoundLevelHistorySettings)
+		errType string
+	}{
+		{"valid settings", func(s *SoundLevelHistorySettings) {}, ""},
+		{"disabled history is not validated", func(s *SoundLevelHistorySettings) { s.Enabled = false; s.DownsampleMinutes = 7 }, ""},
+		{"keep forever", func(s *SoundLevelHistorySettings) { s.RetentionDays = 0 }, ""},
+		{"zero raw retention", func(s *SoundLevelHistorySettings) { s.RawRetentionHours = 0 }, "sound-level-history-raw-retention"},
+		{"downsample does not divide an hour", func(s *SoundLevelHistorySettings) { s.DownsampleMinutes = 7 }, "sound-level-history-downsample"},
+		{"downsample longer than an hour", func(s *SoundLevelHistorySettings) { s.DownsampleMinutes = 120 }, "sound-level-history-downsample"},
+		{"retention shorter than raw retention", func(s *SoundLevelHistorySettings) { s.RetentionDays = 1 }, "sound-level-history-retention"},
+		{"negative retention", func(s *SoundLevelHistorySettings) { s.RetentionDays = -1 }, "sound-level-history-retention"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := SoundLevelSettings{Enabled: true, Interval: 10, History: valid}
+			tt.modify(&settings.History)
+
+			err := validateSoundLevelSettings(&settings)
+			if tt.errType == "" {
+				assert.NoError(t, err)
+				return
+			}
+			enhanced := requireEnhancedError(t, err)
+			assert.Equal(t, tt.errType, enhanced.Context["validation_type"])
+		})
+	}
+}
+
+func TestValidateArchiveSettings(t *testing.T) {
+	valid := ArchiveSettings{Enabled: true, Path: "archive/", Type: "flac", Bitrate: "64k", MaxAge: "7d", MaxUsage: "90%"}
+
+	tests := []struct {
+		name    string
+		modify  func(s *ArchiveSettings)
+		errType string
+	}{
+		{"valid flac", func(s *ArchiveSettings) {}, ""},
+		{"valid opus", func(s *ArchiveSettings) { s.Type = "opus" }, ""},
+		{"usage limit disabled", func(s *ArchiveSettings) { s.MaxUsage = "" }, ""},
+		{"disabled archive is not validated", func(s *ArchiveSettings) { s.Enabled = false; s.Type = "mp3" }, ""},
+		{"selected sources", func(s *ArchiveSettings) { s.Sources = []string{"Garden", "rtsp://camera/stream"} }, ""},
+		{"empty source", func(s *ArchiveSettings) { s.Sources = []string{"Garden", " "} }, "audio-archive-sources"},
+		{"empty path", func(s *ArchiveSettings) { s.Path = "" }, "audio-archive-path"},
+		{"unsupported type", func(s *ArchiveSettings) { s.Type = "mp3" }, "audio-archive-type"},
+		{"opus bitrate without unit", func(s *ArchiveSettings) { s.Type = "opus"; s.Bitrate = "64" }, "audio-archive-bitrate"},
+		{"opus bitrate too high", func(s *ArchiveSettings) { s.Type = "opus"; s.Bitrate = "512k" }, "audio-archive-bitrate"},
+		{"invalid max age", func(s *ArchiveSettings) { s.MaxAge = "week" }, "audio-archive-max-age"},
+		{"zero max age", func(s *ArchiveSettings) { s.MaxAge = "0d" }, "audio-archive-max-age"},
+		{"invalid max usage", func(s *ArchiveSettings) { s.MaxUsage = "90" }, "audio-archive-max-usage"},
+		{"max usage over 100", func(s *ArchiveSettings) { s.MaxUsage = "120%" }, "audio-archive-max-usage"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			settings := valid
+			tt.modify(&settings)
+
+			err := validateArchiveSettings(&settings, "/usr/bin/ffmpeg")
+			if tt.errType == "" {
+				assert.NoError(t, err)
+				return
+			}
+			enhanced := requireEnhancedError(t, err)
+			assert.Equal(t, tt.errType, enhanced.Context["validation_type"])
+		})
+	}
+
+	t.Run("disabled without ffmpeg", func(t *testing.T) {
+		settings := valid
+		require.NoError(t, validateArchiveSettings(&settings, ""))
+		assert.False(t, settings.Enabled)
+	})
+}
+
+func TestValidateSpectrogramRenderer(t *testing.T) {
+	valid := SpectrogramPreRender{
+		Renderer: SpectrogramRendererNative,
+		Native:   NativeSpectrogramSettings{FFTSize: 2048, Window: SpectrogramWindowDolph, MinFreq: 500, MaxFreq: 10000, ColorMap: SpectrogramColorMapViridis},
+	}
+
+	tests := []struct {
+		name   string
+		modify func(s *SpectrogramPreRender)
+		want   func(s *SpectrogramPreRender)
+	}{
+		{"valid settings are kept", func(s *SpectrogramPreRender) {}, func(s *SpectrogramPreRender) {}},
+		{"unset values are kept", func(s *SpectrogramPreRender) { *s = SpectrogramPreRender{} }, func(s *SpectrogramPreRender) { *s = SpectrogramPreRender{} }},
+		{"unknown renderer", func(s *SpectrogramPreRender) { s.Renderer = "gnuplot" }, func(s *SpectrogramPreRender) { s.Renderer = SpectrogramRendererSox }},
+		{"FFT size not a power of two", func(s *SpectrogramPreRender) { s.Native.FFTSize = 1000 }, func(s *SpectrogramPreRender) { s.Native.FFTSize = 1024 }},
+		{"FFT size too large", func(s *SpectrogramPreRender) { s.Native.FFTSize = 16384 }, func(s *SpectrogramPreRender) { s.Native.FFTSize = 1024 }},
+		{"unknown window", func(s *SpectrogramPreRender) { s.Native.Window = "kaiser" }, func(s *SpectrogramPreRender) { s.Native.Window = "" }},
+		{"unknown color map", func(s *SpectrogramPreRender) { s.Native.ColorMap = "jet" }, func(s *SpectrogramPreRender) { s.Native.ColorMap = "" }},
+		{"max frequency above Nyquist", func(s *SpectrogramPreRender) { s.Native.MaxFreq = 30000 }, func(s *SpectrogramPreRender) { s.Native.MaxFreq = 12000 }},
+		{"min frequency above max", func(s *SpectrogramPreRender) { s.Native.MinFreq = 11000 }, func(s *SpectrogramPreRender) { s.Native.MinFreq = 0 }},
+		{"negative min frequency", func(s *SpectrogramPreRender) { s.Native.MinFreq = -1 }, func(s *SpectrogramPreRender) { s.Native.MinFreq = 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, want := valid, valid
+			tt.modify(&got)
+			tt.want(&want)
+			validateSpectrogramRenderer(&got)
+			assert.Equal(t, want, got)
+		})
+	}
+}
+
 func BenchmarkValidateSoundLevelSettings(b *testing.B) {
 	settings := &SoundLevelSettings{
 		Enabled:  true,
@@ -185,3 +299,131 @@ func BenchmarkValidateSoundLevelSettingsWithError(b *testing.B) {
 		_ = validateSoundLevelSettings(settings)
 	}
 }
+
+func TestValidateHubSettings(t *testing.T) {
+	validNodes := []HubNode{{Name: "north", Token: "t1"}, {Name: "south", Token: "t2"}}
+	validUpstream := HubUpstreamSettings{Enabled: true, URL: "https://hub.example.com", Token: "secret"}
+
+	tests := []struct {
+		name     string
+		settings HubSettings
+		errType  string
+	}{
+		{
+			name:     "disabled hub ignores nodes",
+			settings: HubSettings{Nodes: []HubNode{{Name: ""}}},
+		},
+		{
+			name:     "valid hub and upstream",
+			settings: HubSettings{Enabled: true, Nodes: validNodes, Upstream: validUpstream},
+		},
+		{
+			name:     "node without name",
+			settings: HubSettings{Enabled: true, Nodes: []HubNode{{Token: "t1"}}},
+			errType:  "hub-node-name",
+		},
+		{
+			name:     "node without token",
+			settings: HubSettings{Enabled: true, Nodes: []HubNode{{Name: "north"}}},
+			errType:  "hub-node-token",
+		},
+		{
+			name:     "duplicate node name is case-insensitive",
+			settings: HubSettings{Enabled: true, Nodes: []HubNode{{Name: "north", Token: "t1"}, {Name: "North", Token: "t2"}}},
+			errType:  "hub-node-duplicate",
+		},
+		{
+			name:     "shared token",
+			settings: HubSettings{Enabled: true, Nodes: []HubNode{{Name: "north", Token: "t1"}, {Name: "south", Token: "t1"}}},
+			errType:  "hub-node-token-duplicate",
+		},
+		{
+			name:     "upstream with invalid url",
+			settings: HubSettings{Upstream: HubUpstreamSettings{Enabled: true, URL: "ftp://hub", Token: "secret"}},
+			errType:  "hub-upstream-url",
+		},
+		{
+			name:     "upstream without token",
+			settings: HubSettings{Upstream: HubUpstreamSettings{Enabled: true, URL: "https://hub.example.com"}},
+			errType:  "hub-upstream-token",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateHubSettings(&tt.settings)
+			if tt.errType == "" {
+				assert.NoError(t, err)
+				return
+			}
+			enhanced := requireEnhancedError(t, err)
+			assert.Equal(t, tt.errType, enhanced.Context["validation_type"])
+			assert.Equal(t, errors.CategoryValidation, enhanced.Category)
+		})
+	}
+}
+
+func TestValidateAudioDevices(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings AudioSettings
+		wantErr  string
+	}{
+		{
+			name:     "no devices",
+			settings: AudioSettings{Source: "sysdefault"},
+		},
+		{
+			name: "split device with channel names",
+			settings: AudioSettings{Devices: []AudioDeviceSettings{
+				{Device: "hw:1,0", Channels: 4, Split: true, ChannelNames: []string{"North", "East"}},
+			}},
+		},
+		{
+			name:     "missing device",
+			settings: AudioSettings{Devices: []AudioDeviceSettings{{Name: "Roof"}}},
+			wantErr:  "device is required",
+		},
+		{
+			name:     "duplicates main source",
+			settings: AudioSettings{Source: "hw:1,0", Devices: []AudioDeviceSettings{{Device: "hw:1,0"}}},
+			wantErr:  "more than once",
+		},
+		{
+			name:     "too many channels",
+			settings: AudioSettings{Devices: []AudioDeviceSettings{{Device: "hw:1,0", Channels: MaxCaptureChannels + 1}}},
+			wantErr:  "channels must be between",
+		},
+		{
+			name:     "split without channels",
+			settings: AudioSettings{Devices: []AudioDeviceSettings{{Device: "hw:1,0", Split: true}}},
+			wantErr:  "at least 2 channels",
+		},
+		{
+			name: "more names than channels",
+			settings: AudioSettings{Devices: []AudioDeviceSettings{
+				{Device: "hw:1,0", Channels: 2, Split: true, ChannelNames: []string{"a", "b", "c"}},
+			}},
+			wantErr: "3 channel names given for 2 channels",
+		},
+		{
+			name: "invalid schedule",
+			settings: AudioSettings{Devices: []AudioDeviceSettings{
+				{Device: "hw:1,0", Schedule: ScheduleSettings{Enabled: true}},
+			}},
+			wantErr: "no windows",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAudioDevices(&tt.settings)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
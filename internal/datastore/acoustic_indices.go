@@ -0,0 +1,36 @@
+// acoustic_indices.go: Acoustic index history records
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+// AcousticIndicesRecord is a stored set of acoustic indices of one audio
+// source over one interval, normally a minute. See myaudio.AcousticIndices for
+// the meaning of the indices.
+type AcousticIndicesRecord struct {
+	// SourceURI is the sanitized source identifier, matching sound level records.
+	SourceURI  string    `json:"source"`
+	SourceName string    `json:"source_name,omitempty"`
+	NodeName   string    `json:"node_name,omitempty"`
+	Start      time.Time `json:"start"`
+	Duration   int       `json:"duration_seconds"`
+	ACI        float64   `json:"aci"`
+	ADI        float64   `json:"adi"`
+	NDSI       float64   `json:"ndsi"`
+	BI         float64   `json:"bi"`
+	H          float64   `json:"h"`
+}
+
+// AcousticIndicesStore is implemented by datastores that persist acoustic
+// indices alongside the sound level history. Like SoundLevelHistoryStore it is
+// optional and checked with a type assertion. Indices are not downsampled.
+type AcousticIndicesStore interface {
+	// SaveAcousticIndices stores indices, creating unknown sources.
+	SaveAcousticIndices(ctx context.Context, records []AcousticIndicesRecord) error
+	// GetAcousticIndices returns records starting within the query range, ordered by source and time.
+	GetAcousticIndices(ctx context.Context, query SoundLevelQuery) ([]AcousticIndicesRecord, error)
+	// PruneAcousticIndices deletes records starting before the cutoff.
+	PruneAcousticIndices(ctx context.Context, before time.Time) (int64, error)
+}
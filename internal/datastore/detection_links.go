@@ -0,0 +1,43 @@
+// detection_links.go: Detections merged across grouped audio sources
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+// DetectionLinkRecord is a detection at another audio source of a source group
+// that was merged into a detection heard with a higher confidence.
+type DetectionLinkRecord struct {
+	DetectionID uint `json:"detection_id"`
+	// SourceURI is the sanitized source identifier, matching detections.
+	SourceURI  string    `json:"source"`
+	SourceName string    `json:"source_name,omitempty"`
+	NodeName   string    `json:"node_name,omitempty"`
+	Confidence float64   `json:"confidence"`
+	BeginTime  time.Time `json:"begin_time"`
+}
+
+// MergedDetectionCount counts the detections of a species that were merged
+// from several sources.
+type MergedDetectionCount struct {
+	ScientificName string `json:"scientific_name"`
+	CommonName     string `json:"common_name"`
+	Events         int64  `json:"events"`     // detections heard at more than one source
+	Duplicates     int64  `json:"duplicates"` // detections at other sources merged into them
+}
+
+// DetectionLinkStore is implemented by datastores that persist detections
+// merged across sources. Like StreamGapStore it is optional and checked with
+// a type assertion.
+type DetectionLinkStore interface {
+	// SaveDetectionLinks links merged detections to a detection, creating
+	// unknown sources.
+	SaveDetectionLinks(ctx context.Context, detectionID uint, links []DetectionLinkRecord) error
+	// GetDetectionLinks returns the detections merged into a detection,
+	// ordered by confidence, highest first.
+	GetDetectionLinks(ctx context.Context, detectionID uint) ([]DetectionLinkRecord, error)
+	// GetMergedDetectionCounts returns per species counts of merged detections
+	// detected within the range, ordered by events, most first.
+	GetMergedDetectionCounts(ctx context.Context, start, end time.Time) ([]MergedDetectionCount, error)
+}
@@ -358,6 +358,7 @@ func NoteFromResult(result *detection.Result) Note {
 			DisplayName: result.AudioSource.DisplayName,
 		},
 		Occurrence: result.Occurrence,
+		Bearing:    result.Bearing,
 		Verified:   result.Verified,
 		Locked:     result.Locked,
 	}
@@ -420,6 +421,7 @@ func (r *detectionRepository) noteToResult(note *Note) (*detection.Result, error
 		ClipName:       note.ClipName,
 		ProcessingTime: note.ProcessingTime,
 		Occurrence:     note.Occurrence,
+		Bearing:        note.Bearing,
 		Verified:       note.Verified,
 		Locked:         note.Locked,
 		Model:          detection.DefaultModelInfo(),
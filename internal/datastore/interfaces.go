@@ -1973,7 +1973,10 @@ type SearchFilters struct {
 	LockedOnly     bool
 	UnlockedOnly   bool
 	Device         string
-	TimeOfDay      string // "any", "day", "night", "sunrise", "sunset"
+	TimeOfDay      string   // "any", "day", "night", "sunrise", "sunset"
+	Tags           []string // Detection tags (v2 schema only)
+	TagsMatchAll   bool     // Require all Tags instead of any
+	CommentQuery   string   // Full-text search over comments
 	Page           int
 	PerPage        int
 	SortBy         string
@@ -2101,6 +2104,9 @@ func applyCommonFilters(query *gorm.DB, filters *SearchFilters, ds *DataStore) *
 		query = query.Where("notes.source_node LIKE ?", "%"+filters.Device+"%")
 	}
 
+	query = applyTagFilter(query, filters.Tags)
+	query = applyCommentQueryFilter(query, filters.CommentQuery)
+
 	return query
 }
 
@@ -33,6 +33,7 @@ type Note struct {
 	ClipName       string
 	ProcessingTime time.Duration
 	Occurrence     float64       `gorm:"-" json:"occurrence,omitempty"` // Runtime only, occurrence probability (0-1) based on location/time
+	Bearing        *float64      `gorm:"-" json:"bearing,omitempty"`    // Compass bearing in degrees of the sound, stored in the v2 database only
 	Results        []Results     `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE"`
 	Review         *NoteReview   `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE"` // One-to-one relationship with cascade delete
 	Comments       []NoteComment `gorm:"foreignKey:NoteID;constraint:OnDelete:CASCADE"` // One-to-many relationship with cascade delete
@@ -0,0 +1,398 @@
+// phenology.go: species occupancy and phenology analytics
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore/entities"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// PhenologyWeeks is the number of weekly buckets in a year. Week 1 starts on
+// January 1st; week 53 holds the last one or two days of the year.
+const PhenologyWeeks = 53
+
+// Cache lifetimes for per-year phenology summaries. The current year keeps
+// receiving detections and is refreshed often; completed years only change
+// when detections are reviewed or deleted.
+const (
+	phenologyCurrentYearTTL = 10 * time.Minute
+	phenologyPastYearTTL    = 24 * time.Hour
+)
+
+// SpeciesDailyPresence is the number of detections of a species on one day.
+type SpeciesDailyPresence struct {
+	ScientificName string
+	CommonName     string
+	Date           string // YYYY-MM-DD, local time
+	Count          int
+}
+
+// SpeciesPresenceProvider is implemented by datastores that can report daily
+// per-species detection counts. Both the legacy and the v2 datastores implement
+// it; call it via type assertion on Interface.
+type SpeciesPresenceProvider interface {
+	// GetSpeciesDailyPresence returns one row per species and day with at least one
+	// detection in the given date range, excluding detections reviewed as false positives.
+	GetSpeciesDailyPresence(ctx context.Context, startDate, endDate string) ([]SpeciesDailyPresence, error)
+}
+
+// SpeciesPhenology summarizes when a species was present during one year.
+type SpeciesPhenology struct {
+	ScientificName    string  `json:"scientific_name"`
+	CommonName        string  `json:"common_name"`
+	FirstDetection    string  `json:"first_detection"` // arrival date, YYYY-MM-DD
+	LastDetection     string  `json:"last_detection"`  // departure date, YYYY-MM-DD
+	FirstDayOfYear    int     `json:"first_day_of_year"`
+	LastDayOfYear     int     `json:"last_day_of_year"`
+	SeasonLengthDays  int     `json:"season_length_days"` // days from first to last detection, inclusive
+	DetectionDays     int     `json:"detection_days"`     // days with at least one detection
+	TotalDetections   int     `json:"total_detections"`
+	DetectionDayRatio float64 `json:"detection_day_ratio"` // DetectionDays / active days of the year
+	SeasonDayRatio    float64 `json:"season_day_ratio"`    // DetectionDays / active days within the season
+	// WeeklyPresence holds the number of detection days per week of the year (0-7).
+	WeeklyPresence []int `json:"weekly_presence"`
+}
+
+// YearPhenology holds the phenology of all species detected in one year.
+type YearPhenology struct {
+	Year int `json:"year"`
+	// ActiveDays is the number of days with any detection. It approximates the
+	// days the station was recording and is the denominator of detection-day ratios.
+	ActiveDays  int                `json:"active_days"`
+	ActiveWeeks []int              `json:"active_weeks"` // active days per week of the year
+	Species     []SpeciesPhenology `json:"species"`
+	GeneratedAt time.Time          `json:"generated_at"`
+}
+
+// Find returns the phenology of a species by scientific or common name.
+func (y *YearPhenology) Find(species string) (*SpeciesPhenology, bool) {
+	for i := range y.Species {
+		s := &y.Species[i]
+		if strings.EqualFold(s.ScientificName, species) || strings.EqualFold(s.CommonName, species) {
+			return s, true
+		}
+	}
+	return nil, false
+}
+
+// PhenologyYearComparison is the arrival and departure of a species in one year.
+type PhenologyYearComparison struct {
+	Year              int     `json:"year"`
+	Detected          bool    `json:"detected"`
+	FirstDetection    string  `json:"first_detection,omitempty"`
+	LastDetection     string  `json:"last_detection,omitempty"`
+	FirstDayOfYear    int     `json:"first_day_of_year,omitempty"`
+	LastDayOfYear     int     `json:"last_day_of_year,omitempty"`
+	DetectionDays     int     `json:"detection_days"`
+	DetectionDayRatio float64 `json:"detection_day_ratio"`
+	// ArrivalShiftDays and DepartureShiftDays compare against the mean over all
+	// compared years in which the species was detected. Negative means earlier.
+	ArrivalShiftDays   *float64 `json:"arrival_shift_days,omitempty"`
+	DepartureShiftDays *float64 `json:"departure_shift_days,omitempty"`
+}
+
+// PhenologyComparison compares the arrival and departure of a species across years.
+type PhenologyComparison struct {
+	ScientificName     string                    `json:"scientific_name"`
+	CommonName         string                    `json:"common_name"`
+	Years              []PhenologyYearComparison `json:"years"`
+	MeanArrivalDay     *float64                  `json:"mean_arrival_day,omitempty"`
+	MeanDepartureDay   *float64                  `json:"mean_departure_day,omitempty"`
+	ArrivalTrendDays   *float64                  `json:"arrival_trend_days_per_year,omitempty"`
+	DepartureTrendDays *float64                  `json:"departure_trend_days_per_year,omitempty"`
+}
+
+// phenologyWeek returns the zero-based week of the year for a day of year (1-366).
+func phenologyWeek(dayOfYear int) int {
+	return min((dayOfYear-1)/7, PhenologyWeeks-1)
+}
+
+// ComputeYearPhenology builds the phenology summary of one year from daily
+// presence rows. Rows outside the year or with unparseable dates are ignored.
+func ComputeYearPhenology(year int, rows []SpeciesDailyPresence) *YearPhenology {
+	type accumulator struct {
+		phenology SpeciesPhenology
+		days      map[int]struct{}
+	}
+
+	activeDays := make(map[int]struct{})
+	species := make(map[string]*accumulator)
+
+	for i := range rows {
+		row := &rows[i]
+		day, err := time.Parse(time.DateOnly, row.Date)
+		if err != nil || day.Year() != year || row.Count <= 0 {
+			continue
+		}
+		doy := day.YearDay()
+		activeDays[doy] = struct{}{}
+
+		acc, ok := species[row.ScientificName]
+		if !ok {
+			acc = &accumulator{
+				phenology: SpeciesPhenology{
+					ScientificName: row.ScientificName,
+					CommonName:     row.CommonName,
+					WeeklyPresence: make([]int, PhenologyWeeks),
+				},
+				days: make(map[int]struct{}),
+			}
+			species[row.ScientificName] = acc
+		}
+		p := &acc.phenology
+		if p.CommonName == "" {
+			p.CommonName = row.CommonName
+		}
+		p.TotalDetections += row.Count
+		if _, seen := acc.days[doy]; seen {
+			continue
+		}
+		acc.days[doy] = struct{}{}
+		p.WeeklyPresence[phenologyWeek(doy)]++
+		if p.FirstDayOfYear == 0 || doy < p.FirstDayOfYear {
+			p.FirstDayOfYear = doy
+			p.FirstDetection = row.Date
+		}
+		if doy > p.LastDayOfYear {
+			p.LastDayOfYear = doy
+			p.LastDetection = row.Date
+		}
+	}
+
+	result := &YearPhenology{
+		Year:        year,
+		ActiveDays:  len(activeDays),
+		ActiveWeeks: make([]int, PhenologyWeeks),
+		Species:     make([]SpeciesPhenology, 0, len(species)),
+		GeneratedAt: time.Now(),
+	}
+	for doy := range activeDays {
+		result.ActiveWeeks[phenologyWeek(doy)]++
+	}
+
+	for _, acc := range species {
+		p := acc.phenology
+		p.DetectionDays = len(acc.days)
+		p.SeasonLengthDays = p.LastDayOfYear - p.FirstDayOfYear + 1
+		if result.ActiveDays > 0 {
+			p.DetectionDayRatio = float64(p.DetectionDays) / float64(result.ActiveDays)
+		}
+		seasonActive := 0
+		for doy := range activeDays {
+			if doy >= p.FirstDayOfYear && doy <= p.LastDayOfYear {
+				seasonActive++
+			}
+		}
+		if seasonActive > 0 {
+			p.SeasonDayRatio = float64(p.DetectionDays) / float64(seasonActive)
+		}
+		result.Species = append(result.Species, p)
+	}
+
+	slices.SortFunc(result.Species, func(a, b SpeciesPhenology) int {
+		if a.FirstDayOfYear != b.FirstDayOfYear {
+			return a.FirstDayOfYear - b.FirstDayOfYear
+		}
+		return strings.Compare(a.ScientificName, b.ScientificName)
+	})
+
+	return result
+}
+
+// ComparePhenology compares the arrival and departure of a species across the
+// given years. Years must be sorted in ascending order.
+func ComparePhenology(species string, years []*YearPhenology) *PhenologyComparison {
+	comparison := &PhenologyComparison{
+		ScientificName: species,
+		CommonName:     species,
+		Years:          make([]PhenologyYearComparison, 0, len(years)),
+	}
+
+	var detectedYears, arrivals, departures []float64
+	for _, y := range years {
+		entry := PhenologyYearComparison{Year: y.Year}
+		if p, ok := y.Find(species); ok {
+			comparison.ScientificName = p.ScientificName
+			comparison.CommonName = p.CommonName
+			entry.Detected = true
+			entry.FirstDetection = p.FirstDetection
+			entry.LastDetection = p.LastDetection
+			entry.FirstDayOfYear = p.FirstDayOfYear
+			entry.LastDayOfYear = p.LastDayOfYear
+			entry.DetectionDays = p.DetectionDays
+			entry.DetectionDayRatio = p.DetectionDayRatio
+			detectedYears = append(detectedYears, float64(y.Year))
+			arrivals = append(arrivals, float64(p.FirstDayOfYear))
+			departures = append(departures, float64(p.LastDayOfYear))
+		}
+		comparison.Years = append(comparison.Years, entry)
+	}
+
+	if len(arrivals) == 0 {
+		return comparison
+	}
+
+	meanArrival := mean(arrivals)
+	meanDeparture := mean(departures)
+	comparison.MeanArrivalDay = &meanArrival
+	comparison.MeanDepartureDay = &meanDeparture
+	for i := range comparison.Years {
+		entry := &comparison.Years[i]
+		if !entry.Detected {
+			continue
+		}
+		arrivalShift := float64(entry.FirstDayOfYear) - meanArrival
+		departureShift := float64(entry.LastDayOfYear) - meanDeparture
+		entry.ArrivalShiftDays = &arrivalShift
+		entry.DepartureShiftDays = &departureShift
+	}
+
+	if len(detectedYears) >= 2 {
+		arrivalTrend := linearSlope(detectedYears, arrivals)
+		departureTrend := linearSlope(detectedYears, departures)
+		comparison.ArrivalTrendDays = &arrivalTrend
+		comparison.DepartureTrendDays = &departureTrend
+	}
+
+	return comparison
+}
+
+// mean returns the arithmetic mean of values, which must not be empty.
+func mean(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// linearSlope returns the least-squares slope of ys over xs.
+func linearSlope(xs, ys []float64) float64 {
+	mx, my := mean(xs), mean(ys)
+	var num, den float64
+	for i := range xs {
+		num += (xs[i] - mx) * (ys[i] - my)
+		den += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if den == 0 {
+		return 0
+	}
+	return math.Round(num/den*100) / 100
+}
+
+// PhenologyCache caches per-year phenology summaries. Entries are keyed by year
+// and scope, where scope distinguishes differently filtered views of the same
+// data (for example a hub node filter). Safe for concurrent use.
+type PhenologyCache struct {
+	mu      sync.Mutex
+	entries map[phenologyCacheKey]phenologyCacheEntry
+	now     func() time.Time
+}
+
+type phenologyCacheKey struct {
+	year  int
+	scope string
+}
+
+type phenologyCacheEntry struct {
+	data      *YearPhenology
+	expiresAt time.Time
+}
+
+// NewPhenologyCache creates an empty phenology cache.
+func NewPhenologyCache() *PhenologyCache {
+	return &PhenologyCache{
+		entries: make(map[phenologyCacheKey]phenologyCacheEntry),
+		now:     time.Now,
+	}
+}
+
+// Year returns the phenology of a year, loading it from provider on a cache miss.
+// Loads are not deduplicated; concurrent misses for the same year both query the
+// database and the last result wins, which is harmless.
+func (c *PhenologyCache) Year(ctx context.Context, provider SpeciesPresenceProvider, year int, scope string) (*YearPhenology, error) {
+	key := phenologyCacheKey{year: year, scope: scope}
+	now := c.now()
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		return entry.data, nil
+	}
+
+	startDate := fmt.Sprintf("%04d-01-01", year)
+	endDate := fmt.Sprintf("%04d-12-31", year)
+	rows, err := provider.GetSpeciesDailyPresence(ctx, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	data := ComputeYearPhenology(year, rows)
+
+	ttl := phenologyPastYearTTL
+	if year >= now.Year() {
+		ttl = phenologyCurrentYearTTL
+	}
+
+	c.mu.Lock()
+	c.entries[key] = phenologyCacheEntry{data: data, expiresAt: now.Add(ttl)}
+	c.mu.Unlock()
+
+	return data, nil
+}
+
+// Invalidate drops all cached years.
+func (c *PhenologyCache) Invalidate() {
+	c.mu.Lock()
+	clear(c.entries)
+	c.mu.Unlock()
+}
+
+// GetSpeciesDailyPresence returns per-species detection counts for each day in
+// the given date range, excluding detections reviewed as false positives.
+func (ds *DataStore) GetSpeciesDailyPresence(ctx context.Context, startDate, endDate string) ([]SpeciesDailyPresence, error) {
+	if startDate != "" && endDate != "" && startDate > endDate {
+		return nil, errors.Newf("start date cannot be after end date").
+			Component("datastore").
+			Category(errors.CategoryValidation).
+			Context("operation", "get_species_daily_presence").
+			Context("start_date", startDate).
+			Context("end_date", endDate).
+			Build()
+	}
+
+	var results []SpeciesDailyPresence
+	query := ds.DB.WithContext(ctx).Table("notes").
+		Joins("LEFT JOIN note_reviews ON notes.id = note_reviews.note_id").
+		Select("notes.scientific_name, MAX(notes.common_name) as common_name, notes.date, COUNT(*) as count").
+		Where("(note_reviews.verified IS NULL OR note_reviews.verified != ?)", string(entities.VerificationFalsePositive)).
+		Where("notes.date != '' AND notes.date IS NOT NULL").
+		Group("notes.scientific_name, notes.date").
+		Order("notes.date, notes.scientific_name")
+
+	if startDate != "" {
+		query = query.Where("notes.date >= ?", startDate)
+	}
+	if endDate != "" {
+		query = query.Where("notes.date <= ?", endDate)
+	}
+
+	if err := query.Scan(&results).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "get_species_daily_presence").
+			Context("start_date", startDate).
+			Context("end_date", endDate).
+			Build()
+	}
+
+	return results, nil
+}
@@ -0,0 +1,156 @@
+// phenology_test.go: Tests for phenology analytics
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore/entities"
+)
+
+func presence(species, date string, count int) SpeciesDailyPresence {
+	return SpeciesDailyPresence{ScientificName: species, CommonName: species, Date: date, Count: count}
+}
+
+func TestComputeYearPhenology(t *testing.T) {
+	t.Parallel()
+
+	rows := []SpeciesDailyPresence{
+		presence("Phylloscopus collybita", "2024-03-20", 3),
+		presence("Phylloscopus collybita", "2024-03-21", 1),
+		presence("Phylloscopus collybita", "2024-09-30", 2),
+		presence("Turdus merula", "2024-01-01", 5),
+		presence("Turdus merula", "2024-03-21", 2),
+		presence("Turdus merula", "2023-12-31", 9), // other year, ignored
+		presence("Turdus merula", "not-a-date", 1), // ignored
+	}
+
+	result := ComputeYearPhenology(2024, rows)
+	assert.Equal(t, 2024, result.Year)
+	assert.Equal(t, 4, result.ActiveDays)
+	require.Len(t, result.Species, 2)
+
+	// Sorted by arrival
+	blackbird := result.Species[0]
+	assert.Equal(t, "Turdus merula", blackbird.ScientificName)
+	assert.Equal(t, "2024-01-01", blackbird.FirstDetection)
+	assert.Equal(t, 7, blackbird.TotalDetections)
+
+	chiffchaff := result.Species[1]
+	assert.Equal(t, "2024-03-20", chiffchaff.FirstDetection)
+	assert.Equal(t, "2024-09-30", chiffchaff.LastDetection)
+	assert.Equal(t, 80, chiffchaff.FirstDayOfYear)
+	assert.Equal(t, 274, chiffchaff.LastDayOfYear)
+	assert.Equal(t, 195, chiffchaff.SeasonLengthDays)
+	assert.Equal(t, 3, chiffchaff.DetectionDays)
+	assert.Equal(t, 6, chiffchaff.TotalDetections)
+	assert.InDelta(t, 0.75, chiffchaff.DetectionDayRatio, 1e-9)
+	assert.InDelta(t, 1.0, chiffchaff.SeasonDayRatio, 1e-9, "every active day within the season had a detection")
+
+	require.Len(t, chiffchaff.WeeklyPresence, PhenologyWeeks)
+	assert.Equal(t, 2, chiffchaff.WeeklyPresence[11], "March 20-21 fall in week 12")
+	assert.Equal(t, 1, chiffchaff.WeeklyPresence[39])
+	assert.Equal(t, 1, result.ActiveWeeks[0])
+}
+
+func TestComparePhenology(t *testing.T) {
+	t.Parallel()
+
+	years := []*YearPhenology{
+		ComputeYearPhenology(2022, []SpeciesDailyPresence{presence("Phylloscopus collybita", "2022-03-25", 1), presence("Phylloscopus collybita", "2022-10-05", 1)}),
+		ComputeYearPhenology(2023, []SpeciesDailyPresence{presence("Turdus merula", "2023-03-01", 1)}),
+		ComputeYearPhenology(2024, []SpeciesDailyPresence{presence("Phylloscopus collybita", "2024-03-15", 1), presence("Phylloscopus collybita", "2024-10-01", 1)}),
+	}
+
+	comparison := ComparePhenology("phylloscopus collybita", years)
+	assert.Equal(t, "Phylloscopus collybita", comparison.ScientificName)
+	require.Len(t, comparison.Years, 3)
+	assert.True(t, comparison.Years[0].Detected)
+	assert.False(t, comparison.Years[1].Detected)
+	assert.Nil(t, comparison.Years[1].ArrivalShiftDays)
+
+	// 2022-03-25 is day 84, 2024-03-15 is day 75 (leap year)
+	require.NotNil(t, comparison.MeanArrivalDay)
+	assert.InDelta(t, 79.5, *comparison.MeanArrivalDay, 1e-9)
+	assert.InDelta(t, 4.5, *comparison.Years[0].ArrivalShiftDays, 1e-9)
+	assert.InDelta(t, -4.5, *comparison.Years[2].ArrivalShiftDays, 1e-9)
+	require.NotNil(t, comparison.ArrivalTrendDays)
+	assert.InDelta(t, -4.5, *comparison.ArrivalTrendDays, 1e-9)
+
+	missing := ComparePhenology("Strix aluco", years)
+	assert.Nil(t, missing.MeanArrivalDay)
+	assert.Len(t, missing.Years, 3)
+}
+
+// countingPresenceProvider counts loads to verify caching.
+type countingPresenceProvider struct {
+	calls int
+}
+
+func (p *countingPresenceProvider) GetSpeciesDailyPresence(_ context.Context, startDate, _ string) ([]SpeciesDailyPresence, error) {
+	p.calls++
+	return []SpeciesDailyPresence{presence("Turdus merula", startDate, 1)}, nil
+}
+
+func TestPhenologyCache(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	cache := NewPhenologyCache()
+	cache.now = func() time.Time { return now }
+	provider := &countingPresenceProvider{}
+
+	for range 2 {
+		data, err := cache.Year(t.Context(), provider, 2023, "")
+		require.NoError(t, err)
+		assert.Equal(t, 1, data.ActiveDays)
+	}
+	assert.Equal(t, 1, provider.calls, "second lookup is served from cache")
+
+	_, err := cache.Year(t.Context(), provider, 2023, "north")
+	require.NoError(t, err)
+	assert.Equal(t, 2, provider.calls, "scopes are cached separately")
+
+	// The current year expires quickly, past years do not
+	_, err = cache.Year(t.Context(), provider, 2024, "")
+	require.NoError(t, err)
+	now = now.Add(phenologyCurrentYearTTL + time.Minute)
+	_, err = cache.Year(t.Context(), provider, 2024, "")
+	require.NoError(t, err)
+	_, err = cache.Year(t.Context(), provider, 2023, "")
+	require.NoError(t, err)
+	assert.Equal(t, 4, provider.calls)
+
+	cache.Invalidate()
+	_, err = cache.Year(t.Context(), provider, 2023, "")
+	require.NoError(t, err)
+	assert.Equal(t, 5, provider.calls)
+}
+
+func TestGetSpeciesDailyPresence(t *testing.T) {
+	t.Parallel()
+
+	ds := setupTestDB(t)
+	notes := []Note{
+		{ID: 1, Date: "2024-04-01", Time: "06:00:00", ScientificName: "Phylloscopus collybita", CommonName: "Common Chiffchaff", Confidence: 0.9},
+		{ID: 2, Date: "2024-04-01", Time: "07:00:00", ScientificName: "Phylloscopus collybita", CommonName: "Common Chiffchaff", Confidence: 0.8},
+		{ID: 3, Date: "2024-04-02", Time: "06:00:00", ScientificName: "Phylloscopus collybita", CommonName: "Common Chiffchaff", Confidence: 0.9},
+		{ID: 4, Date: "2024-04-02", Time: "08:00:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.9},
+		{ID: 5, Date: "2025-01-01", Time: "08:00:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.9},
+	}
+	require.NoError(t, ds.DB.Create(&notes).Error)
+	require.NoError(t, ds.DB.Create(&NoteReview{NoteID: 4, Verified: string(entities.VerificationFalsePositive)}).Error)
+
+	rows, err := ds.GetSpeciesDailyPresence(t.Context(), "2024-01-01", "2024-12-31")
+	require.NoError(t, err)
+	assert.Equal(t, []SpeciesDailyPresence{
+		{ScientificName: "Phylloscopus collybita", CommonName: "Common Chiffchaff", Date: "2024-04-01", Count: 2},
+		{ScientificName: "Phylloscopus collybita", CommonName: "Common Chiffchaff", Date: "2024-04-02", Count: 1},
+	}, rows, "false positives and other years are excluded")
+
+	_, err = ds.GetSpeciesDailyPresence(t.Context(), "2024-12-31", "2024-01-01")
+	require.Error(t, err)
+}
@@ -21,6 +21,9 @@ type AdvancedSearchFilters struct {
 	Species       []string
 	Location      []string // Maps to source_node column
 	Locked        *bool
+	Tags          []string // Detection tags (v2 schema only)
+	TagsMatchAll  bool     // Require all Tags instead of any
+	CommentQuery  string   // Full-text search over comments
 	SortAscending bool
 	SortBy        string // "date_desc", "date_asc", "species_asc", "confidence_desc", "status"
 	Limit         int
@@ -105,6 +108,10 @@ func (ds *DataStore) SearchNotesAdvanced(filters *AdvancedSearchFilters) ([]Note
 	// Apply locked filter
 	query = applyLockedFilter(query, filters.Locked)
 
+	// Apply tag and comment filters
+	query = applyTagFilter(query, filters.Tags)
+	query = applyCommentQueryFilter(query, filters.CommentQuery)
+
 	// Apply MinID filter for cursor-based pagination (used by migration worker)
 	if filters.MinID > 0 {
 		query = query.Where("id > ?", filters.MinID)
@@ -327,3 +334,22 @@ func applyLockedFilter(query *gorm.DB, locked *bool) *gorm.DB {
 	return query.Joins("LEFT JOIN note_locks ON note_locks.note_id = notes.id").
 		Where("note_locks.id IS NULL")
 }
+
+// applyTagFilter applies the detection tag filter.
+// The legacy schema has no tag storage, so any tag filter matches nothing.
+func applyTagFilter(query *gorm.DB, tags []string) *gorm.DB {
+	if len(tags) == 0 {
+		return query
+	}
+	return query.Where("1 = 0")
+}
+
+// applyCommentQueryFilter restricts results to notes with a comment containing text.
+func applyCommentQueryFilter(query *gorm.DB, text string) *gorm.DB {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return query
+	}
+	return query.Where("EXISTS (SELECT 1 FROM note_comments WHERE note_comments.note_id = notes.id AND note_comments.entry LIKE ?)",
+		"%"+text+"%")
+}
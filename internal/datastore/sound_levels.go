@@ -0,0 +1,364 @@
+// sound_levels.go: Sound level history records, band encoding and noise profiles
+package datastore
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Noise profile defaults and limits
+const (
+	DefaultNoiseProfileBucketMinutes = 15
+	// soundLevelBandEncodingVersion is the first byte of encoded band levels.
+	soundLevelBandEncodingVersion = 1
+	// soundLevelBandSize is the encoded size of one band: float32 center
+	// frequency and int16 Leq, Lmin and Lmax in hundredths of a dB.
+	soundLevelBandSize = 10
+	// soundLevelFloorDB is the lowest level stored; processors report -100 dB
+	// for silent bands.
+	soundLevelFloorDB = -100.0
+)
+
+// SoundLevelBand holds the levels of one 1/3-octave band over an interval, in dB.
+type SoundLevelBand struct {
+	CenterFreq float64 `json:"center_frequency_hz"`
+	Leq        float64 `json:"leq_db"`
+	Min        float64 `json:"min_db"`
+	Max        float64 `json:"max_db"`
+}
+
+// SoundLevelRecord is a stored sound level measurement of one audio source.
+type SoundLevelRecord struct {
+	// SourceURI is the sanitized source identifier, matching detection sources.
+	SourceURI  string    `json:"source"`
+	SourceName string    `json:"source_name,omitempty"`
+	NodeName   string    `json:"node_name,omitempty"`
+	Start      time.Time `json:"start"`
+	// Duration is the measured time in seconds; Resolution is the span of the
+	// record, which is larger than Duration for downsampled records with gaps.
+	Duration   int              `json:"duration_seconds"`
+	Resolution int              `json:"resolution_seconds"`
+	Leq        float64          `json:"leq_db"` // broadband equivalent level
+	Bands      []SoundLevelBand `json:"bands"`
+}
+
+// SoundLevelQuery selects stored sound level records.
+type SoundLevelQuery struct {
+	Source string // source URI or display name, empty for all sources
+	Start  time.Time
+	End    time.Time // exclusive
+}
+
+// SoundLevelHistoryStore is implemented by datastores that persist sound level
+// measurements. It is optional: the API checks for it with a type assertion.
+type SoundLevelHistoryStore interface {
+	// SaveSoundLevels stores measurements, creating unknown sources.
+	SaveSoundLevels(ctx context.Context, records []SoundLevelRecord) error
+	// GetSoundLevels returns records starting within the query range, ordered by source and time.
+	GetSoundLevels(ctx context.Context, query SoundLevelQuery) ([]SoundLevelRecord, error)
+	// GetDetectionSoundLevel returns the record covering a detection, or nil if none exists.
+	GetDetectionSoundLevel(ctx context.Context, detectionID uint) (*SoundLevelRecord, error)
+	// DownsampleSoundLevels merges records finer than resolution that start before
+	// the cutoff into records of resolution, returning the number of records removed.
+	DownsampleSoundLevels(ctx context.Context, before time.Time, resolution time.Duration) (int64, error)
+	// PruneSoundLevels deletes records starting before the cutoff.
+	PruneSoundLevels(ctx context.Context, before time.Time) (int64, error)
+}
+
+// EncodeSoundLevelBands packs band levels into a compact binary form, rounding
+// levels to hundredths of a dB.
+func EncodeSoundLevelBands(bands []SoundLevelBand) []byte {
+	buf := make([]byte, 1, 1+len(bands)*soundLevelBandSize)
+	buf[0] = soundLevelBandEncodingVersion
+	for i := range bands {
+		b := &bands[i]
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(b.CenterFreq)))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(encodeLevel(b.Leq)))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(encodeLevel(b.Min)))
+		buf = binary.LittleEndian.AppendUint16(buf, uint16(encodeLevel(b.Max)))
+	}
+	return buf
+}
+
+// DecodeSoundLevelBands unpacks band levels encoded by EncodeSoundLevelBands.
+func DecodeSoundLevelBands(data []byte) ([]SoundLevelBand, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	if data[0] != soundLevelBandEncodingVersion {
+		return nil, fmt.Errorf("unsupported sound level band encoding version %d", data[0])
+	}
+	payload := data[1:]
+	if len(payload)%soundLevelBandSize != 0 {
+		return nil, fmt.Errorf("invalid sound level band data length %d", len(data))
+	}
+	bands := make([]SoundLevelBand, 0, len(payload)/soundLevelBandSize)
+	for off := 0; off < len(payload); off += soundLevelBandSize {
+		bands = append(bands, SoundLevelBand{
+			CenterFreq: float64(math.Float32frombits(binary.LittleEndian.Uint32(payload[off:]))),
+			Leq:        decodeLevel(binary.LittleEndian.Uint16(payload[off+4:])),
+			Min:        decodeLevel(binary.LittleEndian.Uint16(payload[off+6:])),
+			Max:        decodeLevel(binary.LittleEndian.Uint16(payload[off+8:])),
+		})
+	}
+	return bands, nil
+}
+
+// encodeLevel converts a level to hundredths of a dB, clamped to the int16 range.
+func encodeLevel(db float64) int16 {
+	if math.IsNaN(db) || db < soundLevelFloorDB {
+		db = soundLevelFloorDB
+	}
+	return int16(max(min(math.Round(db*100), math.MaxInt16), math.MinInt16))
+}
+
+func decodeLevel(v uint16) float64 {
+	return float64(int16(v)) / 100
+}
+
+// BroadbandLeq returns the energy sum of band equivalent levels, in dB.
+func BroadbandLeq(bands []SoundLevelBand) float64 {
+	var energy float64
+	for i := range bands {
+		energy += math.Pow(10, bands[i].Leq/10)
+	}
+	if energy == 0 {
+		return soundLevelFloorDB
+	}
+	return roundDB(10 * math.Log10(energy))
+}
+
+// energyMean returns the energy average of levels in dB weighted by weights.
+func energyMean(levels []float64, weights []float64) float64 {
+	var energy, total float64
+	for i, level := range levels {
+		energy += math.Pow(10, level/10) * weights[i]
+		total += weights[i]
+	}
+	if total == 0 {
+		return soundLevelFloorDB
+	}
+	return 10 * math.Log10(energy/total)
+}
+
+func roundDB(db float64) float64 {
+	return math.Round(db*100) / 100
+}
+
+// MergeSoundLevels combines records of one source into a single record
+// starting at start and spanning resolution. Band Leq is the duration-weighted
+// energy average; Lmin and Lmax are the extremes of the merged records.
+func MergeSoundLevels(records []SoundLevelRecord, start time.Time, resolution time.Duration) SoundLevelRecord {
+	merged := SoundLevelRecord{
+		Start:      start,
+		Resolution: int(resolution / time.Second),
+	}
+	type bandAccumulator struct {
+		levels, weights []float64
+		min, max        float64
+	}
+	bands := make(map[float64]*bandAccumulator)
+	for i := range records {
+		r := &records[i]
+		if merged.SourceURI == "" {
+			merged.SourceURI, merged.SourceName, merged.NodeName = r.SourceURI, r.SourceName, r.NodeName
+		}
+		merged.Duration += r.Duration
+		weight := float64(max(r.Duration, 1))
+		for _, b := range r.Bands {
+			acc, ok := bands[b.CenterFreq]
+			if !ok {
+				acc = &bandAccumulator{min: b.Min, max: b.Max}
+				bands[b.CenterFreq] = acc
+			}
+			acc.levels = append(acc.levels, b.Leq)
+			acc.weights = append(acc.weights, weight)
+			acc.min = min(acc.min, b.Min)
+			acc.max = max(acc.max, b.Max)
+		}
+	}
+
+	merged.Bands = make([]SoundLevelBand, 0, len(bands))
+	for freq, acc := range bands {
+		merged.Bands = append(merged.Bands, SoundLevelBand{
+			CenterFreq: freq,
+			Leq:        roundDB(energyMean(acc.levels, acc.weights)),
+			Min:        acc.min,
+			Max:        acc.max,
+		})
+	}
+	slices.SortFunc(merged.Bands, func(a, b SoundLevelBand) int {
+		return compareFloat(a.CenterFreq, b.CenterFreq)
+	})
+	merged.Leq = BroadbandLeq(merged.Bands)
+	return merged
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NoiseProfileBand is the equivalent level of one band over a profile bucket.
+type NoiseProfileBand struct {
+	CenterFreq float64 `json:"center_frequency_hz"`
+	Leq        float64 `json:"leq_db"`
+}
+
+// NoiseProfileBucket summarizes the records starting within one time-of-day bucket.
+// L10, L50 and L90 are the broadband levels exceeded 10, 50 and 90 percent of
+// the measured time: L90 approximates the background, L10 the intermittent noise.
+type NoiseProfileBucket struct {
+	Time     string             `json:"time"` // HH:MM bucket start
+	Duration int                `json:"duration_seconds"`
+	Leq      float64            `json:"leq_db"`
+	Min      float64            `json:"min_db"`
+	Max      float64            `json:"max_db"`
+	L10      float64            `json:"l10_db"`
+	L50      float64            `json:"l50_db"`
+	L90      float64            `json:"l90_db"`
+	Bands    []NoiseProfileBand `json:"bands"`
+}
+
+// NoiseProfile is the daily noise profile of one audio source.
+type NoiseProfile struct {
+	Date          string               `json:"date"`
+	Source        string               `json:"source"`
+	SourceName    string               `json:"source_name,omitempty"`
+	BucketMinutes int                  `json:"bucket_minutes"`
+	Duration      int                  `json:"duration_seconds"`
+	Leq           float64              `json:"leq_db"` // whole-day equivalent level
+	L90           float64              `json:"l90_db"`
+	Buckets       []NoiseProfileBucket `json:"buckets"`
+}
+
+// ComputeNoiseProfiles groups records by source and local date in loc and
+// summarizes each day in buckets of bucketMinutes. Buckets without records
+// are omitted. Profiles are ordered by source and date.
+func ComputeNoiseProfiles(records []SoundLevelRecord, loc *time.Location, bucketMinutes int) []NoiseProfile {
+	if bucketMinutes <= 0 {
+		bucketMinutes = DefaultNoiseProfileBucketMinutes
+	}
+
+	type dayKey struct{ source, date string }
+	days := make(map[dayKey][]SoundLevelRecord)
+	for i := range records {
+		key := dayKey{records[i].SourceURI, records[i].Start.In(loc).Format(time.DateOnly)}
+		days[key] = append(days[key], records[i])
+	}
+
+	profiles := make([]NoiseProfile, 0, len(days))
+	for key, dayRecords := range days {
+		profile := NoiseProfile{
+			Date:          key.date,
+			Source:        key.source,
+			SourceName:    dayRecords[0].SourceName,
+			BucketMinutes: bucketMinutes,
+		}
+
+		buckets := make(map[int][]SoundLevelRecord)
+		for i := range dayRecords {
+			local := dayRecords[i].Start.In(loc)
+			minute := local.Hour()*60 + local.Minute()
+			bucket := minute / bucketMinutes * bucketMinutes
+			buckets[bucket] = append(buckets[bucket], dayRecords[i])
+		}
+		starts := make([]int, 0, len(buckets))
+		for start := range buckets {
+			starts = append(starts, start)
+		}
+		sort.Ints(starts)
+
+		profile.Buckets = make([]NoiseProfileBucket, 0, len(starts))
+		for _, start := range starts {
+			b := summarizeNoiseBucket(buckets[start])
+			b.Time = fmt.Sprintf("%02d:%02d", start/60, start%60)
+			profile.Buckets = append(profile.Buckets, b)
+		}
+
+		summary := summarizeNoiseBucket(dayRecords)
+		profile.Duration = summary.Duration
+		profile.Leq = summary.Leq
+		profile.L90 = summary.L90
+		profiles = append(profiles, profile)
+	}
+
+	slices.SortFunc(profiles, func(a, b NoiseProfile) int {
+		if c := strings.Compare(a.Source, b.Source); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Date, b.Date)
+	})
+	return profiles
+}
+
+// summarizeNoiseBucket computes the levels and statistics of a set of records.
+func summarizeNoiseBucket(records []SoundLevelRecord) NoiseProfileBucket {
+	levels := make([]float64, len(records))
+	weights := make([]float64, len(records))
+	bucket := NoiseProfileBucket{Min: math.Inf(1), Max: math.Inf(-1)}
+	bandLevels := make(map[float64][]float64)
+	bandWeights := make(map[float64][]float64)
+	for i := range records {
+		r := &records[i]
+		levels[i] = r.Leq
+		weights[i] = float64(max(r.Duration, 1))
+		bucket.Duration += r.Duration
+		bucket.Min = min(bucket.Min, r.Leq)
+		bucket.Max = max(bucket.Max, r.Leq)
+		for _, b := range r.Bands {
+			bandLevels[b.CenterFreq] = append(bandLevels[b.CenterFreq], b.Leq)
+			bandWeights[b.CenterFreq] = append(bandWeights[b.CenterFreq], weights[i])
+		}
+	}
+
+	bucket.Leq = roundDB(energyMean(levels, weights))
+	bucket.L10 = roundDB(exceededLevel(levels, weights, 0.10))
+	bucket.L50 = roundDB(exceededLevel(levels, weights, 0.50))
+	bucket.L90 = roundDB(exceededLevel(levels, weights, 0.90))
+
+	bucket.Bands = make([]NoiseProfileBand, 0, len(bandLevels))
+	for freq, bl := range bandLevels {
+		bucket.Bands = append(bucket.Bands, NoiseProfileBand{
+			CenterFreq: freq,
+			Leq:        roundDB(energyMean(bl, bandWeights[freq])),
+		})
+	}
+	slices.SortFunc(bucket.Bands, func(a, b NoiseProfileBand) int {
+		return compareFloat(a.CenterFreq, b.CenterFreq)
+	})
+	return bucket
+}
+
+// exceededLevel returns the level exceeded for the given fraction of the
+// weighted time, e.g. 0.9 for L90.
+func exceededLevel(levels, weights []float64, fraction float64) float64 {
+	idx := make([]int, len(levels))
+	var total float64
+	for i := range idx {
+		idx[i] = i
+		total += weights[i]
+	}
+	// Loudest first: walk down until the fraction of time is covered
+	slices.SortFunc(idx, func(a, b int) int { return compareFloat(levels[b], levels[a]) })
+	var covered float64
+	for _, i := range idx {
+		covered += weights[i]
+		if covered >= fraction*total {
+			return levels[i]
+		}
+	}
+	return levels[idx[len(idx)-1]]
+}
@@ -0,0 +1,133 @@
+// sound_levels_test.go: Tests for sound level encoding, downsampling and noise profiles
+package datastore
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSoundLevelBandEncoding(t *testing.T) {
+	t.Parallel()
+
+	bands := []SoundLevelBand{
+		{CenterFreq: 31.5, Leq: -42.123, Min: -50, Max: -30.5},
+		{CenterFreq: 1000, Leq: -12.345, Min: -20, Max: -5},
+		{CenterFreq: 20000, Leq: math.NaN(), Min: -250, Max: 400},
+	}
+
+	data := EncodeSoundLevelBands(bands)
+	assert.Len(t, data, 1+3*soundLevelBandSize)
+
+	decoded, err := DecodeSoundLevelBands(data)
+	require.NoError(t, err)
+	require.Len(t, decoded, 3)
+	assert.InDelta(t, 31.5, decoded[0].CenterFreq, 0.001)
+	assert.InDelta(t, -42.12, decoded[0].Leq, 0.001, "levels are stored in hundredths of a dB")
+	assert.InDelta(t, -30.5, decoded[0].Max, 0.001)
+	assert.InDelta(t, -12.35, decoded[1].Leq, 0.001)
+	assert.InDelta(t, -100.0, decoded[2].Leq, 0.001, "NaN is stored as the floor level")
+	assert.InDelta(t, -100.0, decoded[2].Min, 0.001)
+	assert.InDelta(t, 327.67, decoded[2].Max, 0.001, "levels are clamped to the encodable range")
+
+	empty, err := DecodeSoundLevelBands(nil)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	_, err = DecodeSoundLevelBands([]byte{9})
+	require.Error(t, err, "unknown version")
+	_, err = DecodeSoundLevelBands(data[:5])
+	require.Error(t, err, "truncated data")
+}
+
+func TestBroadbandLeq(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, -36.99, BroadbandLeq([]SoundLevelBand{{Leq: -40}, {Leq: -40}}), 0.01, "two equal bands add 3 dB")
+	assert.InDelta(t, -100.0, BroadbandLeq(nil), 0.001)
+}
+
+func TestMergeSoundLevels(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2024, 5, 1, 4, 10, 0, 0, time.UTC)
+	records := []SoundLevelRecord{
+		{SourceURI: "hw:1,0", SourceName: "Garden", Start: start, Duration: 10, Bands: []SoundLevelBand{
+			{CenterFreq: 1000, Leq: -40, Min: -45, Max: -35},
+			{CenterFreq: 100, Leq: -50, Min: -55, Max: -45},
+		}},
+		{SourceURI: "hw:1,0", Start: start.Add(10 * time.Second), Duration: 10, Bands: []SoundLevelBand{
+			{CenterFreq: 1000, Leq: -20, Min: -30, Max: -10},
+		}},
+	}
+
+	merged := MergeSoundLevels(records, start, 5*time.Minute)
+	assert.Equal(t, "hw:1,0", merged.SourceURI)
+	assert.Equal(t, "Garden", merged.SourceName)
+	assert.Equal(t, 20, merged.Duration)
+	assert.Equal(t, 300, merged.Resolution)
+	require.Len(t, merged.Bands, 2)
+	assert.InDelta(t, 100.0, merged.Bands[0].CenterFreq, 0.001, "bands are ordered by frequency")
+	assert.InDelta(t, -50.0, merged.Bands[0].Leq, 0.001)
+	loud := merged.Bands[1]
+	assert.InDelta(t, -22.97, loud.Leq, 0.01, "energy average is dominated by the louder interval")
+	assert.InDelta(t, -45.0, loud.Min, 0.001)
+	assert.InDelta(t, -10.0, loud.Max, 0.001)
+	assert.InDelta(t, BroadbandLeq(merged.Bands), merged.Leq, 0.001)
+}
+
+func TestComputeNoiseProfiles(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	record := func(source string, offset time.Duration, leq float64) SoundLevelRecord {
+		return SoundLevelRecord{
+			SourceURI: source, Start: day.Add(offset), Duration: 10, Leq: leq,
+			Bands: []SoundLevelBand{{CenterFreq: 1000, Leq: leq}},
+		}
+	}
+
+	var records []SoundLevelRecord
+	// Ten measurements at 07:00-07:01: one loud truck among quiet background
+	for i := range 10 {
+		leq := -60.0 + float64(i)
+		if i == 9 {
+			leq = -20
+		}
+		records = append(records, record("hw:1,0", 7*time.Hour+time.Duration(i*10)*time.Second, leq))
+	}
+	records = append(records,
+		record("hw:1,0", 7*time.Hour+20*time.Minute, -45),
+		record("hw:1,0", 24*time.Hour+time.Hour, -50), // next day
+		record("rtsp://cam", 7*time.Hour, -30),
+	)
+
+	profiles := ComputeNoiseProfiles(records, time.UTC, 15)
+	require.Len(t, profiles, 3)
+	assert.Equal(t, "hw:1,0", profiles[0].Source)
+	assert.Equal(t, "2024-05-01", profiles[0].Date)
+	assert.Equal(t, "2024-05-02", profiles[1].Date)
+	assert.Equal(t, "rtsp://cam", profiles[2].Source)
+
+	morning := profiles[0]
+	assert.Equal(t, 15, morning.BucketMinutes)
+	assert.Equal(t, 110, morning.Duration)
+	require.Len(t, morning.Buckets, 2)
+
+	rush := morning.Buckets[0]
+	assert.Equal(t, "07:00", rush.Time)
+	assert.Equal(t, 100, rush.Duration)
+	assert.InDelta(t, -60.0, rush.Min, 0.001)
+	assert.InDelta(t, -20.0, rush.Max, 0.001)
+	assert.InDelta(t, -30.0, rush.Leq, 0.05, "the truck dominates the equivalent level")
+	assert.InDelta(t, -20.0, rush.L10, 0.001)
+	assert.InDelta(t, -55.0, rush.L50, 0.001)
+	assert.InDelta(t, -59.0, rush.L90, 0.001, "L90 reflects the background")
+	require.Len(t, rush.Bands, 1)
+	assert.InDelta(t, rush.Leq, rush.Bands[0].Leq, 0.001)
+
+	assert.Equal(t, "07:15", morning.Buckets[1].Time)
+}
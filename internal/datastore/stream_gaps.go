@@ -0,0 +1,29 @@
+// stream_gaps.go: Stream gap records
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+// StreamGapRecord is a span of audio an audio stream did not deliver, either
+// because it stalled or because it reconnected.
+type StreamGapRecord struct {
+	// SourceURI is the sanitized source identifier, matching detections.
+	SourceURI  string        `json:"source"`
+	SourceName string        `json:"source_name,omitempty"`
+	NodeName   string        `json:"node_name,omitempty"`
+	Start      time.Time     `json:"start"`
+	Duration   time.Duration `json:"duration"`
+	Reason     string        `json:"reason"` // "stall" or "restart"
+}
+
+// StreamGapStore is implemented by datastores that persist stream gaps. Like
+// SoundLevelHistoryStore it is optional and checked with a type assertion.
+type StreamGapStore interface {
+	// SaveStreamGaps stores gaps, creating unknown sources.
+	SaveStreamGaps(ctx context.Context, records []StreamGapRecord) error
+	// GetStreamGaps returns gaps starting within the range, ordered by start.
+	// An empty source matches all sources.
+	GetStreamGaps(ctx context.Context, source string, start, end time.Time) ([]StreamGapRecord, error)
+}
@@ -0,0 +1,461 @@
+// sun_activity.go: detection activity relative to sunrise, sunset and civil twilight
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore/entities"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+// Sun events that activity can be measured against.
+const (
+	SunEventCivilDawn = "civil_dawn"
+	SunEventSunrise   = "sunrise"
+	SunEventSunset    = "sunset"
+	SunEventCivilDusk = "civil_dusk"
+)
+
+// Defaults for sun-relative activity analysis.
+const (
+	DefaultSunActivityBucketMinutes = 15
+	DefaultSunActivityWindowMinutes = 240
+	DefaultDawnChorusOnsetSpecies   = 3
+
+	// dawnChorusSearchBefore and dawnChorusSearchAfter bound the onset search
+	// around sunrise, in minutes.
+	dawnChorusSearchBefore = 120
+	dawnChorusSearchAfter  = 180
+	// dawnChorusOnsetWindow is the rolling window, in minutes, in which
+	// OnsetSpecies distinct species must be detected.
+	dawnChorusOnsetWindow = 15
+)
+
+// DetectionMinuteCount is the number of detections of a species in one minute.
+type DetectionMinuteCount struct {
+	ScientificName string
+	CommonName     string
+	Date           string // YYYY-MM-DD, local time
+	Minute         string // HH:MM, local time
+	Count          int
+}
+
+// DetectionMinuteProvider is implemented by datastores that can report
+// per-minute detection counts. Both the legacy and the v2 datastores implement
+// it; call it via type assertion on Interface.
+type DetectionMinuteProvider interface {
+	// GetDetectionMinuteCounts returns detection counts per species, day and minute
+	// of day in the given date range, excluding detections reviewed as false
+	// positives. An empty species matches all species.
+	GetDetectionMinuteCounts(ctx context.Context, startDate, endDate, species string) ([]DetectionMinuteCount, error)
+}
+
+// SunTimesFunc returns the local sun event times of a date.
+type SunTimesFunc func(date time.Time) (suncalc.SunEventTimes, error)
+
+// SunActivityOptions configures ComputeSunRelativeActivity.
+type SunActivityOptions struct {
+	Event         string // one of the SunEvent constants
+	BucketMinutes int    // histogram bucket width
+	WindowMinutes int    // histogram covers [-WindowMinutes, +WindowMinutes) around the event
+	OnsetSpecies  int    // distinct species that mark dawn chorus onset
+}
+
+// SpeciesSunActivity is the sun-relative activity of one species.
+type SpeciesSunActivity struct {
+	ScientificName string `json:"scientific_name"`
+	CommonName     string `json:"common_name"`
+	Total          int    `json:"total"`
+	InWindow       int    `json:"in_window"`
+	Buckets        []int  `json:"buckets"`
+	// MedianOffsetMinutes is the median detection offset from the event within the window.
+	MedianOffsetMinutes *int                 `json:"median_offset_minutes,omitempty"`
+	Monthly             []MonthlySunActivity `json:"monthly"`
+}
+
+// MonthlySunActivity summarizes sun-relative activity within one month.
+type MonthlySunActivity struct {
+	Month               string `json:"month"` // YYYY-MM
+	Count               int    `json:"count"`
+	MedianOffsetMinutes int    `json:"median_offset_minutes"`
+}
+
+// DawnChorusDay describes the morning chorus of one day.
+type DawnChorusDay struct {
+	Date      string `json:"date"`
+	CivilDawn string `json:"civil_dawn"` // HH:MM local time
+	Sunrise   string `json:"sunrise"`    // HH:MM local time
+	// Onset is the first minute at which OnsetSpecies distinct species were
+	// detected within a rolling window. Empty if the threshold was not reached.
+	Onset                    string `json:"onset,omitempty"`
+	OnsetRelativeToSunrise   *int   `json:"onset_relative_to_sunrise,omitempty"`
+	OnsetRelativeToCivilDawn *int   `json:"onset_relative_to_civil_dawn,omitempty"`
+	FirstDetection           string `json:"first_detection,omitempty"` // first morning detection, HH:MM
+	FirstSpecies             string `json:"first_species,omitempty"`
+	MorningSpecies           int    `json:"morning_species"` // distinct species in the search window
+}
+
+// SunRelativeActivity is detection activity bucketed by minutes from a sun event.
+type SunRelativeActivity struct {
+	Event         string `json:"event"`
+	BucketMinutes int    `json:"bucket_minutes"`
+	WindowMinutes int    `json:"window_minutes"`
+	// BucketOffsets holds the start offset, in minutes from the event, of each bucket.
+	BucketOffsets []int                `json:"bucket_offsets"`
+	Overall       []int                `json:"overall"`
+	Total         int                  `json:"total"`
+	OutsideWindow int                  `json:"outside_window"`
+	Species       []SpeciesSunActivity `json:"species"`
+	DawnChorus    []DawnChorusDay      `json:"dawn_chorus"`
+}
+
+// ValidSunEvent reports whether event is one of the SunEvent constants.
+func ValidSunEvent(event string) bool {
+	switch event {
+	case SunEventCivilDawn, SunEventSunrise, SunEventSunset, SunEventCivilDusk:
+		return true
+	}
+	return false
+}
+
+// sunEventTime returns the time of event from the day's sun times.
+func sunEventTime(times *suncalc.SunEventTimes, event string) time.Time {
+	switch event {
+	case SunEventCivilDawn:
+		return times.CivilDawn
+	case SunEventSunset:
+		return times.Sunset
+	case SunEventCivilDusk:
+		return times.CivilDusk
+	default:
+		return times.Sunrise
+	}
+}
+
+// parseMinuteOfDay parses HH:MM into minutes since midnight.
+func parseMinuteOfDay(minute string) (int, bool) {
+	hh, mm, ok := strings.Cut(minute, ":")
+	if !ok {
+		return 0, false
+	}
+	h, errH := strconv.Atoi(hh)
+	m, errM := strconv.Atoi(mm)
+	if errH != nil || errM != nil || h < 0 || h > 23 || m < 0 || m > 59 {
+		return 0, false
+	}
+	return h*60 + m, true
+}
+
+// weightedOffsets collects detection offsets with their counts.
+type weightedOffsets map[int]int
+
+// median returns the weighted median offset.
+func (w weightedOffsets) median() (int, bool) {
+	total := 0
+	offsets := make([]int, 0, len(w))
+	for offset, count := range w {
+		offsets = append(offsets, offset)
+		total += count
+	}
+	if total == 0 {
+		return 0, false
+	}
+	slices.Sort(offsets)
+	half := (total + 1) / 2
+	seen := 0
+	for _, offset := range offsets {
+		seen += w[offset]
+		if seen >= half {
+			return offset, true
+		}
+	}
+	return offsets[len(offsets)-1], true
+}
+
+// ComputeSunRelativeActivity buckets per-minute detection counts by their offset
+// from a sun event and derives a daily dawn chorus onset. Days for which sun
+// times cannot be calculated are skipped.
+func ComputeSunRelativeActivity(rows []DetectionMinuteCount, sunTimes SunTimesFunc, opts SunActivityOptions) *SunRelativeActivity {
+	if !ValidSunEvent(opts.Event) {
+		opts.Event = SunEventSunrise
+	}
+	if opts.BucketMinutes <= 0 {
+		opts.BucketMinutes = DefaultSunActivityBucketMinutes
+	}
+	if opts.WindowMinutes <= 0 {
+		opts.WindowMinutes = DefaultSunActivityWindowMinutes
+	}
+	// Round the window up to whole buckets
+	opts.WindowMinutes = (opts.WindowMinutes + opts.BucketMinutes - 1) / opts.BucketMinutes * opts.BucketMinutes
+	if opts.OnsetSpecies <= 0 {
+		opts.OnsetSpecies = DefaultDawnChorusOnsetSpecies
+	}
+
+	numBuckets := 2 * opts.WindowMinutes / opts.BucketMinutes
+	result := &SunRelativeActivity{
+		Event:         opts.Event,
+		BucketMinutes: opts.BucketMinutes,
+		WindowMinutes: opts.WindowMinutes,
+		BucketOffsets: make([]int, numBuckets),
+		Overall:       make([]int, numBuckets),
+		Species:       []SpeciesSunActivity{},
+		DawnChorus:    []DawnChorusDay{},
+	}
+	for i := range result.BucketOffsets {
+		result.BucketOffsets[i] = -opts.WindowMinutes + i*opts.BucketMinutes
+	}
+
+	type speciesAccumulator struct {
+		activity SpeciesSunActivity
+		offsets  weightedOffsets
+		monthly  map[string]weightedOffsets
+	}
+	species := make(map[string]*speciesAccumulator)
+
+	// Group rows by date so sun times are computed once per day
+	byDate := make(map[string][]*DetectionMinuteCount)
+	for i := range rows {
+		byDate[rows[i].Date] = append(byDate[rows[i].Date], &rows[i])
+	}
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	slices.Sort(dates)
+
+	for _, date := range dates {
+		day, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			continue
+		}
+		times, err := sunTimes(day)
+		if err != nil {
+			continue
+		}
+		event := sunEventTime(&times, opts.Event)
+
+		for _, row := range byDate[date] {
+			minuteOfDay, ok := parseMinuteOfDay(row.Minute)
+			if !ok || row.Count <= 0 {
+				continue
+			}
+			acc, ok := species[row.ScientificName]
+			if !ok {
+				acc = &speciesAccumulator{
+					activity: SpeciesSunActivity{
+						ScientificName: row.ScientificName,
+						CommonName:     row.CommonName,
+						Buckets:        make([]int, numBuckets),
+						Monthly:        []MonthlySunActivity{},
+					},
+					offsets: weightedOffsets{},
+					monthly: make(map[string]weightedOffsets),
+				}
+				species[row.ScientificName] = acc
+			}
+			acc.activity.Total += row.Count
+			result.Total += row.Count
+
+			detectedAt := atMinuteOfDay(day, minuteOfDay)
+			offset := int(detectedAt.Sub(event).Round(time.Minute) / time.Minute)
+			if offset < -opts.WindowMinutes || offset >= opts.WindowMinutes {
+				result.OutsideWindow += row.Count
+				continue
+			}
+			bucket := (offset + opts.WindowMinutes) / opts.BucketMinutes
+			result.Overall[bucket] += row.Count
+			acc.activity.Buckets[bucket] += row.Count
+			acc.activity.InWindow += row.Count
+			acc.offsets[offset] += row.Count
+
+			month := date[:7]
+			if acc.monthly[month] == nil {
+				acc.monthly[month] = weightedOffsets{}
+			}
+			acc.monthly[month][offset] += row.Count
+		}
+
+		if chorus, ok := dawnChorus(date, day, &times, byDate[date], opts.OnsetSpecies); ok {
+			result.DawnChorus = append(result.DawnChorus, chorus)
+		}
+	}
+
+	for _, acc := range species {
+		a := acc.activity
+		if median, ok := acc.offsets.median(); ok {
+			a.MedianOffsetMinutes = &median
+		}
+		for month, offsets := range acc.monthly {
+			median, _ := offsets.median()
+			count := 0
+			for _, c := range offsets {
+				count += c
+			}
+			a.Monthly = append(a.Monthly, MonthlySunActivity{Month: month, Count: count, MedianOffsetMinutes: median})
+		}
+		slices.SortFunc(a.Monthly, func(x, y MonthlySunActivity) int { return strings.Compare(x.Month, y.Month) })
+		result.Species = append(result.Species, a)
+	}
+	slices.SortFunc(result.Species, func(a, b SpeciesSunActivity) int {
+		if a.Total != b.Total {
+			return b.Total - a.Total
+		}
+		return strings.Compare(a.ScientificName, b.ScientificName)
+	})
+
+	return result
+}
+
+// ComputeDawnChorus computes the dawn chorus of each day of rows, like
+// ComputeSunRelativeActivity does. The chorus onset needs detections of all
+// species, so rows should not be limited to one species.
+func ComputeDawnChorus(rows []DetectionMinuteCount, sunTimes SunTimesFunc, onsetSpecies int) []DawnChorusDay {
+	if onsetSpecies <= 0 {
+		onsetSpecies = DefaultDawnChorusOnsetSpecies
+	}
+	byDate := make(map[string][]*DetectionMinuteCount)
+	for i := range rows {
+		byDate[rows[i].Date] = append(byDate[rows[i].Date], &rows[i])
+	}
+	dates := make([]string, 0, len(byDate))
+	for date := range byDate {
+		dates = append(dates, date)
+	}
+	slices.Sort(dates)
+
+	days := []DawnChorusDay{}
+	for _, date := range dates {
+		day, err := time.ParseInLocation(time.DateOnly, date, time.Local)
+		if err != nil {
+			continue
+		}
+		times, err := sunTimes(day)
+		if err != nil {
+			continue
+		}
+		if chorus, ok := dawnChorus(date, day, &times, byDate[date], onsetSpecies); ok {
+			days = append(days, chorus)
+		}
+	}
+	return days
+}
+
+// dawnChorus computes the dawn chorus metrics of one day. It reports false if
+// there were no detections in the morning search window.
+func dawnChorus(date string, day time.Time, times *suncalc.SunEventTimes, rows []*DetectionMinuteCount, onsetSpecies int) (DawnChorusDay, bool) {
+	sunrise := times.Sunrise.In(day.Location())
+	sunriseMinute := sunrise.Hour()*60 + sunrise.Minute()
+	searchStart := max(sunriseMinute-dawnChorusSearchBefore, 0)
+	searchEnd := min(sunriseMinute+dawnChorusSearchAfter, 24*60)
+
+	// Species detected per minute of the search window
+	perMinute := make(map[int][]string)
+	morning := make(map[string]struct{})
+	for _, row := range rows {
+		minuteOfDay, ok := parseMinuteOfDay(row.Minute)
+		if !ok || row.Count <= 0 || minuteOfDay < searchStart || minuteOfDay >= searchEnd {
+			continue
+		}
+		perMinute[minuteOfDay] = append(perMinute[minuteOfDay], row.ScientificName)
+		morning[row.ScientificName] = struct{}{}
+	}
+	if len(morning) == 0 {
+		return DawnChorusDay{}, false
+	}
+
+	chorus := DawnChorusDay{
+		Date:           date,
+		CivilDawn:      times.CivilDawn.Format("15:04"),
+		Sunrise:        times.Sunrise.Format("15:04"),
+		MorningSpecies: len(morning),
+	}
+
+	for minute := searchStart; minute < searchEnd; minute++ {
+		if first, ok := perMinute[minute]; ok && chorus.FirstDetection == "" {
+			slices.Sort(first)
+			chorus.FirstDetection = formatMinuteOfDay(minute)
+			chorus.FirstSpecies = first[0]
+		}
+		distinct := make(map[string]struct{})
+		for m := minute; m < min(minute+dawnChorusOnsetWindow, searchEnd); m++ {
+			for _, s := range perMinute[m] {
+				distinct[s] = struct{}{}
+			}
+		}
+		if len(distinct) >= onsetSpecies && len(perMinute[minute]) > 0 {
+			onset := atMinuteOfDay(day, minute)
+			relSunrise := int(onset.Sub(times.Sunrise).Round(time.Minute) / time.Minute)
+			relDawn := int(onset.Sub(times.CivilDawn).Round(time.Minute) / time.Minute)
+			chorus.Onset = formatMinuteOfDay(minute)
+			chorus.OnsetRelativeToSunrise = &relSunrise
+			chorus.OnsetRelativeToCivilDawn = &relDawn
+			break
+		}
+	}
+
+	return chorus, true
+}
+
+// atMinuteOfDay returns the wall-clock time minute minutes after midnight of day.
+// Unlike adding a duration, this stays correct on daylight saving transition days.
+func atMinuteOfDay(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+}
+
+// formatMinuteOfDay formats minutes since midnight as HH:MM.
+func formatMinuteOfDay(minute int) string {
+	return fmt.Sprintf("%02d:%02d", minute/60, minute%60)
+}
+
+// GetDetectionMinuteCounts returns detection counts per species, day and minute
+// of day, excluding detections reviewed as false positives.
+func (ds *DataStore) GetDetectionMinuteCounts(ctx context.Context, startDate, endDate, species string) ([]DetectionMinuteCount, error) {
+	if startDate != "" && endDate != "" && startDate > endDate {
+		return nil, errors.Newf("start date cannot be after end date").
+			Component("datastore").
+			Category(errors.CategoryValidation).
+			Context("operation", "get_detection_minute_counts").
+			Context("start_date", startDate).
+			Context("end_date", endDate).
+			Build()
+	}
+
+	const minuteExpr = "SUBSTR(notes.time, 1, 5)"
+	var results []DetectionMinuteCount
+	query := ds.DB.WithContext(ctx).Table("notes").
+		Joins("LEFT JOIN note_reviews ON notes.id = note_reviews.note_id").
+		Select("notes.scientific_name, MAX(notes.common_name) as common_name, notes.date, "+minuteExpr+" as minute, COUNT(*) as count").
+		Where("(note_reviews.verified IS NULL OR note_reviews.verified != ?)", string(entities.VerificationFalsePositive)).
+		Where("notes.date != '' AND notes.date IS NOT NULL").
+		Group("notes.scientific_name, notes.date, " + minuteExpr).
+		Order("notes.date")
+
+	if startDate != "" {
+		query = query.Where("notes.date >= ?", startDate)
+	}
+	if endDate != "" {
+		query = query.Where("notes.date <= ?", endDate)
+	}
+	if species != "" {
+		query = query.Where("(notes.scientific_name = ? OR notes.common_name = ?)", species, species)
+	}
+
+	if err := query.Scan(&results).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "get_detection_minute_counts").
+			Context("start_date", startDate).
+			Context("end_date", endDate).
+			Context("species", species).
+			Build()
+	}
+
+	return results, nil
+}
@@ -0,0 +1,148 @@
+// sun_activity_test.go: Tests for sun-relative activity analytics
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+// fixedSunTimes returns sun times with sunrise at 05:00 and sunset at 21:00 on
+// every day, and civil dawn and dusk 40 minutes outside them.
+func fixedSunTimes(date time.Time) (suncalc.SunEventTimes, error) {
+	at := func(h, m int) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), h, m, 0, 0, date.Location())
+	}
+	return suncalc.SunEventTimes{
+		CivilDawn: at(4, 20),
+		Sunrise:   at(5, 0),
+		Sunset:    at(21, 0),
+		CivilDusk: at(21, 40),
+	}, nil
+}
+
+func minuteCount(species, date, minute string, count int) DetectionMinuteCount {
+	return DetectionMinuteCount{ScientificName: species, CommonName: species, Date: date, Minute: minute, Count: count}
+}
+
+func TestComputeSunRelativeActivity(t *testing.T) {
+	t.Parallel()
+
+	rows := []DetectionMinuteCount{
+		minuteCount("Turdus merula", "2024-05-01", "04:10", 2),          // -50 min
+		minuteCount("Turdus merula", "2024-05-01", "05:20", 1),          // +20 min
+		minuteCount("Turdus merula", "2024-06-01", "04:55", 1),          // -5 min
+		minuteCount("Erithacus rubecula", "2024-05-01", "04:15", 1),     // -45 min
+		minuteCount("Fringilla coelebs", "2024-05-01", "04:20", 1),      // -40 min
+		minuteCount("Strix aluco", "2024-05-01", "23:30", 3),            // outside window
+		minuteCount("Erithacus rubecula", "2024-05-01", "bad", 1),       // ignored
+		minuteCount("Phylloscopus collybita", "2024-06-01", "05:00", 1), // 0 min
+	}
+
+	result := ComputeSunRelativeActivity(rows, fixedSunTimes, SunActivityOptions{
+		Event:         SunEventSunrise,
+		BucketMinutes: 30,
+		WindowMinutes: 60,
+		OnsetSpecies:  3,
+	})
+
+	assert.Equal(t, []int{-60, -30, 0, 30}, result.BucketOffsets)
+	assert.Equal(t, []int{4, 1, 2, 0}, result.Overall)
+	assert.Equal(t, 10, result.Total)
+	assert.Equal(t, 3, result.OutsideWindow)
+
+	require.NotEmpty(t, result.Species)
+	blackbird := result.Species[0]
+	assert.Equal(t, "Turdus merula", blackbird.ScientificName)
+	assert.Equal(t, 4, blackbird.Total)
+	assert.Equal(t, []int{2, 1, 1, 0}, blackbird.Buckets)
+	require.NotNil(t, blackbird.MedianOffsetMinutes)
+	assert.Equal(t, -50, *blackbird.MedianOffsetMinutes)
+	require.Len(t, blackbird.Monthly, 2)
+	assert.Equal(t, MonthlySunActivity{Month: "2024-05", Count: 3, MedianOffsetMinutes: -50}, blackbird.Monthly[0])
+	assert.Equal(t, MonthlySunActivity{Month: "2024-06", Count: 1, MedianOffsetMinutes: -5}, blackbird.Monthly[1])
+
+	require.Len(t, result.DawnChorus, 2)
+	may := result.DawnChorus[0]
+	assert.Equal(t, "2024-05-01", may.Date)
+	assert.Equal(t, "05:00", may.Sunrise)
+	assert.Equal(t, "04:10", may.FirstDetection)
+	assert.Equal(t, "Turdus merula", may.FirstSpecies)
+	assert.Equal(t, "04:10", may.Onset, "three species within 15 minutes from 04:10")
+	require.NotNil(t, may.OnsetRelativeToSunrise)
+	assert.Equal(t, -50, *may.OnsetRelativeToSunrise)
+	assert.Equal(t, -10, *may.OnsetRelativeToCivilDawn)
+	assert.Equal(t, 3, may.MorningSpecies, "the owl at 23:30 is outside the morning window")
+
+	june := result.DawnChorus[1]
+	assert.Empty(t, june.Onset, "only two species in June")
+	assert.Nil(t, june.OnsetRelativeToSunrise)
+	assert.Equal(t, "04:55", june.FirstDetection)
+}
+
+func TestComputeSunRelativeActivityDefaults(t *testing.T) {
+	t.Parallel()
+
+	result := ComputeSunRelativeActivity(nil, fixedSunTimes, SunActivityOptions{Event: "noon", BucketMinutes: 25, WindowMinutes: 60})
+	assert.Equal(t, SunEventSunrise, result.Event)
+	assert.Equal(t, 75, result.WindowMinutes, "window is rounded up to whole buckets")
+	assert.Len(t, result.Overall, 6)
+	assert.Empty(t, result.Species)
+	assert.Empty(t, result.DawnChorus)
+}
+
+func TestComputeDawnChorus(t *testing.T) {
+	t.Parallel()
+
+	rows := []DetectionMinuteCount{
+		minuteCount("Erithacus rubecula", "2024-05-02", "04:40", 1),
+		minuteCount("Turdus merula", "2024-05-02", "04:41", 1),
+		minuteCount("Fringilla coelebs", "2024-05-02", "04:42", 1),
+		minuteCount("Turdus merula", "2024-05-01", "04:50", 1),
+		minuteCount("Turdus merula", "2024-05-01", "12:00", 3), // outside the search window
+	}
+
+	days := ComputeDawnChorus(rows, fixedSunTimes, 0)
+	require.Len(t, days, 2)
+
+	assert.Equal(t, "2024-05-01", days[0].Date)
+	assert.Empty(t, days[0].Onset, "one species does not reach the default onset")
+	assert.Equal(t, 1, days[0].MorningSpecies)
+
+	assert.Equal(t, "2024-05-02", days[1].Date)
+	assert.Equal(t, "04:40", days[1].Onset)
+	require.NotNil(t, days[1].OnsetRelativeToSunrise)
+	assert.Equal(t, -20, *days[1].OnsetRelativeToSunrise)
+	assert.Equal(t, 3, days[1].MorningSpecies)
+
+	assert.Empty(t, ComputeDawnChorus(nil, fixedSunTimes, 3))
+}
+
+func TestGetDetectionMinuteCounts(t *testing.T) {
+	t.Parallel()
+
+	ds := setupTestDB(t)
+	notes := []Note{
+		{ID: 1, Date: "2024-05-01", Time: "04:10:05", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.9},
+		{ID: 2, Date: "2024-05-01", Time: "04:10:45", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.8},
+		{ID: 3, Date: "2024-05-01", Time: "04:11:00", ScientificName: "Turdus merula", CommonName: "Eurasian Blackbird", Confidence: 0.9},
+		{ID: 4, Date: "2024-05-01", Time: "04:11:00", ScientificName: "Erithacus rubecula", CommonName: "European Robin", Confidence: 0.9},
+	}
+	require.NoError(t, ds.DB.Create(&notes).Error)
+
+	rows, err := ds.GetDetectionMinuteCounts(t.Context(), "2024-05-01", "2024-05-01", "Eurasian Blackbird")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	counts := map[string]int{}
+	for _, r := range rows {
+		counts[r.Minute] = r.Count
+	}
+	assert.Equal(t, map[string]int{"04:10": 2, "04:11": 1}, counts)
+
+	all, err := ds.GetDetectionMinuteCounts(t.Context(), "2024-05-01", "2024-05-01", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
@@ -0,0 +1,24 @@
+package entities
+
+// AcousticIndex stores the acoustic indices of one audio source over one
+// interval, normally a minute. Rows are written with the sound level history
+// and pruned with it, but are never downsampled.
+type AcousticIndex struct {
+	ID        uint    `gorm:"primaryKey"`
+	SourceID  uint    `gorm:"not null;index:idx_acoustic_indices_source_start,priority:1"`
+	StartTime int64   `gorm:"not null;index:idx_acoustic_indices_source_start,priority:2;index"` // Unix seconds
+	Duration  int     `gorm:"not null"`
+	ACI       float64 `gorm:"not null"` // Acoustic Complexity Index
+	ADI       float64 `gorm:"not null"` // Acoustic Diversity Index
+	NDSI      float64 `gorm:"not null"` // Normalized Difference Soundscape Index
+	BI        float64 `gorm:"not null"` // Bioacoustic Index
+	H         float64 `gorm:"not null"` // Acoustic entropy
+
+	// Relationship
+	Source *AudioSource `gorm:"foreignKey:SourceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
+
+// TableName returns the table name for GORM.
+func (AcousticIndex) TableName() string {
+	return "acoustic_indices"
+}
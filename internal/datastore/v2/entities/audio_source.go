@@ -10,11 +10,21 @@ const (
 	SourceTypeALSA       SourceType = "alsa"
 	SourceTypePulseAudio SourceType = "pulseaudio"
 	SourceTypeFile       SourceType = "file"
+	SourceTypeHTTP       SourceType = "http"
+	SourceTypeHLS        SourceType = "hls"
+	SourceTypeRTMP       SourceType = "rtmp"
+	SourceTypeUDP        SourceType = "udp"
+	SourceTypeSRT        SourceType = "srt"
 	SourceTypeUnknown    SourceType = "unknown"
 )
 
 // AudioSource represents a normalized audio input source.
 // This normalizes the source_node string from the legacy schema.
+//
+// Managed sources are defined by the user (sources API or config import) and
+// drive audio capture. Unmanaged sources are created implicitly when a detection
+// references an unknown source. The ID is stable across renames and URL changes,
+// so historical detections stay linked to the source.
 type AudioSource struct {
 	ID uint `gorm:"primaryKey"`
 	// SourceURI is the source identifier (e.g., "rtsp://...", "hw:0,0", "/path/to/file").
@@ -24,8 +34,27 @@ type AudioSource struct {
 	NodeName    string     `gorm:"type:varchar(100);not null;uniqueIndex:idx_audio_source_unique,priority:2;index"`
 	SourceType  SourceType `gorm:"type:varchar(20);not null"`
 	DisplayName *string    `gorm:"type:varchar(200)"`
-	ConfigJSON  *string    `gorm:"type:text"`
-	CreatedAt   time.Time  `gorm:"autoCreateTime"`
+	// ConfigJSON holds protocol specific stream options, such as the SRT
+	// latency and passphrase. It may contain secrets and is never serialized.
+	ConfigJSON *string `gorm:"type:text" json:"-"`
+	// Managed marks sources whose stream definition is owned by the database.
+	Managed bool `gorm:"not null;default:false;index"`
+	// Enabled controls whether a managed source is captured. Ignored for unmanaged sources.
+	Enabled bool `gorm:"not null;default:false"`
+	// StreamURL is the full connection URL including credentials; SourceURI holds the sanitized form.
+	StreamURL *string `gorm:"type:text" json:"-"`
+	// Transport is the stream transport protocol ("tcp" or "udp") for RTSP/RTMP sources.
+	Transport *string  `gorm:"type:varchar(10)"`
+	Latitude  *float64 // Per-source location, overrides the station location when set
+	Longitude *float64
+	Notes     *string   `gorm:"type:text"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+}
+
+// HasLocation reports whether the source defines its own coordinates.
+func (s *AudioSource) HasLocation() bool {
+	return s.Latitude != nil && s.Longitude != nil
 }
 
 // TableName returns the table name for GORM.
@@ -28,6 +28,9 @@ type Detection struct {
 	// Processing metadata
 	ProcessingTimeMs *int64 // Milliseconds
 
+	// Direction of arrival (optional, from microphone arrays)
+	Bearing *float64 // Compass bearing in degrees of the sound
+
 	// Migration reference (preserves legacy ID for lookups and related data migration)
 	LegacyID *uint `gorm:"index"`
 
@@ -0,0 +1,21 @@
+package entities
+
+// DetectionLink stores a detection at another audio source that was merged
+// into a detection because both sources are in a source group and the
+// detection's source heard the bird with a higher confidence.
+type DetectionLink struct {
+	ID          uint    `gorm:"primaryKey"`
+	DetectionID uint    `gorm:"not null;index"`
+	SourceID    uint    `gorm:"not null;index"`
+	Confidence  float64 `gorm:"not null"`
+	BeginTime   int64   `gorm:"not null"` // Unix milliseconds
+
+	// Relationships
+	Detection *Detection   `gorm:"foreignKey:DetectionID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+	Source    *AudioSource `gorm:"foreignKey:SourceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
+
+// TableName returns the table name for GORM.
+func (DetectionLink) TableName() string {
+	return "detection_links"
+}
@@ -0,0 +1,23 @@
+package entities
+
+import "time"
+
+// MaxTagLength is the maximum length of a normalized detection tag.
+const MaxTagLength = 64
+
+// DetectionTag stores a free-form user tag on a detection.
+// Tags are stored normalized (trimmed, lower-case) and are unique per detection.
+type DetectionTag struct {
+	ID          uint      `gorm:"primaryKey"`
+	DetectionID uint      `gorm:"not null;uniqueIndex:idx_detection_tags_detection_tag,priority:1"`
+	Tag         string    `gorm:"size:64;not null;uniqueIndex:idx_detection_tags_detection_tag,priority:2;index:idx_detection_tags_tag"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+
+	// Relationship
+	Detection *Detection `gorm:"foreignKey:DetectionID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
+
+// TableName returns the table name for GORM.
+func (DetectionTag) TableName() string {
+	return "detection_tags"
+}
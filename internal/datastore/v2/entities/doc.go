@@ -9,7 +9,7 @@
 //   - Label: Classification labels (species and non-species like noise, wind)
 //   - AIModel: AI model registry (BirdNET, Perch, BatNET)
 //   - ModelLabel: Maps model raw output to normalized labels
-//   - AudioSource: Normalized audio input sources
+//   - AudioSource: Normalized audio input sources and managed stream definitions
 //
 // # Detection Entities
 //
@@ -17,7 +17,14 @@
 //   - DetectionPrediction: Additional predictions per detection (replaces 'results')
 //   - DetectionReview: Verification status
 //   - DetectionComment: User comments
+//   - DetectionTag: Free-form user tags
 //   - DetectionLock: Lock status
+//   - DetectionLink: Detections at grouped sources merged into a detection
+//   - IngestedDetection: Detections received from remote nodes
+//
+// # Acoustic Environment
+//
+//   - SoundLevel: Per-source 1/3-octave band level history
 //
 // # Migration
 //
@@ -0,0 +1,22 @@
+package entities
+
+import "time"
+
+// IngestedDetection maps a detection pushed by a remote node to the local
+// detection it was stored as. The unique (node_name, remote_id) pair makes
+// ingestion idempotent: a node retrying a push gets the existing detection.
+type IngestedDetection struct {
+	ID          uint      `gorm:"primaryKey"`
+	NodeName    string    `gorm:"size:255;not null;uniqueIndex:idx_ingested_detections_node_remote,priority:1"`
+	RemoteID    uint      `gorm:"not null;uniqueIndex:idx_ingested_detections_node_remote,priority:2"`
+	DetectionID uint      `gorm:"not null;index"`
+	ReceivedAt  time.Time `gorm:"autoCreateTime"`
+
+	// Relationship
+	Detection *Detection `gorm:"foreignKey:DetectionID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
+
+// TableName returns the table name for GORM.
+func (IngestedDetection) TableName() string {
+	return "ingested_detections"
+}
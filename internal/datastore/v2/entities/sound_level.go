@@ -0,0 +1,26 @@
+package entities
+
+// SoundLevel stores the 1/3-octave band levels of one audio source over one
+// interval. Band levels are packed into Bands (see datastore.EncodeSoundLevelBands)
+// so a measurement of all bands is a single row of a few hundred bytes.
+//
+// Fresh measurements have the sound level interval as their Resolution; older
+// measurements are downsampled into rows of a coarser Resolution.
+type SoundLevel struct {
+	ID        uint  `gorm:"primaryKey"`
+	SourceID  uint  `gorm:"not null;index:idx_sound_levels_source_start,priority:1"`
+	StartTime int64 `gorm:"not null;index:idx_sound_levels_source_start,priority:2;index"` // Unix seconds
+	// Duration is the measured time in seconds, Resolution the span of the row.
+	Duration   int     `gorm:"not null"`
+	Resolution int     `gorm:"not null;index"`
+	Leq        float64 `gorm:"not null"` // Broadband equivalent level in dB
+	Bands      []byte  `gorm:"not null"`
+
+	// Relationship
+	Source *AudioSource `gorm:"foreignKey:SourceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
+
+// TableName returns the table name for GORM.
+func (SoundLevel) TableName() string {
+	return "sound_levels"
+}
@@ -0,0 +1,18 @@
+package entities
+
+// StreamGap stores a span of audio an audio stream did not deliver.
+type StreamGap struct {
+	ID         uint   `gorm:"primaryKey"`
+	SourceID   uint   `gorm:"not null;index:idx_stream_gaps_source_start,priority:1"`
+	StartTime  int64  `gorm:"not null;index:idx_stream_gaps_source_start,priority:2;index"` // Unix milliseconds
+	DurationMs int64  `gorm:"not null"`
+	Reason     string `gorm:"size:16;not null"` // stall or restart
+
+	// Relationship
+	Source *AudioSource `gorm:"foreignKey:SourceID;constraint:OnDelete:CASCADE,OnUpdate:CASCADE"`
+}
+
+// TableName returns the table name for GORM.
+func (StreamGap) TableName() string {
+	return "stream_gaps"
+}
@@ -126,7 +126,13 @@ func (m *SQLiteManager) Initialize() error {
 		&entities.DetectionPrediction{},
 		&entities.DetectionReview{},
 		&entities.DetectionComment{},
+		&entities.DetectionTag{},
 		&entities.DetectionLock{},
+		&entities.DetectionLink{},
+		&entities.IngestedDetection{},
+		&entities.SoundLevel{},
+		&entities.AcousticIndex{},
+		&entities.StreamGap{},
 		&entities.MigrationState{},
 		&entities.MigrationDirtyID{},
 		// Auxiliary tables
@@ -152,6 +158,11 @@ func (m *SQLiteManager) Initialize() error {
 		return fmt.Errorf("failed to fix foreign key constraints: %w", err)
 	}
 
+	// Full-text index for detection comment search (optional, requires FTS5)
+	if err := m.setupCommentFTS(); err != nil {
+		return fmt.Errorf("failed to set up comment full-text index: %w", err)
+	}
+
 	// Initialize migration state singleton using FirstOrCreate to handle race conditions
 	state := entities.MigrationState{ID: 1, State: entities.MigrationStatusIdle}
 	if err := m.db.FirstOrCreate(&state, entities.MigrationState{ID: 1}).Error; err != nil {
@@ -185,6 +196,49 @@ func (m *SQLiteManager) fixSQLiteForeignKeys() error {
 	return m.db.Exec(triggerSQL).Error
 }
 
+// setupCommentFTS creates an external-content FTS5 index over detection comments,
+// kept in sync by triggers. FTS5 is a compile-time option of the SQLite driver;
+// when it is unavailable the index is skipped and comment search falls back to LIKE.
+func (m *SQLiteManager) setupCommentFTS() error {
+	var fts5Enabled int
+	if err := m.db.Raw("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&fts5Enabled).Error; err != nil || fts5Enabled == 0 {
+		return nil
+	}
+	if m.db.Migrator().HasTable("detection_comments_fts") {
+		return nil
+	}
+
+	createSQL := `CREATE VIRTUAL TABLE detection_comments_fts
+		USING fts5(entry, content='detection_comments', content_rowid='id')`
+	if err := m.db.Exec(createSQL).Error; err != nil {
+		return err
+	}
+
+	statements := []string{
+		`CREATE TRIGGER IF NOT EXISTS trg_detection_comments_fts_insert
+		AFTER INSERT ON detection_comments BEGIN
+			INSERT INTO detection_comments_fts(rowid, entry) VALUES (NEW.id, NEW.entry);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_detection_comments_fts_delete
+		AFTER DELETE ON detection_comments BEGIN
+			INSERT INTO detection_comments_fts(detection_comments_fts, rowid, entry) VALUES ('delete', OLD.id, OLD.entry);
+		END`,
+		`CREATE TRIGGER IF NOT EXISTS trg_detection_comments_fts_update
+		AFTER UPDATE OF entry ON detection_comments BEGIN
+			INSERT INTO detection_comments_fts(detection_comments_fts, rowid, entry) VALUES ('delete', OLD.id, OLD.entry);
+			INSERT INTO detection_comments_fts(rowid, entry) VALUES (NEW.id, NEW.entry);
+		END`,
+		// Index comments that existed before the FTS table was created
+		`INSERT INTO detection_comments_fts(detection_comments_fts) VALUES ('rebuild')`,
+	}
+	for _, stmt := range statements {
+		if err := m.db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // seedLookupTables seeds the label_types and taxonomic_classes tables with default values.
 func (m *SQLiteManager) seedLookupTables() error {
 	// Seed label types
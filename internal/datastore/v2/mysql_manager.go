@@ -101,7 +101,13 @@ func (m *MySQLManager) Initialize() error {
 		&entities.DetectionPrediction{},
 		&entities.DetectionReview{},
 		&entities.DetectionComment{},
+		&entities.DetectionTag{},
 		&entities.DetectionLock{},
+		&entities.DetectionLink{},
+		&entities.IngestedDetection{},
+		&entities.SoundLevel{},
+		&entities.AcousticIndex{},
+		&entities.StreamGap{},
 		&entities.MigrationState{},
 		&entities.MigrationDirtyID{},
 		// Auxiliary tables
@@ -121,6 +127,11 @@ func (m *MySQLManager) Initialize() error {
 		return fmt.Errorf("failed to migrate v2 schema: %w", err)
 	}
 
+	// FULLTEXT index for detection comment search
+	if err := m.ensureCommentFulltextIndex(); err != nil {
+		return fmt.Errorf("failed to create comment fulltext index: %w", err)
+	}
+
 	// Initialize migration state singleton using FirstOrCreate to handle race conditions
 	state := entities.MigrationState{ID: 1, State: entities.MigrationStatusIdle}
 	if err := m.db.FirstOrCreate(&state, entities.MigrationState{ID: 1}).Error; err != nil {
@@ -136,6 +147,19 @@ func (m *MySQLManager) Initialize() error {
 	return m.seedDefaultModel()
 }
 
+// commentFulltextIndex is the name of the FULLTEXT index on detection comment entries.
+const commentFulltextIndex = "idx_detection_comments_entry_fulltext"
+
+// ensureCommentFulltextIndex creates the FULLTEXT index used by comment search.
+// GORM cannot declare it on the entity because the same tags are used for SQLite.
+func (m *MySQLManager) ensureCommentFulltextIndex() error {
+	table := m.tablePrefix + "detection_comments"
+	if m.db.Migrator().HasIndex(table, commentFulltextIndex) {
+		return nil
+	}
+	return m.db.Exec(fmt.Sprintf("CREATE FULLTEXT INDEX %s ON %s (entry)", commentFulltextIndex, table)).Error
+}
+
 // seedLookupTables seeds the label_types and taxonomic_classes tables with default values.
 func (m *MySQLManager) seedLookupTables() error {
 	// Seed label types
@@ -200,7 +224,13 @@ func (m *MySQLManager) Delete() error {
 	prefix := m.tablePrefix
 	tables := []string{
 		// Core detection tables (drop children first)
+		prefix + "ingested_detections",
+		prefix + "sound_levels",
+		prefix + "acoustic_indices",
+		prefix + "stream_gaps",
+		prefix + "detection_links",
 		prefix + "detection_locks",
+		prefix + "detection_tags",
 		prefix + "detection_comments",
 		prefix + "detection_reviews",
 		prefix + "detection_predictions",
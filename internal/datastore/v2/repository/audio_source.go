@@ -52,4 +52,11 @@ type AudioSourceRepository interface {
 
 	// Exists checks if an audio source with the given ID exists.
 	Exists(ctx context.Context, id uint) (bool, error)
+
+	// Create inserts a new audio source.
+	// Returns ErrDuplicateKey if a source with the same URI and node already exists.
+	Create(ctx context.Context, source *entities.AudioSource) error
+
+	// GetManaged retrieves all managed audio sources ordered by ID.
+	GetManaged(ctx context.Context) ([]*entities.AudioSource, error)
 }
@@ -215,3 +215,30 @@ func (r *audioSourceRepository) Exists(ctx context.Context, id uint) (bool, erro
 		Count(&count).Error
 	return count > 0, err
 }
+
+// Create inserts a new audio source.
+func (r *audioSourceRepository) Create(ctx context.Context, source *entities.AudioSource) error {
+	if _, err := r.GetBySourceURI(ctx, source.SourceURI, source.NodeName); err == nil {
+		return ErrDuplicateKey
+	} else if !errors.Is(err, ErrAudioSourceNotFound) {
+		return err
+	}
+
+	if source.SourceType == "" {
+		source.SourceType = detectSourceType(source.SourceURI)
+	}
+	if err := r.db.WithContext(ctx).Table(r.tableName()).Create(source).Error; err != nil {
+		return fmt.Errorf("create audio source: %w", err)
+	}
+	return nil
+}
+
+// GetManaged retrieves all managed audio sources ordered by ID.
+func (r *audioSourceRepository) GetManaged(ctx context.Context) ([]*entities.AudioSource, error) {
+	var sources []*entities.AudioSource
+	err := r.db.WithContext(ctx).Table(r.tableName()).
+		Where("managed = ?", true).
+		Order("id ASC").
+		Find(&sources).Error
+	return sources, err
+}
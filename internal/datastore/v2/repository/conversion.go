@@ -134,6 +134,7 @@ func ConvertToV2Detection(ctx context.Context, result *detection.Result, deps *C
 		Longitude:        lon,
 		ClipName:         clipName,
 		ProcessingTimeMs: processingTimeMs,
+		Bearing:          result.Bearing,
 		LegacyID:         &legacyID,
 	}
 
@@ -230,6 +231,8 @@ func ConvertFromV2Detection(det *entities.Detection) *detection.Result {
 		result.ProcessingTime = time.Duration(*det.ProcessingTimeMs) * time.Millisecond
 	}
 
+	result.Bearing = det.Bearing
+
 	return result
 }
 
@@ -196,6 +196,28 @@ type DetectionRepository interface {
 	// GetCommentsByDetectionIDs retrieves comments for multiple detections.
 	GetCommentsByDetectionIDs(ctx context.Context, detectionIDs []uint) (map[uint][]*entities.DetectionComment, error)
 
+	// === Tags ===
+
+	// AddTags attaches tags to a detection. Tags are normalized before saving
+	// and tags already present on the detection are ignored.
+	// Returns ErrInvalidTag if any tag is empty or too long.
+	AddTags(ctx context.Context, detectionID uint, tags []string) error
+
+	// RemoveTag removes a tag from a detection.
+	// Returns ErrTagNotFound if the detection does not have the tag.
+	RemoveTag(ctx context.Context, detectionID uint, tag string) error
+
+	// GetTags retrieves the tags of a detection in alphabetical order.
+	GetTags(ctx context.Context, detectionID uint) ([]string, error)
+
+	// GetTagsByDetectionIDs retrieves tags for multiple detections.
+	// Handles large ID sets by chunking to avoid SQL parameter limits.
+	GetTagsByDetectionIDs(ctx context.Context, detectionIDs []uint) (map[uint][]string, error)
+
+	// GetAllTags returns every tag in use with its detection count,
+	// ordered by count descending.
+	GetAllTags(ctx context.Context) ([]TagCount, error)
+
 	// === Locks ===
 
 	// Lock prevents modification/deletion of a detection.
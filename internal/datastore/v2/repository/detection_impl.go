@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"maps"
 	"slices"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
 	"github.com/tphakala/birdnet-go/internal/errors"
@@ -34,6 +37,10 @@ type detectionRepository struct {
 	db          *gorm.DB
 	useV2Prefix bool
 	isMySQL     bool
+
+	// commentFTS caches whether the SQLite FTS5 comment index exists.
+	commentFTSOnce sync.Once
+	commentFTS     bool
 }
 
 // NewDetectionRepository creates a new DetectionRepository.
@@ -78,6 +85,13 @@ func (r *detectionRepository) commentsTable() string {
 	return tableDetectionComments
 }
 
+func (r *detectionRepository) tagsTable() string {
+	if r.useV2Prefix {
+		return tableV2DetectionTags
+	}
+	return tableDetectionTags
+}
+
 func (r *detectionRepository) locksTable() string {
 	if r.useV2Prefix {
 		return tableV2DetectionLocks
@@ -603,6 +617,25 @@ func (r *detectionRepository) buildSearchJoins(query *gorm.DB, filters *SearchFi
 		}
 	}
 
+	// Tag filter (any or all of the given tags)
+	if len(filters.Tags) > 0 {
+		tagTable := r.tagsTable()
+		if filters.TagsMatchAll {
+			query = query.Where(fmt.Sprintf(
+				"(SELECT COUNT(DISTINCT %s.tag) FROM %s WHERE %s.detection_id = %s.id AND %s.tag IN ?) = ?",
+				tagTable, tagTable, tagTable, r.tableName(), tagTable), filters.Tags, len(filters.Tags))
+		} else {
+			query = query.Where(fmt.Sprintf(
+				"EXISTS (SELECT 1 FROM %s WHERE %s.detection_id = %s.id AND %s.tag IN ?)",
+				tagTable, tagTable, r.tableName(), tagTable), filters.Tags)
+		}
+	}
+
+	// Comment full-text filter
+	if filters.CommentQuery != "" {
+		query = r.applyCommentSearch(query, filters.CommentQuery)
+	}
+
 	// Locked filter (requires locks join)
 	if filters.IsLocked != nil {
 		if *filters.IsLocked {
@@ -617,6 +650,114 @@ func (r *detectionRepository) buildSearchJoins(query *gorm.DB, filters *SearchFi
 	return query
 }
 
+// applyCommentSearch restricts the query to detections with a comment matching text.
+// Uses the FTS5 index on SQLite and the FULLTEXT index on MySQL when the text
+// contains searchable terms, otherwise falls back to a substring match. On MySQL
+// terms the FULLTEXT index cannot match are checked with a substring match instead.
+func (r *detectionRepository) applyCommentSearch(query *gorm.DB, text string) *gorm.DB {
+	comTable := r.commentsTable()
+	terms := commentSearchTerms(text)
+	indexed, unindexed := mysqlFulltextTerms(terms)
+
+	switch {
+	case r.isMySQL && len(indexed) > 0:
+		conditions := fmt.Sprintf("MATCH(%s.entry) AGAINST (? IN BOOLEAN MODE)", comTable)
+		args := []any{mysqlBooleanQuery(indexed)}
+		for _, term := range unindexed {
+			conditions += fmt.Sprintf(" AND %s.entry LIKE ?", comTable)
+			args = append(args, "%"+term+"%")
+		}
+		return query.Where(fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s WHERE %s.detection_id = %s.id AND %s)",
+			comTable, comTable, r.tableName(), conditions), args...)
+	case !r.isMySQL && len(terms) > 0 && r.hasCommentFTS():
+		return query.Where(fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s JOIN %s ON %s.rowid = %s.id WHERE %s.detection_id = %s.id AND %s MATCH ?)",
+			comTable, tableDetectionCommentsFTS, tableDetectionCommentsFTS, comTable,
+			comTable, r.tableName(), tableDetectionCommentsFTS), fts5Query(terms))
+	default:
+		return query.Where(fmt.Sprintf(
+			"EXISTS (SELECT 1 FROM %s WHERE %s.detection_id = %s.id AND %s.entry LIKE ?)",
+			comTable, comTable, r.tableName(), comTable), "%"+text+"%")
+	}
+}
+
+// hasCommentFTS reports whether the SQLite FTS5 comment index is present.
+// The index is optional because FTS5 depends on how the SQLite driver was built.
+func (r *detectionRepository) hasCommentFTS() bool {
+	r.commentFTSOnce.Do(func() {
+		r.commentFTS = r.db.Migrator().HasTable(tableDetectionCommentsFTS)
+	})
+	return r.commentFTS
+}
+
+// commentSearchTerms splits free text into search terms, dropping characters
+// that carry operator meaning in FTS5 or MySQL boolean mode.
+func commentSearchTerms(text string) []string {
+	var terms []string
+	for field := range strings.FieldsSeq(text) {
+		term := strings.Map(func(r rune) rune {
+			switch r {
+			case '"', '\'', '*', '+', '-', '<', '>', '(', ')', '~', '@', ':', '^':
+				return -1
+			}
+			return r
+		}, field)
+		if term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// fts5Query builds an FTS5 MATCH expression requiring every term as a prefix.
+func fts5Query(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = `"` + t + `"*`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// mysqlFulltextMinTokenSize is the InnoDB default innodb_ft_min_token_size.
+// Shorter words are not in the FULLTEXT index.
+const mysqlFulltextMinTokenSize = 3
+
+// mysqlFulltextStopwords is the InnoDB default full-text stopword list. These
+// words are not in the FULLTEXT index.
+var mysqlFulltextStopwords = map[string]struct{}{
+	"a": {}, "about": {}, "an": {}, "are": {}, "as": {}, "at": {}, "be": {}, "by": {},
+	"com": {}, "de": {}, "en": {}, "for": {}, "from": {}, "how": {}, "i": {}, "in": {},
+	"is": {}, "it": {}, "la": {}, "of": {}, "on": {}, "or": {}, "that": {}, "the": {},
+	"this": {}, "to": {}, "was": {}, "what": {}, "when": {}, "where": {}, "who": {},
+	"will": {}, "with": {}, "und": {}, "www": {},
+}
+
+// mysqlFulltextTerms splits search terms into those the MySQL FULLTEXT index can
+// match and those it cannot: words shorter than the minimum token size and
+// stopwords. Required terms the index cannot match would make MATCH find nothing.
+// Servers with a non-default token size or stopword list may still differ.
+func mysqlFulltextTerms(terms []string) (indexed, unindexed []string) {
+	for _, term := range terms {
+		_, stopword := mysqlFulltextStopwords[strings.ToLower(term)]
+		if stopword || utf8.RuneCountInString(term) < mysqlFulltextMinTokenSize {
+			unindexed = append(unindexed, term)
+			continue
+		}
+		indexed = append(indexed, term)
+	}
+	return indexed, unindexed
+}
+
+// mysqlBooleanQuery builds a MySQL BOOLEAN MODE expression requiring every term as a prefix.
+func mysqlBooleanQuery(terms []string) string {
+	required := make([]string, len(terms))
+	for i, t := range terms {
+		required[i] = "+" + t + "*"
+	}
+	return strings.Join(required, " ")
+}
+
 // applySearchOrdering applies sorting and pagination to the query.
 func (r *detectionRepository) applySearchOrdering(query *gorm.DB, filters *SearchFilters) *gorm.DB {
 	// Determine sort direction suffix
@@ -1209,6 +1350,120 @@ func (r *detectionRepository) GetCommentsByDetectionIDs(ctx context.Context, det
 	return result, nil
 }
 
+// ============================================================================
+// Tags
+// ============================================================================
+
+// NormalizeTag trims and lower-cases a tag.
+// Returns ErrInvalidTag if the result is empty or longer than entities.MaxTagLength.
+func NormalizeTag(tag string) (string, error) {
+	normalized := strings.ToLower(strings.Join(strings.Fields(tag), " "))
+	if normalized == "" || len([]rune(normalized)) > entities.MaxTagLength {
+		return "", ErrInvalidTag
+	}
+	return normalized, nil
+}
+
+// AddTags attaches normalized tags to a detection, ignoring tags already present.
+func (r *detectionRepository) AddTags(ctx context.Context, detectionID uint, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	rows := make([]*entities.DetectionTag, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		normalized, err := NormalizeTag(tag)
+		if err != nil {
+			return err
+		}
+		if seen[normalized] {
+			continue
+		}
+		seen[normalized] = true
+		rows = append(rows, &entities.DetectionTag{DetectionID: detectionID, Tag: normalized})
+	}
+
+	exists, err := r.Exists(ctx, detectionID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrDetectionNotFound
+	}
+
+	return r.db.WithContext(ctx).Table(r.tagsTable()).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(rows).Error
+}
+
+// RemoveTag removes a tag from a detection.
+func (r *detectionRepository) RemoveTag(ctx context.Context, detectionID uint, tag string) error {
+	normalized, err := NormalizeTag(tag)
+	if err != nil {
+		return err
+	}
+	result := r.db.WithContext(ctx).Table(r.tagsTable()).
+		Where("detection_id = ? AND tag = ?", detectionID, normalized).
+		Delete(&entities.DetectionTag{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTagNotFound
+	}
+	return nil
+}
+
+// GetTags retrieves the tags of a detection in alphabetical order.
+func (r *detectionRepository) GetTags(ctx context.Context, detectionID uint) ([]string, error) {
+	var tags []string
+	err := r.db.WithContext(ctx).Table(r.tagsTable()).
+		Where("detection_id = ?", detectionID).
+		Order("tag ASC").
+		Pluck("tag", &tags).Error
+	return tags, err
+}
+
+// GetTagsByDetectionIDs retrieves tags for multiple detections.
+// Handles large ID sets by chunking to avoid SQL parameter limits.
+func (r *detectionRepository) GetTagsByDetectionIDs(ctx context.Context, detectionIDs []uint) (map[uint][]string, error) {
+	result := make(map[uint][]string)
+	if len(detectionIDs) == 0 {
+		return result, nil
+	}
+
+	for i := 0; i < len(detectionIDs); i += batchQuerySize {
+		end := min(i+batchQuerySize, len(detectionIDs))
+		batchIDs := detectionIDs[i:end]
+
+		var tags []*entities.DetectionTag
+		err := r.db.WithContext(ctx).Table(r.tagsTable()).
+			Where("detection_id IN ?", batchIDs).
+			Order("tag ASC").
+			Find(&tags).Error
+		if err != nil {
+			return nil, fmt.Errorf("batch load tags: %w", err)
+		}
+
+		for _, t := range tags {
+			result[t.DetectionID] = append(result[t.DetectionID], t.Tag)
+		}
+	}
+	return result, nil
+}
+
+// GetAllTags returns every tag in use with its detection count.
+func (r *detectionRepository) GetAllTags(ctx context.Context) ([]TagCount, error) {
+	var results []TagCount
+	err := r.db.WithContext(ctx).Table(r.tagsTable()).
+		Select("tag, COUNT(*) as count").
+		Group("tag").
+		Order("count DESC, tag ASC").
+		Scan(&results).Error
+	return results, err
+}
+
 // ============================================================================
 // Locks
 // ============================================================================
@@ -1337,6 +1592,9 @@ func (r *detectionRepository) GetSpeciesSummary(ctx context.Context, start, end
 	if modelID != nil {
 		query = query.Where(fmt.Sprintf("%s.model_id = ?", detTable), *modelID)
 	}
+	if clause, args := NodeFilterClause(ctx, detTable+".source_id", r.sourcesTable()); clause != "" {
+		query = query.Where(clause, args...)
+	}
 
 	err := query.Group(fmt.Sprintf("%s.scientific_name", labTable)).
 		Order("total_detections DESC").
@@ -1361,6 +1619,9 @@ func (r *detectionRepository) buildAnalyticsBaseQuery(ctx context.Context, start
 	if modelID != nil {
 		query = query.Where("d.model_id = ?", *modelID)
 	}
+	if clause, args := NodeFilterClause(ctx, "d.source_id", r.sourcesTable()); clause != "" {
+		query = query.Where(clause, args...)
+	}
 
 	return query
 }
@@ -1432,6 +1693,10 @@ func (r *detectionRepository) GetNewSpecies(ctx context.Context, start, end int6
 	// Approach: Use a derived table to compute lifetime first detection per species,
 	// then filter and join back to get detection details. This is O(n) instead of
 	// the O(n²) correlated subquery approach.
+	//
+	// With a node filter, "first ever" means first ever at that node.
+	innerNode, innerArgs := r.nodeFilterClause(ctx, "d2.source_id")
+	outerNode, outerArgs := r.nodeFilterClause(ctx, "d.source_id")
 	rawSQL := fmt.Sprintf(`
 		SELECT
 			MIN(d.label_id) as label_id,
@@ -1443,17 +1708,23 @@ func (r *detectionRepository) GetNewSpecies(ctx context.Context, start, end int6
 			SELECT l2.scientific_name, MIN(d2.detected_at) as lifetime_first
 			FROM %s d2
 			JOIN %s l2 ON l2.id = d2.label_id
+			WHERE %s
 			GROUP BY l2.scientific_name
 			HAVING MIN(d2.detected_at) >= ? AND MIN(d2.detected_at) < ?
 		) species_first
 		JOIN %s l ON l.scientific_name = species_first.scientific_name
 		JOIN %s d ON d.label_id = l.id AND d.detected_at = species_first.lifetime_first
+		WHERE %s
 		GROUP BY species_first.scientific_name, species_first.lifetime_first
 		ORDER BY first_detected DESC
 		LIMIT ? OFFSET ?
-	`, r.tableName(), r.labelsTable(), r.labelsTable(), r.tableName())
+	`, r.tableName(), r.labelsTable(), innerNode, r.labelsTable(), r.tableName(), outerNode)
 
-	err := r.db.WithContext(ctx).Raw(rawSQL, start, end, limit, offset).Scan(&results).Error
+	args := append([]any{}, innerArgs...)
+	args = append(args, start, end)
+	args = append(args, outerArgs...)
+	args = append(args, limit, offset)
+	err := r.db.WithContext(ctx).Raw(rawSQL, args...).Scan(&results).Error
 	return results, err
 }
 
@@ -1467,6 +1738,7 @@ func (r *detectionRepository) GetSpeciesFirstDetectionInPeriod(ctx context.Conte
 	// Use window function to rank detections per species (by scientific_name) by timestamp
 	// This ensures we get the actual detection_id that corresponds to the first_detected time
 	// Partitioning by scientific_name aggregates across all models for the same species
+	nodeClause, nodeArgs := r.nodeFilterClause(ctx, "d.source_id")
 	rawSQL := fmt.Sprintf(`
 		SELECT label_id, scientific_name, first_detected, detection_id
 		FROM (
@@ -1478,14 +1750,16 @@ func (r *detectionRepository) GetSpeciesFirstDetectionInPeriod(ctx context.Conte
 				ROW_NUMBER() OVER (PARTITION BY l.scientific_name ORDER BY d.detected_at ASC, d.id ASC) as rn
 			FROM %s d
 			JOIN %s l ON l.id = d.label_id
-			WHERE d.detected_at >= ? AND d.detected_at < ?
+			WHERE d.detected_at >= ? AND d.detected_at < ? AND %s
 		) ranked
 		WHERE rn = 1
 		ORDER BY first_detected ASC
 		LIMIT ? OFFSET ?
-	`, r.tableName(), r.labelsTable())
+	`, r.tableName(), r.labelsTable(), nodeClause)
 
-	err := r.db.WithContext(ctx).Raw(rawSQL, start, end, limit, offset).Scan(&results).Error
+	args := append([]any{start, end}, nodeArgs...)
+	args = append(args, limit, offset)
+	err := r.db.WithContext(ctx).Raw(rawSQL, args...).Scan(&results).Error
 	return results, err
 }
 
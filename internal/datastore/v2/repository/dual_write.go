@@ -317,6 +317,9 @@ func (dw *DualWriteRepository) syncToV2(ctx context.Context, result *detection.R
 		if det.ClipName != nil {
 			updates["clip_name"] = *det.ClipName
 		}
+		if det.Bearing != nil {
+			updates["bearing"] = *det.Bearing
+		}
 		if err := dw.v2.Update(ctx, det.ID, updates); err != nil {
 			// ErrDetectionLocked is acceptable - record is protected
 			if !errors.Is(err, ErrDetectionLocked) {
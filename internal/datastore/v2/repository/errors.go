@@ -35,6 +35,12 @@ var (
 	// ErrCommentNotFound indicates the requested comment does not exist.
 	ErrCommentNotFound = errors.NewStd("comment not found")
 
+	// ErrTagNotFound indicates the tag is not set on the detection.
+	ErrTagNotFound = errors.NewStd("tag not found")
+
+	// ErrInvalidTag indicates a tag is empty or exceeds the maximum length.
+	ErrInvalidTag = errors.NewStd("invalid tag")
+
 	// ErrLockNotFound indicates no lock exists for the detection.
 	ErrLockNotFound = errors.NewStd("lock not found")
 
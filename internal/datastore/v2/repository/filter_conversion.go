@@ -331,6 +331,20 @@ func ResolveLocationsToSourceIDs(ctx context.Context, deps *FilterLookupDeps, lo
 // SearchFilters (API v2 Search) Conversion Helpers
 // =============================================================================
 
+// NormalizeTags normalizes tag filter values, dropping invalid entries and duplicates.
+// Returns nil when no valid tags remain so the tag filter is skipped.
+func NormalizeTags(tags []string) []string {
+	var result []string
+	for _, tag := range tags {
+		normalized, err := NormalizeTag(tag)
+		if err != nil || slices.Contains(result, normalized) {
+			continue
+		}
+		result = append(result, normalized)
+	}
+	return result
+}
+
 // parseDateString parses a date string in YYYY-MM-DD format to Unix timestamp.
 // Returns the start of day (00:00:00) or end of day (23:59:59) in the given timezone.
 // Returns (nil, nil) if dateStr is empty (no filter, not an error).
@@ -534,6 +548,11 @@ func ConvertSearchFilters(
 	// TimeOfDay to hours conversion
 	sf.IncludedHours = singleTimeOfDayToHours(filters.TimeOfDay)
 
+	// Tags and comment search
+	sf.Tags = NormalizeTags(filters.Tags)
+	sf.TagsMatchAll = filters.TagsMatchAll
+	sf.CommentQuery = strings.TrimSpace(filters.CommentQuery)
+
 	// Sorting
 	// Default sort is by detected_at descending
 	sf.SortBy = SortFieldDetectedAt
@@ -667,6 +686,11 @@ func ConvertAdvancedFilters(
 		sf.IsReviewed = filters.Verified
 	}
 
+	// Tags and comment search
+	sf.Tags = NormalizeTags(filters.Tags)
+	sf.TagsMatchAll = filters.TagsMatchAll
+	sf.CommentQuery = strings.TrimSpace(filters.CommentQuery)
+
 	// Entity lookups (require deps)
 	if deps != nil {
 		var err error
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/tphakala/birdnet-go/internal/datastore"
 	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	gorm_logger "gorm.io/gorm/logger"
 )
 
 // =============================================================================
@@ -201,6 +205,92 @@ func TestGetTimezoneOffset(t *testing.T) {
 	})
 }
 
+// =============================================================================
+// Tag Normalization Tests
+// =============================================================================
+
+func TestNormalizeTags(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"nil input", nil, nil},
+		{"lower-cases and trims", []string{"  Feeder "}, []string{"feeder"}},
+		{"collapses inner whitespace", []string{"dawn \t chorus"}, []string{"dawn chorus"}},
+		{"drops duplicates after normalization", []string{"Owl", "owl", "OWL"}, []string{"owl"}},
+		{"drops empty and too long", []string{"", "  ", strings.Repeat("x", 65), "ok"}, []string{"ok"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.want, NormalizeTags(tt.tags))
+		})
+	}
+}
+
+func TestCommentSearchQueries(t *testing.T) {
+	t.Parallel()
+
+	terms := commentSearchTerms(`juvenile "near-feeder" (dawn*) +`)
+	assert.Equal(t, []string{"juvenile", "nearfeeder", "dawn"}, terms)
+	assert.Equal(t, `"juvenile"* "nearfeeder"* "dawn"*`, fts5Query(terms))
+	assert.Equal(t, "+juvenile* +nearfeeder* +dawn*", mysqlBooleanQuery(terms))
+	assert.Empty(t, commentSearchTerms(`"*" -`))
+}
+
+func TestMySQLFulltextTerms(t *testing.T) {
+	t.Parallel()
+
+	indexed, unindexed := mysqlFulltextTerms([]string{"owl", "at", "The", "feeder", "kä"})
+	assert.Equal(t, []string{"owl", "feeder"}, indexed)
+	assert.Equal(t, []string{"at", "The", "kä"}, unindexed, "short words and stopwords are not in the FULLTEXT index")
+}
+
+func TestApplyCommentSearch_MySQLUnindexedTerms(t *testing.T) {
+	t.Parallel()
+
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{
+		DryRun: true,
+		Logger: gorm_logger.Default.LogMode(gorm_logger.Silent),
+	})
+	require.NoError(t, err)
+	repo := &detectionRepository{db: db, isMySQL: true}
+
+	toSQL := func(text string) string {
+		return db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return repo.applyCommentSearch(tx.Model(&entities.Detection{}), text).Find(&[]entities.Detection{})
+		})
+	}
+
+	tests := []struct {
+		name     string
+		text     string
+		contains []string
+		excludes []string
+	}{
+		{"indexed terms use MATCH", "juvenile feeder", []string{`AGAINST ("+juvenile* +feeder*" IN BOOLEAN MODE)`}, []string{"LIKE"}},
+		{"unindexed terms add LIKE", "owl at feeder", []string{`AGAINST ("+owl* +feeder*" IN BOOLEAN MODE)`, `LIKE "%at%"`}, nil},
+		{"only unindexed terms use LIKE", "to be", []string{`LIKE "%to be%"`}, []string{"MATCH"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			sql := toSQL(tt.text)
+			for _, want := range tt.contains {
+				assert.Contains(t, sql, want)
+			}
+			for _, unwanted := range tt.excludes {
+				assert.NotContains(t, sql, unwanted)
+			}
+		})
+	}
+}
+
 // =============================================================================
 // ConfidenceFilterToMinMax Tests
 // =============================================================================
@@ -533,6 +623,12 @@ func (m *mockAudioSourceRepository) Update(_ context.Context, _ uint, _ map[stri
 func (m *mockAudioSourceRepository) Exists(_ context.Context, _ uint) (bool, error) {
 	return false, nil
 }
+func (m *mockAudioSourceRepository) Create(_ context.Context, _ *entities.AudioSource) error {
+	return nil
+}
+func (m *mockAudioSourceRepository) GetManaged(_ context.Context) ([]*entities.AudioSource, error) {
+	return nil, nil
+}
 
 // =============================================================================
 // ResolveSpeciesToLabelIDs Tests
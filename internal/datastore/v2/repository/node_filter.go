@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// nodeFilterKey is the context key for the analytics node filter.
+type nodeFilterKey struct{}
+
+// WithNodeFilter returns a context that restricts analytics queries to detections
+// whose audio source belongs to the given node. An empty node name leaves
+// queries unrestricted.
+func WithNodeFilter(ctx context.Context, nodeName string) context.Context {
+	nodeName = strings.TrimSpace(nodeName)
+	if nodeName == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, nodeFilterKey{}, nodeName)
+}
+
+// NodeFilterFromContext returns the node name set by WithNodeFilter, if any.
+func NodeFilterFromContext(ctx context.Context) (string, bool) {
+	nodeName, ok := ctx.Value(nodeFilterKey{}).(string)
+	return nodeName, ok && nodeName != ""
+}
+
+// NodeFilterClause returns a WHERE fragment restricting sourceColumn to audio
+// sources of the node in ctx, together with its argument. It returns an empty
+// clause when no node filter is set.
+func NodeFilterClause(ctx context.Context, sourceColumn, sourcesTable string) (clause string, args []any) {
+	nodeName, ok := NodeFilterFromContext(ctx)
+	if !ok {
+		return "", nil
+	}
+	return fmt.Sprintf("%s IN (SELECT id FROM %s WHERE node_name = ?)", sourceColumn, sourcesTable), []any{nodeName}
+}
+
+// nodeFilterClause returns the node filter for a detections column, or "1 = 1"
+// when no filter is set, so it can be embedded in raw SQL unconditionally.
+func (r *detectionRepository) nodeFilterClause(ctx context.Context, sourceColumn string) (clause string, args []any) {
+	clause, args = NodeFilterClause(ctx, sourceColumn, r.sourcesTable())
+	if clause == "" {
+		return "1 = 1", nil
+	}
+	return clause, args
+}
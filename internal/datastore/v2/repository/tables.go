@@ -11,6 +11,8 @@ const (
 	tableDetectionPredictions = "detection_predictions"
 	tableDetectionReviews     = "detection_reviews"
 	tableDetectionComments    = "detection_comments"
+	tableDetectionTags        = "detection_tags"
+	tableDetectionCommentsFTS = "detection_comments_fts"
 	tableDetectionLocks       = "detection_locks"
 	// Auxiliary tables
 	tableDailyEvents         = "daily_events"
@@ -32,6 +34,7 @@ const (
 	tableV2DetectionPredictions = "v2_detection_predictions"
 	tableV2DetectionReviews     = "v2_detection_reviews"
 	tableV2DetectionComments    = "v2_detection_comments"
+	tableV2DetectionTags        = "v2_detection_tags"
 	tableV2DetectionLocks       = "v2_detection_locks"
 	// Auxiliary tables
 	tableV2DailyEvents         = "v2_daily_events"
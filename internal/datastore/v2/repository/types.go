@@ -51,6 +51,18 @@ type SearchFilters struct {
 	// IsLocked filters by lock status (optional).
 	IsLocked *bool
 
+	// Tags filters by detection tags (optional).
+	// Tags must already be normalized (see NormalizeTag).
+	Tags []string
+
+	// TagsMatchAll requires every tag in Tags to be present when true.
+	// When false, a detection matching any of the tags is included.
+	TagsMatchAll bool
+
+	// CommentQuery provides full-text search across detection comments (optional).
+	// Uses FTS5 on SQLite and FULLTEXT on MySQL, falling back to LIKE.
+	CommentQuery string
+
 	// SortBy specifies sort field: "detected_at", "confidence", "species", or "status".
 	SortBy string
 
@@ -213,3 +225,12 @@ type NewSpeciesData struct {
 	// Confidence is the confidence score of the first detection.
 	Confidence float64
 }
+
+// TagCount represents a tag with the number of detections carrying it.
+type TagCount struct {
+	// Tag is the normalized tag text.
+	Tag string
+
+	// Count is the number of detections with this tag.
+	Count int64
+}
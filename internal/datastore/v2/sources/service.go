@@ -0,0 +1,496 @@
+// Package sources manages user-defined audio sources stored in the v2 database.
+//
+// In enhanced database mode the database is the source of truth for stream
+// definitions. Streams from the config file are imported on first start for
+// backward compatibility, after which the enabled database sources drive
+// capture. Each source keeps its database ID for life, so renaming a source or
+// changing its URL does not detach its historical detections.
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// DefaultNodeName is the node name used when the station has no name configured.
+// It matches the node name used when detections are saved.
+const DefaultNodeName = "default"
+
+var (
+	// ErrInvalidSource indicates the source definition failed validation.
+	ErrInvalidSource = errors.NewStd("invalid audio source")
+
+	// ErrDuplicateName indicates another managed source already uses the name.
+	ErrDuplicateName = errors.NewStd("audio source name already in use")
+)
+
+// Spec describes a new managed audio source.
+type Spec struct {
+	Name      string
+	URL       string
+	Type      string // Stream type: rtsp, http, hls, rtmp, udp, srt
+	Transport string // tcp or udp, empty defaults to tcp
+	SRT       conf.SRTSettings
+	Enabled   bool
+	Latitude  *float64
+	Longitude *float64
+	Notes     *string
+}
+
+// Patch describes a partial update of a managed audio source.
+// Nil fields are left unchanged.
+type Patch struct {
+	Name          *string
+	URL           *string
+	Type          *string
+	Transport     *string
+	SRT           *conf.SRTSettings
+	Enabled       *bool
+	Latitude      *float64
+	Longitude     *float64
+	ClearLocation bool // Removes the per-source location
+	Notes         *string
+}
+
+// ImportResult summarizes a config import or reconcile run.
+type ImportResult struct {
+	Created   int `json:"created"`
+	Updated   int `json:"updated"`
+	Unchanged int `json:"unchanged"`
+	Disabled  int `json:"disabled"`
+	Skipped   int `json:"skipped"`
+}
+
+// Service provides CRUD and config synchronization for managed audio sources.
+type Service struct {
+	repo     repository.AudioSourceRepository
+	nodeName string
+}
+
+// NewService creates a Service for the given node.
+// An empty nodeName falls back to DefaultNodeName.
+func NewService(repo repository.AudioSourceRepository, nodeName string) *Service {
+	if nodeName == "" {
+		nodeName = DefaultNodeName
+	}
+	return &Service{repo: repo, nodeName: nodeName}
+}
+
+// List returns all managed sources ordered by ID.
+func (s *Service) List(ctx context.Context) ([]*entities.AudioSource, error) {
+	return s.repo.GetManaged(ctx)
+}
+
+// Get returns a managed source by ID.
+// Returns repository.ErrAudioSourceNotFound for unknown or unmanaged sources.
+func (s *Service) Get(ctx context.Context, id uint) (*entities.AudioSource, error) {
+	source, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !source.Managed {
+		return nil, repository.ErrAudioSourceNotFound
+	}
+	return source, nil
+}
+
+// Create adds a managed source. An unmanaged source with the same URI, created
+// implicitly by earlier detections, is adopted so its history is preserved.
+func (s *Service) Create(ctx context.Context, spec *Spec) (*entities.AudioSource, error) {
+	stream := conf.StreamConfig{
+		Name:      strings.TrimSpace(spec.Name),
+		URL:       strings.TrimSpace(spec.URL),
+		Type:      spec.Type,
+		Transport: spec.Transport,
+		SRT:       spec.SRT,
+	}
+	if err := validate(&stream, spec.Latitude, spec.Longitude); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, stream.Name, 0); err != nil {
+		return nil, err
+	}
+
+	uri := privacy.SanitizeStreamUrl(stream.URL)
+	existing, err := s.repo.GetBySourceURI(ctx, uri, s.nodeName)
+	switch {
+	case err == nil && existing.Managed:
+		return nil, repository.ErrDuplicateKey
+	case err == nil:
+		updates := streamUpdates(&stream)
+		updates["managed"] = true
+		updates["enabled"] = spec.Enabled
+		updates["latitude"] = spec.Latitude
+		updates["longitude"] = spec.Longitude
+		updates["notes"] = spec.Notes
+		if err := s.repo.Update(ctx, existing.ID, updates); err != nil {
+			return nil, err
+		}
+		return s.repo.GetByID(ctx, existing.ID)
+	case !errors.Is(err, repository.ErrAudioSourceNotFound):
+		return nil, err
+	}
+
+	source := newManagedSource(&stream, uri, s.nodeName)
+	source.Enabled = spec.Enabled
+	source.Latitude = spec.Latitude
+	source.Longitude = spec.Longitude
+	source.Notes = spec.Notes
+	if err := s.repo.Create(ctx, source); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// Update applies a partial update to a managed source. The source keeps its ID,
+// so renames and URL changes do not affect historical detections.
+func (s *Service) Update(ctx context.Context, id uint, patch *Patch) (*entities.AudioSource, error) {
+	source, err := s.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := ToStreamConfig(source)
+	if patch.Name != nil {
+		stream.Name = strings.TrimSpace(*patch.Name)
+	}
+	if patch.URL != nil {
+		stream.URL = strings.TrimSpace(*patch.URL)
+	}
+	if patch.Type != nil {
+		stream.Type = *patch.Type
+	}
+	if patch.Transport != nil {
+		stream.Transport = *patch.Transport
+	}
+	if patch.SRT != nil {
+		stream.SRT = *patch.SRT
+	}
+
+	lat, lon := source.Latitude, source.Longitude
+	if patch.ClearLocation {
+		lat, lon = nil, nil
+	}
+	if patch.Latitude != nil || patch.Longitude != nil {
+		lat, lon = patch.Latitude, patch.Longitude
+	}
+	if err := validate(&stream, lat, lon); err != nil {
+		return nil, err
+	}
+	if err := s.checkNameAvailable(ctx, stream.Name, id); err != nil {
+		return nil, err
+	}
+
+	updates := streamUpdates(&stream)
+	if updates["source_uri"] != source.SourceURI {
+		other, err := s.repo.GetBySourceURI(ctx, updates["source_uri"].(string), source.NodeName)
+		if err == nil && other.ID != id {
+			return nil, repository.ErrDuplicateKey
+		}
+		if err != nil && !errors.Is(err, repository.ErrAudioSourceNotFound) {
+			return nil, err
+		}
+	}
+	updates["latitude"] = lat
+	updates["longitude"] = lon
+	if patch.Enabled != nil {
+		updates["enabled"] = *patch.Enabled
+	}
+	if patch.Notes != nil {
+		updates["notes"] = emptyToNil(*patch.Notes)
+	}
+
+	if err := s.repo.Update(ctx, id, updates); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(ctx, id)
+}
+
+// Delete stops managing a source. The row is kept, without its credentials,
+// so historical detections remain linked to it.
+func (s *Service) Delete(ctx context.Context, id uint) error {
+	if _, err := s.Get(ctx, id); err != nil {
+		return err
+	}
+	return s.repo.Update(ctx, id, map[string]any{
+		"managed":     false,
+		"enabled":     false,
+		"stream_url":  nil,
+		"config_json": nil,
+		"updated_at":  time.Now(),
+	})
+}
+
+// EnabledStreams returns the enabled managed sources as stream configurations.
+func (s *Service) EnabledStreams(ctx context.Context) ([]conf.StreamConfig, error) {
+	managed, err := s.repo.GetManaged(ctx)
+	if err != nil {
+		return nil, err
+	}
+	streams := make([]conf.StreamConfig, 0, len(managed))
+	for _, source := range managed {
+		if !source.Enabled || source.StreamURL == nil {
+			continue
+		}
+		streams = append(streams, ToStreamConfig(source))
+	}
+	return streams, nil
+}
+
+// SeedFromConfig imports config file streams when no managed sources exist yet.
+// Returns false when the database already defines sources and nothing was imported.
+func (s *Service) SeedFromConfig(ctx context.Context, streams []conf.StreamConfig) (bool, ImportResult, error) {
+	managed, err := s.repo.GetManaged(ctx)
+	if err != nil {
+		return false, ImportResult{}, err
+	}
+	if len(managed) > 0 {
+		return false, ImportResult{}, nil
+	}
+	result, err := s.ImportStreams(ctx, streams)
+	return true, result, err
+}
+
+// ImportStreams creates or updates managed sources from stream configurations.
+// Streams are matched to existing sources by URI, so a stream renamed in the
+// config file updates the existing source instead of creating a new one.
+// Invalid streams are skipped.
+func (s *Service) ImportStreams(ctx context.Context, streams []conf.StreamConfig) (ImportResult, error) {
+	var result ImportResult
+	for i := range streams {
+		stream := streams[i]
+		stream.Name = strings.TrimSpace(stream.Name)
+		stream.URL = strings.TrimSpace(stream.URL)
+		if err := stream.Validate(); err != nil {
+			result.Skipped++
+			continue
+		}
+
+		uri := privacy.SanitizeStreamUrl(stream.URL)
+		existing, err := s.repo.GetBySourceURI(ctx, uri, s.nodeName)
+		if errors.Is(err, repository.ErrAudioSourceNotFound) {
+			source := newManagedSource(&stream, uri, s.nodeName)
+			source.Enabled = true
+			if err := s.repo.Create(ctx, source); err != nil {
+				return result, err
+			}
+			result.Created++
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+
+		if existing.Managed && existing.Enabled && sameStream(ToStreamConfig(existing), &stream) {
+			result.Unchanged++
+			continue
+		}
+		updates := streamUpdates(&stream)
+		updates["managed"] = true
+		updates["enabled"] = true
+		if err := s.repo.Update(ctx, existing.ID, updates); err != nil {
+			return result, err
+		}
+		result.Updated++
+	}
+	return result, nil
+}
+
+// ReconcileStreams makes the managed sources match the given stream list:
+// listed streams are imported and enabled, other managed sources are disabled.
+// Disabled sources keep their settings so they can be re-enabled later.
+func (s *Service) ReconcileStreams(ctx context.Context, streams []conf.StreamConfig) (ImportResult, error) {
+	result, err := s.ImportStreams(ctx, streams)
+	if err != nil {
+		return result, err
+	}
+
+	listed := make(map[string]bool, len(streams))
+	for i := range streams {
+		listed[privacy.SanitizeStreamUrl(strings.TrimSpace(streams[i].URL))] = true
+	}
+
+	managed, err := s.repo.GetManaged(ctx)
+	if err != nil {
+		return result, err
+	}
+	for _, source := range managed {
+		if !source.Enabled || listed[source.SourceURI] {
+			continue
+		}
+		if err := s.repo.Update(ctx, source.ID, map[string]any{"enabled": false, "updated_at": time.Now()}); err != nil {
+			return result, err
+		}
+		result.Disabled++
+	}
+	return result, nil
+}
+
+// ToStreamConfig converts a managed source to a stream configuration.
+func ToStreamConfig(source *entities.AudioSource) conf.StreamConfig {
+	stream := conf.StreamConfig{
+		Type: string(source.SourceType),
+	}
+	if source.DisplayName != nil {
+		stream.Name = *source.DisplayName
+	}
+	if source.StreamURL != nil {
+		stream.URL = *source.StreamURL
+	}
+	if source.Transport != nil {
+		stream.Transport = *source.Transport
+	}
+	if source.ConfigJSON != nil {
+		var options streamOptions
+		if err := json.Unmarshal([]byte(*source.ConfigJSON), &options); err == nil {
+			if options.SRT != nil {
+				stream.SRT = *options.SRT
+			}
+			if options.Calibration != nil {
+				stream.Calibration = *options.Calibration
+			}
+		}
+	}
+	return stream
+}
+
+// sameStream reports whether a stored source matches a stream. Recording
+// schedules are kept in the config file only and are not compared.
+func sameStream(stored conf.StreamConfig, stream *conf.StreamConfig) bool {
+	return stored.Name == stream.Name && stored.URL == stream.URL &&
+		stored.Type == stream.Type && stored.Transport == stream.Transport &&
+		stored.SRT == stream.SRT && stored.Calibration == stream.Calibration
+}
+
+// streamOptions holds the protocol specific stream options and the sound
+// level calibration stored in the config_json column.
+type streamOptions struct {
+	SRT         *conf.SRTSettings         `json:"srt,omitempty"`
+	Calibration *conf.CalibrationSettings `json:"calibration,omitempty"`
+}
+
+// optionsJSON returns the config_json value of a stream, nil when the stream
+// has no protocol specific options or calibration.
+func optionsJSON(stream *conf.StreamConfig) *string {
+	var options streamOptions
+	if stream.Type == conf.StreamTypeSRT && stream.SRT != (conf.SRTSettings{}) {
+		options.SRT = &stream.SRT
+	}
+	if stream.Calibration != (conf.CalibrationSettings{}) {
+		options.Calibration = &stream.Calibration
+	}
+	if options == (streamOptions{}) {
+		return nil
+	}
+	data, err := json.Marshal(options)
+	if err != nil {
+		return nil
+	}
+	value := string(data)
+	return &value
+}
+
+// PreserveStreamSettings copies the recording schedules of configured streams
+// to the streams loaded from the database, matching streams by URL. Schedules
+// are not stored in the database, so this keeps them when the configured
+// streams are replaced. Calibrations are copied to streams stored without one,
+// such as streams imported before calibrations were stored.
+func PreserveStreamSettings(streams, configured []conf.StreamConfig) {
+	byURL := make(map[string]*conf.StreamConfig, len(configured))
+	for i := range configured {
+		byURL[strings.TrimSpace(configured[i].URL)] = &configured[i]
+	}
+	for i := range streams {
+		stream, ok := byURL[streams[i].URL]
+		if !ok {
+			continue
+		}
+		if stream.Schedule.Enabled || len(stream.Schedule.Windows) > 0 {
+			streams[i].Schedule = stream.Schedule
+		}
+		if streams[i].Calibration == (conf.CalibrationSettings{}) {
+			streams[i].Calibration = stream.Calibration
+		}
+	}
+}
+
+// newManagedSource builds a managed source entity from a validated stream.
+func newManagedSource(stream *conf.StreamConfig, uri, nodeName string) *entities.AudioSource {
+	name := stream.Name
+	url := stream.URL
+	return &entities.AudioSource{
+		SourceURI:   uri,
+		NodeName:    nodeName,
+		SourceType:  entities.SourceType(stream.Type),
+		DisplayName: &name,
+		StreamURL:   &url,
+		Transport:   emptyToNil(stream.Transport),
+		ConfigJSON:  optionsJSON(stream),
+		Managed:     true,
+	}
+}
+
+// streamUpdates returns the column updates for a validated stream definition.
+// updated_at is always set so MySQL reports the row as affected even when
+// nothing else changed.
+func streamUpdates(stream *conf.StreamConfig) map[string]any {
+	return map[string]any{
+		"source_uri":   privacy.SanitizeStreamUrl(stream.URL),
+		"source_type":  stream.Type,
+		"display_name": stream.Name,
+		"stream_url":   stream.URL,
+		"transport":    emptyToNil(stream.Transport),
+		"config_json":  optionsJSON(stream),
+		"updated_at":   time.Now(),
+	}
+}
+
+// checkNameAvailable returns ErrDuplicateName if another managed source uses the name.
+// Names are compared case-insensitively, matching config stream validation.
+func (s *Service) checkNameAvailable(ctx context.Context, name string, selfID uint) error {
+	managed, err := s.repo.GetManaged(ctx)
+	if err != nil {
+		return err
+	}
+	for _, source := range managed {
+		if source.ID == selfID || source.DisplayName == nil {
+			continue
+		}
+		if strings.EqualFold(*source.DisplayName, name) {
+			return ErrDuplicateName
+		}
+	}
+	return nil
+}
+
+// validate checks the stream definition and optional coordinates.
+func validate(stream *conf.StreamConfig, lat, lon *float64) error {
+	if err := stream.Validate(); err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidSource, err)
+	}
+	if (lat == nil) != (lon == nil) {
+		return fmt.Errorf("%w: latitude and longitude must be set together", ErrInvalidSource)
+	}
+	if lat != nil && (math.Abs(*lat) > 90 || math.Abs(*lon) > 180) {
+		return fmt.Errorf("%w: coordinates out of range", ErrInvalidSource)
+	}
+	return nil
+}
+
+// emptyToNil returns nil for empty strings so optional columns are stored as NULL.
+func emptyToNil(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
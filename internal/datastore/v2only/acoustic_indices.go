@@ -0,0 +1,108 @@
+package v2only
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+)
+
+// SaveAcousticIndices stores acoustic indices. Sources are resolved as for
+// sound levels, so indices and levels of a source share its ID.
+func (ds *Datastore) SaveAcousticIndices(ctx context.Context, records []datastore.AcousticIndicesRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if ds.source == nil {
+		return fmt.Errorf("%w: audio source repository is not available", repository.ErrInvalidInput)
+	}
+
+	resolve := ds.soundLevelSourceResolver()
+	rows := make([]entities.AcousticIndex, 0, len(records))
+	for i := range records {
+		r := &records[i]
+		if r.SourceURI == "" {
+			continue
+		}
+		sourceID, err := resolve(ctx, r.SourceURI, r.NodeName, r.SourceName)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, entities.AcousticIndex{
+			SourceID:  sourceID,
+			StartTime: r.Start.Unix(),
+			Duration:  r.Duration,
+			ACI:       r.ACI,
+			ADI:       r.ADI,
+			NDSI:      r.NDSI,
+			BI:        r.BI,
+			H:         r.H,
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return ds.manager.DB().WithContext(ctx).CreateInBatches(rows, 100).Error
+}
+
+// GetAcousticIndices returns acoustic index records starting within the query
+// range, ordered by source and start time. query.Source matches the source URI
+// or display name.
+func (ds *Datastore) GetAcousticIndices(ctx context.Context, query datastore.SoundLevelQuery) ([]datastore.AcousticIndicesRecord, error) {
+	db := ds.manager.DB().WithContext(ctx).
+		Preload("Source").
+		Where("start_time >= ? AND start_time < ?", query.Start.Unix(), query.End.Unix())
+
+	sourceIDs, err := ds.soundLevelSourceIDs(ctx, query.Source)
+	if err != nil {
+		return nil, err
+	}
+	if sourceIDs != nil {
+		if len(sourceIDs) == 0 {
+			return []datastore.AcousticIndicesRecord{}, nil
+		}
+		db = db.Where("source_id IN ?", sourceIDs)
+	}
+
+	var rows []entities.AcousticIndex
+	if err := db.Order("source_id, start_time").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get acoustic indices: %w", err)
+	}
+
+	records := make([]datastore.AcousticIndicesRecord, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		record := datastore.AcousticIndicesRecord{
+			Start:    time.Unix(row.StartTime, 0),
+			Duration: row.Duration,
+			ACI:      row.ACI,
+			ADI:      row.ADI,
+			NDSI:     row.NDSI,
+			BI:       row.BI,
+			H:        row.H,
+		}
+		if row.Source != nil {
+			record.SourceURI = row.Source.SourceURI
+			record.NodeName = row.Source.NodeName
+			if row.Source.DisplayName != nil {
+				record.SourceName = *row.Source.DisplayName
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// PruneAcousticIndices deletes acoustic index records starting before the cutoff.
+func (ds *Datastore) PruneAcousticIndices(ctx context.Context, before time.Time) (int64, error) {
+	result := ds.manager.DB().WithContext(ctx).
+		Where("start_time < ?", before.Unix()).
+		Delete(&entities.AcousticIndex{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune acoustic indices: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,51 @@
+package v2only
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestV2OnlyDatastore_AcousticIndices(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	start := time.Date(2024, 5, 1, 4, 10, 0, 0, time.Local)
+	records := make([]datastore.AcousticIndicesRecord, 0, 12)
+	for i := range 10 {
+		records = append(records, datastore.AcousticIndicesRecord{
+			SourceURI: "hw:1,0", SourceName: "Garden",
+			Start: start.Add(time.Duration(i) * time.Minute), Duration: 60,
+			ACI: 150 + float64(i), ADI: 1.8, NDSI: 0.4, BI: 12.5, H: 0.85,
+		})
+	}
+	records = append(records,
+		datastore.AcousticIndicesRecord{SourceURI: "rtsp://cam", Start: start, Duration: 60, NDSI: -0.6},
+		datastore.AcousticIndicesRecord{Start: start, Duration: 60}, // no source, skipped
+	)
+	require.NoError(t, ds.SaveAcousticIndices(t.Context(), records))
+
+	got, err := ds.GetAcousticIndices(t.Context(), datastore.SoundLevelQuery{Source: "Garden", Start: start, End: start.Add(5 * time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, got, 5)
+	assert.Equal(t, "hw:1,0", got[0].SourceURI)
+	assert.Equal(t, "Garden", got[0].SourceName)
+	assert.Equal(t, start.Unix(), got[0].Start.Unix())
+	assert.InDelta(t, 151.0, got[1].ACI, 0.001)
+	assert.InDelta(t, 0.85, got[1].H, 0.001)
+
+	all, err := ds.GetAcousticIndices(t.Context(), datastore.SoundLevelQuery{Start: start, End: start.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, all, 11)
+
+	none, err := ds.GetAcousticIndices(t.Context(), datastore.SoundLevelQuery{Source: "unknown", Start: start, End: start.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	pruned, err := ds.PruneAcousticIndices(t.Context(), start.Add(3*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), pruned, "three minutes of the sound card and one of the camera")
+}
@@ -280,6 +280,11 @@ func (ds *Datastore) Manager() v2.Manager {
 	return ds.manager
 }
 
+// AudioSources returns the audio source repository (may be nil).
+func (ds *Datastore) AudioSources() repository.AudioSourceRepository {
+	return ds.source
+}
+
 // SetSunCalcMetrics sets the SunCalc metrics instance for observability.
 func (ds *Datastore) SetSunCalcMetrics(suncalcMetrics any) {
 	if ds.suncalc != nil && suncalcMetrics != nil {
@@ -330,14 +335,27 @@ func (ds *Datastore) GetDatabaseStats() (*datastore.DatabaseStats, error) {
 // Save saves a note with its results atomically.
 // The detection and its predictions are saved in a single transaction to prevent
 // partial writes (e.g., detection saved but predictions failed).
+// On success note.ID is set to the new detection ID.
 func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) error {
+	id, err := ds.saveDetection(note, results, nil)
+	if err != nil {
+		return err
+	}
+	note.ID = id
+	return nil
+}
+
+// saveDetection saves a note with its results and returns the detection ID.
+// If inTx is non-nil it runs inside the save transaction after the detection
+// row is created; returning an error rolls back the whole save.
+func (ds *Datastore) saveDetection(note *datastore.Note, results []datastore.Results, inTx func(tx *gorm.DB, det *entities.Detection) error) (uint, error) {
 	ctx := context.Background()
 
 	// Get or create default model first (needed for model-specific labels)
 	modelInfo := detection.DefaultModelInfo()
 	model, err := ds.model.GetOrCreate(ctx, modelInfo.Name, modelInfo.Version, modelInfo.Variant, entities.ModelTypeBird, modelInfo.ClassifierPath)
 	if err != nil {
-		return fmt.Errorf("failed to get/create model: %w", err)
+		return 0, fmt.Errorf("failed to get/create model: %w", err)
 	}
 
 	// NOTE: Label GetOrCreate calls are outside the transaction.
@@ -345,7 +363,7 @@ func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) err
 	// This is acceptable as they will be reused on subsequent saves.
 	label, err := ds.label.GetOrCreate(ctx, note.ScientificName, model.ID, ds.speciesLabelTypeID, ds.avesClassID)
 	if err != nil {
-		return fmt.Errorf("failed to get/create label: %w", err)
+		return 0, fmt.Errorf("failed to get/create label: %w", err)
 	}
 
 	// Pre-resolve all prediction labels before starting transaction.
@@ -361,7 +379,7 @@ func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) err
 		// Batch resolve all labels (returns map[scientificName]*Label)
 		labelMap, err := ds.label.BatchGetOrCreate(ctx, speciesNames, model.ID, ds.speciesLabelTypeID, ds.avesClassID)
 		if err != nil {
-			return fmt.Errorf("failed to batch get/create prediction labels: %w", err)
+			return 0, fmt.Errorf("failed to batch get/create prediction labels: %w", err)
 		}
 
 		// Build predLabels slice from map, preserving order
@@ -369,7 +387,7 @@ func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) err
 		for i, r := range results {
 			lbl, ok := labelMap[r.Species]
 			if !ok {
-				return fmt.Errorf("label not found for species %s after batch creation", r.Species)
+				return 0, fmt.Errorf("label not found for species %s after batch creation", r.Species)
 			}
 			predLabels[i] = lbl
 		}
@@ -406,6 +424,7 @@ func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) err
 		pt := note.ProcessingTime.Milliseconds()
 		det.ProcessingTimeMs = &pt
 	}
+	det.Bearing = note.Bearing
 
 	// Resolve audio source if provided (follows same pattern as conversion.go)
 	if note.Source.SafeString != "" && ds.source != nil {
@@ -429,6 +448,11 @@ func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) err
 			// Continue without source - not fatal
 		} else {
 			det.SourceID = &source.ID
+			// Managed sources may define their own location (e.g. a remote camera)
+			if source.Managed && source.HasLocation() {
+				det.Latitude = source.Latitude
+				det.Longitude = source.Longitude
+			}
 		}
 	} else if note.Source.SafeString != "" && ds.source == nil {
 		if ds.log != nil {
@@ -444,7 +468,7 @@ func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) err
 	// DIRECT DB WRITE: We use tx.Create directly instead of ds.detection.Save()
 	// to ensure both detection and predictions are in the same transaction.
 	// The repository doesn't currently support transaction injection.
-	return ds.manager.DB().WithContext(txCtx).Transaction(func(tx *gorm.DB) error {
+	err = ds.manager.DB().WithContext(txCtx).Transaction(func(tx *gorm.DB) error {
 		if err := tx.Create(det).Error; err != nil {
 			return fmt.Errorf("failed to save detection: %w", err)
 		}
@@ -465,8 +489,15 @@ func (ds *Datastore) Save(note *datastore.Note, results []datastore.Results) err
 			}
 		}
 
+		if inTx != nil {
+			return inTx(tx, det)
+		}
 		return nil
 	})
+	if err != nil {
+		return 0, err
+	}
+	return det.ID, nil
 }
 
 // Delete deletes a note by ID.
@@ -607,6 +638,7 @@ func (ds *Datastore) detectionToNote(det *entities.Detection) datastore.Note {
 		EndTime:        endTime,
 		ProcessingTime: processingTime,
 		Source:         source,
+		Bearing:        det.Bearing,
 		Comments:       comments,
 		Verified:       verified,
 		Locked:         locked,
@@ -800,6 +832,11 @@ func (ds *Datastore) GetAllNotes() ([]datastore.Note, error) {
 
 // GetTopBirdsData retrieves top birds data for a date.
 func (ds *Datastore) GetTopBirdsData(selectedDate string, minConfidenceNormalized float64, limit int) ([]datastore.Note, error) {
+	return ds.GetTopBirdsDataContext(context.Background(), selectedDate, minConfidenceNormalized, limit)
+}
+
+// GetTopBirdsDataContext is GetTopBirdsData honouring the node filter carried by ctx.
+func (ds *Datastore) GetTopBirdsDataContext(ctx context.Context, selectedDate string, minConfidenceNormalized float64, limit int) ([]datastore.Note, error) {
 	t, err := time.ParseInLocation("2006-01-02", selectedDate, ds.timezone)
 	if err != nil {
 		return nil, err
@@ -828,8 +865,7 @@ func (ds *Datastore) GetTopBirdsData(selectedDate string, minConfidenceNormalize
 	// detection_predictions table (which only stores secondary predictions).
 	// Secondary sort by scientific_name ensures deterministic results when counts are equal.
 	// Excludes detections marked as false_positive.
-	db := ds.manager.DB()
-	err = db.Table("detections d").
+	query := ds.manager.DB().WithContext(ctx).Table("detections d").
 		Select(`
 			l.scientific_name,
 			COUNT(d.id) as count,
@@ -840,7 +876,8 @@ func (ds *Datastore) GetTopBirdsData(selectedDate string, minConfidenceNormalize
 		Joins("LEFT JOIN detection_reviews dr ON d.id = dr.detection_id").
 		Where("d.detected_at >= ? AND d.detected_at < ?", startTime, endTime).
 		Where("d.confidence >= ?", minConfidenceNormalized).
-		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive)).
+		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive))
+	err = applyNodeFilter(ctx, query).
 		Group("l.scientific_name").
 		Order("count DESC, l.scientific_name ASC").
 		Limit(reportCount).
@@ -912,12 +949,16 @@ func (ds *Datastore) GetHourlyOccurrences(date, commonName string, minConfidence
 
 // GetBatchHourlyOccurrences retrieves hourly detection counts for multiple species on a given date.
 func (ds *Datastore) GetBatchHourlyOccurrences(date string, species []string, minConfidence float64) (map[string][24]int, error) {
+	return ds.GetBatchHourlyOccurrencesContext(context.Background(), date, species, minConfidence)
+}
+
+// GetBatchHourlyOccurrencesContext is GetBatchHourlyOccurrences honouring the
+// node filter carried by ctx.
+func (ds *Datastore) GetBatchHourlyOccurrencesContext(ctx context.Context, date string, species []string, minConfidence float64) (map[string][24]int, error) {
 	if len(species) == 0 {
 		return make(map[string][24]int), nil
 	}
 
-	ctx := context.Background()
-
 	// Parse date
 	targetDate, err := time.ParseInLocation(time.DateOnly, date, ds.timezone)
 	if err != nil {
@@ -991,14 +1032,15 @@ func (ds *Datastore) GetBatchHourlyOccurrences(date string, species []string, mi
 
 	var results []result
 	// Exclude detections marked as false_positive
-	err = ds.manager.DB().WithContext(ctx).
+	query := ds.manager.DB().WithContext(ctx).
 		Table("detections d").
 		Joins("LEFT JOIN detection_reviews dr ON d.id = dr.detection_id").
 		Select(fmt.Sprintf("d.label_id as label_id, %s as hour, COUNT(*) as count", hourExpr)).
 		Where("d.label_id IN ?", flatLabelIDs).
 		Where("d.detected_at >= ? AND d.detected_at < ?", startOfDay, endOfDay).
 		Where("d.confidence >= ?", minConfidence).
-		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive)).
+		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive))
+	err = applyNodeFilter(ctx, query).
 		Group(fmt.Sprintf("d.label_id, %s", hourExpr)).
 		Scan(&results).Error
 
@@ -2295,6 +2337,7 @@ func (ds *Datastore) GetSpeciesDiversityData(ctx context.Context, startDate, end
 		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive)).
 		Group(dateExpr).
 		Order("date")
+	query = applyNodeFilter(ctx, query)
 
 	// Apply date range filters
 	switch {
@@ -2320,6 +2363,149 @@ func (ds *Datastore) GetSpeciesDiversityData(ctx context.Context, startDate, end
 	return results, nil
 }
 
+// GetSpeciesDailyPresence returns per-species detection counts for each day in
+// the given date range, excluding detections reviewed as false positives.
+func (ds *Datastore) GetSpeciesDailyPresence(ctx context.Context, startDate, endDate string) ([]datastore.SpeciesDailyPresence, error) {
+	if startDate != "" {
+		if _, err := time.Parse(time.DateOnly, startDate); err != nil {
+			return nil, fmt.Errorf("invalid start date format (expected YYYY-MM-DD): %w", err)
+		}
+	}
+	if endDate != "" {
+		if _, err := time.Parse(time.DateOnly, endDate); err != nil {
+			return nil, fmt.Errorf("invalid end date format (expected YYYY-MM-DD): %w", err)
+		}
+	}
+
+	// Same local-time date bucketing as GetSpeciesDiversityData
+	var dateExpr string
+	if ds.manager.IsMySQL() {
+		dateExpr = "DATE(FROM_UNIXTIME(d.detected_at))"
+	} else {
+		dateExpr = "date(d.detected_at, 'unixepoch', 'localtime')"
+	}
+
+	var rows []struct {
+		ScientificName string
+		Date           string
+		Count          int
+	}
+	query := ds.manager.DB().WithContext(ctx).
+		Table("detections d").
+		Select(fmt.Sprintf("l.scientific_name, %s as date, COUNT(*) as count", dateExpr)).
+		Joins("JOIN labels l ON d.label_id = l.id").
+		Joins("LEFT JOIN detection_reviews dr ON d.id = dr.detection_id").
+		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive)).
+		Group(fmt.Sprintf("l.scientific_name, %s", dateExpr)).
+		Order("date")
+	query = applyNodeFilter(ctx, query)
+
+	switch {
+	case startDate != "" && endDate != "":
+		query = query.Where(fmt.Sprintf("%s BETWEEN ? AND ?", dateExpr), startDate, endDate)
+	case startDate != "":
+		query = query.Where(fmt.Sprintf("%s >= ?", dateExpr), startDate)
+	case endDate != "":
+		query = query.Where(fmt.Sprintf("%s <= ?", dateExpr), endDate)
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "get_species_daily_presence").
+			Context("start_date", startDate).
+			Context("end_date", endDate).
+			Build()
+	}
+
+	result := make([]datastore.SpeciesDailyPresence, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, datastore.SpeciesDailyPresence{
+			ScientificName: r.ScientificName,
+			CommonName:     ds.resolveCommonName(r.ScientificName),
+			Date:           r.Date,
+			Count:          r.Count,
+		})
+	}
+	return result, nil
+}
+
+// GetDetectionMinuteCounts returns detection counts per species, day and minute
+// of day, excluding detections reviewed as false positives.
+func (ds *Datastore) GetDetectionMinuteCounts(ctx context.Context, startDate, endDate, species string) ([]datastore.DetectionMinuteCount, error) {
+	if startDate != "" {
+		if _, err := time.Parse(time.DateOnly, startDate); err != nil {
+			return nil, fmt.Errorf("invalid start date format (expected YYYY-MM-DD): %w", err)
+		}
+	}
+	if endDate != "" {
+		if _, err := time.Parse(time.DateOnly, endDate); err != nil {
+			return nil, fmt.Errorf("invalid end date format (expected YYYY-MM-DD): %w", err)
+		}
+	}
+
+	var dateExpr, minuteExpr string
+	if ds.manager.IsMySQL() {
+		dateExpr = "DATE(FROM_UNIXTIME(d.detected_at))"
+		minuteExpr = "DATE_FORMAT(FROM_UNIXTIME(d.detected_at), '%H:%i')"
+	} else {
+		dateExpr = "date(d.detected_at, 'unixepoch', 'localtime')"
+		minuteExpr = "strftime('%H:%M', d.detected_at, 'unixepoch', 'localtime')"
+	}
+
+	var rows []struct {
+		ScientificName string
+		Date           string
+		Minute         string
+		Count          int
+	}
+	query := ds.manager.DB().WithContext(ctx).
+		Table("detections d").
+		Select(fmt.Sprintf("l.scientific_name, %s as date, %s as minute, COUNT(*) as count", dateExpr, minuteExpr)).
+		Joins("JOIN labels l ON d.label_id = l.id").
+		Joins("LEFT JOIN detection_reviews dr ON d.id = dr.detection_id").
+		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive)).
+		Group(fmt.Sprintf("l.scientific_name, %s, %s", dateExpr, minuteExpr)).
+		Order("date")
+	query = applyNodeFilter(ctx, query)
+
+	switch {
+	case startDate != "" && endDate != "":
+		query = query.Where(fmt.Sprintf("%s BETWEEN ? AND ?", dateExpr), startDate, endDate)
+	case startDate != "":
+		query = query.Where(fmt.Sprintf("%s >= ?", dateExpr), startDate)
+	case endDate != "":
+		query = query.Where(fmt.Sprintf("%s <= ?", dateExpr), endDate)
+	}
+	if species != "" {
+		query = query.Where("l.scientific_name = ?", ds.resolveToScientificName(species))
+	}
+
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, errors.New(err).
+			Component("datastore").
+			Category(errors.CategoryDatabase).
+			Context("operation", "get_detection_minute_counts").
+			Context("start_date", startDate).
+			Context("end_date", endDate).
+			Context("species", species).
+			Build()
+	}
+
+	result := make([]datastore.DetectionMinuteCount, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, datastore.DetectionMinuteCount{
+			ScientificName: r.ScientificName,
+			CommonName:     ds.resolveCommonName(r.ScientificName),
+			Date:           r.Date,
+			Minute:         r.Minute,
+			Count:          r.Count,
+		})
+	}
+	return result, nil
+}
+
 // ============================================================
 // Dynamic Threshold Methods
 // ============================================================
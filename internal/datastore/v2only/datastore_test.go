@@ -1,6 +1,7 @@
 package v2only
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -750,6 +751,104 @@ func TestV2OnlyDatastore_CommentOperations(t *testing.T) {
 	assert.Equal(t, "This is a test comment", comments[0].Entry)
 }
 
+// seedTaggedNotes saves three notes with comments and tags for search tests.
+func seedTaggedNotes(t *testing.T, ds *Datastore) {
+	t.Helper()
+	ctx := t.Context()
+
+	for i := range 3 {
+		note := &datastore.Note{
+			Date:           "2024-01-15",
+			Time:           fmt.Sprintf("12:3%d:00", i),
+			ScientificName: "Passer domesticus",
+			Confidence:     0.85,
+		}
+		require.NoError(t, ds.Save(note, nil))
+	}
+
+	comments := map[uint]string{
+		1: "Heard near the feeder at dawn",
+		2: "Possible juvenile, needs review",
+	}
+	for id, entry := range comments {
+		require.NoError(t, ds.SaveNoteComment(&datastore.NoteComment{NoteID: id, Entry: entry, CreatedAt: time.Now()}))
+	}
+
+	require.NoError(t, ds.detection.AddTags(ctx, 1, []string{"Feeder", "dawn chorus"}))
+	require.NoError(t, ds.detection.AddTags(ctx, 2, []string{"juvenile", "feeder"}))
+}
+
+func TestV2OnlyDatastore_SearchByTagsAndComments(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+	seedTaggedNotes(t, ds)
+
+	tests := []struct {
+		name    string
+		filters datastore.AdvancedSearchFilters
+		wantIDs []uint
+	}{
+		{"any tag", datastore.AdvancedSearchFilters{Tags: []string{"feeder"}}, []uint{1, 2}},
+		{"tag normalized", datastore.AdvancedSearchFilters{Tags: []string{"  DAWN   Chorus "}}, []uint{1}},
+		{"all tags", datastore.AdvancedSearchFilters{Tags: []string{"feeder", "juvenile"}, TagsMatchAll: true}, []uint{2}},
+		{"unknown tag", datastore.AdvancedSearchFilters{Tags: []string{"owl"}}, nil},
+		{"comment word", datastore.AdvancedSearchFilters{CommentQuery: "feeder"}, []uint{1}},
+		{"comment prefix", datastore.AdvancedSearchFilters{CommentQuery: "juven"}, []uint{2}},
+		{"comment and tag", datastore.AdvancedSearchFilters{CommentQuery: "dawn", Tags: []string{"juvenile"}}, nil},
+		{"comment operators only", datastore.AdvancedSearchFilters{CommentQuery: "\"*"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filters.SortAscending = true
+			notes, total, err := ds.SearchNotesAdvanced(&tt.filters)
+			require.NoError(t, err)
+
+			var gotIDs []uint
+			for i := range notes {
+				gotIDs = append(gotIDs, notes[i].ID)
+			}
+			assert.Equal(t, tt.wantIDs, gotIDs)
+			assert.Equal(t, int64(len(tt.wantIDs)), total)
+		})
+	}
+}
+
+func TestV2OnlyDatastore_TagOperations(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+	seedTaggedNotes(t, ds)
+	ctx := t.Context()
+
+	tags, err := ds.detection.GetTags(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"dawn chorus", "feeder"}, tags)
+
+	// Re-adding an existing tag is a no-op
+	require.NoError(t, ds.detection.AddTags(ctx, 1, []string{"FEEDER"}))
+
+	counts, err := ds.detection.GetAllTags(ctx)
+	require.NoError(t, err)
+	require.Len(t, counts, 3)
+	assert.Equal(t, repository.TagCount{Tag: "feeder", Count: 2}, counts[0])
+
+	err = ds.detection.AddTags(ctx, 1, []string{"   "})
+	require.ErrorIs(t, err, repository.ErrInvalidTag)
+
+	err = ds.detection.AddTags(ctx, 999, []string{"feeder"})
+	require.ErrorIs(t, err, repository.ErrDetectionNotFound)
+
+	require.NoError(t, ds.detection.RemoveTag(ctx, 1, "Feeder"))
+	err = ds.detection.RemoveTag(ctx, 1, "feeder")
+	require.ErrorIs(t, err, repository.ErrTagNotFound)
+
+	// Tags are removed together with their detection
+	require.NoError(t, ds.Delete("2"))
+	byID, err := ds.detection.GetTagsByDetectionIDs(ctx, []uint{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, map[uint][]string{1: {"dawn chorus"}}, byID)
+}
+
 func TestV2OnlyDatastore_Optimize(t *testing.T) {
 	ds, cleanup := setupTestDatastore(t)
 	defer cleanup()
@@ -855,6 +954,7 @@ func TestV2OnlyDatastore_SavePersistsAllFields(t *testing.T) {
 
 	beginTime := time.Date(2024, 1, 15, 12, 30, 0, 0, time.UTC)
 	endTime := time.Date(2024, 1, 15, 12, 30, 3, 0, time.UTC)
+	bearing := 247.5
 
 	note := &datastore.Note{
 		Date:           "2024-01-15",
@@ -865,6 +965,7 @@ func TestV2OnlyDatastore_SavePersistsAllFields(t *testing.T) {
 		EndTime:        endTime,
 		ProcessingTime: 150 * time.Millisecond,
 		ClipName:       "/clips/test.wav",
+		Bearing:        &bearing,
 	}
 	err := ds.Save(note, nil)
 	require.NoError(t, err)
@@ -878,6 +979,8 @@ func TestV2OnlyDatastore_SavePersistsAllFields(t *testing.T) {
 	assert.Equal(t, beginTime.Unix(), got.BeginTime.Unix(), "BeginTime should match saved value")
 	assert.Equal(t, endTime.Unix(), got.EndTime.Unix(), "EndTime should match saved value")
 	assert.Equal(t, 150*time.Millisecond, got.ProcessingTime, "ProcessingTime should match saved value")
+	require.NotNil(t, got.Bearing, "Bearing should be saved")
+	assert.InDelta(t, bearing, *got.Bearing, 0.001, "Bearing should match saved value")
 }
 
 // TestV2OnlyDatastore_DetectionToNote_MapsSourceAndComments verifies that
@@ -947,3 +1050,57 @@ func TestV2OnlyDatastore_ReviewAndLockVisibleInGetAllNotes(t *testing.T) {
 	assert.Equal(t, "correct", notes[0].Verified, "Verified should be populated in GetAllNotes")
 	assert.True(t, notes[0].Locked, "Locked should be true in GetAllNotes")
 }
+
+func TestV2OnlyDatastore_GetSpeciesDailyPresence(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	save := func(date, tm, species string) *datastore.Note {
+		t.Helper()
+		note := &datastore.Note{Date: date, Time: tm, ScientificName: species, Confidence: 0.9}
+		require.NoError(t, ds.Save(note, nil))
+		return note
+	}
+	save("2024-04-01", "06:00:00", "Phylloscopus collybita")
+	save("2024-04-01", "07:00:00", "Phylloscopus collybita")
+	save("2024-04-02", "06:00:00", "Phylloscopus collybita")
+	falsePositive := save("2024-04-02", "08:00:00", "Turdus merula")
+	save("2025-01-01", "08:00:00", "Turdus merula")
+
+	require.NoError(t, ds.SaveNoteReview(&datastore.NoteReview{NoteID: falsePositive.ID, Verified: "false_positive"}))
+
+	rows, err := ds.GetSpeciesDailyPresence(t.Context(), "2024-01-01", "2024-12-31")
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "Phylloscopus collybita", rows[0].ScientificName)
+	assert.Equal(t, "2024-04-01", rows[0].Date)
+	assert.Equal(t, 2, rows[0].Count)
+	assert.Equal(t, "2024-04-02", rows[1].Date)
+	assert.Equal(t, 1, rows[1].Count)
+
+	_, err = ds.GetSpeciesDailyPresence(t.Context(), "2024/01/01", "")
+	require.Error(t, err)
+}
+
+func TestV2OnlyDatastore_GetDetectionMinuteCounts(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	for _, tm := range []string{"04:10:05", "04:10:45", "04:11:00"} {
+		require.NoError(t, ds.Save(&datastore.Note{Date: "2024-05-01", Time: tm, ScientificName: "Turdus merula", Confidence: 0.9}, nil))
+	}
+	require.NoError(t, ds.Save(&datastore.Note{Date: "2024-05-01", Time: "04:11:00", ScientificName: "Erithacus rubecula", Confidence: 0.9}, nil))
+
+	rows, err := ds.GetDetectionMinuteCounts(t.Context(), "2024-05-01", "2024-05-01", "Turdus merula")
+	require.NoError(t, err)
+	counts := map[string]int{}
+	for _, r := range rows {
+		assert.Equal(t, "2024-05-01", r.Date)
+		counts[r.Minute] = r.Count
+	}
+	assert.Equal(t, map[string]int{"04:10": 2, "04:11": 1}, counts)
+
+	all, err := ds.GetDetectionMinuteCounts(t.Context(), "2024-05-01", "2024-05-01", "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+}
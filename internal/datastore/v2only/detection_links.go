@@ -0,0 +1,121 @@
+package v2only
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+)
+
+// SaveDetectionLinks links detections at other sources of a source group to
+// the detection they were merged into. Sources are resolved as for sound
+// levels, so links and detections of a source share its ID.
+func (ds *Datastore) SaveDetectionLinks(ctx context.Context, detectionID uint, links []datastore.DetectionLinkRecord) error {
+	if len(links) == 0 {
+		return nil
+	}
+	if ds.source == nil {
+		return fmt.Errorf("%w: audio source repository is not available", repository.ErrInvalidInput)
+	}
+
+	resolve := ds.soundLevelSourceResolver()
+	rows := make([]entities.DetectionLink, 0, len(links))
+	for i := range links {
+		link := &links[i]
+		if link.SourceURI == "" {
+			continue
+		}
+		sourceID, err := resolve(ctx, link.SourceURI, link.NodeName, link.SourceName)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, entities.DetectionLink{
+			DetectionID: detectionID,
+			SourceID:    sourceID,
+			Confidence:  link.Confidence,
+			BeginTime:   link.BeginTime.UnixMilli(),
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := ds.manager.DB().WithContext(ctx).Create(&rows).Error; err != nil {
+		return fmt.Errorf("failed to save detection links: %w", err)
+	}
+	return nil
+}
+
+// GetDetectionLinks returns the detections merged into a detection, ordered
+// by confidence, highest first.
+func (ds *Datastore) GetDetectionLinks(ctx context.Context, detectionID uint) ([]datastore.DetectionLinkRecord, error) {
+	var rows []entities.DetectionLink
+	if err := ds.manager.DB().WithContext(ctx).
+		Preload("Source").
+		Where("detection_id = ?", detectionID).
+		Order("confidence DESC, id").
+		Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get detection links: %w", err)
+	}
+
+	records := make([]datastore.DetectionLinkRecord, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		record := datastore.DetectionLinkRecord{
+			DetectionID: row.DetectionID,
+			Confidence:  row.Confidence,
+			BeginTime:   time.UnixMilli(row.BeginTime),
+		}
+		if row.Source != nil {
+			record.SourceURI = row.Source.SourceURI
+			record.NodeName = row.Source.NodeName
+			if row.Source.DisplayName != nil {
+				record.SourceName = *row.Source.DisplayName
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// GetMergedDetectionCounts returns per species counts of detections merged
+// from several sources, detected within the range. Detections marked as
+// false positives are excluded.
+func (ds *Datastore) GetMergedDetectionCounts(ctx context.Context, start, end time.Time) ([]datastore.MergedDetectionCount, error) {
+	var rows []struct {
+		ScientificName string
+		Events         int64
+		Duplicates     int64
+	}
+	query := ds.manager.DB().WithContext(ctx).
+		Table("detection_links dl").
+		Select("l.scientific_name, COUNT(DISTINCT d.id) as events, COUNT(*) as duplicates").
+		Joins("JOIN detections d ON dl.detection_id = d.id").
+		Joins("JOIN labels l ON d.label_id = l.id").
+		Joins("LEFT JOIN detection_reviews dr ON d.id = dr.detection_id").
+		Where("d.detected_at >= ? AND d.detected_at < ?", start.Unix(), end.Unix()).
+		Where("(dr.verified IS NULL OR dr.verified != ?)", string(entities.VerificationFalsePositive))
+	if err := applyNodeFilter(ctx, query).
+		Group("l.scientific_name").
+		Order("events DESC, l.scientific_name ASC").
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to count merged detections: %w", err)
+	}
+
+	counts := make([]datastore.MergedDetectionCount, 0, len(rows))
+	for _, r := range rows {
+		commonName := r.ScientificName
+		if cn, ok := ds.commonNameMap[r.ScientificName]; ok {
+			commonName = cn
+		}
+		counts = append(counts, datastore.MergedDetectionCount{
+			ScientificName: r.ScientificName,
+			CommonName:     commonName,
+			Events:         r.Events,
+			Duplicates:     r.Duplicates,
+		})
+	}
+	return counts, nil
+}
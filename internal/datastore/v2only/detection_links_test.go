@@ -0,0 +1,66 @@
+package v2only
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestV2OnlyDatastore_DetectionLinks(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	begin := time.Date(2024, 5, 1, 6, 0, 0, 0, time.Local)
+	for _, species := range []string{"Turdus merula", "Turdus merula", "Erithacus rubecula"} {
+		note := &datastore.Note{
+			Date:           "2024-05-01",
+			Time:           "06:00:00",
+			ScientificName: species,
+			Confidence:     0.9,
+			BeginTime:      begin,
+			Source:         datastore.AudioSource{SafeString: "rtsp://front", DisplayName: "Front"},
+		}
+		require.NoError(t, ds.Save(note, nil))
+	}
+
+	require.NoError(t, ds.SaveDetectionLinks(t.Context(), 1, []datastore.DetectionLinkRecord{
+		{SourceURI: "rtsp://back", SourceName: "Back", Confidence: 0.6, BeginTime: begin.Add(1500 * time.Millisecond)},
+		{SourceURI: "rtsp://shed", SourceName: "Shed", Confidence: 0.7, BeginTime: begin.Add(time.Second)},
+		{Confidence: 0.5}, // no source, skipped
+	}))
+	require.NoError(t, ds.SaveDetectionLinks(t.Context(), 2, []datastore.DetectionLinkRecord{
+		{SourceURI: "rtsp://back", SourceName: "Back", Confidence: 0.8, BeginTime: begin},
+	}))
+	require.NoError(t, ds.SaveDetectionLinks(t.Context(), 3, []datastore.DetectionLinkRecord{
+		{SourceURI: "rtsp://back", SourceName: "Back", Confidence: 0.4, BeginTime: begin},
+	}))
+
+	links, err := ds.GetDetectionLinks(t.Context(), 1)
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	assert.Equal(t, "rtsp://shed", links[0].SourceURI, "ordered by confidence")
+	assert.Equal(t, "Shed", links[0].SourceName)
+	assert.InDelta(t, 0.7, links[0].Confidence, 1e-9)
+	assert.Equal(t, begin.Add(1500*time.Millisecond).UnixMilli(), links[1].BeginTime.UnixMilli())
+	assert.Equal(t, uint(1), links[1].DetectionID)
+
+	none, err := ds.GetDetectionLinks(t.Context(), 42)
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	counts, err := ds.GetMergedDetectionCounts(t.Context(), begin.Add(-time.Hour), begin.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, counts, 2)
+	assert.Equal(t, "Turdus merula", counts[0].ScientificName)
+	assert.Equal(t, int64(2), counts[0].Events)
+	assert.Equal(t, int64(3), counts[0].Duplicates)
+	assert.Equal(t, "Erithacus rubecula", counts[1].ScientificName)
+	assert.Equal(t, int64(1), counts[1].Events)
+
+	empty, err := ds.GetMergedDetectionCounts(t.Context(), begin.Add(time.Hour), begin.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+}
@@ -0,0 +1,83 @@
+package v2only
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	"gorm.io/gorm"
+)
+
+// SaveIngested saves a detection pushed by a remote node. note.SourceNode
+// must name the node; remoteID is the detection ID on that node.
+//
+// The detection and its (node, remoteID) mapping are written in one
+// transaction, so a node retrying a push after a timeout never creates a
+// second detection. For a retry the existing detection ID is returned with
+// duplicate set to true.
+func (ds *Datastore) SaveIngested(note *datastore.Note, results []datastore.Results, remoteID uint) (id uint, duplicate bool, err error) {
+	if note.SourceNode == "" || remoteID == 0 {
+		return 0, false, fmt.Errorf("%w: node name and remote ID are required", repository.ErrInvalidInput)
+	}
+
+	if existing, err := ds.IngestedDetectionID(note.SourceNode, remoteID); err == nil {
+		note.ID = existing
+		return existing, true, nil
+	} else if !errors.Is(err, repository.ErrDetectionNotFound) {
+		return 0, false, err
+	}
+
+	id, err = ds.saveDetection(note, results, func(tx *gorm.DB, det *entities.Detection) error {
+		return tx.Create(&entities.IngestedDetection{
+			NodeName:    note.SourceNode,
+			RemoteID:    remoteID,
+			DetectionID: det.ID,
+		}).Error
+	})
+	if err != nil {
+		// A concurrent retry may have won the race on the unique mapping
+		if existing, lookupErr := ds.IngestedDetectionID(note.SourceNode, remoteID); lookupErr == nil {
+			note.ID = existing
+			return existing, true, nil
+		}
+		return 0, false, err
+	}
+
+	note.ID = id
+	return id, false, nil
+}
+
+// IngestedDetectionID returns the local detection ID stored for a remote
+// node's detection. Returns repository.ErrDetectionNotFound if the detection
+// has not been received.
+func (ds *Datastore) IngestedDetectionID(nodeName string, remoteID uint) (uint, error) {
+	var mapping entities.IngestedDetection
+	err := ds.manager.DB().WithContext(context.Background()).
+		Where("node_name = ? AND remote_id = ?", nodeName, remoteID).
+		First(&mapping).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, repository.ErrDetectionNotFound
+		}
+		return 0, err
+	}
+	return mapping.DetectionID, nil
+}
+
+// SetClipName sets the clip path of a detection, e.g. once a remote node has
+// uploaded the clip for a detection received earlier.
+func (ds *Datastore) SetClipName(id uint, clipName string) error {
+	return ds.detection.Update(context.Background(), id, map[string]any{"clip_name": clipName})
+}
+
+// applyNodeFilter restricts a query over "detections d" to the node filter
+// carried by ctx, if any.
+func applyNodeFilter(ctx context.Context, query *gorm.DB) *gorm.DB {
+	if clause, args := repository.NodeFilterClause(ctx, "d.source_id", "audio_sources"); clause != "" {
+		return query.Where(clause, args...)
+	}
+	return query
+}
@@ -0,0 +1,108 @@
+package v2only
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+)
+
+// remoteNote builds a note as received from a remote node.
+func remoteNote(node, species string) *datastore.Note {
+	return &datastore.Note{
+		Date:           "2024-05-01",
+		Time:           "06:15:00",
+		ScientificName: species,
+		CommonName:     species,
+		Confidence:     0.9,
+		SourceNode:     node,
+		Source:         datastore.AudioSource{ID: "rtsp_1", SafeString: "rtsp://10.0.0.5/stream", DisplayName: "Pond"},
+	}
+}
+
+func TestV2OnlyDatastore_SaveSetsNoteID(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	note := remoteNote("", "Turdus merula")
+	require.NoError(t, ds.Save(note, nil))
+	assert.NotZero(t, note.ID)
+
+	saved, err := ds.Get(strconv.FormatUint(uint64(note.ID), 10))
+	require.NoError(t, err)
+	assert.Equal(t, "Turdus merula", saved.ScientificName)
+}
+
+func TestV2OnlyDatastore_SaveIngestedDeduplicates(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	id, duplicate, err := ds.SaveIngested(remoteNote("north", "Turdus merula"), nil, 42)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+
+	// Retry of the same remote detection
+	retryID, duplicate, err := ds.SaveIngested(remoteNote("north", "Turdus merula"), nil, 42)
+	require.NoError(t, err)
+	assert.True(t, duplicate)
+	assert.Equal(t, id, retryID)
+
+	// Same remote ID from another node is a different detection
+	otherID, duplicate, err := ds.SaveIngested(remoteNote("south", "Turdus merula"), nil, 42)
+	require.NoError(t, err)
+	assert.False(t, duplicate)
+	assert.NotEqual(t, id, otherID)
+
+	notes, err := ds.GetAllNotes()
+	require.NoError(t, err)
+	assert.Len(t, notes, 2)
+
+	found, err := ds.IngestedDetectionID("north", 42)
+	require.NoError(t, err)
+	assert.Equal(t, id, found)
+	_, err = ds.IngestedDetectionID("north", 43)
+	require.ErrorIs(t, err, repository.ErrDetectionNotFound)
+
+	_, _, err = ds.SaveIngested(remoteNote("", "Turdus merula"), nil, 1)
+	require.ErrorIs(t, err, repository.ErrInvalidInput)
+}
+
+func TestV2OnlyDatastore_NodeFilter(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	_, _, err := ds.SaveIngested(remoteNote("north", "Turdus merula"), nil, 1)
+	require.NoError(t, err)
+	_, _, err = ds.SaveIngested(remoteNote("north", "Erithacus rubecula"), nil, 2)
+	require.NoError(t, err)
+	_, _, err = ds.SaveIngested(remoteNote("south", "Turdus merula"), nil, 1)
+	require.NoError(t, err)
+
+	ctx := t.Context()
+	all, err := ds.GetTopBirdsDataContext(ctx, "2024-05-01", 0, 10)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+
+	south, err := ds.GetTopBirdsDataContext(repository.WithNodeFilter(ctx, "south"), "2024-05-01", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, south, 1)
+	assert.Equal(t, "Turdus merula", south[0].ScientificName)
+
+	summary, err := ds.GetSpeciesSummaryData(repository.WithNodeFilter(ctx, "north"), "2024-05-01", "2024-05-01")
+	require.NoError(t, err)
+	require.Len(t, summary, 2)
+	for _, s := range summary {
+		assert.Equal(t, 1, s.Count, s.ScientificName)
+	}
+
+	hourly, err := ds.GetBatchHourlyOccurrencesContext(repository.WithNodeFilter(ctx, "south"), "2024-05-01", []string{"Turdus merula"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 1, hourly["Turdus merula"][6])
+
+	unknown, err := ds.GetSpeciesDiversityData(repository.WithNodeFilter(ctx, "west"), "2024-05-01", "2024-05-01")
+	require.NoError(t, err)
+	assert.Empty(t, unknown)
+}
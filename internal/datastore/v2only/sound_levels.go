@@ -0,0 +1,322 @@
+package v2only
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"gorm.io/gorm"
+)
+
+// soundLevelDownsampleWindow is the time span downsampled per transaction,
+// bounding the rows held in memory.
+const soundLevelDownsampleWindow = 6 * time.Hour
+
+// SaveSoundLevels stores sound level measurements. Sources are resolved by
+// URI and node name, creating unmanaged sources as detections do.
+func (ds *Datastore) SaveSoundLevels(ctx context.Context, records []datastore.SoundLevelRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if ds.source == nil {
+		return fmt.Errorf("%w: audio source repository is not available", repository.ErrInvalidInput)
+	}
+
+	resolve := ds.soundLevelSourceResolver()
+	rows := make([]entities.SoundLevel, 0, len(records))
+	for i := range records {
+		r := &records[i]
+		if r.SourceURI == "" {
+			continue
+		}
+		sourceID, err := resolve(ctx, r.SourceURI, r.NodeName, r.SourceName)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, soundLevelToEntity(r, sourceID))
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return ds.manager.DB().WithContext(ctx).CreateInBatches(rows, 100).Error
+}
+
+// soundLevelSourceResolver returns a function resolving a source URI and node
+// name to a source ID, caching results for the lifetime of the function.
+func (ds *Datastore) soundLevelSourceResolver() func(ctx context.Context, uri, node, name string) (uint, error) {
+	type sourceKey struct{ uri, node string }
+	sourceIDs := make(map[sourceKey]uint)
+	return func(ctx context.Context, uri, node, name string) (uint, error) {
+		key := sourceKey{uri, node}
+		if key.node == "" {
+			key.node = "default"
+		}
+		if id, ok := sourceIDs[key]; ok {
+			return id, nil
+		}
+		var displayName *string
+		if name != "" {
+			displayName = &name
+		}
+		source, err := ds.source.GetOrCreate(ctx, key.uri, key.node, displayName, entities.SourceType(""))
+		if err != nil {
+			return 0, fmt.Errorf("failed to resolve sound level source: %w", err)
+		}
+		sourceIDs[key] = source.ID
+		return source.ID, nil
+	}
+}
+
+// soundLevelSourceIDs returns the IDs of the sources matching a URI or display
+// name, or nil if source is empty.
+func (ds *Datastore) soundLevelSourceIDs(ctx context.Context, source string) ([]uint, error) {
+	if source == "" {
+		return nil, nil
+	}
+	sourceIDs := []uint{}
+	if err := ds.manager.DB().WithContext(ctx).Model(&entities.AudioSource{}).
+		Where("source_uri = ? OR display_name = ?", source, source).
+		Pluck("id", &sourceIDs).Error; err != nil {
+		return nil, fmt.Errorf("failed to resolve sound level source: %w", err)
+	}
+	return sourceIDs, nil
+}
+
+// GetSoundLevels returns sound level records starting within the query range,
+// ordered by source and start time. query.Source matches the source URI or
+// display name.
+func (ds *Datastore) GetSoundLevels(ctx context.Context, query datastore.SoundLevelQuery) ([]datastore.SoundLevelRecord, error) {
+	db := ds.manager.DB().WithContext(ctx).
+		Preload("Source").
+		Where("start_time >= ? AND start_time < ?", query.Start.Unix(), query.End.Unix())
+
+	sourceIDs, err := ds.soundLevelSourceIDs(ctx, query.Source)
+	if err != nil {
+		return nil, err
+	}
+	if sourceIDs != nil {
+		if len(sourceIDs) == 0 {
+			return []datastore.SoundLevelRecord{}, nil
+		}
+		db = db.Where("source_id IN ?", sourceIDs)
+	}
+
+	var rows []entities.SoundLevel
+	if err := db.Order("source_id, start_time").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get sound levels: %w", err)
+	}
+
+	records := make([]datastore.SoundLevelRecord, 0, len(rows))
+	for i := range rows {
+		record, err := soundLevelFromEntity(&rows[i])
+		if err != nil {
+			ds.logWarn("skipping undecodable sound level row",
+				logger.Uint64("id", uint64(rows[i].ID)), logger.Error(err))
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// GetDetectionSoundLevel returns the sound level record covering a detection's
+// time at its source, or nil if none was stored. Returns
+// repository.ErrDetectionNotFound for an unknown detection.
+func (ds *Datastore) GetDetectionSoundLevel(ctx context.Context, detectionID uint) (*datastore.SoundLevelRecord, error) {
+	var det entities.Detection
+	err := ds.manager.DB().WithContext(ctx).
+		Select("id", "source_id", "detected_at").
+		First(&det, detectionID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, repository.ErrDetectionNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get detection: %w", err)
+	}
+	if det.SourceID == nil {
+		return nil, nil
+	}
+
+	var row entities.SoundLevel
+	err = ds.manager.DB().WithContext(ctx).
+		Preload("Source").
+		Where("source_id = ? AND start_time <= ?", *det.SourceID, det.DetectedAt).
+		Order("start_time DESC").
+		First(&row).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get detection sound level: %w", err)
+	}
+	// The latest record before the detection must still cover it
+	if row.StartTime+int64(max(row.Resolution, row.Duration)) < det.DetectedAt {
+		return nil, nil
+	}
+
+	record, err := soundLevelFromEntity(&row)
+	if err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// DownsampleSoundLevels merges records finer than resolution that start before
+// the cutoff into one record per source and resolution-aligned interval.
+// Returns the number of records removed.
+func (ds *Datastore) DownsampleSoundLevels(ctx context.Context, before time.Time, resolution time.Duration) (int64, error) {
+	step := int64(resolution / time.Second)
+	if step <= 0 {
+		return 0, fmt.Errorf("%w: invalid downsample resolution %s", repository.ErrInvalidInput, resolution)
+	}
+	// Align the cutoff so no interval is split between runs
+	cutoff := before.Unix() - before.Unix()%step
+	db := ds.manager.DB().WithContext(ctx)
+
+	var removed int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return removed, err
+		}
+
+		var first entities.SoundLevel
+		err := db.Select("start_time").
+			Where("resolution < ? AND start_time < ?", step, cutoff).
+			Order("start_time").
+			First(&first).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return removed, nil
+		}
+		if err != nil {
+			return removed, fmt.Errorf("failed to find sound levels to downsample: %w", err)
+		}
+
+		windowStart := first.StartTime - first.StartTime%step
+		windowEnd := min(windowStart+int64(soundLevelDownsampleWindow/time.Second), cutoff)
+
+		var rows []entities.SoundLevel
+		if err := db.Where("resolution < ? AND start_time >= ? AND start_time < ?", step, windowStart, windowEnd).
+			Order("source_id, start_time").
+			Find(&rows).Error; err != nil {
+			return removed, fmt.Errorf("failed to load sound levels to downsample: %w", err)
+		}
+		if len(rows) == 0 {
+			return removed, nil
+		}
+
+		merged, ids := downsampleSoundLevelRows(rows, step, ds.logWarn)
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Delete(&entities.SoundLevel{}, ids).Error; err != nil {
+				return err
+			}
+			if len(merged) == 0 {
+				return nil
+			}
+			return tx.CreateInBatches(merged, 100).Error
+		})
+		if err != nil {
+			return removed, fmt.Errorf("failed to store downsampled sound levels: %w", err)
+		}
+		removed += int64(len(ids))
+	}
+}
+
+// downsampleSoundLevelRows merges rows into one row per source and aligned
+// interval of step seconds. It returns the merged rows and the IDs of all
+// input rows, including undecodable ones that are dropped.
+func downsampleSoundLevelRows(rows []entities.SoundLevel, step int64, warn func(string, ...logger.Field)) (merged []entities.SoundLevel, ids []uint) {
+	type groupKey struct {
+		sourceID uint
+		start    int64
+	}
+	groups := make(map[groupKey][]datastore.SoundLevelRecord)
+	var order []groupKey
+	ids = make([]uint, 0, len(rows))
+	for i := range rows {
+		ids = append(ids, rows[i].ID)
+		record, err := soundLevelFromEntity(&rows[i])
+		if err != nil {
+			warn("dropping undecodable sound level row",
+				logger.Uint64("id", uint64(rows[i].ID)), logger.Error(err))
+			continue
+		}
+		key := groupKey{rows[i].SourceID, rows[i].StartTime - rows[i].StartTime%step}
+		if _, ok := groups[key]; !ok {
+			order = append(order, key)
+		}
+		groups[key] = append(groups[key], record)
+	}
+
+	merged = make([]entities.SoundLevel, 0, len(order))
+	for _, key := range order {
+		record := datastore.MergeSoundLevels(groups[key], time.Unix(key.start, 0), time.Duration(step)*time.Second)
+		merged = append(merged, soundLevelToEntity(&record, key.sourceID))
+	}
+	return merged, ids
+}
+
+// PruneSoundLevels deletes sound level records starting before the cutoff.
+func (ds *Datastore) PruneSoundLevels(ctx context.Context, before time.Time) (int64, error) {
+	result := ds.manager.DB().WithContext(ctx).
+		Where("start_time < ?", before.Unix()).
+		Delete(&entities.SoundLevel{})
+	if result.Error != nil {
+		return 0, fmt.Errorf("failed to prune sound levels: %w", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// soundLevelToEntity converts a record to a row of the given source.
+func soundLevelToEntity(r *datastore.SoundLevelRecord, sourceID uint) entities.SoundLevel {
+	leq := r.Leq
+	if leq == 0 && len(r.Bands) > 0 {
+		leq = datastore.BroadbandLeq(r.Bands)
+	}
+	resolution := r.Resolution
+	if resolution == 0 {
+		resolution = r.Duration
+	}
+	return entities.SoundLevel{
+		SourceID:   sourceID,
+		StartTime:  r.Start.Unix(),
+		Duration:   r.Duration,
+		Resolution: resolution,
+		Leq:        leq,
+		Bands:      datastore.EncodeSoundLevelBands(r.Bands),
+	}
+}
+
+// soundLevelFromEntity converts a row, with its source preloaded if available, to a record.
+func soundLevelFromEntity(row *entities.SoundLevel) (datastore.SoundLevelRecord, error) {
+	bands, err := datastore.DecodeSoundLevelBands(row.Bands)
+	if err != nil {
+		return datastore.SoundLevelRecord{}, err
+	}
+	record := datastore.SoundLevelRecord{
+		Start:      time.Unix(row.StartTime, 0),
+		Duration:   row.Duration,
+		Resolution: row.Resolution,
+		Leq:        row.Leq,
+		Bands:      bands,
+	}
+	if row.Source != nil {
+		record.SourceURI = row.Source.SourceURI
+		record.NodeName = row.Source.NodeName
+		if row.Source.DisplayName != nil {
+			record.SourceName = *row.Source.DisplayName
+		}
+	}
+	return record, nil
+}
+
+// logWarn logs a warning if a logger is configured.
+func (ds *Datastore) logWarn(msg string, fields ...logger.Field) {
+	if ds.log != nil {
+		ds.log.Warn(msg, fields...)
+	}
+}
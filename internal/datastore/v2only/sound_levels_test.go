@@ -0,0 +1,110 @@
+package v2only
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+)
+
+// soundLevelSeries returns count 10-second measurements of a source from start.
+func soundLevelSeries(source string, start time.Time, count int, leq float64) []datastore.SoundLevelRecord {
+	records := make([]datastore.SoundLevelRecord, count)
+	for i := range records {
+		records[i] = datastore.SoundLevelRecord{
+			SourceURI:  source,
+			SourceName: "Garden",
+			Start:      start.Add(time.Duration(i*10) * time.Second),
+			Duration:   10,
+			Bands: []datastore.SoundLevelBand{
+				{CenterFreq: 1000, Leq: leq, Min: leq - 5, Max: leq + 5},
+				{CenterFreq: 31.5, Leq: leq - 10, Min: leq - 15, Max: leq - 5},
+			},
+		}
+	}
+	return records
+}
+
+func TestV2OnlyDatastore_SoundLevels(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	start := time.Date(2024, 5, 1, 4, 10, 0, 0, time.Local)
+	require.NoError(t, ds.SaveSoundLevels(t.Context(), soundLevelSeries("hw:1,0", start, 60, -40)))
+	require.NoError(t, ds.SaveSoundLevels(t.Context(), soundLevelSeries("rtsp://cam", start, 6, -30)))
+
+	records, err := ds.GetSoundLevels(t.Context(), datastore.SoundLevelQuery{
+		Source: "hw:1,0",
+		Start:  start,
+		End:    start.Add(time.Minute),
+	})
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	first := records[0]
+	assert.Equal(t, "hw:1,0", first.SourceURI)
+	assert.Equal(t, "Garden", first.SourceName)
+	assert.Equal(t, start.Unix(), first.Start.Unix())
+	assert.Equal(t, 10, first.Resolution)
+	require.Len(t, first.Bands, 2)
+	assert.InDelta(t, -40.0, first.Bands[0].Leq, 0.001)
+	assert.InDelta(t, 31.5, first.Bands[1].CenterFreq, 0.001)
+	assert.InDelta(t, -39.59, first.Leq, 0.01, "broadband level is the energy sum of the bands")
+
+	all, err := ds.GetSoundLevels(t.Context(), datastore.SoundLevelQuery{Start: start, End: start.Add(time.Hour)})
+	require.NoError(t, err)
+	assert.Len(t, all, 66)
+
+	t.Run("detection ambient level", func(t *testing.T) {
+		note := &datastore.Note{
+			Date: "2024-05-01", Time: "04:10:25", ScientificName: "Turdus merula", Confidence: 0.9,
+			Source: datastore.AudioSource{ID: "sound_card", SafeString: "hw:1,0", DisplayName: "Garden"},
+		}
+		require.NoError(t, ds.Save(note, nil))
+
+		level, err := ds.GetDetectionSoundLevel(t.Context(), note.ID)
+		require.NoError(t, err)
+		require.NotNil(t, level)
+		assert.Equal(t, start.Add(20*time.Second).Unix(), level.Start.Unix())
+
+		late := &datastore.Note{
+			Date: "2024-05-01", Time: "06:00:00", ScientificName: "Turdus merula", Confidence: 0.9,
+			Source: datastore.AudioSource{ID: "sound_card", SafeString: "hw:1,0"},
+		}
+		require.NoError(t, ds.Save(late, nil))
+		level, err = ds.GetDetectionSoundLevel(t.Context(), late.ID)
+		require.NoError(t, err)
+		assert.Nil(t, level, "no measurement covers the detection")
+
+		_, err = ds.GetDetectionSoundLevel(t.Context(), 999999)
+		require.Error(t, err)
+	})
+
+	t.Run("downsample and prune", func(t *testing.T) {
+		removed, err := ds.DownsampleSoundLevels(t.Context(), start.Add(time.Hour), 5*time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, int64(66), removed)
+
+		var count int64
+		require.NoError(t, ds.manager.DB().Model(&entities.SoundLevel{}).Count(&count).Error)
+		assert.Equal(t, int64(3), count, "two 5-minute rows for the sound card, one for the camera")
+
+		records, err := ds.GetSoundLevels(t.Context(), datastore.SoundLevelQuery{Source: "Garden", Start: start.Add(-time.Hour), End: start.Add(time.Hour)})
+		require.NoError(t, err)
+		require.Len(t, records, 3, "both sources share the display name")
+		for _, r := range records {
+			assert.Equal(t, 300, r.Resolution)
+			assert.Zero(t, r.Start.Unix()%300, "downsampled rows are aligned")
+		}
+
+		again, err := ds.DownsampleSoundLevels(t.Context(), start.Add(time.Hour), 5*time.Minute)
+		require.NoError(t, err)
+		assert.Zero(t, again, "downsampled rows are not downsampled again")
+
+		pruned, err := ds.PruneSoundLevels(t.Context(), start.Add(5*time.Minute))
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), pruned)
+	})
+}
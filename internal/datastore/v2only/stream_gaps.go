@@ -0,0 +1,88 @@
+package v2only
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/entities"
+	"github.com/tphakala/birdnet-go/internal/datastore/v2/repository"
+)
+
+// SaveStreamGaps stores stream gaps. Sources are resolved as for sound
+// levels, so gaps and levels of a source share its ID.
+func (ds *Datastore) SaveStreamGaps(ctx context.Context, records []datastore.StreamGapRecord) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if ds.source == nil {
+		return fmt.Errorf("%w: audio source repository is not available", repository.ErrInvalidInput)
+	}
+
+	resolve := ds.soundLevelSourceResolver()
+	rows := make([]entities.StreamGap, 0, len(records))
+	for i := range records {
+		r := &records[i]
+		if r.SourceURI == "" {
+			continue
+		}
+		sourceID, err := resolve(ctx, r.SourceURI, r.NodeName, r.SourceName)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, entities.StreamGap{
+			SourceID:   sourceID,
+			StartTime:  r.Start.UnixMilli(),
+			DurationMs: r.Duration.Milliseconds(),
+			Reason:     r.Reason,
+		})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+	return ds.manager.DB().WithContext(ctx).CreateInBatches(rows, 100).Error
+}
+
+// GetStreamGaps returns stream gaps starting within the range, ordered by
+// start time. source matches the source URI or display name.
+func (ds *Datastore) GetStreamGaps(ctx context.Context, source string, start, end time.Time) ([]datastore.StreamGapRecord, error) {
+	db := ds.manager.DB().WithContext(ctx).
+		Preload("Source").
+		Where("start_time >= ? AND start_time < ?", start.UnixMilli(), end.UnixMilli())
+
+	sourceIDs, err := ds.soundLevelSourceIDs(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if sourceIDs != nil {
+		if len(sourceIDs) == 0 {
+			return []datastore.StreamGapRecord{}, nil
+		}
+		db = db.Where("source_id IN ?", sourceIDs)
+	}
+
+	var rows []entities.StreamGap
+	if err := db.Order("start_time, id").Find(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to get stream gaps: %w", err)
+	}
+
+	records := make([]datastore.StreamGapRecord, 0, len(rows))
+	for i := range rows {
+		row := &rows[i]
+		record := datastore.StreamGapRecord{
+			Start:    time.UnixMilli(row.StartTime),
+			Duration: time.Duration(row.DurationMs) * time.Millisecond,
+			Reason:   row.Reason,
+		}
+		if row.Source != nil {
+			record.SourceURI = row.Source.SourceURI
+			record.NodeName = row.Source.NodeName
+			if row.Source.DisplayName != nil {
+				record.SourceName = *row.Source.DisplayName
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
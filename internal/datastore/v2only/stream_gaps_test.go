@@ -0,0 +1,43 @@
+package v2only
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestV2OnlyDatastore_StreamGaps(t *testing.T) {
+	ds, cleanup := setupTestDatastore(t)
+	defer cleanup()
+
+	start := time.Date(2024, 5, 1, 4, 10, 0, 0, time.Local)
+	records := []datastore.StreamGapRecord{
+		{SourceURI: "rtsp://cam", SourceName: "Pond", Start: start.Add(1500 * time.Millisecond), Duration: 4250 * time.Millisecond, Reason: "stall"},
+		{SourceURI: "rtsp://cam", SourceName: "Pond", Start: start.Add(time.Hour), Duration: 30 * time.Second, Reason: "restart"},
+		{SourceURI: "http://radio/marsh", Start: start.Add(time.Minute), Duration: 2 * time.Second, Reason: "stall"},
+		{Start: start, Duration: time.Second}, // no source, skipped
+	}
+	require.NoError(t, ds.SaveStreamGaps(t.Context(), records))
+
+	got, err := ds.GetStreamGaps(t.Context(), "Pond", start, start.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, "rtsp://cam", got[0].SourceURI)
+	assert.Equal(t, "Pond", got[0].SourceName)
+	assert.Equal(t, start.Add(1500*time.Millisecond).UnixMilli(), got[0].Start.UnixMilli())
+	assert.Equal(t, 4250*time.Millisecond, got[0].Duration)
+	assert.Equal(t, "stall", got[0].Reason)
+	assert.Equal(t, "restart", got[1].Reason)
+
+	all, err := ds.GetStreamGaps(t.Context(), "", start, start.Add(30*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "http://radio/marsh", all[1].SourceURI, "ordered by start")
+
+	none, err := ds.GetStreamGaps(t.Context(), "unknown", start, start.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
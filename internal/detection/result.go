@@ -44,6 +44,13 @@ type Result struct {
 	ClipName       string        // Saved audio clip filename
 	ProcessingTime time.Duration // How long analysis took
 
+	// Direction of the sound, estimated by microphone arrays
+	Bearing *float64 // Compass bearing in degrees, nil when not estimated
+
+	// Detections of the same bird at other sources of a source group,
+	// merged into this one because this source heard it best
+	MergedSources []MergedSource
+
 	// Runtime-only data (not persisted)
 	Occurrence float64 // Probability 0-1 based on location/time/season
 
@@ -61,6 +68,14 @@ type Comment struct {
 	UpdatedAt time.Time
 }
 
+// MergedSource is a detection at another source of a source group that was
+// merged into a detection because it heard the same bird less clearly.
+type MergedSource struct {
+	AudioSource AudioSource
+	Confidence  float64
+	BeginTime   time.Time
+}
+
 // AdditionalResult represents a secondary species prediction from the same audio chunk.
 // BirdNET may return multiple species predictions for a single 3-second analysis window.
 // The primary (highest confidence) result is in Result.Species/Confidence.
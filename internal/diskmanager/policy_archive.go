@@ -0,0 +1,235 @@
+// policy_archive.go - rolling retention for the continuous recording archive
+package diskmanager
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+const (
+	// ArchiveFileTimeLayout is the layout of the UTC start time in archive file names
+	ArchiveFileTimeLayout = "20060102T150405.000Z"
+
+	// ArchiveSegmentLength is the maximum length of an archive file. Files are
+	// rotated on the hour, so a file never spans two UTC hours.
+	ArchiveSegmentLength = time.Hour
+
+	// archivePolicy is the policy name used in logs and metrics
+	archivePolicy = "archive"
+)
+
+// archiveExtensions lists the file extensions of archive files
+var archiveExtensions = []string{".flac", ".opus"}
+
+// archiveFile is an archive file found on disk
+type archiveFile struct {
+	path  string
+	start time.Time
+	size  int64
+}
+
+// end returns the latest time the file can cover.
+func (f *archiveFile) end() time.Time {
+	return f.start.Truncate(ArchiveSegmentLength).Add(ArchiveSegmentLength)
+}
+
+// ParseArchiveFileName returns the start time encoded in an archive file name.
+func ParseArchiveFileName(name string) (time.Time, bool) {
+	base := filepath.Base(name)
+	ext := filepath.Ext(base)
+	if !slices.Contains(archiveExtensions, strings.ToLower(ext)) {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(ArchiveFileTimeLayout, strings.TrimSuffix(base, ext))
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// ArchiveCleanup removes archive files that are older than the configured
+// maximum age, then removes the oldest files while disk usage exceeds the
+// configured maximum. Files that may still be recorded to are never removed.
+func ArchiveCleanup(quit <-chan struct{}, settings *conf.ArchiveSettings) CleanupResult {
+	log := GetLogger()
+	startTime := time.Now()
+
+	maxAgeHours, err := conf.ParseRetentionPeriod(settings.MaxAge)
+	if err != nil {
+		return CleanupResult{Err: errors.New(err).
+			Component("diskmanager").
+			Category(errors.CategoryConfiguration).
+			Context("policy", archivePolicy).
+			Context("max_age", settings.MaxAge).
+			Build()}
+	}
+	var maxUsage float64
+	if settings.MaxUsage != "" {
+		if maxUsage, err = conf.ParsePercentage(settings.MaxUsage); err != nil {
+			return CleanupResult{Err: err}
+		}
+	}
+
+	files, err := listArchiveFiles(settings.Path)
+	if err != nil {
+		return CleanupResult{Err: err}
+	}
+
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(maxAgeHours) * time.Hour)
+	removed := 0
+
+	// Files are sorted oldest first, so both passes stop at the first file to keep
+	for len(files) > 0 && files[0].end().Before(cutoff) && removed < maxDeletionsPerRun {
+		select {
+		case <-quit:
+			return CleanupResult{ClipsRemoved: removed}
+		default:
+		}
+		if err := deleteArchiveFile(&files[0], "max age exceeded"); err == nil {
+			removed++
+		}
+		files = files[1:]
+	}
+
+	utilization := 0
+	if usage, err := GetDiskUsage(settings.Path); err == nil {
+		utilization = int(usage)
+		for maxUsage > 0 && usage > maxUsage && len(files) > 0 && files[0].end().Before(now) && removed < maxDeletionsPerRun {
+			select {
+			case <-quit:
+				return CleanupResult{ClipsRemoved: removed, DiskUtilization: utilization}
+			default:
+			}
+			if err := deleteArchiveFile(&files[0], "disk usage exceeded"); err == nil {
+				removed++
+			}
+			files = files[1:]
+			if usage, err = GetDiskUsage(settings.Path); err != nil {
+				break
+			}
+			utilization = int(usage)
+		}
+	}
+
+	removeEmptyArchiveDirs(settings.Path)
+
+	if m := getMetrics(); m != nil {
+		m.RecordCleanupOperation(archivePolicy, "success")
+		m.RecordCleanupDuration(archivePolicy, time.Since(startTime).Seconds())
+	}
+	if removed > 0 {
+		log.Info("Archive cleanup completed",
+			logger.Int("files_removed", removed),
+			logger.Int("disk_utilization", utilization),
+			logger.Int64("duration_ms", time.Since(startTime).Milliseconds()))
+	}
+
+	return CleanupResult{ClipsRemoved: removed, DiskUtilization: utilization}
+}
+
+// listArchiveFiles returns the archive files under baseDir, oldest first.
+func listArchiveFiles(baseDir string) ([]archiveFile, error) {
+	var files []archiveFile
+	err := filepath.WalkDir(baseDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		start, ok := ParseArchiveFileName(d.Name())
+		if !ok {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // file removed during the walk
+		}
+		files = append(files, archiveFile{path: path, start: start, size: info.Size()})
+		return nil
+	})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("diskmanager").
+			Category(errors.CategoryFileIO).
+			Context("policy", archivePolicy).
+			Context("operation", "list_archive_files").
+			Context("base_dir", baseDir).
+			Build()
+	}
+
+	slices.SortFunc(files, func(a, b archiveFile) int {
+		return a.start.Compare(b.start)
+	})
+	return files, nil
+}
+
+// deleteArchiveFile removes an archive file and records metrics.
+func deleteArchiveFile(file *archiveFile, reason string) error {
+	log := GetLogger()
+	time.Sleep(deletionThrottleDelay)
+
+	if err := os.Remove(file.path); err != nil && !os.IsNotExist(err) {
+		enhancedErr := errors.New(err).
+			Component("diskmanager").
+			Category(errors.CategoryFileIO).
+			Context("policy", archivePolicy).
+			Context("operation", "delete_archive_file").
+			FileContext(file.path, file.size).
+			Build()
+		log.Error("Failed to delete archive file",
+			logger.String("path", file.path),
+			logger.Error(enhancedErr))
+		if m := getMetrics(); m != nil {
+			m.RecordCleanupError(archivePolicy, "file_deletion")
+		}
+		return enhancedErr
+	}
+
+	log.Info("Deleted archive file",
+		logger.String("reason", reason),
+		logger.String("path", file.path),
+		logger.Int64("size", file.size))
+	if m := getMetrics(); m != nil {
+		m.RecordFilesDeleted(archivePolicy, 1)
+		m.RecordBytesFreed(archivePolicy, float64(file.size))
+	}
+	return nil
+}
+
+// removeEmptyArchiveDirs removes the day and source directories left empty by
+// a cleanup. The base directory itself is kept.
+func removeEmptyArchiveDirs(baseDir string) {
+	sources, err := os.ReadDir(baseDir)
+	if err != nil {
+		return
+	}
+	for _, source := range sources {
+		if !source.IsDir() {
+			continue
+		}
+		sourceDir := filepath.Join(baseDir, source.Name())
+		days, err := os.ReadDir(sourceDir)
+		if err != nil {
+			continue
+		}
+		for _, day := range days {
+			if day.IsDir() {
+				_ = os.Remove(filepath.Join(sourceDir, day.Name())) // fails if not empty
+			}
+		}
+		_ = os.Remove(sourceDir)
+	}
+}
@@ -0,0 +1,90 @@
+package diskmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// createArchiveFile creates an empty archive file for source starting at start.
+func createArchiveFile(t *testing.T, baseDir, source string, start time.Time) string {
+	t.Helper()
+	start = start.UTC()
+	path := filepath.Join(baseDir, source, start.Format(time.DateOnly), start.Format(ArchiveFileTimeLayout)+".flac")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("fLaC"), 0o600))
+	return path
+}
+
+func TestParseArchiveFileName(t *testing.T) {
+	tests := []struct {
+		name  string
+		want  time.Time
+		valid bool
+	}{
+		{"20261018T140000.000Z.flac", time.Date(2026, 10, 18, 14, 0, 0, 0, time.UTC), true},
+		{"/archive/src/2026-10-18/20261018T141523.250Z.opus", time.Date(2026, 10, 18, 14, 15, 23, 250e6, time.UTC), true},
+		{"20261018T140000.000Z.wav", time.Time{}, false},
+		{"clip_20261018T140000.000Z.flac", time.Time{}, false},
+		{"20261018T140000.000Z", time.Time{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ParseArchiveFileName(tt.name)
+			assert.Equal(t, tt.valid, ok)
+			assert.True(t, tt.want.Equal(got), "got %v", got)
+		})
+	}
+}
+
+func TestArchiveCleanup_MaxAge(t *testing.T) {
+	baseDir := t.TempDir()
+	now := time.Now()
+
+	old := createArchiveFile(t, baseDir, "rtsp_1", now.Add(-50*time.Hour))
+	kept := createArchiveFile(t, baseDir, "rtsp_1", now.Add(-10*time.Hour))
+	otherSource := createArchiveFile(t, baseDir, "malgo_2", now.Add(-49*time.Hour))
+	unrelated := filepath.Join(baseDir, "notes.txt")
+	require.NoError(t, os.WriteFile(unrelated, []byte("keep"), 0o600))
+
+	result := ArchiveCleanup(nil, &conf.ArchiveSettings{Path: baseDir, MaxAge: "1d"})
+	require.NoError(t, result.Err)
+	assert.Equal(t, 2, result.ClipsRemoved)
+
+	assert.NoFileExists(t, old)
+	assert.NoFileExists(t, otherSource)
+	assert.FileExists(t, kept)
+	assert.FileExists(t, unrelated)
+
+	// Directories left empty by the cleanup are removed
+	assert.NoDirExists(t, filepath.Join(baseDir, "malgo_2"))
+	assert.DirExists(t, filepath.Dir(kept))
+}
+
+func TestArchiveCleanup_MaxUsageKeepsCurrentHour(t *testing.T) {
+	baseDir := t.TempDir()
+	now := time.Now()
+
+	older := createArchiveFile(t, baseDir, "rtsp_1", now.Add(-3*time.Hour))
+	newer := createArchiveFile(t, baseDir, "rtsp_1", now.Add(-2*time.Hour))
+	current := createArchiveFile(t, baseDir, "rtsp_1", now.Truncate(time.Hour))
+
+	// Any real disk is above the limit, so every finished file is removed
+	result := ArchiveCleanup(nil, &conf.ArchiveSettings{Path: baseDir, MaxAge: "30d", MaxUsage: "0.0001%"})
+	require.NoError(t, result.Err)
+	assert.Equal(t, 2, result.ClipsRemoved)
+
+	assert.NoFileExists(t, older)
+	assert.NoFileExists(t, newer)
+	assert.FileExists(t, current)
+}
+
+func TestArchiveCleanup_InvalidSettings(t *testing.T) {
+	result := ArchiveCleanup(nil, &conf.ArchiveSettings{Path: t.TempDir(), MaxAge: "forever"})
+	require.Error(t, result.Err)
+}
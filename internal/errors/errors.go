@@ -395,6 +395,7 @@ func init() {
 	RegisterComponent("imageprovider", "imageprovider")
 	RegisterComponent("diskmanager", "diskmanager")
 	RegisterComponent("ebird", "ebird")
+	RegisterComponent("onvif", "onvif")
 	RegisterComponent("mqtt", "mqtt")
 	RegisterComponent("weather", "weather")
 	RegisterComponent("conf", "configuration")
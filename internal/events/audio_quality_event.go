@@ -0,0 +1,125 @@
+package events
+
+import (
+	"fmt"
+	"time"
+)
+
+// Audio source kinds of audio quality events
+const (
+	AudioSourceStream = "stream"
+	AudioSourceDevice = "device"
+)
+
+// AudioQualityEvent represents a change of an audio quality issue of an audio source
+type AudioQualityEvent interface {
+	// GetSourceID returns the registry ID of the audio source
+	GetSourceID() string
+
+	// GetSourceName returns the display name of the audio source
+	GetSourceName() string
+
+	// GetSourceKind returns AudioSourceStream or AudioSourceDevice
+	GetSourceKind() string
+
+	// GetSourceURL returns the sanitized URL of a stream, empty for devices
+	GetSourceURL() string
+
+	// GetIssue returns the issue code, e.g. "clipping" or "mains_hum"
+	GetIssue() string
+
+	// GetValue returns the measurement that raised or cleared the issue
+	GetValue() float64
+
+	// GetSeverity returns SeverityWarning when the issue appears and
+	// SeverityRecovery when it clears
+	GetSeverity() string
+
+	// GetMessage returns a human-readable description of the issue
+	GetMessage() string
+
+	// GetTimestamp returns when the event occurred
+	GetTimestamp() time.Time
+}
+
+// AudioQualityEventData holds the fields of an audio quality event
+type AudioQualityEventData struct {
+	SourceID   string
+	SourceName string
+	SourceKind string
+	SourceURL  string
+	Issue      string
+	Value      float64
+	Severity   string
+	Message    string
+}
+
+// audioQualityEventImpl is the concrete implementation of AudioQualityEvent
+type audioQualityEventImpl struct {
+	data      AudioQualityEventData
+	timestamp time.Time
+}
+
+// NewAudioQualityEvent creates a new audio quality event
+func NewAudioQualityEvent(data AudioQualityEventData) AudioQualityEvent {
+	return &audioQualityEventImpl{data: data, timestamp: time.Now()}
+}
+
+// GetSourceID returns the registry ID of the audio source
+func (e *audioQualityEventImpl) GetSourceID() string {
+	return e.data.SourceID
+}
+
+// GetSourceName returns the display name of the audio source
+func (e *audioQualityEventImpl) GetSourceName() string {
+	return e.data.SourceName
+}
+
+// GetSourceKind returns AudioSourceStream or AudioSourceDevice
+func (e *audioQualityEventImpl) GetSourceKind() string {
+	return e.data.SourceKind
+}
+
+// GetSourceURL returns the sanitized URL of a stream, empty for devices
+func (e *audioQualityEventImpl) GetSourceURL() string {
+	return e.data.SourceURL
+}
+
+// GetIssue returns the issue code
+func (e *audioQualityEventImpl) GetIssue() string {
+	return e.data.Issue
+}
+
+// GetValue returns the measurement that raised or cleared the issue
+func (e *audioQualityEventImpl) GetValue() float64 {
+	return e.data.Value
+}
+
+// GetSeverity returns the severity of the event
+func (e *audioQualityEventImpl) GetSeverity() string {
+	return e.data.Severity
+}
+
+// GetMessage returns a human-readable description of the issue
+func (e *audioQualityEventImpl) GetMessage() string {
+	return e.data.Message
+}
+
+// GetTimestamp returns when the event occurred
+func (e *audioQualityEventImpl) GetTimestamp() time.Time {
+	return e.timestamp
+}
+
+// String returns a string representation of the audio quality event
+func (e *audioQualityEventImpl) String() string {
+	return fmt.Sprintf("AudioQuality: %s %s on %s (%s)",
+		e.data.Issue, e.data.Severity, e.data.SourceName, e.data.Message)
+}
+
+// AudioQualityEventConsumer represents a consumer that processes audio quality events
+type AudioQualityEventConsumer interface {
+	EventConsumer
+
+	// ProcessAudioQualityEvent processes a single audio quality event
+	ProcessAudioQualityEvent(event AudioQualityEvent) error
+}
@@ -30,6 +30,12 @@ const (
 	// EventTypeDetection represents bird detection events from the BirdNET analysis engine
 	EventTypeDetection EventType = "detection"
 
+	// EventTypeAudioQuality represents audio quality issues raised or cleared for an audio source
+	EventTypeAudioQuality EventType = "audio_quality"
+
+	// EventTypeSourceFailover represents switches of the analyzed audio source of a failover group
+	EventTypeSourceFailover EventType = "source_failover"
+
 	// EventTypeUnknown represents events that cannot be categorized into the above types
 	EventTypeUnknown EventType = "unknown"
 )
@@ -54,6 +60,10 @@ func getEventType(event any) EventType {
 		return EventTypeResource
 	case DetectionEvent:
 		return EventTypeDetection
+	case AudioQualityEvent:
+		return EventTypeAudioQuality
+	case SourceFailoverEvent:
+		return EventTypeSourceFailover
 	default:
 		// Return generic constant to avoid exposing internal types
 		// Use EventTypeUnknown instead of Go type strings for security
@@ -67,6 +77,8 @@ type EventBus struct {
 	errorEventChan     chan ErrorEvent
 	resourceEventChan  chan ResourceEvent
 	detectionEventChan chan DetectionEvent
+	audioEventChan     chan AudioQualityEvent
+	failoverEventChan  chan SourceFailoverEvent
 
 	// Configuration
 	config     *Config
@@ -83,8 +95,10 @@ type EventBus struct {
 
 	// Consumers
 	consumers          []EventConsumer
-	resourceConsumers  []ResourceEventConsumer  // Separate slice for resource event consumers
-	detectionConsumers []DetectionEventConsumer // Separate slice for detection event consumers
+	resourceConsumers  []ResourceEventConsumer       // Separate slice for resource event consumers
+	detectionConsumers []DetectionEventConsumer      // Separate slice for detection event consumers
+	audioConsumers     []AudioQualityEventConsumer   // Separate slice for audio quality event consumers
+	failoverConsumers  []SourceFailoverEventConsumer // Separate slice for source failover event consumers
 
 	// Deduplication
 	deduplicator *ErrorDeduplicator
@@ -181,6 +195,8 @@ func Initialize(config *Config) (*EventBus, error) {
 		errorEventChan:     make(chan ErrorEvent, config.BufferSize),
 		resourceEventChan:  make(chan ResourceEvent, resourceBufSize),
 		detectionEventChan: make(chan DetectionEvent, config.BufferSize),
+		audioEventChan:     make(chan AudioQualityEvent, resourceBufSize),
+		failoverEventChan:  make(chan SourceFailoverEvent, resourceBufSize),
 		bufferSize:         config.BufferSize,
 		workers:            config.Workers,
 		ctx:                ctx,
@@ -188,6 +204,8 @@ func Initialize(config *Config) (*EventBus, error) {
 		consumers:          make([]EventConsumer, 0),
 		resourceConsumers:  make([]ResourceEventConsumer, 0),
 		detectionConsumers: make([]DetectionEventConsumer, 0),
+		audioConsumers:     make([]AudioQualityEventConsumer, 0),
+		failoverConsumers:  make([]SourceFailoverEventConsumer, 0),
 		logger:             eventsLogger,
 		startTime:          time.Now(),
 	}
@@ -257,6 +275,16 @@ func (eb *EventBus) RegisterConsumer(consumer EventConsumer) error {
 		eb.detectionConsumers = append(eb.detectionConsumers, detectionConsumer)
 	}
 
+	// Check if consumer also implements AudioQualityEventConsumer
+	if audioConsumer, ok := consumer.(AudioQualityEventConsumer); ok {
+		eb.audioConsumers = append(eb.audioConsumers, audioConsumer)
+	}
+
+	// Check if consumer also implements SourceFailoverEventConsumer
+	if failoverConsumer, ok := consumer.(SourceFailoverEventConsumer); ok {
+		eb.failoverConsumers = append(eb.failoverConsumers, failoverConsumer)
+	}
+
 	// Update global flag for fast path optimization
 	hasActiveConsumers.Store(true)
 
@@ -459,6 +487,116 @@ func (eb *EventBus) TryPublishDetection(event DetectionEvent) bool {
 	}
 }
 
+// TryPublishAudioQuality attempts to publish an audio quality event without blocking
+// Returns true if the event was accepted, false if dropped
+func (eb *EventBus) TryPublishAudioQuality(event AudioQualityEvent) bool {
+	// Ultra-fast path: check global flag first (lock-free)
+	if !hasActiveConsumers.Load() {
+		if eb != nil {
+			atomic.AddUint64(&eb.stats.FastPathHits, 1)
+		}
+		return false
+	}
+
+	if eb == nil || !eb.initialized.Load() || !eb.running.Load() {
+		return false
+	}
+
+	// Debug logging for event publishing
+	if eb.config != nil && eb.config.Debug {
+		eb.logger.Debug("publishing audio quality event",
+			logger.String("source_id", event.GetSourceID()),
+			logger.String("issue", event.GetIssue()),
+			logger.String("severity", event.GetSeverity()),
+			logger.Int("buffer_used", len(eb.audioEventChan)),
+			logger.Int("buffer_capacity", cap(eb.audioEventChan)),
+		)
+	}
+
+	// Audio quality events only go to audio quality consumers
+	eb.mu.Lock()
+	hasConsumers := len(eb.audioConsumers) > 0
+	eb.mu.Unlock()
+
+	if !hasConsumers {
+		atomic.AddUint64(&eb.stats.FastPathHits, 1)
+		return false
+	}
+
+	// Non-blocking send
+	select {
+	case eb.audioEventChan <- event:
+		atomic.AddUint64(&eb.stats.EventsReceived, 1)
+		return true
+	default:
+		// Channel full, drop the event
+		atomic.AddUint64(&eb.stats.EventsDropped, 1)
+
+		if eb.logger != nil {
+			eb.logger.Debug("audio quality event dropped due to full buffer",
+				logger.String("source_id", event.GetSourceID()),
+				logger.String("issue", event.GetIssue()),
+			)
+		}
+		return false
+	}
+}
+
+// TryPublishSourceFailover attempts to publish a source failover event without blocking
+// Returns true if the event was accepted, false if dropped
+func (eb *EventBus) TryPublishSourceFailover(event SourceFailoverEvent) bool {
+	// Ultra-fast path: check global flag first (lock-free)
+	if !hasActiveConsumers.Load() {
+		if eb != nil {
+			atomic.AddUint64(&eb.stats.FastPathHits, 1)
+		}
+		return false
+	}
+
+	if eb == nil || !eb.initialized.Load() || !eb.running.Load() {
+		return false
+	}
+
+	// Debug logging for event publishing
+	if eb.config != nil && eb.config.Debug {
+		eb.logger.Debug("publishing source failover event",
+			logger.String("group", event.GetGroupName()),
+			logger.String("to_source_id", event.GetToSourceID()),
+			logger.Bool("recovery", event.IsRecovery()),
+			logger.Int("buffer_used", len(eb.failoverEventChan)),
+			logger.Int("buffer_capacity", cap(eb.failoverEventChan)),
+		)
+	}
+
+	// Source failover events only go to source failover consumers
+	eb.mu.Lock()
+	hasConsumers := len(eb.failoverConsumers) > 0
+	eb.mu.Unlock()
+
+	if !hasConsumers {
+		atomic.AddUint64(&eb.stats.FastPathHits, 1)
+		return false
+	}
+
+	// Non-blocking send
+	select {
+	case eb.failoverEventChan <- event:
+		atomic.AddUint64(&eb.stats.EventsReceived, 1)
+		return true
+	default:
+		// Channel full, drop the event
+		atomic.AddUint64(&eb.stats.EventsDropped, 1)
+
+		if eb.logger != nil {
+			eb.logger.Debug("source failover event dropped due to full buffer",
+				logger.String("group", event.GetGroupName()),
+				logger.String("to_source_id", event.GetToSourceID()),
+			)
+		}
+		return false
+	}
+}
+
 // start begins the worker goroutines
 func (eb *EventBus) start() {
 	if eb.running.Swap(true) {
@@ -552,6 +690,20 @@ func (eb *EventBus) worker(id int) {
 			} else {
 				eb.processDetectionEvent(event, workerLogger)
 			}
+
+		case event, ok := <-eb.audioEventChan:
+			if !ok {
+				workerLogger.Debug("worker stopping due to audio quality channel closure")
+				return
+			}
+			eb.processAudioQualityEvent(event, workerLogger)
+
+		case event, ok := <-eb.failoverEventChan:
+			if !ok {
+				workerLogger.Debug("worker stopping due to source failover channel closure")
+				return
+			}
+			eb.processSourceFailoverEvent(event, workerLogger)
 		}
 	}
 }
@@ -664,6 +816,48 @@ func (eb *EventBus) processDetectionEvent(event DetectionEvent, log logger.Logge
 	}
 }
 
+// processAudioQualityEvent sends the audio quality event to all registered audio quality consumers
+func (eb *EventBus) processAudioQualityEvent(event AudioQualityEvent, log logger.Logger) {
+	eb.mu.Lock()
+	audioConsumers := make([]AudioQualityEventConsumer, len(eb.audioConsumers))
+	copy(audioConsumers, eb.audioConsumers)
+	eb.mu.Unlock()
+
+	for _, consumer := range audioConsumers {
+		logFields := []logger.Field{
+			logger.String("source_id", event.GetSourceID()),
+			logger.String("issue", event.GetIssue()),
+		}
+		eb.processEvent(
+			consumer.Name(),
+			func() error { return consumer.ProcessAudioQualityEvent(event) },
+			logFields,
+			log,
+		)
+	}
+}
+
+// processSourceFailoverEvent sends the source failover event to all registered source failover consumers
+func (eb *EventBus) processSourceFailoverEvent(event SourceFailoverEvent, log logger.Logger) {
+	eb.mu.Lock()
+	failoverConsumers := make([]SourceFailoverEventConsumer, len(eb.failoverConsumers))
+	copy(failoverConsumers, eb.failoverConsumers)
+	eb.mu.Unlock()
+
+	for _, consumer := range failoverConsumers {
+		logFields := []logger.Field{
+			logger.String("group", event.GetGroupName()),
+			logger.String("to_source_id", event.GetToSourceID()),
+		}
+		eb.processEvent(
+			consumer.Name(),
+			func() error { return consumer.ProcessSourceFailoverEvent(event) },
+			logFields,
+			log,
+		)
+	}
+}
+
 // Shutdown gracefully shuts down the event bus
 func (eb *EventBus) Shutdown(timeout time.Duration) error {
 	if eb == nil || !eb.initialized.Load() {
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"fmt"
+	"time"
+)
+
+// SourceFailoverEvent represents a switch of the analyzed audio source of a failover group
+type SourceFailoverEvent interface {
+	// GetGroupName returns the name of the failover group
+	GetGroupName() string
+
+	// GetFromSourceID returns the registry ID of the source analyzed before the switch
+	GetFromSourceID() string
+
+	// GetFromSourceName returns the display name of the source analyzed before the switch
+	GetFromSourceName() string
+
+	// GetToSourceID returns the registry ID of the source analyzed after the switch
+	GetToSourceID() string
+
+	// GetToSourceName returns the display name of the source analyzed after the switch
+	GetToSourceName() string
+
+	// IsRecovery returns true when the group switched back to a higher priority source
+	IsRecovery() bool
+
+	// GetMessage returns a human-readable description of the switch
+	GetMessage() string
+
+	// GetTimestamp returns when the event occurred
+	GetTimestamp() time.Time
+}
+
+// SourceFailoverEventData holds the fields of a source failover event
+type SourceFailoverEventData struct {
+	GroupName      string
+	FromSourceID   string
+	FromSourceName string
+	ToSourceID     string
+	ToSourceName   string
+	Recovery       bool
+	Message        string
+}
+
+// sourceFailoverEventImpl is the concrete implementation of SourceFailoverEvent
+type sourceFailoverEventImpl struct {
+	data      SourceFailoverEventData
+	timestamp time.Time
+}
+
+// NewSourceFailoverEvent creates a new source failover event
+func NewSourceFailoverEvent(data SourceFailoverEventData) SourceFailoverEvent {
+	return &sourceFailoverEventImpl{data: data, timestamp: time.Now()}
+}
+
+// GetGroupName returns the name of the failover group
+func (e *sourceFailoverEventImpl) GetGroupName() string {
+	return e.data.GroupName
+}
+
+// GetFromSourceID returns the registry ID of the source analyzed before the switch
+func (e *sourceFailoverEventImpl) GetFromSourceID() string {
+	return e.data.FromSourceID
+}
+
+// GetFromSourceName returns the display name of the source analyzed before the switch
+func (e *sourceFailoverEventImpl) GetFromSourceName() string {
+	return e.data.FromSourceName
+}
+
+// GetToSourceID returns the registry ID of the source analyzed after the switch
+func (e *sourceFailoverEventImpl) GetToSourceID() string {
+	return e.data.ToSourceID
+}
+
+// GetToSourceName returns the display name of the source analyzed after the switch
+func (e *sourceFailoverEventImpl) GetToSourceName() string {
+	return e.data.ToSourceName
+}
+
+// IsRecovery returns true when the group switched back to a higher priority source
+func (e *sourceFailoverEventImpl) IsRecovery() bool {
+	return e.data.Recovery
+}
+
+// GetMessage returns a human-readable description of the switch
+func (e *sourceFailoverEventImpl) GetMessage() string {
+	return e.data.Message
+}
+
+// GetTimestamp returns when the event occurred
+func (e *sourceFailoverEventImpl) GetTimestamp() time.Time {
+	return e.timestamp
+}
+
+// String returns a string representation of the source failover event
+func (e *sourceFailoverEventImpl) String() string {
+	return fmt.Sprintf("SourceFailover: %s switched from %s to %s (recovery: %t)",
+		e.data.GroupName, e.data.FromSourceName, e.data.ToSourceName, e.data.Recovery)
+}
+
+// SourceFailoverEventConsumer represents a consumer that processes source failover events
+type SourceFailoverEventConsumer interface {
+	EventConsumer
+
+	// ProcessSourceFailoverEvent processes a single source failover event
+	ProcessSourceFailoverEvent(event SourceFailoverEvent) error
+}
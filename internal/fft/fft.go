@@ -0,0 +1,39 @@
+// Package fft computes discrete Fourier transforms of audio frames. It is
+// shared by the native spectrogram renderer and the audio analysis in myaudio.
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+)
+
+// Transform computes the discrete Fourier transform of x in place with a
+// radix-2 FFT. The length of x must be a power of two.
+func Transform(x []complex128) {
+	n := len(x)
+
+	// Bit-reversal permutation
+	for i, j := 1, 0; i < n; i++ {
+		bit := n >> 1
+		for ; j&bit != 0; bit >>= 1 {
+			j ^= bit
+		}
+		j ^= bit
+		if i < j {
+			x[i], x[j] = x[j], x[i]
+		}
+	}
+
+	for size := 2; size <= n; size <<= 1 {
+		step := cmplx.Exp(complex(0, -2*math.Pi/float64(size)))
+		for start := 0; start < n; start += size {
+			w := complex(1, 0)
+			for k := range size / 2 {
+				even, odd := x[start+k], x[start+k+size/2]*w
+				x[start+k] = even + odd
+				x[start+k+size/2] = even - odd
+				w *= step
+			}
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package fft
+
+import (
+	"math"
+	"math/cmplx"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransform_MatchesDFT(t *testing.T) {
+	t.Parallel()
+
+	const n = 64
+	x := make([]complex128, n)
+	for i := range x {
+		x[i] = complex(math.Sin(float64(i)*0.7)+0.3*math.Cos(float64(i)*2.1), 0)
+	}
+	got := append([]complex128(nil), x...)
+	Transform(got)
+
+	for k := range n {
+		var want complex128
+		for i, v := range x {
+			want += v * cmplx.Exp(complex(0, -2*math.Pi*float64(k*i)/n))
+		}
+		assert.InDelta(t, real(want), real(got[k]), 1e-9, "bin %d real", k)
+		assert.InDelta(t, imag(want), imag(got[k]), 1e-9, "bin %d imag", k)
+	}
+}
@@ -0,0 +1,175 @@
+package hub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/httpclient"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// maxErrorBody limits how much of an error response is kept for diagnostics.
+const maxErrorBody = 512
+
+// GetLogger returns the hub package logger.
+func GetLogger() logger.Logger {
+	return logger.Global().Module("hub")
+}
+
+// Client pushes detections from a node to a hub. Safe for concurrent use.
+type Client struct {
+	baseURL string
+	token   string
+	http    *httpclient.Client
+}
+
+// NewClient creates a client for the hub configured in settings.
+func NewClient(settings *conf.HubUpstreamSettings) (*Client, error) {
+	u, err := url.Parse(strings.TrimSpace(settings.URL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Newf("invalid hub URL").
+			Component("hub").
+			Category(errors.CategoryConfiguration).
+			Build()
+	}
+
+	timeout := settings.Timeout
+	if timeout <= 0 {
+		timeout = httpclient.DefaultTimeout
+	}
+	cfg := httpclient.DefaultConfig()
+	cfg.DefaultTimeout = timeout
+
+	return &Client{
+		baseURL: strings.TrimRight(u.String(), "/"),
+		token:   settings.Token,
+		http:    httpclient.New(&cfg),
+	}, nil
+}
+
+// PushDetection sends a detection to the hub. Pushing the same detection
+// again is safe; the hub reports it as a duplicate.
+func (c *Client) PushDetection(ctx context.Context, d *Detection) (*IngestResponse, error) {
+	body, err := json.Marshal(d)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal detection: %w", err)
+	}
+
+	req, err := c.newRequest(ctx, http.MethodPost, DetectionsPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(ctx, req)
+	if err != nil {
+		return nil, c.networkError(err, "push_detection")
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, statusError(resp, "push_detection")
+	}
+
+	var result IngestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode hub response: %w", err)
+	}
+	return &result, nil
+}
+
+// UploadClip uploads the audio clip of a previously pushed detection.
+func (c *Client) UploadClip(ctx context.Context, remoteID uint, clipPath string) error {
+	f, err := os.Open(clipPath)
+	if err != nil {
+		return fmt.Errorf("failed to open clip: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat clip: %w", err)
+	}
+
+	path := fmt.Sprintf(ClipPathFormat, remoteID) + "?filename=" + url.QueryEscape(filepath.Base(clipPath))
+	req, err := c.newRequest(ctx, http.MethodPut, path, f)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.http.Do(ctx, req)
+	if err != nil {
+		return c.networkError(err, "upload_clip")
+	}
+	defer closeBody(resp)
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return statusError(resp, "upload_clip")
+	}
+	return nil
+}
+
+// newRequest creates an authenticated request against the hub.
+func (c *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create hub request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	return req, nil
+}
+
+// networkError wraps a transport failure.
+func (c *Client) networkError(err error, operation string) error {
+	return errors.New(err).
+		Component("hub").
+		Category(errors.CategoryNetwork).
+		Context("operation", operation).
+		Context("hub_url", c.baseURL).
+		Context("retryable", true).
+		Build()
+}
+
+// statusError builds an error for an unexpected hub response.
+func statusError(resp *http.Response, operation string) error {
+	preview, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	return errors.Newf("hub returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(preview))).
+		Component("hub").
+		Category(errors.CategoryNetwork).
+		Context("operation", operation).
+		Context("status_code", resp.StatusCode).
+		Context("retryable", resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests).
+		Build()
+}
+
+// IsRetryable reports whether a failed hub request may succeed when repeated.
+// Network failures and server errors may; rejected requests, such as ones
+// with an invalid token or payload, fail the same way again.
+func IsRetryable(err error) bool {
+	var enhanced *errors.EnhancedError
+	if !errors.As(err, &enhanced) {
+		return false
+	}
+	retryable, _ := enhanced.GetContext()["retryable"].(bool)
+	return retryable
+}
+
+// closeBody drains and closes a response body so the connection can be reused.
+func closeBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+	if err := resp.Body.Close(); err != nil {
+		GetLogger().Debug("failed to close hub response body", logger.Error(err))
+	}
+}
@@ -0,0 +1,131 @@
+package hub
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+func TestClientPushDetection(t *testing.T) {
+	var received Detection
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, DetectionsPath, r.URL.Path)
+		assert.Equal(t, "Bearer node-token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(IngestResponse{ID: 7})
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&conf.HubUpstreamSettings{URL: server.URL + "/", Token: "node-token"})
+	require.NoError(t, err)
+
+	begin := time.Date(2024, 5, 1, 6, 15, 0, 0, time.UTC)
+	note := &datastore.Note{
+		ID:             42,
+		BeginTime:      begin,
+		ScientificName: "Turdus merula",
+		CommonName:     "Eurasian Blackbird",
+		Confidence:     0.91,
+		Source:         datastore.AudioSource{ID: "rtsp_1", SafeString: "rtsp://10.0.0.5/stream"},
+	}
+	resp, err := client.PushDetection(t.Context(), FromNote(note, []datastore.Results{{Species: "Turdus philomelos", Confidence: 0.2}}))
+	require.NoError(t, err)
+	assert.Equal(t, uint(7), resp.ID)
+	assert.False(t, resp.Duplicate)
+
+	assert.Equal(t, uint(42), received.RemoteID)
+	assert.True(t, begin.Equal(received.DetectedAt))
+	assert.Equal(t, "rtsp://10.0.0.5/stream", received.Source.URI)
+	require.Len(t, received.Results, 1)
+	assert.Equal(t, "Turdus philomelos", received.Results[0].Species)
+}
+
+func TestClientErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&conf.HubUpstreamSettings{URL: server.URL, Token: "wrong"})
+	require.NoError(t, err)
+
+	_, err = client.PushDetection(t.Context(), &Detection{RemoteID: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.False(t, IsRetryable(err), "rejected requests are not retried")
+}
+
+func TestIsRetryable(t *testing.T) {
+	var status atomic.Int32
+	status.Store(http.StatusServiceUnavailable)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(int(status.Load()))
+	}))
+	client, err := NewClient(&conf.HubUpstreamSettings{URL: server.URL, Token: "node-token"})
+	require.NoError(t, err)
+
+	_, err = client.PushDetection(t.Context(), &Detection{RemoteID: 1})
+	assert.True(t, IsRetryable(err), "server errors are retried")
+
+	status.Store(http.StatusConflict)
+	_, err = client.PushDetection(t.Context(), &Detection{RemoteID: 1})
+	assert.False(t, IsRetryable(err))
+
+	server.Close()
+	_, err = client.PushDetection(t.Context(), &Detection{RemoteID: 1})
+	assert.True(t, IsRetryable(err), "network errors are retried")
+}
+
+func TestClientUploadClip(t *testing.T) {
+	clipPath := filepath.Join(t.TempDir(), "turdus_merula_91p_20240501T061500Z.wav")
+	require.NoError(t, os.WriteFile(clipPath, []byte("RIFF-test-audio"), 0o600))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "/api/v2/hub/detections/42/clip", r.URL.Path)
+		assert.Equal(t, filepath.Base(clipPath), r.URL.Query().Get("filename"))
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, "RIFF-test-audio", string(body))
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&conf.HubUpstreamSettings{URL: server.URL, Token: "node-token"})
+	require.NoError(t, err)
+	require.NoError(t, client.UploadClip(t.Context(), 42, clipPath))
+}
+
+func TestDetectionToNote(t *testing.T) {
+	loc := time.FixedZone("hub", 2*60*60)
+	d := &Detection{
+		RemoteID:       5,
+		DetectedAt:     time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC),
+		ScientificName: "Strix aluco",
+		Confidence:     0.8,
+		Source:         Source{ID: "malgo_1", URI: "sysdefault", DisplayName: "Garden mic"},
+	}
+	require.NoError(t, d.Validate())
+
+	note, _ := d.ToNote("north", loc)
+	assert.Equal(t, "north", note.SourceNode)
+	assert.Equal(t, "2024-05-02", note.Date, "date is expressed in the hub's time zone")
+	assert.Equal(t, "01:30:00", note.Time)
+	assert.Equal(t, "Strix aluco", note.CommonName, "common name falls back to scientific name")
+	assert.Equal(t, "sysdefault", note.Source.SafeString)
+
+	d.Confidence = 1.5
+	assert.Error(t, d.Validate())
+}
@@ -0,0 +1,159 @@
+// Package hub implements multi-station aggregation: remote BirdNET-Go nodes
+// push their detections, and optionally audio clips, to a hub instance over
+// the authenticated v2 API.
+//
+// This package holds the wire format shared by both ends and the client used
+// by nodes. The receiving endpoints live in the v2 API.
+package hub
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// API paths on the hub, relative to its base URL.
+const (
+	DetectionsPath = "/api/v2/hub/detections"
+	// ClipPathFormat takes the remote detection ID.
+	ClipPathFormat = "/api/v2/hub/detections/%d/clip"
+)
+
+// Source describes the audio source a detection was made on at the remote node.
+type Source struct {
+	ID          string `json:"id"`                    // runtime source ID on the node, e.g. "rtsp_87b89761"
+	URI         string `json:"uri"`                   // sanitized source URI, without credentials
+	DisplayName string `json:"displayName,omitempty"` // user-facing source name
+}
+
+// Result is a secondary prediction for a detection.
+type Result struct {
+	Species    string  `json:"species"`
+	Confidence float32 `json:"confidence"`
+}
+
+// Detection is a detection as pushed from a node to the hub.
+type Detection struct {
+	RemoteID       uint      `json:"remoteId"` // detection ID in the node's database, used for deduplication
+	DetectedAt     time.Time `json:"detectedAt"`
+	BeginTime      time.Time `json:"beginTime,omitzero"`
+	EndTime        time.Time `json:"endTime,omitzero"`
+	ScientificName string    `json:"scientificName"`
+	CommonName     string    `json:"commonName"`
+	SpeciesCode    string    `json:"speciesCode,omitempty"`
+	Confidence     float64   `json:"confidence"`
+	Threshold      float64   `json:"threshold,omitempty"`
+	Sensitivity    float64   `json:"sensitivity,omitempty"`
+	Latitude       float64   `json:"latitude,omitempty"`
+	Longitude      float64   `json:"longitude,omitempty"`
+	Source         Source    `json:"source"`
+	Results        []Result  `json:"results,omitempty"`
+}
+
+// IngestResponse is returned by the hub for a pushed detection.
+type IngestResponse struct {
+	ID        uint `json:"id"`        // detection ID on the hub
+	Duplicate bool `json:"duplicate"` // true if the hub had already received this detection
+}
+
+// NodesResponse lists the nodes configured on the hub.
+type NodesResponse struct {
+	Nodes []string `json:"nodes"`
+}
+
+// Validate checks that a pushed detection carries the fields the hub needs.
+func (d *Detection) Validate() error {
+	switch {
+	case d.RemoteID == 0:
+		return newValidationError("remoteId is required")
+	case d.DetectedAt.IsZero():
+		return newValidationError("detectedAt is required")
+	case strings.TrimSpace(d.ScientificName) == "":
+		return newValidationError("scientificName is required")
+	case d.Confidence < 0 || d.Confidence > 1:
+		return newValidationError(fmt.Sprintf("confidence %.3f out of range [0, 1]", d.Confidence))
+	case d.Latitude < -90 || d.Latitude > 90 || d.Longitude < -180 || d.Longitude > 180:
+		return newValidationError("coordinates out of range")
+	}
+	return nil
+}
+
+// FromNote builds the push payload for a saved note. note.ID must be set.
+func FromNote(note *datastore.Note, results []datastore.Results) *Detection {
+	detectedAt := note.BeginTime
+	if detectedAt.IsZero() {
+		if t, err := time.ParseInLocation(time.DateTime, note.Date+" "+note.Time, time.Local); err == nil {
+			detectedAt = t
+		}
+	}
+
+	d := &Detection{
+		RemoteID:       note.ID,
+		DetectedAt:     detectedAt,
+		BeginTime:      note.BeginTime,
+		EndTime:        note.EndTime,
+		ScientificName: note.ScientificName,
+		CommonName:     note.CommonName,
+		SpeciesCode:    note.SpeciesCode,
+		Confidence:     note.Confidence,
+		Threshold:      note.Threshold,
+		Sensitivity:    note.Sensitivity,
+		Latitude:       note.Latitude,
+		Longitude:      note.Longitude,
+		Source: Source{
+			ID:          note.Source.ID,
+			URI:         note.Source.SafeString,
+			DisplayName: note.Source.DisplayName,
+		},
+	}
+	for _, r := range results {
+		d.Results = append(d.Results, Result{Species: r.Species, Confidence: r.Confidence})
+	}
+	return d
+}
+
+// ToNote converts a received detection into a note attributed to nodeName.
+// Date and time are expressed in loc, the hub's local time zone.
+func (d *Detection) ToNote(nodeName string, loc *time.Location) (*datastore.Note, []datastore.Results) {
+	local := d.DetectedAt.In(loc)
+	note := &datastore.Note{
+		SourceNode:     nodeName,
+		Date:           local.Format(time.DateOnly),
+		Time:           local.Format(time.TimeOnly),
+		BeginTime:      d.BeginTime,
+		EndTime:        d.EndTime,
+		SpeciesCode:    d.SpeciesCode,
+		ScientificName: d.ScientificName,
+		CommonName:     d.CommonName,
+		Confidence:     d.Confidence,
+		Latitude:       d.Latitude,
+		Longitude:      d.Longitude,
+		Threshold:      d.Threshold,
+		Sensitivity:    d.Sensitivity,
+		Source: datastore.AudioSource{
+			ID:          d.Source.ID,
+			SafeString:  d.Source.URI,
+			DisplayName: d.Source.DisplayName,
+		},
+	}
+	if note.CommonName == "" {
+		note.CommonName = note.ScientificName
+	}
+
+	results := make([]datastore.Results, 0, len(d.Results))
+	for _, r := range d.Results {
+		results = append(results, datastore.Results{Species: r.Species, Confidence: r.Confidence})
+	}
+	return note, results
+}
+
+// newValidationError creates a validation error for an invalid payload.
+func newValidationError(msg string) error {
+	return errors.Newf("invalid hub detection: %s", msg).
+		Component("hub").
+		Category(errors.CategoryValidation).
+		Build()
+}
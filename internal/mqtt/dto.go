@@ -52,6 +52,12 @@ type MQTTEventDTO struct {
 	ModelVersion  string `json:"modelVersion,omitempty"`  // "2.4"
 	IsCustomModel bool   `json:"isCustomModel,omitempty"` // Custom model flag
 	Timezone      string `json:"timezone,omitempty"`      // e.g., "Europe/Helsinki"
+
+	// Compass bearing in degrees of the sound, only from microphone arrays
+	Bearing *float64 `json:"bearing,omitempty"`
+
+	// Display names of grouped sources that heard the bird less clearly
+	MergedSources []string `json:"mergedSources,omitempty"`
 }
 
 // BirdImageDTO represents the species thumbnail in MQTT payloads.
@@ -91,6 +97,11 @@ func NewMQTTEventDTO(r *detection.Result) *MQTTEventDTO {
 		ModelName:      r.Model.Name,
 		ModelVersion:   r.Model.Version,
 		IsCustomModel:  r.Model.Variant != "" && r.Model.Variant != detection.DefaultModelVariant,
+		Bearing:        r.Bearing,
+	}
+
+	for i := range r.MergedSources {
+		dto.MergedSources = append(dto.MergedSources, r.MergedSources[i].AudioSource.DisplayName)
 	}
 
 	// Add timezone if timestamp has location info
@@ -0,0 +1,302 @@
+// acoustic_indices.go: soundscape ecology indices computed from the capture stream
+package myaudio
+
+import (
+	"math"
+	"math/cmplx"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/fft"
+)
+
+// Acoustic index parameters. Frequency bands follow the defaults of the
+// soundecology R package so values are comparable with offline analyses.
+const (
+	// AcousticIndicesInterval is the span of audio each set of indices covers
+	AcousticIndicesInterval = time.Minute
+
+	acousticIndexFFTSize = 512
+	// acousticIndexACICluster is the time step over which ACI intensity
+	// differences are normalized
+	acousticIndexACICluster = 5 * time.Second
+	// acousticIndexACIFloor is the mean bin amplitude, far below 16-bit
+	// resolution, under which a bin is numerical noise and excluded from ACI
+	acousticIndexACIFloor = 1e-7
+	// ADI: 1 kHz bands up to 10 kHz, cells above -50 dBFS count as occupied
+	acousticIndexADIMaxFreq   = 10000.0
+	acousticIndexADIBandWidth = 1000.0
+	acousticIndexADIThreshold = -50.0
+	// NDSI: anthrophony 1-2 kHz, biophony 2-11 kHz
+	acousticIndexAnthroMin = 1000.0
+	acousticIndexAnthroMax = 2000.0
+	acousticIndexBioMin    = 2000.0
+	acousticIndexBioMax    = 11000.0
+	// BI: area of the mean spectrum over 2-8 kHz
+	acousticIndexBIMin = 2000.0
+	acousticIndexBIMax = 8000.0
+)
+
+// AcousticIndices holds standard soundscape ecology indices of one source
+// over one interval.
+//
+//   - ACI, Acoustic Complexity Index: variability of intensity within frequency bins
+//   - ADI, Acoustic Diversity Index: Shannon diversity of occupied 1 kHz bands
+//   - NDSI, Normalized Difference Soundscape Index: biophony (2-11 kHz) versus
+//     anthrophony (1-2 kHz), from -1 to 1
+//   - BI, Bioacoustic Index: area of the 2-8 kHz mean spectrum above its minimum
+//   - H, acoustic entropy: product of temporal (Ht) and spectral (Hf) entropy, from 0 to 1
+type AcousticIndices struct {
+	Start    time.Time `json:"start"`
+	Duration int       `json:"duration_seconds"`
+	ACI      float64   `json:"aci"`
+	ADI      float64   `json:"adi"`
+	NDSI     float64   `json:"ndsi"`
+	BI       float64   `json:"bi"`
+	H        float64   `json:"h"`
+}
+
+// acousticIndexAccumulator computes acoustic indices from a stream of samples.
+// Samples are split into non-overlapping Hann-windowed frames; per-frame
+// spectra are accumulated until an interval of audio is complete.
+type acousticIndexAccumulator struct {
+	sampleRate        int
+	binWidth          float64
+	window            []float64
+	amplitudeScale    float64 // converts FFT magnitude to full scale amplitude
+	framesPerInterval int
+	framesPerCluster  int
+
+	pending []float64    // samples not yet forming a full frame
+	buf     []complex128 // FFT work buffer
+
+	frames       int
+	sumPower     []float64 // per bin power summed over frames
+	sumAmplitude []float64 // per bin amplitude summed over frames
+	envelope     []float64 // per frame RMS
+
+	// ACI state of the current cluster
+	clusterFrames int
+	prevAmplitude []float64
+	clusterDiff   []float64
+	clusterSum    []float64
+	aci           float64
+
+	// ADI: occupied cells per 1 kHz band
+	adiOccupied []int
+	adiCells    []int
+}
+
+// newAcousticIndexAccumulator returns an accumulator for audio at sampleRate.
+func newAcousticIndexAccumulator(sampleRate int) *acousticIndexAccumulator {
+	bins := acousticIndexFFTSize / 2
+	window := make([]float64, acousticIndexFFTSize)
+	var windowSum float64
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(acousticIndexFFTSize))
+		windowSum += window[i]
+	}
+
+	framesPerSecond := float64(sampleRate) / acousticIndexFFTSize
+	adiBands := int(math.Min(acousticIndexADIMaxFreq, float64(sampleRate)/2) / acousticIndexADIBandWidth)
+
+	return &acousticIndexAccumulator{
+		sampleRate:        sampleRate,
+		binWidth:          float64(sampleRate) / acousticIndexFFTSize,
+		window:            window,
+		amplitudeScale:    2 / windowSum,
+		framesPerInterval: int(framesPerSecond * AcousticIndicesInterval.Seconds()),
+		framesPerCluster:  max(int(framesPerSecond*acousticIndexACICluster.Seconds()), 2),
+		pending:           make([]float64, 0, acousticIndexFFTSize),
+		buf:               make([]complex128, acousticIndexFFTSize),
+		sumPower:          make([]float64, bins),
+		sumAmplitude:      make([]float64, bins),
+		prevAmplitude:     make([]float64, bins),
+		clusterDiff:       make([]float64, bins),
+		clusterSum:        make([]float64, bins),
+		adiOccupied:       make([]int, adiBands),
+		adiCells:          make([]int, adiBands),
+	}
+}
+
+// add processes normalized samples and returns the indices of every interval
+// completed by them, stamped as ending at now.
+func (a *acousticIndexAccumulator) add(samples []float64, now time.Time) []AcousticIndices {
+	var completed []AcousticIndices
+	for len(samples) > 0 {
+		n := min(acousticIndexFFTSize-len(a.pending), len(samples))
+		a.pending = append(a.pending, samples[:n]...)
+		samples = samples[n:]
+		if len(a.pending) < acousticIndexFFTSize {
+			break
+		}
+		a.processFrame(a.pending)
+		a.pending = a.pending[:0]
+
+		if a.frames >= a.framesPerInterval {
+			completed = append(completed, a.result(now))
+			a.reset()
+		}
+	}
+	return completed
+}
+
+// processFrame accumulates the spectrum of one frame.
+func (a *acousticIndexAccumulator) processFrame(frame []float64) {
+	var sumSquares float64
+	for i, s := range frame {
+		sumSquares += s * s
+		a.buf[i] = complex(s*a.window[i], 0)
+	}
+	a.envelope = append(a.envelope, math.Sqrt(sumSquares/float64(len(frame))))
+	fft.Transform(a.buf)
+
+	for k := range a.sumPower {
+		amplitude := cmplx.Abs(a.buf[k]) * a.amplitudeScale
+		a.sumPower[k] += amplitude * amplitude
+		a.sumAmplitude[k] += amplitude
+
+		if a.clusterFrames > 0 {
+			a.clusterDiff[k] += math.Abs(amplitude - a.prevAmplitude[k])
+		}
+		a.clusterSum[k] += amplitude
+		a.prevAmplitude[k] = amplitude
+
+		if band := int(float64(k) * a.binWidth / acousticIndexADIBandWidth); band < len(a.adiCells) {
+			a.adiCells[band]++
+			if 20*math.Log10(amplitude+1e-12) > acousticIndexADIThreshold {
+				a.adiOccupied[band]++
+			}
+		}
+	}
+	a.frames++
+	a.clusterFrames++
+	if a.clusterFrames >= a.framesPerCluster {
+		a.closeCluster()
+	}
+}
+
+// closeCluster adds the ACI of the current cluster to the interval total.
+func (a *acousticIndexAccumulator) closeCluster() {
+	if a.clusterFrames >= 2 {
+		for k := range a.clusterDiff {
+			if a.clusterSum[k] > acousticIndexACIFloor*float64(a.clusterFrames) {
+				a.aci += a.clusterDiff[k] / a.clusterSum[k]
+			}
+		}
+	}
+	a.clusterFrames = 0
+	clear(a.clusterDiff)
+	clear(a.clusterSum)
+}
+
+// result computes the indices of the accumulated interval.
+func (a *acousticIndexAccumulator) result(now time.Time) AcousticIndices {
+	a.closeCluster()
+	duration := time.Duration(float64(a.frames*acousticIndexFFTSize) / float64(a.sampleRate) * float64(time.Second))
+	return AcousticIndices{
+		Start:    now.Add(-duration),
+		Duration: int(math.Round(duration.Seconds())),
+		ACI:      a.aci,
+		ADI:      a.adi(),
+		NDSI:     a.ndsi(),
+		BI:       a.bi(),
+		H:        normalizedEntropy(a.envelope) * normalizedEntropy(a.sumAmplitude),
+	}
+}
+
+// adi returns the Shannon diversity of the fraction of occupied cells per band.
+func (a *acousticIndexAccumulator) adi() float64 {
+	proportions := make([]float64, len(a.adiCells))
+	for i, cells := range a.adiCells {
+		if cells > 0 {
+			proportions[i] = float64(a.adiOccupied[i]) / float64(cells)
+		}
+	}
+	return shannonEntropy(proportions)
+}
+
+// ndsi compares the power of the biophony and anthrophony bands.
+func (a *acousticIndexAccumulator) ndsi() float64 {
+	anthro := a.bandPower(acousticIndexAnthroMin, acousticIndexAnthroMax)
+	bio := a.bandPower(acousticIndexBioMin, acousticIndexBioMax)
+	if anthro+bio == 0 {
+		return 0
+	}
+	return (bio - anthro) / (bio + anthro)
+}
+
+// bandPower returns the summed power of the bins in [low, high).
+func (a *acousticIndexAccumulator) bandPower(low, high float64) float64 {
+	var power float64
+	for k, p := range a.sumPower {
+		if f := float64(k) * a.binWidth; f >= low && f < high {
+			power += p
+		}
+	}
+	return power
+}
+
+// bi returns the area between the mean spectrum in dB and its minimum over
+// the BI band, in dB × kHz.
+func (a *acousticIndexAccumulator) bi() float64 {
+	if a.frames == 0 {
+		return 0
+	}
+	var levels []float64
+	floor := math.Inf(1)
+	for k, sum := range a.sumAmplitude {
+		if f := float64(k) * a.binWidth; f < acousticIndexBIMin || f > acousticIndexBIMax {
+			continue
+		}
+		level := 20 * math.Log10(sum/float64(a.frames)+1e-12)
+		levels = append(levels, level)
+		floor = math.Min(floor, level)
+	}
+	var area float64
+	for _, level := range levels {
+		area += (level - floor) * a.binWidth / 1000
+	}
+	return area
+}
+
+// reset clears the accumulated interval, keeping pending samples.
+func (a *acousticIndexAccumulator) reset() {
+	a.frames = 0
+	a.aci = 0
+	a.clusterFrames = 0
+	a.envelope = a.envelope[:0]
+	clear(a.sumPower)
+	clear(a.sumAmplitude)
+	clear(a.clusterDiff)
+	clear(a.clusterSum)
+	clear(a.adiOccupied)
+	clear(a.adiCells)
+}
+
+// shannonEntropy returns the Shannon entropy in nats of values normalized to proportions.
+func shannonEntropy(values []float64) float64 {
+	var total float64
+	for _, v := range values {
+		total += v
+	}
+	if total == 0 {
+		return 0
+	}
+	var h float64
+	for _, v := range values {
+		if v > 0 {
+			p := v / total
+			h -= p * math.Log(p)
+		}
+	}
+	return h
+}
+
+// normalizedEntropy returns the Shannon entropy of values divided by its
+// maximum, so the result is between 0 and 1.
+func normalizedEntropy(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	return shannonEntropy(values) / math.Log(float64(len(values)))
+}
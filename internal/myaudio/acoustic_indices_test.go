@@ -0,0 +1,94 @@
+package myaudio
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const acousticIndexTestRate = 48000
+
+// toneSamples returns d of a sine at freq with the given amplitude.
+func toneSamples(freq, amplitude float64, d time.Duration) []float64 {
+	samples := make([]float64, int(d.Seconds()*acousticIndexTestRate))
+	for i := range samples {
+		samples[i] = amplitude * math.Sin(2*math.Pi*freq*float64(i)/acousticIndexTestRate)
+	}
+	return samples
+}
+
+// noiseSamples returns d of uniform white noise with the given amplitude.
+func noiseSamples(amplitude float64, d time.Duration) []float64 {
+	rng := rand.New(rand.NewPCG(1, 2))
+	samples := make([]float64, int(d.Seconds()*acousticIndexTestRate))
+	for i := range samples {
+		samples[i] = amplitude * (2*rng.Float64() - 1)
+	}
+	return samples
+}
+
+// computeIndices feeds samples in 100 ms chunks and returns the completed intervals.
+func computeIndices(t *testing.T, samples []float64) []AcousticIndices {
+	t.Helper()
+	acc := newAcousticIndexAccumulator(acousticIndexTestRate)
+	end := time.Date(2026, 10, 18, 6, 1, 0, 0, time.UTC)
+	var results []AcousticIndices
+	const chunk = acousticIndexTestRate / 10
+	for i := 0; i < len(samples); i += chunk {
+		results = append(results, acc.add(samples[i:min(i+chunk, len(samples))], end)...)
+	}
+	return results
+}
+
+func TestAcousticIndices_Tone(t *testing.T) {
+	t.Parallel()
+
+	// A steady bird-band tone: no intensity variation, biophony only
+	results := computeIndices(t, toneSamples(3000, 0.5, 61*time.Second))
+	require.Len(t, results, 1, "one interval per minute, the remainder is kept")
+	indices := results[0]
+
+	assert.Equal(t, 60, indices.Duration)
+	assert.Equal(t, time.Date(2026, 10, 18, 6, 0, 0, 0, time.UTC), indices.Start.Round(time.Second))
+	assert.InDelta(t, 1.0, indices.NDSI, 0.01)
+	assert.Less(t, indices.ACI, 1.0)
+	assert.Greater(t, indices.BI, 0.0)
+	assert.Less(t, indices.H, 0.5, "a pure tone has a concentrated spectrum")
+
+	// The same tone in the anthrophony band
+	traffic := computeIndices(t, toneSamples(1500, 0.5, 60*time.Second))
+	require.Len(t, traffic, 1)
+	assert.InDelta(t, -1.0, traffic[0].NDSI, 0.01)
+}
+
+func TestAcousticIndices_NoiseVersusTone(t *testing.T) {
+	t.Parallel()
+
+	noise := computeIndices(t, noiseSamples(0.3, 60*time.Second))
+	tone := computeIndices(t, toneSamples(3000, 0.5, 60*time.Second))
+	require.Len(t, noise, 1)
+	require.Len(t, tone, 1)
+
+	assert.InDelta(t, math.Log(10), noise[0].ADI, 0.05, "noise occupies all ten 1 kHz bands evenly")
+	assert.Less(t, tone[0].ADI, noise[0].ADI)
+	assert.Greater(t, noise[0].ACI, tone[0].ACI, "noise varies from frame to frame")
+	assert.Greater(t, noise[0].H, 0.9, "noise has a flat envelope and spectrum")
+}
+
+func TestAcousticIndices_Silence(t *testing.T) {
+	t.Parallel()
+
+	results := computeIndices(t, make([]float64, 60*acousticIndexTestRate))
+	require.Len(t, results, 1)
+	indices := results[0]
+	for name, value := range map[string]float64{
+		"aci": indices.ACI, "adi": indices.ADI, "ndsi": indices.NDSI, "bi": indices.BI, "h": indices.H,
+	} {
+		assert.False(t, math.IsNaN(value) || math.IsInf(value, 0), "%s must be finite", name)
+		assert.Zero(t, value, name)
+	}
+}
@@ -27,15 +27,14 @@ var (
 	readSize             int                               // readSize is the number of bytes to read from the ring buffer
 	analysisBuffers      map[string]*ringbuffer.RingBuffer // analysisBuffers is a map to store ring buffers for each audio source
 	prevData             map[string][]byte                 // prevData is a map to store the previous data for each audio source
+	analysisOffsets      map[string]int64                  // analysisOffsets counts the stream bytes read or dropped for each audio source
 	abMutex              sync.RWMutex                      // Mutex to protect access to the analysisBuffers and prevData maps
 	warningCounter       map[string]int
 	warningCounterMutex  sync.Mutex              // Mutex to protect access to warningCounter map
 	analysisMetrics      *metrics.MyAudioMetrics // Global metrics instance for analysis buffer operations
 	analysisMetricsMutex sync.RWMutex            // Mutex for thread-safe access to analysisMetrics
 	analysisMetricsOnce  sync.Once               // Ensures metrics are only set once
-	readBufferPool       *BufferPool             // Global buffer pool for read operations
-	bufferPoolInitOnce   sync.Once               // Ensures buffer pool is initialized exactly once
-	errBufferPoolInit    error                   // Stores any error from buffer pool initialization
+	readBufferPool       *BufferPool             // Global buffer pool for read operations, created with the first buffer
 )
 
 // init initializes the warningCounter map
@@ -102,27 +101,6 @@ func AllocateAnalysisBuffer(capacity int, sourceID string) error {
 		return enhancedErr
 	}
 
-	settings := conf.Setting()
-
-	// Initialize buffer pool sizes exactly once, thread-safely
-	bufferPoolInitOnce.Do(func() {
-		overlapSize = SecondsToBytes(settings.BirdNET.Overlap)
-		readSize = conf.BufferSize - overlapSize
-		readBufferPool, errBufferPoolInit = NewBufferPool(readSize)
-	})
-
-	// Check if initialization failed
-	if errBufferPoolInit != nil {
-		enhancedErr := errors.New(errBufferPoolInit).
-			Component("myaudio").
-			Category(errors.CategorySystem).
-			Context("operation", "allocate_analysis_buffer").
-			Context("source", sourceID).
-			Context("buffer_pool_size", readSize).
-			Build()
-		return enhancedErr
-	}
-
 	// Initialize the analysis ring buffer
 	ab := ringbuffer.New(capacity)
 	if ab == nil {
@@ -162,6 +140,26 @@ func AllocateAnalysisBuffer(capacity int, sourceID string) error {
 		return enhancedErr
 	}
 
+	// Initialize the buffer pool with the first buffer, and again after the
+	// last buffer was removed
+	if readBufferPool == nil {
+		overlap := SecondsToBytes(conf.Setting().BirdNET.Overlap)
+		pool, err := NewBufferPool(conf.BufferSize - overlap)
+		if err != nil {
+			ab.Reset()
+			return errors.New(err).
+				Component("myaudio").
+				Category(errors.CategorySystem).
+				Context("operation", "allocate_analysis_buffer").
+				Context("source", sourceID).
+				Context("buffer_pool_size", conf.BufferSize-overlap).
+				Build()
+		}
+		readBufferPool = pool
+		overlapSize = overlap
+		readSize = conf.BufferSize - overlap
+	}
+
 	// Initialize maps if they don't exist
 	if analysisBuffers == nil {
 		analysisBuffers = make(map[string]*ringbuffer.RingBuffer)
@@ -169,12 +167,18 @@ func AllocateAnalysisBuffer(capacity int, sourceID string) error {
 	if prevData == nil {
 		prevData = make(map[string][]byte)
 	}
+	if analysisOffsets == nil {
+		analysisOffsets = make(map[string]int64)
+	}
 	if warningCounter == nil {
 		warningCounter = make(map[string]int)
 	}
 
 	analysisBuffers[sourceID] = ab
 	prevData[sourceID] = nil
+	analysisOffsets[sourceID] = 0
+	// Offsets restart from zero, so must the stream timeline
+	resetStreamTimeline(sourceID)
 	warningCounter[sourceID] = 0
 
 	// Acquire reference to this source using the migrated ID
@@ -221,7 +225,10 @@ func RemoveAnalysisBuffer(sourceID string) error {
 	// Remove from all maps
 	delete(analysisBuffers, sourceID)
 	delete(prevData, sourceID)
+	delete(analysisOffsets, sourceID)
 	delete(warningCounter, sourceID)
+	resetStreamTimeline(sourceID)
+	resetAudioDiagnostics(sourceID)
 
 	// Clean up buffer pool if this was the last buffer (prevents memory leak)
 	if len(analysisBuffers) == 0 && readBufferPool != nil {
@@ -283,7 +290,14 @@ func InitAnalysisBuffers(capacity int, sources []string) error {
 }
 
 // WriteToAnalysisBuffer writes audio data into the ring buffer for a given source ID.
+// Audio of standby sources of failover groups is dropped.
 func WriteToAnalysisBuffer(sourceID string, data []byte) error {
+	if !failoverAnalyzes(sourceID, time.Now()) {
+		// Dropped audio still advances the stream, like audio of a full buffer
+		skipAnalysisOffset(sourceID, len(data))
+		return nil
+	}
+
 	log := GetLogger()
 
 	// Get source info for enhanced logging (ID + DisplayName)
@@ -371,7 +385,7 @@ func WriteToAnalysisBuffer(sourceID string, data []byte) error {
 
 	// Write data to the ring buffer with retry logic
 	var lastErr error
-	var n int
+	var n, written int
 	for retry := range maxRetries {
 		// Use anonymous function with defer to ensure mutex is always unlocked
 		// This prevents deadlocks even if ab.Write panics
@@ -381,10 +395,13 @@ func WriteToAnalysisBuffer(sourceID string, data []byte) error {
 
 			var writeErr error
 			n, writeErr = ab.Write(data) // Write data to the ring buffer
+			written += n
 			return writeErr
 		}()
 
 		if err == nil {
+			skipAnalysisOffset(sourceID, len(data)-written)
+
 			// Record successful write metrics
 			if m := getAnalysisMetrics(); m != nil {
 				duration := time.Since(start).Seconds()
@@ -454,6 +471,7 @@ func WriteToAnalysisBuffer(sourceID string, data []byte) error {
 	}
 
 	// If we've reached this point, we've failed all retries
+	skipAnalysisOffset(sourceID, len(data)-written)
 	log.Error("failed to write to analysis buffer after all retries",
 		logger.String("display_name", displayName),
 		logger.String("source_id", sourceID),
@@ -486,8 +504,38 @@ func WriteToAnalysisBuffer(sourceID string, data []byte) error {
 	return enhancedErr
 }
 
+// skipAnalysisOffset counts bytes dropped by a full analysis buffer as read,
+// keeping the offsets of later audio aligned with the stream timeline.
+func skipAnalysisOffset(sourceID string, dropped int) {
+	if dropped <= 0 {
+		return
+	}
+	abMutex.Lock()
+	defer abMutex.Unlock()
+	if _, exists := analysisOffsets[sourceID]; exists {
+		analysisOffsets[sourceID] += int64(dropped)
+	}
+}
+
+// discardAnalysisCarryover drops the audio kept from the previous read of a
+// source, so that audio from before a pause is not joined to new audio.
+func discardAnalysisCarryover(sourceID string) {
+	abMutex.Lock()
+	defer abMutex.Unlock()
+	if _, exists := prevData[sourceID]; exists {
+		prevData[sourceID] = nil
+	}
+}
+
 // ReadFromAnalysisBuffer reads a sliding chunk of audio data from the ring buffer for a given source ID.
 func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
+	data, _, err := readAnalysisChunk(sourceID)
+	return data, err
+}
+
+// readAnalysisChunk reads a sliding chunk of audio data like ReadFromAnalysisBuffer
+// and also returns the byte offset of the end of the chunk in the source audio.
+func readAnalysisChunk(sourceID string) (chunk []byte, end int64, err error) {
 	start := time.Now()
 
 	// Get source info for enhanced logging (ID + DisplayName) - do this outside mutex
@@ -519,7 +567,7 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 			m.RecordBufferRead("analysis", sourceID, "error")
 			m.RecordBufferReadError("analysis", sourceID, "buffer_not_found")
 		}
-		return nil, enhancedErr
+		return nil, 0, enhancedErr
 	}
 
 	// Calculate the number of bytes written to the buffer
@@ -530,7 +578,7 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 			m.RecordBufferRead("analysis", sourceID, "insufficient_data")
 			m.RecordBufferUnderrun("analysis", sourceID)
 		}
-		return nil, nil
+		return nil, 0, nil
 	}
 
 	// Get a buffer from the pool instead of allocating new
@@ -565,9 +613,11 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 		if readBufferPool != nil {
 			readBufferPool.Put(data)
 		}
-		return nil, enhancedErr
+		return nil, 0, enhancedErr
 	}
 
+	analysisOffsets[sourceID] += int64(bytesRead)
+
 	// Join with previous data to ensure we're processing chunkSize bytes
 	var fullData []byte
 	prevData[sourceID] = append(prevData[sourceID], data...)
@@ -580,6 +630,7 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 	if len(fullData) >= conf.BufferSize {
 		// Update prevData for the next iteration
 		prevData[sourceID] = fullData[readSize:]
+		end = analysisOffsets[sourceID] - int64(len(fullData)-conf.BufferSize)
 		fullData = fullData[:conf.BufferSize]
 
 		// Record successful read metrics
@@ -591,7 +642,7 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 		}
 
 		//log.Printf("✅ Read %d bytes from analysis buffer for source ID %s (%s)", len(fullData), sourceID, displayName)
-		return fullData, nil
+		return fullData, end, nil
 	} else {
 		// If there isn't enough data even after appending, update prevData and return nil
 		prevData[sourceID] = fullData
@@ -599,7 +650,7 @@ func ReadFromAnalysisBuffer(sourceID string) ([]byte, error) {
 		if m := getAnalysisMetrics(); m != nil {
 			m.RecordBufferRead("analysis", sourceID, "insufficient_data")
 		}
-		return nil, nil
+		return nil, 0, nil
 	}
 }
 
@@ -634,7 +685,7 @@ func AnalysisBufferMonitor(_ *sync.WaitGroup, bn *birdnet.BirdNET, quitChan chan
 			return
 
 		case <-ticker.C: // Wait for the next tick
-			data, err := ReadFromAnalysisBuffer(sourceID)
+			data, end, err := readAnalysisChunk(sourceID)
 			if err != nil {
 				log.Error("buffer read error",
 					logger.String("source_id", sourceID),
@@ -658,10 +709,17 @@ func AnalysisBufferMonitor(_ *sync.WaitGroup, bn *birdnet.BirdNET, quitChan chan
 				// This includes the configured pre-capture duration plus an additional detection offset to
 				// account for BirdNET prediction delay
 				beginTimeOffset := time.Duration(conf.Setting().Realtime.Audio.Export.PreCapture)*time.Second + detectionOffset
-				startTime := time.Now().Add(-beginTimeOffset)
 				processingStart := time.Now()
 
-				err := ProcessData(bn, data, startTime, sourceID)
+				// Streams are timestamped from their audio timeline, so audio that
+				// arrived late or in a burst keeps the time it was captured
+				audioTime := processingStart
+				if captured, ok := streamAudioTime(sourceID, end); ok {
+					audioTime = captured
+				}
+				startTime := audioTime.Add(-beginTimeOffset)
+
+				err := ProcessData(bn, data, startTime, audioTime, sourceID)
 
 				if m := getAnalysisMetrics(); m != nil {
 					processingDuration := time.Since(processingStart).Seconds()
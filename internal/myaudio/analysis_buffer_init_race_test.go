@@ -126,8 +126,7 @@ func isAlreadyExistsError(err error) bool {
 
 // resetAnalysisBufferGlobals resets the global variables to simulate fresh start
 // This is necessary to test the initialization race condition
-// NOTE: sync.Once cannot be reset, so this function only clears the buffer maps.
-// The buffer pool initialization will only happen once per process.
+// NOTE: this function only clears the buffer maps, the buffer pool is kept.
 func resetAnalysisBufferGlobals() {
 	abMutex.Lock()
 	defer abMutex.Unlock()
@@ -142,9 +141,8 @@ func resetAnalysisBufferGlobals() {
 		delete(warningCounter, sourceID)
 	}
 
-	// Note: We intentionally do NOT reset readBufferPool, overlapSize, readSize,
-	// or bufferPoolInitOnce because sync.Once cannot be reset.
-	// The fix ensures these are initialized exactly once, thread-safely.
+	// Note: We intentionally do NOT reset readBufferPool, overlapSize or readSize.
+	// The pool is created under abMutex by the first allocation.
 }
 
 // getPoolAddress returns a simple identifier for the pool
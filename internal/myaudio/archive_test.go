@@ -0,0 +1,201 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// memoryArchiveFile collects the PCM audio written to an archive file.
+type memoryArchiveFile struct {
+	bytes.Buffer
+	closed bool
+}
+
+func (f *memoryArchiveFile) Close() error {
+	f.closed = true
+	return nil
+}
+
+// newTestArchiveRecorder returns a recorder writing to in-memory files keyed by path.
+func newTestArchiveRecorder(t *testing.T) (*archiveRecorder, map[string]*memoryArchiveFile) {
+	t.Helper()
+	settings := &conf.AudioSettings{Archive: conf.ArchiveSettings{Path: t.TempDir(), Type: FormatFLAC}}
+	recorder := newArchiveRecorder("rtsp_test", settings)
+	files := make(map[string]*memoryArchiveFile)
+	recorder.openFile = func(path string) (io.WriteCloser, error) {
+		f := &memoryArchiveFile{}
+		files[path] = f
+		return f, nil
+	}
+	return recorder, files
+}
+
+// pcmChunk returns a chunk of d of PCM audio received at received.
+func pcmChunk(d time.Duration, received time.Time) archiveChunk {
+	return archiveChunk{data: make([]byte, pcmBytes(d)), received: received}
+}
+
+func TestArchiveFilePath(t *testing.T) {
+	start := time.Date(2026, 10, 18, 16, 5, 7, 250e6, time.FixedZone("EEST", 3*60*60))
+	path := ArchiveFilePath("/data/archive", "rtsp://cam/../x", start, ".flac")
+	assert.Equal(t, filepath.Join("/data/archive", "rtsp___cam____x", "2026-10-18", "20261018T130507.250Z.flac"), path)
+	assert.Equal(t, ".opus", ArchiveFileExtension(FormatOpus))
+	assert.Equal(t, ".flac", ArchiveFileExtension(FormatFLAC))
+}
+
+func TestArchiveRecordsSource(t *testing.T) {
+	resolve := func(entry string) (string, bool) {
+		ids := map[string]string{"Garden": "rtsp_1", "rtsp://cam/stream": "rtsp_2"}
+		id, ok := ids[entry]
+		return id, ok
+	}
+
+	assert.True(t, archiveRecordsSource(nil, "rtsp_1", resolve), "empty list records every source")
+	assert.True(t, archiveRecordsSource([]string{" Garden "}, "rtsp_1", resolve))
+	assert.True(t, archiveRecordsSource([]string{"Garden", "rtsp://cam/stream"}, "rtsp_2", resolve))
+	assert.False(t, archiveRecordsSource([]string{"Garden"}, "rtsp_2", resolve))
+	assert.False(t, archiveRecordsSource([]string{"Unknown"}, "rtsp_1", resolve))
+}
+
+func TestArchiveRecorder_RotatesOnTheHour(t *testing.T) {
+	recorder, files := newTestArchiveRecorder(t)
+	hour := time.Date(2026, 10, 18, 14, 0, 0, 0, time.UTC)
+
+	// One second chunks from 13:59:58.5 to 14:00:01.5
+	for i := 1; i <= 3; i++ {
+		recorder.write(pcmChunk(time.Second, hour.Add(-1500*time.Millisecond+time.Duration(i)*time.Second)))
+	}
+	recorder.closeFile()
+
+	require.Len(t, files, 2)
+	first := files[ArchiveFilePath(recorder.baseDir, "rtsp_test", hour.Add(-1500*time.Millisecond), ".flac")]
+	second := files[ArchiveFilePath(recorder.baseDir, "rtsp_test", hour, ".flac")]
+	require.NotNil(t, first)
+	require.NotNil(t, second)
+	assert.Equal(t, pcmBytes(1500*time.Millisecond), first.Len())
+	assert.Equal(t, pcmBytes(1500*time.Millisecond), second.Len())
+	assert.True(t, first.closed)
+	assert.True(t, second.closed)
+}
+
+func TestArchiveRecorder_StartsNewFileAfterGap(t *testing.T) {
+	recorder, files := newTestArchiveRecorder(t)
+	start := time.Date(2026, 10, 18, 14, 10, 0, 0, time.UTC)
+
+	recorder.write(pcmChunk(time.Second, start.Add(time.Second)))
+	// Small jitter continues the file
+	recorder.write(pcmChunk(time.Second, start.Add(2*time.Second+100*time.Millisecond)))
+	// Five seconds of missing audio starts a new file
+	recorder.write(pcmChunk(time.Second, start.Add(8*time.Second)))
+	recorder.closeFile()
+
+	require.Len(t, files, 2)
+	assert.Equal(t, pcmBytes(2*time.Second), files[ArchiveFilePath(recorder.baseDir, "rtsp_test", start, ".flac")].Len())
+	assert.Equal(t, pcmBytes(time.Second), files[ArchiveFilePath(recorder.baseDir, "rtsp_test", start.Add(7*time.Second), ".flac")].Len())
+}
+
+func TestArchiveRecorder_RunFlushesOnStop(t *testing.T) {
+	recorder, files := newTestArchiveRecorder(t)
+	done := make(chan struct{})
+	go func() {
+		recorder.run()
+		close(done)
+	}()
+
+	recorder.enqueue(make([]byte, pcmBytes(100*time.Millisecond)), time.Now())
+	close(recorder.stop)
+	<-done
+
+	require.Len(t, files, 1)
+	for _, f := range files {
+		assert.Equal(t, pcmBytes(100*time.Millisecond), f.Len())
+		assert.True(t, f.closed)
+	}
+}
+
+// createArchiveSegment creates an archive file of source starting at start.
+func createArchiveSegment(t *testing.T, baseDir, source string, start time.Time) {
+	t.Helper()
+	path := ArchiveFilePath(baseDir, source, start, ".opus")
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("OggS"), 0o600))
+}
+
+func TestArchive_SegmentsAndLocate(t *testing.T) {
+	baseDir := t.TempDir()
+	hour := time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+	createArchiveSegment(t, baseDir, "rtsp_1", hour.Add(-time.Hour))
+	createArchiveSegment(t, baseDir, "rtsp_1", hour)
+	createArchiveSegment(t, baseDir, "rtsp_1", hour.Add(20*time.Minute)) // after a restart
+	createArchiveSegment(t, baseDir, "rtsp_1", hour.Add(time.Hour))      // next UTC day
+	createArchiveSegment(t, baseDir, "malgo_2", hour.Add(10*time.Minute))
+
+	archive := NewArchive(&conf.AudioSettings{Archive: conf.ArchiveSettings{Path: baseDir}})
+	archive.now = func() time.Time { return hour.Add(time.Hour + 30*time.Minute) }
+
+	segments, err := archive.Segments("rtsp_1", hour.Add(-10*time.Minute), hour.Add(90*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, segments, 4)
+	assert.Equal(t, hour.Add(-time.Hour), segments[0].Start)
+	assert.Equal(t, hour, segments[0].End)
+	assert.Equal(t, hour.Add(20*time.Minute), segments[1].End, "a file ends where the next one starts")
+	assert.Equal(t, hour.Add(time.Hour), segments[2].End)
+	assert.Equal(t, hour.Add(90*time.Minute), segments[3].End, "the current file ends now")
+	assert.Equal(t, "rtsp_1/2026-10-19/20261019T000000.000Z.opus", segments[3].File)
+
+	locations, err := archive.Locate("", hour.Add(15*time.Minute))
+	require.NoError(t, err)
+	require.Len(t, locations, 2)
+	for _, loc := range locations {
+		switch loc.Segment.SourceID {
+		case "rtsp_1":
+			assert.InDelta(t, 15*60, loc.Offset, 0.001)
+		case "malgo_2":
+			assert.InDelta(t, 5*60, loc.Offset, 0.001)
+		default:
+			t.Errorf("unexpected source %q", loc.Segment.SourceID)
+		}
+	}
+
+	sources, err := archive.Sources()
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"rtsp_1", "malgo_2"}, sources)
+}
+
+func TestArchive_ReadRange(t *testing.T) {
+	baseDir := t.TempDir()
+	hour := time.Date(2026, 10, 18, 14, 0, 0, 0, time.UTC)
+	createArchiveSegment(t, baseDir, "rtsp_1", hour)
+	createArchiveSegment(t, baseDir, "rtsp_1", hour.Add(10*time.Second)) // restarted stream
+
+	archive := NewArchive(&conf.AudioSettings{Archive: conf.ArchiveSettings{Path: baseDir}})
+	archive.now = func() time.Time { return hour.Add(time.Minute) }
+
+	var calls []time.Duration
+	archive.decode = func(_ context.Context, _ string, offset, length time.Duration) ([]byte, error) {
+		calls = append(calls, offset, length)
+		return bytes.Repeat([]byte{1}, pcmBytes(length)), nil
+	}
+
+	pcm, err := archive.ReadRange(t.Context(), "rtsp_1", hour.Add(8*time.Second), hour.Add(12*time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{8 * time.Second, 2 * time.Second, 0, 2 * time.Second}, calls)
+	require.Len(t, pcm, pcmBytes(4*time.Second))
+	assert.Equal(t, byte(1), pcm[0])
+	assert.Equal(t, byte(1), pcm[len(pcm)-1])
+
+	_, err = archive.ReadRange(t.Context(), "rtsp_1", hour.Add(2*time.Hour), hour.Add(2*time.Hour+time.Second))
+	require.ErrorIs(t, err, ErrArchiveNotFound)
+
+	_, err = archive.ReadRange(t.Context(), "rtsp_1", hour, hour.Add(MaxArchiveRange+time.Second))
+	require.Error(t, err)
+}
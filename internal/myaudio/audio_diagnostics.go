@@ -0,0 +1,696 @@
+// audio_diagnostics.go: per-source audio quality diagnostics
+package myaudio
+
+import (
+	"cmp"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/cmplx"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/fft"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+// Audio quality issues
+const (
+	AudioIssueClipping           = "clipping"
+	AudioIssueDCOffset           = "dc_offset"
+	AudioIssueDeadChannel        = "dead_channel"
+	AudioIssueMainsHum           = "mains_hum"
+	AudioIssueSampleRateMismatch = "sample_rate_mismatch"
+	AudioIssueSpectralTilt       = "spectral_tilt"
+)
+
+// Diagnostics parameters
+const (
+	// audioDiagnosticsMinInterval bounds the analysis interval from below,
+	// shorter intervals make the spectral checks unreliable
+	audioDiagnosticsMinInterval = 10 * time.Second
+	// diagFFTSize gives 2.9 Hz bins at 48 kHz, fine enough to tell 50 Hz
+	// from 60 Hz hum
+	diagFFTSize = 16384
+	// diagConfirmIntervals is how many consecutive intervals an issue must be
+	// found in before it is raised
+	diagConfirmIntervals = 2
+
+	// Clipping: share of samples at full scale
+	diagClipSample    = math.MaxInt16
+	diagClipThreshold = 0.001
+	// DC offset: mean sample value, full scale is 1
+	diagDCOffsetThreshold = 0.01
+	// Dead channel: peak-to-peak range in LSB at or below which a channel
+	// carries no signal
+	diagDeadChannelRange = 2
+	// Mains hum: harmonics of 50 or 60 Hz standing out from the neighbouring
+	// spectrum by diagHumProminence dB, at least diagHumMinHarmonics of them
+	diagHumHarmonics    = 5
+	diagHumMinHarmonics = 2
+	diagHumProminence   = 10.0
+	// Sample rate: delivered samples per second off by more than the
+	// tolerance, or a spectrum that ends at the Nyquist frequency of a lower rate
+	diagSampleRateTolerance = 0.02
+	diagPauseThreshold      = time.Second
+	diagBandwidthDrop       = 30.0
+	// Spectral tilt: slope of the mean spectrum over 250 Hz - 8 kHz in dB per
+	// octave. Outdoor ambience slopes down by 3 to 12 dB per octave.
+	diagTiltMin      = -18.0
+	diagTiltMax      = 3.0
+	diagTiltMinLevel = -80.0 // dBFS RMS below which the noise floor dominates
+)
+
+// diagBandwidthCutoffs are the Nyquist frequencies of common lower sample rates
+var diagBandwidthCutoffs = []float64{8000, 11025, 12000, 16000}
+
+// diagTiltBands are the octave band centers the spectral tilt is fitted over
+var diagTiltBands = []float64{250, 500, 1000, 2000, 4000, 8000}
+
+// AudioIssue is an audio quality problem found in a source.
+type AudioIssue struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Value   float64 `json:"value"` // measurement that raised the issue
+}
+
+// AudioDiagnostics holds the audio quality measurements of one source over
+// one interval and the issues raised for it.
+type AudioDiagnostics struct {
+	Timestamp          time.Time    `json:"timestamp"`
+	Duration           int          `json:"duration_seconds"`
+	RMSDBFS            float64      `json:"rms_dbfs"`
+	PeakDBFS           float64      `json:"peak_dbfs"`
+	ClippingPercent    float64      `json:"clipping_percent"`
+	DCOffset           float64      `json:"dc_offset"`                         // mean sample value, full scale is 1
+	HumFrequency       float64      `json:"hum_frequency_hz,omitempty"`        // 50 or 60 when hum is present
+	HumProminenceDB    float64      `json:"hum_prominence_db,omitempty"`       // strongest hum harmonic above its neighbourhood
+	SpectralTilt       float64      `json:"spectral_tilt_db_per_octave"`       // slope of the mean spectrum
+	BandwidthHz        float64      `json:"bandwidth_hz,omitempty"`            // where the spectrum ends, set when below Nyquist
+	MeasuredSampleRate float64      `json:"measured_sample_rate_hz,omitempty"` // samples delivered per second of wall clock
+	ChannelRMSDBFS     []float64    `json:"channel_rms_dbfs,omitempty"`        // levels of the channels of a downmixed device
+	Issues             []AudioIssue `json:"issues"`
+}
+
+// diagChannel accumulates the level of one channel.
+type diagChannel struct {
+	sumSquares float64
+	samples    int
+	low, high  int16
+}
+
+// diagIssueState tracks how long an issue has been found.
+type diagIssueState struct {
+	streak int
+	raised bool
+}
+
+// audioDiagnosticsAnalyzer checks the audio of one source. Statistics and the
+// mean spectrum are accumulated until an interval of wall clock time passed.
+type audioDiagnosticsAnalyzer struct {
+	mu         sync.Mutex
+	sourceID   string
+	sampleRate int
+	interval   time.Duration
+	binWidth   float64
+	window     []float64
+
+	pending []float64
+	buf     []complex128
+
+	// Current interval
+	start      time.Time
+	lastChunk  time.Time
+	paused     bool  // a pause makes the delivered sample rate meaningless
+	rateCount  int64 // samples delivered after the first chunk
+	samples    int64
+	sum        float64
+	sumSquares float64
+	peak       int
+	clipped    int64
+	low, high  int16
+	frames     int
+	sumPower   []float64
+	channels   []diagChannel
+
+	issues map[string]*diagIssueState
+	latest *AudioDiagnostics
+}
+
+func newAudioDiagnosticsAnalyzer(sourceID string, sampleRate int, interval time.Duration) *audioDiagnosticsAnalyzer {
+	window := make([]float64, diagFFTSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(diagFFTSize))
+	}
+	a := &audioDiagnosticsAnalyzer{
+		sourceID:   sourceID,
+		sampleRate: sampleRate,
+		interval:   max(interval, audioDiagnosticsMinInterval),
+		binWidth:   float64(sampleRate) / diagFFTSize,
+		window:     window,
+		pending:    make([]float64, 0, diagFFTSize),
+		buf:        make([]complex128, diagFFTSize),
+		sumPower:   make([]float64, diagFFTSize/2),
+		issues:     make(map[string]*diagIssueState),
+	}
+	a.reset(time.Time{})
+	return a
+}
+
+// reset starts a new interval at now.
+func (a *audioDiagnosticsAnalyzer) reset(now time.Time) {
+	a.start = now
+	a.lastChunk = now
+	a.paused = false
+	a.rateCount = 0
+	a.samples = 0
+	a.sum = 0
+	a.sumSquares = 0
+	a.peak = 0
+	a.clipped = 0
+	a.low, a.high = math.MaxInt16, math.MinInt16
+	a.frames = 0
+	clear(a.sumPower)
+	for i := range a.channels {
+		a.channels[i] = diagChannel{low: math.MaxInt16, high: math.MinInt16}
+	}
+}
+
+// add processes a chunk of 16-bit little-endian mono samples received at now.
+// Returns the diagnostics of the interval the chunk completed, and the issues
+// raised and cleared by it.
+func (a *audioDiagnosticsAnalyzer) add(data []byte, now time.Time) (result *AudioDiagnostics, raised, cleared []AudioIssue) {
+	n := len(data) / 2
+	if n == 0 {
+		return nil, nil, nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.start.IsZero() {
+		a.reset(now)
+	} else {
+		if now.Sub(a.lastChunk) > diagPauseThreshold {
+			a.paused = true
+		}
+		a.rateCount += int64(n)
+	}
+	a.lastChunk = now
+
+	for i := range n {
+		s := int16(binary.LittleEndian.Uint16(data[i*2:])) //nolint:gosec // G115: audio sample conversion within 16-bit range
+		a.addSample(s)
+	}
+
+	if now.Sub(a.start) < a.interval {
+		return nil, nil, nil
+	}
+	result = a.result(now)
+	raised, cleared = a.updateIssues(result)
+	a.latest = result
+	a.reset(now)
+	return result, raised, cleared
+}
+
+// addSample accumulates one sample.
+func (a *audioDiagnosticsAnalyzer) addSample(s int16) {
+	a.samples++
+	v := float64(s) / 32768.0
+	a.sum += v
+	a.sumSquares += v * v
+	abs := int(s)
+	if abs < 0 {
+		abs = -abs
+	}
+	a.peak = max(a.peak, abs)
+	if abs >= diagClipSample {
+		a.clipped++
+	}
+	a.low = min(a.low, s)
+	a.high = max(a.high, s)
+
+	a.pending = append(a.pending, v)
+	if len(a.pending) == diagFFTSize {
+		a.processFrame()
+		a.pending = a.pending[:0]
+	}
+}
+
+// processFrame adds the power spectrum of the pending frame to the mean spectrum.
+func (a *audioDiagnosticsAnalyzer) processFrame() {
+	for i, s := range a.pending {
+		a.buf[i] = complex(s*a.window[i], 0)
+	}
+	fft.Transform(a.buf)
+	for k := range a.sumPower {
+		m := cmplx.Abs(a.buf[k])
+		a.sumPower[k] += m * m
+	}
+	a.frames++
+}
+
+// addChannels accumulates the levels of the channels of interleaved 16-bit
+// samples, for devices downmixed to a single source.
+func (a *audioDiagnosticsAnalyzer) addChannels(data []byte, channels int) {
+	if channels < 2 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if len(a.channels) != channels {
+		a.channels = make([]diagChannel, channels)
+		for i := range a.channels {
+			a.channels[i] = diagChannel{low: math.MaxInt16, high: math.MinInt16}
+		}
+	}
+	frames := len(data) / (2 * channels)
+	for f := range frames {
+		for c := range channels {
+			offset := (f*channels + c) * 2
+			s := int16(binary.LittleEndian.Uint16(data[offset:])) //nolint:gosec // G115: audio sample conversion within 16-bit range
+			ch := &a.channels[c]
+			v := float64(s) / 32768.0
+			ch.sumSquares += v * v
+			ch.samples++
+			ch.low = min(ch.low, s)
+			ch.high = max(ch.high, s)
+		}
+	}
+}
+
+// result computes the diagnostics of the interval ending at now. Issues holds
+// every issue found in the interval; updateIssues keeps the raised ones.
+func (a *audioDiagnosticsAnalyzer) result(now time.Time) *AudioDiagnostics {
+	d := &AudioDiagnostics{
+		Timestamp: now,
+		Duration:  int(math.Round(now.Sub(a.start).Seconds())),
+		RMSDBFS:   toDBFS(math.Sqrt(a.sumSquares / float64(max(a.samples, 1)))),
+		PeakDBFS:  toDBFS(float64(a.peak) / 32768.0),
+	}
+	if a.samples > 0 {
+		d.ClippingPercent = float64(a.clipped) / float64(a.samples) * 100
+		d.DCOffset = a.sum / float64(a.samples)
+	}
+
+	if d.ClippingPercent > diagClipThreshold*100 {
+		d.Issues = append(d.Issues, AudioIssue{
+			Code:    AudioIssueClipping,
+			Message: fmt.Sprintf("%.2f%% of samples are clipped, reduce the input gain", d.ClippingPercent),
+			Value:   d.ClippingPercent,
+		})
+	}
+	if math.Abs(d.DCOffset) > diagDCOffsetThreshold {
+		d.Issues = append(d.Issues, AudioIssue{
+			Code:    AudioIssueDCOffset,
+			Message: fmt.Sprintf("DC offset of %.1f%% of full scale", d.DCOffset*100),
+			Value:   d.DCOffset,
+		})
+	}
+	a.checkDeadChannels(d)
+
+	if a.frames > 0 {
+		a.checkHum(d)
+		a.checkBandwidth(d)
+		a.checkTilt(d)
+	}
+
+	if !a.paused {
+		if elapsed := a.lastChunk.Sub(a.start); elapsed >= a.interval/2 {
+			d.MeasuredSampleRate = float64(a.rateCount) / elapsed.Seconds()
+			if deviation := d.MeasuredSampleRate/float64(a.sampleRate) - 1; math.Abs(deviation) > diagSampleRateTolerance {
+				d.Issues = append(d.Issues, AudioIssue{
+					Code: AudioIssueSampleRateMismatch,
+					Message: fmt.Sprintf("source delivers %.0f samples per second, %d expected",
+						d.MeasuredSampleRate, a.sampleRate),
+					Value: d.MeasuredSampleRate,
+				})
+			}
+		}
+	}
+	return d
+}
+
+// checkDeadChannels finds channels without signal. A downmixed device is
+// checked per channel, other sources as a whole.
+func (a *audioDiagnosticsAnalyzer) checkDeadChannels(d *AudioDiagnostics) {
+	if len(a.channels) == 0 || a.channels[0].samples == 0 {
+		if a.samples > 0 && int(a.high)-int(a.low) <= diagDeadChannelRange {
+			d.Issues = append(d.Issues, AudioIssue{
+				Code:    AudioIssueDeadChannel,
+				Message: "no signal, the input is digital silence",
+				Value:   d.RMSDBFS,
+			})
+		}
+		return
+	}
+	for i := range a.channels {
+		ch := &a.channels[i]
+		level := toDBFS(math.Sqrt(ch.sumSquares / float64(max(ch.samples, 1))))
+		d.ChannelRMSDBFS = append(d.ChannelRMSDBFS, level)
+		if ch.samples > 0 && int(ch.high)-int(ch.low) <= diagDeadChannelRange {
+			d.Issues = append(d.Issues, AudioIssue{
+				Code:    AudioIssueDeadChannel,
+				Message: fmt.Sprintf("channel %d has no signal", i+1),
+				Value:   float64(i + 1),
+			})
+		}
+	}
+}
+
+// checkHum looks for peaks at the harmonics of 50 and 60 Hz.
+func (a *audioDiagnosticsAnalyzer) checkHum(d *AudioDiagnostics) {
+	var bestFreq, bestScore, bestPeak float64
+	for _, f0 := range []float64{50, 60} {
+		found := 0
+		var score, peak float64
+		for h := 1; h <= diagHumHarmonics; h++ {
+			prominence := a.peakProminence(f0 * float64(h))
+			if prominence >= diagHumProminence {
+				found++
+				score += prominence
+				peak = max(peak, prominence)
+			}
+		}
+		if found >= diagHumMinHarmonics && score > bestScore {
+			bestFreq, bestScore, bestPeak = f0, score, peak
+		}
+	}
+	if bestFreq == 0 {
+		return
+	}
+	d.HumFrequency = bestFreq
+	d.HumProminenceDB = bestPeak
+	d.Issues = append(d.Issues, AudioIssue{
+		Code:    AudioIssueMainsHum,
+		Message: fmt.Sprintf("%.0f Hz mains hum, %.0f dB above the background", bestFreq, bestPeak),
+		Value:   bestFreq,
+	})
+}
+
+// peakProminence returns how far the spectrum at freq stands out from the
+// median of its neighbourhood, in dB.
+func (a *audioDiagnosticsAnalyzer) peakProminence(freq float64) float64 {
+	center := int(math.Round(freq / a.binWidth))
+	if center+16 >= len(a.sumPower) {
+		return 0
+	}
+	var peak float64
+	for k := max(center-2, 1); k <= center+2; k++ {
+		peak = max(peak, a.sumPower[k])
+	}
+	neighbours := make([]float64, 0, 22)
+	for offset := 6; offset <= 16; offset++ {
+		if center-offset >= 1 {
+			neighbours = append(neighbours, a.sumPower[center-offset])
+		}
+		neighbours = append(neighbours, a.sumPower[center+offset])
+	}
+	slices.Sort(neighbours)
+	background := neighbours[len(neighbours)/2]
+	if peak == 0 {
+		return 0
+	}
+	return 10 * math.Log10(peak/(background+1e-30))
+}
+
+// meanPowerDB returns the mean power of the bins in [low, high) in dB.
+func (a *audioDiagnosticsAnalyzer) meanPowerDB(low, high float64) (float64, bool) {
+	var sum float64
+	var bins int
+	for k := int(math.Ceil(low / a.binWidth)); k < len(a.sumPower) && float64(k)*a.binWidth < high; k++ {
+		sum += a.sumPower[k]
+		bins++
+	}
+	if bins == 0 {
+		return 0, false
+	}
+	return 10 * math.Log10(sum/float64(bins)+1e-30), true
+}
+
+// checkBandwidth looks for a spectrum that ends at the Nyquist frequency of a
+// lower sample rate, a sign of audio captured at that rate and upsampled.
+func (a *audioDiagnosticsAnalyzer) checkBandwidth(d *AudioDiagnostics) {
+	nyquist := float64(a.sampleRate) / 2
+	for _, cutoff := range diagBandwidthCutoffs {
+		if cutoff >= 0.9*nyquist {
+			break
+		}
+		below, ok1 := a.meanPowerDB(0.7*cutoff, 0.95*cutoff)
+		above, ok2 := a.meanPowerDB(1.05*cutoff, math.Min(1.4*cutoff, 0.95*nyquist))
+		if !ok1 || !ok2 || below-above < diagBandwidthDrop {
+			continue
+		}
+		d.BandwidthHz = cutoff
+		d.Issues = append(d.Issues, AudioIssue{
+			Code: AudioIssueSampleRateMismatch,
+			Message: fmt.Sprintf("audio ends at %.0f Hz, the source is likely sampled at %.0f Hz instead of %d Hz",
+				cutoff, 2*cutoff, a.sampleRate),
+			Value: cutoff,
+		})
+		return
+	}
+}
+
+// checkTilt fits a line to the octave band levels of the mean spectrum.
+func (a *audioDiagnosticsAnalyzer) checkTilt(d *AudioDiagnostics) {
+	limit := float64(a.sampleRate) / 2
+	if d.BandwidthHz > 0 {
+		limit = d.BandwidthHz
+	}
+	var xs, ys []float64
+	for _, center := range diagTiltBands {
+		high := center * math.Sqrt2
+		if high > limit {
+			break
+		}
+		level, ok := a.meanPowerDB(center/math.Sqrt2, high)
+		if !ok {
+			continue
+		}
+		xs = append(xs, math.Log2(center))
+		ys = append(ys, level)
+	}
+	if len(xs) < 3 {
+		return
+	}
+	d.SpectralTilt = linearSlope(xs, ys)
+
+	if d.RMSDBFS < diagTiltMinLevel {
+		return
+	}
+	switch {
+	case d.SpectralTilt < diagTiltMin:
+		d.Issues = append(d.Issues, AudioIssue{
+			Code:    AudioIssueSpectralTilt,
+			Message: fmt.Sprintf("spectrum falls %.1f dB per octave, high frequencies are missing, the microphone may be damaged or covered", -d.SpectralTilt),
+			Value:   d.SpectralTilt,
+		})
+	case d.SpectralTilt > diagTiltMax:
+		d.Issues = append(d.Issues, AudioIssue{
+			Code:    AudioIssueSpectralTilt,
+			Message: fmt.Sprintf("spectrum rises %.1f dB per octave, low frequencies are missing or only electronic noise is captured", d.SpectralTilt),
+			Value:   d.SpectralTilt,
+		})
+	}
+}
+
+// linearSlope returns the least squares slope of ys over xs.
+func linearSlope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sx, sy, sxx, sxy float64
+	for i := range xs {
+		sx += xs[i]
+		sy += ys[i]
+		sxx += xs[i] * xs[i]
+		sxy += xs[i] * ys[i]
+	}
+	denominator := n*sxx - sx*sx
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sxy - sx*sy) / denominator
+}
+
+// toDBFS converts a full scale amplitude to dBFS, floored at -120.
+func toDBFS(amplitude float64) float64 {
+	if amplitude <= 1e-6 {
+		return -120
+	}
+	return 20 * math.Log10(amplitude)
+}
+
+// updateIssues raises issues found in diagConfirmIntervals consecutive
+// intervals and clears raised issues no longer found. d.Issues is replaced by
+// the raised issues.
+func (a *audioDiagnosticsAnalyzer) updateIssues(d *AudioDiagnostics) (raised, cleared []AudioIssue) {
+	found := make(map[string]AudioIssue, len(d.Issues))
+	for _, issue := range d.Issues {
+		if _, exists := found[issue.Code]; !exists {
+			found[issue.Code] = issue
+		}
+	}
+
+	active := make([]AudioIssue, 0, len(found))
+	for code, issue := range found {
+		state, exists := a.issues[code]
+		if !exists {
+			state = &diagIssueState{}
+			a.issues[code] = state
+		}
+		state.streak++
+		if !state.raised && state.streak >= diagConfirmIntervals {
+			state.raised = true
+			raised = append(raised, issue)
+		}
+		if state.raised {
+			active = append(active, issue)
+		}
+	}
+	for code, state := range a.issues {
+		if _, exists := found[code]; exists {
+			continue
+		}
+		if state.raised {
+			cleared = append(cleared, AudioIssue{Code: code, Message: code + " is no longer detected"})
+		}
+		delete(a.issues, code)
+	}
+
+	byCode := func(x, y AudioIssue) int { return cmp.Compare(x.Code, y.Code) }
+	slices.SortFunc(active, byCode)
+	slices.SortFunc(raised, byCode)
+	slices.SortFunc(cleared, byCode)
+	d.Issues = active
+	return raised, cleared
+}
+
+// snapshot returns a copy of the diagnostics of the last completed interval.
+func (a *audioDiagnosticsAnalyzer) snapshot() *AudioDiagnostics {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.latest == nil {
+		return nil
+	}
+	d := *a.latest
+	d.ChannelRMSDBFS = slices.Clone(a.latest.ChannelRMSDBFS)
+	d.Issues = slices.Clone(a.latest.Issues)
+	return &d
+}
+
+var (
+	audioDiagnostics   = make(map[string]*audioDiagnosticsAnalyzer)
+	audioDiagnosticsMu sync.Mutex
+)
+
+// getAudioDiagnosticsAnalyzer returns the analyzer of a source, creating it
+// if diagnostics are enabled. Returns nil when disabled.
+func getAudioDiagnosticsAnalyzer(sourceID string) *audioDiagnosticsAnalyzer {
+	settings := conf.Setting().Realtime.Audio.Diagnostics
+	if !settings.Enabled {
+		return nil
+	}
+	audioDiagnosticsMu.Lock()
+	defer audioDiagnosticsMu.Unlock()
+	analyzer, exists := audioDiagnostics[sourceID]
+	if !exists {
+		analyzer = newAudioDiagnosticsAnalyzer(sourceID, conf.SampleRate, time.Duration(settings.Interval)*time.Second)
+		audioDiagnostics[sourceID] = analyzer
+	}
+	return analyzer
+}
+
+// observeAudioDiagnostics adds 16-bit mono audio of a source to its
+// diagnostics and publishes issues raised or cleared by it.
+func observeAudioDiagnostics(sourceID string, data []byte) {
+	analyzer := getAudioDiagnosticsAnalyzer(sourceID)
+	if analyzer == nil {
+		return
+	}
+	_, raised, cleared := analyzer.add(data, time.Now())
+	if len(raised) > 0 || len(cleared) > 0 {
+		publishAudioIssues(sourceID, raised, cleared)
+	}
+}
+
+// observeChannelDiagnostics adds the channel levels of interleaved audio of
+// a device that is downmixed to sourceID.
+func observeChannelDiagnostics(sourceID string, data []byte, channels int) {
+	if analyzer := getAudioDiagnosticsAnalyzer(sourceID); analyzer != nil {
+		analyzer.addChannels(data, channels)
+	}
+}
+
+// resetAudioDiagnostics discards the diagnostics of a source.
+func resetAudioDiagnostics(sourceID string) {
+	audioDiagnosticsMu.Lock()
+	defer audioDiagnosticsMu.Unlock()
+	delete(audioDiagnostics, sourceID)
+}
+
+// GetAudioDiagnostics returns the diagnostics of the last completed interval
+// of a source, nil before the first interval completed or when disabled.
+func GetAudioDiagnostics(sourceID string) *AudioDiagnostics {
+	audioDiagnosticsMu.Lock()
+	analyzer := audioDiagnostics[sourceID]
+	audioDiagnosticsMu.Unlock()
+	if analyzer == nil {
+		return nil
+	}
+	return analyzer.snapshot()
+}
+
+// publishAudioIssues logs raised and cleared issues and publishes them on the
+// event bus, where the alerting bridge turns raised issues into stream or
+// device audio issue alerts.
+func publishAudioIssues(sourceID string, raised, cleared []AudioIssue) {
+	base := events.AudioQualityEventData{
+		SourceID:   sourceID,
+		SourceName: sourceID,
+		SourceKind: events.AudioSourceStream,
+	}
+	if registry := GetRegistry(); registry != nil {
+		if source, ok := registry.GetSourceByID(sourceID); ok {
+			base.SourceName = source.DisplayName
+			if source.Type == SourceTypeAudioCard {
+				base.SourceKind = events.AudioSourceDevice
+			} else {
+				base.SourceURL = privacy.SanitizeStreamUrl(source.SafeString)
+			}
+		}
+	}
+
+	log := GetLogger()
+	eventBus := events.GetEventBus()
+	publish := func(issue AudioIssue, severity string) {
+		data := base
+		data.Issue = issue.Code
+		data.Value = issue.Value
+		data.Severity = severity
+		data.Message = issue.Message
+		if eventBus != nil {
+			eventBus.TryPublishAudioQuality(events.NewAudioQualityEvent(data))
+		}
+	}
+	for _, issue := range raised {
+		log.Warn("audio quality issue detected",
+			logger.String("source_id", sourceID),
+			logger.String("source_name", base.SourceName),
+			logger.String("issue", issue.Code),
+			logger.String("message", issue.Message),
+			logger.String("operation", "audio_diagnostics"))
+		publish(issue, events.SeverityWarning)
+	}
+	for _, issue := range cleared {
+		log.Info("audio quality issue cleared",
+			logger.String("source_id", sourceID),
+			logger.String("source_name", base.SourceName),
+			logger.String("issue", issue.Code),
+			logger.String("operation", "audio_diagnostics"))
+		publish(issue, events.SeverityRecovery)
+	}
+}
@@ -0,0 +1,286 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testDiagRate     = 48000
+	testDiagInterval = 10 * time.Second
+)
+
+// diagSignal returns the sample at index i, full scale is 1.
+type diagSignal func(i int) float64
+
+// noiseSignal returns white noise with the given RMS level.
+func noiseSignal(rms float64) diagSignal {
+	rng := rand.New(rand.NewPCG(1, 2)) //nolint:gosec // G404: deterministic test signal
+	return func(int) float64 { return rng.NormFloat64() * rms }
+}
+
+// mixSignals returns the sum of signals.
+func mixSignals(signals ...diagSignal) diagSignal {
+	return func(i int) float64 {
+		var sum float64
+		for _, signal := range signals {
+			sum += signal(i)
+		}
+		return sum
+	}
+}
+
+// encodeS16 converts samples to 16-bit little-endian PCM, clipping at full scale.
+func encodeS16(samples []float64) []byte {
+	data := make([]byte, len(samples)*2)
+	for i, s := range samples {
+		v := math.Round(s * 32768)
+		v = math.Max(math.Min(v, math.MaxInt16), math.MinInt16)
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(v))) //nolint:gosec // G115: clamped to 16-bit range
+	}
+	return data
+}
+
+// feedDiagnostics delivers intervals of signal in 100 ms chunks of rate
+// samples per second and returns the issues raised and cleared, and the
+// diagnostics of the last interval.
+func feedDiagnostics(a *audioDiagnosticsAnalyzer, start time.Time, intervals, rate int, signal diagSignal) (raisedCodes, clearedCodes []string, last *AudioDiagnostics, end time.Time) {
+	chunk := rate / 10
+	chunks := intervals * int(testDiagInterval/(100*time.Millisecond))
+	now := start
+	samples := make([]float64, chunk)
+	index := 0
+	for range chunks + 1 {
+		for i := range samples {
+			samples[i] = signal(index)
+			index++
+		}
+		result, raised, cleared := a.add(encodeS16(samples), now)
+		for _, issue := range raised {
+			raisedCodes = append(raisedCodes, issue.Code)
+		}
+		for _, issue := range cleared {
+			clearedCodes = append(clearedCodes, issue.Code)
+		}
+		if result != nil {
+			last = result
+		}
+		now = now.Add(100 * time.Millisecond)
+	}
+	return raisedCodes, clearedCodes, last, now
+}
+
+func TestAudioDiagnostics_CleanSignal(t *testing.T) {
+	t.Parallel()
+	a := newAudioDiagnosticsAnalyzer("test", testDiagRate, testDiagInterval)
+	raised, _, last, _ := feedDiagnostics(a, time.Now(), 3, testDiagRate, noiseSignal(0.03))
+
+	assert.Empty(t, raised)
+	require.NotNil(t, last)
+	assert.Empty(t, last.Issues)
+	assert.InDelta(t, -30.5, last.RMSDBFS, 1)
+	assert.InDelta(t, testDiagRate, last.MeasuredSampleRate, testDiagRate*0.005)
+	assert.InDelta(t, 0, last.SpectralTilt, 1, "white noise is flat")
+	assert.Zero(t, last.HumFrequency)
+	assert.Zero(t, last.BandwidthHz)
+}
+
+func TestAudioDiagnostics_Issues(t *testing.T) {
+	t.Parallel()
+	sine := func(freq, amplitude float64) diagSignal {
+		return func(i int) float64 { return amplitude * math.Sin(2*math.Pi*freq*float64(i)/testDiagRate) }
+	}
+
+	tests := []struct {
+		name   string
+		rate   int
+		signal diagSignal
+		issue  string
+		check  func(t *testing.T, d *AudioDiagnostics)
+	}{
+		{
+			name:   "clipping",
+			rate:   testDiagRate,
+			signal: noiseSignal(0.5),
+			issue:  AudioIssueClipping,
+			check: func(t *testing.T, d *AudioDiagnostics) {
+				t.Helper()
+				assert.InDelta(t, 4.6, d.ClippingPercent, 0.5, "2σ peaks of the noise clip")
+			},
+		},
+		{
+			name:   "dc offset",
+			rate:   testDiagRate,
+			signal: mixSignals(noiseSignal(0.01), func(int) float64 { return 0.05 }),
+			issue:  AudioIssueDCOffset,
+			check: func(t *testing.T, d *AudioDiagnostics) {
+				t.Helper()
+				assert.InDelta(t, 0.05, d.DCOffset, 0.001)
+			},
+		},
+		{
+			name:   "digital silence",
+			rate:   testDiagRate,
+			signal: func(int) float64 { return 0 },
+			issue:  AudioIssueDeadChannel,
+		},
+		{
+			name:   "50 Hz hum",
+			rate:   testDiagRate,
+			signal: mixSignals(noiseSignal(0.01), sine(50, 0.01), sine(100, 0.005), sine(150, 0.005)),
+			issue:  AudioIssueMainsHum,
+			check: func(t *testing.T, d *AudioDiagnostics) {
+				t.Helper()
+				assert.InDelta(t, 50.0, d.HumFrequency, 0)
+				assert.Greater(t, d.HumProminenceDB, diagHumProminence)
+			},
+		},
+		{
+			name:   "60 Hz hum",
+			rate:   testDiagRate,
+			signal: mixSignals(noiseSignal(0.01), sine(120, 0.01), sine(180, 0.01)),
+			issue:  AudioIssueMainsHum,
+			check: func(t *testing.T, d *AudioDiagnostics) {
+				t.Helper()
+				assert.InDelta(t, 60.0, d.HumFrequency, 0)
+			},
+		},
+		{
+			name:   "slow sample rate",
+			rate:   44100,
+			signal: noiseSignal(0.01),
+			issue:  AudioIssueSampleRateMismatch,
+			check: func(t *testing.T, d *AudioDiagnostics) {
+				t.Helper()
+				assert.InDelta(t, 44100, d.MeasuredSampleRate, 300)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a := newAudioDiagnosticsAnalyzer("test", testDiagRate, testDiagInterval)
+			start := time.Now()
+
+			// Raised only after the issue was found in consecutive intervals
+			raised, _, last, end := feedDiagnostics(a, start, 1, tt.rate, tt.signal)
+			assert.Empty(t, raised)
+			require.NotNil(t, last)
+			assert.Empty(t, last.Issues)
+
+			raised, _, last, end = feedDiagnostics(a, end, 1, tt.rate, tt.signal)
+			assert.Equal(t, []string{tt.issue}, raised)
+			require.NotNil(t, last)
+			require.Len(t, last.Issues, 1)
+			assert.Equal(t, tt.issue, last.Issues[0].Code)
+			assert.NotEmpty(t, last.Issues[0].Message)
+			if tt.check != nil {
+				tt.check(t, last)
+			}
+
+			// Cleared as soon as the signal is fine again
+			_, cleared, last, _ := feedDiagnostics(a, end, 1, testDiagRate, noiseSignal(0.03))
+			assert.Equal(t, []string{tt.issue}, cleared)
+			assert.Empty(t, last.Issues)
+		})
+	}
+}
+
+func TestAudioDiagnostics_PauseSkipsSampleRate(t *testing.T) {
+	t.Parallel()
+	a := newAudioDiagnosticsAnalyzer("test", testDiagRate, testDiagInterval)
+	chunk := encodeS16(make([]float64, testDiagRate/10))
+	now := time.Now()
+
+	// A stalled stream delivers its audio in a burst after two seconds
+	for i := range 101 {
+		if i == 50 {
+			now = now.Add(2 * time.Second)
+		}
+		result, _, _ := a.add(chunk, now)
+		if result != nil {
+			assert.Zero(t, result.MeasuredSampleRate)
+			for _, issue := range result.Issues {
+				assert.NotEqual(t, AudioIssueSampleRateMismatch, issue.Code)
+			}
+		}
+		now = now.Add(100 * time.Millisecond)
+	}
+}
+
+func TestAudioDiagnostics_DeadChannelOfDownmix(t *testing.T) {
+	t.Parallel()
+	a := newAudioDiagnosticsAnalyzer("test", testDiagRate, testDiagInterval)
+	noise := noiseSignal(0.03)
+	now := time.Now()
+
+	var raised []AudioIssue
+	var last *AudioDiagnostics
+	for range 201 {
+		// Channel 2 of a stereo device is dead, the downmix is not
+		interleaved := make([]float64, testDiagRate/10*2)
+		mono := make([]float64, testDiagRate/10)
+		for i := range mono {
+			interleaved[i*2] = noise(i)
+			mono[i] = interleaved[i*2] / 2
+		}
+		a.addChannels(encodeS16(interleaved), 2)
+		result, r, _ := a.add(encodeS16(mono), now)
+		raised = append(raised, r...)
+		if result != nil {
+			last = result
+		}
+		now = now.Add(100 * time.Millisecond)
+	}
+
+	require.Len(t, raised, 1)
+	assert.Equal(t, AudioIssueDeadChannel, raised[0].Code)
+	assert.InDelta(t, 2.0, raised[0].Value, 0)
+	require.NotNil(t, last)
+	require.Len(t, last.ChannelRMSDBFS, 2)
+	assert.InDelta(t, -30.5, last.ChannelRMSDBFS[0], 1)
+	assert.InDelta(t, -120.0, last.ChannelRMSDBFS[1], 0)
+}
+
+func TestAudioDiagnostics_Spectrum(t *testing.T) {
+	t.Parallel()
+	a := newAudioDiagnosticsAnalyzer("test", testDiagRate, testDiagInterval)
+	a.frames = 1
+
+	// Audio sampled at 16 kHz and upsampled ends at 8 kHz
+	for k := range a.sumPower {
+		if float64(k)*a.binWidth < 8000 {
+			a.sumPower[k] = 1
+		} else {
+			a.sumPower[k] = 1e-6
+		}
+	}
+	d := &AudioDiagnostics{RMSDBFS: -40}
+	a.checkBandwidth(d)
+	assert.InDelta(t, 8000.0, d.BandwidthHz, 0)
+	require.Len(t, d.Issues, 1)
+	assert.Equal(t, AudioIssueSampleRateMismatch, d.Issues[0].Code)
+
+	// A spectrum falling 24 dB per octave, as from a failed capsule
+	for k := range a.sumPower {
+		f := max(float64(k)*a.binWidth, 1)
+		a.sumPower[k] = math.Pow(f/1000, -8)
+	}
+	d = &AudioDiagnostics{RMSDBFS: -40}
+	a.checkTilt(d)
+	assert.InDelta(t, -24.0, d.SpectralTilt, 0.5)
+	require.Len(t, d.Issues, 1)
+	assert.Equal(t, AudioIssueSpectralTilt, d.Issues[0].Code)
+
+	// Too quiet to judge
+	d = &AudioDiagnostics{RMSDBFS: -90}
+	a.checkTilt(d)
+	assert.Empty(t, d.Issues)
+}
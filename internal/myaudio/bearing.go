@@ -0,0 +1,319 @@
+// bearing.go: direction of arrival of detected sounds at multichannel capture devices
+package myaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"math/cmplx"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/fft"
+)
+
+// Bearing estimation parameters
+const (
+	speedOfSound        = 343.0            // m/s in air at 20 °C
+	bearingFFTSize      = 4096             // samples per GCC-PHAT frame, 85 ms at 48 kHz
+	bearingMinFreq      = 1000.0           // Hz, lower frequencies carry wind and traffic noise
+	bearingMaxFreq      = 12000.0          // Hz, above most bird song
+	bearingHistory      = 20 * time.Second // multichannel audio kept for estimates
+	bearingMinCoherence = 0.1              // normalized GCC-PHAT peak below which a delay is noise
+)
+
+// bearingRecorder keeps the recent multichannel audio of a capture device so
+// the bearing of a detection can be estimated once the detection is made.
+type bearingRecorder struct {
+	mu          sync.Mutex
+	channels    int          // interleaved channels of the captured audio
+	positions   [][2]float64 // microphone position in metres of each channel used
+	orientation float64      // compass bearing the array front faces
+	samples     []int16      // ring of frames of the channels used
+	capacity    int          // frames in the ring
+	frames      int          // frames written in total
+	lastWrite   time.Time    // capture time of the newest frame
+}
+
+// Bearing recorders of running captures, keyed by source ID. The sources of
+// a split device share the recorder of the device.
+var (
+	bearingRecorders   = make(map[string]*bearingRecorder)
+	bearingRecordersMu sync.RWMutex
+)
+
+// newBearingRecorder returns a recorder for audio of channels interleaved
+// channels, or nil if the settings have no usable geometry for them.
+func newBearingRecorder(settings *conf.BearingSettings, channels int) *bearingRecorder {
+	positions := settings.MicrophonePositions()
+	if len(positions) < 2 || len(positions) > channels {
+		return nil
+	}
+	capacity := int(bearingHistory.Seconds()) * conf.SampleRate
+	return &bearingRecorder{
+		channels:    channels,
+		positions:   positions,
+		orientation: settings.Orientation,
+		samples:     make([]int16, capacity*len(positions)),
+		capacity:    capacity,
+	}
+}
+
+// registerBearingRecorder makes recorder estimate the bearings of sourceIDs.
+func registerBearingRecorder(sourceIDs []string, recorder *bearingRecorder) {
+	bearingRecordersMu.Lock()
+	defer bearingRecordersMu.Unlock()
+	for _, id := range sourceIDs {
+		bearingRecorders[id] = recorder
+	}
+}
+
+// unregisterBearingRecorder stops estimating the bearings of sourceIDs.
+func unregisterBearingRecorder(sourceIDs []string) {
+	bearingRecordersMu.Lock()
+	defer bearingRecordersMu.Unlock()
+	for _, id := range sourceIDs {
+		delete(bearingRecorders, id)
+	}
+}
+
+// write records interleaved 16-bit audio captured at now.
+func (r *bearingRecorder) write(data []byte, now time.Time) {
+	used := len(r.positions)
+	frameSize := r.channels * 2
+	frames := len(data) / frameSize
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for f := range frames {
+		ring := (r.frames % r.capacity) * used
+		for ch := range used {
+			r.samples[ring+ch] = int16(binary.LittleEndian.Uint16(data[f*frameSize+ch*2:])) //nolint:gosec // G115: reinterpreting PCM bits
+		}
+		r.frames++
+	}
+	r.lastWrite = now
+}
+
+// window returns the audio of each channel used captured between start and
+// end, or nil if less than a frame of it is still recorded.
+func (r *bearingRecorder) window(start, end time.Time) [][]float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.frames == 0 || !end.After(start) {
+		return nil
+	}
+	toFrames := func(d time.Duration) int { return int(d.Seconds() * conf.SampleRate) }
+	last := r.frames - max(toFrames(r.lastWrite.Sub(end)), 0)
+	first := max(last-toFrames(end.Sub(start)), r.frames-r.capacity, 0)
+	if last-first < bearingFFTSize {
+		return nil
+	}
+
+	used := len(r.positions)
+	channels := make([][]float64, used)
+	for ch := range channels {
+		channels[ch] = make([]float64, last-first)
+	}
+	for f := first; f < last; f++ {
+		ring := (f % r.capacity) * used
+		for ch := range used {
+			channels[ch][f-first] = float64(r.samples[ring+ch]) / 32768
+		}
+	}
+	return channels
+}
+
+// EstimateBearing returns the compass bearing in degrees of the dominant
+// sound captured between start and end at a source of a microphone array,
+// and false if the source estimates no bearings or the direction is unclear.
+// A stereo pair reports bearings in front of the array.
+func EstimateBearing(sourceID string, start, end time.Time) (float64, bool) {
+	bearingRecordersMu.RLock()
+	recorder, exists := bearingRecorders[sourceID]
+	bearingRecordersMu.RUnlock()
+	if !exists {
+		return 0, false
+	}
+
+	channels := recorder.window(start, end)
+	if channels == nil {
+		return 0, false
+	}
+	angle, ok := estimateDirection(channels, recorder.positions, conf.SampleRate)
+	if !ok {
+		return 0, false
+	}
+	bearing := math.Mod(recorder.orientation+angle+360, 360)
+	return math.Round(bearing*10) / 10, true
+}
+
+// estimateDirection returns the direction of the dominant sound in channels,
+// in degrees clockwise from the array front, using the delays between pairs
+// of microphones found by GCC-PHAT.
+func estimateDirection(channels [][]float64, positions [][2]float64, sampleRate int) (float64, bool) {
+	type pair struct {
+		i, j      int
+		dx, dy    float64 // position of i relative to j
+		delay     float64 // arrival at i minus arrival at j in seconds
+		coherence float64
+	}
+	var pairs []pair
+	for i := range positions {
+		for j := i + 1; j < len(positions); j++ {
+			pairs = append(pairs, pair{i: i, j: j, dx: positions[i][0] - positions[j][0], dy: positions[i][1] - positions[j][1]})
+		}
+	}
+
+	spectra := crossSpectra(channels, sampleRate)
+	if spectra == nil {
+		return 0, false
+	}
+	for p := range pairs {
+		maxDelay := math.Hypot(pairs[p].dx, pairs[p].dy) / speedOfSound
+		pairs[p].delay, pairs[p].coherence = gccPhatDelay(spectra[[2]int{pairs[p].i, pairs[p].j}], maxDelay, sampleRate)
+	}
+
+	// A far sound in direction u reaches a microphone at p earlier by p·u/c,
+	// so (pi - pj)·u = -c·delay for every pair
+	var ux, uy float64
+	if len(positions) == 2 {
+		pr := pairs[0]
+		if pr.coherence < bearingMinCoherence {
+			return 0, false
+		}
+		// A pair resolves the angle to its axis; the sound is taken to be in front
+		spacing := math.Hypot(pr.dx, pr.dy)
+		ax, ay := pr.dx/spacing, pr.dy/spacing
+		s := math.Max(-1, math.Min(1, -speedOfSound*pr.delay/spacing))
+		c := math.Sqrt(1 - s*s)
+		ux, uy = s*ax+c*ay, s*ay-c*ax
+	} else {
+		// Weighted least squares over the pairs that heard the sound clearly
+		var axx, axy, ayy, bx, by float64
+		for _, pr := range pairs {
+			if pr.coherence < bearingMinCoherence {
+				continue
+			}
+			w := pr.coherence
+			b := -speedOfSound * pr.delay
+			axx += w * pr.dx * pr.dx
+			axy += w * pr.dx * pr.dy
+			ayy += w * pr.dy * pr.dy
+			bx += w * pr.dx * b
+			by += w * pr.dy * b
+		}
+		det := axx*ayy - axy*axy
+		if math.Abs(det) < 1e-12 {
+			return 0, false
+		}
+		ux, uy = (ayy*bx-axy*by)/det, (axx*by-axy*bx)/det
+		if math.Hypot(ux, uy) < 1e-6 {
+			return 0, false
+		}
+	}
+
+	return math.Mod(math.Atan2(ux, uy)*180/math.Pi+360, 360), true
+}
+
+// crossSpectra accumulates the cross spectra of all pairs of channels over
+// Hann-windowed frames overlapping by half. Loud frames weigh more, which
+// favours the detected sound over quiet background.
+// The spectra cover the analysis band and are keyed by channel pair.
+func crossSpectra(channels [][]float64, sampleRate int) map[[2]int][]complex128 {
+	n := len(channels)
+	length := len(channels[0])
+	if length < bearingFFTSize {
+		return nil
+	}
+
+	minBin := int(math.Ceil(bearingMinFreq * bearingFFTSize / float64(sampleRate)))
+	maxBin := min(int(bearingMaxFreq*bearingFFTSize/float64(sampleRate)), bearingFFTSize/2-1)
+	bins := maxBin - minBin + 1
+
+	spectra := make(map[[2]int][]complex128)
+	for i := range n {
+		for j := i + 1; j < n; j++ {
+			spectra[[2]int{i, j}] = make([]complex128, bins)
+		}
+	}
+
+	window := make([]float64, bearingFFTSize)
+	for i := range window {
+		window[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/bearingFFTSize)
+	}
+	frames := make([][]complex128, n)
+	for ch := range frames {
+		frames[ch] = make([]complex128, bearingFFTSize)
+	}
+
+	for start := 0; start+bearingFFTSize <= length; start += bearingFFTSize / 2 {
+		for ch := range n {
+			for i := range bearingFFTSize {
+				frames[ch][i] = complex(channels[ch][start+i]*window[i], 0)
+			}
+			fft.Transform(frames[ch])
+		}
+		for i := range n {
+			for j := i + 1; j < n; j++ {
+				cross := spectra[[2]int{i, j}]
+				for k := range bins {
+					cross[k] += frames[i][minBin+k] * cmplx.Conj(frames[j][minBin+k])
+				}
+			}
+		}
+	}
+	return spectra
+}
+
+// gccPhatDelay returns the delay in seconds of the first channel of the
+// cross spectrum, which starts at the analysis band, relative to the second,
+// searched up to maxDelay, and the height of the correlation peak, which is
+// 1 for identical delayed signals and near 0 for unrelated ones.
+func gccPhatDelay(cross []complex128, maxDelay float64, sampleRate int) (delay, coherence float64) {
+	minBin := int(math.Ceil(bearingMinFreq * bearingFFTSize / float64(sampleRate)))
+
+	// Phase transform: keep only the phase of each bin, so all frequencies of
+	// the band weigh the same regardless of the spectrum of the sound
+	buf := make([]complex128, bearingFFTSize)
+	used := 0
+	for k, v := range cross {
+		magnitude := cmplx.Abs(v)
+		if magnitude == 0 {
+			continue
+		}
+		bin := minBin + k
+		buf[bin] = v / complex(magnitude, 0)
+		buf[bearingFFTSize-bin] = cmplx.Conj(buf[bin])
+		used++
+	}
+	if used == 0 {
+		return 0, 0
+	}
+
+	// Inverse transform through the forward one: ifft(x) = conj(fft(conj(x))) / n
+	for i := range buf {
+		buf[i] = cmplx.Conj(buf[i])
+	}
+	fft.Transform(buf)
+	correlation := func(lag int) float64 {
+		return real(buf[(lag+bearingFFTSize)%bearingFFTSize]) / float64(2*used)
+	}
+
+	maxLag := int(math.Ceil(maxDelay*float64(sampleRate))) + 1
+	peakLag := -maxLag
+	for lag := -maxLag; lag <= maxLag; lag++ {
+		if correlation(lag) > correlation(peakLag) {
+			peakLag = lag
+		}
+	}
+
+	// Parabolic interpolation between the samples around the peak
+	y0, y1, y2 := correlation(peakLag-1), correlation(peakLag), correlation(peakLag+1)
+	offset := 0.0
+	if d := y0 - 2*y1 + y2; d < 0 {
+		offset = math.Max(-0.5, math.Min(0.5, 0.5*(y0-y2)/d))
+	}
+	return (float64(peakLag) + offset) / float64(sampleRate), y1
+}
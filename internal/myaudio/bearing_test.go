@@ -0,0 +1,134 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"math"
+	"math/cmplx"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/fft"
+)
+
+// arrayNoise returns white noise from a far source at angle degrees
+// clockwise from the array front as received by microphones at positions.
+// Delays are applied as phase shifts, so they need not be whole samples.
+func arrayNoise(positions [][2]float64, angle float64, length int, seed uint64) [][]float64 {
+	rng := rand.New(rand.NewPCG(seed, 1)) //nolint:gosec // G404: test signal
+	spectrum := make([]complex128, length)
+	for i := range spectrum {
+		spectrum[i] = complex(rng.NormFloat64()*0.05, 0)
+	}
+	fft.Transform(spectrum)
+
+	ux, uy := math.Sin(angle*math.Pi/180), math.Cos(angle*math.Pi/180)
+	channels := make([][]float64, len(positions))
+	for ch, p := range positions {
+		delay := -(p[0]*ux + p[1]*uy) / speedOfSound * float64(conf.SampleRate)
+		shifted := make([]complex128, length)
+		for k := range shifted {
+			freq := k
+			if k > length/2 {
+				freq = k - length
+			}
+			shifted[k] = cmplx.Conj(spectrum[k] * cmplx.Exp(complex(0, -2*math.Pi*float64(freq)*delay/float64(length))))
+		}
+		fft.Transform(shifted)
+		channels[ch] = make([]float64, length)
+		for i := range shifted {
+			channels[ch][i] = real(shifted[i]) / float64(length)
+		}
+	}
+	return channels
+}
+
+// angleDiff returns the difference of two angles in degrees, between 0 and 180.
+func angleDiff(a, b float64) float64 {
+	d := math.Mod(math.Abs(a-b), 360)
+	return math.Min(d, 360-d)
+}
+
+func TestEstimateDirection(t *testing.T) {
+	t.Parallel()
+
+	square := [][2]float64{{0, 0.05}, {0.05, 0}, {0, -0.05}, {-0.05, 0}}
+	triangle := [][2]float64{{0, 0.1}, {0.087, -0.05}, {-0.087, -0.05}}
+	stereo := (&conf.BearingSettings{Spacing: 0.2}).MicrophonePositions()
+
+	tests := []struct {
+		name      string
+		positions [][2]float64
+		angle     float64
+		want      float64
+	}{
+		{name: "square front", positions: square, angle: 0, want: 0},
+		{name: "square right", positions: square, angle: 90, want: 90},
+		{name: "square behind left", positions: square, angle: 215, want: 215},
+		{name: "triangle", positions: triangle, angle: 300, want: 300},
+		{name: "stereo front right", positions: stereo, angle: 30, want: 30},
+		{name: "stereo left", positions: stereo, angle: 290, want: 290},
+		// A pair mirrors sounds from behind to the front
+		{name: "stereo behind right", positions: stereo, angle: 150, want: 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			channels := arrayNoise(tt.positions, tt.angle, 1<<16, 42)
+			got, ok := estimateDirection(channels, tt.positions, conf.SampleRate)
+			require.True(t, ok)
+			assert.LessOrEqual(t, angleDiff(got, tt.want), 5.0, "got %.1f°, want %.1f°", got, tt.want)
+		})
+	}
+}
+
+func TestEstimateDirection_Uncorrelated(t *testing.T) {
+	t.Parallel()
+
+	// Each microphone hears different noise, so there is no direction
+	positions := [][2]float64{{0, 0.05}, {0.05, 0}, {0, -0.05}}
+	channels := make([][]float64, len(positions))
+	for ch := range channels {
+		channels[ch] = arrayNoise(positions[:1], 0, 1<<16, uint64(ch+1))[0]
+	}
+	_, ok := estimateDirection(channels, positions, conf.SampleRate)
+	assert.False(t, ok)
+}
+
+func TestEstimateBearing(t *testing.T) {
+	t.Parallel()
+
+	settings := &conf.BearingSettings{Enabled: true, Positions: [][]float64{{0, 0.05}, {0.05, 0}, {0, -0.05}, {-0.05, 0}}, Orientation: 90}
+	recorder := newBearingRecorder(settings, 4)
+	require.NotNil(t, recorder)
+
+	const sourceID = "test_bearing_source"
+	registerBearingRecorder([]string{sourceID}, recorder)
+	t.Cleanup(func() { unregisterBearingRecorder([]string{sourceID}) })
+
+	// 1.4 seconds from 45° right of the array front, which faces east
+	channels := arrayNoise(recorder.positions, 45, 1<<16, 7)
+	data := make([]byte, len(channels[0])*4*2)
+	for f := range channels[0] {
+		for ch := range 4 {
+			binary.LittleEndian.PutUint16(data[(f*4+ch)*2:], uint16(int16(channels[ch][f]*32767))) //nolint:gosec // G115: noise below full scale
+		}
+	}
+	end := time.Now()
+	recorder.write(data, end)
+
+	bearing, ok := EstimateBearing(sourceID, end.Add(-time.Second), end)
+	require.True(t, ok)
+	assert.LessOrEqual(t, angleDiff(bearing, 135), 5.0, "got %.1f°", bearing)
+
+	// Audio older than the recorded history has no bearing
+	_, ok = EstimateBearing(sourceID, end.Add(-time.Minute), end.Add(-50*time.Second))
+	assert.False(t, ok)
+
+	_, ok = EstimateBearing("no_such_source", end.Add(-time.Second), end)
+	assert.False(t, ok)
+}
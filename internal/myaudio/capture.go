@@ -25,6 +25,8 @@ type AudioDataCallback func(sourceID string, data []byte)
 // Global callback registry for broadcasting audio data
 var (
 	broadcastCallbacks         map[string]AudioDataCallback // Map of sourceID -> callback
+	broadcastListeners         map[string]map[uint64]AudioDataCallback
+	broadcastListenerSeq       uint64
 	broadcastCallbackMutex     sync.RWMutex
 	lastCallbackLogTime        atomic.Int64 // Unix nano timestamp of last active callback log
 	lastMissingCallbackLogTime atomic.Int64 // Unix nano timestamp of last missing callback log
@@ -32,6 +34,33 @@ var (
 
 func init() {
 	broadcastCallbacks = make(map[string]AudioDataCallback)
+	broadcastListeners = make(map[string]map[uint64]AudioDataCallback)
+}
+
+// AddBroadcastListener adds a callback receiving audio data of a source.
+// Unlike RegisterBroadcastCallback any number of listeners can be added per
+// source. The returned function removes the listener.
+func AddBroadcastListener(sourceID string, callback AudioDataCallback) (remove func()) {
+	broadcastCallbackMutex.Lock()
+	broadcastListenerSeq++
+	id := broadcastListenerSeq
+	if broadcastListeners[sourceID] == nil {
+		broadcastListeners[sourceID] = make(map[uint64]AudioDataCallback)
+	}
+	broadcastListeners[sourceID][id] = callback
+	broadcastCallbackMutex.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			broadcastCallbackMutex.Lock()
+			defer broadcastCallbackMutex.Unlock()
+			delete(broadcastListeners[sourceID], id)
+			if len(broadcastListeners[sourceID]) == 0 {
+				delete(broadcastListeners, sourceID)
+			}
+		})
+	}
 }
 
 // RegisterBroadcastCallback adds a callback function to receive audio data for a specific source
@@ -76,6 +105,10 @@ func UnregisterBroadcastCallback(sourceID string) {
 func broadcastAudioData(sourceID string, data []byte) {
 	broadcastCallbackMutex.RLock()
 	callback, exists := broadcastCallbacks[sourceID]
+	var listeners []AudioDataCallback
+	for _, listener := range broadcastListeners[sourceID] {
+		listeners = append(listeners, listener)
+	}
 
 	// Debug log: log registered callbacks less frequently (every 5 minutes)
 	// Use atomic operations for thread-safe timestamp access
@@ -101,8 +134,15 @@ func broadcastAudioData(sourceID string, data []byte) {
 
 	broadcastCallbackMutex.RUnlock()
 
+	for _, listener := range listeners {
+		listener(sourceID, data)
+	}
+
 	// If no callback registered for this source, skip all processing
 	if !exists {
+		if len(listeners) > 0 {
+			return
+		}
 		// Log much less frequently to avoid log spam (once every 5 minutes)
 		lastMissingLogNano := lastMissingCallbackLogTime.Load()
 		if time.Since(time.Unix(0, lastMissingLogNano)) > 5*time.Minute {
@@ -284,6 +324,10 @@ func initializeBuffersForSource(sourceID string) error {
 }
 
 func CaptureAudio(settings *conf.Settings, wg *sync.WaitGroup, quitChan, restartChan chan struct{}, unifiedAudioChan chan UnifiedAudioData) {
+	// Additional devices restart on their own, so they run independently of
+	// the sources below, which may return early on device errors
+	startDeviceCaptures(settings, wg, quitChan, unifiedAudioChan)
+
 	// If no RTSP streams and no audio device configured, return early
 	if len(settings.Realtime.RTSP.Streams) == 0 && settings.Realtime.Audio.Source == "" {
 		return
@@ -342,8 +386,16 @@ func CaptureAudio(settings *conf.Settings, wg *sync.WaitGroup, quitChan, restart
 		}
 
 		// Device audio capture - pass source ID for buffer operations
+		layout := &captureLayout{
+			channels:    conf.NumChannels,
+			sourceIDs:   []string{source.ID},
+			names:       []string{selectedSource.Name},
+			scheduleKey: settings.Realtime.Audio.Source,
+			schedule:    &settings.Realtime.Audio.Schedule,
+		}
+		layout.audioChan.Store(&unifiedAudioChan)
 		wg.Go(func() {
-			captureAudioMalgo(settings, selectedSource, source.ID, quitChan, restartChan, unifiedAudioChan)
+			captureAudioMalgo(settings, selectedSource, layout, quitChan, restartChan)
 		})
 	}
 }
@@ -377,11 +429,16 @@ func getHardwareDevices(infos []malgo.DeviceInfo) []malgo.DeviceInfo {
 // TestCaptureDevice tests if a capture device can be initialized and started.
 // Returns true if the device is working, false otherwise.
 func TestCaptureDevice(ctx *malgo.AllocatedContext, info *malgo.DeviceInfo) bool {
+	return testCaptureDeviceChannels(ctx, info, conf.NumChannels)
+}
+
+// testCaptureDeviceChannels tests a capture device opened with channels channels.
+func testCaptureDeviceChannels(ctx *malgo.AllocatedContext, info *malgo.DeviceInfo, channels int) bool {
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
 	// Malgo bit depth conversion seems to be broken, so we'll do it manually,
 	// accept default format from capture device
 	//deviceConfig.Capture.Format = malgo.FormatS16
-	deviceConfig.Capture.Channels = conf.NumChannels
+	deviceConfig.Capture.Channels = uint32(channels) //nolint:gosec // G115: channels validated to MaxCaptureChannels
 	deviceConfig.Capture.DeviceID = info.ID.Pointer()
 	deviceConfig.SampleRate = conf.SampleRate
 	deviceConfig.Alsa.NoMMap = 1
@@ -465,6 +522,12 @@ func ValidateAudioDevice(settings *conf.Settings) error {
 
 // selectCaptureSource selects and tests an appropriate capture device based on the provided settings.
 func selectCaptureSource(settings *conf.Settings) (captureSource, error) {
+	return selectCaptureDevice(settings, settings.Realtime.Audio.Source, conf.NumChannels)
+}
+
+// selectCaptureDevice selects the capture device matching device and tests
+// it with channels channels.
+func selectCaptureDevice(settings *conf.Settings, device string, channels int) (captureSource, error) {
 	log := GetLogger()
 
 	var backend malgo.Backend
@@ -508,8 +571,8 @@ func selectCaptureSource(settings *conf.Settings) (captureSource, error) {
 			deviceInfo = deviceInfo + ", " + decodedID
 		}
 
-		if matchesDeviceSettings(decodedID, &infos[i], settings.Realtime.Audio.Source) {
-			if TestCaptureDevice(malgoCtx, &infos[i]) {
+		if matchesDeviceSettings(decodedID, &infos[i], device) {
+			if testCaptureDeviceChannels(malgoCtx, &infos[i], channels) {
 				log.Info("Audio device selected",
 					logger.Int("index", i),
 					logger.String("device", deviceInfo))
@@ -529,7 +592,7 @@ func selectCaptureSource(settings *conf.Settings) (captureSource, error) {
 			logger.String("device", deviceInfo))
 	}
 
-	return captureSource{}, fmt.Errorf("no working capture device found matching '%s'", settings.Realtime.Audio.Source)
+	return captureSource{}, fmt.Errorf("no working capture device found matching '%s'", device)
 }
 
 // matchesDeviceSettings checks if the device matches the settings specified by the user.
@@ -649,6 +712,8 @@ func processAudioFrame(
 		log.Warn("error writing to capture buffer", logger.Error(writeErr))
 		// Potentially non-fatal, log and continue
 	}
+	WriteToArchive(sourceID, bufferToUse)
+	observeAudioDiagnostics(sourceID, bufferToUse)
 
 	// Broadcast audio data using source ID (use the safe bufferToUse)
 	broadcastAudioData(sourceID, bufferToUse)
@@ -697,6 +762,46 @@ func processAudioFrame(
 	return finalBufferPtr, fromPool, nil // Return pointer, pool status, and nil error
 }
 
+// processMultichannelFrame converts a multichannel frame to 16-bit PCM and
+// processes it as one downmixed source or, for split devices, as one source
+// per channel.
+func processMultichannelFrame(pSamples []byte, formatType malgo.FormatType, convertBuffer []byte, settings *conf.Settings, source captureSource, layout *captureLayout) {
+	samples := pSamples
+	if formatType != malgo.FormatS16 {
+		convertedPtr, fromPool, err := ConvertToS16(pSamples, formatType, convertBuffer)
+		if err != nil {
+			GetLogger().Error("error converting audio format", logger.Error(err))
+			return
+		}
+		defer ReturnBufferToPool(convertedPtr, fromPool)
+		samples = *convertedPtr
+	}
+
+	if layout.bearing != nil {
+		layout.bearing.write(samples, time.Now())
+	}
+
+	var channelData [][]byte
+	if layout.split {
+		channelData = deinterleaveS16(samples, layout.channels)
+	} else {
+		// The downmix hides a dead channel, so diagnostics see the channels
+		observeChannelDiagnostics(layout.sourceIDs[0], samples, layout.channels)
+		channelData = [][]byte{downmixS16(samples, layout.channels)}
+	}
+
+	for i, data := range channelData {
+		channelSource := source
+		channelSource.Name = layout.names[i]
+		finalBufferPtr, fromPool, err := processAudioFrame(
+			data, malgo.FormatS16, nil, settings, channelSource, layout.sourceIDs[i], layout.unifiedChan(),
+		)
+		if err == nil && fromPool && finalBufferPtr != nil {
+			ReturnBufferToPool(finalBufferPtr, fromPool)
+		}
+	}
+}
+
 // handleDeviceStop contains the logic for attempting to restart the audio device
 // when it stops unexpectedly.
 func handleDeviceStop(captureDevice *malgo.Device, quitChan, restartChan chan struct{}, settings *conf.Settings, restarting *atomic.Int32) {
@@ -740,12 +845,18 @@ func handleDeviceStop(captureDevice *malgo.Device, quitChan, restartChan chan st
 	}
 }
 
-func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID string, quitChan, restartChan chan struct{}, unifiedAudioChan chan UnifiedAudioData) {
+// captureAudioMalgo captures a device until quitChan is closed or a restart
+// is requested. layout maps the device channels to sources.
+func captureAudioMalgo(settings *conf.Settings, source captureSource, layout *captureLayout, quitChan, restartChan chan struct{}) {
 
 	log := GetLogger()
 
-	// Clean up sound level processor when function exits
-	defer UnregisterSoundLevelProcessor(sourceID)
+	// Clean up sound level processors when function exits
+	defer func() {
+		for _, sourceID := range layout.sourceIDs {
+			UnregisterSoundLevelProcessor(sourceID)
+		}
+	}()
 
 	log.Debug("Initializing audio context")
 
@@ -772,7 +883,7 @@ func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID s
 
 	deviceConfig := malgo.DefaultDeviceConfig(malgo.Capture)
 	// deviceConfig.Capture.Format = malgo.FormatS16 // Let malgo choose or use default
-	deviceConfig.Capture.Channels = conf.NumChannels
+	deviceConfig.Capture.Channels = uint32(layout.channels) //nolint:gosec // G115: channels validated to MaxCaptureChannels
 	deviceConfig.SampleRate = conf.SampleRate
 	deviceConfig.Alsa.NoMMap = 1
 	deviceConfig.Capture.DeviceID = source.Pointer
@@ -782,13 +893,15 @@ func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID s
 		log.Warn("error initializing filter chain", logger.Error(err))
 	}
 
-	// Initialize sound level processor for this source if enabled
+	// Initialize sound level processors for the sources if enabled
 	if settings.Realtime.Audio.SoundLevel.Enabled {
-		if err := RegisterSoundLevelProcessor(sourceID, source.Name); err != nil {
-			log.Warn("error initializing sound level processor",
-				logger.Error(err),
-				logger.String("source_id", sourceID),
-				logger.String("source_name", source.Name))
+		for i, sourceID := range layout.sourceIDs {
+			if err := RegisterSoundLevelProcessor(sourceID, layout.names[i]); err != nil {
+				log.Warn("error initializing sound level processor",
+					logger.Error(err),
+					logger.String("source_id", sourceID),
+					logger.String("source_name", layout.names[i]))
+			}
 		}
 	}
 
@@ -797,11 +910,24 @@ func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID s
 	var scratchBuffer []byte        // Dedicated buffer for conversion destination
 	var restarting atomic.Int32     // Flag to prevent concurrent restarts
 
+	// Frames outside the recording schedule are dropped so nothing is analyzed.
+	// The device stays open, as reopening it is unreliable on some hardware.
+	var scheduleActive atomic.Bool
+	scheduleActive.Store(evaluateSourceSchedule(layout.scheduleKey, layout.schedule, time.Now()).Active)
+	lastScheduleCheck := time.Now()
+
 	onReceiveFrames := func(pSample2, pSamples []byte, framecount uint32) {
+		if !scheduleActive.Load() {
+			return
+		}
+		if layout.channels > conf.NumChannels {
+			processMultichannelFrame(pSamples, formatType, scratchBuffer, settings, source, layout)
+			return
+		}
 		// processAudioFrame now handles pooling internally and returns buffer info
 		// Pass scratchBuffer as the potential destination for conversion
 		finalBufferPtr, fromPool, err := processAudioFrame(
-			pSamples, formatType, scratchBuffer, settings, source, sourceID, unifiedAudioChan,
+			pSamples, formatType, scratchBuffer, settings, source, layout.sourceIDs[0], layout.unifiedChan(),
 		)
 		if err != nil {
 			// Error already logged in processAudioFrame
@@ -836,6 +962,7 @@ func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID s
 	captureDevice, err = malgo.InitDevice(malgoCtx.Context, deviceConfig, deviceCallbacks)
 	if err != nil {
 		log.Error("Device initialization failed", logger.Error(err))
+		failFailoverSources(layout.sourceIDs...)
 		alerting.TryPublish(&alerting.AlertEvent{
 			ObjectType: alerting.ObjectTypeDevice,
 			EventName:  alerting.EventDeviceError,
@@ -860,6 +987,7 @@ func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID s
 	err = captureDevice.Start()
 	if err != nil {
 		log.Error("Device start failed", logger.Error(err))
+		failFailoverSources(layout.sourceIDs...)
 		alerting.TryPublish(&alerting.AlertEvent{
 			ObjectType: alerting.ObjectTypeDevice,
 			EventName:  alerting.EventDeviceError,
@@ -903,6 +1031,15 @@ func captureAudioMalgo(settings *conf.Settings, source captureSource, sourceID s
 			log.Debug("Restarting audio capture")
 			return
 		default:
+			if time.Since(lastScheduleCheck) >= scheduleCheckInterval {
+				lastScheduleCheck = time.Now()
+				active := evaluateSourceSchedule(layout.scheduleKey, layout.schedule, lastScheduleCheck).Active
+				if scheduleActive.Swap(active) != active {
+					log.Info("audio device recording schedule changed",
+						logger.String("name", source.Name),
+						logger.Bool("active", active))
+				}
+			}
 			time.Sleep(100 * time.Millisecond)
 		}
 	}
@@ -274,6 +274,9 @@ func RemoveCaptureBuffer(sourceID string) error {
 	delete(captureBuffers, sourceID)
 	cbMutex.Unlock() // Release lock before calling registry
 
+	// Finish the source's archive file, if it is being recorded
+	stopArchiveRecorder(sourceID)
+
 	// Release reference to this source - registry will auto-remove if count reaches zero
 	registry := GetRegistry()
 	// Guard against nil registry during shutdown to prevent panic
@@ -0,0 +1,268 @@
+// capture_devices.go: capture from additional local devices and per-channel virtual sources
+package myaudio
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// Restart backoff of additional capture devices
+const (
+	deviceRestartMinDelay = 5 * time.Second
+	deviceRestartMaxDelay = 2 * time.Minute
+	// deviceStableDuration is how long a capture must run before the backoff resets
+	deviceStableDuration = time.Minute
+)
+
+// DeviceSource is a source captured from a local audio device: the whole
+// device, or one channel of a device that is split into channels.
+type DeviceSource struct {
+	Connection  string // registry connection string, "<device>#ch<N>" for channels
+	DisplayName string // name shown in the UI and stored with detections
+	Channel     int    // zero based channel of a split device, -1 for the whole device
+}
+
+// captureLayout maps the channels of a capture device to sources.
+type captureLayout struct {
+	channels    int                    // channels opened on the device
+	split       bool                   // each channel is a separate source
+	sourceIDs   []string               // registry IDs, one per channel when split
+	names       []string               // display names matching sourceIDs
+	scheduleKey string                 // key of the schedule state
+	schedule    *conf.ScheduleSettings // recording schedule of the device
+	bearing     *bearingRecorder       // recent audio for bearings, nil if not estimated
+	audioChan   atomic.Pointer[chan UnifiedAudioData]
+}
+
+// unifiedChan returns the channel audio levels and sound levels are sent to.
+func (l *captureLayout) unifiedChan() chan UnifiedAudioData {
+	return *l.audioChan.Load()
+}
+
+// Running captures of additional devices, keyed by device
+var (
+	deviceCaptures   = make(map[string]*captureLayout)
+	deviceCapturesMu sync.Mutex
+)
+
+// ChannelSourceConnection returns the registry connection string of a
+// channel of a split device. channel is zero based.
+func ChannelSourceConnection(device string, channel int) string {
+	return fmt.Sprintf("%s#ch%d", device, channel+1)
+}
+
+// DeviceSources returns the sources a configured device is captured as.
+func DeviceSources(device *conf.AudioDeviceSettings) []DeviceSource {
+	name := device.Name
+	if name == "" {
+		name = device.Device
+	}
+	if !device.Split {
+		return []DeviceSource{{Connection: device.Device, DisplayName: name, Channel: -1}}
+	}
+
+	sources := make([]DeviceSource, device.Channels)
+	for ch := range sources {
+		channelName := fmt.Sprintf("%s ch%d", name, ch+1)
+		if ch < len(device.ChannelNames) && device.ChannelNames[ch] != "" {
+			channelName = device.ChannelNames[ch]
+		}
+		sources[ch] = DeviceSource{
+			Connection:  ChannelSourceConnection(device.Device, ch),
+			DisplayName: channelName,
+			Channel:     ch,
+		}
+	}
+	return sources
+}
+
+// RegisterDeviceSources registers the sources of all additional capture
+// devices in the registry. Sources that fail to register are skipped and
+// their errors returned joined.
+func RegisterDeviceSources(settings *conf.Settings) ([]*AudioSource, error) {
+	registry := GetRegistry()
+	if registry == nil {
+		return nil, errors.Newf("audio source registry not available").
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "register_device_sources").
+			Build()
+	}
+
+	var sources []*AudioSource
+	var errs []error
+	for i := range settings.Realtime.Audio.Devices {
+		for _, deviceSource := range DeviceSources(&settings.Realtime.Audio.Devices[i]) {
+			source, err := registry.RegisterSource(deviceSource.Connection, SourceConfig{
+				Type:        SourceTypeAudioCard,
+				DisplayName: deviceSource.DisplayName,
+			})
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			sources = append(sources, source)
+		}
+	}
+	return sources, errors.Join(errs...)
+}
+
+// startDeviceCaptures starts capturing the additional devices that are not
+// already running. Devices already running switch to unifiedAudioChan, which
+// is replaced when capture restarts.
+func startDeviceCaptures(settings *conf.Settings, wg *sync.WaitGroup, quitChan chan struct{}, unifiedAudioChan chan UnifiedAudioData) {
+	log := GetLogger()
+
+	for i := range settings.Realtime.Audio.Devices {
+		device := &settings.Realtime.Audio.Devices[i]
+
+		deviceCapturesMu.Lock()
+		if running, exists := deviceCaptures[device.Device]; exists {
+			running.audioChan.Store(&unifiedAudioChan)
+			deviceCapturesMu.Unlock()
+			continue
+		}
+		deviceCapturesMu.Unlock()
+
+		layout, err := newCaptureLayout(device)
+		if err != nil {
+			log.Error("failed to register audio device sources",
+				logger.String("device", device.Device),
+				logger.Error(err))
+			continue
+		}
+		layout.audioChan.Store(&unifiedAudioChan)
+
+		deviceCapturesMu.Lock()
+		deviceCaptures[device.Device] = layout
+		deviceCapturesMu.Unlock()
+
+		wg.Go(func() {
+			defer func() {
+				deviceCapturesMu.Lock()
+				delete(deviceCaptures, device.Device)
+				deviceCapturesMu.Unlock()
+				unregisterBearingRecorder(layout.sourceIDs)
+			}()
+			runDeviceCapture(settings, device.Device, layout, quitChan)
+		})
+	}
+}
+
+// newCaptureLayout registers the sources of a device and allocates their buffers.
+func newCaptureLayout(device *conf.AudioDeviceSettings) (*captureLayout, error) {
+	registry := GetRegistry()
+	if registry == nil {
+		return nil, errors.Newf("audio source registry not available").
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "new_capture_layout").
+			Build()
+	}
+
+	layout := &captureLayout{
+		channels:    max(device.Channels, conf.NumChannels),
+		split:       device.Split,
+		scheduleKey: device.Device,
+		schedule:    &device.Schedule,
+	}
+	for _, deviceSource := range DeviceSources(device) {
+		source, err := registry.RegisterSource(deviceSource.Connection, SourceConfig{
+			Type:        SourceTypeAudioCard,
+			DisplayName: deviceSource.DisplayName,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := initializeBuffersForSource(source.ID); err != nil {
+			return nil, err
+		}
+		layout.sourceIDs = append(layout.sourceIDs, source.ID)
+		layout.names = append(layout.names, deviceSource.DisplayName)
+	}
+
+	if device.Bearing.Enabled {
+		layout.bearing = newBearingRecorder(&device.Bearing, layout.channels)
+		if layout.bearing == nil {
+			return nil, errors.Newf("bearing estimation needs a microphone position for each of 2 to %d channels", layout.channels).
+				Component("myaudio").
+				Category(errors.CategoryConfiguration).
+				Context("operation", "new_capture_layout").
+				Context("device", device.Device).
+				Build()
+		}
+		registerBearingRecorder(layout.sourceIDs, layout.bearing)
+	}
+	return layout, nil
+}
+
+// runDeviceCapture captures a device until quitChan is closed. The device is
+// reopened with increasing delays when capture stops or cannot start, as when
+// a USB interface is unplugged.
+func runDeviceCapture(settings *conf.Settings, device string, layout *captureLayout, quitChan chan struct{}) {
+	log := GetLogger()
+	delay := deviceRestartMinDelay
+
+	for {
+		started := time.Now()
+		source, err := selectCaptureDevice(settings, device, layout.channels)
+		if err != nil {
+			log.Warn("audio device not available",
+				logger.String("device", device),
+				logger.Error(err))
+		} else {
+			// A private restart channel keeps device errors from restarting other sources
+			captureAudioMalgo(settings, source, layout, quitChan, make(chan struct{}, 1))
+		}
+
+		if time.Since(started) >= deviceStableDuration {
+			delay = deviceRestartMinDelay
+		}
+		select {
+		case <-quitChan:
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, deviceRestartMaxDelay)
+	}
+}
+
+// downmixS16 averages interleaved 16-bit little-endian channels to mono.
+func downmixS16(data []byte, channels int) []byte {
+	frameSize := channels * 2
+	frames := len(data) / frameSize
+	mono := make([]byte, frames*2)
+	for f := range frames {
+		var sum int
+		for ch := range channels {
+			offset := f*frameSize + ch*2
+			sum += int(int16(binary.LittleEndian.Uint16(data[offset:]))) //nolint:gosec // G115: reinterpreting PCM bits
+		}
+		binary.LittleEndian.PutUint16(mono[f*2:], uint16(int16(sum/channels))) //nolint:gosec // G115: average fits in int16
+	}
+	return mono
+}
+
+// deinterleaveS16 splits interleaved 16-bit channels into one buffer per channel.
+func deinterleaveS16(data []byte, channels int) [][]byte {
+	frameSize := channels * 2
+	frames := len(data) / frameSize
+	out := make([][]byte, channels)
+	for ch := range out {
+		out[ch] = make([]byte, frames*2)
+	}
+	for f := range frames {
+		for ch := range channels {
+			offset := f*frameSize + ch*2
+			copy(out[ch][f*2:f*2+2], data[offset:offset+2])
+		}
+	}
+	return out
+}
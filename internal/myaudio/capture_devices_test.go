@@ -0,0 +1,61 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestDeviceSources(t *testing.T) {
+	t.Parallel()
+
+	whole := DeviceSources(&conf.AudioDeviceSettings{Device: "hw:1,0", Channels: 2})
+	require.Len(t, whole, 1)
+	assert.Equal(t, DeviceSource{Connection: "hw:1,0", DisplayName: "hw:1,0", Channel: -1}, whole[0])
+
+	split := DeviceSources(&conf.AudioDeviceSettings{
+		Device:       "hw:2,0",
+		Name:         "Array",
+		Channels:     3,
+		Split:        true,
+		ChannelNames: []string{"North", ""},
+	})
+	require.Len(t, split, 3)
+	assert.Equal(t, DeviceSource{Connection: "hw:2,0#ch1", DisplayName: "North", Channel: 0}, split[0])
+	assert.Equal(t, DeviceSource{Connection: "hw:2,0#ch2", DisplayName: "Array ch2", Channel: 1}, split[1])
+	assert.Equal(t, DeviceSource{Connection: "hw:2,0#ch3", DisplayName: "Array ch3", Channel: 2}, split[2])
+}
+
+// interleaveS16 builds interleaved 16-bit PCM from frames of channel samples.
+func interleaveS16(frames [][]int16) []byte {
+	var data []byte
+	for _, frame := range frames {
+		for _, sample := range frame {
+			data = binary.LittleEndian.AppendUint16(data, uint16(sample)) //nolint:gosec // G115: reinterpreting PCM bits
+		}
+	}
+	return data
+}
+
+func TestDeinterleaveS16(t *testing.T) {
+	t.Parallel()
+	data := interleaveS16([][]int16{{1, -1, 100}, {2, -2, 200}})
+
+	channels := deinterleaveS16(data, 3)
+	require.Len(t, channels, 3)
+	assert.Equal(t, interleaveS16([][]int16{{1}, {2}}), channels[0])
+	assert.Equal(t, interleaveS16([][]int16{{-1}, {-2}}), channels[1])
+	assert.Equal(t, interleaveS16([][]int16{{100}, {200}}), channels[2])
+}
+
+func TestDownmixS16(t *testing.T) {
+	t.Parallel()
+	data := interleaveS16([][]int16{{100, 300}, {-32768, -32768}, {32767, 32767}})
+
+	assert.Equal(t, interleaveS16([][]int16{{200}, {-32768}, {32767}}), downmixS16(data, 2))
+	// A trailing partial frame is dropped
+	assert.Len(t, downmixS16(data[:len(data)-1], 2), 4)
+}
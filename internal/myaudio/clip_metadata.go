@@ -0,0 +1,315 @@
+// clip_metadata.go: detection metadata tags for exported audio clips
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// clipMetadataSoftware identifies the application in written tags.
+const clipMetadataSoftware = "BirdNET-Go"
+
+// guanoNamespace prefixes GUANO fields that are not part of the GUANO specification.
+const guanoNamespace = "BirdNET-Go"
+
+// ClipMetadata describes the detection an exported audio clip belongs to.
+// It is written into clips as standard tags so a clip stays identifiable
+// once it is copied off the station.
+type ClipMetadata struct {
+	CommonName     string
+	ScientificName string
+	Confidence     float64   // 0-1
+	Model          string    // e.g. "BirdNET 2.4"
+	Source         string    // audio source display name
+	Station        string    // station (node) name
+	RecordedAt     time.Time // start of the clip
+	Latitude       float64
+	Longitude      float64
+	SampleRate     int // 0 uses the capture sample rate
+}
+
+// clipTag is a single tag in the order it is written.
+type clipTag struct {
+	key   string
+	value string
+}
+
+// Title returns the species name used as the clip title.
+func (m *ClipMetadata) Title() string {
+	if m.CommonName != "" {
+		return m.CommonName
+	}
+	return m.ScientificName
+}
+
+// Comment returns a one-line summary of the detection.
+func (m *ClipMetadata) Comment() string {
+	var parts []string
+	if m.ScientificName != "" && m.ScientificName != m.Title() {
+		parts = append(parts, "Species: "+m.ScientificName)
+	}
+	if m.Confidence > 0 {
+		parts = append(parts, fmt.Sprintf("Confidence: %.0f%%", m.Confidence*100))
+	}
+	if m.Model != "" {
+		parts = append(parts, "Model: "+m.Model)
+	}
+	if m.Source != "" {
+		parts = append(parts, "Source: "+m.Source)
+	}
+	return strings.Join(parts, "; ")
+}
+
+// hasLocation reports whether the metadata carries coordinates.
+func (m *ClipMetadata) hasLocation() bool {
+	return m.Latitude != 0 || m.Longitude != 0
+}
+
+// tags returns the standard tags: title, artist, comment, encoder and date.
+// FFmpeg maps these to ID3 frames, Vorbis comments and MP4 atoms.
+func (m *ClipMetadata) tags() []clipTag {
+	tags := []clipTag{
+		{"title", m.Title()},
+		{"artist", m.Station},
+		{"comment", m.Comment()},
+		{"encoder", clipMetadataSoftware},
+	}
+	if !m.RecordedAt.IsZero() {
+		tags = append(tags, clipTag{"date", m.RecordedAt.Format("2006-01-02T15:04:05")})
+	}
+
+	result := tags[:0]
+	for _, tag := range tags {
+		if tag.value = sanitizeTagValue(tag.value); tag.value != "" {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// FFmpegArgs returns the -metadata arguments that write the tags with FFmpeg.
+// The arguments belong after the inputs and before the output file.
+func (m *ClipMetadata) FFmpegArgs() []string {
+	if m == nil {
+		return nil
+	}
+	tags := m.tags()
+	args := make([]string, 0, 2*len(tags))
+	for _, tag := range tags {
+		args = append(args, "-metadata", tag.key+"="+tag.value)
+	}
+	return args
+}
+
+// GUANO returns the metadata in the GUANO format used by bioacoustic software.
+// length is the clip length and is omitted if zero.
+func (m *ClipMetadata) GUANO(length time.Duration) string {
+	sampleRate := m.SampleRate
+	if sampleRate <= 0 {
+		sampleRate = conf.SampleRate
+	}
+
+	var b strings.Builder
+	b.WriteString("GUANO|Version: 1.0\n")
+	field := func(key, value string) {
+		if value = sanitizeTagValue(value); value != "" {
+			b.WriteString(key + ": " + value + "\n")
+		}
+	}
+	if !m.RecordedAt.IsZero() {
+		field("Timestamp", m.RecordedAt.Format(time.RFC3339))
+	}
+	if length > 0 {
+		field("Length", strconv.FormatFloat(length.Seconds(), 'f', 3, 64))
+	}
+	field("Samplerate", strconv.Itoa(sampleRate))
+	if m.hasLocation() {
+		field("Loc Position", fmt.Sprintf("%.6f %.6f", m.Latitude, m.Longitude))
+	}
+	field("Species Auto ID", m.ScientificName)
+	field("Note", m.Comment())
+	field(guanoNamespace+"|Common Name", m.CommonName)
+	if m.Confidence > 0 {
+		field(guanoNamespace+"|Confidence", strconv.FormatFloat(m.Confidence, 'f', 4, 64))
+	}
+	field(guanoNamespace+"|Model", m.Model)
+	field(guanoNamespace+"|Source", m.Source)
+	field(guanoNamespace+"|Station", m.Station)
+	return b.String()
+}
+
+// sanitizeTagValue removes control characters, which would break line-based
+// formats such as GUANO, and surrounding whitespace.
+func sanitizeTagValue(value string) string {
+	return strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return ' '
+		}
+		return r
+	}, value))
+}
+
+// wavMetadataChunks returns a RIFF LIST/INFO chunk with the standard tags
+// followed by a GUANO chunk.
+func wavMetadataChunks(meta *ClipMetadata, length time.Duration) []byte {
+	var info bytes.Buffer
+	info.WriteString("INFO")
+	infoIDs := map[string]string{"title": "INAM", "artist": "IART", "comment": "ICMT", "encoder": "ISFT"}
+	for _, tag := range meta.tags() {
+		if id, ok := infoIDs[tag.key]; ok {
+			writeRIFFChunk(&info, id, append([]byte(tag.value), 0))
+		}
+	}
+	if !meta.RecordedAt.IsZero() {
+		writeRIFFChunk(&info, "ICRD", append([]byte(meta.RecordedAt.Format(time.DateOnly)), 0))
+	}
+
+	var chunks bytes.Buffer
+	writeRIFFChunk(&chunks, "LIST", info.Bytes())
+	writeRIFFChunk(&chunks, "guan", []byte(meta.GUANO(length)))
+	return chunks.Bytes()
+}
+
+// writeRIFFChunk writes a chunk header and data, padded to an even length.
+func writeRIFFChunk(buf *bytes.Buffer, id string, data []byte) {
+	buf.WriteString(id)
+	_ = binary.Write(buf, binary.LittleEndian, uint32(len(data))) //nolint:gosec // G115: metadata chunks are small
+	buf.Write(data)
+	if len(data)%2 == 1 {
+		buf.WriteByte(0)
+	}
+}
+
+// appendWAVMetadata appends the metadata chunks to a finished WAV file and
+// updates the RIFF size in its header.
+func appendWAVMetadata(f *os.File, meta *ClipMetadata, length time.Duration) error {
+	end, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	chunks := wavMetadataChunks(meta, length)
+	if _, err := f.Write(chunks); err != nil {
+		return err
+	}
+	var size [4]byte
+	binary.LittleEndian.PutUint32(size[:], uint32(end+int64(len(chunks))-8)) //nolint:gosec // G115: clip files are far below 4 GiB
+	_, err = f.WriteAt(size[:], 4)
+	return err
+}
+
+// EmbedWAVMetadata returns a copy of a WAV file with the metadata written as
+// LIST/INFO and GUANO chunks, replacing any existing ones.
+func EmbedWAVMetadata(data []byte, meta *ClipMetadata) ([]byte, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, errors.Newf("not a WAV file").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "embed_wav_metadata").
+			Build()
+	}
+
+	out := bytes.NewBuffer(make([]byte, 0, len(data)+1024))
+	out.Write(data[:12])
+	var byteRate, dataSize uint32
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		end := pos + 8 + size
+		if end > len(data) {
+			return nil, errors.Newf("truncated WAV chunk %q", id).
+				Component("myaudio").
+				Category(errors.CategoryValidation).
+				Context("operation", "embed_wav_metadata").
+				Build()
+		}
+		body := data[pos+8 : end]
+		end += size % 2
+		end = min(end, len(data))
+
+		switch {
+		case id == "fmt " && size >= 12:
+			byteRate = binary.LittleEndian.Uint32(body[8:12])
+		case id == "data":
+			dataSize = uint32(size) //nolint:gosec // G115: size was read from a uint32
+		}
+		// Drop the chunks being replaced
+		isInfo := id == "LIST" && size >= 4 && string(body[:4]) == "INFO"
+		if !isInfo && id != "guan" {
+			out.Write(data[pos:end])
+		}
+		pos = end
+	}
+
+	var length time.Duration
+	if byteRate > 0 {
+		length = time.Duration(float64(dataSize) / float64(byteRate) * float64(time.Second))
+	}
+	out.Write(wavMetadataChunks(meta, length))
+
+	result := out.Bytes()
+	binary.LittleEndian.PutUint32(result[4:8], uint32(len(result)-8)) //nolint:gosec // G115: clip files are far below 4 GiB
+	return result, nil
+}
+
+// TagAudioFileWithContext returns a copy of an encoded audio file with the
+// metadata written as tags. WAV files are tagged directly; other formats are
+// remuxed with FFmpeg without re-encoding.
+func TagAudioFileWithContext(ctx context.Context, inputPath, ffmpegPath string, meta *ClipMetadata) ([]byte, error) {
+	ext := strings.ToLower(filepath.Ext(inputPath))
+	if ext == ".wav" {
+		data, err := os.ReadFile(inputPath) //nolint:gosec // G304: inputPath is validated by the caller
+		if err != nil {
+			return nil, err
+		}
+		return EmbedWAVMetadata(data, meta)
+	}
+
+	if ffmpegPath == "" {
+		return nil, errors.Newf("FFmpeg path is not configured").
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "tag_audio_file").
+			Build()
+	}
+
+	// MP4 based containers need a seekable output, so write to a temporary file
+	// with the same extension to keep the container format.
+	tempDir, err := os.MkdirTemp("", "birdnet-go-tag-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tempDir) }()
+	outputPath := filepath.Join(tempDir, "clip"+ext)
+
+	args := make([]string, 0, 16)
+	args = append(args, "-hide_banner", "-loglevel", "error", "-i", inputPath,
+		"-map", "0:a", "-c", "copy", "-map_metadata", "-1")
+	args = append(args, meta.FFmpegArgs()...)
+	args = append(args, "-y", outputPath)
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...) //nolint:gosec // G204: ffmpegPath is from validated settings, args built internally
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Newf("FFmpeg failed to tag audio file: %w, stderr: %s", err, stderr.String()).
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "tag_audio_file").
+			Context("format", ext).
+			Build()
+	}
+
+	return os.ReadFile(outputPath) //nolint:gosec // G304: outputPath is in our own temporary directory
+}
@@ -0,0 +1,133 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testClipMetadata() *ClipMetadata {
+	return &ClipMetadata{
+		CommonName:     "Eurasian Blackbird",
+		ScientificName: "Turdus merula",
+		Confidence:     0.923,
+		Model:          "BirdNET 2.4",
+		Source:         "Garden mic",
+		Station:        "backyard",
+		RecordedAt:     time.Date(2024, 5, 1, 4, 32, 10, 0, time.FixedZone("EEST", 3*3600)),
+		Latitude:       60.1699,
+		Longitude:      24.9384,
+	}
+}
+
+// riffChunks returns the chunk IDs and bodies of a RIFF file.
+func riffChunks(t *testing.T, data []byte) (ids []string, bodies map[string][]byte) {
+	t.Helper()
+	require.Equal(t, "RIFF", string(data[:4]))
+	require.Equal(t, uint32(len(data)-8), binary.LittleEndian.Uint32(data[4:8]), "RIFF size must cover the whole file") //nolint:gosec // G115: test data is small
+	bodies = make(map[string][]byte)
+	for pos := 12; pos+8 <= len(data); {
+		id := string(data[pos : pos+4])
+		size := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		require.LessOrEqual(t, pos+8+size, len(data), "chunk %q overruns the file", id)
+		ids = append(ids, id)
+		bodies[id] = data[pos+8 : pos+8+size]
+		pos += 8 + size + size%2
+	}
+	return ids, bodies
+}
+
+func TestClipMetadataFFmpegArgs(t *testing.T) {
+	t.Parallel()
+
+	args := testClipMetadata().FFmpegArgs()
+	assert.Equal(t, []string{
+		"-metadata", "title=Eurasian Blackbird",
+		"-metadata", "artist=backyard",
+		"-metadata", "comment=Species: Turdus merula; Confidence: 92%; Model: BirdNET 2.4; Source: Garden mic",
+		"-metadata", "encoder=BirdNET-Go",
+		"-metadata", "date=2024-05-01T04:32:10",
+	}, args)
+
+	var nilMeta *ClipMetadata
+	assert.Empty(t, nilMeta.FFmpegArgs())
+
+	meta := &ClipMetadata{ScientificName: "Turdus merula", Station: "line\nbreak"}
+	args = meta.FFmpegArgs()
+	assert.Contains(t, args, "title=Turdus merula", "the scientific name is used without a common name")
+	assert.Contains(t, args, "artist=line break", "control characters are removed")
+}
+
+func TestClipMetadataGUANO(t *testing.T) {
+	t.Parallel()
+
+	guano := testClipMetadata().GUANO(3 * time.Second)
+	lines := strings.Split(strings.TrimSuffix(guano, "\n"), "\n")
+	assert.Equal(t, "GUANO|Version: 1.0", lines[0], "the version must be the first field")
+	assert.Contains(t, lines, "Timestamp: 2024-05-01T04:32:10+03:00")
+	assert.Contains(t, lines, "Length: 3.000")
+	assert.Contains(t, lines, "Samplerate: 48000")
+	assert.Contains(t, lines, "Loc Position: 60.169900 24.938400")
+	assert.Contains(t, lines, "Species Auto ID: Turdus merula")
+	assert.Contains(t, lines, "BirdNET-Go|Confidence: 0.9230")
+	assert.Contains(t, lines, "BirdNET-Go|Station: backyard")
+
+	guano = (&ClipMetadata{CommonName: "Eurasian Blackbird"}).GUANO(0)
+	assert.NotContains(t, guano, "Loc Position", "missing coordinates are omitted")
+	assert.NotContains(t, guano, "Length")
+}
+
+func TestSavePCMDataToWAVWithMetadata(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "clip.wav")
+	pcm := make([]byte, 96000) // one second
+	require.NoError(t, SavePCMDataToWAVWithMetadata(path, pcm, testClipMetadata()))
+
+	data, err := os.ReadFile(path) //nolint:gosec // G304: test file in temp dir
+	require.NoError(t, err)
+	ids, bodies := riffChunks(t, data)
+	assert.Equal(t, []string{"fmt ", "data", "LIST", "guan"}, ids, "metadata follows the audio data")
+	assert.Len(t, bodies["data"], len(pcm))
+	assert.Equal(t, "INFO", string(bodies["LIST"][:4]))
+	assert.Contains(t, string(bodies["LIST"]), "INAM")
+	assert.Contains(t, string(bodies["LIST"]), "Eurasian Blackbird")
+	assert.Contains(t, string(bodies["LIST"]), "ICRD")
+	assert.Contains(t, string(bodies["guan"]), "Length: 1.000")
+}
+
+func TestEmbedWAVMetadata(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "clip.wav")
+	require.NoError(t, SavePCMDataToWAV(path, make([]byte, 9600)))
+	original, err := os.ReadFile(path) //nolint:gosec // G304: test file in temp dir
+	require.NoError(t, err)
+
+	tagged, err := EmbedWAVMetadata(original, testClipMetadata())
+	require.NoError(t, err)
+	ids, bodies := riffChunks(t, tagged)
+	assert.Equal(t, []string{"fmt ", "data", "LIST", "guan"}, ids)
+	assert.Contains(t, string(bodies["guan"]), "Length: 0.100")
+
+	// Tagging again replaces the existing metadata instead of adding to it
+	meta := testClipMetadata()
+	meta.CommonName = "Song Thrush"
+	retagged, err := EmbedWAVMetadata(tagged, meta)
+	require.NoError(t, err)
+	ids, bodies = riffChunks(t, retagged)
+	assert.Equal(t, []string{"fmt ", "data", "LIST", "guan"}, ids)
+	assert.Contains(t, string(bodies["LIST"]), "Song Thrush")
+	assert.NotContains(t, string(retagged), "Eurasian Blackbird")
+
+	_, err = EmbedWAVMetadata([]byte("not audio"), meta)
+	require.Error(t, err)
+	_, err = EmbedWAVMetadata(original[:60], meta)
+	require.Error(t, err, "truncated data chunk")
+}
@@ -0,0 +1,195 @@
+// clip_process.go: trimming, filtering, normalization and re-encoding of saved clips
+package myaudio
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/myaudio/equalizer"
+)
+
+// Clip normalization parameters. These match the loudness target and gain
+// limits used for BirdWeather uploads.
+const (
+	ClipNormalizeTargetLUFS = -23.0
+	clipNormalizeMaxGain    = 30.0
+)
+
+// ClipFormatWAV is the format of processed clips encoded without FFmpeg.
+const ClipFormatWAV = "wav"
+
+// ClipProcessOptions describes how a saved clip is processed for download.
+type ClipProcessOptions struct {
+	Offset    time.Duration           // start of the audio to keep
+	Length    time.Duration           // length of the audio to keep, 0 keeps the rest of the clip
+	Format    string                  // wav, flac, mp3, opus, aac or alac
+	Bitrate   string                  // e.g. "96k", lossy formats only
+	Normalize bool                    // adjust gain to ClipNormalizeTargetLUFS
+	Equalizer *conf.EqualizerSettings // filters to apply, nil applies none
+}
+
+// Validate checks the options for unsupported values.
+func (o *ClipProcessOptions) Validate() error {
+	switch o.Format {
+	case ClipFormatWAV, FormatFLAC, FormatMP3, FormatOpus, FormatAAC, FormatALAC:
+	default:
+		return errors.Newf("unsupported clip format: %s", o.Format).
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "validate_clip_options").
+			Context("format", o.Format).
+			Build()
+	}
+	if o.Offset < 0 || o.Length < 0 {
+		return errors.Newf("clip offset and length cannot be negative").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "validate_clip_options").
+			Build()
+	}
+	if o.Bitrate != "" {
+		kbps, err := strconv.Atoi(strings.TrimSuffix(o.Bitrate, "k"))
+		if err != nil || !strings.HasSuffix(o.Bitrate, "k") || kbps < 32 || kbps > 320 {
+			return errors.Newf("invalid bitrate %s, must be between 32k and 320k", o.Bitrate).
+				Component("myaudio").
+				Category(errors.CategoryValidation).
+				Context("operation", "validate_clip_options").
+				Context("bitrate", o.Bitrate).
+				Build()
+		}
+	}
+	return nil
+}
+
+// ClipFileExtension returns the file extension, with leading dot, of a clip
+// processed to format.
+func ClipFileExtension(format string) string {
+	return "." + GetFileExtension(format)
+}
+
+// ProcessClipWithContext decodes a saved clip, applies the processing in
+// opts and returns the encoded result. FFmpeg is required to decode the clip.
+func ProcessClipWithContext(ctx context.Context, path, ffmpegPath string, opts *ClipProcessOptions) ([]byte, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	pcm, err := decodeAudioFileWithFFmpeg(ctx, ffmpegPath, path, opts.Offset, opts.Length)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryAudio).
+			Context("operation", "process_clip_decode").
+			Build()
+	}
+	if len(pcm) < 2 {
+		return nil, errors.Newf("no audio in the requested clip range").
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "process_clip").
+			Context("offset", opts.Offset.String()).
+			Build()
+	}
+
+	samples := BytesToFloat64PCM16(pcm)
+	if opts.Equalizer != nil {
+		if err := ApplyEqualizerToSamples(samples, opts.Equalizer, float64(conf.SampleRate)); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Normalize {
+		applyClipNormalization(ctx, samples, ffmpegPath)
+	}
+	if err := Float64ToBytesPCM16(samples, pcm); err != nil {
+		return nil, err
+	}
+
+	return encodeProcessedClip(ctx, pcm, ffmpegPath, opts)
+}
+
+// ApplyEqualizerToSamples filters samples with a new filter chain built from
+// settings. The chain is not shared with live capture, so filter state of
+// the capture stream is left untouched.
+func ApplyEqualizerToSamples(samples []float64, settings *conf.EqualizerSettings, sampleRate float64) error {
+	chain := equalizer.NewFilterChain()
+	for _, cfg := range settings.Filters {
+		filter, err := createFilter(cfg, sampleRate)
+		if errors.Is(err, ErrFilterDisabled) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := chain.AddFilter(filter); err != nil {
+			return err
+		}
+	}
+	if chain.Length() > 0 {
+		chain.ApplyBatch(samples)
+	}
+	return nil
+}
+
+// applyClipNormalization scales samples towards ClipNormalizeTargetLUFS using
+// the integrated loudness measured by FFmpeg. The gain is limited to
+// ±clipNormalizeMaxGain dB; if the measurement fails samples are left as is.
+func applyClipNormalization(ctx context.Context, samples []float64, ffmpegPath string) {
+	// Float64ToBytesPCM16 clamps its input, so measure a copy
+	pcm := make([]byte, len(samples)*2)
+	if err := Float64ToBytesPCM16(append([]float64(nil), samples...), pcm); err != nil {
+		return
+	}
+	stats, err := AnalyzeAudioLoudnessWithContext(ctx, pcm, ffmpegPath)
+	if err != nil {
+		GetLogger().Warn("Loudness analysis failed, clip is not normalized", logger.Error(err))
+		return
+	}
+	inputLUFS, err := strconv.ParseFloat(stats.InputI, 64)
+	if err != nil || math.IsInf(inputLUFS, 0) {
+		return
+	}
+	gain := clipNormalizationGain(inputLUFS)
+	scale := math.Pow(10, gain/20)
+	for i := range samples {
+		samples[i] *= scale
+	}
+}
+
+// clipNormalizationGain returns the gain in dB that brings audio at inputLUFS
+// to the normalization target, within the gain limits.
+func clipNormalizationGain(inputLUFS float64) float64 {
+	return math.Max(-clipNormalizeMaxGain, math.Min(clipNormalizeMaxGain, ClipNormalizeTargetLUFS-inputLUFS))
+}
+
+// encodeProcessedClip encodes PCM in the capture format to the format in opts.
+func encodeProcessedClip(ctx context.Context, pcm []byte, ffmpegPath string, opts *ClipProcessOptions) ([]byte, error) {
+	if opts.Format == ClipFormatWAV {
+		buf, err := EncodePCMtoWAVWithContext(ctx, pcm)
+		if err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	args := []string{"-c:a", getEncoder(opts.Format)}
+	if opts.Bitrate != "" && opts.Format != FormatFLAC && opts.Format != FormatALAC {
+		args = append(args, "-b:a", getMaxBitrate(opts.Format, opts.Bitrate))
+	}
+	if opts.Format == FormatAAC || opts.Format == FormatALAC {
+		// MP4 containers are not seekable through a pipe, so write a fragmented file
+		args = append(args, "-movflags", "frag_keyframe+empty_moov")
+	}
+	args = append(args, "-f", getOutputFormat(opts.Format))
+
+	buf, err := ExportAudioWithCustomFFmpegArgsContext(ctx, pcm, ffmpegPath, args)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
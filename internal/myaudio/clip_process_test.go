@@ -0,0 +1,75 @@
+package myaudio
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestClipProcessOptionsValidate(t *testing.T) {
+	t.Parallel()
+
+	valid := ClipProcessOptions{Format: FormatOpus, Bitrate: "64k", Offset: time.Second, Length: 5 * time.Second}
+	require.NoError(t, valid.Validate())
+
+	for name, opts := range map[string]ClipProcessOptions{
+		"format":          {Format: "exe"},
+		"negative offset": {Format: ClipFormatWAV, Offset: -time.Second},
+		"bitrate suffix":  {Format: FormatMP3, Bitrate: "128"},
+		"bitrate range":   {Format: FormatMP3, Bitrate: "1000k"},
+	} {
+		assert.Error(t, opts.Validate(), name)
+	}
+
+	assert.Equal(t, ".m4a", ClipFileExtension(FormatAAC))
+	assert.Equal(t, ".wav", ClipFileExtension(ClipFormatWAV))
+}
+
+func TestClipNormalizationGain(t *testing.T) {
+	t.Parallel()
+
+	assert.InDelta(t, 10.0, clipNormalizationGain(-33), 1e-9)
+	assert.InDelta(t, -5.0, clipNormalizationGain(-18), 1e-9)
+	assert.InDelta(t, clipNormalizeMaxGain, clipNormalizationGain(-90), 1e-9)
+}
+
+func TestApplyEqualizerToSamples(t *testing.T) {
+	t.Parallel()
+
+	const sampleRate = 48000.0
+	tone := func(freq float64) []float64 {
+		samples := make([]float64, int(sampleRate))
+		for i := range samples {
+			samples[i] = 0.5 * math.Sin(2*math.Pi*freq*float64(i)/sampleRate)
+		}
+		return samples
+	}
+	rms := func(samples []float64) float64 {
+		var sum float64
+		for _, s := range samples[len(samples)/2:] {
+			sum += s * s
+		}
+		return math.Sqrt(sum / float64(len(samples)/2))
+	}
+
+	settings := &conf.EqualizerSettings{Filters: []conf.EqualizerFilter{
+		{Type: "HighPass", Frequency: 1000, Q: 0.707, Passes: 1},
+		{Type: "LowPass", Frequency: 10000, Q: 0.707, Passes: 0}, // disabled
+	}}
+
+	low := tone(100)
+	require.NoError(t, ApplyEqualizerToSamples(low, settings, sampleRate))
+	high := tone(5000)
+	require.NoError(t, ApplyEqualizerToSamples(high, settings, sampleRate))
+
+	assert.Less(t, rms(low), 0.05, "high pass should attenuate 100 Hz")
+	assert.InDelta(t, 0.5/math.Sqrt2, rms(high), 0.05, "high pass should keep 5 kHz")
+
+	// A fresh chain is built per call, so unsupported filters are reported
+	bad := &conf.EqualizerSettings{Filters: []conf.EqualizerFilter{{Type: "Unknown", Passes: 1}}}
+	assert.Error(t, ApplyEqualizerToSamples(tone(100), bad, sampleRate))
+}
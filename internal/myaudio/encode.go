@@ -41,6 +41,12 @@ func recordFileOperationErrorWithValidation(operation, format, errorType, valida
 
 // SavePCMDataToWAV saves the given PCM data as a WAV file at the specified filePath.
 func SavePCMDataToWAV(filePath string, pcmData []byte) error {
+	return SavePCMDataToWAVWithMetadata(filePath, pcmData, nil)
+}
+
+// SavePCMDataToWAVWithMetadata saves PCM data like SavePCMDataToWAV and appends
+// the detection metadata as LIST/INFO and GUANO chunks. A nil meta writes no tags.
+func SavePCMDataToWAVWithMetadata(filePath string, pcmData []byte, meta *ClipMetadata) error {
 	log := GetLogger()
 	start := time.Now()
 
@@ -166,6 +172,21 @@ func SavePCMDataToWAV(filePath string, pcmData []byte) error {
 		return recordFileOperationError("save_wav", "wav", "encoder_close_failed", enhancedErr)
 	}
 
+	if meta != nil {
+		bytesPerSecond := conf.SampleRate * conf.NumChannels * conf.BitDepth / 8
+		length := time.Duration(len(pcmData)) * time.Second / time.Duration(bytesPerSecond)
+		if err := appendWAVMetadata(outFile, meta, length); err != nil {
+			enhancedErr := errors.New(err).
+				Component("myaudio").
+				Category(errors.CategoryFileIO).
+				Context("operation", "save_pcm_to_wav").
+				Context("file_operation", "write_metadata").
+				Build()
+
+			return recordFileOperationError("save_wav", "wav", "metadata_write_failed", enhancedErr)
+		}
+	}
+
 	// Record successful operation
 	if fileMetrics != nil {
 		duration := time.Since(start).Seconds()
@@ -14,3 +14,11 @@ var (
 		Context("operation", "process_sound_level_data").
 		Build()
 )
+
+// Calibration errors
+var (
+	// ErrCalibrationInProgress is returned when a calibration tone is already being measured at a source
+	ErrCalibrationInProgress = errors.Newf("calibration measurement already in progress").Component("myaudio").Category(errors.CategoryConflict).Build()
+	// ErrCalibrationToneNotFound is returned when the measured audio is not a steady 1 kHz reference tone
+	ErrCalibrationToneNotFound = errors.Newf("no steady 1 kHz calibration tone found").Component("myaudio").Category(errors.CategoryValidation).Build()
+)
@@ -0,0 +1,339 @@
+// failover.go: switching analysis between the sources of failover groups
+package myaudio
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/events"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// failoverCheckInterval is how often failover groups are re-evaluated.
+const failoverCheckInterval = 5 * time.Second
+
+// failoverMember is a registered source of a failover group. Its audio
+// timestamps are updated from the capture hot path.
+type failoverMember struct {
+	sourceID     string
+	timeout      time.Duration
+	lastAudio    atomic.Int64 // unix nanoseconds of the latest audio, 0 after a failure
+	healthySince atomic.Int64 // unix nanoseconds of the first audio after the latest failure
+	analyzed     atomic.Bool
+	resumed      atomic.Bool // analyzed again after standby, carryover audio not yet discarded
+}
+
+// newFailoverMember creates a member that counts as delivering audio since
+// now, giving the source one timeout to start.
+func newFailoverMember(sourceID string, timeout time.Duration, now time.Time) *failoverMember {
+	m := &failoverMember{sourceID: sourceID, timeout: timeout}
+	m.lastAudio.Store(now.UnixNano())
+	m.healthySince.Store(now.UnixNano())
+	m.analyzed.Store(true)
+	return m
+}
+
+// observe records audio from the source at now.
+func (m *failoverMember) observe(now time.Time) {
+	nanos := now.UnixNano()
+	if previous := m.lastAudio.Swap(nanos); nanos-previous > int64(m.timeout) {
+		m.healthySince.Store(nanos)
+	}
+}
+
+// fail marks the source as failed, without waiting for the timeout.
+func (m *failoverMember) fail() {
+	m.lastAudio.Store(0)
+}
+
+// healthy reports whether the source delivered audio within its timeout.
+func (m *failoverMember) healthy(now time.Time) bool {
+	return now.UnixNano()-m.lastAudio.Load() <= int64(m.timeout)
+}
+
+// healthyFor returns how long the source has been delivering audio.
+func (m *failoverMember) healthyFor(now time.Time) time.Duration {
+	return time.Duration(now.UnixNano() - m.healthySince.Load())
+}
+
+// failoverGroup is the runtime state of a configured failover group.
+type failoverGroup struct {
+	settings conf.FailoverGroupSettings
+	members  []*failoverMember // registered sources in priority order
+	active   *failoverMember   // source being analyzed
+}
+
+// evaluate selects the source to analyze at now: the active source while it
+// delivers audio, otherwise the first source by priority that does, and a
+// higher priority source once it has delivered audio for the recovery period.
+// When no source delivers audio the active source is kept. It returns the
+// previously active source and the newly selected one.
+func (g *failoverGroup) evaluate(now time.Time) (from, to *failoverMember) {
+	if len(g.members) == 0 {
+		return nil, nil
+	}
+
+	target := g.active
+	if target == nil || !target.healthy(now) {
+		target = nil
+		for _, m := range g.members {
+			if m.healthy(now) {
+				target = m
+				break
+			}
+		}
+		if target == nil {
+			target = g.active
+		}
+		if target == nil {
+			target = g.members[0]
+		}
+	} else {
+		recovery := g.settings.RecoveryDuration()
+		for _, m := range g.members {
+			if m == g.active {
+				break
+			}
+			if m.healthy(now) && m.healthyFor(now) >= recovery {
+				target = m
+				break
+			}
+		}
+	}
+
+	for _, m := range g.members {
+		if wasAnalyzed := m.analyzed.Swap(m == target); m == target && !wasAnalyzed {
+			m.resumed.Store(true)
+		}
+	}
+	from, g.active = g.active, target
+	return from, target
+}
+
+// priorityOf returns the position of m in the group, -1 if it is not a member.
+func (g *failoverGroup) priorityOf(m *failoverMember) int {
+	for i, member := range g.members {
+		if member == m {
+			return i
+		}
+	}
+	return -1
+}
+
+// Failover group state, rebuilt from the settings on every check
+var (
+	failoverGroups  = make(map[string]*failoverGroup)  // keyed by group name
+	failoverMembers = make(map[string]*failoverMember) // keyed by source ID
+	failoverMu      sync.RWMutex
+	failoverEnabled atomic.Bool // whether any source is in a failover group
+)
+
+// failoverAnalyzes records audio from a source and reports whether it should
+// be analyzed. Sources outside failover groups are always analyzed, standby
+// sources of a group are captured but not analyzed.
+func failoverAnalyzes(sourceID string, now time.Time) bool {
+	if !failoverEnabled.Load() {
+		return true
+	}
+	failoverMu.RLock()
+	m := failoverMembers[sourceID]
+	failoverMu.RUnlock()
+	if m == nil {
+		return true
+	}
+	m.observe(now)
+	if !m.analyzed.Load() {
+		return false
+	}
+	if m.resumed.CompareAndSwap(true, false) {
+		// Audio kept from before standby does not continue the new audio
+		discardAnalysisCarryover(sourceID)
+	}
+	return true
+}
+
+// failFailoverSources marks sources as failed, such as devices that failed to
+// start, so that their groups fail over on the next check.
+func failFailoverSources(sourceIDs ...string) {
+	if !failoverEnabled.Load() {
+		return
+	}
+	failoverMu.RLock()
+	defer failoverMu.RUnlock()
+	for _, sourceID := range sourceIDs {
+		if m := failoverMembers[sourceID]; m != nil {
+			m.fail()
+		}
+	}
+}
+
+// refreshFailoverGroups rebuilds the failover groups from settings, resolving
+// group entries to source IDs with resolve. Members keep their state across
+// refreshes; sources not yet registered are left out until they are.
+// The caller must hold failoverMu.
+func refreshFailoverGroups(settings []conf.FailoverGroupSettings, resolve func(entry string) (string, bool), now time.Time) {
+	groups := make(map[string]*failoverGroup, len(settings))
+	members := make(map[string]*failoverMember)
+	for i := range settings {
+		group := &failoverGroup{settings: settings[i]}
+		if previous := failoverGroups[settings[i].Name]; previous != nil {
+			group.active = previous.active
+		}
+		timeout := settings[i].TimeoutDuration()
+		for _, entry := range settings[i].Sources {
+			sourceID, ok := resolve(strings.TrimSpace(entry))
+			if !ok || members[sourceID] != nil {
+				continue
+			}
+			m := failoverMembers[sourceID]
+			if m == nil || m.timeout != timeout {
+				m = newFailoverMember(sourceID, timeout, now)
+			}
+			members[sourceID] = m
+			group.members = append(group.members, m)
+		}
+		if group.priorityOf(group.active) < 0 {
+			group.active = nil
+		}
+		groups[settings[i].Name] = group
+	}
+
+	failoverGroups = groups
+	failoverMembers = members
+	failoverEnabled.Store(len(members) > 0)
+}
+
+// resolveFailoverSource returns the ID of the registered source named by a
+// failover group or archive source entry: its connection string, ID, display
+// name or sanitized connection string.
+func resolveFailoverSource(entry string) (string, bool) {
+	registry := GetRegistry()
+	if registry == nil || entry == "" {
+		return "", false
+	}
+	if source, ok := registry.GetSourceByConnection(entry); ok {
+		return source.ID, true
+	}
+	if source, ok := registry.GetSourceByID(entry); ok {
+		return source.ID, true
+	}
+	for _, source := range registry.ListSources() {
+		if strings.EqualFold(entry, source.DisplayName) || entry == source.SafeString {
+			return source.ID, true
+		}
+	}
+	return "", false
+}
+
+// checkFailoverGroups refreshes the failover groups from the current settings,
+// evaluates them and reports switches.
+func checkFailoverGroups(now time.Time) {
+	var settings []conf.FailoverGroupSettings
+	if s := conf.GetSettings(); s != nil {
+		settings = s.Realtime.FailoverGroups
+	}
+	failoverMu.Lock()
+	refreshFailoverGroups(settings, resolveFailoverSource, now)
+	type failoverSwitch struct {
+		group    *failoverGroup
+		from, to *failoverMember
+	}
+	var switches []failoverSwitch
+	for _, group := range failoverGroups {
+		if from, to := group.evaluate(now); from != to {
+			switches = append(switches, failoverSwitch{group, from, to})
+		}
+	}
+	failoverMu.Unlock()
+
+	for _, s := range switches {
+		reportFailoverSwitch(s.group, s.from, s.to, now)
+	}
+}
+
+// reportFailoverSwitch logs a switch of the analyzed source of a group and
+// publishes it on the event bus, where the alerting bridge records it in the
+// alert history. The initial selection of a source is only logged.
+func reportFailoverSwitch(group *failoverGroup, from, to *failoverMember, now time.Time) {
+	log := GetLogger()
+	toName := failoverSourceName(to.sourceID)
+	if from == nil {
+		log.Debug("failover group analyzing source",
+			logger.String("group", group.settings.Name),
+			logger.String("source_id", to.sourceID),
+			logger.String("source_name", toName),
+			logger.String("operation", "source_failover"))
+		return
+	}
+
+	fromName := failoverSourceName(from.sourceID)
+	recovery := group.priorityOf(to) < group.priorityOf(from)
+	var message string
+	if recovery {
+		message = fmt.Sprintf("%s delivered audio for %s, analyzing it instead of %s",
+			toName, to.healthyFor(now).Round(time.Second), fromName)
+		log.Info("failover group switched back to recovered source",
+			logger.String("group", group.settings.Name),
+			logger.String("from_source", fromName),
+			logger.String("to_source", toName),
+			logger.String("operation", "source_failover"))
+	} else {
+		message = fmt.Sprintf("no audio from %s for %s, analyzing %s instead",
+			fromName, group.settings.TimeoutDuration(), toName)
+		log.Warn("failover group switched to backup source",
+			logger.String("group", group.settings.Name),
+			logger.String("from_source", fromName),
+			logger.String("to_source", toName),
+			logger.String("operation", "source_failover"))
+	}
+
+	if eventBus := events.GetEventBus(); eventBus != nil {
+		eventBus.TryPublishSourceFailover(events.NewSourceFailoverEvent(events.SourceFailoverEventData{
+			GroupName:      group.settings.Name,
+			FromSourceID:   from.sourceID,
+			FromSourceName: fromName,
+			ToSourceID:     to.sourceID,
+			ToSourceName:   toName,
+			Recovery:       recovery,
+			Message:        message,
+		}))
+	}
+}
+
+// failoverSourceName returns the display name of a source, or its ID if it is
+// not registered.
+func failoverSourceName(sourceID string) string {
+	if registry := GetRegistry(); registry != nil {
+		if source, ok := registry.GetSourceByID(sourceID); ok {
+			return source.DisplayName
+		}
+	}
+	return sourceID
+}
+
+// StartFailoverMonitor evaluates the configured failover groups until
+// quitChan is closed, after which all sources are analyzed again.
+func StartFailoverMonitor(wg *sync.WaitGroup, quitChan chan struct{}) {
+	wg.Go(func() {
+		defer func() {
+			failoverMu.Lock()
+			refreshFailoverGroups(nil, resolveFailoverSource, time.Now())
+			failoverMu.Unlock()
+		}()
+
+		ticker := time.NewTicker(failoverCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-quitChan:
+				return
+			case now := <-ticker.C:
+				checkFailoverGroups(now)
+			}
+		}
+	})
+}
@@ -0,0 +1,183 @@
+package myaudio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestFailoverGroupEvaluate(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 6, 15, 5, 0, 0, 0, time.UTC)
+	timeout := 15 * time.Second
+	primary := newFailoverMember("mic", timeout, start)
+	backup := newFailoverMember("cam", timeout, start)
+	group := &failoverGroup{
+		settings: conf.FailoverGroupSettings{Name: "Main", Sources: []string{"mic", "cam"}, Timeout: 15, Recovery: 60},
+		members:  []*failoverMember{primary, backup},
+	}
+	feed := func(m *failoverMember, from, to time.Time) {
+		for at := from; !at.After(to); at = at.Add(time.Second) {
+			m.observe(at)
+		}
+	}
+
+	// The primary source is analyzed first, with one timeout to deliver audio
+	from, to := group.evaluate(start)
+	assert.Nil(t, from)
+	assert.Same(t, primary, to)
+	assert.True(t, primary.analyzed.Load())
+	assert.False(t, backup.analyzed.Load())
+
+	// Both deliver audio, the primary stays active
+	feed(primary, start, start.Add(10*time.Second))
+	feed(backup, start, start.Add(30*time.Second))
+	from, to = group.evaluate(start.Add(20 * time.Second))
+	assert.Same(t, from, to)
+
+	// The primary stopped for longer than the timeout
+	from, to = group.evaluate(start.Add(30 * time.Second))
+	assert.Same(t, primary, from)
+	assert.Same(t, backup, to)
+	assert.False(t, primary.analyzed.Load())
+	assert.True(t, backup.analyzed.Load())
+
+	// The primary recovers, but must deliver audio for the recovery period first
+	feed(primary, start.Add(40*time.Second), start.Add(120*time.Second))
+	feed(backup, start.Add(30*time.Second), start.Add(120*time.Second))
+	from, to = group.evaluate(start.Add(60 * time.Second))
+	assert.Same(t, backup, to)
+	assert.Same(t, from, to)
+	from, to = group.evaluate(start.Add(100 * time.Second))
+	assert.Same(t, backup, from)
+	assert.Same(t, primary, to)
+	assert.Equal(t, 0, group.priorityOf(to))
+
+	// With no source delivering audio, the active source is kept
+	from, to = group.evaluate(start.Add(10 * time.Minute))
+	assert.Same(t, primary, from)
+	assert.Same(t, primary, to)
+
+	// A failed source fails over without waiting for the timeout
+	feed(primary, start.Add(11*time.Minute), start.Add(12*time.Minute))
+	feed(backup, start.Add(11*time.Minute), start.Add(12*time.Minute))
+	primary.fail()
+	_, to = group.evaluate(start.Add(12 * time.Minute))
+	assert.Same(t, backup, to)
+}
+
+//nolint:paralleltest // modifies the global failover state
+func TestFailoverAnalyzes(t *testing.T) {
+	now := time.Now()
+	resolve := func(entry string) (string, bool) {
+		if entry == "Unregistered" {
+			return "", false
+		}
+		return "id-" + entry, true
+	}
+	settings := []conf.FailoverGroupSettings{
+		{Name: "Main", Sources: []string{"Mic", "Unregistered", "Cam"}},
+	}
+
+	failoverMu.Lock()
+	refreshFailoverGroups(settings, resolve, now)
+	require.Len(t, failoverGroups["Main"].members, 2)
+	failoverGroups["Main"].evaluate(now)
+	failoverMu.Unlock()
+	t.Cleanup(func() {
+		failoverMu.Lock()
+		refreshFailoverGroups(nil, resolve, time.Now())
+		failoverMu.Unlock()
+	})
+
+	assert.True(t, failoverAnalyzes("id-Mic", now), "primary source is analyzed")
+	assert.False(t, failoverAnalyzes("id-Cam", now), "standby source is not analyzed")
+	assert.True(t, failoverAnalyzes("other", now), "sources outside groups are analyzed")
+
+	// Members keep their state across refreshes
+	primary := failoverMembers["id-Mic"]
+	failoverMu.Lock()
+	refreshFailoverGroups(settings, resolve, now.Add(time.Minute))
+	failoverMu.Unlock()
+	assert.Same(t, primary, failoverMembers["id-Mic"])
+	assert.Same(t, primary, failoverGroups["Main"].active)
+
+	// Removing the groups analyzes all sources again
+	failoverMu.Lock()
+	refreshFailoverGroups(nil, resolve, now)
+	failoverMu.Unlock()
+	assert.True(t, failoverAnalyzes("id-Cam", now))
+}
+
+//nolint:paralleltest // modifies the global failover and analysis buffer state
+func TestFailoverStandbyKeepsAudioTime(t *testing.T) {
+	const sourceID = "test_failover_backup"
+	resolve := func(entry string) (string, bool) {
+		if entry == "Backup" {
+			return sourceID, true
+		}
+		return "id-" + entry, true
+	}
+	settings := []conf.FailoverGroupSettings{
+		{Name: "Main", Sources: []string{"Mic", "Backup"}},
+	}
+
+	require.NoError(t, AllocateAnalysisBuffer(conf.BufferSize*3, sourceID))
+	failoverMu.Lock()
+	refreshFailoverGroups(settings, resolve, time.Now())
+	failoverGroups["Main"].evaluate(time.Now())
+	failoverMu.Unlock()
+	t.Cleanup(func() {
+		failoverMu.Lock()
+		refreshFailoverGroups(nil, resolve, time.Now())
+		failoverMu.Unlock()
+		_ = RemoveAnalysisBuffer(sourceID)
+	})
+
+	// stream delivers live audio like the FFmpeg stream handler
+	base := time.Date(2026, 6, 15, 5, 0, 0, 0, time.UTC)
+	sent := 0
+	stream := func(d time.Duration) {
+		for range int(d / testTimelineChunk) {
+			observeStreamTimeline(sourceID, testTimelineBytes, base.Add(time.Duration(sent)*testTimelineChunk))
+			require.NoError(t, WriteToAnalysisBuffer(sourceID, make([]byte, testTimelineBytes)))
+			sent++
+		}
+	}
+	readChunk := func() (int64, bool) {
+		for range 4 {
+			data, end, err := readAnalysisChunk(sourceID)
+			require.NoError(t, err)
+			if data != nil {
+				return end, true
+			}
+		}
+		return 0, false
+	}
+
+	// The backup is on standby while the primary is analyzed
+	stream(time.Minute)
+	_, ok := readChunk()
+	assert.False(t, ok, "standby audio is not analyzed")
+
+	// The primary fails and the backup is analyzed from its current audio
+	failoverMu.Lock()
+	failoverMembers["id-Mic"].fail()
+	_, to := failoverGroups["Main"].evaluate(time.Now())
+	failoverMu.Unlock()
+	require.Equal(t, sourceID, to.sourceID)
+	resumed := base.Add(time.Duration(sent) * testTimelineChunk)
+	stream(8 * time.Second)
+
+	end, ok := readChunk()
+	require.True(t, ok)
+	captured, ok := streamAudioTime(sourceID, end)
+	require.True(t, ok)
+	chunk := time.Duration(float64(conf.BufferSize) / testTimelineRate * float64(time.Second))
+	assert.WithinDuration(t, resumed.Add(chunk), captured, 2*testTimelineChunk,
+		"detections are timestamped from the audio after standby")
+}
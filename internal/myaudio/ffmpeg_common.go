@@ -123,3 +123,34 @@ func GetAudioDuration(ctx context.Context, audioPath string) (float64, error) {
 
 	return duration, nil
 }
+
+// decodeAudioFileWithFFmpeg decodes length of audio starting at offset in an
+// audio file to PCM in the capture format. A zero length decodes to the end
+// of the file.
+func decodeAudioFileWithFFmpeg(ctx context.Context, ffmpegPath, path string, offset, length time.Duration) ([]byte, error) {
+	if err := validateFFmpegPath(ffmpegPath); err != nil {
+		return nil, err
+	}
+
+	sampleRate, numChannels, format := getFFmpegFormat(conf.SampleRate, conf.NumChannels, conf.BitDepth)
+	args := []string{"-hide_banner", "-loglevel", "error"}
+	if offset > 0 {
+		args = append(args, "-ss", fmt.Sprintf("%.3f", offset.Seconds()))
+	}
+	if length > 0 {
+		args = append(args, "-t", fmt.Sprintf("%.3f", length.Seconds()))
+	}
+	args = append(args,
+		"-i", path,
+		"-f", format, "-ar", sampleRate, "-ac", numChannels,
+		"pipe:1",
+	)
+	cmd := exec.CommandContext(ctx, ffmpegPath, args...) //nolint:gosec // G204: ffmpegPath is from validated settings, args built internally
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("ffmpeg: %w, stderr: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.Bytes(), nil
+}
@@ -36,6 +36,12 @@ const (
 // outputPath is full path with audio file name and extension based on format
 // pcmData is the PCM data to export
 func ExportAudioWithFFmpeg(pcmData []byte, outputPath string, settings *conf.AudioSettings) error {
+	return ExportAudioWithFFmpegMetadata(pcmData, outputPath, settings, nil)
+}
+
+// ExportAudioWithFFmpegMetadata exports PCM data like ExportAudioWithFFmpeg and
+// writes the detection metadata as tags. A nil meta writes no tags.
+func ExportAudioWithFFmpegMetadata(pcmData []byte, outputPath string, settings *conf.AudioSettings, meta *ClipMetadata) error {
 	start := time.Now()
 
 	// Validate inputs
@@ -180,7 +186,7 @@ func ExportAudioWithFFmpeg(pcmData []byte, outputPath string, settings *conf.Aud
 	}
 
 	// Run the FFmpeg command to process the audio
-	if err := runFFmpegCommand(settings.FfmpegPath, pcmData, tempFilePath, settings); err != nil {
+	if err := runFFmpegCommand(settings.FfmpegPath, pcmData, tempFilePath, settings, meta); err != nil {
 		enhancedErr := errors.New(err).
 			Component("myaudio").
 			Category(errors.CategorySystem).
@@ -343,10 +349,10 @@ func finalizeOutput(tempFilePath string) error {
 
 // runFFmpegCommand executes the FFmpeg command to process the audio
 // This version includes a context timeout to prevent hangs.
-func runFFmpegCommand(ffmpegPath string, pcmData []byte, tempFilePath string, settings *conf.AudioSettings) error {
+func runFFmpegCommand(ffmpegPath string, pcmData []byte, tempFilePath string, settings *conf.AudioSettings, meta *ClipMetadata) error {
 	log := GetLogger()
 	// Build the FFmpeg command arguments
-	args := buildFFmpegArgs(tempFilePath, settings)
+	args := buildFFmpegArgs(tempFilePath, settings, meta)
 
 	// Create a context with a timeout (e.g., 30 seconds)
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -427,7 +433,7 @@ func runFFmpegCommand(ffmpegPath string, pcmData []byte, tempFilePath string, se
 }
 
 // buildFFmpegArgs constructs the arguments for the FFmpeg command
-func buildFFmpegArgs(tempFilePath string, settings *conf.AudioSettings) []string {
+func buildFFmpegArgs(tempFilePath string, settings *conf.AudioSettings, meta *ClipMetadata) []string {
 	ffmpegSampleRate, ffmpegNumChannels, ffmpegFormat := getFFmpegFormat(conf.SampleRate, conf.NumChannels, conf.BitDepth)
 
 	outputEncoder := getEncoder(settings.Export.Type)
@@ -448,6 +454,9 @@ func buildFFmpegArgs(tempFilePath string, settings *conf.AudioSettings) []string
 		args = append(args, "-af", audioFilter)
 	}
 
+	// Add detection metadata tags (ID3 for MP3, Vorbis comments for FLAC and Opus)
+	args = append(args, meta.FFmpegArgs()...)
+
 	// Add output encoding settings
 	args = append(args,
 		"-c:a", outputEncoder,
@@ -183,8 +183,14 @@ func CaptureAudioRTSP(url, transport string, wg *sync.WaitGroup, quitChan <-chan
 	// This is done once across all streams via sync.Once
 	startMonitoringOnce(manager, unifiedAudioChan)
 
-	// Start the stream
-	if err := manager.StartStream(url, transport, unifiedAudioChan); err != nil {
+	// Start the stream unless it is outside its recording schedule, in which
+	// case the monitoring schedule check starts it when its window opens
+	if state := evaluateSourceSchedule(url, streamSchedule(url), time.Now()); !state.Active {
+		getIntegrationLogger().Info("stream is idle outside its recording schedule",
+			logger.String("url", privacy.SanitizeStreamUrl(url)),
+			logger.String("next_start", state.NextChange.Format(time.RFC3339)),
+			logger.String("operation", "capture_audio_rtsp"))
+	} else if err := manager.StartStream(url, transport, unifiedAudioChan); err != nil {
 		getIntegrationLogger().Error("failed to start stream",
 			logger.String("url", privacy.SanitizeStreamUrl(url)),
 			logger.Error(err),
@@ -198,7 +204,10 @@ func CaptureAudioRTSP(url, transport string, wg *sync.WaitGroup, quitChan <-chan
 		for {
 			select {
 			case <-quitChan:
-				// Stop the stream
+				// Stop the stream, an idle scheduled stream is not running
+				if !manager.isStreamRunning(url) {
+					return
+				}
 				if err := manager.StopStream(url); err != nil {
 					getIntegrationLogger().Warn("failed to stop stream",
 						logger.String("url", privacy.SanitizeStreamUrl(url)),
@@ -208,6 +217,9 @@ func CaptureAudioRTSP(url, transport string, wg *sync.WaitGroup, quitChan <-chan
 				return
 			case <-restartChan:
 				// Restart the stream
+				if !manager.isStreamRunning(url) {
+					continue
+				}
 				if err := manager.RestartStream(url); err != nil {
 					getIntegrationLogger().Warn("failed to restart stream",
 						logger.String("url", privacy.SanitizeStreamUrl(url)),
@@ -219,6 +231,29 @@ func CaptureAudioRTSP(url, transport string, wg *sync.WaitGroup, quitChan <-chan
 	})
 }
 
+// streamSchedule returns the recording schedule of the configured stream
+// with url, or an empty schedule if the stream is not configured.
+func streamSchedule(url string) *conf.ScheduleSettings {
+	settings := conf.Setting()
+	for i := range settings.Realtime.RTSP.Streams {
+		if settings.Realtime.RTSP.Streams[i].URL == url {
+			return &settings.Realtime.RTSP.Streams[i].Schedule
+		}
+	}
+	return &conf.ScheduleSettings{}
+}
+
+// streamSRTSettings returns the SRT options of the configured stream with the URL.
+func streamSRTSettings(url string) conf.SRTSettings {
+	settings := conf.Setting()
+	for i := range settings.Realtime.RTSP.Streams {
+		if settings.Realtime.RTSP.Streams[i].URL == url {
+			return settings.Realtime.RTSP.Streams[i].SRT
+		}
+	}
+	return conf.SRTSettings{}
+}
+
 // SyncStreamsWithConfig synchronizes running streams with configuration
 // This is called when configuration changes to start/stop streams as needed
 func SyncStreamsWithConfig(audioChan chan UnifiedAudioData) error {
@@ -266,10 +266,22 @@ func (m *FFmpegManager) HealthCheck() map[string]StreamHealth {
 func (m *FFmpegManager) SyncWithConfig(audioChan chan UnifiedAudioData) error {
 	settings := conf.Setting()
 	configuredURLs := make(map[string]string) // url -> transport
+	configuredSRT := make(map[string]conf.SRTSettings)
 
-	// Build map of configured URLs with their per-stream transport settings
-	for _, stream := range settings.Realtime.RTSP.Streams {
+	// Build map of configured URLs with their per-stream transport settings.
+	// Streams outside their recording schedule are left out so they idle.
+	idleURLs := make(map[string]bool)
+	now := time.Now()
+	for i := range settings.Realtime.RTSP.Streams {
+		stream := &settings.Realtime.RTSP.Streams[i]
+		if !evaluateSourceSchedule(stream.URL, &stream.Schedule, now).Active {
+			idleURLs[stream.URL] = true
+			continue
+		}
 		configuredURLs[stream.URL] = stream.Transport
+		if stream.Type == conf.StreamTypeSRT {
+			configuredSRT[stream.URL] = stream.SRT
+		}
 	}
 
 	// Check for transport changes in existing streams
@@ -354,6 +366,24 @@ func (m *FFmpegManager) SyncWithConfig(audioChan chan UnifiedAudioData) error {
 			logger.String("operation", "sync_transport_change_complete"))
 	}
 
+	// Restart SRT streams whose latency or passphrase changed, the options are
+	// applied when the process starts again
+	m.streamsMu.RLock()
+	var srtChanged []*FFmpegStream
+	for url, srt := range configuredSRT {
+		if stream, running := m.streams[url]; running && stream.srtSettingsChanged(srt) {
+			srtChanged = append(srtChanged, stream)
+		}
+	}
+	m.streamsMu.RUnlock()
+	for _, stream := range srtChanged {
+		getManagerLogger().Info("SRT settings changed, restarting stream",
+			logger.String("url", privacy.SanitizeStreamUrl(stream.source.SafeString)),
+			logger.String("component", "ffmpeg-manager"),
+			logger.String("operation", "sync_srt_change"))
+		stream.Restart(true)
+	}
+
 	// Stop streams that are no longer configured
 	m.streamsMu.RLock()
 	toStop := []string{}
@@ -365,6 +395,14 @@ func (m *FFmpegManager) SyncWithConfig(audioChan chan UnifiedAudioData) error {
 	m.streamsMu.RUnlock()
 
 	for _, url := range toStop {
+		if idleURLs[url] {
+			getManagerLogger().Info("stopping stream outside its recording schedule",
+				logger.String("url", privacy.SanitizeStreamUrl(url)),
+				logger.String("component", "ffmpeg-manager"),
+				logger.String("operation", "sync_schedule"))
+		} else {
+			clearScheduleState(url)
+		}
 		if err := m.StopStream(url); err != nil {
 			getManagerLogger().Warn("failed to stop unconfigured stream",
 				logger.String("url", privacy.SanitizeStreamUrl(url)),
@@ -411,7 +449,7 @@ func (m *FFmpegManager) StartMonitoring(interval time.Duration, audioChan chan U
 	m.audioChan = audioChan
 	m.audioChanMu.Unlock()
 
-	m.wg.Add(2) // Starting 2 goroutines: health check + watchdog
+	m.wg.Add(3) // Starting 3 goroutines: health check + watchdog + schedule
 
 	// Health check goroutine (existing functionality)
 	go func() {
@@ -461,6 +499,48 @@ func (m *FFmpegManager) StartMonitoring(interval time.Duration, audioChan chan U
 			}
 		}
 	}()
+
+	// Schedule goroutine - idles and resumes streams at recording window boundaries
+	go func() {
+		defer m.wg.Done()
+		ticker := time.NewTicker(scheduleCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.ctx.Done():
+				return
+			case <-ticker.C:
+				if !hasScheduledStreams(conf.Setting()) {
+					continue
+				}
+				if err := m.SyncWithConfig(audioChan); err != nil {
+					getManagerLogger().Warn("failed to apply stream recording schedules",
+						logger.Error(err),
+						logger.String("component", "ffmpeg-manager"),
+						logger.String("operation", "apply_schedules"))
+				}
+			}
+		}
+	}()
+}
+
+// hasScheduledStreams reports whether any configured stream has a recording schedule.
+func hasScheduledStreams(settings *conf.Settings) bool {
+	for i := range settings.Realtime.RTSP.Streams {
+		if settings.Realtime.RTSP.Streams[i].Schedule.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+// isStreamRunning reports whether the manager has a stream for url.
+func (m *FFmpegManager) isStreamRunning(url string) bool {
+	m.streamsMu.RLock()
+	defer m.streamsMu.RUnlock()
+	_, exists := m.streams[url]
+	return exists
 }
 
 // checkStreamHealth checks health of all streams
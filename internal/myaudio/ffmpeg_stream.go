@@ -301,6 +301,15 @@ type StreamHealth struct {
 	// Process state information
 	ProcessState ProcessState      // Current process state
 	StateHistory []StateTransition // Recent state transitions (last 10 for health checks)
+	NativeClient bool              // Audio is read by the in-process RTSP client instead of FFmpeg
+	Metadata     *StreamMetadata   // Icecast/Shoutcast station metadata, nil if the server sends none
+	AudioQuality *AudioDiagnostics // Audio quality of the last diagnostics interval, nil before the first
+	// Audio timeline, see StreamTimelineStats
+	Latency      time.Duration // steady delay of the audio behind the wall clock
+	DriftPPM     float64       // clock drift in parts per million, positive when the stream runs slow
+	TotalGapTime time.Duration // audio lost in gaps
+	GapCount     int
+	BurstCount   int // stalls recovered by a burst of buffered audio
 	// FFmpeg error diagnostics
 	// Note: Internally stores up to 100 errors for analysis, but only exposes the 10 most recent
 	LastErrorContext *ErrorContext   // Most recent error detected
@@ -313,6 +322,7 @@ type FFmpegStream struct {
 	source    *AudioSource
 	transport string
 	audioChan chan UnifiedAudioData
+	srt       conf.SRTSettings // SRT options of the last started process, protected by cmdMu
 
 	// Process management
 	cmd      *exec.Cmd
@@ -354,6 +364,13 @@ type FFmpegStream struct {
 	// Process timing
 	processStartTime time.Time
 
+	// Native RTSP session used instead of an FFmpeg process, nil when FFmpeg runs
+	native *rtspAudioStream
+	// nativeFallback is set once the native client cannot read the stream
+	nativeFallback bool
+	// nativeFailures counts consecutive failed native session starts
+	nativeFailures int
+
 	// Backoff for restarts
 	backoffDuration time.Duration
 	maxBackoff      time.Duration
@@ -651,6 +668,11 @@ func (s *FFmpegStream) Run(parentCtx context.Context) {
 		s.ctx, s.cancel = context.WithCancelCause(parentCtx)
 	}()
 
+	// Icecast and Shoutcast servers describe the station in ICY metadata
+	if s.source.Type == SourceTypeHTTP {
+		go s.pollIcyMetadata(s.ctx)
+	}
+
 	defer func() {
 		s.cancelMu.Lock()
 		defer s.cancelMu.Unlock()
@@ -800,8 +822,13 @@ func (s *FFmpegStream) Run(parentCtx context.Context) {
 	}
 }
 
-// startProcess starts the FFmpeg process
+// startProcess starts the FFmpeg process, or a native RTSP session when
+// enabled and the stream's audio codec is supported
 func (s *FFmpegStream) startProcess() error {
+	if started, err := s.startNativeSession(); started || err != nil {
+		return err
+	}
+
 	s.cmdMu.Lock()
 	defer s.cmdMu.Unlock()
 
@@ -909,6 +936,7 @@ func (s *FFmpegStream) startProcess() error {
 
 	// Record start time for runtime calculation
 	s.processStartTime = time.Now()
+	markStreamRestart(s.source.ID)
 
 	// Debug log process details
 	if conf.Setting().Debug {
@@ -944,10 +972,88 @@ func (s *FFmpegStream) startProcess() error {
 	return nil
 }
 
+// maxNativeSessionFailures is the number of consecutive failed native session
+// starts after which a stream falls back to FFmpeg.
+const maxNativeSessionFailures = 3
+
+// startNativeSession starts reading the stream with the in-process RTSP
+// client. started is false when the native client is disabled or cannot
+// read the stream, in which case FFmpeg is used. Streams fall back to FFmpeg
+// when the server refuses a request or after maxNativeSessionFailures
+// consecutive failures.
+func (s *FFmpegStream) startNativeSession() (started bool, err error) {
+	if !conf.Setting().Realtime.RTSP.NativeClient || s.nativeFallback ||
+		s.source == nil || s.source.Type != SourceTypeRTSP {
+		return false, nil
+	}
+	if s.transport != "" && s.transport != "tcp" {
+		s.useFFmpegFallback(errRTSPTransportUnsupported)
+		return false, nil
+	}
+
+	connStr, err := s.source.GetConnectionString()
+	if err != nil || connStr == "" {
+		// Let the FFmpeg path report the invalid connection string
+		return false, nil
+	}
+
+	s.cancelMu.RLock()
+	ctx := s.ctx
+	s.cancelMu.RUnlock()
+
+	session, err := dialRTSPAudio(ctx, connStr, conf.SampleRate)
+	if err != nil {
+		if errors.Is(err, errRTSPCodecUnsupported) || errors.Is(err, errRTSPTransportUnsupported) ||
+			errors.Is(err, errRTSPRequestRefused) {
+			s.useFFmpegFallback(err)
+			return false, nil
+		}
+		s.nativeFailures++
+		if s.nativeFailures >= maxNativeSessionFailures && ctx.Err() == nil {
+			s.useFFmpegFallback(err)
+			return false, nil
+		}
+		return false, errors.Newf("failed to start native RTSP session: %w", err).
+			Category(errors.CategoryRTSP).
+			Component("ffmpeg-stream").
+			Context("operation", "start_native_session").
+			Context("url", privacy.SanitizeStreamUrl(s.source.SafeString)).
+			Build()
+	}
+
+	s.nativeFailures = 0
+	s.cmdMu.Lock()
+	s.native = session
+	s.stdout = session
+	s.processStartTime = time.Now()
+	markStreamRestart(s.source.ID)
+	s.cmdMu.Unlock()
+
+	getStreamLogger().Info("native RTSP session started",
+		logger.String("source_id", s.source.ID),
+		logger.String("url", privacy.SanitizeStreamUrl(s.source.SafeString)),
+		logger.String("codec", session.decoder.media.encoding),
+		logger.Int("clock_rate", session.decoder.media.clockRate),
+		logger.String("component", "ffmpeg-stream"),
+		logger.String("operation", "start_native_session"))
+	return true, nil
+}
+
+// useFFmpegFallback switches the stream to FFmpeg for the rest of its lifetime.
+func (s *FFmpegStream) useFFmpegFallback(reason error) {
+	s.nativeFallback = true
+	getStreamLogger().Info("native RTSP client cannot read stream, using FFmpeg",
+		logger.String("url", privacy.SanitizeStreamUrl(s.source.SafeString)),
+		logger.String("reason", reason.Error()),
+		logger.String("component", "ffmpeg-stream"),
+		logger.String("operation", "start_native_session"))
+}
+
 // buildFFmpegInputArgs constructs the FFmpeg input arguments for this stream.
 // RTSP-specific flags like -rtsp_transport are only added for RTSP streams;
-// applying them to HTTP/HLS/RTMP/UDP streams causes FFmpeg to exit immediately
-// with "Option rtsp_transport not found".
+// applying them to HTTP/HLS/RTMP/UDP/SRT streams causes FFmpeg to exit immediately
+// with "Option rtsp_transport not found". SRT streams get their latency and
+// passphrase options.
 func (s *FFmpegStream) buildFFmpegInputArgs(ffmpegParameters []string) []string {
 	args := []string{}
 
@@ -957,6 +1063,14 @@ func (s *FFmpegStream) buildFFmpegInputArgs(ffmpegParameters []string) []string
 		args = append(args, "-rtsp_transport", s.transport)
 	}
 
+	// SRT latency and passphrase come from the stream configuration
+	if s.source != nil && s.source.Type == SourceTypeSRT {
+		if connStr, err := s.source.GetConnectionString(); err == nil {
+			s.srt = streamSRTSettings(connStr)
+			args = append(args, srtInputArgs(s.srt)...)
+		}
+	}
+
 	// Check if user has already provided a timeout parameter
 	hasUserTimeout, userTimeoutValue := detectUserTimeout(ffmpegParameters)
 
@@ -1005,6 +1119,27 @@ func (s *FFmpegStream) buildFFmpegInputArgs(ffmpegParameters []string) []string
 	return args
 }
 
+// srtSettingsChanged reports whether the running process uses SRT options
+// other than srt. Streams without a started process report no change.
+func (s *FFmpegStream) srtSettingsChanged(srt conf.SRTSettings) bool {
+	s.cmdMu.Lock()
+	defer s.cmdMu.Unlock()
+	return !s.processStartTime.IsZero() && s.srt != srt
+}
+
+// srtInputArgs returns the FFmpeg options of an SRT input. FFmpeg takes the
+// latency in microseconds.
+func srtInputArgs(srt conf.SRTSettings) []string {
+	var args []string
+	if srt.Latency > 0 {
+		args = append(args, "-latency", strconv.Itoa(srt.Latency*1000))
+	}
+	if srt.Passphrase != "" {
+		args = append(args, "-passphrase", srt.Passphrase)
+	}
+	return args
+}
+
 // handleSilenceTimeout checks if stream has stopped producing data and triggers restart
 // Returns an error if silence timeout is exceeded, nil otherwise
 func (s *FFmpegStream) handleSilenceTimeout(startTime time.Time) error {
@@ -1352,6 +1487,17 @@ func (s *FFmpegStream) handleAudioData(data []byte) error {
 		}
 	}
 
+	// Place the audio on the stream timeline before buffering it, detections
+	// are timestamped from the timeline
+	if gap := observeStreamTimeline(s.source.ID, len(data), time.Now()); gap != nil {
+		getStreamLogger().Warn("stream audio gap detected",
+			logger.String("url", privacy.SanitizeStreamUrl(s.source.SafeString)),
+			logger.Float64("gap_seconds", gap.Duration.Seconds()),
+			logger.String("reason", gap.Reason),
+			logger.String("component", "ffmpeg-stream"),
+			logger.String("operation", "handle_audio_data"))
+	}
+
 	// Write to analysis buffer using source ID
 	if err := WriteToAnalysisBuffer(s.source.ID, data); err != nil {
 		return errors.Newf("failed to write to analysis buffer: %w", err).
@@ -1374,6 +1520,12 @@ func (s *FFmpegStream) handleAudioData(data []byte) error {
 			Build()
 	}
 
+	// Queue for continuous recording if enabled
+	WriteToArchive(s.source.ID, data)
+
+	// Check audio quality if enabled
+	observeAudioDiagnostics(s.source.ID, data)
+
 	// Broadcast to WebSocket clients using source ID
 	broadcastAudioData(s.source.ID, data)
 
@@ -1494,12 +1646,29 @@ func (s *FFmpegStream) cleanupProcess() {
 	if cmd != nil && cmd.Process != nil {
 		pid = cmd.Process.Pid
 	}
+	native := s.native
 	// Clear references so other observers see "no running process" immediately
 	s.cmd = nil
 	s.stdout = nil
+	s.native = nil
 	s.processStartTime = time.Time{} // Clear start time when tearing down
 	s.cmdMu.Unlock()
 
+	// A native RTSP session has no process, closing it tears down the session
+	if native != nil {
+		if err := native.Close(); err != nil && conf.Setting().Debug {
+			getStreamLogger().Debug("failed to close native RTSP session",
+				logger.String("url", privacy.SanitizeStreamUrl(s.source.SafeString)),
+				logger.Error(err),
+				logger.String("operation", "cleanup_process"))
+		}
+		getStreamLogger().Info("native RTSP session stopped",
+			logger.String("url", privacy.SanitizeStreamUrl(s.source.SafeString)),
+			logger.String("component", "ffmpeg-stream"),
+			logger.String("operation", "cleanup_process"))
+		return
+	}
+
 	// Check if there was actually a process to clean
 	if cmd == nil || cmd.Process == nil {
 		if conf.Setting().Debug {
@@ -1855,7 +2024,7 @@ func (s *FFmpegStream) GetProcessStartTime() time.Time {
 
 	// Only return start time if we have a truly running process (not exited)
 	// Check ProcessState to ensure the process hasn't exited
-	if s.cmd != nil && s.cmd.Process != nil && s.cmd.ProcessState == nil {
+	if s.native != nil || (s.cmd != nil && s.cmd.Process != nil && s.cmd.ProcessState == nil) {
 		return s.processStartTime
 	}
 	return time.Time{} // Zero time indicates no running process
@@ -1870,6 +2039,7 @@ func (s *FFmpegStream) GetHealth() StreamHealth {
 	if s.cmd != nil && s.cmd.Process != nil {
 		currentPID = s.cmd.Process.Pid
 	}
+	nativeClient := s.native != nil
 	s.cmdMu.Unlock()
 	s.lastDataMu.RLock()
 	lastData := s.lastDataTime
@@ -1967,6 +2137,14 @@ func (s *FFmpegStream) GetHealth() StreamHealth {
 	// Get last error context
 	lastError := s.getLastErrorContext()
 
+	// Station metadata recorded by the ICY metadata poller
+	var metadata *StreamMetadata
+	if registry := GetRegistry(); registry != nil {
+		metadata = registry.GetSourceMetadata(s.source.ID)
+	}
+
+	timeline, _ := streamTimelineStats(s.source.ID)
+
 	// Debug log health check details
 	if conf.Setting().Debug {
 		getStreamLogger().Debug("health check performed",
@@ -2000,6 +2178,14 @@ func (s *FFmpegStream) GetHealth() StreamHealth {
 		IsReceivingData:    isReceivingData,
 		ProcessState:       state,
 		StateHistory:       recentHistory,
+		NativeClient:       nativeClient,
+		Metadata:           metadata,
+		AudioQuality:       GetAudioDiagnostics(s.source.ID),
+		Latency:            timeline.Latency,
+		DriftPPM:           timeline.DriftPPM,
+		TotalGapTime:       timeline.TotalGap,
+		GapCount:           timeline.GapCount,
+		BurstCount:         timeline.BurstCount,
 		LastErrorContext:   lastError,
 		ErrorHistory:       recentErrors,
 	}
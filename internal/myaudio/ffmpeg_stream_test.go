@@ -1121,6 +1121,14 @@ func TestBuildFFmpegInputArgs(t *testing.T) {
 			wantRTSPTransport: false,
 			wantTimeout:       true,
 		},
+		{
+			name:              "srt_stream_excludes_rtsp_transport",
+			url:               "srt://remote.example.com:9000?mode=caller",
+			transport:         "tcp",
+			ffmpegParams:      []string{},
+			wantRTSPTransport: false,
+			wantTimeout:       true,
+		},
 		{
 			name:              "rtsp_stream_with_user_timeout_no_default_timeout",
 			url:               "rtsp://192.168.1.100/stream",
@@ -1170,3 +1178,12 @@ func TestBuildFFmpegInputArgs(t *testing.T) {
 		})
 	}
 }
+
+// TestSRTInputArgs verifies the SRT latency is passed in microseconds.
+func TestSRTInputArgs(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, srtInputArgs(conf.SRTSettings{}))
+	assert.Equal(t, []string{"-latency", "800000", "-passphrase", "wetland-recorder"},
+		srtInputArgs(conf.SRTSettings{Latency: 800, Passphrase: "wetland-recorder"}))
+}
@@ -0,0 +1,278 @@
+// icy_metadata.go: station and title metadata of Icecast/Shoutcast HTTP streams
+package myaudio
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/privacy"
+)
+
+const (
+	// icyPollInterval is how often the metadata of a running stream is refreshed
+	icyPollInterval = 5 * time.Minute
+	// icyFetchTimeout bounds one metadata request including the audio read
+	// up to the first metadata block
+	icyFetchTimeout = 15 * time.Second
+	// icyMaxMetaInt rejects metadata intervals that would read too much audio
+	icyMaxMetaInt = 256 * 1024
+	// icyMaxBlocks is how many metadata blocks are read to find a title, as
+	// servers may send empty blocks when the title has not changed
+	icyMaxBlocks = 3
+)
+
+// errNoIcyMetadata indicates the server does not send ICY metadata.
+var errNoIcyMetadata = errors.NewStd("stream has no ICY metadata")
+
+// StreamMetadata is the station information an Icecast or Shoutcast server
+// sends with a stream.
+type StreamMetadata struct {
+	Name        string    `json:"name,omitempty"`        // station or mount name (icy-name)
+	Description string    `json:"description,omitempty"` // station description (icy-description)
+	Genre       string    `json:"genre,omitempty"`       // station genre (icy-genre)
+	URL         string    `json:"url,omitempty"`         // station website (icy-url)
+	Bitrate     int       `json:"bitrate,omitempty"`     // bitrate in kbit/s (icy-br)
+	Title       string    `json:"title,omitempty"`       // current stream title (StreamTitle)
+	UpdatedAt   time.Time `json:"updatedAt"`             // when the metadata was read
+}
+
+// fetchIcyMetadata requests the stream with ICY metadata enabled and returns
+// the station headers and the title of the first metadata block. Returns
+// errNoIcyMetadata when the server sends neither.
+func fetchIcyMetadata(ctx context.Context, streamURL string) (*StreamMetadata, error) {
+	ctx, cancel := context.WithTimeout(ctx, icyFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, streamURL, http.NoBody)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "fetch_icy_metadata").
+			Build()
+	}
+	req.Header.Set("Icy-MetaData", "1")
+	req.Header.Set("User-Agent", "BirdNET-Go")
+
+	client := &http.Client{Transport: icyTransport()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryNetwork).
+			Context("operation", "fetch_icy_metadata").
+			Build()
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Newf("stream server returned %s", resp.Status).
+			Component("myaudio").
+			Category(errors.CategoryNetwork).
+			Context("operation", "fetch_icy_metadata").
+			Context("status_code", resp.StatusCode).
+			Build()
+	}
+
+	metadata := &StreamMetadata{
+		Name:        icyHeader(resp.Header, "Icy-Name"),
+		Description: icyHeader(resp.Header, "Icy-Description"),
+		Genre:       icyHeader(resp.Header, "Icy-Genre"),
+		URL:         icyHeader(resp.Header, "Icy-Url"),
+		UpdatedAt:   time.Now(),
+	}
+	// icy-br may list several bitrates, e.g. "128,128"
+	if bitrate, _, _ := strings.Cut(resp.Header.Get("Icy-Br"), ","); bitrate != "" {
+		metadata.Bitrate, _ = strconv.Atoi(strings.TrimSpace(bitrate))
+	}
+
+	hasHeaders := metadata.Name != "" || metadata.Description != "" ||
+		metadata.Genre != "" || metadata.URL != "" || metadata.Bitrate > 0
+
+	metaInt, _ := strconv.Atoi(resp.Header.Get("Icy-Metaint"))
+	if metaInt <= 0 || metaInt > icyMaxMetaInt {
+		if !hasHeaders {
+			return nil, errNoIcyMetadata
+		}
+		return metadata, nil
+	}
+
+	// The station headers are still worth keeping if the title cannot be read
+	title, err := readIcyTitle(resp.Body, metaInt)
+	if err != nil && !hasHeaders {
+		return nil, err
+	}
+	metadata.Title = title
+	return metadata, nil
+}
+
+// readIcyTitle reads metadata blocks interleaved with the audio until one
+// carries a stream title. Each block follows metaInt bytes of audio and
+// starts with its length in 16 byte units.
+func readIcyTitle(body io.Reader, metaInt int) (string, error) {
+	reader := bufio.NewReader(body)
+	block := make([]byte, 255*16)
+
+	for range icyMaxBlocks {
+		if _, err := reader.Discard(metaInt); err != nil {
+			return "", errors.New(err).
+				Component("myaudio").
+				Category(errors.CategoryNetwork).
+				Context("operation", "read_icy_title").
+				Build()
+		}
+		length, err := reader.ReadByte()
+		if err != nil {
+			return "", errors.New(err).
+				Component("myaudio").
+				Category(errors.CategoryNetwork).
+				Context("operation", "read_icy_title").
+				Build()
+		}
+		if length == 0 {
+			continue
+		}
+		data := block[:int(length)*16]
+		if _, err := io.ReadFull(reader, data); err != nil {
+			return "", errors.New(err).
+				Component("myaudio").
+				Category(errors.CategoryNetwork).
+				Context("operation", "read_icy_title").
+				Build()
+		}
+		if title := parseIcyStreamTitle(string(data)); title != "" {
+			return title, nil
+		}
+	}
+	return "", nil
+}
+
+// parseIcyStreamTitle returns the StreamTitle of a metadata block such as
+// "StreamTitle='Dawn chorus';". Titles may contain quotes, so the value ends
+// at the first "';".
+func parseIcyStreamTitle(block string) string {
+	block = strings.TrimRight(block, "\x00")
+	_, value, found := strings.Cut(block, "StreamTitle='")
+	if !found {
+		return ""
+	}
+	if end := strings.Index(value, "';"); end >= 0 {
+		value = value[:end]
+	} else {
+		value = strings.TrimSuffix(value, "'")
+	}
+	return decodeIcyText(strings.TrimSpace(value))
+}
+
+// icyHeader returns a trimmed ICY header value.
+func icyHeader(header http.Header, key string) string {
+	return decodeIcyText(strings.TrimSpace(header.Get(key)))
+}
+
+// decodeIcyText converts ICY text to UTF-8. Older servers send Latin-1.
+func decodeIcyText(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+	runes := make([]rune, len(s))
+	for i := range len(s) {
+		runes[i] = rune(s[i])
+	}
+	return string(runes)
+}
+
+// icyTransport returns an HTTP transport that accepts the "ICY 200 OK"
+// status line of Shoutcast v1 servers.
+func icyTransport() *http.Transport {
+	dialer := &net.Dialer{Timeout: icyFetchTimeout}
+	return &http.Transport{
+		Proxy:             http.ProxyFromEnvironment,
+		DisableKeepAlives: true,
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			conn, err := dialer.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			return &icyConn{Conn: conn}, nil
+		},
+	}
+}
+
+// icyConn rewrites an "ICY" response status line to HTTP/1.0. TLS streams
+// never start with "ICY ", so they pass through unchanged.
+type icyConn struct {
+	net.Conn
+	once   sync.Once
+	reader io.Reader
+}
+
+func (c *icyConn) Read(p []byte) (int, error) {
+	c.once.Do(func() {
+		buffered := bufio.NewReader(c.Conn)
+		if prefix, err := buffered.Peek(4); err == nil && string(prefix) == "ICY " {
+			_, _ = buffered.Discard(3)
+			c.reader = io.MultiReader(strings.NewReader("HTTP/1.0"), buffered)
+			return
+		}
+		c.reader = buffered
+	})
+	return c.reader.Read(p)
+}
+
+// pollIcyMetadata records the ICY metadata of an HTTP stream in the source
+// registry until the stream stops. Polling ends early when the server does
+// not send ICY metadata.
+func (s *FFmpegStream) pollIcyMetadata(ctx context.Context) {
+	connStr, err := s.source.GetConnectionString()
+	if err != nil {
+		return
+	}
+
+	for {
+		metadata, err := fetchIcyMetadata(ctx, connStr)
+		switch {
+		case errors.Is(err, errNoIcyMetadata):
+			return
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			getStreamLogger().Debug("failed to read ICY metadata",
+				logger.String("url", privacy.SanitizeStreamUrl(s.source.SafeString)),
+				logger.Error(err),
+				logger.String("component", "ffmpeg-stream"),
+				logger.String("operation", "poll_icy_metadata"))
+		default:
+			if registry := GetRegistry(); registry != nil {
+				previous := registry.GetSourceMetadata(s.source.ID)
+				registry.UpdateSourceMetadata(s.source.ID, metadata)
+				if previous == nil || previous.Title != metadata.Title || previous.Name != metadata.Name {
+					getStreamLogger().Info("stream metadata updated",
+						logger.String("url", privacy.SanitizeStreamUrl(s.source.SafeString)),
+						logger.String("station", metadata.Name),
+						logger.String("title", metadata.Title),
+						logger.String("component", "ffmpeg-stream"),
+						logger.String("operation", "poll_icy_metadata"))
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopChan:
+			return
+		case <-time.After(icyPollInterval):
+		}
+	}
+}
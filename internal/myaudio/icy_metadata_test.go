@@ -0,0 +1,108 @@
+package myaudio
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// icyStream builds a stream of metaInt audio bytes followed by each
+// metadata block, padded to 16 byte units.
+func icyStream(metaInt int, blocks ...string) []byte {
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		buf.Write(make([]byte, metaInt))
+		units := (len(block) + 15) / 16
+		buf.WriteByte(byte(units))
+		buf.WriteString(block)
+		buf.Write(make([]byte, units*16-len(block)))
+	}
+	return buf.Bytes()
+}
+
+func TestFetchIcyMetadata_Icecast(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Icy-MetaData") != "1" {
+			http.Error(w, "metadata not requested", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("icy-name", "Marsh Mount")
+		w.Header().Set("icy-genre", "Nature")
+		w.Header().Set("icy-br", "128,128")
+		w.Header().Set("icy-metaint", "64")
+		_, _ = w.Write(icyStream(64, "", "StreamTitle='Reed bed, north';StreamUrl='';"))
+	}))
+	t.Cleanup(server.Close)
+
+	metadata, err := fetchIcyMetadata(t.Context(), server.URL+"/marsh")
+	require.NoError(t, err)
+	assert.Equal(t, "Marsh Mount", metadata.Name)
+	assert.Equal(t, "Nature", metadata.Genre)
+	assert.Equal(t, 128, metadata.Bitrate)
+	assert.Equal(t, "Reed bed, north", metadata.Title, "empty blocks are skipped")
+	assert.False(t, metadata.UpdatedAt.IsZero())
+}
+
+func TestFetchIcyMetadata_ShoutcastStatusLine(t *testing.T) {
+	t.Parallel()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil || line == "\r\n" {
+				break
+			}
+		}
+		_, _ = io.WriteString(conn, "ICY 200 OK\r\nicy-name:Forest \xc4ni\r\nicy-metaint:32\r\n\r\n")
+		_, _ = conn.Write(icyStream(32, "StreamTitle='It''s dawn';"))
+	}()
+
+	metadata, err := fetchIcyMetadata(t.Context(), "http://"+listener.Addr().String()+"/")
+	require.NoError(t, err)
+	assert.Equal(t, "Forest Äni", metadata.Name, "Latin-1 is converted to UTF-8")
+	assert.Equal(t, "It''s dawn", metadata.Title)
+}
+
+func TestFetchIcyMetadata_PlainHTTP(t *testing.T) {
+	t.Parallel()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(make([]byte, 1024))
+	}))
+	t.Cleanup(server.Close)
+
+	_, err := fetchIcyMetadata(t.Context(), server.URL)
+	require.ErrorIs(t, err, errNoIcyMetadata)
+}
+
+func TestParseIcyStreamTitle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		block, want string
+	}{
+		{"StreamTitle='Dawn chorus';StreamUrl='';\x00\x00", "Dawn chorus"},
+		{"StreamTitle='Robin's song';", "Robin's song"},
+		{"StreamTitle='';", ""},
+		{"StreamUrl='http://example.com';", ""},
+		{"StreamTitle='Unterminated'", "Unterminated"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, parseIcyStreamTitle(tt.block), tt.block)
+	}
+}
@@ -0,0 +1,297 @@
+// live_opus.go: shared Opus encoders for low latency live audio streaming
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// Live Opus stream parameters
+const (
+	// LiveOpusSampleRate is the sample rate of live Opus streams
+	LiveOpusSampleRate = 48000
+	// LiveOpusDefaultBitrate is the bitrate in kbps used when none is configured
+	LiveOpusDefaultBitrate = 48
+	// LiveOpusFrameDuration is the duration of each Opus packet
+	LiveOpusFrameDuration = 20 * time.Millisecond
+
+	liveOpusStartTimeout = 10 * time.Second
+	liveOpusPacketBuffer = 100 // packets buffered per subscriber, 2 s of audio
+	liveOpusPCMBuffer    = 64  // capture chunks buffered for the encoder
+)
+
+// LiveOpusPacket is an encoded packet of a live Opus stream.
+type LiveOpusPacket struct {
+	Data []byte
+	Time time.Time // when the packet left the encoder
+}
+
+// liveOpusStream encodes the audio of one source with a single FFmpeg
+// process shared by all of its subscribers.
+type liveOpusStream struct {
+	sourceID string
+	cancel   context.CancelFunc
+
+	head      []byte        // OpusHead identification header, set before ready is closed
+	ready     chan struct{} // closed when the header has been read
+	done      chan struct{} // closed when the encoder has exited
+	closeOnce sync.Once
+
+	mu          sync.Mutex
+	subscribers map[chan LiveOpusPacket]struct{}
+}
+
+// LiveOpusManager runs an Opus encoder per source while the source has
+// subscribers. Encoders are fed from the capture broadcast, so live
+// listeners add no load on the capture and analysis pipeline.
+type LiveOpusManager struct {
+	ffmpegPath string
+	bitrate    int
+
+	mu      sync.Mutex
+	streams map[string]*liveOpusStream
+}
+
+// NewLiveOpusManager returns a manager encoding with FFmpeg at bitrate kbps.
+func NewLiveOpusManager(ffmpegPath string, bitrate int) *LiveOpusManager {
+	if bitrate <= 0 {
+		bitrate = LiveOpusDefaultBitrate
+	}
+	return &LiveOpusManager{
+		ffmpegPath: ffmpegPath,
+		bitrate:    bitrate,
+		streams:    make(map[string]*liveOpusStream),
+	}
+}
+
+// Subscribe returns the encoded audio of a source and the OpusHead header
+// of the stream, starting an encoder if the source has none. The packet
+// channel is closed if the encoder stops. Slow subscribers miss packets
+// rather than delaying others. unsubscribe must be called when done; the
+// encoder stops with its last subscriber.
+func (m *LiveOpusManager) Subscribe(ctx context.Context, sourceID string) (packets <-chan LiveOpusPacket, head []byte, unsubscribe func(), err error) {
+	if err := validateFFmpegPath(m.ffmpegPath); err != nil {
+		return nil, nil, nil, err
+	}
+
+	ch := make(chan LiveOpusPacket, liveOpusPacketBuffer)
+	m.mu.Lock()
+	stream, exists := m.streams[sourceID]
+	if !exists {
+		stream = m.startStream(sourceID)
+		m.streams[sourceID] = stream
+	}
+	stream.mu.Lock()
+	stream.subscribers[ch] = struct{}{}
+	stream.mu.Unlock()
+	m.mu.Unlock()
+
+	var once sync.Once
+	unsubscribe = func() { once.Do(func() { m.unsubscribe(stream, ch) }) }
+
+	timer := time.NewTimer(liveOpusStartTimeout)
+	defer timer.Stop()
+	select {
+	case <-stream.ready:
+		return ch, stream.head, unsubscribe, nil
+	case <-stream.done:
+		err = errors.Newf("live audio encoder exited").
+			Component("myaudio").
+			Category(errors.CategorySystem).
+			Context("operation", "live_opus_subscribe").
+			Build()
+	case <-timer.C:
+		err = errors.Newf("live audio encoder did not start within %s", liveOpusStartTimeout).
+			Component("myaudio").
+			Category(errors.CategoryTimeout).
+			Context("operation", "live_opus_subscribe").
+			Build()
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	unsubscribe()
+	return nil, nil, nil, err
+}
+
+// unsubscribe removes a subscriber and stops the encoder if it was the last one.
+func (m *LiveOpusManager) unsubscribe(stream *liveOpusStream, ch chan LiveOpusPacket) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stream.mu.Lock()
+	delete(stream.subscribers, ch)
+	remaining := len(stream.subscribers)
+	stream.mu.Unlock()
+
+	if remaining == 0 {
+		if m.streams[stream.sourceID] == stream {
+			delete(m.streams, stream.sourceID)
+		}
+		stream.cancel()
+	}
+}
+
+// SubscriberCount returns the number of subscribers of a source.
+func (m *LiveOpusManager) SubscriberCount(sourceID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stream, exists := m.streams[sourceID]
+	if !exists {
+		return 0
+	}
+	stream.mu.Lock()
+	defer stream.mu.Unlock()
+	return len(stream.subscribers)
+}
+
+// startStream starts the encoder of a source. Must be called with m.mu held.
+func (m *LiveOpusManager) startStream(sourceID string) *liveOpusStream {
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &liveOpusStream{
+		sourceID:    sourceID,
+		cancel:      cancel,
+		ready:       make(chan struct{}),
+		done:        make(chan struct{}),
+		subscribers: make(map[chan LiveOpusPacket]struct{}),
+	}
+	go func() {
+		err := stream.run(ctx, m.ffmpegPath, m.bitrate)
+		if err != nil && ctx.Err() == nil {
+			GetLogger().Warn("live audio encoder stopped",
+				logger.String("source_id", sourceID),
+				logger.Error(err))
+		}
+		m.mu.Lock()
+		if m.streams[sourceID] == stream {
+			delete(m.streams, sourceID)
+		}
+		m.mu.Unlock()
+		stream.close()
+	}()
+	return stream
+}
+
+// liveOpusFFmpegArgs returns the arguments encoding capture PCM from stdin
+// to Ogg Opus on stdout. Ogg pages are flushed every frame to keep latency low.
+func liveOpusFFmpegArgs(bitrate int) []string {
+	sampleRate, numChannels, format := getFFmpegFormat(conf.SampleRate, conf.NumChannels, conf.BitDepth)
+	return []string{
+		"-hide_banner", "-loglevel", "error",
+		"-f", format, "-ar", sampleRate, "-ac", numChannels,
+		"-i", "pipe:0",
+		"-ar", fmt.Sprintf("%d", LiveOpusSampleRate),
+		"-c:a", "libopus",
+		"-b:a", fmt.Sprintf("%dk", bitrate),
+		"-application", "lowdelay",
+		"-frame_duration", fmt.Sprintf("%d", LiveOpusFrameDuration.Milliseconds()),
+		"-f", "ogg",
+		"-page_duration", fmt.Sprintf("%d", LiveOpusFrameDuration.Microseconds()),
+		"-flush_packets", "1",
+		"pipe:1",
+	}
+}
+
+// run encodes the source until ctx is canceled or FFmpeg exits.
+func (s *liveOpusStream) run(ctx context.Context, ffmpegPath string, bitrate int) error {
+	cmd := exec.CommandContext(ctx, ffmpegPath, liveOpusFFmpegArgs(bitrate)...) //nolint:gosec // G204: ffmpegPath is from validated settings, args built internally
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	pcm := make(chan []byte, liveOpusPCMBuffer)
+	removeListener := AddBroadcastListener(s.sourceID, func(_ string, data []byte) {
+		select {
+		case pcm <- bytes.Clone(data):
+		default:
+			// Encoder is behind, drop the chunk rather than block capture
+		}
+	})
+	defer removeListener()
+
+	go func() {
+		defer func() { _ = stdin.Close() }()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case data := <-pcm:
+				if _, err := stdin.Write(data); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	readErr := s.readPackets(stdout)
+	waitErr := cmd.Wait()
+	if readErr != nil && !errors.Is(readErr, io.EOF) {
+		return readErr
+	}
+	if waitErr != nil {
+		return fmt.Errorf("ffmpeg: %w, stderr: %s", waitErr, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// readPackets reads the Opus headers and fans out audio packets to subscribers.
+func (s *liveOpusStream) readPackets(r io.Reader) error {
+	reader := NewOggPacketReader(r)
+	for index := 0; ; index++ {
+		packet, err := reader.ReadPacket()
+		if err != nil {
+			return err
+		}
+		switch index {
+		case 0: // OpusHead
+			s.head = packet
+			close(s.ready)
+		case 1: // OpusTags, not needed by decoders
+		default:
+			s.publish(LiveOpusPacket{Data: packet, Time: time.Now()})
+		}
+	}
+}
+
+// publish sends a packet to all subscribers without blocking.
+func (s *liveOpusStream) publish(packet LiveOpusPacket) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range s.subscribers {
+		select {
+		case ch <- packet:
+		default:
+		}
+	}
+}
+
+// close marks the stream done and closes the channels of its subscribers.
+func (s *liveOpusStream) close() {
+	s.closeOnce.Do(func() {
+		s.mu.Lock()
+		for ch := range s.subscribers {
+			close(ch)
+			delete(s.subscribers, ch)
+		}
+		s.mu.Unlock()
+		close(s.done)
+	})
+}
@@ -0,0 +1,79 @@
+package myaudio
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddBroadcastListener(t *testing.T) {
+	t.Parallel()
+
+	const sourceID = "test_listener_source"
+	var first, second [][]byte
+	removeFirst := AddBroadcastListener(sourceID, func(_ string, data []byte) { first = append(first, data) })
+	removeSecond := AddBroadcastListener(sourceID, func(_ string, data []byte) { second = append(second, data) })
+
+	broadcastAudioData(sourceID, []byte{1})
+	removeFirst()
+	removeFirst() // removing twice is harmless
+	broadcastAudioData(sourceID, []byte{2})
+	removeSecond()
+	broadcastAudioData(sourceID, []byte{3})
+
+	assert.Equal(t, [][]byte{{1}}, first)
+	assert.Equal(t, [][]byte{{1}, {2}}, second)
+}
+
+func TestLiveOpusStreamReadPackets(t *testing.T) {
+	t.Parallel()
+
+	stream := &liveOpusStream{
+		ready:       make(chan struct{}),
+		done:        make(chan struct{}),
+		subscribers: make(map[chan LiveOpusPacket]struct{}),
+	}
+	ch := make(chan LiveOpusPacket, 4)
+	stream.subscribers[ch] = struct{}{}
+
+	var ogg bytes.Buffer
+	for _, packet := range [][]byte{[]byte("OpusHead"), []byte("OpusTags"), {0x78, 1}, {0x78, 2}} {
+		ogg.Write(buildOggPage(t, false, oggLacing(len(packet)), packet))
+	}
+	require.Error(t, stream.readPackets(&ogg)) // io.EOF at the end of the stream
+
+	select {
+	case <-stream.ready:
+	default:
+		t.Fatal("stream should be ready after the OpusHead packet")
+	}
+	assert.Equal(t, []byte("OpusHead"), stream.head)
+	require.Len(t, ch, 2, "headers must not be published")
+	assert.Equal(t, []byte{0x78, 1}, (<-ch).Data)
+	assert.Equal(t, []byte{0x78, 2}, (<-ch).Data)
+
+	stream.close()
+	stream.close()
+	_, open := <-ch
+	assert.False(t, open, "subscriber channels are closed with the stream")
+}
+
+func TestLiveOpusManagerRequiresFFmpeg(t *testing.T) {
+	t.Parallel()
+
+	manager := NewLiveOpusManager("", 0)
+	assert.Equal(t, LiveOpusDefaultBitrate, manager.bitrate)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, _, _, err := manager.Subscribe(ctx, "source")
+	require.Error(t, err)
+	assert.Zero(t, manager.SubscriberCount("source"))
+
+	args := liveOpusFFmpegArgs(64)
+	assert.Contains(t, args, "libopus")
+	assert.Contains(t, args, "64k")
+}
@@ -0,0 +1,102 @@
+// ogg.go: minimal Ogg demuxer for reading encoded packets from FFmpeg output
+package myaudio
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Ogg page layout, see RFC 3533
+const (
+	oggPageHeaderSize = 27
+	oggMaxPacketSize  = 1 << 20
+)
+
+// oggCapturePattern starts every Ogg page.
+var oggCapturePattern = []byte("OggS")
+
+// OggPacketReader reads the packets of a single logical Ogg stream. Page
+// checksums are not verified, the reader is meant for trusted local input
+// such as an FFmpeg pipe.
+type OggPacketReader struct {
+	r       *bufio.Reader
+	pending [][]byte // complete packets of the current page
+	partial []byte   // packet continued on the next page
+}
+
+// NewOggPacketReader returns a reader of the packets in r.
+func NewOggPacketReader(r io.Reader) *OggPacketReader {
+	return &OggPacketReader{r: bufio.NewReader(r)}
+}
+
+// ReadPacket returns the next packet. It returns io.EOF at the end of the stream.
+func (o *OggPacketReader) ReadPacket() ([]byte, error) {
+	for len(o.pending) == 0 {
+		if err := o.readPage(); err != nil {
+			return nil, err
+		}
+	}
+	packet := o.pending[0]
+	o.pending = o.pending[1:]
+	return packet, nil
+}
+
+// readPage reads one page and queues the packets completed in it.
+func (o *OggPacketReader) readPage() error {
+	header := make([]byte, oggPageHeaderSize)
+	if _, err := io.ReadFull(o.r, header); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return io.EOF
+		}
+		return err
+	}
+	if !bytes.Equal(header[:4], oggCapturePattern) {
+		return errors.Newf("invalid Ogg page: missing capture pattern").
+			Component("myaudio").
+			Category(errors.CategoryAudio).
+			Context("operation", "read_ogg_page").
+			Build()
+	}
+
+	segments := make([]byte, header[26])
+	if _, err := io.ReadFull(o.r, segments); err != nil {
+		return err
+	}
+	var bodySize int
+	for _, lacing := range segments {
+		bodySize += int(lacing)
+	}
+	body := make([]byte, bodySize)
+	if _, err := io.ReadFull(o.r, body); err != nil {
+		return err
+	}
+
+	// A packet continued from the previous page is dropped if this page does
+	// not continue it, as after a lost page
+	const continuedPacket = 0x01
+	if header[5]&continuedPacket == 0 {
+		o.partial = o.partial[:0]
+	}
+
+	offset := 0
+	for _, lacing := range segments {
+		o.partial = append(o.partial, body[offset:offset+int(lacing)]...)
+		offset += int(lacing)
+		if len(o.partial) > oggMaxPacketSize {
+			return errors.Newf("Ogg packet exceeds %d bytes", oggMaxPacketSize).
+				Component("myaudio").
+				Category(errors.CategoryAudio).
+				Context("operation", "read_ogg_page").
+				Build()
+		}
+		// A lacing value below 255 ends a packet
+		if lacing < 255 {
+			o.pending = append(o.pending, bytes.Clone(o.partial))
+			o.partial = o.partial[:0]
+		}
+	}
+	return nil
+}
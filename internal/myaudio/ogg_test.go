@@ -0,0 +1,70 @@
+package myaudio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildOggPage returns an Ogg page with the given segment lacing values and body.
+func buildOggPage(t *testing.T, continued bool, lacing []byte, body []byte) []byte {
+	t.Helper()
+	header := make([]byte, oggPageHeaderSize)
+	copy(header, oggCapturePattern)
+	if continued {
+		header[5] = 0x01
+	}
+	binary.LittleEndian.PutUint32(header[14:18], 1) // serial
+	header[26] = byte(len(lacing))
+	page := append(header, lacing...)
+	return append(page, body...)
+}
+
+// oggLacing returns the lacing values of a packet of size n that ends on the page.
+func oggLacing(n int) []byte {
+	lacing := bytes.Repeat([]byte{255}, n/255)
+	return append(lacing, byte(n%255))
+}
+
+func TestOggPacketReader(t *testing.T) {
+	t.Parallel()
+
+	head := []byte("OpusHead-test")
+	long := bytes.Repeat([]byte{0xAB}, 600)
+
+	var stream bytes.Buffer
+	stream.Write(buildOggPage(t, false, oggLacing(len(head)), head))
+	// The 600 byte packet is split over two pages: 510 bytes, then the remaining 90
+	stream.Write(buildOggPage(t, false, []byte{255, 255}, long[:510]))
+	page := buildOggPage(t, true, append([]byte{90}, oggLacing(3)...), append(bytes.Clone(long[510:]), 1, 2, 3))
+	stream.Write(page)
+
+	reader := NewOggPacketReader(&stream)
+	packet, err := reader.ReadPacket()
+	require.NoError(t, err)
+	assert.Equal(t, head, packet)
+
+	packet, err = reader.ReadPacket()
+	require.NoError(t, err)
+	assert.Equal(t, long, packet)
+
+	packet, err = reader.ReadPacket()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{1, 2, 3}, packet)
+
+	_, err = reader.ReadPacket()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestOggPacketReaderInvalidPage(t *testing.T) {
+	t.Parallel()
+
+	reader := NewOggPacketReader(bytes.NewReader(bytes.Repeat([]byte{'x'}, oggPageHeaderSize)))
+	_, err := reader.ReadPacket()
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, io.EOF)
+}
@@ -67,7 +67,7 @@ func ReturnFloat32Buffer(buffer []float32) {
 
 // processData processes the given audio data to detect bird species, logs the detected species
 // and optionally saves the audio clip if a bird species is detected above the configured threshold.
-func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source string) error {
+func ProcessData(bn *birdnet.BirdNET, data []byte, startTime, audioTime time.Time, source string) error {
 	log := GetLogger()
 	// get current time to track processing time
 	predictStart := time.Now()
@@ -172,6 +172,7 @@ func ProcessData(bn *birdnet.BirdNET, data []byte, startTime time.Time, source s
 	// Create a Results message to be sent through queue to processor
 	resultsMessage := birdnet.Results{
 		StartTime:   startTime,
+		AudioTime:   audioTime,
 		ElapsedTime: elapsedTime,
 		PCMdata:     data,
 		Results:     results,
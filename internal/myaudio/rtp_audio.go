@@ -0,0 +1,349 @@
+// rtp_audio.go: SDP parsing and RTP audio decoding for the native RTSP client
+package myaudio
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+
+	"github.com/pion/opus"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// RTP audio encodings decoded natively
+const (
+	rtpEncodingPCMU = "PCMU" // G.711 µ-law
+	rtpEncodingPCMA = "PCMA" // G.711 A-law
+	rtpEncodingL16  = "L16"  // 16-bit big-endian linear PCM
+	rtpEncodingOpus = "OPUS" // Opus, RFC 7587
+)
+
+const (
+	rtpHeaderSize = 12
+	rtpVersion    = 2
+	// rtpMaxGapFillSeconds limits silence inserted for lost packets, larger
+	// timestamp jumps are treated as a stream discontinuity
+	rtpMaxGapFillSeconds = 1
+	// Opus RTP streams always use a 48 kHz clock, RFC 7587 section 4.1
+	opusClockRate = 48000
+	// opusMaxPacketSamples is the longest Opus packet, 120 ms at 48 kHz
+	opusMaxPacketSamples = 5760
+)
+
+// sdpAudioMedia is an audio media description from an SDP session description.
+type sdpAudioMedia struct {
+	payloadType uint8
+	encoding    string // upper case encoding name, e.g. "PCMU" or "MPEG4-GENERIC"
+	clockRate   int
+	channels    int
+	control     string // control attribute, resolved against the session base URL
+}
+
+// staticAudioPayloadTypes are the RFC 3551 static audio payload types the
+// native client can decode. Their rtpmap attribute is optional.
+var staticAudioPayloadTypes = map[uint8]sdpAudioMedia{
+	0:  {payloadType: 0, encoding: rtpEncodingPCMU, clockRate: 8000, channels: 1},
+	8:  {payloadType: 8, encoding: rtpEncodingPCMA, clockRate: 8000, channels: 1},
+	10: {payloadType: 10, encoding: rtpEncodingL16, clockRate: 44100, channels: 2},
+	11: {payloadType: 11, encoding: rtpEncodingL16, clockRate: 44100, channels: 1},
+}
+
+// parseSDPAudio returns the audio media of an SDP session description, using
+// the first payload format of each media.
+func parseSDPAudio(sdp string) []sdpAudioMedia {
+	var medias []sdpAudioMedia
+	var current *sdpAudioMedia
+
+	for line := range strings.Lines(sdp) {
+		line = strings.TrimRight(line, "\r\n")
+		if strings.HasPrefix(line, "m=") {
+			current = nil
+			fields := strings.Fields(line[2:])
+			if len(fields) < 4 || fields[0] != "audio" {
+				continue
+			}
+			pt, err := strconv.ParseUint(fields[3], 10, 8)
+			if err != nil || pt > 127 {
+				continue
+			}
+			media := sdpAudioMedia{payloadType: uint8(pt), channels: 1}
+			if static, ok := staticAudioPayloadTypes[media.payloadType]; ok {
+				media = static
+			}
+			medias = append(medias, media)
+			current = &medias[len(medias)-1]
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "a=control:"):
+			current.control = strings.TrimSpace(strings.TrimPrefix(line, "a=control:"))
+		case strings.HasPrefix(line, "a=rtpmap:"):
+			ptStr, rtpmap, found := strings.Cut(strings.TrimPrefix(line, "a=rtpmap:"), " ")
+			if !found || ptStr != strconv.Itoa(int(current.payloadType)) {
+				continue
+			}
+			parts := strings.Split(strings.TrimSpace(rtpmap), "/")
+			current.encoding = strings.ToUpper(parts[0])
+			if len(parts) > 1 {
+				current.clockRate, _ = strconv.Atoi(parts[1])
+			}
+			current.channels = 1
+			if len(parts) > 2 {
+				if channels, err := strconv.Atoi(parts[2]); err == nil && channels > 0 {
+					current.channels = channels
+				}
+			}
+		}
+	}
+	return medias
+}
+
+// nativelyDecodable reports whether the native client can decode the media.
+// AAC (MPEG4-GENERIC and MP4A-LATM) is left to FFmpeg as there is no pure Go
+// AAC decoder.
+func (m *sdpAudioMedia) nativelyDecodable() bool {
+	switch m.encoding {
+	case rtpEncodingPCMU, rtpEncodingPCMA, rtpEncodingL16:
+		return m.clockRate > 0 && m.channels > 0
+	case rtpEncodingOpus:
+		return m.clockRate == opusClockRate
+	default:
+		return false
+	}
+}
+
+// rtpAudioDecoder decodes RTP audio packets to mono 16-bit little-endian PCM
+// at the capture sample rate.
+type rtpAudioDecoder struct {
+	media     sdpAudioMedia
+	resampler linearResampler
+	opus      *opus.Decoder // decodes Opus to mono at 48 kHz, nil for other encodings
+
+	started bool
+	lastSeq uint16
+	nextTS  uint32 // expected timestamp of the next packet
+}
+
+// newRTPAudioDecoder returns a decoder converting media to outputRate.
+func newRTPAudioDecoder(media sdpAudioMedia, outputRate int) *rtpAudioDecoder {
+	d := &rtpAudioDecoder{
+		media:     media,
+		resampler: newLinearResampler(media.clockRate, outputRate),
+	}
+	if media.encoding == rtpEncodingOpus {
+		decoder := opus.NewDecoder()
+		d.opus = &decoder
+	}
+	return d
+}
+
+// decode returns the PCM of an RTP packet. Packets of other payload types and
+// late or duplicate packets return no data. Lost packets up to a second, and
+// packets that cannot be decoded, are replaced with silence to keep the audio
+// timeline intact.
+func (d *rtpAudioDecoder) decode(packet []byte) ([]byte, error) {
+	payloadType, seq, timestamp, payload, err := parseRTPPacket(packet)
+	if err != nil {
+		return nil, err
+	}
+	if payloadType != d.media.payloadType {
+		return nil, nil
+	}
+
+	var gap int
+	if d.started {
+		delta := seq - d.lastSeq
+		if delta == 0 || delta >= 0x8000 {
+			return nil, nil
+		}
+		if missing := int32(timestamp - d.nextTS); missing > 0 && missing <= int32(d.media.clockRate*rtpMaxGapFillSeconds) {
+			gap = int(missing)
+		}
+	}
+
+	samples, err := d.decodeSamples(payload, gap)
+	if err != nil {
+		return nil, err
+	}
+	d.started = true
+	d.lastSeq = seq
+	d.nextTS = timestamp + uint32(len(samples)-gap) //nolint:gosec // G115: sample count of one packet
+
+	resampled := d.resampler.process(samples)
+	out := make([]byte, len(resampled)*2)
+	for i, sample := range resampled {
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(sample)) //nolint:gosec // G115: reinterpreting PCM bits
+	}
+	return out, nil
+}
+
+// decodeSamples decodes payload to mono samples preceded by gap samples of silence.
+func (d *rtpAudioDecoder) decodeSamples(payload []byte, gap int) ([]int16, error) {
+	if d.opus != nil {
+		return d.decodeOpus(payload, gap)
+	}
+
+	channels := d.media.channels
+	bytesPerSample := 1
+	if d.media.encoding == rtpEncodingL16 {
+		bytesPerSample = 2
+	}
+	frames := len(payload) / (bytesPerSample * channels)
+	samples := make([]int16, gap, gap+frames)
+
+	for f := range frames {
+		var sum int
+		for ch := range channels {
+			offset := (f*channels + ch) * bytesPerSample
+			switch d.media.encoding {
+			case rtpEncodingPCMU:
+				sum += int(ulawTable[payload[offset]])
+			case rtpEncodingPCMA:
+				sum += int(alawTable[payload[offset]])
+			default:
+				sum += int(int16(binary.BigEndian.Uint16(payload[offset:]))) //nolint:gosec // G115: reinterpreting PCM bits
+			}
+		}
+		samples = append(samples, int16(sum/channels)) //nolint:gosec // G115: average fits in int16
+	}
+	return samples, nil
+}
+
+// decodeOpus decodes an Opus packet, which RFC 7587 carries one per RTP
+// payload. Stereo packets are downmixed by the decoder.
+func (d *rtpAudioDecoder) decodeOpus(payload []byte, gap int) ([]int16, error) {
+	samples := make([]int16, gap+opusMaxPacketSamples)
+	n, err := d.opus.DecodeToInt16(payload, samples[gap:])
+	if err != nil {
+		return nil, errors.Newf("failed to decode Opus packet: %w", err).
+			Component("myaudio").
+			Category(errors.CategoryRTSP).
+			Context("operation", "decode_opus").
+			Build()
+	}
+	return samples[:gap+n], nil
+}
+
+// parseRTPPacket returns the header fields and payload of an RTP packet.
+func parseRTPPacket(packet []byte) (payloadType uint8, seq uint16, timestamp uint32, payload []byte, err error) {
+	if len(packet) < rtpHeaderSize || packet[0]>>6 != rtpVersion {
+		return 0, 0, 0, nil, errors.Newf("invalid RTP packet of %d bytes", len(packet)).
+			Component("myaudio").
+			Category(errors.CategoryRTSP).
+			Context("operation", "parse_rtp_packet").
+			Build()
+	}
+
+	offset := rtpHeaderSize + int(packet[0]&0x0f)*4
+	if packet[0]&0x10 != 0 && len(packet) >= offset+4 {
+		offset += 4 + int(binary.BigEndian.Uint16(packet[offset+2:]))*4
+	}
+	end := len(packet)
+	if packet[0]&0x20 != 0 {
+		end -= int(packet[len(packet)-1])
+	}
+	if offset > end {
+		return 0, 0, 0, nil, errors.Newf("RTP packet header exceeds packet size").
+			Component("myaudio").
+			Category(errors.CategoryRTSP).
+			Context("operation", "parse_rtp_packet").
+			Build()
+	}
+
+	return packet[1] & 0x7f,
+		binary.BigEndian.Uint16(packet[2:]),
+		binary.BigEndian.Uint32(packet[4:]),
+		packet[offset:end],
+		nil
+}
+
+// G.711 decoding tables
+var (
+	ulawTable = buildG711Table(ulawToLinear)
+	alawTable = buildG711Table(alawToLinear)
+)
+
+func buildG711Table(decode func(byte) int16) [256]int16 {
+	var table [256]int16
+	for i := range table {
+		table[i] = decode(byte(i))
+	}
+	return table
+}
+
+// ulawToLinear decodes a G.711 µ-law sample.
+func ulawToLinear(u byte) int16 {
+	u = ^u
+	t := (int(u&0x0f) << 3) + 0x84
+	t <<= (u & 0x70) >> 4
+	if u&0x80 != 0 {
+		return int16(0x84 - t) //nolint:gosec // G115: G.711 range fits in int16
+	}
+	return int16(t - 0x84) //nolint:gosec // G115: G.711 range fits in int16
+}
+
+// alawToLinear decodes a G.711 A-law sample.
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+	t := int(a&0x0f) << 4
+	switch segment := (a & 0x70) >> 4; segment {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= segment - 1
+	}
+	if a&0x80 != 0 {
+		return int16(t) //nolint:gosec // G115: G.711 range fits in int16
+	}
+	return int16(-t) //nolint:gosec // G115: G.711 range fits in int16
+}
+
+// linearResampler converts a continuous stream of chunks between sample
+// rates with linear interpolation, carrying its position across chunks.
+// Positions are kept in units of 1/outputRate input samples so they stay exact.
+type linearResampler struct {
+	inputRate  int
+	outputRate int
+	pos        int   // position of the next output sample, 0 is the last sample of the previous chunk
+	prev       int16 // last sample of the previous chunk
+}
+
+func newLinearResampler(inputRate, outputRate int) linearResampler {
+	return linearResampler{inputRate: inputRate, outputRate: outputRate, pos: outputRate}
+}
+
+// process resamples the next chunk of the stream.
+func (r *linearResampler) process(in []int16) []int16 {
+	if r.inputRate == r.outputRate || len(in) == 0 {
+		return in
+	}
+
+	sample := func(i int) float64 {
+		if i == 0 {
+			return float64(r.prev)
+		}
+		return float64(in[i-1])
+	}
+
+	out := make([]int16, 0, len(in)*r.outputRate/r.inputRate+1)
+	for {
+		i := r.pos / r.outputRate
+		if i >= len(in) {
+			break
+		}
+		frac := float64(r.pos%r.outputRate) / float64(r.outputRate)
+		out = append(out, int16(sample(i)*(1-frac)+sample(i+1)*frac))
+		r.pos += r.inputRate
+	}
+	r.pos -= len(in) * r.outputRate
+	r.prev = in[len(in)-1]
+	return out
+}
@@ -0,0 +1,183 @@
+package myaudio
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildRTPPacket builds an RTP packet without CSRCs or extensions.
+func buildRTPPacket(payloadType uint8, seq uint16, timestamp uint32, payload []byte) []byte {
+	packet := make([]byte, rtpHeaderSize, rtpHeaderSize+len(payload))
+	packet[0] = rtpVersion << 6
+	packet[1] = payloadType
+	binary.BigEndian.PutUint16(packet[2:], seq)
+	binary.BigEndian.PutUint32(packet[4:], timestamp)
+	return append(packet, payload...)
+}
+
+// pcmSamples decodes 16-bit little-endian PCM.
+func pcmSamples(data []byte) []int16 {
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:])) //nolint:gosec // G115: reinterpreting PCM bits
+	}
+	return samples
+}
+
+func TestParseSDPAudio(t *testing.T) {
+	t.Parallel()
+	sdp := "v=0\r\n" +
+		"o=- 0 0 IN IP4 127.0.0.1\r\n" +
+		"s=Camera\r\n" +
+		"m=video 0 RTP/AVP 96\r\n" +
+		"a=rtpmap:96 H264/90000\r\n" +
+		"a=control:trackID=0\r\n" +
+		"m=audio 0 RTP/AVP 0\r\n" +
+		"a=control:trackID=1\r\n" +
+		"m=audio 0 RTP/AVP 97\r\n" +
+		"a=rtpmap:97 L16/16000/2\r\n" +
+		"a=control:trackID=2\r\n" +
+		"m=audio 0 RTP/AVP 98\r\n" +
+		"a=rtpmap:98 mpeg4-generic/48000/2\r\n" +
+		"m=audio 0 RTP/AVP 111\r\n" +
+		"a=rtpmap:111 opus/48000/2\r\n"
+
+	medias := parseSDPAudio(sdp)
+	require.Len(t, medias, 4)
+
+	assert.Equal(t, sdpAudioMedia{payloadType: 0, encoding: "PCMU", clockRate: 8000, channels: 1, control: "trackID=1"}, medias[0])
+	assert.Equal(t, sdpAudioMedia{payloadType: 97, encoding: "L16", clockRate: 16000, channels: 2, control: "trackID=2"}, medias[1])
+	assert.Equal(t, "MPEG4-GENERIC", medias[2].encoding)
+
+	assert.True(t, medias[0].nativelyDecodable())
+	assert.True(t, medias[1].nativelyDecodable())
+	assert.False(t, medias[2].nativelyDecodable(), "AAC is left to FFmpeg")
+	assert.Equal(t, sdpAudioMedia{payloadType: 111, encoding: "OPUS", clockRate: 48000, channels: 2}, medias[3])
+	assert.True(t, medias[3].nativelyDecodable())
+}
+
+func TestG711Decoding(t *testing.T) {
+	t.Parallel()
+	ulaw := map[byte]int16{0x00: -32124, 0x0f: -16764, 0x7f: 0, 0x80: 32124, 0xff: 0}
+	for code, want := range ulaw {
+		assert.Equal(t, want, ulawTable[code], "µ-law 0x%02x", code)
+	}
+	alaw := map[byte]int16{0x00: -5504, 0x2a: -32256, 0x55: -8, 0xaa: 32256, 0xd5: 8}
+	for code, want := range alaw {
+		assert.Equal(t, want, alawTable[code], "A-law 0x%02x", code)
+	}
+}
+
+func TestParseRTPPacket(t *testing.T) {
+	t.Parallel()
+	payload := []byte{1, 2, 3, 4}
+
+	// One CSRC, a one word extension and two bytes of padding
+	packet := []byte{0x80 | 0x20 | 0x10 | 0x01, 96, 0x01, 0x02, 0, 0, 0x10, 0}
+	packet = append(packet, 0, 0, 0, 1) // SSRC
+	packet = append(packet, 0, 0, 0, 2) // CSRC
+	packet = append(packet, 0xbe, 0xde, 0, 1, 9, 9, 9, 9)
+	packet = append(packet, payload...)
+	packet = append(packet, 0, 2)
+
+	payloadType, seq, timestamp, got, err := parseRTPPacket(packet)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(96), payloadType)
+	assert.Equal(t, uint16(0x0102), seq)
+	assert.Equal(t, uint32(0x1000), timestamp)
+	assert.Equal(t, payload, got)
+
+	_, _, _, _, err = parseRTPPacket(packet[:8])
+	require.Error(t, err)
+	_, _, _, _, err = parseRTPPacket(append([]byte{0x40}, packet[1:]...))
+	require.Error(t, err, "RTP version 1 is rejected")
+}
+
+func TestRTPAudioDecoder(t *testing.T) {
+	t.Parallel()
+	media := sdpAudioMedia{payloadType: 97, encoding: rtpEncodingL16, clockRate: 8000, channels: 2}
+	decoder := newRTPAudioDecoder(media, 8000)
+
+	// Stereo frames are downmixed to mono
+	stereo := []byte{0x01, 0x00, 0x03, 0x00, 0xff, 0xfe, 0xff, 0xfe}
+	pcm, err := decoder.decode(buildRTPPacket(97, 10, 1000, stereo))
+	require.NoError(t, err)
+	assert.Equal(t, []int16{512, -2}, pcmSamples(pcm))
+
+	// Duplicates and packets of other payload types are dropped
+	pcm, err = decoder.decode(buildRTPPacket(97, 10, 1000, stereo))
+	require.NoError(t, err)
+	assert.Empty(t, pcm)
+	pcm, err = decoder.decode(buildRTPPacket(101, 11, 1002, stereo))
+	require.NoError(t, err)
+	assert.Empty(t, pcm)
+
+	// A lost packet of three frames is filled with silence
+	pcm, err = decoder.decode(buildRTPPacket(97, 12, 1005, stereo))
+	require.NoError(t, err)
+	assert.Equal(t, []int16{0, 0, 0, 512, -2}, pcmSamples(pcm))
+
+	// Large timestamp jumps are not filled
+	pcm, err = decoder.decode(buildRTPPacket(97, 13, 90000, stereo))
+	require.NoError(t, err)
+	assert.Len(t, pcmSamples(pcm), 2)
+}
+
+func TestRTPAudioDecoder_Opus(t *testing.T) {
+	t.Parallel()
+	media := sdpAudioMedia{payloadType: 111, encoding: rtpEncodingOpus, clockRate: 48000, channels: 2}
+	decoder := newRTPAudioDecoder(media, 48000)
+
+	// 20 ms CELT fullband frames, mono and stereo, decode to 960 mono samples
+	mono := []byte{0xf8, 0xff, 0xfe}
+	stereo := []byte{0xfc, 0xff, 0xfe}
+	pcm, err := decoder.decode(buildRTPPacket(111, 1, 0, mono))
+	require.NoError(t, err)
+	assert.Len(t, pcmSamples(pcm), 960)
+	pcm, err = decoder.decode(buildRTPPacket(111, 2, 960, stereo))
+	require.NoError(t, err)
+	assert.Len(t, pcmSamples(pcm), 960)
+
+	// Undecodable packets are dropped and filled with silence by the next packet
+	_, err = decoder.decode(buildRTPPacket(111, 3, 1920, nil))
+	require.Error(t, err)
+	pcm, err = decoder.decode(buildRTPPacket(111, 4, 2880, mono))
+	require.NoError(t, err)
+	samples := pcmSamples(pcm)
+	require.Len(t, samples, 1920)
+	assert.Equal(t, make([]int16, 960), samples[:960])
+
+	// Output is resampled to the capture rate
+	decoder = newRTPAudioDecoder(media, 16000)
+	pcm, err = decoder.decode(buildRTPPacket(111, 1, 0, mono))
+	require.NoError(t, err)
+	assert.InDelta(t, 320, len(pcmSamples(pcm)), 1)
+}
+
+func TestLinearResampler(t *testing.T) {
+	t.Parallel()
+	resampler := newLinearResampler(8000, 48000)
+
+	// Chunked input produces the same continuous output as a single chunk
+	input := make([]int16, 160)
+	for i := range input {
+		input[i] = int16(i * 100) //nolint:gosec // G115: test values fit in int16
+	}
+	var chunked []int16
+	for i := 0; i < len(input); i += 40 {
+		chunked = append(chunked, resampler.process(input[i:i+40])...)
+	}
+	whole := newLinearResampler(8000, 48000)
+	assert.Equal(t, whole.process(input), chunked)
+
+	// Six output samples per input sample, the span after the last input
+	// sample is produced with the next chunk
+	assert.Len(t, chunked, 159*6)
+	assert.Equal(t, []int16{0, 16, 33, 50, 66, 83, 100}, chunked[:7])
+
+	same := newLinearResampler(48000, 48000)
+	assert.Equal(t, input, same.process(input))
+}
@@ -0,0 +1,552 @@
+// rtsp_native.go: in-process RTSP client for streams with natively decoded audio codecs
+package myaudio
+
+import (
+	"bufio"
+	"context"
+	"crypto/md5" //nolint:gosec // G501: required by RTSP digest authentication
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	rtspUserAgent = "BirdNET-Go"
+	// rtspIOTimeout matches the default FFmpeg -timeout used for streams
+	rtspIOTimeout = time.Duration(defaultTimeoutMicroseconds) * time.Microsecond
+	// rtspDefaultSessionTimeout is the RFC 2326 session timeout when the server sends none
+	rtspDefaultSessionTimeout = 60 * time.Second
+	rtspMaxBodySize           = 64 * 1024
+	rtspTeardownTimeout       = time.Second
+)
+
+// Errors that make a stream fall back to FFmpeg
+var (
+	errRTSPCodecUnsupported     = errors.NewStd("audio codec not supported by native RTSP client")
+	errRTSPTransportUnsupported = errors.NewStd("transport not supported by native RTSP client")
+	errRTSPRequestRefused       = errors.NewStd("RTSP request refused by server")
+)
+
+// rtspResponse is an RTSP message read from the server. Requests sent by the
+// server have a zero status.
+type rtspResponse struct {
+	status int
+	header textproto.MIMEHeader
+	body   []byte
+}
+
+// rtspClient is an RTSP control connection with RTP interleaved over TCP.
+type rtspClient struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+
+	user     *url.Userinfo
+	cseq     int
+	session  string
+	timeout  time.Duration // session timeout
+	auth     string        // WWW-Authenticate challenge answered in requests
+	frameBuf []byte
+}
+
+// rtspAudioStream reads decoded audio of an RTSP session. It implements
+// io.ReadCloser so it can replace the FFmpeg stdout pipe of a stream.
+type rtspAudioStream struct {
+	client        *rtspClient
+	url           string // request URL without credentials, for keepalives and teardown
+	decoder       *rtpAudioDecoder
+	channel       byte // interleaved channel of RTP packets
+	pending       []byte
+	lastKeepalive time.Time
+	closeOnce     sync.Once
+}
+
+// dialRTSPAudio opens an RTSP session and starts playing its first audio
+// track. It returns errRTSPCodecUnsupported if the audio needs FFmpeg.
+func dialRTSPAudio(ctx context.Context, rawURL string, outputRate int) (*rtspAudioStream, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryValidation).
+			Context("operation", "dial_rtsp").
+			Build()
+	}
+
+	client, err := dialRTSP(ctx, u)
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := client.startAudio(u, outputRate)
+	if err != nil {
+		_ = client.conn.Close()
+		return nil, err
+	}
+	return stream, nil
+}
+
+// dialRTSP connects to the RTSP server of u.
+func dialRTSP(ctx context.Context, u *url.URL) (*rtspClient, error) {
+	host := u.Host
+	if u.Port() == "" {
+		port := "554"
+		if u.Scheme == "rtsps" {
+			port = "322"
+		}
+		host = net.JoinHostPort(u.Hostname(), port)
+	}
+
+	dialer := &net.Dialer{Timeout: rtspIOTimeout}
+	var conn net.Conn
+	var err error
+	switch u.Scheme {
+	case "rtsp":
+		conn, err = dialer.DialContext(ctx, "tcp", host)
+	case "rtsps":
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", host)
+	default:
+		return nil, fmt.Errorf("%w: scheme %s", errRTSPTransportUnsupported, u.Scheme)
+	}
+	if err != nil {
+		return nil, errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryRTSP).
+			Context("operation", "dial_rtsp").
+			Build()
+	}
+
+	return &rtspClient{
+		conn:    conn,
+		reader:  bufio.NewReader(conn),
+		user:    u.User,
+		timeout: rtspDefaultSessionTimeout,
+	}, nil
+}
+
+// startAudio describes the session, sets up its audio track and starts playing.
+func (c *rtspClient) startAudio(u *url.URL, outputRate int) (*rtspAudioStream, error) {
+	requestURL := *u
+	requestURL.User = nil
+	base := requestURL.String()
+
+	resp, err := c.request("DESCRIBE", base, map[string]string{"Accept": "application/sdp"})
+	if err != nil {
+		return nil, err
+	}
+	if contentBase := resp.header.Get("Content-Base"); contentBase != "" {
+		base = contentBase
+	} else if location := resp.header.Get("Content-Location"); location != "" {
+		base = location
+	}
+
+	medias := parseSDPAudio(string(resp.body))
+	if len(medias) == 0 {
+		return nil, errors.Newf("stream has no audio track").
+			Component("myaudio").
+			Category(errors.CategoryRTSP).
+			Context("operation", "rtsp_describe").
+			Build()
+	}
+	media := medias[0]
+	if !media.nativelyDecodable() {
+		return nil, fmt.Errorf("%w: %s", errRTSPCodecUnsupported, media.encoding)
+	}
+
+	resp, err = c.request("SETUP", resolveRTSPControl(base, media.control),
+		map[string]string{"Transport": "RTP/AVP/TCP;unicast;interleaved=0-1"})
+	if err != nil {
+		return nil, err
+	}
+	channel := byte(0)
+	if transport := resp.header.Get("Transport"); transport != "" {
+		channel = parseInterleavedChannel(transport)
+	}
+	c.session, c.timeout = parseRTSPSession(resp.header.Get("Session"))
+
+	if _, err := c.request("PLAY", base, map[string]string{"Range": "npt=0.000-"}); err != nil {
+		return nil, err
+	}
+
+	return &rtspAudioStream{
+		client:        c,
+		url:           base,
+		decoder:       newRTPAudioDecoder(media, outputRate),
+		channel:       channel,
+		lastKeepalive: time.Now(),
+	}, nil
+}
+
+// Read returns decoded 16-bit little-endian mono PCM.
+func (s *rtspAudioStream) Read(p []byte) (int, error) {
+	for len(s.pending) == 0 {
+		if time.Since(s.lastKeepalive) >= s.client.timeout/2 {
+			// The response is discarded by readFrame
+			if err := s.client.send("GET_PARAMETER", s.url, nil, rtspIOTimeout); err != nil {
+				return 0, err
+			}
+			s.lastKeepalive = time.Now()
+		}
+
+		channel, packet, err := s.client.readFrame()
+		if err != nil {
+			return 0, err
+		}
+		if channel != s.channel {
+			continue // RTCP
+		}
+		pcm, err := s.decoder.decode(packet)
+		if err != nil {
+			continue // malformed packets are skipped like FFmpeg does
+		}
+		s.pending = pcm
+	}
+
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Close tears down the session and closes the connection.
+func (s *rtspAudioStream) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		_ = s.client.send("TEARDOWN", s.url, nil, rtspTeardownTimeout)
+		err = s.client.conn.Close()
+	})
+	return err
+}
+
+// request sends a request and returns its response, retrying once with
+// credentials when the server asks for authentication.
+func (c *rtspClient) request(method, requestURL string, header map[string]string) (*rtspResponse, error) {
+	for attempt := 0; ; attempt++ {
+		if err := c.send(method, requestURL, header, rtspIOTimeout); err != nil {
+			return nil, err
+		}
+		resp, err := c.readResponse()
+		if err != nil {
+			return nil, err
+		}
+
+		if resp.status == 401 && attempt == 0 && c.user != nil {
+			if challenge := selectAuthChallenge(resp.header.Values("WWW-Authenticate")); challenge != "" {
+				c.auth = challenge
+				continue
+			}
+		}
+		if resp.status != 200 {
+			if rtspRefusal(resp.status) {
+				return nil, fmt.Errorf("%w: %s returned status %d", errRTSPRequestRefused, method, resp.status)
+			}
+			return nil, errors.Newf("RTSP %s failed with status %d", method, resp.status).
+				Component("myaudio").
+				Category(errors.CategoryRTSP).
+				Context("operation", "rtsp_request").
+				Context("method", method).
+				Context("status", resp.status).
+				Build()
+		}
+		return resp, nil
+	}
+}
+
+// rtspRefusal reports whether a response status refuses a request the native
+// client made, such as 461 Unsupported Transport for interleaved TCP or a 401
+// for an authentication scheme it cannot answer. FFmpeg may still read the
+// stream. Other server errors may be transient and are retried.
+func rtspRefusal(status int) bool {
+	switch {
+	case status >= 400 && status < 500:
+		return true
+	case status == 501, status == 505, status == 551: // not implemented, version or option not supported
+		return true
+	default:
+		return false
+	}
+}
+
+// send writes a request.
+func (c *rtspClient) send(method, requestURL string, header map[string]string, timeout time.Duration) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	c.cseq++
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s RTSP/1.0\r\nCSeq: %d\r\nUser-Agent: %s\r\n", method, requestURL, c.cseq, rtspUserAgent)
+	if c.session != "" {
+		fmt.Fprintf(&b, "Session: %s\r\n", c.session)
+	}
+	if c.auth != "" {
+		fmt.Fprintf(&b, "Authorization: %s\r\n", c.authorization(method, requestURL))
+	}
+	for key, value := range header {
+		fmt.Fprintf(&b, "%s: %s\r\n", key, value)
+	}
+	b.WriteString("\r\n")
+
+	if err := c.conn.SetWriteDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return errors.New(err).
+			Component("myaudio").
+			Category(errors.CategoryRTSP).
+			Context("operation", "rtsp_send").
+			Context("method", method).
+			Build()
+	}
+	return nil
+}
+
+// readResponse reads the next response, skipping interleaved frames and
+// requests sent by the server.
+func (c *rtspClient) readResponse() (*rtspResponse, error) {
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(rtspIOTimeout)); err != nil {
+			return nil, err
+		}
+		next, err := c.reader.Peek(1)
+		if err != nil {
+			return nil, err
+		}
+		if next[0] == '$' {
+			if _, _, err := c.readInterleaved(); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		msg, err := c.readMessage()
+		if err != nil {
+			return nil, err
+		}
+		if msg.status != 0 {
+			return msg, nil
+		}
+	}
+}
+
+// readFrame reads the next interleaved frame, skipping RTSP messages such as
+// keepalive responses.
+func (c *rtspClient) readFrame() (channel byte, data []byte, err error) {
+	for {
+		if err := c.conn.SetReadDeadline(time.Now().Add(rtspIOTimeout)); err != nil {
+			return 0, nil, err
+		}
+		next, err := c.reader.Peek(1)
+		if err != nil {
+			return 0, nil, err
+		}
+		if next[0] == '$' {
+			return c.readInterleaved()
+		}
+		if _, err := c.readMessage(); err != nil {
+			return 0, nil, err
+		}
+	}
+}
+
+// readInterleaved reads an interleaved frame. The returned data is valid
+// until the next read.
+func (c *rtspClient) readInterleaved() (channel byte, data []byte, err error) {
+	var header [4]byte
+	if _, err := io.ReadFull(c.reader, header[:]); err != nil {
+		return 0, nil, err
+	}
+	size := int(binary.BigEndian.Uint16(header[2:]))
+	if cap(c.frameBuf) < size {
+		c.frameBuf = make([]byte, size)
+	}
+	data = c.frameBuf[:size]
+	if _, err := io.ReadFull(c.reader, data); err != nil {
+		return 0, nil, err
+	}
+	return header[1], data, nil
+}
+
+// readMessage reads an RTSP response or server request with its body.
+func (c *rtspClient) readMessage() (*rtspResponse, error) {
+	tp := textproto.NewReader(c.reader)
+	line, err := tp.ReadLine()
+	if err != nil {
+		return nil, err
+	}
+	header, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	msg := &rtspResponse{header: header}
+	if proto, rest, found := strings.Cut(line, " "); found && strings.HasPrefix(proto, "RTSP/") {
+		code, _, _ := strings.Cut(rest, " ")
+		if msg.status, err = strconv.Atoi(code); err != nil {
+			return nil, errors.Newf("invalid RTSP status line %q", line).
+				Component("myaudio").
+				Category(errors.CategoryRTSP).
+				Context("operation", "rtsp_read").
+				Build()
+		}
+	}
+
+	if length := header.Get("Content-Length"); length != "" {
+		size, err := strconv.Atoi(length)
+		if err != nil || size < 0 || size > rtspMaxBodySize {
+			return nil, errors.Newf("invalid RTSP content length %q", length).
+				Component("myaudio").
+				Category(errors.CategoryRTSP).
+				Context("operation", "rtsp_read").
+				Build()
+		}
+		msg.body = make([]byte, size)
+		if _, err := io.ReadFull(c.reader, msg.body); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
+
+// authorization returns the Authorization header for a request.
+func (c *rtspClient) authorization(method, uri string) string {
+	username := c.user.Username()
+	password, _ := c.user.Password()
+
+	scheme, params, _ := strings.Cut(c.auth, " ")
+	if strings.EqualFold(scheme, "Basic") {
+		return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+	}
+
+	p := parseAuthParams(params)
+	ha1 := md5Hex(username + ":" + p["realm"] + ":" + password)
+	ha2 := md5Hex(method + ":" + uri)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `Digest username="%s", realm="%s", nonce="%s", uri="%s"`, username, p["realm"], p["nonce"], uri)
+	if slices.Contains(strings.Split(p["qop"], ","), "auth") {
+		nc := fmt.Sprintf("%08x", c.cseq)
+		cnonce := randomHex(8)
+		fmt.Fprintf(&b, `, qop=auth, nc=%s, cnonce="%s", response="%s"`,
+			nc, cnonce, md5Hex(ha1+":"+p["nonce"]+":"+nc+":"+cnonce+":auth:"+ha2))
+	} else {
+		fmt.Fprintf(&b, `, response="%s"`, md5Hex(ha1+":"+p["nonce"]+":"+ha2))
+	}
+	if opaque, ok := p["opaque"]; ok {
+		fmt.Fprintf(&b, `, opaque="%s"`, opaque)
+	}
+	if algorithm, ok := p["algorithm"]; ok {
+		fmt.Fprintf(&b, `, algorithm=%s`, algorithm)
+	}
+	return b.String()
+}
+
+// selectAuthChallenge picks the WWW-Authenticate challenge to answer,
+// preferring MD5 digest over basic authentication.
+func selectAuthChallenge(challenges []string) string {
+	var basic string
+	for _, challenge := range challenges {
+		scheme, params, _ := strings.Cut(challenge, " ")
+		switch {
+		case strings.EqualFold(scheme, "Digest"):
+			if algorithm := parseAuthParams(params)["algorithm"]; algorithm == "" || strings.EqualFold(algorithm, "MD5") {
+				return challenge
+			}
+		case strings.EqualFold(scheme, "Basic"):
+			basic = challenge
+		}
+	}
+	return basic
+}
+
+// parseAuthParams parses the comma separated key=value parameters of an
+// authentication challenge. Quoted values may contain commas.
+func parseAuthParams(params string) map[string]string {
+	result := make(map[string]string)
+	for params != "" {
+		key, rest, found := strings.Cut(params, "=")
+		if !found {
+			break
+		}
+		key = strings.ToLower(strings.TrimSpace(strings.TrimLeft(key, ", ")))
+
+		var value string
+		rest = strings.TrimSpace(rest)
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			value = strings.TrimSpace(value)
+		}
+		result[key] = value
+		params = strings.TrimLeft(rest, ", ")
+	}
+	return result
+}
+
+// parseInterleavedChannel returns the RTP channel of a Transport header.
+func parseInterleavedChannel(transport string) byte {
+	for param := range strings.SplitSeq(transport, ";") {
+		if channels, found := strings.CutPrefix(strings.TrimSpace(param), "interleaved="); found {
+			first, _, _ := strings.Cut(channels, "-")
+			if channel, err := strconv.ParseUint(first, 10, 8); err == nil {
+				return byte(channel)
+			}
+		}
+	}
+	return 0
+}
+
+// parseRTSPSession returns the ID and timeout of a Session header.
+func parseRTSPSession(session string) (id string, timeout time.Duration) {
+	id, params, _ := strings.Cut(session, ";")
+	timeout = rtspDefaultSessionTimeout
+	if seconds, found := strings.CutPrefix(strings.TrimSpace(params), "timeout="); found {
+		if n, err := strconv.Atoi(seconds); err == nil && n > 0 {
+			timeout = time.Duration(n) * time.Second
+		}
+	}
+	return strings.TrimSpace(id), timeout
+}
+
+// resolveRTSPControl resolves a media control attribute against the base URL.
+func resolveRTSPControl(base, control string) string {
+	switch {
+	case control == "" || control == "*":
+		return base
+	case strings.HasPrefix(control, "rtsp://") || strings.HasPrefix(control, "rtsps://"):
+		return control
+	case strings.HasSuffix(base, "/"):
+		return base + control
+	default:
+		return base + "/" + control
+	}
+}
+
+func md5Hex(s string) string {
+	sum := md5.Sum([]byte(s)) //nolint:gosec // G401: required by RTSP digest authentication
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,271 @@
+package myaudio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// fakeRTSPServer serves one session of an SDP and RTP packets over TCP.
+type fakeRTSPServer struct {
+	listener net.Listener
+	sdp      string
+	packets  [][]byte
+	auth     bool   // require digest authentication
+	setup    string // SETUP response status, empty for 200 OK
+
+	mu       sync.Mutex
+	requests []string // "METHOD authorized" of received requests
+}
+
+func startFakeRTSPServer(t *testing.T, sdp string, packets [][]byte, auth bool) *fakeRTSPServer {
+	t.Helper()
+	return serveFakeRTSP(t, &fakeRTSPServer{sdp: sdp, packets: packets, auth: auth})
+}
+
+// serveFakeRTSP starts serving one session of server.
+func serveFakeRTSP(t *testing.T, server *fakeRTSPServer) *fakeRTSPServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server.listener = listener
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		server.serve(conn)
+	}()
+	return server
+}
+
+func (s *fakeRTSPServer) url(credentials string) string {
+	return fmt.Sprintf("rtsp://%s%s/birds", credentials, s.listener.Addr())
+}
+
+func (s *fakeRTSPServer) serve(conn net.Conn) {
+	reader := textproto.NewReader(bufio.NewReader(conn))
+	for {
+		line, err := reader.ReadLine()
+		if err != nil {
+			return
+		}
+		header, err := reader.ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		method := strings.Fields(line)[0]
+		authorization := header.Get("Authorization")
+
+		s.mu.Lock()
+		s.requests = append(s.requests, fmt.Sprintf("%s %t", method, authorization != ""))
+		s.mu.Unlock()
+
+		reply := func(status string, headers ...string) {
+			resp := fmt.Sprintf("RTSP/1.0 %s\r\nCSeq: %s\r\n", status, header.Get("CSeq"))
+			for _, h := range headers {
+				resp += h + "\r\n"
+			}
+			_, _ = io.WriteString(conn, resp+"\r\n")
+		}
+
+		if s.auth && !strings.HasPrefix(authorization, `Digest username="birder"`) {
+			reply("401 Unauthorized", `WWW-Authenticate: Basic realm="cam"`, `WWW-Authenticate: Digest realm="cam", nonce="n0nce", qop="auth"`)
+			continue
+		}
+
+		switch method {
+		case "DESCRIBE":
+			reply("200 OK",
+				"Content-Base: "+s.url("")+"/",
+				"Content-Type: application/sdp",
+				fmt.Sprintf("Content-Length: %d", len(s.sdp)))
+			_, _ = io.WriteString(conn, s.sdp)
+		case "SETUP":
+			if s.setup != "" {
+				reply(s.setup)
+				continue
+			}
+			reply("200 OK", "Transport: RTP/AVP/TCP;unicast;interleaved=2-3", "Session: 12345678;timeout=30")
+		case "PLAY":
+			reply("200 OK", "Session: 12345678")
+			// An RTCP packet precedes the audio
+			_, _ = conn.Write([]byte{'$', 3, 0, 4, 0x80, 200, 0, 0})
+			for _, packet := range s.packets {
+				frame := []byte{'$', 2, 0, 0}
+				binary.BigEndian.PutUint16(frame[2:], uint16(len(packet))) //nolint:gosec // G115: test packets are small
+				_, _ = conn.Write(append(frame, packet...))
+			}
+		case "TEARDOWN":
+			return
+		default:
+			reply("200 OK")
+		}
+	}
+}
+
+func (s *fakeRTSPServer) receivedRequests() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func TestDialRTSPAudio_PCMU(t *testing.T) {
+	t.Parallel()
+	sdp := "v=0\r\nm=audio 0 RTP/AVP 0\r\na=control:trackID=1\r\n\r\n"
+
+	// Two packets of 80 µ-law samples at 8 kHz
+	payload := make([]byte, 80)
+	for i := range payload {
+		payload[i] = 0x80
+	}
+	packets := [][]byte{
+		buildRTPPacket(0, 1, 0, payload),
+		buildRTPPacket(0, 2, 80, payload),
+	}
+	server := startFakeRTSPServer(t, sdp, packets, true)
+
+	stream, err := dialRTSPAudio(t.Context(), server.url("birder:secret@"), 48000)
+	require.NoError(t, err)
+	assert.Equal(t, byte(2), stream.channel)
+	assert.Equal(t, 30*time.Second, stream.client.timeout)
+
+	// 80 samples upsampled to 48 kHz, the span after the last sample of the
+	// second packet is held back
+	pcm := make([]byte, (80*6+79*6)*2)
+	_, err = io.ReadFull(stream, pcm)
+	require.NoError(t, err)
+	for _, sample := range pcmSamples(pcm[12:]) {
+		require.Equal(t, int16(32124), sample)
+	}
+
+	require.NoError(t, stream.Close())
+	assert.Eventually(t, func() bool {
+		requests := server.receivedRequests()
+		return len(requests) > 0 && requests[len(requests)-1] == "TEARDOWN true"
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"DESCRIBE false", "DESCRIBE true", "SETUP true", "PLAY true", "TEARDOWN true"}, server.receivedRequests())
+}
+
+func TestDialRTSPAudio_UnsupportedCodec(t *testing.T) {
+	t.Parallel()
+	sdp := "v=0\r\nm=audio 0 RTP/AVP 97\r\na=rtpmap:97 mpeg4-generic/48000/2\r\n\r\n"
+	server := startFakeRTSPServer(t, sdp, nil, false)
+
+	_, err := dialRTSPAudio(t.Context(), server.url(""), 48000)
+	require.ErrorIs(t, err, errRTSPCodecUnsupported)
+	assert.Contains(t, err.Error(), "MPEG4-GENERIC")
+}
+
+func TestDialRTSPAudio_Refused(t *testing.T) {
+	t.Parallel()
+	sdp := "v=0\r\nm=audio 0 RTP/AVP 0\r\n\r\n"
+
+	// Interleaved TCP transport refused
+	server := serveFakeRTSP(t, &fakeRTSPServer{sdp: sdp, setup: "461 Unsupported Transport"})
+	_, err := dialRTSPAudio(t.Context(), server.url(""), 48000)
+	require.ErrorIs(t, err, errRTSPRequestRefused)
+	assert.Contains(t, err.Error(), "SETUP returned status 461")
+
+	// Authentication required without credentials
+	server = serveFakeRTSP(t, &fakeRTSPServer{sdp: sdp, auth: true})
+	_, err = dialRTSPAudio(t.Context(), server.url(""), 48000)
+	require.ErrorIs(t, err, errRTSPRequestRefused)
+}
+
+func TestRTSPRefusal(t *testing.T) {
+	t.Parallel()
+	for status, refused := range map[int]bool{400: true, 401: true, 404: true, 461: true, 501: true, 551: true, 500: false, 503: false} {
+		assert.Equal(t, refused, rtspRefusal(status), "status %d", status)
+	}
+}
+
+// TestStartNativeSession_Fallback verifies that refused requests and repeated
+// failures switch a stream to FFmpeg.
+func TestStartNativeSession_Fallback(t *testing.T) {
+	settings := conf.Setting()
+	oldNative := settings.Realtime.RTSP.NativeClient
+	settings.Realtime.RTSP.NativeClient = true
+	t.Cleanup(func() { settings.Realtime.RTSP.NativeClient = oldNative })
+
+	newStream := func(url string) *FFmpegStream {
+		stream := NewFFmpegStream(url, "tcp", make(chan UnifiedAudioData, 1))
+		stream.ctx = t.Context()
+		return stream
+	}
+
+	t.Run("refused", func(t *testing.T) {
+		server := serveFakeRTSP(t, &fakeRTSPServer{sdp: "v=0\r\nm=audio 0 RTP/AVP 0\r\n\r\n", setup: "461 Unsupported Transport"})
+		stream := newStream(server.url(""))
+
+		started, err := stream.startNativeSession()
+		require.NoError(t, err)
+		assert.False(t, started)
+		assert.True(t, stream.nativeFallback)
+	})
+
+	t.Run("unreachable", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		addr := listener.Addr().String()
+		require.NoError(t, listener.Close())
+		stream := newStream("rtsp://" + addr + "/birds")
+
+		for range maxNativeSessionFailures - 1 {
+			started, err := stream.startNativeSession()
+			require.Error(t, err)
+			assert.False(t, started)
+			assert.False(t, stream.nativeFallback)
+		}
+		started, err := stream.startNativeSession()
+		require.NoError(t, err)
+		assert.False(t, started)
+		assert.True(t, stream.nativeFallback)
+	})
+}
+
+func TestParseAuthParams(t *testing.T) {
+	t.Parallel()
+	params := parseAuthParams(`realm="IP Camera", nonce="a,b", qop="auth,auth-int", algorithm=MD5, stale=FALSE`)
+	assert.Equal(t, map[string]string{
+		"realm":     "IP Camera",
+		"nonce":     "a,b",
+		"qop":       "auth,auth-int",
+		"algorithm": "MD5",
+		"stale":     "FALSE",
+	}, params)
+
+	assert.Equal(t, `Digest realm="x"`, selectAuthChallenge([]string{`Basic realm="x"`, `Digest realm="x"`}))
+	assert.Equal(t, `Basic realm="x"`, selectAuthChallenge([]string{`Digest realm="x", algorithm=SHA-256`, `Basic realm="x"`}))
+}
+
+func TestResolveRTSPControl(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		base, control, want string
+	}{
+		{"rtsp://cam/live", "", "rtsp://cam/live"},
+		{"rtsp://cam/live", "*", "rtsp://cam/live"},
+		{"rtsp://cam/live/", "trackID=1", "rtsp://cam/live/trackID=1"},
+		{"rtsp://cam/live", "trackID=1", "rtsp://cam/live/trackID=1"},
+		{"rtsp://cam/live", "rtsp://cam/audio", "rtsp://cam/audio"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, resolveRTSPControl(tt.base, tt.control))
+	}
+}
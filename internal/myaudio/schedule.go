@@ -0,0 +1,223 @@
+// schedule.go: evaluation of per-source recording schedules
+package myaudio
+
+import (
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+// scheduleCheckInterval is how often schedules are re-evaluated to idle or
+// resume sources at window boundaries.
+const scheduleCheckInterval = 30 * time.Second
+
+// SunEventSource provides sun event times for sun relative schedules.
+type SunEventSource interface {
+	GetSunEventTimes(date time.Time) (suncalc.SunEventTimes, error)
+}
+
+// ScheduleState is the evaluated state of a source's recording schedule.
+type ScheduleState struct {
+	Active     bool      // whether the source is inside a schedule window
+	NextChange time.Time // when Active changes next, zero if unknown
+	Error      string    // evaluation error, the source stays active on errors
+	CheckedAt  time.Time // when the schedule was evaluated
+}
+
+// scheduleInterval is a resolved window on a specific day.
+type scheduleInterval struct {
+	start, end time.Time
+}
+
+// Schedule states of scheduled sources, keyed by stream URL or audio device name
+var (
+	scheduleStates   = make(map[string]ScheduleState)
+	scheduleStatesMu sync.RWMutex
+
+	scheduleSun       *suncalc.SunCalc
+	scheduleSunCoords [2]float64
+	scheduleSunMu     sync.Mutex
+)
+
+// EvaluateSchedule returns the state of schedule at now. A disabled schedule
+// is always active. Window times are resolved in the location of now.
+func EvaluateSchedule(schedule *conf.ScheduleSettings, now time.Time, sun SunEventSource) (ScheduleState, error) {
+	state := ScheduleState{Active: true, CheckedAt: now}
+	if schedule == nil || !schedule.Enabled {
+		return state, nil
+	}
+
+	// Resolve windows from yesterday, which may continue past midnight, to
+	// tomorrow, which holds the next start after today's last window
+	var intervals []scheduleInterval
+	for dayOffset := -1; dayOffset <= 1; dayOffset++ {
+		day := time.Date(now.Year(), now.Month(), now.Day()+dayOffset, 0, 0, 0, 0, now.Location())
+		for i := range schedule.Windows {
+			interval, err := resolveScheduleWindow(&schedule.Windows[i], day, sun)
+			if err != nil {
+				return state, err
+			}
+			intervals = append(intervals, interval)
+		}
+	}
+	intervals = mergeScheduleIntervals(intervals)
+
+	state.Active = false
+	for _, interval := range intervals {
+		if !now.Before(interval.start) && now.Before(interval.end) {
+			state.Active = true
+			state.NextChange = interval.end
+			return state, nil
+		}
+		if interval.start.After(now) {
+			state.NextChange = interval.start
+			return state, nil
+		}
+	}
+	return state, nil
+}
+
+// resolveScheduleWindow resolves a window starting on day. A window ending
+// before it starts ends on the following day.
+func resolveScheduleWindow(window *conf.ScheduleWindow, day time.Time, sun SunEventSource) (scheduleInterval, error) {
+	startSpec, err := conf.ParseScheduleTime(window.Start)
+	if err != nil {
+		return scheduleInterval{}, err
+	}
+	endSpec, err := conf.ParseScheduleTime(window.End)
+	if err != nil {
+		return scheduleInterval{}, err
+	}
+
+	start, err := resolveScheduleTime(startSpec, day, sun)
+	if err != nil {
+		return scheduleInterval{}, err
+	}
+	end, err := resolveScheduleTime(endSpec, day, sun)
+	if err != nil {
+		return scheduleInterval{}, err
+	}
+	if !end.After(start) {
+		end, err = resolveScheduleTime(endSpec, day.AddDate(0, 0, 1), sun)
+		if err != nil {
+			return scheduleInterval{}, err
+		}
+	}
+	return scheduleInterval{start: start, end: end}, nil
+}
+
+// resolveScheduleTime returns the time of spec on day.
+func resolveScheduleTime(spec conf.ScheduleTime, day time.Time, sun SunEventSource) (time.Time, error) {
+	if spec.Event == "" {
+		// Build from the wall-clock fields; adding the duration to midnight
+		// would be off by the DST shift on days the clocks change
+		return time.Date(day.Year(), day.Month(), day.Day(),
+			int(spec.Clock/time.Hour), int(spec.Clock%time.Hour/time.Minute), 0, 0, day.Location()), nil
+	}
+	if sun == nil {
+		return time.Time{}, errors.Newf("sun event times are not available for %s", spec.Event).
+			Component("myaudio").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "resolve_schedule_time").
+			Build()
+	}
+
+	times, err := sun.GetSunEventTimes(day)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var event time.Time
+	switch spec.Event {
+	case conf.ScheduleEventDawn:
+		event = times.CivilDawn
+	case conf.ScheduleEventSunrise:
+		event = times.Sunrise
+	case conf.ScheduleEventSunset:
+		event = times.Sunset
+	case conf.ScheduleEventDusk:
+		event = times.CivilDusk
+	}
+	return event.Add(spec.Offset), nil
+}
+
+// mergeScheduleIntervals sorts intervals and merges those that overlap or touch.
+func mergeScheduleIntervals(intervals []scheduleInterval) []scheduleInterval {
+	slices.SortFunc(intervals, func(a, b scheduleInterval) int {
+		return a.start.Compare(b.start)
+	})
+	merged := intervals[:0]
+	for _, interval := range intervals {
+		if n := len(merged); n > 0 && !interval.start.After(merged[n-1].end) {
+			if interval.end.After(merged[n-1].end) {
+				merged[n-1].end = interval.end
+			}
+			continue
+		}
+		merged = append(merged, interval)
+	}
+	return merged
+}
+
+// evaluateSourceSchedule evaluates a schedule with the configured station
+// location and records the state under key. Errors leave the source active so
+// a misconfiguration never silently stops recording.
+func evaluateSourceSchedule(key string, schedule *conf.ScheduleSettings, now time.Time) ScheduleState {
+	if !schedule.Enabled {
+		clearScheduleState(key)
+		return ScheduleState{Active: true, CheckedAt: now}
+	}
+
+	state, err := EvaluateSchedule(schedule, now, getScheduleSunCalc())
+	if err != nil {
+		state = ScheduleState{Active: true, Error: err.Error(), CheckedAt: now}
+	}
+
+	scheduleStatesMu.Lock()
+	previous, known := scheduleStates[key]
+	scheduleStates[key] = state
+	scheduleStatesMu.Unlock()
+
+	if err != nil && (!known || previous.Error == "") {
+		GetLogger().Warn("recording schedule evaluation failed, source stays active",
+			logger.Error(err),
+			logger.String("operation", "evaluate_schedule"))
+	}
+	return state
+}
+
+// clearScheduleState removes the recorded state of a source.
+func clearScheduleState(key string) {
+	scheduleStatesMu.Lock()
+	delete(scheduleStates, key)
+	scheduleStatesMu.Unlock()
+}
+
+// GetScheduleState returns the last evaluated schedule state of a source,
+// keyed by stream URL or audio device name. ok is false if the source has
+// no enabled schedule.
+func GetScheduleState(key string) (state ScheduleState, ok bool) {
+	scheduleStatesMu.RLock()
+	defer scheduleStatesMu.RUnlock()
+	state, ok = scheduleStates[key]
+	return state, ok
+}
+
+// getScheduleSunCalc returns a sun calculator for the configured station
+// location, recreating it if the location changes.
+func getScheduleSunCalc() *suncalc.SunCalc {
+	settings := conf.Setting()
+	coords := [2]float64{settings.BirdNET.Latitude, settings.BirdNET.Longitude}
+
+	scheduleSunMu.Lock()
+	defer scheduleSunMu.Unlock()
+	if scheduleSun == nil || scheduleSunCoords != coords {
+		scheduleSun = suncalc.NewSunCalc(coords[0], coords[1])
+		scheduleSunCoords = coords
+	}
+	return scheduleSun
+}
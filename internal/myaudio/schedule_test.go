@@ -0,0 +1,127 @@
+package myaudio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/suncalc"
+)
+
+// fixedSun returns the same sun event times of day for every date.
+type fixedSun struct{}
+
+func (fixedSun) GetSunEventTimes(date time.Time) (suncalc.SunEventTimes, error) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(date.Year(), date.Month(), date.Day(), hour, minute, 0, 0, date.Location())
+	}
+	return suncalc.SunEventTimes{
+		CivilDawn: at(5, 0),
+		Sunrise:   at(5, 40),
+		Sunset:    at(21, 0),
+		CivilDusk: at(21, 40),
+	}, nil
+}
+
+func TestEvaluateSchedule(t *testing.T) {
+	t.Parallel()
+	day := func(hour, minute int) time.Time {
+		return time.Date(2026, 6, 15, hour, minute, 0, 0, time.UTC)
+	}
+
+	dawnChorus := &conf.ScheduleSettings{Enabled: true, Windows: []conf.ScheduleWindow{
+		{Start: "dawn-60", End: "dawn+180"},
+	}}
+	overnight := &conf.ScheduleSettings{Enabled: true, Windows: []conf.ScheduleWindow{
+		{Start: "sunset", End: "02:00"},
+	}}
+	overlapping := &conf.ScheduleSettings{Enabled: true, Windows: []conf.ScheduleWindow{
+		{Start: "06:00", End: "08:00"},
+		{Start: "07:00", End: "09:00"},
+	}}
+
+	tests := []struct {
+		name       string
+		schedule   *conf.ScheduleSettings
+		now        time.Time
+		wantActive bool
+		wantNext   time.Time
+	}{
+		{"disabled schedule is active", &conf.ScheduleSettings{}, day(12, 0), true, time.Time{}},
+		{"before dawn window", dawnChorus, day(3, 0), false, day(4, 0)},
+		{"window start is inclusive", dawnChorus, day(4, 0), true, day(8, 0)},
+		{"inside dawn window", dawnChorus, day(6, 0), true, day(8, 0)},
+		{"after dawn window", dawnChorus, day(8, 0), false, time.Date(2026, 6, 16, 4, 0, 0, 0, time.UTC)},
+		{"overnight before midnight", overnight, day(23, 0), true, time.Date(2026, 6, 16, 2, 0, 0, 0, time.UTC)},
+		{"overnight after midnight", overnight, day(1, 0), true, day(2, 0)},
+		{"overnight idle during day", overnight, day(12, 0), false, day(21, 0)},
+		{"overlapping windows merge", overlapping, day(7, 30), true, day(9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			state, err := EvaluateSchedule(tt.schedule, tt.now, fixedSun{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantActive, state.Active)
+			assert.True(t, tt.wantNext.Equal(state.NextChange), "next change %s, want %s", state.NextChange, tt.wantNext)
+		})
+	}
+}
+
+func TestEvaluateSchedule_Errors(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+
+	invalid := &conf.ScheduleSettings{Enabled: true, Windows: []conf.ScheduleWindow{{Start: "soon", End: "later"}}}
+	state, err := EvaluateSchedule(invalid, now, fixedSun{})
+	require.Error(t, err)
+	assert.True(t, state.Active, "sources stay active when a schedule cannot be evaluated")
+
+	sunRelative := &conf.ScheduleSettings{Enabled: true, Windows: []conf.ScheduleWindow{{Start: "sunrise", End: "sunset"}}}
+	_, err = EvaluateSchedule(sunRelative, now, nil)
+	require.Error(t, err)
+}
+
+func TestResolveScheduleTime_DST(t *testing.T) {
+	t.Parallel()
+	loc, err := time.LoadLocation("Europe/Helsinki")
+	if err != nil {
+		t.Skipf("time zone data not available: %v", err)
+	}
+
+	// Clocks go forward from 03:00 to 04:00 on 2026-03-29 and back from
+	// 04:00 to 03:00 on 2026-10-25
+	for _, date := range []time.Time{
+		time.Date(2026, 3, 29, 12, 0, 0, 0, loc),
+		time.Date(2026, 10, 25, 12, 0, 0, 0, loc),
+	} {
+		spec, err := conf.ParseScheduleTime("05:30")
+		require.NoError(t, err)
+		got, err := resolveScheduleTime(spec, date, nil)
+		require.NoError(t, err)
+		want := time.Date(date.Year(), date.Month(), date.Day(), 5, 30, 0, 0, loc)
+		assert.True(t, want.Equal(got), "resolved %s, want %s", got, want)
+	}
+}
+
+func TestEvaluateSourceSchedule_RecordsState(t *testing.T) {
+	key := "rtsp://schedule-test.local/stream"
+	t.Cleanup(func() { clearScheduleState(key) })
+
+	schedule := &conf.ScheduleSettings{Enabled: true, Windows: []conf.ScheduleWindow{{Start: "06:00", End: "07:00"}}}
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.UTC)
+	state := evaluateSourceSchedule(key, schedule, now)
+	assert.False(t, state.Active)
+
+	recorded, ok := GetScheduleState(key)
+	require.True(t, ok)
+	assert.Equal(t, state, recorded)
+
+	schedule.Enabled = false
+	assert.True(t, evaluateSourceSchedule(key, schedule, now).Active)
+	_, ok = GetScheduleState(key)
+	assert.False(t, ok, "state is cleared when the schedule is disabled")
+}
@@ -4,6 +4,7 @@ package myaudio
 import (
 	"fmt"
 	"math"
+	"slices"
 	"sync"
 	"time"
 
@@ -28,6 +29,15 @@ type SoundLevelData struct {
 	Name        string                    `json:"name"`
 	Duration    int                       `json:"duration_seconds"`
 	OctaveBands map[string]OctaveBandData `json:"octave_bands"`
+	// Overall levels of the interval in the configured frequency weighting
+	Weighting string  `json:"weighting"` // A, C or Z
+	Leq       float64 `json:"leq_db"`    // equivalent continuous level
+	LMin      float64 `json:"lmin_db"`   // quietest one-second level
+	LMax      float64 `json:"lmax_db"`   // loudest one-second level
+	// Calibrated is true when all levels are in dB SPL rather than dBFS
+	Calibrated bool `json:"calibrated"`
+	// AcousticIndices holds the indices of the minutes completed during this interval
+	AcousticIndices []AcousticIndices `json:"acoustic_indices,omitempty"`
 }
 
 // Standard 1/3rd octave band center frequencies (Hz) - ISO 266 standard
@@ -60,9 +70,53 @@ type soundLevelProcessor struct {
 	intervalBuffer *intervalAggregator
 	interval       int // interval in seconds
 
+	// Acoustic indices, nil when disabled
+	indices        *acousticIndexAccumulator
+	pendingIndices []AcousticIndices
+
+	// Broadband level meters, keyed by frequency weighting
+	meters map[string]*levelMeter
+
+	// Calibration tone measurement in progress, nil when idle
+	probe *calibrationProbe
+
+	// calibration returns the dB SPL offset of a source, false if uncalibrated
+	calibration func(source string) (float64, bool)
+
 	mutex sync.RWMutex
 }
 
+// soundLevelWeightings are the frequency weightings measured by every
+// processor, so the configured weighting can change at runtime
+var soundLevelWeightings = []string{conf.WeightingA, conf.WeightingC, conf.WeightingZ}
+
+// levelMeter measures the broadband level of one second in a frequency weighting
+type levelMeter struct {
+	filter     *weightingFilter // nil for the Z weighting
+	sum        float64
+	sumSquares float64
+	count      int
+	level      float64 // level of the last completed second in dBFS
+}
+
+// add accumulates a sample and computes the level once target samples were
+// added. The mean is removed so that a DC offset does not count as sound.
+func (m *levelMeter) add(sample float64, target int) {
+	if m.filter != nil {
+		sample = m.filter.process(sample)
+	}
+	m.sum += sample
+	m.sumSquares += sample * sample
+	m.count++
+	if m.count < target {
+		return
+	}
+	mean := m.sum / float64(m.count)
+	rms := math.Sqrt(max(m.sumSquares/float64(m.count)-mean*mean, 0))
+	m.level = 20 * math.Log10(max(rms, 1e-10))
+	m.sum, m.sumSquares, m.count = 0, 0, 0
+}
+
 // octaveBandBuffer accumulates samples for 1-second intervals
 type octaveBandBuffer struct {
 	samples           []float64
@@ -73,6 +127,7 @@ type octaveBandBuffer struct {
 // intervalAggregator collects 1-second measurements to produce interval statistics
 type intervalAggregator struct {
 	secondMeasurements []map[string]float64 // Array of second measurements
+	overallLevels      map[string][]float64 // Broadband level of each second, keyed by weighting
 	startTime          time.Time
 	currentIndex       int
 	measurementCount   int // Track number of completed 1-second measurements
@@ -107,8 +162,20 @@ func newSoundLevelProcessor(source, name string) (*soundLevelProcessor, error) {
 		interval:      interval,
 		intervalBuffer: &intervalAggregator{
 			secondMeasurements: make([]map[string]float64, interval),
+			overallLevels:      make(map[string][]float64, len(soundLevelWeightings)),
 			startTime:          time.Now(),
 		},
+		meters:      make(map[string]*levelMeter, len(soundLevelWeightings)),
+		calibration: soundLevelCalibration,
+	}
+
+	for _, weighting := range soundLevelWeightings {
+		processor.meters[weighting] = &levelMeter{filter: newWeightingFilter(weighting, float64(conf.SampleRate))}
+		processor.intervalBuffer.overallLevels[weighting] = make([]float64, interval)
+	}
+
+	if conf.Setting().Realtime.Audio.SoundLevel.AcousticIndices {
+		processor.indices = newAcousticIndexAccumulator(conf.SampleRate)
 	}
 
 	// Initialize filters for each 1/3rd octave band
@@ -311,6 +378,17 @@ func (p *soundLevelProcessor) ProcessAudioData(samples []byte) (*SoundLevelData,
 		}
 	}
 
+	if p.indices != nil {
+		p.pendingIndices = append(p.pendingIndices, p.indices.add(audioSamples, time.Now())...)
+	}
+
+	// Broadband levels of each second for the overall level
+	for _, meter := range p.meters {
+		for _, sample := range audioSamples {
+			meter.add(sample, p.sampleRate)
+		}
+	}
+
 	// Track if any band completed a 1-second measurement in this call
 	measurementCompleted := false
 
@@ -385,6 +463,14 @@ func (p *soundLevelProcessor) ProcessAudioData(samples []byte) (*SoundLevelData,
 
 	// If a 1-second measurement was completed, update aggregator state
 	if measurementCompleted {
+		currentIdx := p.intervalBuffer.currentIndex
+		for weighting, meter := range p.meters {
+			p.intervalBuffer.overallLevels[weighting][currentIdx] = meter.level
+		}
+		if p.probe != nil && p.probe.add(p.intervalBuffer.secondMeasurements[currentIdx], p.meters[conf.WeightingZ].level) {
+			p.probe = nil
+		}
+
 		// Move to next index after all bands have stored their measurements
 		p.intervalBuffer.currentIndex = (p.intervalBuffer.currentIndex + 1) % p.interval
 		p.intervalBuffer.measurementCount++
@@ -427,6 +513,7 @@ func calculateRMS(samples []float64) float64 {
 // generateSoundLevelData creates SoundLevelData from interval aggregated measurements
 func (p *soundLevelProcessor) generateSoundLevelData() *SoundLevelData {
 	octaveBands := make(map[string]OctaveBandData)
+	offset, calibrated := p.calibration(p.source)
 
 	// For each octave band, calculate min/max/mean from the interval one-second measurements
 	for _, filter := range p.filters {
@@ -473,21 +560,47 @@ func (p *soundLevelProcessor) generateSoundLevelData() *SoundLevelData {
 
 			octaveBands[bandKey] = OctaveBandData{
 				CenterFreq:  filter.centerFreq,
-				Min:         minVal,
-				Max:         maxVal,
-				Mean:        mean,
+				Min:         minVal + offset,
+				Max:         maxVal + offset,
+				Mean:        mean + offset,
 				SampleCount: len(values),
 			}
 		}
 	}
 
-	return &SoundLevelData{
+	weighting := soundLevelWeighting()
+	leq, lmin, lmax := p.overallLevels(weighting)
+
+	data := &SoundLevelData{
 		Timestamp:   time.Now(),
 		Source:      p.source,
 		Name:        p.name,
 		Duration:    p.interval, // Use configured interval
 		OctaveBands: octaveBands,
+		Weighting:   weighting,
+		Leq:         leq + offset,
+		LMin:        lmin + offset,
+		LMax:        lmax + offset,
+		Calibrated:  calibrated,
+	}
+	if len(p.pendingIndices) > 0 {
+		data.AcousticIndices = p.pendingIndices
+		p.pendingIndices = nil
 	}
+	return data
+}
+
+// overallLevels returns the equivalent, quietest and loudest one-second
+// broadband level of the interval in dBFS in the frequency weighting.
+func (p *soundLevelProcessor) overallLevels(weighting string) (leq, lmin, lmax float64) {
+	levels := p.intervalBuffer.overallLevels[weighting]
+	if count := min(p.intervalBuffer.measurementCount, len(levels)); count < len(levels) {
+		levels = levels[:count]
+	}
+	if len(levels) == 0 {
+		return -100.0, -100.0, -100.0
+	}
+	return energyMeanDB(levels), slices.Min(levels), slices.Max(levels)
 }
 
 // resetIntervalBuffer resets the interval aggregation buffer
@@ -501,6 +614,9 @@ func (p *soundLevelProcessor) resetIntervalBuffer() {
 	for i := range p.intervalBuffer.secondMeasurements {
 		clear(p.intervalBuffer.secondMeasurements[i])
 	}
+	for _, levels := range p.intervalBuffer.overallLevels {
+		clear(levels)
+	}
 }
 
 // formatBandKey creates a consistent key for octave band data
@@ -0,0 +1,296 @@
+// soundlevel_calibration.go: frequency weighting and SPL calibration of sound levels
+package myaudio
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"slices"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Calibration tone measurement parameters
+const (
+	// CalibrationToneFrequency is the frequency of the reference tone in Hz
+	CalibrationToneFrequency = 1000.0
+
+	calibrationMinToneLevel  = -70.0 // dBFS below which the tone is lost in noise
+	calibrationMaxToneLevel  = -4.0  // dBFS above which a sine may clip, a full scale sine is -3 dBFS
+	calibrationMaxToneSpread = 1.0   // dB the one-second tone levels may vary
+	calibrationMaxNoise      = 3.0   // dB the broadband level may exceed the tone level
+	calibrationMaxSeconds    = 60    // longest measurement in seconds
+)
+
+// CalibrationMeasurement is the level of a reference tone measured at a source.
+type CalibrationMeasurement struct {
+	ToneLevel      float64 // energy mean of the 1 kHz band in dBFS
+	BroadbandLevel float64 // energy mean of the unweighted broadband level in dBFS
+	Spread         float64 // difference of the loudest and quietest one-second tone level in dB
+	Seconds        int     // seconds measured
+}
+
+// calibrationProbe collects one-second levels for a tone measurement while
+// the processor analyzes audio.
+type calibrationProbe struct {
+	seconds         int
+	toneLevels      []float64
+	broadbandLevels []float64
+	done            chan CalibrationMeasurement
+}
+
+// add records the levels of a completed second and reports the measurement
+// once enough seconds were collected. It returns true when done.
+func (c *calibrationProbe) add(bandLevels map[string]float64, broadbandLevel float64) bool {
+	tone, ok := bandLevels[formatBandKey(CalibrationToneFrequency)]
+	if !ok {
+		return false
+	}
+	c.toneLevels = append(c.toneLevels, tone)
+	c.broadbandLevels = append(c.broadbandLevels, broadbandLevel)
+	if len(c.toneLevels) < c.seconds {
+		return false
+	}
+
+	c.done <- CalibrationMeasurement{
+		ToneLevel:      energyMeanDB(c.toneLevels),
+		BroadbandLevel: energyMeanDB(c.broadbandLevels),
+		Spread:         slices.Max(c.toneLevels) - slices.Min(c.toneLevels),
+		Seconds:        len(c.toneLevels),
+	}
+	return true
+}
+
+// MeasureCalibrationTone measures the level of a 1 kHz reference tone, as
+// from an acoustic calibrator, at a source with a registered sound level
+// processor. It returns ErrCalibrationToneNotFound if the audio is not a
+// steady tone at a usable level.
+func MeasureCalibrationTone(ctx context.Context, sourceID string, duration time.Duration) (*CalibrationMeasurement, error) {
+	soundLevelProcessorMutex.RLock()
+	processor, exists := soundLevelProcessors[sourceID]
+	soundLevelProcessorMutex.RUnlock()
+	if !exists {
+		return nil, errors.New(ErrSoundLevelProcessorNotRegistered).
+			Context("operation", "measure_calibration_tone").
+			Context("source", sourceID).
+			Build()
+	}
+
+	seconds := min(max(int(duration.Seconds()), 1), calibrationMaxSeconds)
+	probe := &calibrationProbe{seconds: seconds, done: make(chan CalibrationMeasurement, 1)}
+
+	processor.mutex.Lock()
+	if processor.probe != nil {
+		processor.mutex.Unlock()
+		return nil, ErrCalibrationInProgress
+	}
+	processor.probe = probe
+	processor.mutex.Unlock()
+
+	var measurement CalibrationMeasurement
+	select {
+	case measurement = <-probe.done:
+	case <-ctx.Done():
+		processor.mutex.Lock()
+		if processor.probe == probe {
+			processor.probe = nil
+		}
+		processor.mutex.Unlock()
+		return nil, errors.New(ctx.Err()).
+			Component("myaudio").
+			Category(errors.CategoryTimeout).
+			Context("operation", "measure_calibration_tone").
+			Context("source", sourceID).
+			Build()
+	}
+
+	if err := measurement.validate(); err != nil {
+		return &measurement, err
+	}
+	return &measurement, nil
+}
+
+// validate checks that the measured audio was a steady reference tone.
+func (m *CalibrationMeasurement) validate() error {
+	reason := ""
+	switch {
+	case m.ToneLevel < calibrationMinToneLevel:
+		reason = "no 1 kHz tone, the level is too low"
+	case m.ToneLevel > calibrationMaxToneLevel:
+		reason = "the tone is too loud and may clip, reduce the input gain"
+	case m.BroadbandLevel-m.ToneLevel > calibrationMaxNoise:
+		reason = "the audio is not a 1 kHz tone or the background noise is too loud"
+	case m.Spread > calibrationMaxToneSpread:
+		reason = "the tone level was not steady"
+	default:
+		return nil
+	}
+	return errors.New(ErrCalibrationToneNotFound).
+		Context("reason", reason).
+		Context("tone_level", m.ToneLevel).
+		Context("broadband_level", m.BroadbandLevel).
+		Build()
+}
+
+// SourceCalibration returns the calibration settings of the configured
+// source with the registry ID sourceID, or nil if the source is not
+// configured. Channels of a split device share the device calibration.
+func SourceCalibration(settings *conf.Settings, sourceID string) *conf.CalibrationSettings {
+	registry := GetRegistry()
+	if registry == nil || settings == nil {
+		return nil
+	}
+	source, ok := registry.GetSourceByID(sourceID)
+	if !ok {
+		return nil
+	}
+	connection, err := source.GetConnectionString()
+	if err != nil {
+		return nil
+	}
+
+	for i := range settings.Realtime.RTSP.Streams {
+		if settings.Realtime.RTSP.Streams[i].URL == connection {
+			return &settings.Realtime.RTSP.Streams[i].Calibration
+		}
+	}
+	if settings.Realtime.Audio.Source != "" && settings.Realtime.Audio.Source == connection {
+		return &settings.Realtime.Audio.Calibration
+	}
+	for i := range settings.Realtime.Audio.Devices {
+		device := &settings.Realtime.Audio.Devices[i]
+		for _, deviceSource := range DeviceSources(device) {
+			if deviceSource.Connection == connection {
+				return &device.Calibration
+			}
+		}
+	}
+	return nil
+}
+
+// soundLevelCalibration returns the dB SPL offset of a source, and false if
+// the source is not calibrated.
+func soundLevelCalibration(sourceID string) (float64, bool) {
+	calibration := SourceCalibration(conf.Setting(), sourceID)
+	if calibration == nil {
+		return 0, false
+	}
+	return calibration.SPLOffset()
+}
+
+// soundLevelWeighting returns the configured frequency weighting of overall levels.
+func soundLevelWeighting() string {
+	weighting, err := conf.NormalizeWeighting(conf.Setting().Realtime.Audio.SoundLevel.Weighting)
+	if err != nil {
+		return conf.WeightingZ
+	}
+	return weighting
+}
+
+// Pole frequencies in Hz of the A and C weighting, IEC 61672-1
+const (
+	weightingPole1 = 20.598997
+	weightingPole2 = 107.65265
+	weightingPole3 = 737.86223
+	weightingPole4 = 12194.217
+)
+
+// biquad is a second order IIR section in direct form I
+type biquad struct {
+	b0, b1, b2, a1, a2 float64
+	x1, x2, y1, y2     float64
+}
+
+// process filters a sample
+func (f *biquad) process(x float64) float64 {
+	y := f.b0*x + f.b1*f.x1 + f.b2*f.x2 - f.a1*f.y1 - f.a2*f.y2
+	f.x2, f.x1 = f.x1, x
+	f.y2, f.y1 = f.y1, y
+	return y
+}
+
+// response returns the magnitude of the section's response at freq
+func (f *biquad) response(freq, sampleRate float64) float64 {
+	z := cmplx.Exp(complex(0, -2*math.Pi*freq/sampleRate)) // z^-1
+	num := complex(f.b0, 0) + complex(f.b1, 0)*z + complex(f.b2, 0)*z*z
+	den := 1 + complex(f.a1, 0)*z + complex(f.a2, 0)*z*z
+	return cmplx.Abs(num / den)
+}
+
+// bilinearBiquad converts the analog section (b0 s² + b1 s + b2) / (a0 s² +
+// a1 s + a2) to a digital biquad with the bilinear transform.
+func bilinearBiquad(b0, b1, b2, a0, a1, a2, sampleRate float64) *biquad {
+	k := 2 * sampleRate
+	kk := k * k
+	norm := a0*kk + a1*k + a2
+	return &biquad{
+		b0: (b0*kk + b1*k + b2) / norm,
+		b1: (2*b2 - 2*b0*kk) / norm,
+		b2: (b0*kk - b1*k + b2) / norm,
+		a1: (2*a2 - 2*a0*kk) / norm,
+		a2: (a0*kk - a1*k + a2) / norm,
+	}
+}
+
+// weightingFilter applies the A or C frequency weighting to audio samples
+type weightingFilter struct {
+	sections []*biquad
+	gain     float64
+}
+
+// newWeightingFilter returns a filter for weighting, or nil for the Z
+// weighting. The filter is normalized to 0 dB at 1 kHz.
+func newWeightingFilter(weighting string, sampleRate float64) *weightingFilter {
+	// Pole frequencies are prewarped so the bilinear transform keeps them,
+	// which keeps the weighting within 0.6 dB of IEC 61672-1 up to 10 kHz at 48 kHz
+	w := func(f float64) float64 { return 2 * sampleRate * math.Tan(math.Pi*f/sampleRate) }
+	w1, w2, w3, w4 := w(weightingPole1), w(weightingPole2), w(weightingPole3), w(weightingPole4)
+
+	// Both weightings are high passes at w1 and low passes at w4, A adds
+	// a high pass at w2 and w3
+	filter := &weightingFilter{gain: 1}
+	switch weighting {
+	case conf.WeightingA:
+		filter.sections = []*biquad{
+			bilinearBiquad(1, 0, 0, 1, 2*w1, w1*w1, sampleRate),
+			bilinearBiquad(1, 0, 0, 1, w2+w3, w2*w3, sampleRate),
+			bilinearBiquad(0, 0, 1, 1, 2*w4, w4*w4, sampleRate),
+		}
+	case conf.WeightingC:
+		filter.sections = []*biquad{
+			bilinearBiquad(1, 0, 0, 1, 2*w1, w1*w1, sampleRate),
+			bilinearBiquad(0, 0, 1, 1, 2*w4, w4*w4, sampleRate),
+		}
+	default:
+		return nil
+	}
+
+	response := 1.0
+	for _, section := range filter.sections {
+		response *= section.response(CalibrationToneFrequency, sampleRate)
+	}
+	filter.gain = 1 / response
+	return filter
+}
+
+// process filters a sample
+func (f *weightingFilter) process(x float64) float64 {
+	for _, section := range f.sections {
+		x = section.process(x)
+	}
+	return x * f.gain
+}
+
+// energyMeanDB returns the level of the mean energy of levels in dB.
+func energyMeanDB(levels []float64) float64 {
+	if len(levels) == 0 {
+		return 0
+	}
+	var energy float64
+	for _, level := range levels {
+		energy += math.Pow(10, level/10)
+	}
+	return 10 * math.Log10(energy/float64(len(levels)))
+}
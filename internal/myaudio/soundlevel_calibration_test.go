@@ -0,0 +1,199 @@
+package myaudio
+
+import (
+	"context"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// toneChunk returns 100 ms of a sine of freq Hz and amplitude as 16-bit PCM,
+// continuing the phase from sample offset.
+func toneChunk(freq, amplitude float64, offset int) []byte {
+	samples := conf.SampleRate / 10
+	data := make([]byte, samples*2)
+	for i := range samples {
+		v := amplitude * math.Sin(2*math.Pi*freq*float64(offset+i)/float64(conf.SampleRate))
+		binary.LittleEndian.PutUint16(data[i*2:], uint16(int16(math.Round(v*32767)))) //nolint:gosec // G115: amplitude below full scale
+	}
+	return data
+}
+
+// setSoundLevelTestSettings sets the interval and weighting for a test.
+func setSoundLevelTestSettings(t *testing.T, interval int, weighting string) {
+	t.Helper()
+	settings := conf.Setting()
+	if settings == nil {
+		t.Skip("Settings not available for test")
+	}
+	originalInterval := settings.Realtime.Audio.SoundLevel.Interval
+	originalWeighting := settings.Realtime.Audio.SoundLevel.Weighting
+	settings.Realtime.Audio.SoundLevel.Interval = interval
+	settings.Realtime.Audio.SoundLevel.Weighting = weighting
+	t.Cleanup(func() {
+		settings.Realtime.Audio.SoundLevel.Interval = originalInterval
+		settings.Realtime.Audio.SoundLevel.Weighting = originalWeighting
+	})
+}
+
+func TestWeightingFilter(t *testing.T) {
+	t.Parallel()
+
+	// Reference values of IEC 61672-1 table 3; the bilinear transform
+	// deviates slightly towards the Nyquist frequency
+	tests := []struct {
+		weighting string
+		freq      float64
+		want      float64
+		tolerance float64
+	}{
+		{conf.WeightingA, 31.62, -39.4, 0.2},
+		{conf.WeightingA, 100, -19.1, 0.1},
+		{conf.WeightingA, 1000, 0, 0.01},
+		{conf.WeightingA, 3981, 1.0, 0.3},
+		{conf.WeightingA, 10000, -2.5, 0.7},
+		{conf.WeightingC, 31.62, -3.0, 0.1},
+		{conf.WeightingC, 1000, 0, 0.01},
+		{conf.WeightingC, 10000, -4.4, 0.7},
+	}
+	for _, tt := range tests {
+		filter := newWeightingFilter(tt.weighting, float64(conf.SampleRate))
+		require.NotNil(t, filter)
+		response := filter.gain
+		for _, section := range filter.sections {
+			response *= section.response(tt.freq, float64(conf.SampleRate))
+		}
+		assert.InDelta(t, tt.want, 20*math.Log10(response), tt.tolerance, "%s at %.0f Hz", tt.weighting, tt.freq)
+	}
+	assert.Nil(t, newWeightingFilter(conf.WeightingZ, float64(conf.SampleRate)))
+}
+
+func TestSoundLevelProcessor_CalibratedLevels(t *testing.T) {
+	setSoundLevelTestSettings(t, 5, conf.WeightingA)
+
+	tests := []struct {
+		name       string
+		freq       float64
+		calibrated bool
+		wantLeq    float64
+	}{
+		// A 0.1 amplitude sine is -23 dBFS, the A-weighting is 0 dB at 1 kHz
+		{name: "uncalibrated 1 kHz", freq: 1000, wantLeq: -23.0},
+		{name: "calibrated 1 kHz", freq: 1000, calibrated: true, wantLeq: 97.0},
+		// and -19.1 dB at 100 Hz
+		{name: "calibrated 100 Hz", freq: 100, calibrated: true, wantLeq: 77.9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			processor, err := newSoundLevelProcessor("test-source", "test-name")
+			require.NoError(t, err)
+			processor.calibration = func(string) (float64, bool) {
+				if tt.calibrated {
+					return 120, true
+				}
+				return 0, false
+			}
+
+			var data *SoundLevelData
+			for i := 0; data == nil && i < 100; i++ {
+				data, err = processor.ProcessAudioData(toneChunk(tt.freq, 0.1, i*conf.SampleRate/10))
+				if err != nil {
+					require.ErrorIs(t, err, ErrIntervalIncomplete)
+				}
+			}
+			require.NotNil(t, data)
+
+			assert.Equal(t, tt.calibrated, data.Calibrated)
+			assert.Equal(t, conf.WeightingA, data.Weighting)
+			assert.InDelta(t, tt.wantLeq, data.Leq, 0.5)
+			assert.LessOrEqual(t, data.LMin, data.Leq)
+			assert.GreaterOrEqual(t, data.LMax, data.Leq)
+
+			// Octave bands are unweighted
+			band := data.OctaveBands[formatBandKey(tt.freq)]
+			offset := 0.0
+			if tt.calibrated {
+				offset = 120
+			}
+			assert.InDelta(t, -23.0+offset, band.Mean, 0.5)
+		})
+	}
+}
+
+func TestMeasureCalibrationTone(t *testing.T) {
+	setSoundLevelTestSettings(t, 5, conf.WeightingZ)
+
+	tests := []struct {
+		name      string
+		freq      float64
+		amplitude float64
+		wantErr   bool
+	}{
+		{name: "reference tone", freq: 1000, amplitude: 0.1},
+		{name: "wrong frequency", freq: 250, amplitude: 0.1, wantErr: true},
+		{name: "too quiet", freq: 1000, amplitude: 0.0001, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const sourceID = "test_calibration_source"
+			processor, err := newSoundLevelProcessor(sourceID, "Calibration")
+			require.NoError(t, err)
+			soundLevelProcessorMutex.Lock()
+			soundLevelProcessors[sourceID] = processor
+			soundLevelProcessorMutex.Unlock()
+			t.Cleanup(func() { UnregisterSoundLevelProcessor(sourceID) })
+
+			type result struct {
+				measurement *CalibrationMeasurement
+				err         error
+			}
+			results := make(chan result, 1)
+			ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+			defer cancel()
+			go func() {
+				measurement, err := MeasureCalibrationTone(ctx, sourceID, 2*time.Second)
+				results <- result{measurement, err}
+			}()
+
+			// Feed audio until the measurement completes
+			var got result
+			for i := 0; ; i++ {
+				select {
+				case got = <-results:
+				default:
+					_, _ = processor.ProcessAudioData(toneChunk(tt.freq, tt.amplitude, i*conf.SampleRate/10))
+					if i%10 == 0 {
+						time.Sleep(time.Millisecond)
+					}
+					continue
+				}
+				break
+			}
+
+			require.NotNil(t, got.measurement)
+			assert.Equal(t, 2, got.measurement.Seconds)
+			if tt.wantErr {
+				require.ErrorIs(t, got.err, ErrCalibrationToneNotFound)
+				return
+			}
+			require.NoError(t, got.err)
+			assert.InDelta(t, -23.0, got.measurement.ToneLevel, 0.5)
+			assert.InDelta(t, -23.0, got.measurement.BroadbandLevel, 0.5)
+			assert.Less(t, got.measurement.Spread, calibrationMaxToneSpread)
+		})
+	}
+}
+
+func TestMeasureCalibrationTone_NotRegistered(t *testing.T) {
+	t.Parallel()
+	_, err := MeasureCalibrationTone(t.Context(), "no_such_source", time.Second)
+	assert.True(t, errors.Is(err, ErrSoundLevelProcessorNotRegistered))
+}
@@ -39,6 +39,7 @@ type SourceStats struct {
 	HLS    int `json:"hls_sources"`
 	RTMP   int `json:"rtmp_sources"`
 	UDP    int `json:"udp_sources"`
+	SRT    int `json:"srt_sources"`
 	Device int `json:"device_sources"`
 	File   int `json:"file_sources"`
 }
@@ -167,6 +168,46 @@ func (r *AudioSourceRegistry) GetSourceByConnection(connectionString string) (*A
 	return nil, false
 }
 
+// UpdateDisplayName changes the display name of a registered source.
+// Returns false if the source does not exist.
+func (r *AudioSourceRegistry) UpdateDisplayName(id, displayName string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, exists := r.sources[id]
+	if !exists {
+		return false
+	}
+	source.DisplayName = displayName
+	return true
+}
+
+// UpdateSourceMetadata replaces the stream metadata of a registered source.
+// Returns false if the source does not exist.
+func (r *AudioSourceRegistry) UpdateSourceMetadata(id string, metadata *StreamMetadata) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	source, exists := r.sources[id]
+	if !exists {
+		return false
+	}
+	source.Metadata = metadata
+	return true
+}
+
+// GetSourceMetadata returns the stream metadata of a source, nil if the
+// source is unknown or has no metadata. The returned value must not be modified.
+func (r *AudioSourceRegistry) GetSourceMetadata(id string) *StreamMetadata {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if source, exists := r.sources[id]; exists {
+		return source.Metadata
+	}
+	return nil
+}
+
 // GetOrCreateSource ensures a source exists and returns it
 func (r *AudioSourceRegistry) GetOrCreateSource(connectionString string, sourceType SourceType) *AudioSource {
 	// Auto-detect type if unknown or if detection yields a different type
@@ -224,6 +265,11 @@ func detectSourceTypeFromString(connectionString string) SourceType {
 		return SourceTypeUDP
 	}
 
+	// SRT streams
+	if strings.HasPrefix(connectionString, "srt://") {
+		return SourceTypeSRT
+	}
+
 	// Audio device patterns
 	if strings.HasPrefix(connectionString, "hw:") ||
 		strings.HasPrefix(connectionString, "plughw:") ||
@@ -513,9 +559,10 @@ func (r *AudioSourceRegistry) validateConnectionString(connectionString string,
 	}
 
 	// Check for shell injection attempts - customize patterns based on source type
-	// Stream types (RTSP, HTTP, HLS, RTMP, UDP) allow query parameters with ampersands
+	// Stream types (RTSP, HTTP, HLS, RTMP, UDP, SRT) allow query parameters with ampersands
 	isStreamType := sourceType == SourceTypeRTSP || sourceType == SourceTypeHTTP ||
-		sourceType == SourceTypeHLS || sourceType == SourceTypeRTMP || sourceType == SourceTypeUDP
+		sourceType == SourceTypeHLS || sourceType == SourceTypeRTMP || sourceType == SourceTypeUDP ||
+		sourceType == SourceTypeSRT
 	if isStreamType {
 		// For stream URLs, allow query parameters with ampersands (e.g., ?channel=1&subtype=0)
 		// but still block dangerous shell injection patterns
@@ -560,6 +607,8 @@ func (r *AudioSourceRegistry) validateConnectionString(connectionString string,
 		return r.validateRTMPURL(connectionString)
 	case SourceTypeUDP:
 		return r.validateUDPURL(connectionString)
+	case SourceTypeSRT:
+		return r.validateSRTURL(connectionString)
 	case SourceTypeFile:
 		return r.validateFilePath(connectionString)
 	case SourceTypeAudioCard:
@@ -572,6 +621,12 @@ func (r *AudioSourceRegistry) validateConnectionString(connectionString string,
 	}
 }
 
+// ValidateRTSPURL validates an RTSP URL with the same rules as RegisterSource,
+// for checking stream URLs before they are added, such as discovered cameras.
+func (r *AudioSourceRegistry) ValidateRTSPURL(rtspURL string) error {
+	return r.validateRTSPURL(rtspURL)
+}
+
 // validateRTSPURL validates RTSP URLs for security
 func (r *AudioSourceRegistry) validateRTSPURL(rtspURL string) error {
 	// Basic validation - check for RTSP scheme without using url.Parse()
@@ -678,6 +733,11 @@ func (r *AudioSourceRegistry) validateUDPURL(udpURL string) error {
 	return r.validateStreamURL(udpURL, "UDP", "validate_udp_url", []string{"udp://", "rtp://"})
 }
 
+// validateSRTURL validates SRT URLs for audio streams
+func (r *AudioSourceRegistry) validateSRTURL(srtURL string) error {
+	return r.validateStreamURL(srtURL, "SRT", "validate_srt_url", []string{"srt://"})
+}
+
 // validateFilePath validates file paths for security
 func (r *AudioSourceRegistry) validateFilePath(filePath string) error {
 	// Clean the path to prevent directory traversal
@@ -781,6 +841,8 @@ func (r *AudioSourceRegistry) GetSourceStats() SourceStats {
 			stats.RTMP++
 		case SourceTypeUDP:
 			stats.UDP++
+		case SourceTypeSRT:
+			stats.SRT++
 		case SourceTypeAudioCard:
 			stats.Device++
 		case SourceTypeFile:
@@ -798,7 +860,7 @@ func (r *AudioSourceRegistry) GetSourceStats() SourceStats {
 
 func (r *AudioSourceRegistry) sanitizeConnectionString(conn string, sourceType SourceType) string {
 	switch sourceType {
-	case SourceTypeRTSP, SourceTypeHTTP, SourceTypeHLS, SourceTypeRTMP, SourceTypeUDP:
+	case SourceTypeRTSP, SourceTypeHTTP, SourceTypeHLS, SourceTypeRTMP, SourceTypeUDP, SourceTypeSRT:
 		// All stream types may contain credentials and should be sanitized
 		return privacy.SanitizeStreamUrl(conn)
 	case SourceTypeAudioCard, SourceTypeFile:
@@ -1020,6 +1082,8 @@ func StreamTypeToSourceType(streamType string) SourceType {
 		return SourceTypeRTMP
 	case "udp":
 		return SourceTypeUDP
+	case "srt":
+		return SourceTypeSRT
 	default:
 		return SourceTypeUnknown
 	}
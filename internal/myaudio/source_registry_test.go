@@ -367,6 +367,22 @@ func TestMultiProtocolValidation(t *testing.T) {
 			description: "UDP URL with FFmpeg options",
 		},
 
+		// SRT protocol tests
+		{
+			name:        "SRT caller",
+			url:         "srt://remote.example.com:9000?mode=caller&latency=800000",
+			sourceType:  SourceTypeSRT,
+			shouldPass:  true,
+			description: "SRT URL with FFmpeg options",
+		},
+		{
+			name:        "Wrong scheme for SRT type",
+			url:         "udp://239.0.0.1:1234",
+			sourceType:  SourceTypeSRT,
+			shouldPass:  false,
+			description: "UDP URL should be rejected when SRT type is specified",
+		},
+
 		// Invalid cases
 		{
 			name:        "Empty URL",
@@ -421,16 +437,18 @@ func TestSourceStatsMultiProtocol(t *testing.T) {
 	registry.GetOrCreateSource("https://cdn.example.com/playlist.m3u8", SourceTypeHLS)
 	registry.GetOrCreateSource("rtmp://live.example.com/app/stream", SourceTypeRTMP)
 	registry.GetOrCreateSource("udp://239.0.0.1:1234", SourceTypeUDP)
+	registry.GetOrCreateSource("srt://remote.example.com:9000", SourceTypeUnknown)
 	registry.GetOrCreateSource("hw:1,0", SourceTypeAudioCard)
 
 	stats := registry.GetSourceStats()
 
-	assert.Equal(t, 7, stats.Total, "Expected 7 total sources")
+	assert.Equal(t, 8, stats.Total, "Expected 8 total sources")
 	assert.Equal(t, 2, stats.RTSP, "Expected 2 RTSP sources")
 	assert.Equal(t, 1, stats.HTTP, "Expected 1 HTTP source")
 	assert.Equal(t, 1, stats.HLS, "Expected 1 HLS source")
 	assert.Equal(t, 1, stats.RTMP, "Expected 1 RTMP source")
 	assert.Equal(t, 1, stats.UDP, "Expected 1 UDP source")
+	assert.Equal(t, 1, stats.SRT, "Expected 1 SRT source (detected from URL)")
 	assert.Equal(t, 1, stats.Device, "Expected 1 device source")
 }
 
@@ -546,6 +564,7 @@ func TestSourceIDPrefixesByType(t *testing.T) {
 		{"https://cdn.example.com/playlist.m3u8", SourceTypeHLS, "hls_"},
 		{"rtmp://live.example.com/app/stream", SourceTypeRTMP, "rtmp_"},
 		{"udp://239.0.0.1:1234", SourceTypeUDP, "udp_"},
+		{"srt://remote.example.com:9000", SourceTypeSRT, "srt_"},
 		{"hw:1,0", SourceTypeAudioCard, "audio_card_"},
 	}
 
@@ -16,6 +16,7 @@ const (
 	SourceTypeHLS       SourceType = "hls"        // HLS (m3u8) streams
 	SourceTypeRTMP      SourceType = "rtmp"       // RTMP/RTMPS streams
 	SourceTypeUDP       SourceType = "udp"        // UDP/RTP streams
+	SourceTypeSRT       SourceType = "srt"        // SRT streams
 	SourceTypeAudioCard SourceType = "audio_card" // Local audio devices
 	SourceTypeFile      SourceType = "file"       // Audio files
 	SourceTypeUnknown   SourceType = "unknown"    // Used when type needs to be detected
@@ -42,6 +43,9 @@ type AudioSource struct {
 	// Simple metrics
 	TotalBytes int64 `json:"totalBytes"`
 	ErrorCount int   `json:"errorCount"`
+
+	// Station metadata sent by Icecast/Shoutcast servers, nil for other sources
+	Metadata *StreamMetadata `json:"metadata,omitempty"`
 }
 
 // GetConnectionString returns the raw connection string for external use (e.g., FFmpeg input)
@@ -0,0 +1,323 @@
+// stream_timeline.go: audio timeline of streams for clock drift and gap detection
+package myaudio
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+const (
+	// timelineGapThreshold is how far a stream must fall behind its steady
+	// latency before it is considered stalled
+	timelineGapThreshold = time.Second
+	// timelineSettleTime is how long a stalled stream has to catch up with
+	// buffered audio before the missing audio is recorded as a gap
+	timelineSettleTime = 3 * time.Second
+	// timelineAheadTolerance is how far the audio timeline may run ahead of
+	// the wall clock before it is pulled back
+	timelineAheadTolerance = 250 * time.Millisecond
+	// timelineLatencyAlpha is the smoothing factor of the steady latency
+	timelineLatencyAlpha = 0.01
+	// timelineDriftWarmup is how long a stream runs before drift is measured,
+	// and the shortest span drift is measured over
+	timelineDriftWarmup = time.Minute
+	// timelineMaxAnchors bounds the corrections kept for timestamp lookups
+	timelineMaxAnchors = 64
+	// streamGapQueueSize bounds gap events waiting to be stored
+	streamGapQueueSize = 100
+)
+
+// Reasons of stream gaps
+const (
+	// StreamGapStall is audio the stream never delivered after stalling
+	StreamGapStall = "stall"
+	// StreamGapRestart is audio lost while the stream reconnected
+	StreamGapRestart = "restart"
+)
+
+// StreamGap is a span of audio a stream did not deliver.
+type StreamGap struct {
+	SourceID string
+	Start    time.Time // audio time where the gap starts
+	Duration time.Duration
+	Reason   string // StreamGapStall or StreamGapRestart
+}
+
+// StreamTimelineStats summarizes how the audio timeline of a stream compares
+// to the wall clock.
+type StreamTimelineStats struct {
+	Latency    time.Duration // steady delay of the audio behind the wall clock
+	DriftPPM   float64       // clock drift, positive when the stream delivers fewer samples than the wall clock expects
+	TotalGap   time.Duration // audio lost in gaps
+	GapCount   int           // number of gaps
+	BurstCount int           // stalls the stream recovered from by delivering buffered audio
+}
+
+// timelineAnchor is a correction of the audio timeline from a byte offset on.
+type timelineAnchor struct {
+	offset int64
+	shift  time.Duration // total correction of audio times from offset on
+}
+
+// timelineStall is a suspected gap waiting to settle.
+type timelineStall struct {
+	since  time.Time // wall clock time the stall was noticed
+	offset int64     // byte offset where audio stopped
+}
+
+// streamTimeline maps the bytes of a stream to the wall clock time they were
+// captured. The audio time of a byte is the wall clock time of the first byte
+// plus the duration of the audio before it, corrected for gaps. Gaps are
+// told apart from bursts by waiting whether a stalled stream catches up.
+type streamTimeline struct {
+	mu             sync.Mutex
+	bytesPerSecond float64
+	origin         time.Time // wall clock time of byte offset 0
+	received       int64
+	anchors        []timelineAnchor
+	latency        time.Duration // smoothed lag of the audio behind the wall clock
+	stall          *timelineStall
+	restarted      bool // the next chunk starts a new connection
+	totalGap       time.Duration
+	totalAhead     time.Duration // corrections of audio running ahead of the wall clock, negative
+	gapCount       int
+	burstCount     int
+	driftRefTime   time.Time
+	driftRefLag    time.Duration
+	driftPPM       float64
+}
+
+func newStreamTimeline(bytesPerSecond float64) *streamTimeline {
+	return &streamTimeline{bytesPerSecond: bytesPerSecond}
+}
+
+// duration returns the duration of n bytes of audio.
+func (t *streamTimeline) duration(n int64) time.Duration {
+	return time.Duration(float64(n) / t.bytesPerSecond * float64(time.Second))
+}
+
+// shiftAt returns the correction applied at a byte offset.
+func (t *streamTimeline) shiftAt(offset int64) time.Duration {
+	for i := len(t.anchors) - 1; i >= 0; i-- {
+		if t.anchors[i].offset <= offset {
+			return t.anchors[i].shift
+		}
+	}
+	if len(t.anchors) > 0 {
+		// Older corrections were dropped, the oldest kept is the best estimate
+		return t.anchors[0].shift
+	}
+	return 0
+}
+
+// audioTime returns the audio time of a byte offset.
+func (t *streamTimeline) audioTime(offset int64) time.Time {
+	return t.origin.Add(t.duration(offset) + t.shiftAt(offset))
+}
+
+// addShift corrects the audio times from offset on by delta.
+func (t *streamTimeline) addShift(offset int64, delta time.Duration) {
+	shift := t.shiftAt(offset) + delta
+	if n := len(t.anchors); n > 0 && t.anchors[n-1].offset == offset {
+		t.anchors[n-1].shift = shift
+		return
+	}
+	if len(t.anchors) >= timelineMaxAnchors {
+		t.anchors = t.anchors[1:]
+	}
+	t.anchors = append(t.anchors, timelineAnchor{offset: offset, shift: shift})
+}
+
+// addGap records missing audio at offset.
+func (t *streamTimeline) addGap(offset int64, missing time.Duration, reason string) *StreamGap {
+	gap := &StreamGap{Start: t.audioTime(offset), Duration: missing, Reason: reason}
+	t.addShift(offset, missing)
+	t.totalGap += missing
+	t.gapCount++
+	return gap
+}
+
+// observe adds a chunk of n bytes received at now. Returns the gap the chunk
+// revealed, if any.
+func (t *streamTimeline) observe(n int, now time.Time) *StreamGap {
+	if n <= 0 || t.bytesPerSecond <= 0 {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	chunk := t.duration(int64(n))
+	if t.origin.IsZero() {
+		t.origin = now.Add(-chunk)
+		t.received = int64(n)
+		t.restarted = false
+		return nil
+	}
+
+	var gap *StreamGap
+	if t.restarted {
+		// A new connection delivers live audio, so everything between the
+		// previous audio and this chunk was lost
+		t.restarted = false
+		t.stall = nil
+		if missing := now.Add(-chunk - t.latency).Sub(t.audioTime(t.received)); missing > 0 {
+			gap = t.addGap(t.received, missing, StreamGapRestart)
+		}
+	}
+
+	start := t.received
+	t.received += int64(n)
+	lag := now.Sub(t.audioTime(t.received))
+	if lag < -timelineAheadTolerance {
+		// Audio cannot be captured after it arrives: the stream clock runs
+		// fast or buffered audio arrived right after connecting
+		t.addShift(start, lag)
+		t.totalAhead += lag
+		lag = 0
+	}
+
+	excess := lag - t.latency
+	switch {
+	case t.stall == nil && excess > timelineGapThreshold:
+		t.stall = &timelineStall{since: now, offset: start}
+	case t.stall != nil && excess <= timelineGapThreshold:
+		// The stream delivered the audio it held back, the timeline is intact
+		t.stall = nil
+		t.burstCount++
+	case t.stall != nil && now.Sub(t.stall.since) >= timelineSettleTime:
+		gap = t.addGap(t.stall.offset, excess, StreamGapStall)
+		t.stall = nil
+	case t.stall == nil:
+		t.latency += time.Duration(float64(lag-t.latency) * timelineLatencyAlpha)
+	}
+
+	t.updateDrift(now)
+	return gap
+}
+
+// updateDrift measures the drift of the stream clock from the change of the
+// latency, ignoring gaps but not the corrections of audio running ahead.
+func (t *streamTimeline) updateDrift(now time.Time) {
+	if t.stall != nil || now.Sub(t.origin) < timelineDriftWarmup {
+		return
+	}
+	lag := t.latency + t.totalAhead
+	if t.driftRefTime.IsZero() {
+		t.driftRefTime = now
+		t.driftRefLag = lag
+		return
+	}
+	if span := now.Sub(t.driftRefTime); span >= timelineDriftWarmup {
+		t.driftPPM = float64(lag-t.driftRefLag) / float64(span) * 1e6
+	}
+}
+
+// markRestart notes that the next chunk comes from a new connection.
+func (t *streamTimeline) markRestart() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.origin.IsZero() {
+		t.restarted = true
+	}
+}
+
+// timeAt returns the audio time of a byte offset, false before any audio.
+func (t *streamTimeline) timeAt(offset int64) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.origin.IsZero() {
+		return time.Time{}, false
+	}
+	return t.audioTime(offset), true
+}
+
+func (t *streamTimeline) stats() StreamTimelineStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return StreamTimelineStats{
+		Latency:    t.latency,
+		DriftPPM:   t.driftPPM,
+		TotalGap:   t.totalGap,
+		GapCount:   t.gapCount,
+		BurstCount: t.burstCount,
+	}
+}
+
+var (
+	// streamTimelines holds the timeline of each stream source. A timeline
+	// counts the same bytes as the analysis buffer of the source, so both are
+	// reset together.
+	streamTimelines   = make(map[string]*streamTimeline)
+	streamTimelinesMu sync.Mutex
+	streamGapEvents   = make(chan StreamGap, streamGapQueueSize)
+)
+
+// getStreamTimeline returns the timeline of a source, creating it if asked.
+func getStreamTimeline(sourceID string, create bool) *streamTimeline {
+	streamTimelinesMu.Lock()
+	defer streamTimelinesMu.Unlock()
+	timeline, exists := streamTimelines[sourceID]
+	if !exists && create {
+		timeline = newStreamTimeline(float64(conf.SampleRate * conf.BitDepth / 8))
+		streamTimelines[sourceID] = timeline
+	}
+	return timeline
+}
+
+// observeStreamTimeline adds a chunk of stream audio to the timeline of the
+// source and publishes a revealed gap on StreamGaps.
+func observeStreamTimeline(sourceID string, n int, now time.Time) *StreamGap {
+	gap := getStreamTimeline(sourceID, true).observe(n, now)
+	if gap == nil {
+		return nil
+	}
+	gap.SourceID = sourceID
+	select {
+	case streamGapEvents <- *gap:
+	default:
+		// Nobody is storing gaps or the store is behind
+	}
+	return gap
+}
+
+// markStreamRestart notes that the next audio of the source comes from a new
+// connection, so the time since the previous audio is a gap.
+func markStreamRestart(sourceID string) {
+	if timeline := getStreamTimeline(sourceID, false); timeline != nil {
+		timeline.markRestart()
+	}
+}
+
+// resetStreamTimeline discards the timeline of a source.
+func resetStreamTimeline(sourceID string) {
+	streamTimelinesMu.Lock()
+	defer streamTimelinesMu.Unlock()
+	delete(streamTimelines, sourceID)
+}
+
+// streamAudioTime returns when the audio at a byte offset of a source was
+// captured. Returns false for sources without a timeline, such as sound cards.
+func streamAudioTime(sourceID string, offset int64) (time.Time, bool) {
+	timeline := getStreamTimeline(sourceID, false)
+	if timeline == nil {
+		return time.Time{}, false
+	}
+	return timeline.timeAt(offset)
+}
+
+// streamTimelineStats returns the timeline statistics of a source.
+func streamTimelineStats(sourceID string) (StreamTimelineStats, bool) {
+	timeline := getStreamTimeline(sourceID, false)
+	if timeline == nil {
+		return StreamTimelineStats{}, false
+	}
+	return timeline.stats(), true
+}
+
+// StreamGaps returns the channel gaps detected in stream audio are published
+// on. Gaps are dropped while the channel is full.
+func StreamGaps() <-chan StreamGap {
+	return streamGapEvents
+}
@@ -0,0 +1,154 @@
+package myaudio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testTimelineRate  = 96000 // 48 kHz 16-bit mono
+	testTimelineChunk = 100 * time.Millisecond
+	testTimelineBytes = testTimelineRate / 10
+)
+
+// feedTimeline delivers count live chunks starting at now and returns the
+// time of the next chunk and the gaps revealed.
+func feedTimeline(t *streamTimeline, now time.Time, count int) (time.Time, []*StreamGap) {
+	var gaps []*StreamGap
+	for range count {
+		if gap := t.observe(testTimelineBytes, now); gap != nil {
+			gaps = append(gaps, gap)
+		}
+		now = now.Add(testTimelineChunk)
+	}
+	return now, gaps
+}
+
+func TestStreamTimeline_Steady(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC)
+	timeline := newStreamTimeline(testTimelineRate)
+
+	now, gaps := feedTimeline(timeline, start, 600)
+	assert.Empty(t, gaps)
+
+	stats := timeline.stats()
+	assert.Zero(t, stats.GapCount)
+	assert.Zero(t, stats.BurstCount)
+	assert.Less(t, stats.Latency.Abs(), 10*time.Millisecond)
+
+	// The end of the audio received so far was captured just now
+	end, ok := timeline.timeAt(timeline.received)
+	require.True(t, ok)
+	assert.WithinDuration(t, now.Add(-testTimelineChunk), end, 10*time.Millisecond)
+}
+
+func TestStreamTimeline_BurstKeepsTimeline(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC)
+	timeline := newStreamTimeline(testTimelineRate)
+
+	now, _ := feedTimeline(timeline, start, 50)
+	stalledAt := timeline.received
+
+	// The stream stalls for two seconds, then delivers the held back audio
+	// at once
+	now = now.Add(2 * time.Second)
+	var gaps []*StreamGap
+	for range 21 {
+		if gap := timeline.observe(testTimelineBytes, now); gap != nil {
+			gaps = append(gaps, gap)
+		}
+	}
+	_, more := feedTimeline(timeline, now.Add(testTimelineChunk), 50)
+	gaps = append(gaps, more...)
+
+	assert.Empty(t, gaps)
+	stats := timeline.stats()
+	assert.Equal(t, 1, stats.BurstCount)
+	assert.Zero(t, stats.TotalGap)
+
+	// Audio after the stall keeps its capture time
+	resumed, ok := timeline.timeAt(stalledAt + testTimelineBytes)
+	require.True(t, ok)
+	assert.WithinDuration(t, start.Add(50*testTimelineChunk), resumed, 10*time.Millisecond)
+}
+
+func TestStreamTimeline_StallBecomesGap(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC)
+	timeline := newStreamTimeline(testTimelineRate)
+
+	now, _ := feedTimeline(timeline, start, 50)
+	stalledAt := timeline.received
+
+	// Five seconds of audio never arrive, the stream resumes with live audio
+	_, gaps := feedTimeline(timeline, now.Add(5*time.Second), 50)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, StreamGapStall, gaps[0].Reason)
+	assert.InDelta(t, 5.0, gaps[0].Duration.Seconds(), 0.05)
+	assert.WithinDuration(t, start.Add(50*testTimelineChunk-testTimelineChunk), gaps[0].Start, 150*time.Millisecond)
+
+	stats := timeline.stats()
+	assert.Equal(t, 1, stats.GapCount)
+	assert.Zero(t, stats.BurstCount)
+	assert.InDelta(t, 5.0, stats.TotalGap.Seconds(), 0.05)
+
+	// Audio after the gap is timestamped when it arrived, not right after
+	// the audio before the gap
+	resumed, ok := timeline.timeAt(stalledAt + testTimelineBytes)
+	require.True(t, ok)
+	assert.WithinDuration(t, now.Add(5*time.Second), resumed, 150*time.Millisecond)
+}
+
+func TestStreamTimeline_RestartGap(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC)
+	timeline := newStreamTimeline(testTimelineRate)
+
+	now, _ := feedTimeline(timeline, start, 50)
+	timeline.markRestart()
+
+	_, gaps := feedTimeline(timeline, now.Add(10*time.Second), 10)
+	require.Len(t, gaps, 1)
+	assert.Equal(t, StreamGapRestart, gaps[0].Reason)
+	assert.InDelta(t, 10.0, gaps[0].Duration.Seconds(), 0.05)
+	assert.Equal(t, 1, timeline.stats().GapCount)
+}
+
+func TestStreamTimeline_DriftPPM(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2024, 5, 1, 5, 0, 0, 0, time.UTC)
+	// The stream clock runs 100 ppm slow: each chunk arrives a little later
+	// than its audio lasts
+	timeline := newStreamTimeline(testTimelineRate)
+	interval := testTimelineChunk + testTimelineChunk/10000
+
+	now := start
+	for range 36000 { // one hour
+		assert.Nil(t, timeline.observe(testTimelineBytes, now))
+		now = now.Add(interval)
+	}
+	assert.InDelta(t, 100, timeline.stats().DriftPPM, 15)
+}
+
+func TestStreamTimelineRegistry(t *testing.T) {
+	const sourceID = "test_timeline_registry"
+	t.Cleanup(func() { resetStreamTimeline(sourceID) })
+
+	_, ok := streamAudioTime(sourceID, 0)
+	assert.False(t, ok, "sources without stream audio have no timeline")
+
+	now := time.Now()
+	observeStreamTimeline(sourceID, testTimelineBytes, now)
+	captured, ok := streamAudioTime(sourceID, testTimelineBytes)
+	require.True(t, ok)
+	assert.WithinDuration(t, now, captured, time.Millisecond)
+
+	resetStreamTimeline(sourceID)
+	_, ok = streamTimelineStats(sourceID)
+	assert.False(t, ok)
+}
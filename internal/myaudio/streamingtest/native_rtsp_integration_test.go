@@ -0,0 +1,75 @@
+//go:build integration
+
+package streamingtest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/testutil/containers"
+)
+
+// publishCodec publishes tawnyowl.wav to path with codecArgs for the duration of the test.
+func publishCodec(t *testing.T, path string, codecArgs ...string) string {
+	t.Helper()
+	rtspURL := mediamtxContainer.GetRTSPURL(path)
+	pub, err := containers.PublishWAVToMediaMTXWithCodec(context.Background(), findTawnyOwlWAV(), rtspURL, codecArgs...)
+	require.NoError(t, err)
+	t.Cleanup(pub.Stop)
+
+	// Allow the publisher to announce the stream
+	time.Sleep(3 * time.Second)
+	return rtspURL
+}
+
+// TestStreamingIntegration_NativeRTSP verifies that G.711, L16 and Opus streams
+// are read by the in-process RTSP client without an FFmpeg process.
+func TestStreamingIntegration_NativeRTSP(t *testing.T) {
+	tests := []struct {
+		name      string
+		codecArgs []string
+	}{
+		{"pcmu", []string{"-c:a", "pcm_mulaw", "-ar", "8000"}},
+		{"pcma", []string{"-c:a", "pcm_alaw", "-ar", "8000"}},
+		{"l16", []string{"-c:a", "pcm_s16be", "-ar", "16000"}},
+		{"opus", []string{"-c:a", "libopus", "-ar", "48000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rtspURL := publishCodec(t, "native-"+tt.name, tt.codecArgs...)
+			setupTestSettings(t, rtspURL, conf.StreamTypeRTSP, "tcp")
+			conf.Setting().Realtime.RTSP.NativeClient = true
+
+			manager, audioChan := startManagerStream(t, rtspURL, "tcp")
+
+			msgCount := waitForAudioData(t, audioChan, 15*time.Second)
+			require.Positive(t, msgCount, "native RTSP stream should receive audio data")
+
+			health := manager.HealthCheck()[rtspURL]
+			assert.True(t, health.NativeClient, "stream should be read without FFmpeg")
+
+			require.NoError(t, manager.StopStream(rtspURL))
+		})
+	}
+}
+
+// TestStreamingIntegration_NativeRTSPFallback verifies that streams with codecs
+// the native client cannot decode fall back to FFmpeg.
+func TestStreamingIntegration_NativeRTSPFallback(t *testing.T) {
+	rtspURL := publishCodec(t, "native-aac", "-c:a", "aac", "-ar", "48000")
+	setupTestSettings(t, rtspURL, conf.StreamTypeRTSP, "tcp")
+	conf.Setting().Realtime.RTSP.NativeClient = true
+
+	manager, audioChan := startManagerStream(t, rtspURL, "tcp")
+
+	msgCount := waitForAudioData(t, audioChan, 15*time.Second)
+	require.Positive(t, msgCount, "AAC stream should receive audio data through FFmpeg")
+
+	health := manager.HealthCheck()[rtspURL]
+	assert.False(t, health.NativeClient, "AAC streams are decoded by FFmpeg")
+}
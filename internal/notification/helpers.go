@@ -276,6 +276,7 @@ func EnrichWithTemplateData(notification *Notification, data *TemplateData) *Not
 		WithMetadata("bg_detection_path", data.DetectionPath).
 		WithMetadata("bg_detection_url", data.DetectionURL).
 		WithMetadata("bg_image_url", data.ImageURL).
+		WithMetadata("bg_spectrogram_url", data.SpectrogramURL).
 		WithMetadata("bg_confidence_percent", data.ConfidencePercent).
 		WithMetadata("bg_detection_time", data.DetectionTime).
 		WithMetadata("bg_detection_date", data.DetectionDate).
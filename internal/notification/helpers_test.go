@@ -278,6 +278,7 @@ func TestEnrichWithTemplateData(t *testing.T) {
 					"bg_detection_path":     tt.templateData.DetectionPath,
 					"bg_detection_url":      tt.templateData.DetectionURL,
 					"bg_image_url":          tt.templateData.ImageURL,
+					"bg_spectrogram_url":    tt.templateData.SpectrogramURL,
 					"bg_confidence_percent": tt.templateData.ConfidencePercent,
 					"bg_detection_time":     tt.templateData.DetectionTime,
 					"bg_detection_date":     tt.templateData.DetectionDate,
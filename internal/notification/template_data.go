@@ -25,6 +25,7 @@ type TemplateData struct {
 	DetectionPath      string
 	DetectionURL       string
 	ImageURL           string
+	SpectrogramURL     string // annotated spectrogram, empty without a detection ID
 	DaysSinceFirstSeen int
 }
 
@@ -89,6 +90,12 @@ func NewTemplateData(event events.DetectionEvent, baseURL string, timeAs24h bool
 		imageURL = fmt.Sprintf("%s/api/v2/media/species-image?scientific_name=%s", baseURL, encodedScientificName)
 	}
 
+	// Annotated spectrogram for push notification attachments
+	var spectrogramURL string
+	if noteID != "" {
+		spectrogramURL = fmt.Sprintf("%s/api/v2/spectrogram/%s?annotated=true", baseURL, noteID)
+	}
+
 	return &TemplateData{
 		CommonName:         event.GetSpeciesName(),
 		ScientificName:     scientificName,
@@ -103,6 +110,7 @@ func NewTemplateData(event events.DetectionEvent, baseURL string, timeAs24h bool
 		DetectionPath:      detectionPath,
 		DetectionURL:       detectionURL,
 		ImageURL:           imageURL,
+		SpectrogramURL:     spectrogramURL,
 		DaysSinceFirstSeen: event.GetDaysSinceFirstSeen(),
 	}
 }
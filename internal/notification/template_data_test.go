@@ -7,6 +7,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/events"
 )
 
 // setEnv is a test helper that sets an environment variable and fails the test if it errors
@@ -307,6 +308,20 @@ func TestNewTemplateData(t *testing.T) {
 	t.Skip("Template data construction is covered by integration tests")
 }
 
+func TestNewTemplateData_SpectrogramURL(t *testing.T) {
+	t.Parallel()
+
+	event, err := events.NewDetectionEvent("Northern Cardinal", "Cardinalis cardinalis", 0.95, "backyard", true, 0) //nolint:misspell // Cardinalis is a scientific name
+	require.NoError(t, err)
+
+	data := NewTemplateData(event, "http://birdnet.local", true)
+	assert.Empty(t, data.SpectrogramURL, "no spectrogram without a detection ID")
+
+	event.GetMetadata()["note_id"] = uint(42)
+	data = NewTemplateData(event, "http://birdnet.local", true)
+	assert.Equal(t, "http://birdnet.local/api/v2/spectrogram/42?annotated=true", data.SpectrogramURL)
+}
+
 // BenchmarkBuildBaseURL measures the performance of URL construction
 func BenchmarkBuildBaseURL(b *testing.B) {
 	scenarios := []struct {
@@ -14,12 +14,18 @@ type SoundLevelMetrics struct {
 	soundLevelUpdatesTotal *prometheus.CounterVec
 	soundLevelDuration     *prometheus.HistogramVec
 
+	// Calibrated sound pressure level metrics
+	soundPressureLevelGauge *prometheus.GaugeVec
+
 	// Octave band specific metrics
 	octaveBandLevelGauge *prometheus.GaugeVec
 	octaveBandMinGauge   *prometheus.GaugeVec
 	octaveBandMaxGauge   *prometheus.GaugeVec
 	octaveBandMeanGauge  *prometheus.GaugeVec
 
+	// Acoustic index metrics
+	acousticIndexGauge *prometheus.GaugeVec
+
 	// Processing metrics
 	soundLevelProcessingDuration *prometheus.HistogramVec
 	soundLevelProcessingErrors   *prometheus.CounterVec
@@ -67,6 +73,15 @@ func (m *SoundLevelMetrics) initMetrics() error {
 		[]string{"source", "name"},
 	)
 
+	// Calibrated sound pressure level metrics
+	m.soundPressureLevelGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myaudio_sound_pressure_level_db",
+			Help: "Weighted sound pressure level of calibrated sources in dB SPL",
+		},
+		[]string{"source", "name", "weighting", "statistic"}, // statistic: leq, min, max
+	)
+
 	// Octave band specific metrics
 	m.octaveBandLevelGauge = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -100,6 +115,15 @@ func (m *SoundLevelMetrics) initMetrics() error {
 		[]string{"source", "name", "frequency_band"},
 	)
 
+	// Acoustic index metrics
+	m.acousticIndexGauge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "myaudio_acoustic_index",
+			Help: "Acoustic index of the last completed minute",
+		},
+		[]string{"source", "name", "index"}, // index: aci, adi, ndsi, bi, h
+	)
+
 	// Processing metrics
 	m.soundLevelProcessingDuration = prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
@@ -142,10 +166,12 @@ func (m *SoundLevelMetrics) Describe(ch chan<- *prometheus.Desc) {
 	m.soundLevelGauge.Describe(ch)
 	m.soundLevelUpdatesTotal.Describe(ch)
 	m.soundLevelDuration.Describe(ch)
+	m.soundPressureLevelGauge.Describe(ch)
 	m.octaveBandLevelGauge.Describe(ch)
 	m.octaveBandMinGauge.Describe(ch)
 	m.octaveBandMaxGauge.Describe(ch)
 	m.octaveBandMeanGauge.Describe(ch)
+	m.acousticIndexGauge.Describe(ch)
 	m.soundLevelProcessingDuration.Describe(ch)
 	m.soundLevelProcessingErrors.Describe(ch)
 	m.soundLevelPublishingTotal.Describe(ch)
@@ -157,10 +183,12 @@ func (m *SoundLevelMetrics) Collect(ch chan<- prometheus.Metric) {
 	m.soundLevelGauge.Collect(ch)
 	m.soundLevelUpdatesTotal.Collect(ch)
 	m.soundLevelDuration.Collect(ch)
+	m.soundPressureLevelGauge.Collect(ch)
 	m.octaveBandLevelGauge.Collect(ch)
 	m.octaveBandMinGauge.Collect(ch)
 	m.octaveBandMaxGauge.Collect(ch)
 	m.octaveBandMeanGauge.Collect(ch)
+	m.acousticIndexGauge.Collect(ch)
 	m.soundLevelProcessingDuration.Collect(ch)
 	m.soundLevelProcessingErrors.Collect(ch)
 	m.soundLevelPublishingTotal.Collect(ch)
@@ -180,6 +208,13 @@ func (m *SoundLevelMetrics) RecordSoundLevelDuration(source, name string, durati
 	m.soundLevelDuration.WithLabelValues(source, name).Observe(durationSeconds)
 }
 
+// UpdateSoundPressureLevel updates the calibrated sound pressure level of a source
+func (m *SoundLevelMetrics) UpdateSoundPressureLevel(source, name, weighting string, leqDB, minDB, maxDB float64) {
+	m.soundPressureLevelGauge.WithLabelValues(source, name, weighting, "leq").Set(leqDB)
+	m.soundPressureLevelGauge.WithLabelValues(source, name, weighting, "min").Set(minDB)
+	m.soundPressureLevelGauge.WithLabelValues(source, name, weighting, "max").Set(maxDB)
+}
+
 // UpdateOctaveBandLevel updates the sound level for a specific octave band
 func (m *SoundLevelMetrics) UpdateOctaveBandLevel(source, name, frequencyBand string, minDB, maxDB, meanDB float64) {
 	m.octaveBandMinGauge.WithLabelValues(source, name, frequencyBand).Set(minDB)
@@ -188,6 +223,11 @@ func (m *SoundLevelMetrics) UpdateOctaveBandLevel(source, name, frequencyBand st
 	m.octaveBandLevelGauge.WithLabelValues(source, name, frequencyBand, "current").Set(meanDB)
 }
 
+// UpdateAcousticIndex updates the value of an acoustic index
+func (m *SoundLevelMetrics) UpdateAcousticIndex(source, name, index string, value float64) {
+	m.acousticIndexGauge.WithLabelValues(source, name, index).Set(value)
+}
+
 // RecordSoundLevelProcessingDuration records the duration of sound level processing
 func (m *SoundLevelMetrics) RecordSoundLevelProcessingDuration(source, name, operation string, duration float64) {
 	m.soundLevelProcessingDuration.WithLabelValues(source, name, operation).Observe(duration)
@@ -0,0 +1,367 @@
+// client.go: SOAP client for the ONVIF device and media services
+package onvif
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // WS-Security UsernameToken digests are defined with SHA-1
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// Client settings
+const (
+	// DefaultRequestTimeout bounds a single SOAP request to a camera.
+	DefaultRequestTimeout = 5 * time.Second
+	maxResponseSize       = 1 << 20
+	soapContentType       = "application/soap+xml; charset=utf-8"
+)
+
+// soapEnvelopeTemplate wraps a request body with the namespaces used by requests.
+const soapEnvelopeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:trt="http://www.onvif.org/ver10/media/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema">
+<s:Header>%s</s:Header>
+<s:Body>%s</s:Body>
+</s:Envelope>`
+
+// securityHeaderTemplate is a WS-Security UsernameToken with a password digest.
+const securityHeaderTemplate = `<wsse:Security s:mustUnderstand="1" xmlns:wsse="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd" xmlns:wsu="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd">
+<wsse:UsernameToken>
+<wsse:Username>%s</wsse:Username>
+<wsse:Password Type="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest">%s</wsse:Password>
+<wsse:Nonce EncodingType="http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary">%s</wsse:Nonce>
+<wsu:Created>%s</wsu:Created>
+</wsse:UsernameToken>
+</wsse:Security>`
+
+// Client queries the device and media services of an ONVIF camera.
+type Client struct {
+	deviceURL  string
+	username   string
+	password   string
+	httpClient *http.Client
+	clockSkew  time.Duration // camera clock minus local clock, for UsernameToken timestamps
+}
+
+// NewClient creates a client for the camera with the given device service URL.
+// An empty username sends requests without WS-Security.
+func NewClient(deviceURL, username, password string) *Client {
+	return &Client{
+		deviceURL:  deviceURL,
+		username:   username,
+		password:   password,
+		httpClient: &http.Client{Timeout: DefaultRequestTimeout},
+	}
+}
+
+// soapFault is the part of a SOAP 1.2 fault used in errors.
+type soapFault struct {
+	Code struct {
+		Value   string `xml:"Value"`
+		Subcode struct {
+			Value string `xml:"Value"`
+		} `xml:"Subcode"`
+	} `xml:"Code"`
+	Reason struct {
+		Text string `xml:"Text"`
+	} `xml:"Reason"`
+}
+
+// call posts an authenticated SOAP request with body to endpoint and decodes
+// the response body element into out, whose fields match elements by local name.
+func (c *Client) call(ctx context.Context, endpoint, operation, body string, out any) error {
+	return c.post(ctx, endpoint, operation, body, c.username != "", out)
+}
+
+// post posts a SOAP request, with a WS-Security header if authenticate is set,
+// and decodes the response body element into out.
+func (c *Client) post(ctx context.Context, endpoint, operation, body string, authenticate bool, out any) error {
+	header := ""
+	if authenticate {
+		var err error
+		if header, err = c.securityHeader(time.Now().Add(c.clockSkew)); err != nil {
+			return err
+		}
+	}
+	envelope := fmt.Sprintf(soapEnvelopeTemplate, header, body)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(envelope))
+	if err != nil {
+		return errors.New(err).
+			Component("onvif").
+			Category(errors.CategoryValidation).
+			Context("operation", operation).
+			Build()
+	}
+	req.Header.Set("Content-Type", soapContentType)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.New(err).
+			Component("onvif").
+			Category(errors.CategoryNetwork).
+			Context("operation", operation).
+			Build()
+	}
+	defer resp.Body.Close() //nolint:errcheck // response body is fully read
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	if err != nil {
+		return errors.New(err).
+			Component("onvif").
+			Category(errors.CategoryNetwork).
+			Context("operation", operation).
+			Build()
+	}
+
+	var response struct {
+		Body struct {
+			Fault   *soapFault `xml:"Fault"`
+			Content []byte     `xml:",innerxml"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(data, &response); err != nil {
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return ErrUnauthorized
+		}
+		return errors.Newf("invalid response from camera (HTTP %d): %v", resp.StatusCode, err).
+			Component("onvif").
+			Category(errors.CategoryHTTP).
+			Context("operation", operation).
+			Build()
+	}
+	if fault := response.Body.Fault; fault != nil {
+		if strings.Contains(fault.Code.Subcode.Value, "NotAuthorized") || strings.Contains(fault.Code.Subcode.Value, "FailedAuthentication") {
+			return ErrUnauthorized
+		}
+		return errors.Newf("camera returned a fault: %s %s", fault.Code.Subcode.Value, strings.TrimSpace(fault.Reason.Text)).
+			Component("onvif").
+			Category(errors.CategoryHTTP).
+			Context("operation", operation).
+			Build()
+	}
+	if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+		return ErrUnauthorized
+	}
+	if resp.StatusCode != http.StatusOK {
+		return errors.Newf("camera returned HTTP %d", resp.StatusCode).
+			Component("onvif").
+			Category(errors.CategoryHTTP).
+			Context("operation", operation).
+			Build()
+	}
+
+	if err := xml.Unmarshal(wrapBody(response.Body.Content), out); err != nil {
+		return errors.Newf("invalid %s response from camera: %v", operation, err).
+			Component("onvif").
+			Category(errors.CategoryHTTP).
+			Context("operation", operation).
+			Build()
+	}
+	return nil
+}
+
+// wrapBody wraps the inner XML of a SOAP body in a single root element, so
+// that it can be decoded into a struct whose fields are the body children.
+func wrapBody(content []byte) []byte {
+	var buf bytes.Buffer
+	buf.Grow(len(content) + len("<Body></Body>"))
+	buf.WriteString("<Body>")
+	buf.Write(content)
+	buf.WriteString("</Body>")
+	return buf.Bytes()
+}
+
+// securityHeader returns a WS-Security header with a password digest created
+// at the given camera time.
+func (c *Client) securityHeader(created time.Time) (string, error) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", errors.New(err).
+			Component("onvif").
+			Category(errors.CategorySystem).
+			Context("operation", "onvif_security_header").
+			Build()
+	}
+	timestamp := created.UTC().Format("2006-01-02T15:04:05.000Z")
+	return fmt.Sprintf(securityHeaderTemplate,
+		escapeXML(c.username),
+		passwordDigest(nonce, timestamp, c.password),
+		base64.StdEncoding.EncodeToString(nonce),
+		timestamp), nil
+}
+
+// passwordDigest returns Base64(SHA-1(nonce + created + password)).
+func passwordDigest(nonce []byte, created, password string) string {
+	h := sha1.New() //nolint:gosec // required by the UsernameToken profile
+	h.Write(nonce)
+	h.Write([]byte(created))
+	h.Write([]byte(password))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// escapeXML escapes text for use in XML element content.
+func escapeXML(text string) string {
+	var buf bytes.Buffer
+	_ = xml.EscapeText(&buf, []byte(text))
+	return buf.String()
+}
+
+// SyncClock reads the camera clock so that UsernameToken timestamps match it;
+// cameras reject tokens created too far from their own time. The request needs
+// no credentials.
+func (c *Client) SyncClock(ctx context.Context) error {
+	var response struct {
+		GetSystemDateAndTimeResponse struct {
+			SystemDateAndTime struct {
+				UTCDateTime struct {
+					Date struct {
+						Year  int `xml:"Year"`
+						Month int `xml:"Month"`
+						Day   int `xml:"Day"`
+					} `xml:"Date"`
+					Time struct {
+						Hour   int `xml:"Hour"`
+						Minute int `xml:"Minute"`
+						Second int `xml:"Second"`
+					} `xml:"Time"`
+				} `xml:"UTCDateTime"`
+			} `xml:"SystemDateAndTime"`
+		} `xml:"GetSystemDateAndTimeResponse"`
+	}
+
+	if err := c.post(ctx, c.deviceURL, "get_system_date_and_time", "<tds:GetSystemDateAndTime/>", false, &response); err != nil {
+		return err
+	}
+
+	utc := response.GetSystemDateAndTimeResponse.SystemDateAndTime.UTCDateTime
+	if utc.Date.Year == 0 {
+		return nil
+	}
+	cameraTime := time.Date(utc.Date.Year, time.Month(utc.Date.Month), utc.Date.Day,
+		utc.Time.Hour, utc.Time.Minute, utc.Time.Second, 0, time.UTC)
+	c.clockSkew = time.Until(cameraTime)
+	return nil
+}
+
+// MediaURL returns the media service URL of the camera, falling back to the
+// device service URL for cameras that do not report one.
+func (c *Client) MediaURL(ctx context.Context) (string, error) {
+	var response struct {
+		GetCapabilitiesResponse struct {
+			Capabilities struct {
+				Media struct {
+					XAddr string `xml:"XAddr"`
+				} `xml:"Media"`
+			} `xml:"Capabilities"`
+		} `xml:"GetCapabilitiesResponse"`
+	}
+	body := "<tds:GetCapabilities><tds:Category>Media</tds:Category></tds:GetCapabilities>"
+	if err := c.call(ctx, c.deviceURL, "get_capabilities", body, &response); err != nil {
+		return "", err
+	}
+	if xaddr := strings.TrimSpace(response.GetCapabilitiesResponse.Capabilities.Media.XAddr); xaddr != "" {
+		return xaddr, nil
+	}
+	return c.deviceURL, nil
+}
+
+// mediaProfile is the part of an ONVIF media profile used to find audio.
+type mediaProfile struct {
+	Token                     string `xml:"token,attr"`
+	Name                      string `xml:"Name"`
+	VideoEncoderConfiguration *struct {
+		Encoding string `xml:"Encoding"`
+	} `xml:"VideoEncoderConfiguration"`
+	AudioEncoderConfiguration *struct {
+		Encoding   string `xml:"Encoding"`
+		Bitrate    int    `xml:"Bitrate"`
+		SampleRate int    `xml:"SampleRate"`
+	} `xml:"AudioEncoderConfiguration"`
+}
+
+// getProfiles returns the media profiles of the camera.
+func (c *Client) getProfiles(ctx context.Context, mediaURL string) ([]mediaProfile, error) {
+	var response struct {
+		GetProfilesResponse struct {
+			Profiles []mediaProfile `xml:"Profiles"`
+		} `xml:"GetProfilesResponse"`
+	}
+	if err := c.call(ctx, mediaURL, "get_profiles", "<trt:GetProfiles/>", &response); err != nil {
+		return nil, err
+	}
+	return response.GetProfilesResponse.Profiles, nil
+}
+
+// getStreamURI returns the RTSP URI of a media profile.
+func (c *Client) getStreamURI(ctx context.Context, mediaURL, profileToken string) (string, error) {
+	var response struct {
+		GetStreamUriResponse struct {
+			MediaUri struct {
+				Uri string `xml:"Uri"`
+			} `xml:"MediaUri"`
+		} `xml:"GetStreamUriResponse"`
+	}
+	body := "<trt:GetStreamUri><trt:StreamSetup><tt:Stream>RTP-Unicast</tt:Stream>" +
+		"<tt:Transport><tt:Protocol>RTSP</tt:Protocol></tt:Transport></trt:StreamSetup>" +
+		"<trt:ProfileToken>" + escapeXML(profileToken) + "</trt:ProfileToken></trt:GetStreamUri>"
+	if err := c.call(ctx, mediaURL, "get_stream_uri", body, &response); err != nil {
+		return "", err
+	}
+	uri := strings.TrimSpace(response.GetStreamUriResponse.MediaUri.Uri)
+	if uri == "" {
+		return "", errors.Newf("camera returned no stream URI for profile %s", profileToken).
+			Component("onvif").
+			Category(errors.CategoryHTTP).
+			Context("operation", "get_stream_uri").
+			Build()
+	}
+	return uri, nil
+}
+
+// AudioProfiles returns the media profiles of the camera that include audio,
+// with their RTSP stream URIs.
+func (c *Client) AudioProfiles(ctx context.Context) ([]StreamProfile, error) {
+	mediaURL, err := c.MediaURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := c.getProfiles(ctx, mediaURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []StreamProfile
+	for i := range profiles {
+		profile := &profiles[i]
+		audio := profile.AudioEncoderConfiguration
+		if audio == nil || audio.Encoding == "" {
+			continue
+		}
+		uri, err := c.getStreamURI(ctx, mediaURL, profile.Token)
+		if err != nil {
+			return nil, err
+		}
+		stream := StreamProfile{
+			Token:           profile.Token,
+			Name:            profile.Name,
+			AudioEncoding:   audio.Encoding,
+			AudioSampleRate: audio.SampleRate,
+			AudioBitrate:    audio.Bitrate,
+			URI:             uri,
+		}
+		if profile.VideoEncoderConfiguration != nil {
+			stream.VideoEncoding = profile.VideoEncoderConfiguration.Encoding
+		}
+		result = append(result, stream)
+	}
+	return result, nil
+}
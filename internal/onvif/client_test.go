@@ -0,0 +1,142 @@
+package onvif
+
+import (
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEnvelope = `<?xml version="1.0" encoding="UTF-8"?>
+<env:Envelope xmlns:env="http://www.w3.org/2003/05/soap-envelope" xmlns:tds="http://www.onvif.org/ver10/device/wsdl" xmlns:trt="http://www.onvif.org/ver10/media/wsdl" xmlns:tt="http://www.onvif.org/ver10/schema" xmlns:ter="http://www.onvif.org/ver10/error">
+<env:Body>%s</env:Body>
+</env:Envelope>`
+
+// fakeCamera serves the ONVIF operations used by the client and checks the
+// UsernameToken digest of authenticated requests.
+func fakeCamera(t *testing.T, username, password string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		body := string(data)
+		w.Header().Set("Content-Type", soapContentType)
+
+		if strings.Contains(body, "GetSystemDateAndTime") {
+			now := time.Now().UTC()
+			_, _ = fmt.Fprintf(w, testEnvelope, fmt.Sprintf(`<tds:GetSystemDateAndTimeResponse><tds:SystemDateAndTime>
+<tt:UTCDateTime><tt:Time><tt:Hour>%d</tt:Hour><tt:Minute>%d</tt:Minute><tt:Second>%d</tt:Second></tt:Time>
+<tt:Date><tt:Year>%d</tt:Year><tt:Month>%d</tt:Month><tt:Day>%d</tt:Day></tt:Date></tt:UTCDateTime>
+</tds:SystemDateAndTime></tds:GetSystemDateAndTimeResponse>`,
+				now.Hour(), now.Minute(), now.Second(), now.Year(), now.Month(), now.Day()))
+			return
+		}
+
+		if !validToken(body, username, password) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprintf(w, testEnvelope, `<env:Fault><env:Code><env:Value>env:Sender</env:Value>
+<env:Subcode><env:Value>ter:NotAuthorized</env:Value></env:Subcode></env:Code>
+<env:Reason><env:Text xml:lang="en">Sender not Authorized</env:Text></env:Reason></env:Fault>`)
+			return
+		}
+
+		switch {
+		case strings.Contains(body, "GetCapabilities"):
+			_, _ = fmt.Fprintf(w, testEnvelope, `<tds:GetCapabilitiesResponse><tds:Capabilities>
+<tt:Media><tt:XAddr>`+server.URL+`/onvif/media</tt:XAddr></tt:Media>
+</tds:Capabilities></tds:GetCapabilitiesResponse>`)
+		case strings.Contains(body, "GetProfiles"):
+			assert.Equal(t, "/onvif/media", r.URL.Path)
+			_, _ = fmt.Fprintf(w, testEnvelope, `<trt:GetProfilesResponse>
+<trt:Profiles token="Profile_1" fixed="true"><tt:Name>mainStream</tt:Name>
+<tt:VideoEncoderConfiguration token="VideoEncoder_1"><tt:Encoding>H264</tt:Encoding></tt:VideoEncoderConfiguration>
+<tt:AudioEncoderConfiguration token="AudioEncoder_1"><tt:Encoding>AAC</tt:Encoding><tt:Bitrate>64</tt:Bitrate><tt:SampleRate>16</tt:SampleRate></tt:AudioEncoderConfiguration>
+</trt:Profiles>
+<trt:Profiles token="Profile_2" fixed="true"><tt:Name>subStream</tt:Name>
+<tt:VideoEncoderConfiguration token="VideoEncoder_2"><tt:Encoding>H264</tt:Encoding></tt:VideoEncoderConfiguration>
+</trt:Profiles>
+</trt:GetProfilesResponse>`)
+		case strings.Contains(body, "GetStreamUri"):
+			assert.Contains(t, body, "<trt:ProfileToken>Profile_1</trt:ProfileToken>")
+			_, _ = fmt.Fprintf(w, testEnvelope, `<trt:GetStreamUriResponse><trt:MediaUri>
+<tt:Uri>rtsp://192.168.1.64:554/Streaming/Channels/101?transportmode=unicast&amp;profile=Profile_1</tt:Uri>
+<tt:InvalidAfterConnect>false</tt:InvalidAfterConnect>
+</trt:MediaUri></trt:GetStreamUriResponse>`)
+		default:
+			t.Errorf("unexpected request: %s", body)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// validToken reports whether an envelope carries a UsernameToken for username
+// with a digest of password.
+func validToken(envelope, username, password string) bool {
+	var token struct {
+		Body   struct{} `xml:"Body"`
+		Header struct {
+			Security struct {
+				UsernameToken struct {
+					Username string `xml:"Username"`
+					Password string `xml:"Password"`
+					Nonce    string `xml:"Nonce"`
+					Created  string `xml:"Created"`
+				} `xml:"UsernameToken"`
+			} `xml:"Security"`
+		} `xml:"Header"`
+	}
+	if err := xml.Unmarshal([]byte(envelope), &token); err != nil {
+		return false
+	}
+	ut := token.Header.Security.UsernameToken
+	nonce, err := base64.StdEncoding.DecodeString(ut.Nonce)
+	if err != nil {
+		return false
+	}
+	return ut.Username == username && ut.Password == passwordDigest(nonce, ut.Created, password)
+}
+
+func TestClientAudioProfiles(t *testing.T) {
+	t.Parallel()
+	server := fakeCamera(t, "admin", "secret")
+
+	client := NewClient(server.URL+"/onvif/device_service", "admin", "secret")
+	require.NoError(t, client.SyncClock(t.Context()))
+	assert.Less(t, client.clockSkew.Abs(), 2*time.Second)
+
+	profiles, err := client.AudioProfiles(t.Context())
+	require.NoError(t, err)
+	require.Len(t, profiles, 1, "profiles without audio are skipped")
+	assert.Equal(t, StreamProfile{
+		Token:           "Profile_1",
+		Name:            "mainStream",
+		AudioEncoding:   "AAC",
+		AudioSampleRate: 16,
+		AudioBitrate:    64,
+		VideoEncoding:   "H264",
+		URI:             "rtsp://192.168.1.64:554/Streaming/Channels/101?transportmode=unicast&profile=Profile_1",
+	}, profiles[0])
+}
+
+func TestClientUnauthorized(t *testing.T) {
+	t.Parallel()
+	server := fakeCamera(t, "admin", "secret")
+
+	client := NewClient(server.URL+"/onvif/device_service", "admin", "wrong")
+	_, err := client.AudioProfiles(t.Context())
+	require.ErrorIs(t, err, ErrUnauthorized)
+
+	client = NewClient(server.URL+"/onvif/device_service", "", "")
+	_, err = client.AudioProfiles(t.Context())
+	require.ErrorIs(t, err, ErrUnauthorized)
+}
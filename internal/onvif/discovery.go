@@ -0,0 +1,188 @@
+// discovery.go: WS-Discovery of ONVIF cameras
+package onvif
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// WS-Discovery settings
+const (
+	discoveryAddress = "239.255.255.250:3702"
+	// DefaultDiscoveryTimeout is how long Discover waits for cameras to answer.
+	DefaultDiscoveryTimeout = 3 * time.Second
+	// MaxDiscoveryTimeout bounds the discovery timeout accepted from callers.
+	MaxDiscoveryTimeout = 15 * time.Second
+	// probeRepeats is how often the probe is sent, as UDP may drop it.
+	probeRepeats       = 2
+	maxDiscoveryPacket = 64 * 1024
+)
+
+// probeTemplate is a WS-Discovery probe for ONVIF network video transmitters.
+const probeTemplate = `<?xml version="1.0" encoding="UTF-8"?>
+<e:Envelope xmlns:e="http://www.w3.org/2003/05/soap-envelope" xmlns:w="http://schemas.xmlsoap.org/ws/2004/08/addressing" xmlns:d="http://schemas.xmlsoap.org/ws/2005/04/discovery" xmlns:dn="http://www.onvif.org/ver10/network/wsdl">
+<e:Header>
+<w:MessageID>uuid:%s</w:MessageID>
+<w:To e:mustUnderstand="true">urn:schemas-xmlsoap-org:ws:2005:04:discovery</w:To>
+<w:Action e:mustUnderstand="true">http://schemas.xmlsoap.org/ws/2005/04/discovery/Probe</w:Action>
+</e:Header>
+<e:Body><d:Probe><d:Types>dn:NetworkVideoTransmitter</d:Types></d:Probe></e:Body>
+</e:Envelope>`
+
+// probeMatchesEnvelope is the part of a WS-Discovery ProbeMatches message
+// used to identify cameras. Elements match by local name.
+type probeMatchesEnvelope struct {
+	Body struct {
+		ProbeMatches struct {
+			ProbeMatch []struct {
+				EndpointReference struct {
+					Address string `xml:"Address"`
+				} `xml:"EndpointReference"`
+				Scopes string `xml:"Scopes"`
+				XAddrs string `xml:"XAddrs"`
+			} `xml:"ProbeMatch"`
+		} `xml:"ProbeMatches"`
+	} `xml:"Body"`
+}
+
+// Discover sends a WS-Discovery probe to the local network and returns the
+// ONVIF cameras that answer within timeout, in the order they answered.
+func Discover(ctx context.Context, timeout time.Duration) ([]Device, error) {
+	if timeout <= 0 {
+		timeout = DefaultDiscoveryTimeout
+	}
+	timeout = min(timeout, MaxDiscoveryTimeout)
+
+	groupAddr, err := net.ResolveUDPAddr("udp4", discoveryAddress)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("onvif").
+			Category(errors.CategoryNetwork).
+			Context("operation", "onvif_discover").
+			Build()
+	}
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{})
+	if err != nil {
+		return nil, errors.New(err).
+			Component("onvif").
+			Category(errors.CategoryNetwork).
+			Context("operation", "onvif_discover").
+			Build()
+	}
+	defer conn.Close() //nolint:errcheck // closing a UDP socket after reading
+
+	// Unblock the read loop when the caller gives up
+	stop := context.AfterFunc(ctx, func() {
+		_ = conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	probe := fmt.Sprintf(probeTemplate, uuid.NewString())
+	for range probeRepeats {
+		if _, err := conn.WriteToUDP([]byte(probe), groupAddr); err != nil {
+			return nil, errors.New(err).
+				Component("onvif").
+				Category(errors.CategoryNetwork).
+				Context("operation", "onvif_discover").
+				Build()
+		}
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, errors.New(err).
+			Component("onvif").
+			Category(errors.CategoryNetwork).
+			Context("operation", "onvif_discover").
+			Build()
+	}
+
+	var devices []Device
+	seen := make(map[string]bool)
+	buf := make([]byte, maxDiscoveryPacket)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			// The read deadline ends discovery
+			break
+		}
+		matches, err := parseProbeMatches(buf[:n])
+		if err != nil {
+			GetLogger().Debug("ignoring invalid discovery response",
+				logger.String("from", from.String()),
+				logger.Error(err),
+				logger.String("operation", "onvif_discover"))
+			continue
+		}
+		for i := range matches {
+			key := matches[i].EndpointID
+			if key == "" {
+				key = matches[i].Address
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			devices = append(devices, matches[i])
+		}
+	}
+
+	if ctx.Err() != nil {
+		return devices, ctx.Err()
+	}
+	return devices, nil
+}
+
+// parseProbeMatches returns the cameras described by a ProbeMatches message.
+// Matches without a device service address are skipped.
+func parseProbeMatches(data []byte) ([]Device, error) {
+	var envelope probeMatchesEnvelope
+	if err := xml.Unmarshal(data, &envelope); err != nil {
+		return nil, err
+	}
+
+	var devices []Device
+	for _, match := range envelope.Body.ProbeMatches.ProbeMatch {
+		xaddrs := strings.Fields(match.XAddrs)
+		if len(xaddrs) == 0 {
+			continue
+		}
+		device := Device{
+			EndpointID: strings.TrimSpace(match.EndpointReference.Address),
+			XAddrs:     xaddrs,
+		}
+		if u, err := url.Parse(xaddrs[0]); err == nil {
+			device.Address = u.Hostname()
+		}
+		for _, scope := range strings.Fields(match.Scopes) {
+			if value, ok := scopeValue(scope, "name"); ok {
+				device.Name = value
+			} else if value, ok := scopeValue(scope, "hardware"); ok {
+				device.Hardware = value
+			}
+		}
+		devices = append(devices, device)
+	}
+	return devices, nil
+}
+
+// scopeValue returns the decoded value of an ONVIF scope such as
+// onvif://www.onvif.org/name/Garden%20Cam for the scope kind "name".
+func scopeValue(scope, kind string) (string, bool) {
+	prefix := "onvif://www.onvif.org/" + kind + "/"
+	if !strings.HasPrefix(strings.ToLower(scope), prefix) {
+		return "", false
+	}
+	value, err := url.PathUnescape(scope[len(prefix):])
+	if err != nil {
+		value = scope[len(prefix):]
+	}
+	return value, true
+}
@@ -0,0 +1,31 @@
+package onvif
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseProbeMatches(t *testing.T) {
+	t.Parallel()
+
+	data, err := os.ReadFile(filepath.Join("testdata", "probe_matches.xml"))
+	require.NoError(t, err)
+
+	devices, err := parseProbeMatches(data)
+	require.NoError(t, err)
+	require.Len(t, devices, 1, "matches without an address are skipped")
+
+	device := devices[0]
+	assert.Equal(t, "urn:uuid:4e1a2b9c-0000-1000-8000-001122334455", device.EndpointID)
+	assert.Equal(t, "Garden Cam", device.Name)
+	assert.Equal(t, "DS-2CD2043", device.Hardware)
+	assert.Equal(t, "192.168.1.64", device.Address)
+	assert.Equal(t, []string{"http://192.168.1.64/onvif/device_service", "http://[fe80::1]/onvif/device_service"}, device.XAddrs)
+
+	_, err = parseProbeMatches([]byte("not xml"))
+	require.Error(t, err)
+}
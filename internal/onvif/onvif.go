@@ -0,0 +1,80 @@
+// Package onvif discovers ONVIF IP cameras on the local network and queries
+// their media profiles for RTSP stream URIs, so that cameras with audio can be
+// added as audio sources without knowing the vendor specific stream URL.
+package onvif
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+	"github.com/tphakala/birdnet-go/internal/logger"
+)
+
+// GetLogger returns the package logger for the onvif module
+func GetLogger() logger.Logger {
+	return logger.Global().Module("onvif")
+}
+
+// ErrUnauthorized is returned when a camera rejects the credentials.
+var ErrUnauthorized = errors.NewStd("camera rejected the credentials")
+
+// Device is a camera found by WS-Discovery.
+type Device struct {
+	EndpointID string   `json:"endpointId"` // stable endpoint reference of the device
+	Name       string   `json:"name"`       // name from the device scopes
+	Hardware   string   `json:"hardware"`   // hardware model from the device scopes
+	Address    string   `json:"address"`    // host of the first device service address
+	XAddrs     []string `json:"xaddrs"`     // device service URLs
+}
+
+// StreamProfile is a media profile of a camera with audio and its stream URI.
+type StreamProfile struct {
+	Token           string `json:"token"`
+	Name            string `json:"name"`
+	AudioEncoding   string `json:"audioEncoding"`   // e.g. AAC or G711
+	AudioSampleRate int    `json:"audioSampleRate"` // in kHz as reported by the camera
+	AudioBitrate    int    `json:"audioBitrate"`    // in kbps as reported by the camera
+	VideoEncoding   string `json:"videoEncoding,omitempty"`
+	URI             string `json:"uri"` // RTSP URI as reported by the camera
+}
+
+// DeviceURL returns the device service URL of a camera address, which may be
+// a host, a host:port or a full URL.
+func DeviceURL(address string) (string, error) {
+	address = strings.TrimSpace(address)
+	if address == "" {
+		return "", errors.Newf("camera address is required").
+			Component("onvif").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if !strings.Contains(address, "://") {
+		address = "http://" + address + "/onvif/device_service"
+	}
+	u, err := url.Parse(address)
+	if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return "", errors.Newf("invalid camera address: %s", address).
+			Component("onvif").
+			Category(errors.CategoryValidation).
+			Build()
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = "/onvif/device_service"
+	}
+	return u.String(), nil
+}
+
+// WithCredentials returns a stream URI with the username and password of the
+// camera, which ONVIF stream URIs usually leave out.
+func WithCredentials(uri, username, password string) string {
+	if username == "" {
+		return uri
+	}
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" {
+		return uri
+	}
+	u.User = url.UserPassword(username, password)
+	return u.String()
+}
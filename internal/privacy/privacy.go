@@ -140,7 +140,7 @@ var (
 	// streamURLPattern for finding and sanitizing stream URLs with credentials
 	// Supports RTSP, RTSPS, HTTP, HTTPS, RTMP, RTMPS, UDP, and RTP URLs
 	// including IPv6 addresses in brackets
-	streamURLPattern = regexp.MustCompile(`(?:rtsps?|https?|rtmps?|udp|rtp)://(?:[^:]+:[^@]+@)?(?:\[[0-9a-fA-F:]+\]|[^/:\s]+)(?::[0-9]+)?(?:/[^\s]*)?`)
+	streamURLPattern = regexp.MustCompile(`(?:rtsps?|https?|rtmps?|udp|rtp|srt)://(?:[^:]+:[^@]+@)?(?:\[[0-9a-fA-F:]+\]|[^/:?\s]+)(?::[0-9]+)?(?:[/?][^\s]*)?`)
 
 	// FFmpeg error prefix pattern - matches memory addresses like [rtsp @ 0x55d4a4808980]
 	ffmpegPrefixPattern = regexp.MustCompile(`\[\w+\s*@\s*0x[0-9a-fA-F]+\]\s*`)
@@ -253,11 +253,12 @@ var streamSchemes = map[string]bool{
 	"http": true, "https": true,
 	"rtmp": true, "rtmps": true,
 	"udp": true, "rtp": true,
+	"srt": true,
 }
 
 // SanitizeRTSPUrl removes sensitive information from stream URLs and returns a display-friendly version.
 // It strips credentials while preserving the host, port, and path for debugging.
-// Despite the name, this function handles all stream protocols (RTSP, HTTP, HLS, RTMP, UDP, RTP).
+// Despite the name, this function handles all stream protocols (RTSP, HTTP, HLS, RTMP, UDP, RTP, SRT).
 // Kept for backward compatibility - prefer SanitizeStreamUrl for new code.
 func SanitizeRTSPUrl(source string) string {
 	return SanitizeStreamUrl(source)
@@ -265,7 +266,8 @@ func SanitizeRTSPUrl(source string) string {
 
 // SanitizeStreamUrl removes sensitive information from stream URLs and returns a display-friendly version.
 // It strips credentials while preserving the host, port, and path for debugging.
-// Supports RTSP, RTSPS, HTTP, HTTPS, RTMP, RTMPS, UDP, and RTP URLs.
+// Supports RTSP, RTSPS, HTTP, HTTPS, RTMP, RTMPS, UDP, RTP, and SRT URLs.
+// The passphrase query parameter of SRT URLs is removed as well.
 func SanitizeStreamUrl(source string) string {
 	// Parse the URL using standard library
 	parsedURL, err := url.Parse(source)
@@ -282,6 +284,14 @@ func SanitizeStreamUrl(source string) string {
 	// Remove user credentials only (keeps path, query, and fragment for debugging)
 	parsedURL.User = nil
 
+	if parsedURL.Scheme == "srt" {
+		query := parsedURL.Query()
+		if query.Has("passphrase") {
+			query.Del("passphrase")
+			parsedURL.RawQuery = query.Encode()
+		}
+	}
+
 	return parsedURL.String()
 }
 
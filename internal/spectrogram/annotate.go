@@ -0,0 +1,266 @@
+// annotate.go: detection annotations drawn over raw spectrograms
+package spectrogram
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-audio/wav"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	// MaxAnnotationPredictions is the number of predictions listed above an annotated spectrogram
+	MaxAnnotationPredictions = 3
+
+	// annotationLineHeight is the height of a prediction label line in pixels
+	annotationLineHeight = 14
+
+	// soxMaxFreq is the top of sox spectrograms, which are rendered after resampling to 24 kHz
+	soxMaxFreq = 12000
+)
+
+// annotationHighlight is the color of the detection box and frequency range overlay.
+var annotationHighlight = color.RGBA{R: 255, G: 220, B: 0, A: 255}
+
+// annotationHighlightLight is the detection box color on light backgrounds.
+var annotationHighlightLight = color.RGBA{R: 200, G: 0, B: 0, A: 255}
+
+// Prediction is a species prediction listed on an annotated spectrogram.
+type Prediction struct {
+	Label      string  // species name
+	Confidence float64 // 0-1
+}
+
+// Annotation describes the detection drawn over an annotated spectrogram.
+type Annotation struct {
+	ClipStart time.Time // wall clock time of the first sample of the clip
+	BeginTime time.Time // start of the detection
+	EndTime   time.Time // end of the detection
+
+	// MinFreq and MaxFreq optionally mark the frequency range of the call in Hz.
+	// The overlay is drawn only if MaxFreq is greater than MinFreq.
+	MinFreq float64
+	MaxFreq float64
+
+	// Predictions are the top predictions, highest confidence first.
+	// At most MaxAnnotationPredictions are drawn.
+	Predictions []Prediction
+}
+
+// Annotate draws a detection annotation over a raw spectrogram of audioPath
+// and writes the result as a PNG to outputPath. The annotated image adds time
+// and frequency axes, a box marking the detection's begin and end time and
+// labels for the top predictions.
+func (g *Generator) Annotate(ctx context.Context, audioPath, spectrogramPath, outputPath string, ann *Annotation) error {
+	if ann == nil || !filepath.IsAbs(outputPath) {
+		return errors.Newf("annotation and absolute output path are required").
+			Component("spectrogram").
+			Category(errors.CategoryValidation).
+			Context("operation", "annotate").
+			Context("output_path", outputPath).
+			Build()
+	}
+
+	img, err := g.annotatedImage(ctx, audioPath, spectrogramPath, ann)
+	if err != nil {
+		return err
+	}
+	if err := g.ensureOutputDirectory(outputPath); err != nil {
+		return err
+	}
+	return writeNativeImage(img, outputPath)
+}
+
+// AnnotateTo draws a detection annotation like Annotate and writes the PNG to
+// w instead of a file, for annotated images that are not cached.
+func (g *Generator) AnnotateTo(ctx context.Context, audioPath, spectrogramPath string, w io.Writer, ann *Annotation) error {
+	if ann == nil {
+		return errors.Newf("annotation is required").
+			Component("spectrogram").
+			Category(errors.CategoryValidation).
+			Context("operation", "annotate").
+			Build()
+	}
+
+	img, err := g.annotatedImage(ctx, audioPath, spectrogramPath, ann)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(w, img); err != nil {
+		return errors.New(err).
+			Component("spectrogram").
+			Category(errors.CategoryFileIO).
+			Context("operation", "annotate").
+			Build()
+	}
+	return nil
+}
+
+// annotatedImage draws the annotation over the raw spectrogram.
+func (g *Generator) annotatedImage(ctx context.Context, audioPath, spectrogramPath string, ann *Annotation) (image.Image, error) {
+	base, err := readPNG(spectrogramPath)
+	if err != nil {
+		return nil, err
+	}
+
+	duration, sampleRate := g.clipInfo(ctx, audioPath)
+	minFreq, maxFreq := g.frequencyRange(sampleRate)
+	style := getNativeStyle(g.settings.Realtime.Dashboard.Spectrogram.Style)
+
+	return drawAnnotation(base, ann, duration, minFreq, maxFreq, style), nil
+}
+
+// readPNG decodes a PNG file.
+func readPNG(path string) (image.Image, error) {
+	f, err := os.Open(path) //nolint:gosec // G304: path is validated by the caller
+	if err != nil {
+		return nil, errors.New(err).
+			Component("spectrogram").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_spectrogram").
+			Context("path", path).
+			Build()
+	}
+	defer func() { _ = f.Close() }()
+
+	img, err := png.Decode(f)
+	if err != nil {
+		return nil, errors.New(err).
+			Component("spectrogram").
+			Category(errors.CategoryValidation).
+			Context("operation", "read_spectrogram").
+			Context("path", path).
+			Build()
+	}
+	return img, nil
+}
+
+// clipInfo returns the duration in seconds and sample rate of an audio clip.
+// WAV headers are read directly; other formats use ffprobe. Unknown values
+// fall back to the configured clip length and the capture sample rate.
+func (g *Generator) clipInfo(ctx context.Context, audioPath string) (duration float64, sampleRate int) {
+	sampleRate = conf.SampleRate
+	if strings.EqualFold(filepath.Ext(audioPath), ".wav") {
+		if f, err := os.Open(audioPath); err == nil { //nolint:gosec // G304: audioPath is validated by the caller
+			decoder := wav.NewDecoder(f)
+			if d, err := decoder.Duration(); err == nil && decoder.SampleRate > 0 {
+				duration, sampleRate = d.Seconds(), int(decoder.SampleRate)
+			}
+			_ = f.Close()
+		}
+	} else {
+		duration = getCachedAudioDuration(ctx, audioPath)
+	}
+	if duration <= 0 {
+		duration = float64(g.settings.Realtime.Audio.Export.Length)
+	}
+	return duration, sampleRate
+}
+
+// frequencyRange returns the frequency range shown in raw spectrograms of
+// audio at the given sample rate.
+func (g *Generator) frequencyRange(sampleRate int) (minFreq, maxFreq float64) {
+	nyquist := float64(sampleRate) / 2
+	if !g.settings.Realtime.Dashboard.Spectrogram.UseNativeRenderer() {
+		return 0, math.Min(soxMaxFreq, nyquist)
+	}
+	opts := g.nativeOptions()
+	maxFreq = math.Min(opts.maxFreq, nyquist)
+	if opts.minFreq >= maxFreq {
+		return 0, maxFreq
+	}
+	return opts.minFreq, maxFreq
+}
+
+// drawAnnotation returns the raw spectrogram framed by axes with the detection
+// box, frequency range overlay and prediction labels.
+func drawAnnotation(base image.Image, ann *Annotation, duration, minFreq, maxFreq float64, style nativeStyle) *image.RGBA {
+	predictions := ann.Predictions[:min(len(ann.Predictions), MaxAnnotationPredictions)]
+	labelsHeight := len(predictions)*annotationLineHeight + nativeMarginTop
+
+	size := base.Bounds().Size()
+	plot := image.Rect(0, 0, size.X, size.Y).Add(image.Pt(nativeMarginLeft, labelsHeight))
+	bounds := image.Rect(0, 0, plot.Max.X+nativeMarginTop, plot.Max.Y+nativeMarginBottom)
+
+	img := image.NewRGBA(bounds)
+	draw.Draw(img, bounds, image.NewUniform(style.background), image.Point{}, draw.Src)
+	draw.Draw(img, plot, base, base.Bounds().Min, draw.Src)
+	drawAxes(img, plot, duration, minFreq, maxFreq, style.foreground)
+
+	highlight := annotationHighlight
+	if style.background == lightBackground {
+		highlight = annotationHighlightLight
+	}
+
+	// Detection box over its time range and the whole frequency range
+	x0, x1 := plot.Min.X, plot.Max.X-1
+	if duration > 0 && !ann.ClipStart.IsZero() {
+		toX := func(t time.Time) int {
+			offset := t.Sub(ann.ClipStart).Seconds() / duration
+			return plot.Min.X + int(math.Round(math.Min(math.Max(offset, 0), 1)*float64(plot.Dx()-1)))
+		}
+		if !ann.BeginTime.IsZero() {
+			x0 = toX(ann.BeginTime)
+		}
+		if !ann.EndTime.IsZero() {
+			x1 = toX(ann.EndTime)
+		}
+	}
+	if x1 > x0 {
+		box := image.Rect(x0, plot.Min.Y, x1+1, plot.Max.Y)
+		strokeRect(img, box, highlight, 2)
+
+		// Frequency range overlay as dashed lines across the box
+		if span := maxFreq - minFreq; ann.MaxFreq > ann.MinFreq && span > 0 {
+			for _, f := range []float64{ann.MinFreq, ann.MaxFreq} {
+				if f < minFreq || f > maxFreq {
+					continue
+				}
+				y := plot.Max.Y - 1 - int(math.Round((f-minFreq)/span*float64(plot.Dy()-1)))
+				for x := box.Min.X; x < box.Max.X; x++ {
+					if (x-box.Min.X)%8 < 5 {
+						img.SetRGBA(x, y, highlight)
+					}
+				}
+			}
+		}
+	}
+
+	// Prediction labels above the plot
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(style.foreground), Face: basicfont.Face7x13}
+	for i, p := range predictions {
+		if i == 0 {
+			drawer.Src = image.NewUniform(highlight)
+		} else {
+			drawer.Src = image.NewUniform(style.foreground)
+		}
+		label := fmt.Sprintf("%s %.0f%%", p.Label, p.Confidence*100)
+		drawText(drawer, label, plot.Min.X, nativeMarginTop/2+(i+1)*annotationLineHeight-3)
+	}
+	return img
+}
+
+// strokeRect draws the outline of a rectangle with the given line width inside its bounds.
+func strokeRect(img *image.RGBA, r image.Rectangle, c color.RGBA, width int) {
+	for i := range width {
+		hLine(img, r.Min.X, r.Max.X, r.Min.Y+i, c)
+		hLine(img, r.Min.X, r.Max.X, r.Max.Y-1-i, c)
+		vLine(img, r.Min.X+i, r.Min.Y, r.Max.Y, c)
+		vLine(img, r.Max.X-1-i, r.Min.Y, r.Max.Y, c)
+	}
+}
@@ -0,0 +1,88 @@
+package spectrogram
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+func TestDrawAnnotation(t *testing.T) {
+	t.Parallel()
+
+	base := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	start := time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC)
+	ann := &Annotation{
+		ClipStart: start,
+		BeginTime: start.Add(3 * time.Second),
+		EndTime:   start.Add(6 * time.Second),
+		MinFreq:   2000,
+		MaxFreq:   4000,
+		Predictions: []Prediction{
+			{"Eurasian Blackbird", 0.92},
+			{"Song Thrush", 0.12},
+			{"European Robin", 0.05},
+			{"Common Chaffinch", 0.01},
+		},
+	}
+	img := drawAnnotation(base, ann, 12, 0, 12000, getNativeStyle(conf.SpectrogramStyleDefault))
+
+	labelsHeight := MaxAnnotationPredictions*annotationLineHeight + nativeMarginTop
+	assert.Equal(t, 400+nativeMarginLeft+nativeMarginTop, img.Bounds().Dx())
+	assert.Equal(t, 200+labelsHeight+nativeMarginBottom, img.Bounds().Dy(), "room for three prediction labels")
+
+	// The box spans 3-6 s of a 12 s clip: a quarter to a half of the plot width
+	plotX := func(fraction float64) int { return nativeMarginLeft + int(fraction*399+0.5) }
+	midY := labelsHeight + 100
+	assert.Equal(t, annotationHighlight, img.RGBAAt(plotX(0.25), midY), "box begins at the detection begin time")
+	assert.Equal(t, annotationHighlight, img.RGBAAt(plotX(0.5), midY), "box ends at the detection end time")
+	assert.Equal(t, color.RGBA{}, img.RGBAAt(plotX(0.375), midY), "spectrogram inside the box is unchanged")
+	assert.Equal(t, color.RGBA{}, img.RGBAAt(plotX(0.75), midY), "spectrogram outside the box is unchanged")
+
+	// The frequency range overlay crosses the box at 2 and 4 kHz
+	yAt := func(freq float64) int { return labelsHeight + 199 - int(freq/12000*199+0.5) }
+	assert.Equal(t, annotationHighlight, img.RGBAAt(plotX(0.25)+3, yAt(4000)))
+	assert.Equal(t, annotationHighlight, img.RGBAAt(plotX(0.25)+3, yAt(2000)))
+	assert.Equal(t, color.RGBA{}, img.RGBAAt(plotX(0.25)+3, yAt(3000)))
+}
+
+func TestAnnotate(t *testing.T) {
+	t.Parallel()
+
+	gen, tempDir := newNativeTestGenerator(t)
+	audioPath := filepath.Join(tempDir, "clip.wav")
+	writeTestStereoWAV(t, audioPath, 48000)
+	rawPath := filepath.Join(tempDir, "clip_400px.png")
+	require.NoError(t, gen.GenerateFromFile(t.Context(), audioPath, rawPath, 400, true))
+
+	start := time.Now()
+	ann := &Annotation{ClipStart: start, BeginTime: start, EndTime: start.Add(500 * time.Millisecond), Predictions: []Prediction{{"Eurasian Blackbird", 0.92}}}
+	outputPath := filepath.Join(tempDir, "clip_400px-annotated.png")
+	require.NoError(t, gen.Annotate(t.Context(), audioPath, rawPath, outputPath, ann))
+	assert.Equal(t, 200+annotationLineHeight+nativeMarginTop+nativeMarginBottom, decodeImageFile(t, outputPath).Bounds().Dy())
+
+	minFreq, maxFreq := gen.frequencyRange(48000)
+	assert.InDelta(t, 0, minFreq, 0)
+	assert.InDelta(t, defaultNativeMaxFreq, maxFreq, 0)
+	minFreq, maxFreq = gen.frequencyRange(16000)
+	assert.InDelta(t, 0, minFreq, 0)
+	assert.InDelta(t, 8000, maxFreq, 0, "limited to the Nyquist frequency")
+
+	require.Error(t, gen.Annotate(t.Context(), audioPath, filepath.Join(tempDir, "missing.png"), outputPath, ann))
+	require.Error(t, gen.Annotate(t.Context(), audioPath, rawPath, "relative.png", ann))
+
+	// Images written to a writer match the cached ones
+	var buf bytes.Buffer
+	require.NoError(t, gen.AnnotateTo(t.Context(), audioPath, rawPath, &buf, ann))
+	img, err := png.Decode(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, decodeImageFile(t, outputPath).Bounds(), img.Bounds())
+}
@@ -0,0 +1,145 @@
+// colormap.go: color maps and style presets for the native spectrogram renderer
+package spectrogram
+
+import (
+	"image/color"
+	"math"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+)
+
+// colorMapSize is the number of entries in a color lookup table.
+const colorMapSize = 256
+
+// nativeStyle describes the appearance of a native spectrogram.
+type nativeStyle struct {
+	colorMap   string
+	window     string
+	background color.RGBA // axes background
+	foreground color.RGBA // axes, labels
+}
+
+var (
+	darkBackground  = color.RGBA{R: 0, G: 0, B: 0, A: 255}
+	darkForeground  = color.RGBA{R: 200, G: 200, B: 200, A: 255}
+	lightBackground = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	lightForeground = color.RGBA{R: 40, G: 40, B: 40, A: 255}
+)
+
+// getNativeStyle returns the native renderer equivalent of a style preset,
+// matching the color maps and windows getStyleArgs selects for sox.
+func getNativeStyle(style string) nativeStyle {
+	switch style {
+	case conf.SpectrogramStyleScientificDark:
+		return nativeStyle{conf.SpectrogramColorMapGrayscale, conf.SpectrogramWindowDolph, darkBackground, darkForeground}
+	case conf.SpectrogramStyleHighContrastDark:
+		return nativeStyle{conf.SpectrogramColorMapHighContrast, conf.SpectrogramWindowHann, darkBackground, darkForeground}
+	case conf.SpectrogramStyleScientific:
+		return nativeStyle{conf.SpectrogramColorMapInverted, conf.SpectrogramWindowDolph, lightBackground, lightForeground}
+	default:
+		return nativeStyle{conf.SpectrogramColorMapSox, conf.SpectrogramWindowHann, darkBackground, darkForeground}
+	}
+}
+
+// colorStop is a point of a piecewise linear color map.
+type colorStop struct {
+	pos     float64
+	r, g, b float64
+}
+
+// highContrastStops run through the hues like sox's high contrast palette.
+var highContrastStops = []colorStop{
+	{0, 0, 0, 0},
+	{0.15, 0, 0, 0.6},
+	{0.3, 0, 0.5, 1},
+	{0.45, 0, 1, 0.5},
+	{0.6, 0.5, 1, 0},
+	{0.75, 1, 0.8, 0},
+	{0.9, 1, 0.2, 0},
+	{1, 1, 1, 1},
+}
+
+// viridisStops sample the perceptually uniform viridis color map.
+var viridisStops = []colorStop{
+	{0, 0.267, 0.005, 0.329},
+	{0.125, 0.283, 0.141, 0.458},
+	{0.25, 0.254, 0.265, 0.530},
+	{0.375, 0.207, 0.372, 0.553},
+	{0.5, 0.164, 0.471, 0.558},
+	{0.625, 0.128, 0.567, 0.551},
+	{0.75, 0.135, 0.659, 0.518},
+	{0.875, 0.478, 0.821, 0.318},
+	{1, 0.993, 0.906, 0.144},
+}
+
+// buildColorMap returns the lookup table of a color map, from the quietest
+// level at index 0 to the loudest.
+func buildColorMap(name string) [colorMapSize]color.RGBA {
+	var lut [colorMapSize]color.RGBA
+	for i := range lut {
+		c := float64(i) / (colorMapSize - 1)
+		var r, g, b float64
+		switch name {
+		case conf.SpectrogramColorMapGrayscale:
+			r, g, b = c, c, c
+		case conf.SpectrogramColorMapInverted:
+			r, g, b = 1-c, 1-c, 1-c
+		case conf.SpectrogramColorMapHighContrast:
+			r, g, b = interpolateStops(highContrastStops, c)
+		case conf.SpectrogramColorMapViridis:
+			r, g, b = interpolateStops(viridisStops, c)
+		default:
+			r, g, b = soxColor(c)
+		}
+		lut[i] = color.RGBA{R: toByte(r), G: toByte(g), B: toByte(b), A: 255}
+	}
+	return lut
+}
+
+// soxColor returns the color of sox's default palette: black through blue,
+// magenta, red and yellow to white.
+func soxColor(c float64) (r, g, b float64) {
+	switch {
+	case c < 0.13:
+		r = 0
+	case c < 0.73:
+		r = math.Sin((c - 0.13) / 0.60 * math.Pi / 2)
+	default:
+		r = 1
+	}
+	switch {
+	case c < 0.6:
+		g = 0
+	case c < 0.91:
+		g = math.Sin((c - 0.6) / 0.31 * math.Pi / 2)
+	default:
+		g = 1
+	}
+	switch {
+	case c < 0.6:
+		b = 0.5 * math.Sin(c/0.6*math.Pi)
+	case c < 0.78:
+		b = 0
+	default:
+		b = (c - 0.78) / 0.22
+	}
+	return r, g, b
+}
+
+// interpolateStops linearly interpolates a color between the stops around c.
+func interpolateStops(stops []colorStop, c float64) (r, g, b float64) {
+	for i := 1; i < len(stops); i++ {
+		if c <= stops[i].pos {
+			lo, hi := stops[i-1], stops[i]
+			t := (c - lo.pos) / (hi.pos - lo.pos)
+			return lo.r + t*(hi.r-lo.r), lo.g + t*(hi.g-lo.g), lo.b + t*(hi.b-lo.b)
+		}
+	}
+	last := stops[len(stops)-1]
+	return last.r, last.g, last.b
+}
+
+// toByte converts a color component in [0, 1] to a byte.
+func toByte(v float64) uint8 {
+	return uint8(math.Round(math.Min(math.Max(v, 0), 1) * 255))
+}
@@ -158,7 +158,8 @@ func (g *Generator) getDynamicRange() string {
 
 // GenerateFromFile creates a spectrogram from an audio file path.
 // Used by API on-demand and user-requested modes.
-// Tries Sox first (faster), falls back to FFmpeg if Sox fails.
+// Tries Sox first (faster), falls back to FFmpeg if Sox fails. With the native
+// renderer configured, the spectrogram is rendered in Go instead.
 //
 // The audioPath and outputPath must be absolute paths.
 // Width is in pixels, raw controls whether to show axes/legends.
@@ -208,6 +209,19 @@ func (g *Generator) GenerateFromFile(ctx context.Context, audioPath, outputPath
 		return err
 	}
 
+	// The native renderer needs neither sox nor (for WAV files) FFmpeg
+	if g.settings.Realtime.Dashboard.Spectrogram.UseNativeRenderer() {
+		nativeCtx, nativeCancel := context.WithTimeout(ctx, defaultGenerationTimeout)
+		defer nativeCancel()
+		if err := g.generateNativeFile(nativeCtx, audioPath, outputPath, width, raw); err != nil {
+			return err
+		}
+		g.logger.Debug("Native spectrogram generation completed successfully",
+			logger.String("audio_path", audioPath),
+			logger.Int64("duration_ms", time.Since(start).Milliseconds()))
+		return nil
+	}
+
 	// Create context with timeout for Sox (see function documentation for timeout layering behavior)
 	soxCtx, soxCancel := context.WithTimeout(ctx, defaultGenerationTimeout)
 	defer soxCancel()
@@ -245,7 +259,7 @@ func (g *Generator) GenerateFromFile(ctx context.Context, audioPath, outputPath
 
 // GenerateFromPCM creates a spectrogram from in-memory PCM data.
 // Used by pre-renderer (background mode).
-// PCM format: s16le, 48kHz, mono
+// PCM format: s16le, 48kHz, mono. Rendered by sox or the native renderer.
 //
 // Context Timeout Behavior:
 // This function enforces a 60-second timeout for spectrogram generation.
@@ -301,8 +315,12 @@ func (g *Generator) GenerateFromPCM(ctx context.Context, pcmData []byte, outputP
 	ctx, cancel := context.WithTimeout(ctx, defaultGenerationTimeout)
 	defer cancel()
 
-	// Generate directly from PCM stdin (no FFmpeg needed)
-	if err := g.generateWithSoxPCM(ctx, pcmData, outputPath, width, raw); err != nil {
+	if g.settings.Realtime.Dashboard.Spectrogram.UseNativeRenderer() {
+		if err := g.generateNativePCM(ctx, pcmData, outputPath, width, raw); err != nil {
+			return err
+		}
+	} else if err := g.generateWithSoxPCM(ctx, pcmData, outputPath, width, raw); err != nil {
+		// Generate directly from PCM stdin (no FFmpeg needed)
 		return err
 	}
 
@@ -0,0 +1,415 @@
+// native.go: pure Go spectrogram renderer
+package spectrogram
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-audio/wav"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+const (
+	// defaultNativeFFTSize is the FFT size used when none is configured
+	defaultNativeFFTSize = 1024
+
+	// defaultNativeMaxFreq matches the 12 kHz upper limit of sox after resampling to 24 kHz
+	defaultNativeMaxFreq = 12000
+
+	// Margins around the plot area when axes are drawn, in pixels
+	nativeMarginLeft   = 44
+	nativeMarginRight  = 64
+	nativeMarginTop    = 8
+	nativeMarginBottom = 22
+
+	// nativeColorBarWidth is the width of the level legend in pixels
+	nativeColorBarWidth = 12
+
+	// nativeTickLength is the length of axis ticks in pixels
+	nativeTickLength = 4
+
+	// nativeImagePermissions matches the permissions of images written by sox
+	nativeImagePermissions = 0o644
+)
+
+// nativeOptions are the resolved settings of a native render.
+type nativeOptions struct {
+	fftSize      int
+	window       string
+	minFreq      float64
+	maxFreq      float64
+	dynamicRange float64
+	colorMap     string
+	style        nativeStyle
+}
+
+// nativeOptions resolves the native renderer settings, filling unset values
+// from the style preset and defaults.
+func (g *Generator) nativeOptions() nativeOptions {
+	settings := &g.settings.Realtime.Dashboard.Spectrogram
+	style := getNativeStyle(settings.Style)
+	dynamicRange, err := strconv.ParseFloat(g.getDynamicRange(), 64)
+	if err != nil {
+		dynamicRange, _ = strconv.ParseFloat(defaultDynamicRange, 64)
+	}
+
+	opts := nativeOptions{
+		fftSize:      settings.Native.FFTSize,
+		window:       settings.Native.Window,
+		minFreq:      float64(settings.Native.MinFreq),
+		maxFreq:      float64(settings.Native.MaxFreq),
+		dynamicRange: dynamicRange,
+		colorMap:     settings.Native.ColorMap,
+		style:        style,
+	}
+	if opts.fftSize <= 0 || opts.fftSize&(opts.fftSize-1) != 0 {
+		opts.fftSize = defaultNativeFFTSize
+	}
+	if opts.window == "" {
+		opts.window = style.window
+	}
+	if opts.colorMap == "" {
+		opts.colorMap = style.colorMap
+	}
+	if opts.maxFreq <= 0 {
+		opts.maxFreq = defaultNativeMaxFreq
+	}
+	if opts.minFreq < 0 || opts.minFreq >= opts.maxFreq {
+		opts.minFreq = 0
+	}
+	return opts
+}
+
+// generateNativePCM renders a spectrogram from s16le mono PCM data at the capture sample rate.
+func (g *Generator) generateNativePCM(ctx context.Context, pcmData []byte, outputPath string, width int, raw bool) error {
+	return g.renderNative(ctx, pcmToSamples(pcmData), conf.SampleRate, outputPath, width, raw)
+}
+
+// generateNativeFile renders a spectrogram from an audio file. WAV files are
+// decoded in Go; other formats are decoded to PCM with FFmpeg.
+func (g *Generator) generateNativeFile(ctx context.Context, audioPath, outputPath string, width int, raw bool) error {
+	var samples []float64
+	var sampleRate int
+	var err error
+	if strings.EqualFold(filepath.Ext(audioPath), ".wav") {
+		samples, sampleRate, err = readWAVSamples(audioPath)
+	} else {
+		samples, sampleRate, err = g.decodeWithFFmpeg(ctx, audioPath)
+	}
+	if err != nil {
+		return err
+	}
+	return g.renderNative(ctx, samples, sampleRate, outputPath, width, raw)
+}
+
+// readWAVSamples reads a WAV file as mono samples in the range [-1, 1).
+// Multichannel files are mixed down.
+func readWAVSamples(audioPath string) (samples []float64, sampleRate int, err error) {
+	file, err := os.Open(audioPath) //nolint:gosec // G304: audioPath is validated by the caller
+	if err != nil {
+		return nil, 0, errors.New(err).
+			Component("spectrogram").
+			Category(errors.CategoryFileIO).
+			Context("operation", "read_wav_samples").
+			Context("audio_path", audioPath).
+			Build()
+	}
+	defer func() { _ = file.Close() }()
+
+	decoder := wav.NewDecoder(file)
+	buf, err := decoder.FullPCMBuffer()
+	if err != nil || !decoder.IsValidFile() || buf.Format == nil || buf.Format.NumChannels < 1 {
+		if err == nil {
+			err = errors.NewStd("invalid WAV file")
+		}
+		return nil, 0, errors.New(err).
+			Component("spectrogram").
+			Category(errors.CategoryValidation).
+			Context("operation", "read_wav_samples").
+			Context("audio_path", audioPath).
+			Build()
+	}
+
+	channels := buf.Format.NumChannels
+	scale := math.Pow(2, float64(buf.SourceBitDepth-1))
+	if buf.SourceBitDepth <= 0 {
+		scale = math.Pow(2, float64(decoder.BitDepth-1))
+	}
+	samples = make([]float64, len(buf.Data)/channels)
+	for i := range samples {
+		sum := 0
+		for ch := range channels {
+			sum += buf.Data[i*channels+ch]
+		}
+		samples[i] = float64(sum) / float64(channels) / scale
+	}
+	return samples, buf.Format.SampleRate, nil
+}
+
+// decodeWithFFmpeg decodes an audio file to mono samples at the capture sample rate.
+func (g *Generator) decodeWithFFmpeg(ctx context.Context, audioPath string) (samples []float64, sampleRate int, err error) {
+	ffmpegBinary := g.settings.Realtime.Audio.FfmpegPath
+	if ffmpegBinary == "" {
+		return nil, 0, errors.Newf("ffmpeg binary not configured").
+			Component("spectrogram").
+			Category(errors.CategoryConfiguration).
+			Context("operation", "native_decode_with_ffmpeg").
+			Build()
+	}
+
+	args := []string{
+		"-hide_banner", "-loglevel", "error",
+		"-i", audioPath,
+		"-f", "s16le",
+		"-ac", strconv.Itoa(conf.NumChannels),
+		"-ar", strconv.Itoa(conf.SampleRate),
+		"pipe:1",
+	}
+	cmd := createCommandWithNice(ctx, ffmpegBinary, args)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		eb := errors.New(err).
+			Component("spectrogram").
+			Category(errors.CategorySystem).
+			Context("operation", "native_decode_with_ffmpeg").
+			Context("audio_path", audioPath).
+			Context("ffmpeg_stderr", stderr.String())
+		if IsOperationalError(err) {
+			eb = eb.Priority(errors.PriorityLow)
+		}
+		return nil, 0, eb.Build()
+	}
+	return pcmToSamples(stdout.Bytes()), conf.SampleRate, nil
+}
+
+// renderNative renders samples to a PNG or WebP image at outputPath. The plot
+// area is width pixels wide and width/heightRatio pixels high like sox output;
+// axes and a level legend are drawn around it unless raw is set.
+func (g *Generator) renderNative(ctx context.Context, samples []float64, sampleRate int, outputPath string, width int, raw bool) error {
+	if len(samples) == 0 || sampleRate <= 0 {
+		return errors.Newf("no audio samples to render").
+			Component("spectrogram").
+			Category(errors.CategoryValidation).
+			Context("operation", "render_native").
+			Context("output_path", outputPath).
+			Build()
+	}
+
+	opts := g.nativeOptions()
+	opts.maxFreq = math.Min(opts.maxFreq, float64(sampleRate)/2)
+	if opts.minFreq >= opts.maxFreq {
+		opts.minFreq = 0
+	}
+	img, err := renderNativeImage(ctx, samples, sampleRate, width, max(width/heightRatio, 1), raw, &opts)
+	if err != nil {
+		return err
+	}
+	return writeNativeImage(img, outputPath)
+}
+
+// renderNativeImage computes the spectrogram of samples and draws it.
+func renderNativeImage(ctx context.Context, samples []float64, sampleRate, width, height int, raw bool, opts *nativeOptions) (*image.RGBA, error) {
+	levels, err := computeLevels(ctx, samples, &stftParams{
+		sampleRate: sampleRate,
+		fftSize:    opts.fftSize,
+		window:     makeWindow(opts.window, opts.fftSize, opts.dynamicRange),
+		columns:    width,
+		rows:       height,
+		minFreq:    opts.minFreq,
+		maxFreq:    opts.maxFreq,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lut := buildColorMap(opts.colorMap)
+	plot := image.Rect(0, 0, width, height)
+	if !raw {
+		plot = plot.Add(image.Pt(nativeMarginLeft, nativeMarginTop))
+	}
+	bounds := plot
+	if !raw {
+		bounds = image.Rect(0, 0, plot.Max.X+nativeMarginRight, plot.Max.Y+nativeMarginBottom)
+	}
+
+	img := image.NewRGBA(bounds)
+	if !raw {
+		draw.Draw(img, bounds, image.NewUniform(opts.style.background), image.Point{}, draw.Src)
+	}
+	for col, column := range levels {
+		for row, level := range column {
+			img.SetRGBA(plot.Min.X+col, plot.Min.Y+row, lut[levelIndex(level, opts.dynamicRange)])
+		}
+	}
+
+	if !raw {
+		duration := float64(len(samples)) / float64(sampleRate)
+		drawNativeAxes(img, plot, duration, opts, &lut)
+	}
+	return img, nil
+}
+
+// levelIndex maps a level in dBFS to a color map index, with levels below the
+// dynamic range at index 0.
+func levelIndex(level, dynamicRange float64) int {
+	idx := int(math.Round((level + dynamicRange) / dynamicRange * (colorMapSize - 1)))
+	return min(max(idx, 0), colorMapSize-1)
+}
+
+// drawNativeAxes draws the frequency and time axes and the level legend around the plot.
+func drawNativeAxes(img *image.RGBA, plot image.Rectangle, duration float64, opts *nativeOptions, lut *[colorMapSize]color.RGBA) {
+	drawAxes(img, plot, duration, opts.minFreq, opts.maxFreq, opts.style.foreground)
+
+	fg := opts.style.foreground
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(fg), Face: basicfont.Face7x13}
+	ascent := basicfont.Face7x13.Metrics().Ascent.Ceil()
+
+	// Level legend from 0 dBFS at the top to the bottom of the dynamic range
+	bar := image.Rect(plot.Max.X+8, plot.Min.Y, plot.Max.X+8+nativeColorBarWidth, plot.Max.Y)
+	for y := bar.Min.Y; y < bar.Max.Y; y++ {
+		level := -float64(y-bar.Min.Y) / float64(max(bar.Dy()-1, 1)) * opts.dynamicRange
+		c := lut[levelIndex(level, opts.dynamicRange)]
+		hLine(img, bar.Min.X, bar.Max.X, y, c)
+	}
+	for _, level := range []float64{0, -opts.dynamicRange / 2, -opts.dynamicRange} {
+		y := bar.Min.Y + int(math.Round(-level/opts.dynamicRange*float64(bar.Dy()-1)))
+		drawText(drawer, fmt.Sprintf("%.0fdB", level), bar.Max.X+3, min(max(y+ascent/2-1, bar.Min.Y+ascent), bar.Max.Y))
+	}
+}
+
+// drawAxes draws a border around the plot with a frequency axis in kHz on the
+// left and a time axis in seconds below it.
+func drawAxes(img *image.RGBA, plot image.Rectangle, duration, minFreq, maxFreq float64, fg color.RGBA) {
+	drawer := &font.Drawer{Dst: img, Src: image.NewUniform(fg), Face: basicfont.Face7x13}
+	ascent := basicfont.Face7x13.Metrics().Ascent.Ceil()
+
+	// Plot border
+	hLine(img, plot.Min.X-1, plot.Max.X, plot.Min.Y-1, fg)
+	hLine(img, plot.Min.X-1, plot.Max.X, plot.Max.Y, fg)
+	vLine(img, plot.Min.X-1, plot.Min.Y-1, plot.Max.Y, fg)
+	vLine(img, plot.Max.X, plot.Min.Y-1, plot.Max.Y, fg)
+
+	// Frequency axis
+	freqSpan := maxFreq - minFreq
+	if freqSpan > 0 {
+		freqStep := niceStep(freqSpan, plot.Dy()/30)
+		first := math.Ceil(minFreq / freqStep)
+		for i := 0.0; (first+i)*freqStep <= maxFreq+1e-9; i++ {
+			f := (first + i) * freqStep
+			y := plot.Max.Y - int(math.Round((f-minFreq)/freqSpan*float64(plot.Dy())))
+			hLine(img, plot.Min.X-1-nativeTickLength, plot.Min.X-1, y, fg)
+			label := formatTick(f/1000, freqStep/1000) + "k"
+			drawText(drawer, label, plot.Min.X-nativeTickLength-3-font.MeasureString(drawer.Face, label).Ceil(), y+ascent/2-1)
+		}
+	}
+
+	// Time axis
+	if duration > 0 {
+		timeStep := niceStep(duration, plot.Dx()/60)
+		for i := 0.0; i*timeStep <= duration+1e-9; i++ {
+			t := i * timeStep
+			x := plot.Min.X + int(math.Round(t/duration*float64(plot.Dx())))
+			vLine(img, x, plot.Max.Y+1, plot.Max.Y+1+nativeTickLength, fg)
+			label := formatTick(t, timeStep) + "s"
+			drawText(drawer, label, x-font.MeasureString(drawer.Face, label).Ceil()/2, plot.Max.Y+nativeTickLength+ascent+2)
+		}
+	}
+}
+
+// niceStep returns a 1, 2 or 5 times power of ten step that divides span into at most maxTicks intervals.
+func niceStep(span float64, maxTicks int) float64 {
+	maxTicks = max(maxTicks, 1)
+	raw := span / float64(maxTicks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if step := m * magnitude; step >= raw {
+			return step
+		}
+	}
+	return 10 * magnitude
+}
+
+// formatTick formats an axis tick value with as many decimals as the tick step needs.
+func formatTick(v, step float64) string {
+	decimals := max(0, int(math.Ceil(-math.Log10(step)-1e-9)))
+	return strconv.FormatFloat(v, 'f', decimals, 64)
+}
+
+// hLine draws a horizontal line from x0 up to but not including x1.
+func hLine(img *image.RGBA, x0, x1, y int, c color.RGBA) {
+	for x := x0; x < x1; x++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// vLine draws a vertical line from y0 up to but not including y1.
+func vLine(img *image.RGBA, x, y0, y1 int, c color.RGBA) {
+	for y := y0; y < y1; y++ {
+		img.SetRGBA(x, y, c)
+	}
+}
+
+// drawText draws text with its baseline starting at (x, y).
+func drawText(d *font.Drawer, text string, x, y int) {
+	d.Dot = fixed.P(x, y)
+	d.DrawString(text)
+}
+
+// writeNativeImage encodes img as WebP for .webp paths and as PNG otherwise.
+// The image is written to a temporary file and renamed into place so readers
+// never see a partial image.
+func writeNativeImage(img image.Image, outputPath string) error {
+	encode := func(w io.Writer) error { return png.Encode(w, img) }
+	if strings.EqualFold(filepath.Ext(outputPath), ".webp") {
+		encode = func(w io.Writer) error { return encodeWebP(w, img) }
+	}
+
+	wrap := func(err error) error {
+		return errors.New(err).
+			Component("spectrogram").
+			Category(errors.CategoryFileIO).
+			Context("operation", "write_native_image").
+			Context("output_path", outputPath).
+			Build()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(outputPath), ".spectrogram-*.tmp")
+	if err != nil {
+		return wrap(err)
+	}
+	tmpPath := tmp.Name()
+	defer func() { _ = os.Remove(tmpPath) }()
+
+	if err := encode(tmp); err != nil {
+		_ = tmp.Close()
+		return wrap(err)
+	}
+	if err := tmp.Close(); err != nil {
+		return wrap(err)
+	}
+	if err := os.Chmod(tmpPath, nativeImagePermissions); err != nil {
+		return wrap(err)
+	}
+	if err := os.Rename(tmpPath, outputPath); err != nil {
+		return wrap(err)
+	}
+	return nil
+}
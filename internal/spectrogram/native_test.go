@@ -0,0 +1,330 @@
+package spectrogram
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-audio/audio"
+	"github.com/go-audio/wav"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/image/webp"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/securefs"
+)
+
+// newNativeTestGenerator returns a generator configured for the native renderer.
+func newNativeTestGenerator(t *testing.T) (gen *Generator, tempDir string) {
+	t.Helper()
+	env := setupTestEnv(t)
+	env.Settings.Realtime.Dashboard.Spectrogram.Renderer = conf.SpectrogramRendererNative
+	return NewGenerator(env.Settings, env.SFS, nil), env.TempDir
+}
+
+// decodeImageFile decodes a PNG or WebP file.
+func decodeImageFile(t *testing.T, path string) image.Image {
+	t.Helper()
+	data, err := os.ReadFile(path) //nolint:gosec // G304: test file in temp dir
+	require.NoError(t, err)
+	var img image.Image
+	if filepath.Ext(path) == ".webp" {
+		img, err = webp.Decode(bytes.NewReader(data))
+	} else {
+		img, err = png.Decode(bytes.NewReader(data))
+	}
+	require.NoError(t, err)
+	return img
+}
+
+func TestMakeWindow(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{conf.SpectrogramWindowHann, conf.SpectrogramWindowHamming, conf.SpectrogramWindowBlackman, conf.SpectrogramWindowDolph} {
+		w := makeWindow(name, 1024, 100)
+		require.Len(t, w, 1024, name)
+		assert.InDelta(t, w[0], w[len(w)-1], 1e-9, "%s window must be symmetric", name)
+		assert.InDelta(t, 1, slicesMax(w), 0.01, "%s window must peak near 1", name)
+		assert.Less(t, w[0], 0.1, "%s window must taper", name)
+	}
+}
+
+// slicesMax returns the largest value of a slice.
+func slicesMax(values []float64) float64 {
+	m := math.Inf(-1)
+	for _, v := range values {
+		m = math.Max(m, v)
+	}
+	return m
+}
+
+func TestComputeLevels_SinePeak(t *testing.T) {
+	t.Parallel()
+
+	const sampleRate, freq = 48000, 3000.0
+	samples := make([]float64, sampleRate)
+	for i := range samples {
+		samples[i] = math.Sin(2 * math.Pi * freq * float64(i) / sampleRate)
+	}
+
+	const rows = 120 // 100 Hz per row
+	levels, err := computeLevels(t.Context(), samples, &stftParams{
+		sampleRate: sampleRate,
+		fftSize:    1024,
+		window:     makeWindow(conf.SpectrogramWindowHann, 1024, 100),
+		columns:    32,
+		rows:       rows,
+		minFreq:    0,
+		maxFreq:    12000,
+	})
+	require.NoError(t, err)
+	require.Len(t, levels, 32)
+
+	column := levels[16]
+	peakRow := 0
+	for row, level := range column {
+		if level > column[peakRow] {
+			peakRow = row
+		}
+	}
+	peakFreq := 12000 - (float64(peakRow)+0.5)*100
+	assert.InDelta(t, freq, peakFreq, 100, "peak must be at the sine frequency")
+	assert.InDelta(t, 0, column[peakRow], 1.5, "a full-scale sine must read about 0 dBFS")
+	assert.Less(t, column[0], -60.0, "far from the sine the level must be low")
+}
+
+func TestComputeLevels_Canceled(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	_, err := computeLevels(ctx, make([]float64, 4800), &stftParams{
+		sampleRate: 48000, fftSize: 256, window: makeWindow("", 256, 100),
+		columns: 10, rows: 10, maxFreq: 12000,
+	})
+	require.Error(t, err)
+}
+
+func TestBuildColorMap(t *testing.T) {
+	t.Parallel()
+
+	sox := buildColorMap(conf.SpectrogramColorMapSox)
+	assert.Equal(t, color.RGBA{0, 0, 0, 255}, sox[0], "sox palette starts at black")
+	assert.Equal(t, color.RGBA{255, 255, 255, 255}, sox[colorMapSize-1], "sox palette ends at white")
+
+	gray := buildColorMap(conf.SpectrogramColorMapGrayscale)
+	inverted := buildColorMap(conf.SpectrogramColorMapInverted)
+	for i := range colorMapSize {
+		assert.Equal(t, gray[i].R, gray[i].B)
+		assert.Equal(t, 255-gray[i].R, inverted[i].R)
+	}
+
+	viridis := buildColorMap(conf.SpectrogramColorMapViridis)
+	assert.Greater(t, viridis[colorMapSize-1].G, viridis[0].G)
+}
+
+func TestGetNativeStyle(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		style    string
+		colorMap string
+		window   string
+		light    bool
+	}{
+		{conf.SpectrogramStyleDefault, conf.SpectrogramColorMapSox, conf.SpectrogramWindowHann, false},
+		{conf.SpectrogramStyleScientificDark, conf.SpectrogramColorMapGrayscale, conf.SpectrogramWindowDolph, false},
+		{conf.SpectrogramStyleHighContrastDark, conf.SpectrogramColorMapHighContrast, conf.SpectrogramWindowHann, false},
+		{conf.SpectrogramStyleScientific, conf.SpectrogramColorMapInverted, conf.SpectrogramWindowDolph, true},
+	}
+	for _, tt := range tests {
+		style := getNativeStyle(tt.style)
+		assert.Equal(t, tt.colorMap, style.colorMap, tt.style)
+		assert.Equal(t, tt.window, style.window, tt.style)
+		assert.Equal(t, tt.light, style.background == lightBackground, tt.style)
+	}
+}
+
+func TestNativeOptions_Defaults(t *testing.T) {
+	t.Parallel()
+
+	gen, _ := newNativeTestGenerator(t)
+	gen.settings.Realtime.Dashboard.Spectrogram.Style = conf.SpectrogramStyleScientific
+	gen.settings.Realtime.Dashboard.Spectrogram.DynamicRange = conf.SpectrogramDynamicRangeExtended
+	opts := gen.nativeOptions()
+	assert.Equal(t, defaultNativeFFTSize, opts.fftSize)
+	assert.InDelta(t, defaultNativeMaxFreq, opts.maxFreq, 0)
+	assert.InDelta(t, 120, opts.dynamicRange, 0)
+	assert.Equal(t, conf.SpectrogramWindowDolph, opts.window, "window follows the style")
+	assert.Equal(t, conf.SpectrogramColorMapInverted, opts.colorMap, "color map follows the style")
+
+	gen.settings.Realtime.Dashboard.Spectrogram.Native = conf.NativeSpectrogramSettings{
+		FFTSize: 2048, Window: conf.SpectrogramWindowBlackman, MinFreq: 1000, MaxFreq: 8000, ColorMap: conf.SpectrogramColorMapViridis,
+	}
+	opts = gen.nativeOptions()
+	assert.Equal(t, 2048, opts.fftSize)
+	assert.Equal(t, conf.SpectrogramWindowBlackman, opts.window)
+	assert.Equal(t, conf.SpectrogramColorMapViridis, opts.colorMap)
+	assert.InDelta(t, 1000, opts.minFreq, 0)
+	assert.InDelta(t, 8000, opts.maxFreq, 0)
+}
+
+func TestEncodeWebP_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(x, y int) color.RGBA{
+		"gradient": func(x, y int) color.RGBA {
+			return color.RGBA{uint8(x * 3), uint8(y * 5), uint8(x ^ y), 255} //nolint:gosec // G115: test pattern wraps intentionally
+		},
+		"solid": func(_, _ int) color.RGBA { return color.RGBA{12, 200, 99, 255} },
+		"palette": func(x, y int) color.RGBA {
+			lut := buildColorMap(conf.SpectrogramColorMapSox)
+			return lut[(x*y)%colorMapSize]
+		},
+	}
+	for name, pattern := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			img := image.NewRGBA(image.Rect(0, 0, 83, 41))
+			for y := range 41 {
+				for x := range 83 {
+					img.SetRGBA(x, y, pattern(x, y))
+				}
+			}
+
+			var buf bytes.Buffer
+			require.NoError(t, encodeWebP(&buf, img))
+			decoded, err := webp.Decode(&buf)
+			require.NoError(t, err)
+			require.Equal(t, img.Bounds(), decoded.Bounds())
+			for y := range 41 {
+				for x := range 83 {
+					r, g, b, a := decoded.At(x, y).RGBA()
+					got := color.RGBA{uint8(r >> 8), uint8(g >> 8), uint8(b >> 8), uint8(a >> 8)} //nolint:gosec // G115: 16-bit to 8-bit color
+					require.Equal(t, img.RGBAAt(x, y), got, "pixel %d,%d", x, y)
+				}
+			}
+		})
+	}
+
+	require.Error(t, encodeWebP(&bytes.Buffer{}, image.NewRGBA(image.Rect(0, 0, 0, 0))))
+}
+
+func TestGenerateFromPCM_Native(t *testing.T) {
+	t.Parallel()
+
+	gen, tempDir := newNativeTestGenerator(t)
+	pcm := generateTestPCMData(DefaultPCMOptions())
+
+	tests := []struct {
+		name string
+		file string
+		raw  bool
+	}{
+		{"raw png", "raw.png", true},
+		{"png with axes", "axes.png", false},
+		{"raw webp", "raw.webp", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			outputPath := filepath.Join(tempDir, "clips", tt.file)
+			require.NoError(t, gen.GenerateFromPCM(t.Context(), pcm, outputPath, 400, tt.raw))
+
+			img := decodeImageFile(t, outputPath)
+			if tt.raw {
+				assert.Equal(t, image.Rect(0, 0, 400, 200), img.Bounds())
+			} else {
+				assert.Equal(t, 400+nativeMarginLeft+nativeMarginRight, img.Bounds().Dx())
+				assert.Equal(t, 200+nativeMarginTop+nativeMarginBottom, img.Bounds().Dy())
+			}
+
+			info, err := os.Stat(outputPath)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(nativeImagePermissions), info.Mode().Perm())
+		})
+	}
+
+	entries, err := os.ReadDir(filepath.Join(tempDir, "clips"))
+	require.NoError(t, err)
+	assert.Len(t, entries, len(tests), "no temporary files are left behind")
+}
+
+func TestGenerateFromFile_NativeWAV(t *testing.T) {
+	t.Parallel()
+
+	gen, tempDir := newNativeTestGenerator(t)
+	// Native rendering must not need external binaries for WAV files
+	gen.settings.Realtime.Audio.SoxPath = ""
+	gen.settings.Realtime.Audio.FfmpegPath = ""
+
+	audioPath := filepath.Join(tempDir, "clip.wav")
+	writeTestStereoWAV(t, audioPath, 22050)
+	outputPath := filepath.Join(tempDir, "clip.png")
+	require.NoError(t, gen.GenerateFromFile(t.Context(), audioPath, outputPath, 200, true))
+	assert.Equal(t, image.Rect(0, 0, 200, 100), decodeImageFile(t, outputPath).Bounds())
+
+	err := gen.GenerateFromFile(t.Context(), filepath.Join(tempDir, "clip.mp3"), outputPath, 200, true)
+	require.Error(t, err, "other formats need FFmpeg")
+}
+
+// writeTestStereoWAV writes a one second 16-bit stereo WAV with a 1 kHz sine.
+func writeTestStereoWAV(t *testing.T, path string, sampleRate int) {
+	t.Helper()
+	file, err := os.Create(path) //nolint:gosec // G304: test file in temp dir
+	require.NoError(t, err)
+	enc := wav.NewEncoder(file, sampleRate, 16, 2, 1)
+	data := make([]int, 2*sampleRate)
+	for i := range sampleRate {
+		v := int(8000 * math.Sin(2*math.Pi*1000*float64(i)/float64(sampleRate)))
+		data[2*i], data[2*i+1] = v, v
+	}
+	require.NoError(t, enc.Write(&audio.IntBuffer{Data: data, Format: &audio.Format{SampleRate: sampleRate, NumChannels: 2}, SourceBitDepth: 16}))
+	require.NoError(t, enc.Close())
+	require.NoError(t, file.Close())
+}
+
+func BenchmarkGenerateFromPCM_Native(b *testing.B) {
+	benchmarkGenerateFromPCM(b, conf.SpectrogramRendererNative)
+}
+
+func BenchmarkGenerateFromPCM_Sox(b *testing.B) {
+	benchmarkGenerateFromPCM(b, conf.SpectrogramRendererSox)
+}
+
+// benchmarkGenerateFromPCM renders a 15 second clip at the default size with a renderer.
+func benchmarkGenerateFromPCM(b *testing.B, renderer string) {
+	b.Helper()
+	tempDir := b.TempDir()
+	settings := &conf.Settings{}
+	settings.Realtime.Dashboard.Spectrogram.Renderer = renderer
+	if renderer == conf.SpectrogramRendererSox {
+		soxPath, err := exec.LookPath("sox")
+		if err != nil {
+			b.Skip("Sox binary not found in PATH")
+		}
+		settings.Realtime.Audio.SoxPath = soxPath
+	}
+	sfs, err := securefs.New(tempDir)
+	require.NoError(b, err)
+	b.Cleanup(func() { _ = sfs.Close() })
+
+	gen := NewGenerator(settings, sfs, nil)
+	opts := DefaultPCMOptions()
+	opts.Duration = 15
+	pcm := generateTestPCMData(opts)
+	outputPath := filepath.Join(tempDir, "bench.png")
+
+	for b.Loop() {
+		if err := gen.GenerateFromPCM(b.Context(), pcm, outputPath, 400, true); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
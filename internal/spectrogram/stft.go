@@ -0,0 +1,200 @@
+// stft.go: short-time Fourier transform for the native spectrogram renderer
+package spectrogram
+
+import (
+	"context"
+	"math"
+	"math/cmplx"
+	"sync"
+
+	"github.com/tphakala/birdnet-go/internal/conf"
+	"github.com/tphakala/birdnet-go/internal/fft"
+)
+
+// levelFloor is the level in dBFS used for silence, below any dynamic range.
+const levelFloor = -200.0
+
+// windowCacheKey identifies a cached window function.
+type windowCacheKey struct {
+	window      string
+	size        int
+	attenuation float64
+}
+
+// windowCache holds computed window functions, which are reused for every spectrogram.
+var windowCache sync.Map // windowCacheKey -> []float64
+
+// makeWindow returns the window function of the given size. attenuation is
+// the side lobe level in dB of the Dolph-Chebyshev window.
+func makeWindow(window string, size int, attenuation float64) []float64 {
+	key := windowCacheKey{window, size, attenuation}
+	if cached, ok := windowCache.Load(key); ok {
+		return cached.([]float64)
+	}
+
+	if window == conf.SpectrogramWindowDolph {
+		w := dolphChebyshevWindow(size, attenuation)
+		windowCache.Store(key, w)
+		return w
+	}
+
+	w := make([]float64, size)
+	denom := float64(size - 1)
+	switch window {
+	case conf.SpectrogramWindowHamming:
+		for n := range w {
+			w[n] = 0.54 - 0.46*math.Cos(2*math.Pi*float64(n)/denom)
+		}
+	case conf.SpectrogramWindowBlackman:
+		for n := range w {
+			x := 2 * math.Pi * float64(n) / denom
+			w[n] = 0.42 - 0.5*math.Cos(x) + 0.08*math.Cos(2*x)
+		}
+	default: // Hann
+		for n := range w {
+			w[n] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(n)/denom)
+		}
+	}
+
+	windowCache.Store(key, w)
+	return w
+}
+
+// dolphChebyshevWindow returns a Dolph-Chebyshev window of an even size whose
+// side lobes are attenuation dB below the main lobe. It is computed from its
+// frequency response with an inverse FFT.
+func dolphChebyshevWindow(size int, attenuation float64) []float64 {
+	order := float64(size - 1)
+	beta := math.Cosh(math.Acosh(math.Pow(10, attenuation/20)) / order)
+
+	p := make([]complex128, size)
+	for k := range p {
+		x := beta * math.Cos(math.Pi*float64(k)/float64(size))
+		var v float64
+		switch {
+		case x > 1:
+			v = math.Cosh(order * math.Acosh(x))
+		case x < -1:
+			// order is odd for even sizes
+			v = -math.Cosh(order * math.Acosh(-x))
+		default:
+			v = math.Cos(order * math.Acos(x))
+		}
+		// Half-sample shift for an even-length window
+		p[k] = complex(v, 0) * cmplx.Exp(complex(0, math.Pi*float64(k)/float64(size)))
+	}
+	fft.Transform(p)
+
+	half := size/2 + 1
+	w := make([]float64, 0, size)
+	for i := half - 1; i > 0; i-- {
+		w = append(w, real(p[i]))
+	}
+	for i := 1; i < half; i++ {
+		w = append(w, real(p[i]))
+	}
+
+	peak := 0.0
+	for _, v := range w {
+		peak = math.Max(peak, v)
+	}
+	for i := range w {
+		w[i] /= peak
+	}
+	return w
+}
+
+// stftParams describes how samples are turned into spectrogram levels.
+type stftParams struct {
+	sampleRate int
+	fftSize    int
+	window     []float64
+	columns    int     // output width
+	rows       int     // output height
+	minFreq    float64 // frequency of the bottom row edge in Hz
+	maxFreq    float64 // frequency of the top row edge in Hz
+}
+
+// computeLevels returns the spectrogram levels in dBFS indexed by [column][row],
+// with row 0 at the top. Each column is the spectrum of a frame centered on the
+// column's time, and each row is the loudest FFT bin within its frequency band.
+// A full-scale sine wave has a level of 0 dBFS.
+func computeLevels(ctx context.Context, samples []float64, p *stftParams) ([][]float64, error) {
+	binWidth := float64(p.sampleRate) / float64(p.fftSize)
+	rowHeight := (p.maxFreq - p.minFreq) / float64(p.rows)
+	maxBin := p.fftSize / 2
+
+	// Precompute the bin range of each row
+	binLo := make([]int, p.rows)
+	binHi := make([]int, p.rows)
+	for row := range p.rows {
+		top := p.maxFreq - float64(row)*rowHeight
+		bottom := top - rowHeight
+		lo := int(math.Ceil(bottom / binWidth))
+		hi := int(math.Floor(top / binWidth))
+		if hi < lo {
+			// Band narrower than a bin: use the nearest bin
+			lo = int(math.Round((top + bottom) / 2 / binWidth))
+			hi = lo
+		}
+		binLo[row] = min(max(lo, 0), maxBin)
+		binHi[row] = min(max(hi, 0), maxBin)
+	}
+
+	windowSum := 0.0
+	for _, v := range p.window {
+		windowSum += v
+	}
+	// Scale magnitudes so a full-scale sine reads 0 dBFS
+	scale := 2 / windowSum
+
+	frame := make([]complex128, p.fftSize)
+	power := make([]float64, maxBin+1)
+	levels := make([][]float64, p.columns)
+	for col := range p.columns {
+		if col%64 == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		center := int((float64(col) + 0.5) * float64(len(samples)) / float64(p.columns))
+		start := center - p.fftSize/2
+		for i := range frame {
+			s := 0.0
+			if idx := start + i; idx >= 0 && idx < len(samples) {
+				s = samples[idx]
+			}
+			frame[i] = complex(s*p.window[i], 0)
+		}
+		fft.Transform(frame)
+		for k := range power {
+			m := cmplx.Abs(frame[k]) * scale
+			power[k] = m * m
+		}
+
+		column := make([]float64, p.rows)
+		for row := range p.rows {
+			peak := 0.0
+			for k := binLo[row]; k <= binHi[row]; k++ {
+				peak = math.Max(peak, power[k])
+			}
+			if peak > 0 {
+				column[row] = math.Max(10*math.Log10(peak), levelFloor)
+			} else {
+				column[row] = levelFloor
+			}
+		}
+		levels[col] = column
+	}
+	return levels, nil
+}
+
+// pcmToSamples converts s16le PCM data to samples in the range [-1, 1).
+func pcmToSamples(pcmData []byte) []float64 {
+	samples := make([]float64, len(pcmData)/2)
+	for i := range samples {
+		samples[i] = float64(int16(uint16(pcmData[2*i])|uint16(pcmData[2*i+1])<<8)) / 32768 //nolint:gosec // G115: reinterpreting little-endian bytes as int16
+	}
+	return samples
+}
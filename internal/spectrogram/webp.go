@@ -0,0 +1,391 @@
+// webp.go: lossless WebP encoder for native spectrograms
+package spectrogram
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"image"
+	"io"
+	"slices"
+
+	"github.com/tphakala/birdnet-go/internal/errors"
+)
+
+// VP8L bitstream constants
+const (
+	vp8lSignature        = 0x2f
+	vp8lMaxDimension     = 1 << 14
+	vp8lTransformSubGrn  = 2
+	vp8lNumLengthCodes   = 24
+	vp8lNumDistanceCodes = 40
+	vp8lColorCacheBits   = 10
+	vp8lColorCacheMult   = 0x1e35a7bd
+	vp8lMaxCodeLength    = 15
+	vp8lMaxCodeLenLength = 7
+	vp8lZeroRunShort     = 17 // repeats zero 3-10 times
+	vp8lZeroRunLong      = 18 // repeats zero 11-138 times
+)
+
+// vp8lCodeLengthOrder is the order in which code length code lengths are stored.
+var vp8lCodeLengthOrder = [19]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// encodeWebP writes img as a lossless WebP (VP8L) image. Spectrograms use few
+// colors, so the subtract green transform, a color cache and Huffman coding
+// compress them well without backward references.
+func encodeWebP(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return errors.Newf("image size %dx%d cannot be encoded as WebP", width, height).
+			Component("spectrogram").
+			Category(errors.CategoryValidation).
+			Context("operation", "encode_webp").
+			Build()
+	}
+
+	// Apply the subtract green transform: red and blue are coded relative to green
+	pixels := make([]uint32, 0, width*height)
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			r, g, b, a := img.At(x, y).RGBA()
+			r8, g8, b8, a8 := uint8(r>>8), uint8(g>>8), uint8(b>>8), uint8(a>>8) //nolint:gosec // G115: 16-bit color to 8-bit
+			pixels = append(pixels, uint32(a8)<<24|uint32(r8-g8)<<16|uint32(g8)<<8|uint32(b8-g8))
+		}
+	}
+
+	// Tokenize pixels into color cache hits and literals
+	const cacheSymbolBase = 256 + vp8lNumLengthCodes
+	histograms := [5][]int{
+		make([]int, cacheSymbolBase+1<<vp8lColorCacheBits), // green, length prefixes, cache indices
+		make([]int, 256), // red
+		make([]int, 256), // blue
+		make([]int, 256), // alpha
+		make([]int, vp8lNumDistanceCodes),
+	}
+	var cache [1 << vp8lColorCacheBits]uint32
+	var cacheValid [1 << vp8lColorCacheBits]bool
+	tokens := make([]int32, len(pixels)) // cache index, or -1 for a literal
+	for i, argb := range pixels {
+		key := (argb * vp8lColorCacheMult) >> (32 - vp8lColorCacheBits)
+		if cacheValid[key] && cache[key] == argb {
+			tokens[i] = int32(key) //nolint:gosec // G115: key is below the cache size
+			histograms[0][cacheSymbolBase+int(key)]++
+		} else {
+			tokens[i] = -1
+			histograms[0][argb>>8&0xff]++
+			histograms[1][argb>>16&0xff]++
+			histograms[2][argb&0xff]++
+			histograms[3][argb>>24]++
+		}
+		cache[key], cacheValid[key] = argb, true
+	}
+
+	bw := &bitWriter{}
+	bw.write(vp8lSignature, 8)
+	bw.write(uint32(width-1), 14)  //nolint:gosec // G115: width checked above
+	bw.write(uint32(height-1), 14) //nolint:gosec // G115: height checked above
+	bw.write(0, 1)                 // alpha is not used
+	bw.write(0, 3)                 // version
+	bw.write(1, 1)                 // transform present
+	bw.write(vp8lTransformSubGrn, 2)
+	bw.write(0, 1)                  // no more transforms
+	bw.write(1, 1)                  // color cache
+	bw.write(vp8lColorCacheBits, 4) // color cache size
+	bw.write(0, 1)                  // single prefix code group
+
+	var codes [5]prefixCode
+	for i, histogram := range histograms {
+		codes[i] = writePrefixCode(bw, histogram)
+	}
+
+	for i, argb := range pixels {
+		if tokens[i] >= 0 {
+			codes[0].write(bw, cacheSymbolBase+int(tokens[i]))
+			continue
+		}
+		codes[0].write(bw, int(argb>>8&0xff))
+		codes[1].write(bw, int(argb>>16&0xff))
+		codes[2].write(bw, int(argb&0xff))
+		codes[3].write(bw, int(argb>>24))
+	}
+
+	return writeWebPContainer(w, bw.bytes())
+}
+
+// writeWebPContainer wraps a VP8L bitstream in a RIFF WebP container.
+func writeWebPContainer(w io.Writer, data []byte) error {
+	padded := len(data) + len(data)%2
+	header := make([]byte, 20, 20+padded)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(12+padded)) //nolint:gosec // G115: bounded by the image size limit
+	copy(header[8:16], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(len(data))) //nolint:gosec // G115: bounded by the image size limit
+	out := append(header, data...)
+	if len(data)%2 == 1 {
+		out = append(out, 0)
+	}
+	_, err := w.Write(out)
+	return err
+}
+
+// bitWriter writes values least significant bit first, as VP8L requires.
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+// write appends the low n bits of v.
+func (b *bitWriter) write(v uint32, n uint) {
+	b.acc |= uint64(v) << b.nbits
+	b.nbits += n
+	for b.nbits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nbits -= 8
+	}
+}
+
+// bytes returns the written data, padding the last byte with zeros.
+func (b *bitWriter) bytes() []byte {
+	if b.nbits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nbits = 0, 0
+	}
+	return b.buf
+}
+
+// prefixCode holds the bit-reversed canonical Huffman codes of an alphabet.
+type prefixCode struct {
+	codes   []uint32
+	lengths []uint8
+}
+
+// write emits the code of a symbol. Symbols of a single-symbol code take no bits.
+func (p *prefixCode) write(bw *bitWriter, symbol int) {
+	if n := p.lengths[symbol]; n > 0 {
+		bw.write(p.codes[symbol], uint(n))
+	}
+}
+
+// writePrefixCode writes the Huffman code for a symbol histogram and returns it.
+func writePrefixCode(bw *bitWriter, histogram []int) prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	// A single symbol below 256 uses the simple code, which takes no bits per symbol
+	if len(used) <= 1 && (len(used) == 0 || used[0] < 256) {
+		symbol := 0
+		if len(used) == 1 {
+			symbol = used[0]
+		}
+		bw.write(1, 1) // simple code
+		bw.write(0, 1) // one symbol
+		if symbol < 2 {
+			bw.write(0, 1)
+			bw.write(uint32(symbol), 1) //nolint:gosec // G115: symbol is 0 or 1
+		} else {
+			bw.write(1, 1)
+			bw.write(uint32(symbol), 8) //nolint:gosec // G115: symbol is below 256
+		}
+		return prefixCode{codes: make([]uint32, len(histogram)), lengths: make([]uint8, len(histogram))}
+	}
+
+	code := newPrefixCode(histogram, vp8lMaxCodeLength)
+	lengths := code.lengths
+	bw.write(0, 1) // normal code
+
+	// Run-length encode the code lengths, using codes 17 and 18 for zero runs
+	type lengthToken struct {
+		symbol, extra int
+		extraBits     uint
+	}
+	var tokens []lengthToken
+	for i := 0; i < len(lengths); {
+		if lengths[i] != 0 {
+			tokens = append(tokens, lengthToken{symbol: int(lengths[i])})
+			i++
+			continue
+		}
+		run := 1
+		for i+run < len(lengths) && lengths[i+run] == 0 && run < 138 {
+			run++
+		}
+		switch {
+		case run >= 11:
+			tokens = append(tokens, lengthToken{vp8lZeroRunLong, run - 11, 7})
+		case run >= 3:
+			tokens = append(tokens, lengthToken{vp8lZeroRunShort, run - 3, 3})
+		default:
+			run = 1
+			tokens = append(tokens, lengthToken{symbol: 0})
+		}
+		i += run
+	}
+
+	lengthHistogram := make([]int, len(vp8lCodeLengthOrder))
+	for _, token := range tokens {
+		lengthHistogram[token.symbol]++
+	}
+	lengthCode := newPrefixCode(lengthHistogram, vp8lMaxCodeLenLength)
+
+	numCodes := 4
+	for i, symbol := range vp8lCodeLengthOrder {
+		if lengthCode.lengths[symbol] > 0 {
+			numCodes = max(numCodes, i+1)
+		}
+	}
+	bw.write(uint32(numCodes-4), 4) //nolint:gosec // G115: at most 15
+	for _, symbol := range vp8lCodeLengthOrder[:numCodes] {
+		bw.write(uint32(lengthCode.lengths[symbol]), 3)
+	}
+	bw.write(0, 1) // code lengths for the whole alphabet follow
+	for _, token := range tokens {
+		lengthCode.write(bw, token.symbol)
+		if token.extraBits > 0 {
+			bw.write(uint32(token.extra), token.extraBits) //nolint:gosec // G115: extra fits the bit count
+		}
+	}
+
+	return code
+}
+
+// newPrefixCode returns a Huffman code for a histogram with at least two
+// symbols, so every symbol takes at least one bit.
+func newPrefixCode(histogram []int, maxLength int) prefixCode {
+	used := 0
+	for _, count := range histogram {
+		if count > 0 {
+			used++
+		}
+	}
+	if used < 2 {
+		// Add unused symbols to avoid a zero-bit code, which decoders special-case
+		histogram = slices.Clone(histogram)
+		for i := range histogram {
+			if used >= 2 {
+				break
+			}
+			if histogram[i] == 0 {
+				histogram[i] = 1
+				used++
+			}
+		}
+	}
+	return canonicalPrefixCode(huffmanLengths(histogram, maxLength))
+}
+
+// canonicalPrefixCode assigns canonical codes to code lengths. Codes are bit
+// reversed because the bit writer emits the least significant bit first.
+func canonicalPrefixCode(lengths []uint8) prefixCode {
+	var count [vp8lMaxCodeLength + 1]uint32
+	for _, n := range lengths {
+		count[n]++
+	}
+	count[0] = 0
+	var next [vp8lMaxCodeLength + 2]uint32
+	code := uint32(0)
+	for n := 1; n <= vp8lMaxCodeLength; n++ {
+		code = (code + count[n-1]) << 1
+		next[n] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, n := range lengths {
+		if n == 0 {
+			continue
+		}
+		c := next[n]
+		next[n]++
+		reversed := uint32(0)
+		for range n {
+			reversed = reversed<<1 | c&1
+			c >>= 1
+		}
+		codes[symbol] = reversed
+	}
+	return prefixCode{codes: codes, lengths: lengths}
+}
+
+// huffmanLengths returns Huffman code lengths for a histogram, limited to
+// maxLength bits by flattening the histogram until the code fits. The
+// histogram must have at least two used symbols.
+func huffmanLengths(histogram []int, maxLength int) []uint8 {
+	counts := slices.Clone(histogram)
+	for {
+		lengths := unlimitedHuffmanLengths(counts)
+		if slices.Max(lengths) <= uint8(maxLength) { //nolint:gosec // G115: maxLength is at most 15
+			return lengths
+		}
+		for i, c := range counts {
+			if c > 0 {
+				counts[i] = max(1, c/2)
+			}
+		}
+	}
+}
+
+// huffmanNode is a node of a Huffman tree under construction.
+type huffmanNode struct {
+	weight      int
+	symbol      int // leaf symbol, or -1
+	left, right int // child node indices
+}
+
+// huffmanHeap orders node indices by weight.
+type huffmanHeap struct {
+	nodes []huffmanNode
+	order []int
+}
+
+func (h *huffmanHeap) Len() int { return len(h.order) }
+func (h *huffmanHeap) Less(i, j int) bool {
+	a, b := h.nodes[h.order[i]], h.nodes[h.order[j]]
+	if a.weight != b.weight {
+		return a.weight < b.weight
+	}
+	return h.order[i] < h.order[j]
+}
+func (h *huffmanHeap) Swap(i, j int) { h.order[i], h.order[j] = h.order[j], h.order[i] }
+func (h *huffmanHeap) Push(x any)    { h.order = append(h.order, x.(int)) }
+func (h *huffmanHeap) Pop() any {
+	last := h.order[len(h.order)-1]
+	h.order = h.order[:len(h.order)-1]
+	return last
+}
+
+// unlimitedHuffmanLengths returns the code lengths of a Huffman tree.
+func unlimitedHuffmanLengths(counts []int) []uint8 {
+	h := &huffmanHeap{}
+	for symbol, count := range counts {
+		if count > 0 {
+			h.nodes = append(h.nodes, huffmanNode{weight: count, symbol: symbol, left: -1, right: -1})
+			h.order = append(h.order, len(h.nodes)-1)
+		}
+	}
+	heap.Init(h)
+	for h.Len() > 1 {
+		a, b := heap.Pop(h).(int), heap.Pop(h).(int)
+		h.nodes = append(h.nodes, huffmanNode{weight: h.nodes[a].weight + h.nodes[b].weight, symbol: -1, left: a, right: b})
+		heap.Push(h, len(h.nodes)-1)
+	}
+
+	lengths := make([]uint8, len(counts))
+	type item struct{ node, depth int }
+	stack := []item{{len(h.nodes) - 1, 0}}
+	for len(stack) > 0 {
+		it := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		node := h.nodes[it.node]
+		if node.symbol >= 0 {
+			lengths[node.symbol] = uint8(min(it.depth, 255)) //nolint:gosec // G115: clamped
+			continue
+		}
+		stack = append(stack, item{node.left, it.depth + 1}, item{node.right, it.depth + 1})
+	}
+	return lengths
+}
@@ -21,22 +21,33 @@ type StreamPublisher struct {
 // The caller should wait a few seconds after calling this for the stream to become
 // available on all MediaMTX protocols (RTSP, RTMP, HLS).
 func PublishWAVToMediaMTX(ctx context.Context, wavPath, rtspURL string) (*StreamPublisher, error) {
+	return PublishWAVToMediaMTXWithCodec(ctx, wavPath, rtspURL,
+		"-c:a", "libopus", // Opus codec (RTSP-compatible, low CPU)
+		"-b:a", "64k", // Bitrate
+		"-ar", "48000", // Sample rate matching BirdNET-Go
+	)
+}
+
+// PublishWAVToMediaMTXWithCodec publishes a WAV file as mono audio encoded
+// with codecArgs, e.g. "-c:a", "pcm_mulaw", "-ar", "8000" for G.711.
+func PublishWAVToMediaMTXWithCodec(ctx context.Context, wavPath, rtspURL string, codecArgs ...string) (*StreamPublisher, error) {
 	pubCtx, cancel := context.WithCancel(ctx)
 
-	//nolint:gosec // G204: paths are from test infrastructure, not user input
-	cmd := exec.CommandContext(pubCtx, "ffmpeg",
+	args := []string{
 		"-re",                // Read input at native framerate (real-time playback)
 		"-stream_loop", "-1", // Loop forever
 		"-i", wavPath, // Input file
-		"-c:a", "libopus", // Opus codec (RTSP-compatible, low CPU)
-		"-b:a", "64k", // Bitrate
-		"-ar", "48000", // Sample rate matching BirdNET-Go
+	}
+	args = append(args, codecArgs...)
+	args = append(args,
 		"-ac", "1", // Mono
 		"-f", "rtsp", // Output format
 		"-rtsp_transport", "tcp", // Use TCP for Docker compatibility
 		rtspURL, // Destination
 	)
 
+	cmd := exec.CommandContext(pubCtx, "ffmpeg", args...) //nolint:gosec // G204: paths are from test infrastructure, not user input
+
 	if err := cmd.Start(); err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to start FFmpeg publisher: %w", err)
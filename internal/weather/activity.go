@@ -0,0 +1,484 @@
+package weather
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// Weather variables detection activity can be binned by.
+const (
+	VariableTemperature = "temperature"
+	VariableWindSpeed   = "wind_speed"
+	VariableClouds      = "clouds"
+	VariablePressure    = "pressure"
+	VariableCondition   = "condition"
+)
+
+// Weather conditions derived from standardized icon codes.
+const (
+	ConditionClear        = "clear"
+	ConditionCloudy       = "cloudy"
+	ConditionFog          = "fog"
+	ConditionRain         = "rain"
+	ConditionThunderstorm = "thunderstorm"
+	ConditionSleet        = "sleet"
+	ConditionSnow         = "snow"
+	ConditionUnknown      = "unknown"
+)
+
+// Day assessments of the rainy day view.
+const (
+	AssessmentNormal      = "normal"
+	AssessmentWeather     = "weather"     // activity is low and the weather explains it
+	AssessmentUnexplained = "unexplained" // activity is low without bad weather, check the equipment
+	AssessmentNoWeather   = "no_weather_data"
+)
+
+const (
+	// maxWeatherGap is how far the nearest weather record may be from an hour
+	// for the hour to be attributed to it.
+	maxWeatherGap = 90 * time.Minute
+	// temperatureBinWidth is the width of temperature bins in °C.
+	temperatureBinWidth = 5
+	// baselineDays is the number of days either side of a day whose dry days
+	// form its expected activity.
+	baselineDays = 7
+	// minBaselineWeatherHours is the weather coverage a dry day needs to be
+	// used as a baseline.
+	minBaselineWeatherHours = 12
+	// lowActivityRatio is the detections/expected ratio below which a day is low.
+	lowActivityRatio = 0.5
+	// rainyHoursThreshold and windyThreshold (m/s) explain a low-activity day.
+	rainyHoursThreshold = 3
+	windyThreshold      = 8.0
+)
+
+var (
+	windSpeedEdges = []float64{0, 2, 5, 8, 11}         // m/s, roughly Beaufort 0-1, 2-3, 4, 5, 6+
+	cloudEdges     = []float64{0, 25, 50, 75}          // %
+	pressureEdges  = []float64{1000, 1010, 1020, 1030} // hPa
+	conditionOrder = []string{ConditionClear, ConditionCloudy, ConditionFog, ConditionRain, ConditionThunderstorm, ConditionSleet, ConditionSnow, ConditionUnknown}
+)
+
+// ActivityBin is detection activity during hours with weather in one bin.
+type ActivityBin struct {
+	Label             string   `json:"label"`
+	Min               *float64 `json:"min,omitempty"` // inclusive lower bound, numeric variables only
+	Max               *float64 `json:"max,omitempty"` // exclusive upper bound, numeric variables only
+	Hours             int      `json:"hours"`
+	Detections        int      `json:"detections"`
+	DetectionsPerHour float64  `json:"detections_per_hour"`
+}
+
+// SpeciesBinActivity is the activity of one species per bin, in bin order.
+type SpeciesBinActivity struct {
+	ScientificName string    `json:"scientific_name"`
+	CommonName     string    `json:"common_name"`
+	Total          int       `json:"total"`
+	Detections     []int     `json:"detections"`
+	PerHour        []float64 `json:"per_hour"`
+}
+
+// VariableActivity is detection activity binned by one weather variable.
+type VariableActivity struct {
+	Variable string               `json:"variable"`
+	Unit     string               `json:"unit,omitempty"`
+	Bins     []ActivityBin        `json:"bins"`
+	Species  []SpeciesBinActivity `json:"species"`
+}
+
+// ActivityCorrelation relates detection activity to weather conditions.
+type ActivityCorrelation struct {
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	// WeatherHours is the number of hours with a weather record within 90 minutes.
+	WeatherHours int `json:"weather_hours"`
+	// UnmatchedDetections were made during hours without weather data.
+	UnmatchedDetections int                `json:"unmatched_detections"`
+	Variables           []VariableActivity `json:"variables"`
+}
+
+// DayActivity compares one day's detections to the expected activity.
+type DayActivity struct {
+	Date          string   `json:"date"`
+	Detections    int      `json:"detections"`
+	Expected      *float64 `json:"expected,omitempty"` // mean detections of nearby dry days
+	Ratio         *float64 `json:"ratio,omitempty"`
+	WeatherHours  int      `json:"weather_hours"`
+	RainyHours    int      `json:"rainy_hours"`
+	MeanWindSpeed float64  `json:"mean_wind_speed"`
+	MeanTemp      float64  `json:"mean_temperature"`
+	Rainy         bool     `json:"rainy"`
+	Assessment    string   `json:"assessment"`
+}
+
+// Condition maps a weather record to a condition using its standardized icon code.
+func Condition(w *datastore.HourlyWeather) string {
+	switch IconCode(w.WeatherIcon) {
+	case IconClearSky, IconFair:
+		return ConditionClear
+	case IconPartlyCloudy, IconCloudy:
+		return ConditionCloudy
+	case IconFog:
+		return ConditionFog
+	case IconRainShowers, IconRain:
+		return ConditionRain
+	case IconThunderstorm:
+		return ConditionThunderstorm
+	case IconSleet:
+		return ConditionSleet
+	case IconSnow:
+		return ConditionSnow
+	default:
+		return ConditionUnknown
+	}
+}
+
+// isPrecipitation reports whether a condition involves precipitation.
+func isPrecipitation(condition string) bool {
+	switch condition {
+	case ConditionRain, ConditionThunderstorm, ConditionSleet, ConditionSnow:
+		return true
+	}
+	return false
+}
+
+// hourSlot is one local clock hour and the weather attributed to it.
+type hourSlot struct {
+	date       string
+	weather    *datastore.HourlyWeather // nil if no record within maxWeatherGap
+	detections int
+	species    map[string]int
+}
+
+// hourSlots is every hour of a date range with weather and detections attached.
+type hourSlots struct {
+	slots       []*hourSlot
+	byKey       map[string]*hourSlot
+	commonNames map[string]string
+	unmatched   int
+}
+
+// slotKey identifies a local clock hour as "YYYY-MM-DD HH".
+func slotKey(date string, hour int) string {
+	return fmt.Sprintf("%s %02d", date, hour)
+}
+
+// buildHourSlots attributes weather records and detections to the hours of
+// [startDate, endDate] in loc.
+func buildHourSlots(startDate, endDate string, loc *time.Location, detections []datastore.DetectionMinuteCount, hourly []datastore.HourlyWeather) (*hourSlots, error) {
+	start, err := time.ParseInLocation(time.DateOnly, startDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start date: %w", err)
+	}
+	end, err := time.ParseInLocation(time.DateOnly, endDate, loc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid end date: %w", err)
+	}
+
+	records := slices.Clone(hourly)
+	slices.SortFunc(records, func(a, b datastore.HourlyWeather) int { return a.Time.Compare(b.Time) })
+
+	hs := &hourSlots{byKey: make(map[string]*hourSlot), commonNames: make(map[string]string)}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		for hour := range 24 {
+			slot := &hourSlot{date: date, species: make(map[string]int)}
+			// Attribute the weather nearest to the middle of the hour
+			mid := time.Date(day.Year(), day.Month(), day.Day(), hour, 30, 0, 0, loc)
+			slot.weather = nearestWeather(records, mid)
+			hs.slots = append(hs.slots, slot)
+			hs.byKey[slotKey(date, hour)] = slot
+		}
+	}
+
+	for i := range detections {
+		d := &detections[i]
+		hour, err := strconv.Atoi(strings.SplitN(d.Minute, ":", 2)[0])
+		if err != nil || d.Count <= 0 {
+			continue
+		}
+		slot, ok := hs.byKey[slotKey(d.Date, hour)]
+		if !ok {
+			continue
+		}
+		if slot.weather == nil {
+			hs.unmatched += d.Count
+		}
+		slot.detections += d.Count
+		slot.species[d.ScientificName] += d.Count
+		if _, ok := hs.commonNames[d.ScientificName]; !ok {
+			hs.commonNames[d.ScientificName] = d.CommonName
+		}
+	}
+
+	return hs, nil
+}
+
+// nearestWeather returns the record closest to t within maxWeatherGap.
+// records must be sorted by time.
+func nearestWeather(records []datastore.HourlyWeather, t time.Time) *datastore.HourlyWeather {
+	i, _ := slices.BinarySearchFunc(records, t, func(w datastore.HourlyWeather, target time.Time) int {
+		return w.Time.Compare(target)
+	})
+	var best *datastore.HourlyWeather
+	bestGap := maxWeatherGap + 1
+	for _, j := range []int{i - 1, i} {
+		if j < 0 || j >= len(records) {
+			continue
+		}
+		gap := records[j].Time.Sub(t)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap <= maxWeatherGap && gap < bestGap {
+			best, bestGap = &records[j], gap
+		}
+	}
+	return best
+}
+
+// binner assigns a weather record to a bin of one variable.
+type binner struct {
+	variable string
+	unit     string
+	// bin returns the bin label, its sort order and optional numeric bounds.
+	bin func(w *datastore.HourlyWeather) (label string, order float64, lo, hi *float64)
+}
+
+// edgeBin places v into the bins delimited by edges. Values below the first
+// edge fall into an open lower bin.
+func edgeBin(v float64, edges []float64, unit string) (label string, order float64, lo, hi *float64) {
+	idx := -1
+	for i, e := range edges {
+		if v >= e {
+			idx = i
+		}
+	}
+	switch {
+	case idx < 0:
+		upper := edges[0]
+		return fmt.Sprintf("< %g %s", upper, unit), -1, nil, &upper
+	case idx == len(edges)-1:
+		lower := edges[idx]
+		return fmt.Sprintf(">= %g %s", lower, unit), float64(idx), &lower, nil
+	default:
+		lower, upper := edges[idx], edges[idx+1]
+		return fmt.Sprintf("%g-%g %s", lower, upper, unit), float64(idx), &lower, &upper
+	}
+}
+
+var binners = []binner{
+	{variable: VariableTemperature, unit: "°C", bin: func(w *datastore.HourlyWeather) (string, float64, *float64, *float64) {
+		lower := math.Floor(w.Temperature/temperatureBinWidth) * temperatureBinWidth
+		upper := lower + temperatureBinWidth
+		return fmt.Sprintf("%g to %g °C", lower, upper), lower, &lower, &upper
+	}},
+	{variable: VariableWindSpeed, unit: "m/s", bin: func(w *datastore.HourlyWeather) (string, float64, *float64, *float64) {
+		return edgeBin(w.WindSpeed, windSpeedEdges, "m/s")
+	}},
+	{variable: VariableClouds, unit: "%", bin: func(w *datastore.HourlyWeather) (string, float64, *float64, *float64) {
+		return edgeBin(float64(w.Clouds), cloudEdges, "%")
+	}},
+	{variable: VariablePressure, unit: "hPa", bin: func(w *datastore.HourlyWeather) (string, float64, *float64, *float64) {
+		return edgeBin(float64(w.Pressure), pressureEdges, "hPa")
+	}},
+	{variable: VariableCondition, bin: func(w *datastore.HourlyWeather) (string, float64, *float64, *float64) {
+		condition := Condition(w)
+		return condition, float64(slices.Index(conditionOrder, condition)), nil, nil
+	}},
+}
+
+// ComputeActivityCorrelation bins the hours of [startDate, endDate] by each
+// weather variable and reports detections per hour in each bin, overall and
+// per species. Dates and detection minutes are in loc.
+func ComputeActivityCorrelation(startDate, endDate string, loc *time.Location, detections []datastore.DetectionMinuteCount, hourly []datastore.HourlyWeather) (*ActivityCorrelation, error) {
+	hs, err := buildHourSlots(startDate, endDate, loc, detections, hourly)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ActivityCorrelation{
+		StartDate:           startDate,
+		EndDate:             endDate,
+		UnmatchedDetections: hs.unmatched,
+		Variables:           make([]VariableActivity, 0, len(binners)),
+	}
+	for _, slot := range hs.slots {
+		if slot.weather != nil {
+			result.WeatherHours++
+		}
+	}
+
+	for _, b := range binners {
+		result.Variables = append(result.Variables, binVariable(b, hs))
+	}
+	return result, nil
+}
+
+// binVariable aggregates hour slots into the bins of one variable.
+func binVariable(b binner, hs *hourSlots) VariableActivity {
+	type binAccumulator struct {
+		bin     ActivityBin
+		order   float64
+		species map[string]int
+	}
+	bins := make(map[string]*binAccumulator)
+
+	for _, slot := range hs.slots {
+		if slot.weather == nil {
+			continue
+		}
+		label, order, lo, hi := b.bin(slot.weather)
+		acc, ok := bins[label]
+		if !ok {
+			acc = &binAccumulator{bin: ActivityBin{Label: label, Min: lo, Max: hi}, order: order, species: make(map[string]int)}
+			bins[label] = acc
+		}
+		acc.bin.Hours++
+		acc.bin.Detections += slot.detections
+		for species, count := range slot.species {
+			acc.species[species] += count
+		}
+	}
+
+	ordered := make([]*binAccumulator, 0, len(bins))
+	for _, acc := range bins {
+		ordered = append(ordered, acc)
+	}
+	slices.SortFunc(ordered, func(a, b *binAccumulator) int {
+		if a.order != b.order {
+			if a.order < b.order {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a.bin.Label, b.bin.Label)
+	})
+
+	va := VariableActivity{
+		Variable: b.variable,
+		Unit:     b.unit,
+		Bins:     make([]ActivityBin, 0, len(ordered)),
+		Species:  []SpeciesBinActivity{},
+	}
+	speciesIndex := make(map[string]int)
+	for i, acc := range ordered {
+		acc.bin.DetectionsPerHour = perHour(acc.bin.Detections, acc.bin.Hours)
+		va.Bins = append(va.Bins, acc.bin)
+		for species, count := range acc.species {
+			idx, ok := speciesIndex[species]
+			if !ok {
+				idx = len(va.Species)
+				speciesIndex[species] = idx
+				va.Species = append(va.Species, SpeciesBinActivity{
+					ScientificName: species,
+					CommonName:     hs.commonNames[species],
+					Detections:     make([]int, len(ordered)),
+					PerHour:        make([]float64, len(ordered)),
+				})
+			}
+			sp := &va.Species[idx]
+			sp.Total += count
+			sp.Detections[i] = count
+			sp.PerHour[i] = perHour(count, acc.bin.Hours)
+		}
+	}
+	slices.SortFunc(va.Species, func(a, b SpeciesBinActivity) int {
+		if a.Total != b.Total {
+			return b.Total - a.Total
+		}
+		return strings.Compare(a.ScientificName, b.ScientificName)
+	})
+	return va
+}
+
+// perHour returns count/hours rounded to two decimals.
+func perHour(count, hours int) float64 {
+	if hours == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(hours)*100) / 100
+}
+
+// ComputeDailyActivity compares each day's detections with the mean of nearby
+// dry days, and assesses whether low-activity days are explained by rain or
+// wind or point to an equipment problem.
+func ComputeDailyActivity(startDate, endDate string, loc *time.Location, detections []datastore.DetectionMinuteCount, hourly []datastore.HourlyWeather) ([]DayActivity, error) {
+	hs, err := buildHourSlots(startDate, endDate, loc, detections, hourly)
+	if err != nil {
+		return nil, err
+	}
+
+	days := make([]DayActivity, 0, len(hs.slots)/24)
+	var windSum, tempSum float64
+	for i, slot := range hs.slots {
+		if i%24 == 0 {
+			days = append(days, DayActivity{Date: slot.date})
+			windSum, tempSum = 0, 0
+		}
+		day := &days[len(days)-1]
+		day.Detections += slot.detections
+		if slot.weather != nil {
+			day.WeatherHours++
+			windSum += slot.weather.WindSpeed
+			tempSum += slot.weather.Temperature
+			if isPrecipitation(Condition(slot.weather)) {
+				day.RainyHours++
+			}
+		}
+		if i%24 == 23 && day.WeatherHours > 0 {
+			day.MeanWindSpeed = math.Round(windSum/float64(day.WeatherHours)*10) / 10
+			day.MeanTemp = math.Round(tempSum/float64(day.WeatherHours)*10) / 10
+		}
+	}
+
+	for i := range days {
+		days[i].Rainy = days[i].RainyHours > 0
+	}
+
+	for i := range days {
+		day := &days[i]
+		var sum float64
+		var n int
+		for j := max(i-baselineDays, 0); j <= min(i+baselineDays, len(days)-1); j++ {
+			other := &days[j]
+			if j == i || other.Rainy || other.WeatherHours < minBaselineWeatherHours {
+				continue
+			}
+			sum += float64(other.Detections)
+			n++
+		}
+		if n > 0 {
+			expected := math.Round(sum/float64(n)*10) / 10
+			day.Expected = &expected
+			if expected > 0 {
+				ratio := math.Round(float64(day.Detections)/expected*100) / 100
+				day.Ratio = &ratio
+			}
+		}
+		day.Assessment = assessDay(day)
+	}
+
+	return days, nil
+}
+
+// assessDay classifies a day's activity against its expectation.
+func assessDay(day *DayActivity) string {
+	switch {
+	case day.WeatherHours == 0:
+		return AssessmentNoWeather
+	case day.Ratio == nil || *day.Ratio >= lowActivityRatio:
+		return AssessmentNormal
+	case day.RainyHours >= rainyHoursThreshold || day.MeanWindSpeed >= windyThreshold:
+		return AssessmentWeather
+	default:
+		return AssessmentUnexplained
+	}
+}
@@ -0,0 +1,193 @@
+package weather
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tphakala/birdnet-go/internal/datastore"
+)
+
+// hourlyRecords returns one weather record per hour from start (inclusive) for
+// count hours, using fill to set the weather of each record.
+func hourlyRecords(start time.Time, count int, fill func(i int, w *datastore.HourlyWeather)) []datastore.HourlyWeather {
+	records := make([]datastore.HourlyWeather, count)
+	for i := range records {
+		records[i] = datastore.HourlyWeather{
+			Time:        start.Add(time.Duration(i) * time.Hour),
+			Temperature: 10,
+			Pressure:    1015,
+			WindSpeed:   1,
+			WeatherIcon: string(IconClearSky),
+		}
+		if fill != nil {
+			fill(i, &records[i])
+		}
+	}
+	return records
+}
+
+func detectionCount(species, date, minute string, count int) datastore.DetectionMinuteCount {
+	return datastore.DetectionMinuteCount{ScientificName: species, CommonName: species, Date: date, Minute: minute, Count: count}
+}
+
+func TestCondition(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		icon     IconCode
+		expected string
+	}{
+		{IconClearSky, ConditionClear},
+		{IconFair, ConditionClear},
+		{IconCloudy, ConditionCloudy},
+		{IconFog, ConditionFog},
+		{IconRain, ConditionRain},
+		{IconThunderstorm, ConditionThunderstorm},
+		{IconSnow, ConditionSnow},
+		{IconCode("??"), ConditionUnknown},
+	}
+	for _, tt := range tests {
+		w := &datastore.HourlyWeather{WeatherIcon: string(tt.icon)}
+		assert.Equal(t, tt.expected, Condition(w), "icon %q", tt.icon)
+	}
+}
+
+func TestNearestWeather(t *testing.T) {
+	t.Parallel()
+
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	records := []datastore.HourlyWeather{
+		{Time: day.Add(10 * time.Hour), Temperature: 10},
+		{Time: day.Add(12 * time.Hour), Temperature: 12},
+	}
+
+	w := nearestWeather(records, day.Add(11*time.Hour+10*time.Minute))
+	require.NotNil(t, w)
+	assert.InDelta(t, 12.0, w.Temperature, 0.001, "12:00 is closer than 10:00")
+
+	w = nearestWeather(records, day.Add(13*time.Hour+30*time.Minute))
+	require.NotNil(t, w, "a gap of exactly 90 minutes still matches")
+	assert.InDelta(t, 12.0, w.Temperature, 0.001)
+
+	assert.Nil(t, nearestWeather(records, day.Add(14*time.Hour)))
+	assert.Nil(t, nearestWeather(nil, day))
+}
+
+func TestComputeActivityCorrelation(t *testing.T) {
+	t.Parallel()
+
+	// Weather from 00:00 to 09:00: cold and clear until 04:00, warm rain after.
+	// Hour 10 still matches the 09:00 record, later hours have no weather.
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	hourly := hourlyRecords(start, 10, func(i int, w *datastore.HourlyWeather) {
+		if i < 5 {
+			w.Temperature = 3
+			return
+		}
+		w.Temperature = 12
+		w.WeatherIcon = string(IconRain)
+	})
+	detections := []datastore.DetectionMinuteCount{
+		detectionCount("Turdus merula", "2024-05-01", "04:10", 4),
+		detectionCount("Turdus merula", "2024-05-01", "06:00", 3),
+		detectionCount("Erithacus rubecula", "2024-05-01", "06:05", 3),
+		detectionCount("Turdus merula", "2024-05-01", "15:00", 2), // no weather
+		detectionCount("Turdus merula", "2024-05-01", "bad", 5),   // ignored
+		detectionCount("Turdus merula", "2024-05-02", "06:00", 5), // outside range
+	}
+
+	result, err := ComputeActivityCorrelation("2024-05-01", "2024-05-01", time.UTC, detections, hourly)
+	require.NoError(t, err)
+
+	assert.Equal(t, 11, result.WeatherHours)
+	assert.Equal(t, 2, result.UnmatchedDetections)
+	require.Len(t, result.Variables, 5)
+
+	temperature := result.Variables[0]
+	assert.Equal(t, VariableTemperature, temperature.Variable)
+	require.Len(t, temperature.Bins, 2)
+	assert.Equal(t, "0 to 5 °C", temperature.Bins[0].Label)
+	assert.Equal(t, 5, temperature.Bins[0].Hours)
+	assert.Equal(t, 4, temperature.Bins[0].Detections)
+	assert.InDelta(t, 0.8, temperature.Bins[0].DetectionsPerHour, 0.001)
+	assert.Equal(t, "10 to 15 °C", temperature.Bins[1].Label)
+	assert.Equal(t, 6, temperature.Bins[1].Hours)
+	assert.Equal(t, 6, temperature.Bins[1].Detections)
+
+	require.Len(t, temperature.Species, 2)
+	assert.Equal(t, "Turdus merula", temperature.Species[0].ScientificName)
+	assert.Equal(t, 7, temperature.Species[0].Total, "the detection without weather is not binned")
+	assert.Equal(t, []int{4, 3}, temperature.Species[0].Detections)
+	assert.Equal(t, []float64{0.8, 0.5}, temperature.Species[0].PerHour)
+	assert.Equal(t, []int{0, 3}, temperature.Species[1].Detections)
+
+	wind := result.Variables[1]
+	require.Len(t, wind.Bins, 1)
+	assert.Equal(t, "0-2 m/s", wind.Bins[0].Label)
+	require.NotNil(t, wind.Bins[0].Min)
+	assert.InDelta(t, 0.0, *wind.Bins[0].Min, 0.001)
+
+	pressure := result.Variables[3]
+	require.Len(t, pressure.Bins, 1)
+	assert.Equal(t, "1010-1020 hPa", pressure.Bins[0].Label)
+
+	condition := result.Variables[4]
+	require.Len(t, condition.Bins, 2)
+	assert.Equal(t, ConditionClear, condition.Bins[0].Label)
+	assert.Equal(t, ConditionRain, condition.Bins[1].Label)
+	assert.Nil(t, condition.Bins[1].Min)
+}
+
+func TestComputeActivityCorrelationInvalidDate(t *testing.T) {
+	t.Parallel()
+
+	_, err := ComputeActivityCorrelation("2024-13-01", "2024-05-01", time.UTC, nil, nil)
+	require.Error(t, err)
+}
+
+func TestComputeDailyActivity(t *testing.T) {
+	t.Parallel()
+
+	// Weather until 2024-05-04 22:00; 2024-05-05 has none. 2024-05-03 rains all day.
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	hourly := hourlyRecords(start, 3*24+23, func(i int, w *datastore.HourlyWeather) {
+		if i/24 == 2 {
+			w.WeatherIcon = string(IconRain)
+		}
+	})
+	detections := []datastore.DetectionMinuteCount{
+		detectionCount("Turdus merula", "2024-05-01", "08:00", 100),
+		detectionCount("Turdus merula", "2024-05-02", "08:00", 100),
+		detectionCount("Turdus merula", "2024-05-03", "08:00", 20),
+		detectionCount("Turdus merula", "2024-05-04", "08:00", 30),
+	}
+
+	days, err := ComputeDailyActivity("2024-05-01", "2024-05-05", time.UTC, detections, hourly)
+	require.NoError(t, err)
+	require.Len(t, days, 5)
+
+	assert.Equal(t, AssessmentNormal, days[0].Assessment)
+	assert.False(t, days[0].Rainy)
+	assert.Equal(t, 24, days[0].WeatherHours)
+	assert.InDelta(t, 10.0, days[0].MeanTemp, 0.001)
+
+	rainy := days[2]
+	assert.True(t, rainy.Rainy)
+	assert.Equal(t, 24, rainy.RainyHours)
+	require.NotNil(t, rainy.Expected)
+	assert.InDelta(t, 76.7, *rainy.Expected, 0.001, "mean of the dry days 1, 2 and 4")
+	require.NotNil(t, rainy.Ratio)
+	assert.InDelta(t, 0.26, *rainy.Ratio, 0.001)
+	assert.Equal(t, AssessmentWeather, rainy.Assessment)
+
+	dry := days[3]
+	assert.False(t, dry.Rainy)
+	require.NotNil(t, dry.Expected)
+	assert.InDelta(t, 100.0, *dry.Expected, 0.001, "the rainy day is not part of the baseline")
+	assert.Equal(t, AssessmentUnexplained, dry.Assessment)
+
+	assert.Equal(t, 0, days[4].WeatherHours)
+	assert.Equal(t, AssessmentNoWeather, days[4].Assessment)
+}
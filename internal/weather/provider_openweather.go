@@ -178,6 +178,11 @@ func mapOpenWeatherResponse(data *OpenWeatherResponse, settings *conf.Settings)
 		data.Main.TempMax,
 		settings.Realtime.Weather.OpenWeather.Units,
 	)
+	windSpeed, windGust := convertOpenWeatherWind(
+		data.Wind.Speed,
+		data.Wind.Gust,
+		settings.Realtime.Weather.OpenWeather.Units,
+	)
 
 	return &WeatherData{
 		Time: time.Unix(data.Dt, 0),
@@ -194,9 +199,9 @@ func mapOpenWeatherResponse(data *OpenWeatherResponse, settings *conf.Settings)
 			Max:       tempMax,
 		},
 		Wind: Wind{
-			Speed: data.Wind.Speed,
+			Speed: windSpeed,
 			Deg:   data.Wind.Deg,
-			Gust:  data.Wind.Gust,
+			Gust:  windGust,
 		},
 		Clouds:      data.Clouds.All,
 		Visibility:  data.Visibility,
@@ -232,6 +237,17 @@ func convertOpenWeatherTemps(temp, feelsLike, tempMin, tempMax float64, units st
 	}
 }
 
+// convertOpenWeatherWind converts OpenWeather wind speeds to m/s.
+// OpenWeather unit systems:
+// - "metric" and "standard": Already m/s, no conversion needed
+// - "imperial": mph, convert to m/s
+func convertOpenWeatherWind(speed, gust float64, units string) (speedMs, gustMs float64) {
+	if units == "imperial" {
+		return speed * MphToMs, gust * MphToMs
+	}
+	return speed, gust
+}
+
 func maskAPIKey(rawURL, keyParamName string) string {
 	parsedURL, err := url.Parse(rawURL)
 	if err != nil {
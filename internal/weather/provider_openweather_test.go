@@ -164,6 +164,29 @@ func TestConvertOpenWeatherTemps_Standard(t *testing.T) {
 	assert.InDelta(t, 25.0, tempMax, 0.001)   // 298.15K = 25°C
 }
 
+func TestConvertOpenWeatherWind(t *testing.T) {
+	tests := []struct {
+		name      string
+		units     string
+		speed     float64
+		gust      float64
+		wantSpeed float64
+		wantGust  float64
+	}{
+		{"metric", "metric", 5.0, 8.0, 5.0, 8.0},
+		{"standard", "standard", 5.0, 8.0, 5.0, 8.0},
+		{"imperial_mph_to_ms", "imperial", 22.37, 33.55, 22.37 * MphToMs, 33.55 * MphToMs}, // ~10 and ~15 m/s
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			speed, gust := convertOpenWeatherWind(tt.speed, tt.gust, tt.units)
+			assert.InDelta(t, tt.wantSpeed, speed, 0.001)
+			assert.InDelta(t, tt.wantGust, gust, 0.001)
+		})
+	}
+}
+
 func TestMaskAPIKey(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -388,15 +411,19 @@ func TestMapOpenWeatherResponse(t *testing.T) {
 		feelsLike float64
 		tempMin   float64
 		tempMax   float64
+		windSpeed float64 // Input in specified units
+		windGust  float64
 		delta     float64 // Acceptable delta for assertions
 	}{
 		{
 			name:      "imperial_fahrenheit_to_celsius",
 			units:     "imperial",
-			temp:      68.0, // 20°C
-			feelsLike: 64.4, // 18°C
-			tempMin:   59.0, // 15°C
-			tempMax:   77.0, // 25°C
+			temp:      68.0,  // 20°C
+			feelsLike: 64.4,  // 18°C
+			tempMin:   59.0,  // 15°C
+			tempMax:   77.0,  // 25°C
+			windSpeed: 11.18, // 5 m/s
+			windGust:  17.9,  // 8 m/s
 			delta:     0.1,
 		},
 		{
@@ -406,6 +433,8 @@ func TestMapOpenWeatherResponse(t *testing.T) {
 			feelsLike: 291.15, // 18°C
 			tempMin:   288.15, // 15°C
 			tempMax:   298.15, // 25°C
+			windSpeed: 5.0,    // m/s
+			windGust:  8.0,    // m/s
 			delta:     0.01,
 		},
 	}
@@ -421,6 +450,8 @@ func TestMapOpenWeatherResponse(t *testing.T) {
 			response.Main.FeelsLike = tt.feelsLike
 			response.Main.TempMin = tt.tempMin
 			response.Main.TempMax = tt.tempMax
+			response.Wind.Speed = tt.windSpeed
+			response.Wind.Gust = tt.windGust
 			response.Dt = 1736769600
 			response.Weather = []struct {
 				ID          int    `json:"id"`
@@ -440,6 +471,9 @@ func TestMapOpenWeatherResponse(t *testing.T) {
 			assert.InDelta(t, 18.0, result.Temperature.FeelsLike, tt.delta)
 			assert.InDelta(t, 15.0, result.Temperature.Min, tt.delta)
 			assert.InDelta(t, 25.0, result.Temperature.Max, tt.delta)
+			// Wind is always stored in m/s
+			assert.InDelta(t, 5.0, result.Wind.Speed, tt.delta)
+			assert.InDelta(t, 8.0, result.Wind.Gust, tt.delta)
 		})
 	}
 